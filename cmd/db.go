@@ -0,0 +1,171 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/spf13/cobra"
+)
+
+var dbPlugin string
+var dbDryRun bool
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manages the schema of a database plugin",
+	Long: `Reports, applies and rolls back the schema migrations of a database plugin, using the same
+migrations directory that is applied on startup when migrations.auto is set. The FireFly node
+should be stopped while migrations are applied or rolled back.`,
+}
+
+var dbStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Prints the schema version of the database, and any pending migrations",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDB(func(ctx context.Context, di database.Plugin) error {
+			status, err := di.MigrationStatus(ctx)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Schema version: %d (latest %d)\n", status.Version, status.Latest)
+			if status.Dirty {
+				fmt.Fprintln(out, "Schema is dirty - the last migration failed part way through")
+			}
+			printMigrations(out, "Pending migrations:", status.Pending)
+			return nil
+		})
+	},
+}
+
+var dbUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Applies all pending migrations, then converts any tables configured for partitioning",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDB(func(ctx context.Context, di database.Plugin) error {
+			out := cmd.OutOrStdout()
+			if dbDryRun {
+				status, err := di.MigrationStatus(ctx)
+				if err != nil {
+					return err
+				}
+				for _, m := range status.Pending {
+					fmt.Fprintf(out, "-- %06d_%s\n%s\n", m.Version, m.Name, m.SQL)
+				}
+				return nil
+			}
+			applied, err := di.MigrateUp(ctx)
+			if err != nil {
+				return err
+			}
+			printMigrations(out, "Applied migrations:", applied)
+			return nil
+		})
+	},
+}
+
+var dbDownCmd = &cobra.Command{
+	Use:   "down N",
+	Short: "Rolls back the last N migrations",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDB(func(ctx context.Context, di database.Plugin) error {
+			steps, err := strconv.Atoi(args[0])
+			if err != nil || steps < 1 {
+				return i18n.NewError(ctx, coremsgs.MsgInvalidMigrationSteps, args[0])
+			}
+			rolledBack, err := di.MigrateDown(ctx, steps)
+			if err != nil {
+				return err
+			}
+			printMigrations(cmd.OutOrStdout(), "Rolled back migrations:", rolledBack)
+			return nil
+		})
+	},
+}
+
+var dbVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Fails unless the schema version of the database matches the latest migration",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDB(func(ctx context.Context, di database.Plugin) error {
+			status, err := di.MigrationStatus(ctx)
+			if err != nil {
+				return err
+			}
+			switch {
+			case status.Dirty:
+				return i18n.NewError(ctx, coremsgs.MsgDatabaseSchemaDirty, status.Version)
+			case status.Version > status.Latest:
+				return i18n.NewError(ctx, coremsgs.MsgDatabaseSchemaNewer, status.Version, status.Latest)
+			case len(status.Pending) > 0:
+				return i18n.NewError(ctx, coremsgs.MsgDatabaseSchemaPending, status.Version, len(status.Pending), status.Latest)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Schema version %d is up to date\n", status.Version)
+			return nil
+		})
+	},
+}
+
+func printMigrations(out io.Writer, heading string, migrations []*core.DatabaseMigration) {
+	if len(migrations) == 0 {
+		return
+	}
+	fmt.Fprintln(out, heading)
+	for _, m := range migrations {
+		fmt.Fprintf(out, "  %06d_%s\n", m.Version, m.Name)
+	}
+}
+
+func runDB(fn func(ctx context.Context, di database.Plugin) error) error {
+	err := reloadConfig()
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+	config.SetupLogging(ctx)
+	if err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgConfigFailed)
+	}
+
+	di, err := getRootManager().InitDatabase(ctx, cancelCtx, dbPlugin)
+	if err != nil {
+		return err
+	}
+	return fn(ctx, di)
+}
+
+func init() {
+	dbCmd.PersistentFlags().StringVarP(&dbPlugin, "plugin", "p", "", "name of the database plugin (required if more than one is configured)")
+	dbUpCmd.Flags().BoolVar(&dbDryRun, "dry-run", false, "print the SQL of the pending migrations, without applying them")
+	dbCmd.AddCommand(dbStatusCmd)
+	dbCmd.AddCommand(dbUpCmd)
+	dbCmd.AddCommand(dbDownCmd)
+	dbCmd.AddCommand(dbVerifyCmd)
+	rootCmd.AddCommand(dbCmd)
+}
@@ -0,0 +1,201 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/namespacemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func runDBCmd(t *testing.T, args ...string) (*databasemocks.Plugin, *bytes.Buffer, func() error) {
+	nm := &namespacemocks.Manager{}
+	mdi := &databasemocks.Plugin{}
+	_utManager = nm
+	nm.On("InitDatabase", mock.Anything, mock.Anything, "").Return(mdi, nil).Maybe()
+	out := &bytes.Buffer{}
+	return mdi, out, func() error {
+		defer func() {
+			_utManager = nil
+			cfgFile, dbPlugin, dbDryRun = "", "", false
+			rootCmd.SetArgs([]string{})
+			rootCmd.SetOut(nil)
+		}()
+		rootCmd.SetOut(out)
+		rootCmd.SetArgs(append([]string{"db", "-f", testConfigFile}, args...))
+		err := rootCmd.Execute()
+		nm.AssertExpectations(t)
+		mdi.AssertExpectations(t)
+		return err
+	}
+}
+
+func testSchemaStatus(version uint, dirty bool, pending ...*core.DatabaseMigration) *core.DatabaseSchemaStatus {
+	return &core.DatabaseSchemaStatus{
+		Version: version,
+		Dirty:   dirty,
+		Latest:  3,
+		Pending: pending,
+	}
+}
+
+func TestDBStatus(t *testing.T) {
+	mdi, out, run := runDBCmd(t, "status")
+	mdi.On("MigrationStatus", mock.Anything).Return(testSchemaStatus(2, true, &core.DatabaseMigration{Version: 3, Name: "create_gizmos"}), nil)
+	err := run()
+	assert.NoError(t, err)
+	assert.Equal(t, "Schema version: 2 (latest 3)\n"+
+		"Schema is dirty - the last migration failed part way through\n"+
+		"Pending migrations:\n"+
+		"  000003_create_gizmos\n", out.String())
+}
+
+func TestDBStatusFail(t *testing.T) {
+	mdi, _, run := runDBCmd(t, "status")
+	mdi.On("MigrationStatus", mock.Anything).Return(nil, fmt.Errorf("pop"))
+	err := run()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDBUp(t *testing.T) {
+	mdi, out, run := runDBCmd(t, "up")
+	mdi.On("MigrateUp", mock.Anything).Return([]*core.DatabaseMigration{{Version: 3, Name: "create_gizmos"}}, nil)
+	err := run()
+	assert.NoError(t, err)
+	assert.Equal(t, "Applied migrations:\n  000003_create_gizmos\n", out.String())
+}
+
+func TestDBUpNothingPending(t *testing.T) {
+	mdi, out, run := runDBCmd(t, "up")
+	mdi.On("MigrateUp", mock.Anything).Return([]*core.DatabaseMigration{}, nil)
+	err := run()
+	assert.NoError(t, err)
+	assert.Empty(t, out.String())
+}
+
+func TestDBUpFail(t *testing.T) {
+	mdi, _, run := runDBCmd(t, "up")
+	mdi.On("MigrateUp", mock.Anything).Return(nil, fmt.Errorf("pop"))
+	err := run()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDBUpDryRun(t *testing.T) {
+	mdi, out, run := runDBCmd(t, "up", "--dry-run")
+	mdi.On("MigrationStatus", mock.Anything).Return(testSchemaStatus(2, false, &core.DatabaseMigration{Version: 3, Name: "create_gizmos", SQL: "CREATE TABLE gizmos;"}), nil)
+	err := run()
+	assert.NoError(t, err)
+	assert.Equal(t, "-- 000003_create_gizmos\nCREATE TABLE gizmos;\n", out.String())
+}
+
+func TestDBUpDryRunFail(t *testing.T) {
+	mdi, _, run := runDBCmd(t, "up", "--dry-run")
+	mdi.On("MigrationStatus", mock.Anything).Return(nil, fmt.Errorf("pop"))
+	err := run()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDBDown(t *testing.T) {
+	mdi, out, run := runDBCmd(t, "down", "2")
+	mdi.On("MigrateDown", mock.Anything, 2).Return([]*core.DatabaseMigration{
+		{Version: 3, Name: "create_gizmos"},
+		{Version: 2, Name: "create_gadgets"},
+	}, nil)
+	err := run()
+	assert.NoError(t, err)
+	assert.Equal(t, "Rolled back migrations:\n  000003_create_gizmos\n  000002_create_gadgets\n", out.String())
+}
+
+func TestDBDownFail(t *testing.T) {
+	mdi, _, run := runDBCmd(t, "down", "1")
+	mdi.On("MigrateDown", mock.Anything, 1).Return(nil, fmt.Errorf("pop"))
+	err := run()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDBDownBadSteps(t *testing.T) {
+	_, _, run := runDBCmd(t, "down", "zero")
+	err := run()
+	assert.Regexp(t, "FF10511.*zero", err)
+}
+
+func TestDBVerify(t *testing.T) {
+	mdi, out, run := runDBCmd(t, "verify")
+	mdi.On("MigrationStatus", mock.Anything).Return(testSchemaStatus(3, false), nil)
+	err := run()
+	assert.NoError(t, err)
+	assert.Equal(t, "Schema version 3 is up to date\n", out.String())
+}
+
+func TestDBVerifyDirty(t *testing.T) {
+	mdi, _, run := runDBCmd(t, "verify")
+	mdi.On("MigrationStatus", mock.Anything).Return(testSchemaStatus(3, true), nil)
+	err := run()
+	assert.Regexp(t, "FF10508", err)
+}
+
+func TestDBVerifyNewer(t *testing.T) {
+	mdi, _, run := runDBCmd(t, "verify")
+	mdi.On("MigrationStatus", mock.Anything).Return(testSchemaStatus(4, false), nil)
+	err := run()
+	assert.Regexp(t, "FF10509", err)
+}
+
+func TestDBVerifyPending(t *testing.T) {
+	mdi, _, run := runDBCmd(t, "verify")
+	mdi.On("MigrationStatus", mock.Anything).Return(testSchemaStatus(2, false, &core.DatabaseMigration{Version: 3}), nil)
+	err := run()
+	assert.Regexp(t, "FF10510", err)
+}
+
+func TestDBVerifyFail(t *testing.T) {
+	mdi, _, run := runDBCmd(t, "verify")
+	mdi.On("MigrationStatus", mock.Anything).Return(nil, fmt.Errorf("pop"))
+	err := run()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDBInitFail(t *testing.T) {
+	nm := &namespacemocks.Manager{}
+	_utManager = nm
+	defer func() {
+		_utManager = nil
+		cfgFile, dbPlugin = "", ""
+		rootCmd.SetArgs([]string{})
+	}()
+	nm.On("InitDatabase", mock.Anything, mock.Anything, "sqlite").Return(nil, fmt.Errorf("pop"))
+	rootCmd.SetArgs([]string{"db", "status", "-f", testConfigFile, "-p", "sqlite"})
+	err := rootCmd.Execute()
+	assert.EqualError(t, err, "pop")
+	nm.AssertExpectations(t)
+}
+
+func TestDBBadConfig(t *testing.T) {
+	defer func() {
+		cfgFile = ""
+		rootCmd.SetArgs([]string{})
+	}()
+	rootCmd.SetArgs([]string{"db", "status", "-f", "badconfig.yaml"})
+	err := rootCmd.Execute()
+	assert.Regexp(t, "FF00101", err)
+}
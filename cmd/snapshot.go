@@ -0,0 +1,116 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/snapshot"
+	"github.com/spf13/cobra"
+)
+
+var snapshotNamespace, snapshotFile string
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Exports or imports the data of a namespace",
+	Long: `Exports the data of a namespace to a snapshot archive, or imports a snapshot archive
+into a namespace with an empty database. The FireFly node should be stopped while these
+commands run, as they only initialize the database and data exchange plugins of the namespace.`,
+}
+
+var snapshotExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exports the data of a namespace to a snapshot archive",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSnapshot(func(ctx context.Context, sm snapshot.Manager, file string) error {
+			f, err := os.Create(file)
+			if err != nil {
+				return err
+			}
+			err = sm.Export(ctx, f)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				// Do not leave a partial archive behind
+				_ = os.Remove(file)
+				return err
+			}
+			log.L(ctx).Infof("Exported snapshot to %s", file)
+			return nil
+		})
+	},
+}
+
+var snapshotImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Imports a snapshot archive into a namespace with an empty database",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSnapshot(func(ctx context.Context, sm snapshot.Manager, file string) error {
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if err = sm.Import(ctx, f); err != nil {
+				return err
+			}
+			log.L(ctx).Infof("Imported snapshot from %s", file)
+			return nil
+		})
+	},
+}
+
+func runSnapshot(fn func(ctx context.Context, sm snapshot.Manager, file string) error) error {
+	err := reloadConfig()
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+	config.SetupLogging(ctx)
+	if err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgConfigFailed)
+	}
+
+	ns := snapshotNamespace
+	if ns == "" {
+		ns = config.GetString(coreconfig.NamespacesDefault)
+	}
+	file := snapshotFile
+	if file == "" {
+		file = fmt.Sprintf("%s.tar.gz", ns)
+	}
+	sm, err := getRootManager().InitSnapshot(ctx, cancelCtx, ns)
+	if err != nil {
+		return err
+	}
+	return fn(ctx, sm, file)
+}
+
+func init() {
+	snapshotCmd.PersistentFlags().StringVarP(&snapshotNamespace, "namespace", "n", "", "namespace to export or import (defaults to namespaces.default)")
+	snapshotCmd.PersistentFlags().StringVarP(&snapshotFile, "file", "o", "", "snapshot archive to write or read (defaults to <namespace>.tar.gz)")
+	snapshotCmd.AddCommand(snapshotExportCmd)
+	snapshotCmd.AddCommand(snapshotImportCmd)
+	rootCmd.AddCommand(snapshotCmd)
+}
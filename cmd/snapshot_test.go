@@ -0,0 +1,127 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/namespacemocks"
+	"github.com/hyperledger/firefly/mocks/snapshotmocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var testConfigFile, _ = filepath.Abs(configDir + "/firefly.core.yaml")
+
+func runSnapshotCmd(t *testing.T, args ...string) (*namespacemocks.Manager, func() error) {
+	nm := &namespacemocks.Manager{}
+	_utManager = nm
+	return nm, func() error {
+		defer func() {
+			_utManager = nil
+			cfgFile, snapshotNamespace, snapshotFile = "", "", ""
+			rootCmd.SetArgs([]string{})
+		}()
+		rootCmd.SetArgs(append([]string{"snapshot", "-f", testConfigFile}, args...))
+		err := rootCmd.Execute()
+		nm.AssertExpectations(t)
+		return err
+	}
+}
+
+func TestSnapshotExportImport(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "ns1.tar.gz")
+	msm := &snapshotmocks.Manager{}
+	msm.On("Export", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		_, _ = args[1].(io.Writer).Write([]byte("archive"))
+	}).Return(nil)
+	msm.On("Import", mock.Anything, mock.MatchedBy(func(r io.Reader) bool {
+		b, _ := io.ReadAll(r)
+		return string(b) == "archive"
+	})).Return(nil)
+
+	nm, run := runSnapshotCmd(t, "export", "-n", "ns1", "-o", file)
+	nm.On("InitSnapshot", mock.Anything, mock.Anything, "ns1").Return(msm, nil)
+	err := run()
+	assert.NoError(t, err)
+
+	nm, run = runSnapshotCmd(t, "import", "-n", "ns1", "-o", file)
+	nm.On("InitSnapshot", mock.Anything, mock.Anything, "ns1").Return(msm, nil)
+	err = run()
+	assert.NoError(t, err)
+
+	msm.AssertExpectations(t)
+}
+
+func TestSnapshotExportFail(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "ns1.tar.gz")
+	msm := &snapshotmocks.Manager{}
+	msm.On("Export", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	nm, run := runSnapshotCmd(t, "export", "-n", "ns1", "-o", file)
+	nm.On("InitSnapshot", mock.Anything, mock.Anything, "ns1").Return(msm, nil)
+	err := run()
+	assert.EqualError(t, err, "pop")
+
+	_, err = os.Stat(file)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSnapshotExportCreateFail(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "missing", "ns1.tar.gz")
+	nm, run := runSnapshotCmd(t, "export", "-n", "ns1", "-o", file)
+	nm.On("InitSnapshot", mock.Anything, mock.Anything, "ns1").Return(&snapshotmocks.Manager{}, nil)
+	err := run()
+	assert.Regexp(t, "no such file", err)
+}
+
+func TestSnapshotImportFail(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "ns1.tar.gz")
+	err := os.WriteFile(file, []byte("archive"), 0600)
+	assert.NoError(t, err)
+	msm := &snapshotmocks.Manager{}
+	msm.On("Import", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	nm, run := runSnapshotCmd(t, "import", "-n", "ns1", "-o", file)
+	nm.On("InitSnapshot", mock.Anything, mock.Anything, "ns1").Return(msm, nil)
+	err = run()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestSnapshotImportDefaultFileMissing(t *testing.T) {
+	nm, run := runSnapshotCmd(t, "import")
+	nm.On("InitSnapshot", mock.Anything, mock.Anything, "default").Return(&snapshotmocks.Manager{}, nil)
+	err := run()
+	assert.Regexp(t, "default.tar.gz", err)
+}
+
+func TestSnapshotInitFail(t *testing.T) {
+	nm, run := runSnapshotCmd(t, "export")
+	nm.On("InitSnapshot", mock.Anything, mock.Anything, "default").Return(nil, fmt.Errorf("pop"))
+	err := run()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestSnapshotBadConfig(t *testing.T) {
+	_, run := runSnapshotCmd(t, "export", "-f", "/does/not/exist.yaml")
+	err := run()
+	assert.Regexp(t, "FF00101", err)
+}
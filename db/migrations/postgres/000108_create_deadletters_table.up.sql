@@ -0,0 +1,17 @@
+BEGIN;
+CREATE TABLE deadletters (
+  seq               SERIAL          PRIMARY KEY,
+  id                UUID            NOT NULL,
+  namespace         VARCHAR(64)     NOT NULL,
+  subscription_id   UUID            NOT NULL,
+  subscription_name VARCHAR(64)     NOT NULL,
+  event_id          UUID            NOT NULL,
+  attempts          BIGINT          NOT NULL,
+  reason            TEXT,
+  created           BIGINT          NOT NULL,
+  updated           BIGINT
+);
+
+CREATE UNIQUE INDEX deadletters_id ON deadletters(namespace,id);
+CREATE INDEX deadletters_subscription ON deadletters(namespace,subscription_id);
+COMMIT;
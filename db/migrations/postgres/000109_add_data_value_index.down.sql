@@ -0,0 +1,3 @@
+BEGIN;
+DROP INDEX IF EXISTS data_value;
+COMMIT;
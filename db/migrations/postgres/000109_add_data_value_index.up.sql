@@ -0,0 +1,3 @@
+BEGIN;
+CREATE INDEX data_value ON data USING GIN ((value::jsonb) jsonb_path_ops);
+COMMIT;
@@ -0,0 +1,4 @@
+BEGIN;
+ALTER TABLE blockchainevents DROP COLUMN filter_index;
+ALTER TABLE contractlisteners DROP COLUMN filters;
+COMMIT;
@@ -0,0 +1,4 @@
+BEGIN;
+ALTER TABLE contractlisteners ADD COLUMN filters TEXT;
+ALTER TABLE blockchainevents ADD COLUMN filter_index INTEGER;
+COMMIT;
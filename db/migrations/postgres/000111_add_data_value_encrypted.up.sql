@@ -0,0 +1,5 @@
+BEGIN;
+ALTER TABLE data ADD COLUMN value_encrypted BOOLEAN;
+UPDATE data SET value_encrypted = false;
+ALTER TABLE data ALTER COLUMN value_encrypted SET NOT NULL;
+COMMIT;
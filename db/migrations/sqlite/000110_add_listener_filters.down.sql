@@ -0,0 +1,2 @@
+ALTER TABLE blockchainevents DROP COLUMN filter_index;
+ALTER TABLE contractlisteners DROP COLUMN filters;
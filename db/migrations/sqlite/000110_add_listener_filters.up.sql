@@ -0,0 +1,2 @@
+ALTER TABLE contractlisteners ADD COLUMN filters TEXT;
+ALTER TABLE blockchainevents ADD COLUMN filter_index INTEGER;
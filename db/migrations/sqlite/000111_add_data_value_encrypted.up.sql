@@ -0,0 +1,2 @@
+ALTER TABLE data ADD COLUMN value_encrypted BOOLEAN;
+UPDATE data SET value_encrypted = false;
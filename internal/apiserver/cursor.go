@@ -0,0 +1,94 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+const nextCursorHeader = "X-FireFly-Next-Cursor"
+
+var cursorQueryParam = &ffapi.QueryParam{Name: "cursor", Description: coremsgs.APIParamsCursor}
+
+// withCursor adds the cursor query parameter to a route, if it returns a collection that can be paged by cursor
+func withCursor(route *ffapi.Route) {
+	if route.FilterFactory != nil && database.CursorKeyset(context.Background(), route.FilterFactory) != nil {
+		route.QueryParams = append(route.QueryParams, cursorQueryParam)
+	}
+}
+
+// documentCursorHeader describes the next cursor header on the routes that can be paged by cursor,
+// as the generated swagger only describes the response body
+func documentCursorHeader(ctx context.Context, doc *openapi3.T) {
+	for _, path := range doc.Paths {
+		if path.Get == nil || path.Get.Parameters.GetByInAndName(openapi3.ParameterInQuery, cursorQueryParam.Name) == nil {
+			continue
+		}
+		if res := path.Get.Responses.Get(200); res != nil && res.Value != nil {
+			res.Value.Headers = openapi3.Headers{
+				nextCursorHeader: &openapi3.HeaderRef{
+					Value: &openapi3.Header{
+						Parameter: openapi3.Parameter{
+							Description: i18n.Expand(ctx, coremsgs.APIHeadersNextCursor),
+							Schema:      openapi3.NewStringSchema().NewRef(),
+						},
+					},
+				},
+			}
+		}
+	}
+}
+
+// pageByCursor runs the handler of a collection route for the page after the cursor in the request.
+// The page is returned in the same shape as without a cursor, and if it is full the cursor for the
+// following page is set in a response header.
+func pageByCursor(r *ffapi.APIRequest, cr *coreRequest, keyset []string, handler func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error)) (interface{}, error) {
+	var c *database.Cursor
+	if token := r.QP["cursor"]; token != "" {
+		var err error
+		if c, err = database.ParseCursor(cr.ctx, token); err != nil {
+			return nil, err
+		}
+	}
+	if err := database.ApplyCursor(cr.ctx, r.Filter, keyset, c); err != nil {
+		return nil, err
+	}
+
+	output, err := handler(r, cr)
+	if err != nil {
+		return nil, err
+	}
+	items := output
+	if res, ok := output.(*ffapi.FilterResultsWithCount); ok {
+		items = res.Items
+	}
+	fi, _ := r.Filter.Finalize()
+	v := reflect.ValueOf(items)
+	if v.Kind() == reflect.Slice && v.Len() > 0 && uint64(v.Len()) >= fi.Limit {
+		if next := database.NextCursor(keyset, v.Index(v.Len()-1).Interface()); next != nil {
+			r.ResponseHeaders.Set(nextCursorHeader, next.String())
+		}
+	}
+	return output, nil
+}
@@ -0,0 +1,184 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/assetmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetMessagesByCursor(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	seq := int64(100)
+	cursor := &database.Cursor{Sequence: &seq}
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages?limit=2&cursor="+cursor.String(), nil)
+	res := httptest.NewRecorder()
+
+	o.On("GetMessages", mock.Anything, mock.MatchedBy(func(filter ffapi.AndFilter) bool {
+		fi, _ := filter.Finalize()
+		return fi.String() == "( sequence << 100 ) sort=-sequence limit=2"
+	}), mock.Anything).Return([]*core.Message{{Sequence: 99}, {Sequence: 98}}, nil, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	var page []*core.Message
+	err := json.NewDecoder(res.Body).Decode(&page)
+	assert.NoError(t, err)
+	assert.Len(t, page, 2)
+	next, err := database.ParseCursor(context.Background(), res.Header().Get(nextCursorHeader))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(98), *next.Sequence)
+}
+
+func TestGetOperationsByCursorFirstPageWithCount(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/operations?limit=1&count&ascending&cursor", nil)
+	res := httptest.NewRecorder()
+
+	op := &core.Operation{ID: fftypes.NewUUID(), Created: fftypes.Now()}
+	total := int64(10)
+	o.On("GetOperations", mock.Anything, mock.MatchedBy(func(filter ffapi.AndFilter) bool {
+		fi, _ := filter.Finalize()
+		return fi.String() == " sort=created,id limit=1 count=true"
+	})).Return([]*core.Operation{op}, &ffapi.FilterResult{TotalCount: &total}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	var page ffapi.FilterResultsWithCount
+	err := json.NewDecoder(res.Body).Decode(&page)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), page.Count)
+	assert.Equal(t, int64(10), page.Total)
+	next, err := database.ParseCursor(context.Background(), res.Header().Get(nextCursorHeader))
+	assert.NoError(t, err)
+	assert.Equal(t, op.ID, next.ID)
+}
+
+func TestGetMessagesByCursorLastPage(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages?limit=2&cursor=", nil)
+	res := httptest.NewRecorder()
+
+	o.On("GetMessages", mock.Anything, mock.Anything, mock.Anything).Return([]*core.Message{{Sequence: 1}}, nil, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Empty(t, res.Header().Get(nextCursorHeader))
+	var page []*core.Message
+	err := json.NewDecoder(res.Body).Decode(&page)
+	assert.NoError(t, err)
+	assert.Len(t, page, 1)
+}
+
+func TestGetMessagesByCursorBadCursor(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages?cursor=!!!", nil)
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 400, res.Result().StatusCode)
+	assert.Regexp(t, "FF10487", res.Body.String())
+}
+
+func TestGetMessagesByCursorWithSort(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages?sort=created&cursor=", nil)
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 400, res.Result().StatusCode)
+	assert.Regexp(t, "FF10488", res.Body.String())
+}
+
+func TestGetMessagesByCursorFail(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages?cursor=", nil)
+	res := httptest.NewRecorder()
+
+	o.On("GetMessages", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 500, res.Result().StatusCode)
+}
+
+func TestCursorNotSupported(t *testing.T) {
+	route := &ffapi.Route{FilterFactory: database.TokenBalanceQueryFactory}
+	withCursor(route)
+	assert.Empty(t, route.QueryParams)
+
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/tokens/balances?sort=key&cursor=", nil)
+	res := httptest.NewRecorder()
+
+	mam := &assetmocks.Manager{}
+	o.On("Assets").Return(mam)
+	mam.On("GetTokenBalances", mock.Anything, mock.Anything).Return([]*core.TokenBalance{}, nil, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Empty(t, res.Header().Get(nextCursorHeader))
+}
+
+func TestDocumentCursorHeader(t *testing.T) {
+	doc := &openapi3.T{
+		Paths: openapi3.Paths{
+			"/messages": &openapi3.PathItem{
+				Get: &openapi3.Operation{
+					Parameters: openapi3.Parameters{
+						{Value: openapi3.NewQueryParameter("cursor")},
+					},
+					Responses: openapi3.NewResponses(),
+				},
+			},
+			"/status": &openapi3.PathItem{
+				Get: &openapi3.Operation{
+					Responses: openapi3.NewResponses(),
+				},
+			},
+			"/messages/broadcast": &openapi3.PathItem{
+				Post: &openapi3.Operation{},
+			},
+		},
+	}
+	doc.Paths["/messages"].Get.Responses["200"] = &openapi3.ResponseRef{Value: openapi3.NewResponse()}
+	doc.Paths["/status"].Get.Responses["200"] = &openapi3.ResponseRef{Value: openapi3.NewResponse()}
+
+	documentCursorHeader(context.Background(), doc)
+	assert.NotNil(t, doc.Paths["/messages"].Get.Responses.Get(200).Value.Headers[nextCursorHeader])
+	assert.Empty(t, doc.Paths["/status"].Get.Responses.Get(200).Value.Headers)
+}
@@ -21,11 +21,13 @@ import (
 )
 
 const (
-	MetricsEnabled = "enabled"
-	MetricsPath    = "path"
+	MetricsEnabled                        = "enabled"
+	MetricsPath                           = "path"
+	MetricsContractListenerStatusInterval = "contractListenerStatusInterval"
 )
 
 func initMetricsConfig(config config.Section) {
 	config.AddKnownKey(MetricsEnabled, true)
 	config.AddKnownKey(MetricsPath, "/metrics")
+	config.AddKnownKey(MetricsContractListenerStatusInterval, "1m")
 }
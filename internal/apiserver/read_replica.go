@@ -0,0 +1,42 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+const readPrimaryHeader = "X-FireFly-Read-Primary"
+
+// queryContext allows the database queries of read-only routes to be served by a read replica,
+// unless the caller sets a header to read from the primary (to see its own recent writes).
+// Lookups that fill the shared caches force the primary for themselves, with database.WithPrimary
+func queryContext(req *http.Request, route *ffapi.Route) context.Context {
+	ctx := req.Context()
+	if route.Method != http.MethodGet {
+		return ctx
+	}
+	if primary, _ := strconv.ParseBool(req.Header.Get(readPrimaryHeader)); primary {
+		return database.WithPrimary(ctx)
+	}
+	return database.WithReadReplica(ctx)
+}
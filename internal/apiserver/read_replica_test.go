@@ -0,0 +1,65 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetMessagesReadReplica(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages", nil)
+	res := httptest.NewRecorder()
+
+	o.On("GetMessages", mock.MatchedBy(func(ctx context.Context) bool {
+		return database.ReadReplicaAllowed(ctx)
+	}), mock.Anything, mock.Anything).Return([]*core.Message{}, nil, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestGetMessagesReadPrimary(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages", nil)
+	req.Header.Set(readPrimaryHeader, "true")
+	res := httptest.NewRecorder()
+
+	o.On("GetMessages", mock.MatchedBy(func(ctx context.Context) bool {
+		return !database.ReadReplicaAllowed(ctx)
+	}), mock.Anything, mock.Anything).Return([]*core.Message{}, nil, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestQueryContextNotGet(t *testing.T) {
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/mynamespace/messages/broadcast", nil)
+	ctx := queryContext(req, &ffapi.Route{Method: http.MethodPost})
+	assert.False(t, database.ReadReplicaAllowed(ctx))
+}
@@ -38,7 +38,11 @@ var getData = &ffapi.Route{
 	JSONOutputCodes: []int{http.StatusOK},
 	Extensions: &coreExtensions{
 		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
-			return r.FilterResult(cr.or.GetData(cr.ctx, r.Filter))
+			valueFilter, err := buildValueFilter(cr.ctx, r)
+			if err != nil {
+				return nil, err
+			}
+			return r.FilterResult(cr.or.GetData(cr.ctx, r.Filter, valueFilter))
 		},
 	},
 }
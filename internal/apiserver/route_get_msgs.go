@@ -41,10 +41,14 @@ var getMsgs = &ffapi.Route{
 	JSONOutputCodes: []int{http.StatusOK},
 	Extensions: &coreExtensions{
 		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
+			valueFilter, err := buildValueFilter(cr.ctx, r)
+			if err != nil {
+				return nil, err
+			}
 			if strings.EqualFold(r.QP["fetchdata"], "true") {
-				return r.FilterResult(cr.or.GetMessagesWithData(cr.ctx, r.Filter))
+				return r.FilterResult(cr.or.GetMessagesWithData(cr.ctx, r.Filter, valueFilter))
 			}
-			return r.FilterResult(cr.or.GetMessages(cr.ctx, r.Filter))
+			return r.FilterResult(cr.or.GetMessages(cr.ctx, r.Filter, valueFilter))
 		},
 	},
 }
@@ -34,7 +34,7 @@ func TestGetMessages(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	res := httptest.NewRecorder()
 
-	o.On("GetMessages", mock.Anything, mock.Anything).
+	o.On("GetMessages", mock.Anything, mock.Anything, mock.Anything).
 		Return([]*core.Message{}, nil, nil)
 	r.ServeHTTP(res, req)
 
@@ -49,7 +49,7 @@ func TestGetMessagesWithCount(t *testing.T) {
 	res := httptest.NewRecorder()
 
 	var ten int64 = 10
-	o.On("GetMessages", mock.Anything, mock.Anything).
+	o.On("GetMessages", mock.Anything, mock.Anything, mock.Anything).
 		Return([]*core.Message{}, &ffapi.FilterResult{
 			TotalCount: &ten,
 		}, nil)
@@ -72,7 +72,7 @@ func TestGetMessagesWithCountAndData(t *testing.T) {
 	res := httptest.NewRecorder()
 
 	var ten int64 = 10
-	o.On("GetMessagesWithData", mock.Anything, mock.Anything).
+	o.On("GetMessagesWithData", mock.Anything, mock.Anything, mock.Anything).
 		Return([]*core.MessageInOut{}, &ffapi.FilterResult{
 			TotalCount: &ten,
 		}, nil)
@@ -86,3 +86,31 @@ func TestGetMessagesWithCountAndData(t *testing.T) {
 	assert.Equal(t, int64(0), resWithCount.Count)
 	assert.Equal(t, int64(10), resWithCount.Total)
 }
+
+func TestGetMessagesWithValueFilter(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages?value.customer.id=c1&fetchdata", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	o.On("GetMessagesWithData", mock.Anything, mock.Anything, mock.MatchedBy(func(filter ffapi.Filter) bool {
+		return filter != nil
+	})).
+		Return([]*core.MessageInOut{}, nil, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestGetMessagesBadValueFilter(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages?value.customer=!>c1", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 400, res.Result().StatusCode)
+}
@@ -0,0 +1,67 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/snapshotmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSPIGetNamespaceSnapshot(t *testing.T) {
+	or, r := newTestSPIServer()
+	or.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	msm := &snapshotmocks.Manager{}
+	or.On("Snapshot").Return(msm)
+	or.On("GetNamespace", mock.Anything).Return(&core.Namespace{Name: "ns1"})
+	req := httptest.NewRequest("GET", "/spi/v1/namespaces/ns1/snapshot", nil)
+	res := httptest.NewRecorder()
+
+	msm.On("Export", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			_, _ = args[1].(io.Writer).Write([]byte("archive"))
+		}).
+		Return(nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Equal(t, `attachment; filename="ns1.tar.gz"`, res.Result().Header.Get("Content-Disposition"))
+	assert.Equal(t, "archive", res.Body.String())
+	msm.AssertExpectations(t)
+}
+
+func TestSPIGetNamespaceSnapshotFail(t *testing.T) {
+	or, r := newTestSPIServer()
+	or.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	msm := &snapshotmocks.Manager{}
+	or.On("Snapshot").Return(msm)
+	or.On("GetNamespace", mock.Anything).Return(&core.Namespace{Name: "ns1"})
+	req := httptest.NewRequest("GET", "/spi/v1/snapshot", nil)
+	res := httptest.NewRecorder()
+
+	msm.On("Export", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	r.ServeHTTP(res, req)
+
+	assert.Regexp(t, "pop", res.Body.String())
+	msm.AssertExpectations(t)
+}
@@ -55,6 +55,7 @@ var routes = append(
 		deleteContractListener,
 		deleteData,
 		deleteSubscription,
+		deleteSubscriptionDeadLetters,
 		getBatchByID,
 		getBatches,
 		getBlockchainEventByID,
@@ -104,7 +105,9 @@ var routes = append(
 		getPins,
 		getStatus,
 		getStatusBatchManager,
+		getStatusRetention,
 		getSubscriptionByID,
+		getSubscriptionDeadLetters,
 		getSubscriptions,
 		getTokenAccountPools,
 		getTokenAccounts,
@@ -129,6 +132,7 @@ var routes = append(
 		postContractInterfaceGenerate,
 		postContractDeploy,
 		postContractInvoke,
+		postContractInvokeBatch,
 		postContractQuery,
 		postData,
 		postDataBlobPublish,
@@ -148,6 +152,8 @@ var routes = append(
 		postNodesSelf,
 		postOpRetry,
 		postPinsRewind,
+		postSSEAck,
+		postSubscriptionDeadLettersReplay,
 		postTokenApproval,
 		postTokenBurn,
 		postTokenMint,
@@ -162,6 +168,7 @@ var routes = append(
 func globalRoutes(routes []*ffapi.Route) []*ffapi.Route {
 	for _, route := range routes {
 		route.Tag = routeTagGlobal
+		withCursor(route)
 	}
 	return routes
 }
@@ -170,6 +177,7 @@ func namespacedRoutes(routes []*ffapi.Route) []*ffapi.Route {
 	newRoutes := make([]*ffapi.Route, len(routes))
 	for i, route := range routes {
 		route.Tag = routeTagDefaultNamespace
+		withCursor(route)
 
 		routeCopy := *route
 		routeCopy.Name += "Namespace"
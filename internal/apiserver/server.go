@@ -37,10 +37,12 @@ import (
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/events/eifactory"
+	"github.com/hyperledger/firefly/internal/events/sse"
 	"github.com/hyperledger/firefly/internal/events/websockets"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/namespace"
 	"github.com/hyperledger/firefly/internal/orchestrator"
+	"github.com/hyperledger/firefly/pkg/database"
 )
 
 var (
@@ -181,7 +183,9 @@ func (as *apiServer) swaggerHandler(generator func(req *http.Request) (*openapi3
 func (as *apiServer) swaggerGenerator(routes []*ffapi.Route, apiBaseURL string) func(req *http.Request) (*openapi3.T, error) {
 	swg := ffapi.NewSwaggerGen(as.swaggerGenConf(apiBaseURL))
 	return func(req *http.Request) (*openapi3.T, error) {
-		return swg.Generate(req.Context(), routes), nil
+		doc := swg.Generate(req.Context(), routes)
+		documentCursorHeader(req.Context(), doc)
+		return doc, nil
 	}
 }
 
@@ -254,9 +258,16 @@ func (as *apiServer) routeHandler(hf *ffapi.HandlerFactory, mgr namespace.Manage
 		cr := &coreRequest{
 			mgr:        mgr,
 			or:         or,
-			ctx:        r.Req.Context(),
+			ctx:        queryContext(r.Req, route),
 			apiBaseURL: apiBaseURL,
 		}
+		if route.FilterFactory != nil {
+			if _, ok := r.Req.URL.Query()["cursor"]; ok {
+				if keyset := database.CursorKeyset(cr.ctx, route.FilterFactory); keyset != nil {
+					return pageByCursor(r, cr, keyset, ce.CoreJSONHandler)
+				}
+			}
+		}
 		return ce.CoreJSONHandler(r, cr)
 	}
 	if ce.CoreFormUploadHandler != nil {
@@ -326,6 +337,10 @@ func (as *apiServer) createMuxRouter(ctx context.Context, mgr namespace.Manager)
 	ws.(*websockets.WebSockets).SetAuthorizer(mgr)
 	r.HandleFunc(`/ws`, ws.(*websockets.WebSockets).ServeHTTP)
 
+	se, _ := eifactory.GetPlugin(ctx, "sse")
+	se.(*sse.SSE).SetAuthorizer(mgr)
+	r.HandleFunc(`/sse`, se.(*sse.SSE).ServeHTTP).Methods(http.MethodGet)
+
 	uiPath := config.GetString(coreconfig.UIPath)
 	if uiPath != "" && config.GetBool(coreconfig.UIEnabled) {
 		r.PathPrefix(`/ui`).Handler(newStaticHandler(uiPath, "index.html", `/ui`))
@@ -28,6 +28,7 @@ var spiRoutes = append(globalRoutes([]*ffapi.Route{
 	spiPostReset,
 }),
 	namespacedRoutes([]*ffapi.Route{
+		spiGetNamespaceSnapshot,
 		spiGetOps,
 	})...,
 )
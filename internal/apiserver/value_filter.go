@@ -0,0 +1,63 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// buildValueFilter returns the filter on the values of data built from the "value.<path>" query parameters
+// of the request, or nil if there are none. These accept the same operators and modifiers as other filter fields.
+func buildValueFilter(ctx context.Context, r *ffapi.APIRequest) (ffapi.Filter, error) {
+	query := r.Req.URL.Query()
+	fields := []string{}
+	for name := range query {
+		if strings.HasPrefix(name, database.ValuePathPrefix) {
+			fields = append(fields, name)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	sort.Strings(fields)
+	qf, err := database.ValueQueryFactory(ctx, fields...)
+	if err != nil {
+		return nil, err
+	}
+	fb := qf.NewFilter(ctx)
+	filter := fb.And()
+	for _, field := range fields {
+		values := query[field]
+		conditions := make([]ffapi.Filter, len(values))
+		for i, value := range values {
+			if conditions[i], err = database.FilterCondition(ctx, fb, field, value); err != nil {
+				return nil, err
+			}
+		}
+		if len(conditions) == 1 {
+			filter.Condition(conditions[0])
+		} else {
+			filter.Condition(fb.Or(conditions...))
+		}
+	}
+	return filter, nil
+}
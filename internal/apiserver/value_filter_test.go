@@ -0,0 +1,89 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func valueFilterString(t *testing.T, query string) (string, error) {
+	r := &ffapi.APIRequest{Req: httptest.NewRequest("GET", "/api/v1/data?"+query, nil)}
+	filter, err := buildValueFilter(context.Background(), r)
+	if err != nil {
+		return "", err
+	}
+	if filter == nil {
+		return "", nil
+	}
+	fi, err := filter.Finalize()
+	assert.NoError(t, err)
+	return fi.String(), nil
+}
+
+func TestValueFilterOperators(t *testing.T) {
+	for query, expected := range map[string]string{
+		"value.a=b":                     "( value.a == 'b' )",
+		"value.a=!b":                    "( value.a != 'b' )",
+		"value.a=:b":                    "( value.a := 'b' )",
+		"value.a=!:b":                   "( value.a ;= 'b' )",
+		"value.a=?":                     "( value.a == null )",
+		"value.a=!?":                    "( value.a != null )",
+		"value.a=>=1":                   "( value.a >= '1' )",
+		"value.a=<=1":                   "( value.a <= '1' )",
+		"value.a=>1":                    "( value.a >> '1' )",
+		"value.a=>>1":                   "( value.a >> '1' )",
+		"value.a=<1":                    "( value.a << '1' )",
+		"value.a=<<1":                   "( value.a << '1' )",
+		"value.a=@b":                    "( value.a %= 'b' )",
+		"value.a=!@b":                   "( value.a !% 'b' )",
+		"value.a=:@b":                   "( value.a :% 'b' )",
+		"value.a=!:@b":                  "( value.a ;% 'b' )",
+		"value.a=^b":                    "( value.a ^= 'b' )",
+		"value.a=!^b":                   "( value.a !^ 'b' )",
+		"value.a=:^b":                   "( value.a :^ 'b' )",
+		"value.a=!:^b":                  "( value.a ;^ 'b' )",
+		"value.a=$b":                    "( value.a $= 'b' )",
+		"value.a=!$b":                   "( value.a !$ 'b' )",
+		"value.a=:$b":                   "( value.a :$ 'b' )",
+		"value.a=!:$b":                  "( value.a ;$ 'b' )",
+		"value.a==b":                    "( value.a == 'b' )",
+		"id=1":                          "",
+		"value.b=2&value.a=1&value.a=3": "( ( value.a == '1' ) || ( value.a == '3' ) ) && ( value.b == '2' )",
+	} {
+		u, err := url.ParseQuery(query)
+		assert.NoError(t, err)
+		s, err := valueFilterString(t, u.Encode())
+		assert.NoError(t, err, query)
+		assert.Equal(t, expected, s, query)
+	}
+}
+
+func TestValueFilterModifierOnComparison(t *testing.T) {
+	_, err := valueFilterString(t, url.Values{"value.a": []string{"!>1"}}.Encode())
+	assert.Regexp(t, "FF00193", err)
+}
+
+func TestValueFilterBadPath(t *testing.T) {
+	_, err := valueFilterString(t, url.Values{"value.a'b": []string{"1"}}.Encode())
+	assert.Regexp(t, "FF10503", err)
+}
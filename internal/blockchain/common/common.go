@@ -84,6 +84,7 @@ type BlockchainReceiptNotification struct {
 	Message          string                   `json:"errorMessage,omitempty"`
 	ProtocolID       string                   `json:"protocolId,omitempty"`
 	ContractLocation *fftypes.JSONAny         `json:"contractLocation,omitempty"`
+	ReturnValue      string                   `json:"returnValue,omitempty"` // only reported by some connectors
 }
 
 func NewBlockchainCallbacks() BlockchainCallbacks {
@@ -155,3 +155,83 @@ var networkVersionMethodABI = &abi.Entry{
 		},
 	},
 }
+
+// multicallSelfABI is the "multicall" method of a contract that extends the OpenZeppelin Multicall contract,
+// which delegatecalls itself for each call so that msg.sender is preserved
+var multicallSelfABI = &abi.Entry{
+	Name:            "multicall",
+	Type:            "function",
+	StateMutability: "nonpayable",
+	Inputs: abi.ParameterArray{
+		{
+			InternalType: "bytes[]",
+			Name:         "data",
+			Type:         "bytes[]",
+		},
+	},
+	Outputs: abi.ParameterArray{
+		{
+			InternalType: "bytes[]",
+			Name:         "results",
+			Type:         "bytes[]",
+		},
+	},
+}
+
+// multicallResultOffsetsABI reads the Result[] returned by "aggregate3" as the offsets of each of its tuples
+var multicallResultOffsetsABI = abi.ParameterArray{
+	{
+		Name: "offsets",
+		Type: "uint256[]",
+	},
+}
+
+// multicallAggregate3ABI is the "aggregate3" method of the Multicall3 contract
+var multicallAggregate3ABI = &abi.Entry{
+	Name:            "aggregate3",
+	Type:            "function",
+	StateMutability: "payable",
+	Inputs: abi.ParameterArray{
+		{
+			InternalType: "struct Multicall3.Call3[]",
+			Name:         "calls",
+			Type:         "tuple[]",
+			Components: abi.ParameterArray{
+				{
+					InternalType: "address",
+					Name:         "target",
+					Type:         "address",
+				},
+				{
+					InternalType: "bool",
+					Name:         "allowFailure",
+					Type:         "bool",
+				},
+				{
+					InternalType: "bytes",
+					Name:         "callData",
+					Type:         "bytes",
+				},
+			},
+		},
+	},
+	Outputs: abi.ParameterArray{
+		{
+			InternalType: "struct Multicall3.Result[]",
+			Name:         "returnData",
+			Type:         "tuple[]",
+			Components: abi.ParameterArray{
+				{
+					InternalType: "bool",
+					Name:         "success",
+					Type:         "bool",
+				},
+				{
+					InternalType: "bytes",
+					Name:         "returnData",
+					Type:         "bytes",
+				},
+			},
+		},
+	},
+}
@@ -69,6 +69,13 @@ const (
 
 	// FFTMConfigKey is a sub-key in the config that optionally contains FireFly transaction connection information
 	FFTMConfigKey = "fftm"
+
+	// MulticallConfigKey is a sub-key in the config to contain the configuration of a multicall contract
+	MulticallConfigKey = "multicall"
+	// MulticallConfigAddress is the address of a Multicall3 compatible contract, used to submit atomic batches of contract invocations
+	MulticallConfigAddress = "address"
+	// MulticallConfigPreserveSender submits atomic batches to the multicall method of the target contract itself, so that msg.sender is the signing key
+	MulticallConfigPreserveSender = "preserveSender"
 )
 
 func (e *Ethereum) InitConfig(config config.Section) {
@@ -93,4 +100,8 @@ func (e *Ethereum) InitConfig(config config.Section) {
 	addressResolverConf.AddKnownKey(AddressResolverURLTemplate)
 	addressResolverConf.AddKnownKey(AddressResolverBodyTemplate)
 	addressResolverConf.AddKnownKey(AddressResolverResponseField, defaultAddressResolverResponseField)
+
+	multicallConf := config.SubSection(MulticallConfigKey)
+	multicallConf.AddKnownKey(MulticallConfigAddress)
+	multicallConf.AddKnownKey(MulticallConfigPreserveSender, false)
 }
@@ -21,6 +21,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"regexp"
 	"strconv"
 	"strings"
@@ -71,6 +72,8 @@ type Ethereum struct {
 	closed               chan struct{}
 	addressResolveAlways bool
 	addressResolver      *addressResolver
+	multicallAddress     string
+	multicallSelf        bool
 	metrics              metrics.Manager
 	ethconnectConf       config.Section
 	subs                 common.FireflySubscriptions
@@ -85,6 +88,10 @@ type queryOutput struct {
 	Output interface{} `json:"output"`
 }
 
+type blockNumberOutput struct {
+	BlockNumber interface{} `json:"blockNumber"`
+}
+
 type ethWSCommandPayload struct {
 	Type        string `json:"type"`
 	Topic       string `json:"topic,omitempty"`
@@ -105,11 +112,6 @@ type ListenerCheckpoint struct {
 	LogIndex         int64 `json:"logIndex"`
 }
 
-type ListenerStatus struct {
-	Checkpoint ListenerCheckpoint `json:"checkpoint"`
-	Catchup    bool               `json:"catchup"`
-}
-
 type EthconnectMessageRequest struct {
 	Headers EthconnectMessageHeaders `json:"headers,omitempty"`
 	To      string                   `json:"to"`
@@ -157,6 +159,17 @@ func (e *Ethereum) Init(ctx context.Context, cancelCtx context.CancelFunc, conf
 		}
 	}
 
+	if multicallAddress := conf.SubSection(MulticallConfigKey).GetString(MulticallConfigAddress); multicallAddress != "" {
+		if e.multicallAddress, err = formatEthAddress(ctx, multicallAddress); err != nil {
+			return err
+		}
+		e.capabilities.AtomicInvokeBatch = true
+	}
+	if conf.SubSection(MulticallConfigKey).GetBool(MulticallConfigPreserveSender) {
+		e.multicallSelf = true
+		e.capabilities.AtomicInvokeBatch = true
+	}
+
 	if ethconnectConf.GetString(ffresty.HTTPConfigURL) == "" {
 		return i18n.NewError(ctx, coremsgs.MsgMissingPluginConfig, "url", ethconnectConf)
 	}
@@ -772,6 +785,123 @@ func (e *Ethereum) InvokeContract(ctx context.Context, nsOpID string, signingKey
 	return e.invokeContractMethod(ctx, ethereumLocation.Address, signingKey, abi, nsOpID, orderedInput, errorsAbi, options)
 }
 
+// InvokeContractBatch packs the calls into a single transaction to the "aggregate3" method of the configured
+// multicall contract, which reverts the whole transaction if any of the calls fail.
+// Note that each call is made by the multicall contract, rather than directly by the signing key. If the
+// sender is preserved, the calls must all target one contract, and are packed into its own "multicall" method.
+func (e *Ethereum) InvokeContractBatch(ctx context.Context, nsOpID, signingKey string, calls []*blockchain.ContractCall, options map[string]interface{}) error {
+	if e.multicallAddress == "" && !e.multicallSelf {
+		return i18n.NewError(ctx, coremsgs.MsgContractBatchAtomicNotSupported, e.Name())
+	}
+	targets := make([]string, len(calls))
+	callData := make([]string, len(calls))
+	for i, call := range calls {
+		ethereumLocation, err := e.parseContractLocation(ctx, call.Location)
+		if err != nil {
+			return err
+		}
+		abi, _, orderedInput, err := e.prepareRequest(ctx, call.Method, call.Errors, call.Input)
+		if err != nil {
+			return err
+		}
+		encoded, err := abi.EncodeCallDataValuesCtx(ctx, orderedInput)
+		if err != nil {
+			return err
+		}
+		targets[i] = ethereumLocation.Address
+		callData[i] = "0x" + hex.EncodeToString(encoded)
+	}
+
+	if e.multicallSelf {
+		for i, target := range targets {
+			if !strings.EqualFold(target, targets[0]) {
+				return i18n.NewError(ctx, coremsgs.MsgContractBatchSingleTarget, i)
+			}
+		}
+		return e.invokeContractMethod(ctx, targets[0], signingKey, multicallSelfABI, nsOpID, []interface{}{callData}, nil, options)
+	}
+
+	multicallInput := make([]interface{}, len(calls))
+	for i := range calls {
+		multicallInput[i] = map[string]interface{}{
+			"target":       targets[i],
+			"allowFailure": false,
+			"callData":     callData[i],
+		}
+	}
+	return e.invokeContractMethod(ctx, e.multicallAddress, signingKey, multicallAggregate3ABI, nsOpID, []interface{}{multicallInput}, nil, options)
+}
+
+// DecodeInvokeBatchOutput decodes the return value of the multicall method, which holds the return data of each call,
+// using the outputs of the method of each call
+func (e *Ethereum) DecodeInvokeBatchOutput(ctx context.Context, calls []*blockchain.ContractCall, output fftypes.JSONObject) ([]*fftypes.JSONAny, error) {
+	returnValue := output.GetString("returnValue")
+	if returnValue == "" {
+		return nil, nil
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(returnValue, "0x"))
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgContractBatchOutputInvalid)
+	}
+	returnData, err := e.decodeMulticallReturnData(ctx, b)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgContractBatchOutputInvalid)
+	}
+	if len(returnData) != len(calls) {
+		return nil, i18n.NewError(ctx, coremsgs.MsgContractBatchOutputInvalid)
+	}
+
+	serializer := abi.NewSerializer().SetByteSerializer(abi.HexByteSerializer0xPrefix)
+	results := make([]*fftypes.JSONAny, len(calls))
+	for i, call := range calls {
+		methodABI, err := ffi2abi.ConvertFFIMethodToABI(ctx, call.Method)
+		if err != nil {
+			return nil, err
+		}
+		callOutput, err := methodABI.Outputs.DecodeABIDataCtx(ctx, returnData[i], 0)
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, coremsgs.MsgContractBatchOutputInvalid)
+		}
+		b, _ := serializer.SerializeJSONCtx(ctx, callOutput) // the values of decoded ABI data always serialize
+		results[i] = fftypes.JSONAnyPtrBytes(b)
+	}
+	return results, nil
+}
+
+// decodeMulticallReturnData returns the data returned by each call, from the return value of the multicall method.
+// Multicall3 returns a (success, returnData) tuple for each call, but the ABI decoder does not handle arrays of
+// dynamic tuples - so the offsets of the tuples are decoded as an array of integers, and each tuple decoded in turn.
+func (e *Ethereum) decodeMulticallReturnData(ctx context.Context, b []byte) ([][]byte, error) {
+	if e.multicallSelf {
+		cv, err := multicallSelfABI.Outputs.DecodeABIDataCtx(ctx, b, 0)
+		if err != nil {
+			return nil, err
+		}
+		returnData := make([][]byte, len(cv.Children[0].Children))
+		for i, data := range cv.Children[0].Children {
+			returnData[i] = data.Value.([]byte)
+		}
+		return returnData, nil
+	}
+
+	cv, err := multicallResultOffsetsABI.DecodeABIDataCtx(ctx, b, 0)
+	if err != nil {
+		return nil, err
+	}
+	// The offsets are relative to the start of the array content, which follows its length
+	arrayStart := new(big.Int).SetBytes(b[0:32]).Int64() + 32
+	offsets := cv.Children[0].Children
+	returnData := make([][]byte, len(offsets))
+	for i, offset := range offsets {
+		result, err := multicallAggregate3ABI.Outputs[0].Components.DecodeABIDataCtx(ctx, b, int(arrayStart+offset.Value.(*big.Int).Int64()))
+		if err != nil {
+			return nil, err
+		}
+		returnData[i] = result.Children[1].Value.([]byte)
+	}
+	return returnData, nil
+}
+
 func (e *Ethereum) QueryContract(ctx context.Context, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}, errors []*fftypes.FFIError, options map[string]interface{}) (interface{}, error) {
 	ethereumLocation, err := e.parseContractLocation(ctx, location)
 	if err != nil {
@@ -823,17 +953,57 @@ func (e *Ethereum) encodeContractLocation(ctx context.Context, location *Locatio
 	return result, err
 }
 
-func (e *Ethereum) AddContractListener(ctx context.Context, listener *core.ContractListener) (err error) {
-	var location *Location
-	if listener.Location != nil {
-		location, err = e.parseContractLocation(ctx, listener.Location)
+func (e *Ethereum) buildEventFilter(ctx context.Context, event *core.FFISerializedEvent, location *fftypes.JSONAny, indexed fftypes.JSONObject) (*eventFilter, error) {
+	filter := &eventFilter{}
+	if location != nil {
+		parsed, err := e.parseContractLocation(ctx, location)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		filter.Address = parsed.Address
 	}
-	abi, err := ffi2abi.ConvertFFIEventDefinitionToABI(ctx, &listener.Event.FFIEventDefinition)
+	abi, err := ffi2abi.ConvertFFIEventDefinitionToABI(ctx, &event.FFIEventDefinition)
 	if err != nil {
-		return i18n.WrapError(ctx, err, coremsgs.MsgContractParamInvalid)
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgContractParamInvalid)
+	}
+	filter.Event = abi
+
+	// The values of indexed parameters are matched by FireFly as events are received,
+	// so only check here that each one is actually indexed
+	for name := range indexed {
+		isIndexed := false
+		for _, input := range abi.Inputs {
+			if input.Name == name {
+				isIndexed = input.Indexed
+				break
+			}
+		}
+		if !isIndexed {
+			return nil, i18n.NewError(ctx, coremsgs.MsgListenerFilterNotIndexed, name, event.Name)
+		}
+	}
+	return filter, nil
+}
+
+func (e *Ethereum) AddContractListener(ctx context.Context, listener *core.ContractListener) (err error) {
+	var filters []*eventFilter
+	if len(listener.Filters) > 1 {
+		filters = make([]*eventFilter, len(listener.Filters))
+		for i, f := range listener.Filters {
+			if filters[i], err = e.buildEventFilter(ctx, f.Event, f.Location, f.Indexed); err != nil {
+				return err
+			}
+		}
+	} else {
+		var indexed fftypes.JSONObject
+		if len(listener.Filters) == 1 {
+			indexed = listener.Filters[0].Indexed
+		}
+		filter, err := e.buildEventFilter(ctx, listener.Event, listener.Location, indexed)
+		if err != nil {
+			return err
+		}
+		filters = []*eventFilter{filter}
 	}
 
 	subName := fmt.Sprintf("ff-sub-%s-%s", listener.Namespace, listener.ID)
@@ -841,7 +1011,7 @@ func (e *Ethereum) AddContractListener(ctx context.Context, listener *core.Contr
 	if listener.Options != nil {
 		firstEvent = listener.Options.FirstEvent
 	}
-	result, err := e.streams.createSubscription(ctx, location, e.streamID, subName, firstEvent, abi)
+	result, err := e.streams.createSubscription(ctx, e.streamID, subName, firstEvent, filters...)
 	if err != nil {
 		return err
 	}
@@ -853,22 +1023,56 @@ func (e *Ethereum) DeleteContractListener(ctx context.Context, subscription *cor
 	return e.streams.deleteSubscription(ctx, subscription.BackendID, okNotFound)
 }
 
-func (e *Ethereum) GetContractListenerStatus(ctx context.Context, subID string, okNotFound bool) (found bool, status interface{}, err error) {
+func (e *Ethereum) GetContractListenerStatus(ctx context.Context, subID string, okNotFound bool) (found bool, status *core.ContractListenerStatus, err error) {
 	sub, err := e.streams.getSubscription(ctx, subID, okNotFound)
 	if err != nil || sub == nil {
 		return false, nil, err
 	}
 
-	checkpoint := &ListenerStatus{
+	status = &core.ContractListenerStatus{
 		Catchup: sub.Catchup,
-		Checkpoint: ListenerCheckpoint{
+		Checkpoint: &core.ContractListenerCheckpoint{
 			Block:            sub.Checkpoint.Block,
 			TransactionIndex: sub.Checkpoint.TransactionIndex,
 			LogIndex:         sub.Checkpoint.LogIndex,
 		},
 	}
 
-	return true, checkpoint, nil
+	// The head of the chain is requested from the connector. This is only used to report progress, so failures
+	// (including from connectors that do not support the request) are logged, and the head and lag are omitted.
+	head, err := e.queryBlockNumber(ctx)
+	if err != nil {
+		log.L(ctx).Warnf("Unable to determine the head of the chain for listener %s: %s", subID, err)
+		return true, status, nil
+	}
+	lag := head - sub.Checkpoint.Block
+	if lag < 0 {
+		lag = 0
+	}
+	status.HeadBlock = &head
+	status.Lag = &lag
+	return true, status, nil
+}
+
+func (e *Ethereum) queryBlockNumber(ctx context.Context) (int64, error) {
+	var output blockNumberOutput
+	var resErr ethError
+	res, err := e.client.R().
+		SetContext(ctx).
+		SetBody(map[string]interface{}{
+			"headers": EthconnectMessageHeaders{Type: "GetBlockNumber"},
+		}).
+		SetResult(&output).
+		SetError(&resErr).
+		Post("/")
+	if err != nil || !res.IsSuccess() {
+		return -1, wrapError(ctx, &resErr, res, err)
+	}
+	blockNumber, ok := output.BlockNumber.(string)
+	if !ok {
+		return -1, i18n.NewError(ctx, coremsgs.MsgBadBlockNumber, output.BlockNumber)
+	}
+	return strconv.ParseInt(blockNumber, 10, 64)
 }
 
 func (e *Ethereum) GetFFIParamValidator(ctx context.Context) (fftypes.FFIParamValidator, error) {
@@ -883,6 +1087,22 @@ func (e *Ethereum) GenerateEventSignature(ctx context.Context, event *fftypes.FF
 	return ffi2abi.ABIMethodToSignature(abi)
 }
 
+func (e *Ethereum) GenerateEventSignatureWithLocation(ctx context.Context, event *fftypes.FFIEventDefinition, location *fftypes.JSONAny) (string, error) {
+	signature := e.GenerateEventSignature(ctx, event)
+	if location == nil {
+		return signature, nil
+	}
+	parsed, err := e.parseContractLocation(ctx, location)
+	if err != nil {
+		return "", err
+	}
+	address, err := formatEthAddress(ctx, parsed.Address)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%s", e.buildEventLocationString(fftypes.JSONObject{"address": address}), signature), nil
+}
+
 func (e *Ethereum) GenerateErrorSignature(ctx context.Context, errorDef *fftypes.FFIErrorDefinition) string {
 	abi, err := ffi2abi.ConvertFFIErrorDefinitionToABI(ctx, errorDef)
 	if err != nil {
@@ -18,6 +18,7 @@ package ethereum
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -51,6 +52,7 @@ var utConfig = config.RootSection("eth_unit_tests")
 var utEthconnectConf = utConfig.SubSection(EthconnectConfigKey)
 var utAddressResolverConf = utConfig.SubSection(AddressResolverConfigKey)
 var utFFTMConf = utConfig.SubSection(FFTMConfigKey)
+var utMulticallConf = utConfig.SubSection(MulticallConfigKey)
 
 func testFFIMethod() *fftypes.FFIMethod {
 	return &fftypes.FFIMethod{
@@ -185,6 +187,30 @@ func TestInitBadAddressResolver(t *testing.T) {
 	assert.Regexp(t, "FF10337.*urlTemplate", err)
 }
 
+func TestInitBadMulticallAddress(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	resetConf(e)
+	cmi := &cachemocks.Manager{}
+	cmi.On("GetCache", mock.Anything).Return(cache.NewUmanagedCache(e.ctx, 100, 5*time.Minute), nil)
+	utMulticallConf.Set(MulticallConfigAddress, "bad")
+	err := e.Init(e.ctx, e.cancelCtx, utConfig, e.metrics, cmi)
+	assert.Regexp(t, "FF10141", err)
+}
+
+func TestInitMulticallPreserveSender(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	resetConf(e)
+	cmi := &cachemocks.Manager{}
+	cmi.On("GetCache", mock.Anything).Return(cache.NewUmanagedCache(e.ctx, 100, 5*time.Minute), nil)
+	utMulticallConf.Set(MulticallConfigPreserveSender, true)
+	err := e.Init(e.ctx, e.cancelCtx, utConfig, e.metrics, cmi)
+	assert.Regexp(t, "FF10138.*url", err)
+	assert.True(t, e.multicallSelf)
+	assert.True(t, e.Capabilities().AtomicInvokeBatch)
+}
+
 func TestInitMissingTopic(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -226,6 +252,7 @@ func TestInitAndStartWithEthConnect(t *testing.T) {
 	utEthconnectConf.Set(EthconnectConfigInstanceDeprecated, "/instances/0x71C7656EC7ab88b098defB751B7401B5f6d8976F")
 	utEthconnectConf.Set(EthconnectConfigTopic, "topic1")
 	utFFTMConf.Set(ffresty.HTTPConfigURL, "http://ethc.example.com:12345")
+	utMulticallConf.Set(MulticallConfigAddress, "0xcA11bde05977b3631167028862bE2a173976CA11")
 
 	cmi := &cachemocks.Manager{}
 	cmi.On("GetCache", mock.Anything).Return(cache.NewUmanagedCache(e.ctx, 100, 5*time.Minute), nil)
@@ -239,7 +266,8 @@ func TestInitAndStartWithEthConnect(t *testing.T) {
 
 	assert.Equal(t, 2, httpmock.GetTotalCallCount())
 	assert.Equal(t, "es12345", e.streamID)
-	assert.NotNil(t, e.Capabilities())
+	assert.True(t, e.Capabilities().AtomicInvokeBatch)
+	assert.Equal(t, "0xca11bde05977b3631167028862be2a173976ca11", e.multicallAddress)
 
 	err = e.Start()
 	assert.NoError(t, err)
@@ -1529,6 +1557,7 @@ func TestHandleReceiptTXSuccess(t *testing.T) {
     },
 		"nonce": "0",
 		"receivedAt": 1622235271565,
+		"returnValue": "0x1234",
 		"status": "1",
 		"to": "0xd3266a857285fb75eb7df37353b4a15c8bb828f5",
 		"transactionHash": "0x71a38acb7a5d4a970854f6d638ceb1fa10a4b59cbf4ed7674273a1a8dc8b36b8",
@@ -1539,6 +1568,7 @@ func TestHandleReceiptTXSuccess(t *testing.T) {
 		return update.NamespacedOpID == "ns1:"+operationID.String() &&
 			update.Status == core.OpStatusSucceeded &&
 			update.BlockchainTXID == "0x71a38acb7a5d4a970854f6d638ceb1fa10a4b59cbf4ed7674273a1a8dc8b36b8" &&
+			update.Output.GetString("returnValue") == "0x1234" &&
 			update.Plugin == "ethereum"
 	})).Return(nil)
 
@@ -1862,6 +1892,130 @@ func TestAddSubscriptionFail(t *testing.T) {
 	assert.Regexp(t, "pop", err)
 }
 
+func TestAddSubscriptionMultipleFilters(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streamID = "es-1"
+	e.streams = &streamManager{
+		client: e.client,
+	}
+
+	transfer := &core.FFISerializedEvent{
+		FFIEventDefinition: fftypes.FFIEventDefinition{
+			Name: "Transfer",
+			Params: fftypes.FFIParams{
+				{
+					Name:   "from",
+					Schema: fftypes.JSONAnyPtr(`{"type": "string", "details": {"type": "address", "indexed": true}}`),
+				},
+				{
+					Name:   "value",
+					Schema: fftypes.JSONAnyPtr(`{"type": "integer", "details": {"type": "uint256"}}`),
+				},
+			},
+		},
+	}
+	changed := &core.FFISerializedEvent{
+		FFIEventDefinition: fftypes.FFIEventDefinition{
+			Name: "Changed",
+			Params: fftypes.FFIParams{
+				{
+					Name:   "value",
+					Schema: fftypes.JSONAnyPtr(`{"type": "string", "details": {"type": "string"}}`),
+				},
+			},
+		},
+	}
+	sub := &core.ContractListener{
+		Namespace: "ns1",
+		ID:        fftypes.NewUUID(),
+		Filters: core.ListenerFilters{
+			{
+				Event: transfer,
+				Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+					"address": "0x123",
+				}.String()),
+				Indexed: fftypes.JSONObject{"from": "0x456"},
+			},
+			{
+				Event: changed,
+			},
+		},
+	}
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			assert.Nil(t, body["event"])
+			filters := body["filters"].([]interface{})
+			assert.Len(t, filters, 2)
+			assert.Equal(t, "0x123", filters[0].(map[string]interface{})["address"])
+			assert.Equal(t, "Transfer", filters[0].(map[string]interface{})["event"].(map[string]interface{})["name"])
+			assert.Nil(t, filters[1].(map[string]interface{})["address"])
+			assert.Equal(t, "Changed", filters[1].(map[string]interface{})["event"].(map[string]interface{})["name"])
+			return httpmock.NewJsonResponderOrPanic(200, &subscription{ID: "sub1"})(req)
+		})
+
+	err := e.AddContractListener(context.Background(), sub)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "sub1", sub.BackendID)
+}
+
+func TestAddSubscriptionMultipleFiltersBadLocation(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+
+	sub := &core.ContractListener{
+		Filters: core.ListenerFilters{
+			{
+				Event:    &core.FFISerializedEvent{},
+				Location: fftypes.JSONAnyPtr(""),
+			},
+			{
+				Event: &core.FFISerializedEvent{},
+			},
+		},
+	}
+
+	err := e.AddContractListener(context.Background(), sub)
+
+	assert.Regexp(t, "FF10310", err)
+}
+
+func TestAddSubscriptionFilterNotIndexed(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+
+	event := &core.FFISerializedEvent{
+		FFIEventDefinition: fftypes.FFIEventDefinition{
+			Name: "Changed",
+			Params: fftypes.FFIParams{
+				{
+					Name:   "value",
+					Schema: fftypes.JSONAnyPtr(`{"type": "string", "details": {"type": "string"}}`),
+				},
+			},
+		},
+	}
+	sub := &core.ContractListener{
+		Event: event,
+		Filters: core.ListenerFilters{
+			{
+				Event:   event,
+				Indexed: fftypes.JSONObject{"value": "abc"},
+			},
+		},
+	}
+
+	err := e.AddContractListener(context.Background(), sub)
+
+	assert.Regexp(t, "FF10515.*value.*Changed", err)
+}
+
 func TestDeleteSubscription(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -2574,6 +2728,248 @@ func TestInvokeContractInvalidInput(t *testing.T) {
 	assert.Regexp(t, "unsupported type", err)
 }
 
+func testMulticallCalls() []*blockchain.ContractCall {
+	return []*blockchain.ContractCall{
+		{
+			Location: fftypes.JSONAnyPtr(`{"address":"0x1111111111111111111111111111111111111111"}`),
+			Method:   testFFIMethod(),
+			Input:    map[string]interface{}{"x": float64(1), "y": "2"},
+			Errors:   testFFIErrors(),
+		},
+		{
+			Location: fftypes.JSONAnyPtr(`{"address":"0x2222222222222222222222222222222222222222"}`),
+			Method:   testFFIPinMethod(),
+			Input:    map[string]interface{}{"data": "0xfeed"},
+		},
+	}
+}
+
+func TestInvokeContractBatchOK(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.multicallAddress = "0xca11bde05977b3631167028862be2a173976ca11"
+	signingKey := ethHexFormatB32(fftypes.NewRandB32())
+	options := map[string]interface{}{
+		"customOption": "customValue",
+	}
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			headers := body["headers"].(map[string]interface{})
+			assert.Equal(t, "SendTransaction", headers["type"])
+			assert.Equal(t, "ns1:op1", headers["id"])
+			assert.Equal(t, "0xca11bde05977b3631167028862be2a173976ca11", body["to"])
+			assert.Equal(t, signingKey, body["from"])
+			assert.Equal(t, "aggregate3", body["method"].(map[string]interface{})["name"])
+			assert.Equal(t, "customValue", body["customOption"])
+			params := body["params"].([]interface{})
+			assert.Len(t, params, 1)
+			calls := params[0].([]interface{})
+			assert.Len(t, calls, 2)
+			assert.Equal(t, map[string]interface{}{
+				"target":       "0x1111111111111111111111111111111111111111",
+				"allowFailure": false,
+				"callData": "0xcad0899b" +
+					"0000000000000000000000000000000000000000000000000000000000000001" +
+					"0000000000000000000000000000000000000000000000000000000000000002",
+			}, calls[0])
+			assert.Equal(t, "0x2222222222222222222222222222222222222222", calls[1].(map[string]interface{})["target"])
+			return httpmock.NewJsonResponderOrPanic(200, "")(req)
+		})
+	err := e.InvokeContractBatch(context.Background(), "ns1:op1", signingKey, testMulticallCalls(), options)
+	assert.NoError(t, err)
+}
+
+func TestInvokeContractBatchPreserveSender(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.multicallSelf = true
+	signingKey := ethHexFormatB32(fftypes.NewRandB32())
+	calls := testMulticallCalls()
+	calls[1].Location = fftypes.JSONAnyPtr(`{"address":"0x1111111111111111111111111111111111111111"}`)
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			assert.Equal(t, "0x1111111111111111111111111111111111111111", body["to"])
+			assert.Equal(t, signingKey, body["from"])
+			assert.Equal(t, "multicall", body["method"].(map[string]interface{})["name"])
+			params := body["params"].([]interface{})
+			assert.Len(t, params, 1)
+			data := params[0].([]interface{})
+			assert.Len(t, data, 2)
+			assert.Equal(t, "0xcad0899b"+
+				"0000000000000000000000000000000000000000000000000000000000000001"+
+				"0000000000000000000000000000000000000000000000000000000000000002", data[0])
+			return httpmock.NewJsonResponderOrPanic(200, "")(req)
+		})
+	err := e.InvokeContractBatch(context.Background(), "ns1:op1", signingKey, calls, nil)
+	assert.NoError(t, err)
+}
+
+func TestInvokeContractBatchPreserveSenderMultipleTargets(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	e.multicallSelf = true
+	err := e.InvokeContractBatch(context.Background(), "ns1:op1", "0x12345", testMulticallCalls(), nil)
+	assert.Regexp(t, "FF10529.*1", err)
+}
+
+func TestDecodeInvokeBatchOutput(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	returnValue, err := multicallAggregate3ABI.Outputs.EncodeABIDataValues([]interface{}{
+		[]interface{}{
+			[]interface{}{true, "0x000000000000000000000000000000000000000000000000000000000000002a"},
+			[]interface{}{true, "0x"},
+		},
+	})
+	assert.NoError(t, err)
+	results, err := e.DecodeInvokeBatchOutput(context.Background(), testMulticallCalls(), fftypes.JSONObject{
+		"returnValue": "0x" + hex.EncodeToString(returnValue),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.JSONEq(t, `{"z":"42"}`, results[0].String())
+	assert.JSONEq(t, `{}`, results[1].String())
+}
+
+func TestDecodeInvokeBatchOutputPreserveSender(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	e.multicallSelf = true
+	returnValue, err := multicallSelfABI.Outputs.EncodeABIDataValues([]interface{}{
+		[]interface{}{"0x000000000000000000000000000000000000000000000000000000000000002a", "0x"},
+	})
+	assert.NoError(t, err)
+	results, err := e.DecodeInvokeBatchOutput(context.Background(), testMulticallCalls(), fftypes.JSONObject{
+		"returnValue": "0x" + hex.EncodeToString(returnValue),
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"z":"42"}`, results[0].String())
+}
+
+func TestDecodeInvokeBatchOutputNoReturnValue(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	results, err := e.DecodeInvokeBatchOutput(context.Background(), testMulticallCalls(), fftypes.JSONObject{})
+	assert.NoError(t, err)
+	assert.Nil(t, results)
+}
+
+func TestDecodeInvokeBatchOutputBadReturnValue(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	_, err := e.DecodeInvokeBatchOutput(context.Background(), testMulticallCalls(), fftypes.JSONObject{"returnValue": "not hex"})
+	assert.Regexp(t, "FF10530", err)
+	_, err = e.DecodeInvokeBatchOutput(context.Background(), testMulticallCalls(), fftypes.JSONObject{"returnValue": "0x1234"})
+	assert.Regexp(t, "FF10530", err)
+	// A single Result, with no data where the tuple should be
+	_, err = e.DecodeInvokeBatchOutput(context.Background(), testMulticallCalls(), fftypes.JSONObject{"returnValue": "0x" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000001" +
+		"0000000000000000000000000000000000000000000000000000000000000020"})
+	assert.Regexp(t, "FF10530", err)
+	e.multicallSelf = true
+	_, err = e.DecodeInvokeBatchOutput(context.Background(), testMulticallCalls(), fftypes.JSONObject{"returnValue": "0x1234"})
+	assert.Regexp(t, "FF10530", err)
+}
+
+func TestDecodeInvokeBatchOutputWrongCount(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	returnValue, err := multicallAggregate3ABI.Outputs.EncodeABIDataValues([]interface{}{
+		[]interface{}{[]interface{}{true, "0x"}},
+	})
+	assert.NoError(t, err)
+	_, err = e.DecodeInvokeBatchOutput(context.Background(), testMulticallCalls(), fftypes.JSONObject{
+		"returnValue": "0x" + hex.EncodeToString(returnValue),
+	})
+	assert.Regexp(t, "FF10530", err)
+}
+
+func TestDecodeInvokeBatchOutputBadCallData(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	returnValue, err := multicallAggregate3ABI.Outputs.EncodeABIDataValues([]interface{}{
+		[]interface{}{[]interface{}{true, "0x2a"}, []interface{}{true, "0x"}},
+	})
+	assert.NoError(t, err)
+	_, err = e.DecodeInvokeBatchOutput(context.Background(), testMulticallCalls(), fftypes.JSONObject{
+		"returnValue": "0x" + hex.EncodeToString(returnValue),
+	})
+	assert.Regexp(t, "FF10530", err)
+}
+
+func TestDecodeInvokeBatchOutputBadMethod(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	returnValue, err := multicallAggregate3ABI.Outputs.EncodeABIDataValues([]interface{}{
+		[]interface{}{[]interface{}{true, "0x"}, []interface{}{true, "0x"}},
+	})
+	assert.NoError(t, err)
+	calls := testMulticallCalls()
+	calls[0].Method.Returns[0].Schema = fftypes.JSONAnyPtr(`{"type":"string","details":{"type":"unknown"}}`)
+	_, err = e.DecodeInvokeBatchOutput(context.Background(), calls, fftypes.JSONObject{
+		"returnValue": "0x" + hex.EncodeToString(returnValue),
+	})
+	assert.Error(t, err)
+}
+
+func TestInvokeContractBatchNotConfigured(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	err := e.InvokeContractBatch(context.Background(), "ns1:op1", "0x12345", testMulticallCalls(), nil)
+	assert.Regexp(t, "FF10525", err)
+}
+
+func TestInvokeContractBatchBadLocation(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	e.multicallAddress = "0xca11bde05977b3631167028862be2a173976ca11"
+	calls := testMulticallCalls()
+	calls[1].Location = fftypes.JSONAnyPtr(`{}`)
+	err := e.InvokeContractBatch(context.Background(), "ns1:op1", "0x12345", calls, nil)
+	assert.Regexp(t, "'address' not set", err)
+}
+
+func TestInvokeContractBatchBadMethod(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	e.multicallAddress = "0xca11bde05977b3631167028862be2a173976ca11"
+	calls := testMulticallCalls()
+	calls[0].Method.Params[0].Schema = fftypes.JSONAnyPtr(`{"type":"string","details":{"type":"unknown"}}`)
+	err := e.InvokeContractBatch(context.Background(), "ns1:op1", "0x12345", calls, nil)
+	assert.Error(t, err)
+}
+
+func TestInvokeContractBatchBadInput(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	e.multicallAddress = "0xca11bde05977b3631167028862be2a173976ca11"
+	calls := testMulticallCalls()
+	calls[0].Input["x"] = "not a number"
+	err := e.InvokeContractBatch(context.Background(), "ns1:op1", "0x12345", calls, nil)
+	assert.Regexp(t, "FF22030", err)
+}
+
+func TestInvokeContractBatchEthconnectError(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.multicallAddress = "0xca11bde05977b3631167028862be2a173976ca11"
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		httpmock.NewJsonResponderOrPanic(400, ""))
+	err := e.InvokeContractBatch(context.Background(), "ns1:op1", "0x12345", testMulticallCalls(), nil)
+	assert.Regexp(t, "FF10111", err)
+}
+
 func TestInvokeContractAddressNotSet(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -2972,6 +3368,39 @@ func TestGenerateEventSignatureInvalid(t *testing.T) {
 	assert.Equal(t, "", signature)
 }
 
+func TestGenerateEventSignatureWithLocation(t *testing.T) {
+	e, _ := newTestEthereum()
+	event := &fftypes.FFIEventDefinition{
+		Name: "Changed",
+		Params: []*fftypes.FFIParam{
+			{
+				Name:   "x",
+				Schema: fftypes.JSONAnyPtr(`{"type": "integer", "details": {"type": "uint256"}}`),
+			},
+		},
+	}
+
+	signature, err := e.GenerateEventSignatureWithLocation(context.Background(), event, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Changed(uint256)", signature)
+
+	location := fftypes.JSONAnyPtr(fftypes.JSONObject{"address": "3081D84FD367044F4ED453F2024709242470388C"}.String())
+	signature, err = e.GenerateEventSignatureWithLocation(context.Background(), event, location)
+	assert.NoError(t, err)
+	assert.Equal(t, "address=0x3081d84fd367044f4ed453f2024709242470388c:Changed(uint256)", signature)
+}
+
+func TestGenerateEventSignatureWithLocationBadLocation(t *testing.T) {
+	e, _ := newTestEthereum()
+	event := &fftypes.FFIEventDefinition{Name: "Changed"}
+
+	_, err := e.GenerateEventSignatureWithLocation(context.Background(), event, fftypes.JSONAnyPtr(""))
+	assert.Regexp(t, "FF10310", err)
+
+	_, err = e.GenerateEventSignatureWithLocation(context.Background(), event, fftypes.JSONAnyPtr(`{"address":"bad"}`))
+	assert.Regexp(t, "FF10141", err)
+}
+
 func TestGenerateErrorSignature(t *testing.T) {
 	e, _ := newTestEthereum()
 	complexParam := fftypes.JSONObject{
@@ -3746,6 +4175,91 @@ func TestGetContractListenerStatus(t *testing.T) {
 	assert.True(t, found)
 }
 
+func newTestListenerStatusEthereum(checkpointBlock int64) (*Ethereum, func()) {
+	e, cancel := newTestEthereum()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	e.streams = &streamManager{
+		client: e.client,
+	}
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/sub1",
+		httpmock.NewJsonResponderOrPanic(200, subscription{
+			ID: "sub1", Stream: "es12345", subscriptionCheckpoint: subscriptionCheckpoint{
+				Catchup:    true,
+				Checkpoint: ListenerCheckpoint{Block: checkpointBlock, TransactionIndex: 1, LogIndex: 2},
+			},
+		}))
+	return e, func() {
+		cancel()
+		httpmock.DeactivateAndReset()
+	}
+}
+
+func TestGetContractListenerStatusHead(t *testing.T) {
+	e, done := newTestListenerStatusEthereum(1000)
+	defer done()
+	httpmock.RegisterResponder("POST", "http://localhost:12345/",
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			assert.Equal(t, "GetBlockNumber", body["headers"].(map[string]interface{})["type"])
+			return httpmock.NewJsonResponderOrPanic(200, blockNumberOutput{BlockNumber: "5000"})(req)
+		})
+
+	found, status, err := e.GetContractListenerStatus(context.Background(), "sub1", false)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.True(t, status.Catchup)
+	assert.Equal(t, &core.ContractListenerCheckpoint{Block: 1000, TransactionIndex: 1, LogIndex: 2}, status.Checkpoint)
+	assert.Equal(t, int64(5000), *status.HeadBlock)
+	assert.Equal(t, int64(4000), *status.Lag)
+}
+
+func TestGetContractListenerStatusHeadBehindCheckpoint(t *testing.T) {
+	e, done := newTestListenerStatusEthereum(1000)
+	defer done()
+	httpmock.RegisterResponder("POST", "http://localhost:12345/",
+		httpmock.NewJsonResponderOrPanic(200, blockNumberOutput{BlockNumber: "990"}))
+
+	_, status, err := e.GetContractListenerStatus(context.Background(), "sub1", false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(990), *status.HeadBlock)
+	assert.Equal(t, int64(0), *status.Lag)
+}
+
+func TestGetContractListenerStatusHeadNotSupported(t *testing.T) {
+	e, done := newTestListenerStatusEthereum(1000)
+	defer done()
+	httpmock.RegisterResponder("POST", "http://localhost:12345/",
+		httpmock.NewJsonResponderOrPanic(400, ethError{Error: "unsupported request type"}))
+
+	found, status, err := e.GetContractListenerStatus(context.Background(), "sub1", false)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.NotNil(t, status.Checkpoint)
+	assert.Nil(t, status.HeadBlock)
+	assert.Nil(t, status.Lag)
+}
+
+func TestGetContractListenerStatusHeadFail(t *testing.T) {
+	for _, responder := range []httpmock.Responder{
+		httpmock.NewStringResponder(500, "pop"),
+		httpmock.NewStringResponder(200, "!json"),
+		httpmock.NewJsonResponderOrPanic(200, blockNumberOutput{BlockNumber: 12345}),
+		httpmock.NewJsonResponderOrPanic(200, blockNumberOutput{BlockNumber: "not a number"}),
+	} {
+		e, done := newTestListenerStatusEthereum(1000)
+		httpmock.RegisterResponder("POST", "http://localhost:12345/", responder)
+
+		found, status, err := e.GetContractListenerStatus(context.Background(), "sub1", false)
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.NotNil(t, status.Checkpoint)
+		assert.Nil(t, status.HeadBlock)
+		assert.Nil(t, status.Lag)
+		done()
+	}
+}
+
 func TestGetContractListenerStatusGetSubFail(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
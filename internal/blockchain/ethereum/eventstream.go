@@ -24,7 +24,6 @@ import (
 
 	"github.com/go-resty/resty/v2"
 	"github.com/hyperledger/firefly-common/pkg/ffresty"
-	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly-signer/pkg/abi"
@@ -50,16 +49,21 @@ type eventStream struct {
 }
 
 type subscription struct {
-	ID               string            `json:"id"`
-	Name             string            `json:"name,omitempty"`
-	Stream           string            `json:"stream"`
-	FromBlock        string            `json:"fromBlock"`
-	EthCompatAddress string            `json:"address,omitempty"`
-	EthCompatEvent   *abi.Entry        `json:"event,omitempty"`
-	Filters          []fftypes.JSONAny `json:"filters"`
+	ID               string         `json:"id"`
+	Name             string         `json:"name,omitempty"`
+	Stream           string         `json:"stream"`
+	FromBlock        string         `json:"fromBlock"`
+	EthCompatAddress string         `json:"address,omitempty"`
+	EthCompatEvent   *abi.Entry     `json:"event,omitempty"`
+	Filters          []*eventFilter `json:"filters"`
 	subscriptionCheckpoint
 }
 
+type eventFilter struct {
+	Event   *abi.Entry `json:"event"`
+	Address string     `json:"address,omitempty"`
+}
+
 type subscriptionCheckpoint struct {
 	Checkpoint ListenerCheckpoint `json:"checkpoint,omitempty"`
 	Catchup    bool               `json:"catchup,omitempty"`
@@ -178,7 +182,7 @@ func (s *streamManager) getSubscriptionName(ctx context.Context, subID string) (
 	return sub.Name, nil
 }
 
-func (s *streamManager) createSubscription(ctx context.Context, location *Location, stream, subName, firstEvent string, abi *abi.Entry) (*subscription, error) {
+func (s *streamManager) createSubscription(ctx context.Context, stream, subName, firstEvent string, filters ...*eventFilter) (*subscription, error) {
 	// Map FireFly "firstEvent" values to Ethereum "fromBlock" values
 	switch firstEvent {
 	case string(core.SubOptsFirstEventOldest):
@@ -187,14 +191,17 @@ func (s *streamManager) createSubscription(ctx context.Context, location *Locati
 		firstEvent = "latest"
 	}
 	sub := subscription{
-		Name:           subName,
-		Stream:         stream,
-		FromBlock:      firstEvent,
-		EthCompatEvent: abi,
+		Name:      subName,
+		Stream:    stream,
+		FromBlock: firstEvent,
 	}
 
-	if location != nil {
-		sub.EthCompatAddress = location.Address
+	if len(filters) == 1 {
+		// A single event is set directly on the subscription, as supported by all connectors
+		sub.EthCompatEvent = filters[0].Event
+		sub.EthCompatAddress = filters[0].Address
+	} else {
+		sub.Filters = filters
 	}
 
 	res, err := s.client.R().
@@ -262,8 +269,7 @@ func (s *streamManager) ensureFireFlySubscription(ctx context.Context, namespace
 	if version == 1 {
 		name = v1Name
 	}
-	location := &Location{Address: instancePath}
-	if sub, err = s.createSubscription(ctx, location, stream, name, firstEvent, abi); err != nil {
+	if sub, err = s.createSubscription(ctx, stream, name, firstEvent, &eventFilter{Event: abi, Address: instancePath}); err != nil {
 		return nil, err
 	}
 	log.L(ctx).Infof("%s subscription: %s", abi.Name, sub.ID)
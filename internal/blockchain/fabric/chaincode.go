@@ -0,0 +1,293 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fabric
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// chaincodeDefinition is the "definition" of a contract deploy request, describing the chaincode
+// to be approved and committed on the channel once its package is installed
+type chaincodeDefinition struct {
+	Name              string           `json:"name"`
+	Version           string           `json:"version"`
+	Sequence          int64            `json:"sequence"`
+	Channel           string           `json:"channel,omitempty"`
+	EndorsementPolicy string           `json:"endorsementPolicy,omitempty"`
+	CollectionsConfig *fftypes.JSONAny `json:"collectionsConfig,omitempty"`
+	InitRequired      bool             `json:"initRequired,omitempty"`
+}
+
+// chaincodePackage is an installed chaincode package, as reported by fabconnect
+type chaincodePackage struct {
+	PackageID string `json:"packageId"`
+}
+
+// chaincodeApproval is the definition approved for the organization of the signer
+type chaincodeApproval struct {
+	Version   string `json:"version"`
+	Sequence  int64  `json:"sequence"`
+	PackageID string `json:"packageId"`
+}
+
+// chaincodeCommitted is the definition committed on the channel
+type chaincodeCommitted struct {
+	Version  string `json:"version"`
+	Sequence int64  `json:"sequence"`
+}
+
+func (f *Fabric) parseChaincodeDefinition(ctx context.Context, definition *fftypes.JSONAny) (*chaincodeDefinition, error) {
+	var def chaincodeDefinition
+	if err := json.Unmarshal(definition.Bytes(), &def); err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgInvalidChaincodeDefinition, err)
+	}
+	switch {
+	case def.Name == "":
+		return nil, i18n.NewError(ctx, coremsgs.MsgInvalidChaincodeDefinition, "'name' not set")
+	case def.Version == "":
+		return nil, i18n.NewError(ctx, coremsgs.MsgInvalidChaincodeDefinition, "'version' not set")
+	case def.Sequence < 1:
+		return nil, i18n.NewError(ctx, coremsgs.MsgInvalidChaincodeDefinition, "'sequence' must be 1 or greater")
+	}
+	if def.Channel == "" {
+		if f.defaultChannel == "" {
+			return nil, i18n.NewError(ctx, coremsgs.MsgDefaultChannelNotConfigured)
+		}
+		def.Channel = f.defaultChannel
+	}
+	return &def, nil
+}
+
+func parseChaincodePackage(ctx context.Context, contract *fftypes.JSONAny) (pkg, hash string, err error) {
+	if err := json.Unmarshal(contract.Bytes(), &pkg); err != nil || pkg == "" {
+		return "", "", i18n.NewError(ctx, coremsgs.MsgInvalidChaincodePackage)
+	}
+	b, err := base64.StdEncoding.DecodeString(pkg)
+	if err != nil {
+		return "", "", i18n.NewError(ctx, coremsgs.MsgInvalidChaincodePackage)
+	}
+	// The peer identifies an installed package by its label and this hash, as <label>:<hash>
+	h := sha256.Sum256(b)
+	return pkg, hex.EncodeToString(h[:]), nil
+}
+
+func (f *Fabric) chaincodeRequestBody(def *chaincodeDefinition, requestType, signingKey, requestID string) map[string]interface{} {
+	body := map[string]interface{}{
+		"headers": &fabTxInputHeaders{
+			ID:      requestID,
+			Type:    requestType,
+			Channel: def.Channel,
+			Signer:  getUserName(signingKey),
+		},
+		"name":     def.Name,
+		"version":  def.Version,
+		"sequence": def.Sequence,
+	}
+	if def.EndorsementPolicy != "" {
+		body["endorsementPolicy"] = def.EndorsementPolicy
+	}
+	if def.CollectionsConfig != nil {
+		body["collectionsConfig"] = def.CollectionsConfig
+	}
+	if def.InitRequired {
+		body["initRequired"] = true
+	}
+	return body
+}
+
+func (f *Fabric) postChaincodeRequest(ctx context.Context, path string, sync bool, body map[string]interface{}, result interface{}) error {
+	var resErr fabError
+	syncHeader := "false"
+	if sync {
+		syncHeader = "true"
+	}
+	req := f.client.R().
+		SetContext(ctx).
+		SetHeader("x-firefly-sync", syncHeader).
+		SetBody(body).
+		SetError(&resErr)
+	if result != nil {
+		req.SetResult(result)
+	}
+	res, err := req.Post(path)
+	if err != nil || !res.IsSuccess() {
+		return wrapError(ctx, &resErr, res, err)
+	}
+	return nil
+}
+
+// getChaincodeState queries the lifecycle state of chaincode from fabconnect, returning false if it is not found
+func (f *Fabric) getChaincodeState(ctx context.Context, path string, params map[string]string, result interface{}) (bool, error) {
+	var resErr fabError
+	res, err := f.client.R().
+		SetContext(ctx).
+		SetQueryParams(params).
+		SetResult(result).
+		SetError(&resErr).
+		Get(path)
+	if err == nil && res.StatusCode() == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil || !res.IsSuccess() {
+		return false, wrapError(ctx, &resErr, res, err)
+	}
+	return true, nil
+}
+
+// DeployContract drives the chaincode lifecycle through fabconnect: the package is installed on the peer,
+// the definition is approved for the organization of the signer, then the definition is committed.
+// The request is validated before returning, and the steps run in the background. A failure in any step
+// fails the operation, and the receipt of the commit transaction completes it.
+func (f *Fabric) DeployContract(ctx context.Context, nsOpID, signingKey string, definition, contract *fftypes.JSONAny, input []interface{}, options map[string]interface{}) error {
+	if len(input) > 0 {
+		return i18n.NewError(ctx, coremsgs.MsgChaincodeDeployInputUnsupported)
+	}
+	def, err := f.parseChaincodeDefinition(ctx, definition)
+	if err != nil {
+		return err
+	}
+	pkg, pkgHash, err := parseChaincodePackage(ctx, contract)
+	if err != nil {
+		return err
+	}
+	commitBody := f.chaincodeRequestBody(def, "CommitChaincodeDefinition", signingKey, nsOpID)
+	for k, v := range options {
+		// Set the new field if it's not already set. Do not allow overriding of existing fields
+		if _, ok := commitBody[k]; !ok {
+			commitBody[k] = v
+		} else {
+			return i18n.NewError(ctx, coremsgs.MsgOverrideExistingFieldCustomOption, k)
+		}
+	}
+	if f.metrics.IsMetricsEnabled() {
+		f.metrics.BlockchainContractDeployment()
+	}
+
+	// The steps outlive the request that runs the operation
+	go f.deployChaincode(f.ctx, nsOpID, signingKey, def, pkg, pkgHash, commitBody)
+	return nil
+}
+
+func (f *Fabric) deployChaincode(ctx context.Context, nsOpID, signingKey string, def *chaincodeDefinition, pkg, pkgHash string, commitBody map[string]interface{}) {
+	packageID, err := f.installChaincode(ctx, def, signingKey, pkg, pkgHash)
+	if err == nil {
+		err = f.approveChaincode(ctx, def, signingKey, packageID)
+	}
+	if err == nil {
+		err = f.commitChaincode(ctx, nsOpID, def, signingKey, commitBody)
+	}
+	if err != nil {
+		log.L(ctx).Errorf("Failed to deploy chaincode %s:%s for operation %s: %s", def.Name, def.Version, nsOpID, err)
+		f.callbacks.OperationUpdate(ctx, f, nsOpID, core.OpStatusFailed, "", err.Error(), nil)
+	}
+}
+
+// installChaincode installs the package on the peer, unless it is already installed by an earlier attempt
+func (f *Fabric) installChaincode(ctx context.Context, def *chaincodeDefinition, signingKey, pkg, pkgHash string) (string, error) {
+	var installed []*chaincodePackage
+	if _, err := f.getChaincodeState(ctx, "/chaincode/installed", map[string]string{"signer": getUserName(signingKey)}, &installed); err != nil {
+		return "", err
+	}
+	for _, p := range installed {
+		if strings.HasSuffix(p.PackageID, ":"+pkgHash) {
+			log.L(ctx).Infof("Chaincode package %s for %s:%s is already installed", p.PackageID, def.Name, def.Version)
+			return p.PackageID, nil
+		}
+	}
+
+	installBody := map[string]interface{}{
+		"headers": &fabTxInputHeaders{
+			Type:    "InstallChaincode",
+			Channel: def.Channel,
+			Signer:  getUserName(signingKey),
+		},
+		"package": pkg,
+	}
+	var result chaincodePackage
+	if err := f.postChaincodeRequest(ctx, "/chaincode/install", true, installBody, &result); err != nil {
+		return "", err
+	}
+	if result.PackageID == "" {
+		return "", i18n.NewError(ctx, coremsgs.MsgChaincodeInstallNoPackageID)
+	}
+	log.L(ctx).Infof("Installed chaincode package %s for %s:%s", result.PackageID, def.Name, def.Version)
+	return result.PackageID, nil
+}
+
+// approveChaincode approves the definition for the organization of the signer, unless the same definition
+// is already approved by an earlier attempt - as the peer rejects approving a sequence a second time
+func (f *Fabric) approveChaincode(ctx context.Context, def *chaincodeDefinition, signingKey, packageID string) error {
+	var approved chaincodeApproval
+	found, err := f.getChaincodeState(ctx, "/chaincode/approved", map[string]string{
+		"channel":  def.Channel,
+		"signer":   getUserName(signingKey),
+		"name":     def.Name,
+		"sequence": strconv.FormatInt(def.Sequence, 10),
+	}, &approved)
+	if err != nil {
+		return err
+	}
+	if found && approved.Version == def.Version && approved.PackageID == packageID {
+		log.L(ctx).Infof("Chaincode definition %s:%s sequence %d is already approved on channel %s", def.Name, def.Version, def.Sequence, def.Channel)
+		return nil
+	}
+
+	approveBody := f.chaincodeRequestBody(def, "ApproveChaincodeDefinition", signingKey, "")
+	approveBody["packageId"] = packageID
+	if err := f.postChaincodeRequest(ctx, "/chaincode/approve", true, approveBody, nil); err != nil {
+		return err
+	}
+	log.L(ctx).Infof("Approved chaincode definition %s:%s sequence %d on channel %s", def.Name, def.Version, def.Sequence, def.Channel)
+	return nil
+}
+
+// commitChaincode submits the commit of the definition with the operation ID, so the receipt completes the
+// operation. If an earlier attempt already committed the definition, the operation is completed directly.
+func (f *Fabric) commitChaincode(ctx context.Context, nsOpID string, def *chaincodeDefinition, signingKey string, commitBody map[string]interface{}) error {
+	var committed chaincodeCommitted
+	found, err := f.getChaincodeState(ctx, "/chaincode/committed", map[string]string{
+		"channel": def.Channel,
+		"signer":  getUserName(signingKey),
+		"name":    def.Name,
+	}, &committed)
+	if err != nil {
+		return err
+	}
+	if found && committed.Sequence == def.Sequence && committed.Version == def.Version {
+		log.L(ctx).Infof("Chaincode definition %s:%s sequence %d is already committed on channel %s", def.Name, def.Version, def.Sequence, def.Channel)
+		f.callbacks.OperationUpdate(ctx, f, nsOpID, core.OpStatusSucceeded, "", "", fftypes.JSONObject{
+			"name":     def.Name,
+			"version":  committed.Version,
+			"sequence": committed.Sequence,
+		})
+		return nil
+	}
+	return f.postChaincodeRequest(ctx, "/chaincode/commit", false, commitBody, nil)
+}
@@ -0,0 +1,397 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fabric
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/coremocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func testChaincodeDefinition() *fftypes.JSONAny {
+	return fftypes.JSONAnyPtr(fftypes.JSONObject{
+		"name":              "asset_transfer",
+		"version":           "1.0",
+		"sequence":          1,
+		"endorsementPolicy": "OR('Org1MSP.member')",
+		"collectionsConfig": []interface{}{fftypes.JSONObject{"name": "private"}},
+		"initRequired":      true,
+	}.String())
+}
+
+func testChaincodePackage() *fftypes.JSONAny {
+	b, _ := json.Marshal(base64.StdEncoding.EncodeToString([]byte("chaincode package")))
+	return fftypes.JSONAnyPtrBytes(b)
+}
+
+func testChaincodePackageID() string {
+	h := sha256.Sum256([]byte("chaincode package"))
+	return "asset_transfer_1.0:" + hex.EncodeToString(h[:])
+}
+
+func newTestFabricDeploy() (*Fabric, *coremocks.OperationCallbacks, func()) {
+	e, cancel := newTestFabric()
+	mmm := &metricsmocks.Manager{}
+	mmm.On("IsMetricsEnabled").Return(true)
+	mmm.On("BlockchainContractDeployment").Return()
+	e.metrics = mmm
+	em := &coremocks.OperationCallbacks{}
+	e.SetOperationHandler("ns1", em)
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	return e, em, func() {
+		httpmock.DeactivateAndReset()
+		cancel()
+	}
+}
+
+func registerChaincodeNotFound() {
+	httpmock.RegisterResponder("GET", "http://localhost:12345/chaincode/installed",
+		httpmock.NewJsonResponderOrPanic(200, []interface{}{}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/chaincode/approved",
+		httpmock.NewJsonResponderOrPanic(404, fftypes.JSONObject{"error": "not found"}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/chaincode/committed",
+		httpmock.NewJsonResponderOrPanic(404, fftypes.JSONObject{"error": "not found"}))
+}
+
+// deployChaincodeSync runs the steps of a deployment that DeployContract starts in the background
+func deployChaincodeSync(t *testing.T, e *Fabric, definition *fftypes.JSONAny, options map[string]interface{}) {
+	def, err := e.parseChaincodeDefinition(context.Background(), definition)
+	assert.NoError(t, err)
+	pkg, pkgHash, err := parseChaincodePackage(context.Background(), testChaincodePackage())
+	assert.NoError(t, err)
+	commitBody := e.chaincodeRequestBody(def, "CommitChaincodeDefinition", "signer001", "ns1:op1")
+	for k, v := range options {
+		commitBody[k] = v
+	}
+	e.deployChaincode(context.Background(), "ns1:op1", "signer001", def, pkg, pkgHash, commitBody)
+}
+
+func expectDeployFailed(em *coremocks.OperationCallbacks, errorMatch string) {
+	em.On("OperationUpdate", mock.MatchedBy(func(update *core.OperationUpdate) bool {
+		return update.NamespacedOpID == "ns1:op1" &&
+			update.Status == core.OpStatusFailed &&
+			regexp.MustCompile(errorMatch).MatchString(update.ErrorMessage)
+	})).Return()
+}
+
+func TestDeployContractOK(t *testing.T) {
+	e, em, cancel := newTestFabricDeploy()
+	defer cancel()
+	signingKey := "signer001"
+	options := map[string]interface{}{
+		"customOption": "customValue",
+	}
+	committed := make(chan struct{})
+
+	registerChaincodeNotFound()
+	httpmock.RegisterResponder("POST", "http://localhost:12345/chaincode/install",
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			headers := body["headers"].(map[string]interface{})
+			assert.Equal(t, "true", req.Header.Get("x-firefly-sync"))
+			assert.Equal(t, "InstallChaincode", headers["type"])
+			assert.Equal(t, "firefly", headers["channel"])
+			assert.Equal(t, signingKey, headers["signer"])
+			assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("chaincode package")), body["package"])
+			return httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"packageId": testChaincodePackageID()})(req)
+		})
+	httpmock.RegisterResponder("POST", "http://localhost:12345/chaincode/approve",
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			headers := body["headers"].(map[string]interface{})
+			assert.Equal(t, "true", req.Header.Get("x-firefly-sync"))
+			assert.Equal(t, "ApproveChaincodeDefinition", headers["type"])
+			assert.Equal(t, testChaincodePackageID(), body["packageId"])
+			assert.Equal(t, "asset_transfer", body["name"])
+			assert.Equal(t, "1.0", body["version"])
+			assert.Equal(t, float64(1), body["sequence"])
+			assert.Equal(t, "OR('Org1MSP.member')", body["endorsementPolicy"])
+			assert.Equal(t, true, body["initRequired"])
+			assert.Nil(t, body["customOption"])
+			return httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{})(req)
+		})
+	httpmock.RegisterResponder("POST", "http://localhost:12345/chaincode/commit",
+		func(req *http.Request) (*http.Response, error) {
+			defer close(committed)
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			headers := body["headers"].(map[string]interface{})
+			assert.Equal(t, "false", req.Header.Get("x-firefly-sync"))
+			assert.Equal(t, "CommitChaincodeDefinition", headers["type"])
+			assert.Equal(t, "ns1:op1", headers["id"])
+			assert.Equal(t, "asset_transfer", body["name"])
+			assert.Len(t, body["collectionsConfig"], 1)
+			assert.Nil(t, body["packageId"])
+			assert.Equal(t, "customValue", body["customOption"])
+			return httpmock.NewJsonResponderOrPanic(202, fftypes.JSONObject{"id": "ns1:op1"})(req)
+		})
+
+	err := e.DeployContract(context.Background(), "ns1:op1", signingKey, testChaincodeDefinition(), testChaincodePackage(), nil, options)
+	assert.NoError(t, err)
+	<-committed
+	assert.Equal(t, 6, httpmock.GetTotalCallCount())
+	em.AssertExpectations(t)
+}
+
+func TestDeployContractChannelOverride(t *testing.T) {
+	e, _, cancel := newTestFabricDeploy()
+	defer cancel()
+	e.defaultChannel = ""
+
+	definition := fftypes.JSONAnyPtr(fftypes.JSONObject{
+		"name":     "asset_transfer",
+		"version":  "1.0",
+		"sequence": 2,
+		"channel":  "other",
+	}.String())
+	channelCheck := func(req *http.Request) (*http.Response, error) {
+		var body map[string]interface{}
+		json.NewDecoder(req.Body).Decode(&body)
+		assert.Equal(t, "other", body["headers"].(map[string]interface{})["channel"])
+		assert.Nil(t, body["endorsementPolicy"])
+		assert.Nil(t, body["initRequired"])
+		return httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"packageId": testChaincodePackageID()})(req)
+	}
+	registerChaincodeNotFound()
+	httpmock.RegisterResponder("GET", "http://localhost:12345/chaincode/approved",
+		func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "other", req.URL.Query().Get("channel"))
+			assert.Equal(t, "2", req.URL.Query().Get("sequence"))
+			return httpmock.NewJsonResponderOrPanic(404, fftypes.JSONObject{})(req)
+		})
+	httpmock.RegisterResponder("POST", "http://localhost:12345/chaincode/install", channelCheck)
+	httpmock.RegisterResponder("POST", "http://localhost:12345/chaincode/approve", channelCheck)
+	httpmock.RegisterResponder("POST", "http://localhost:12345/chaincode/commit", channelCheck)
+
+	deployChaincodeSync(t, e, definition, nil)
+	assert.Equal(t, 6, httpmock.GetTotalCallCount())
+}
+
+func TestDeployContractRetryAlreadyCommitted(t *testing.T) {
+	e, em, cancel := newTestFabricDeploy()
+	defer cancel()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/chaincode/installed",
+		httpmock.NewJsonResponderOrPanic(200, []interface{}{
+			fftypes.JSONObject{"packageId": "other_1.0:1234"},
+			fftypes.JSONObject{"packageId": testChaincodePackageID()},
+		}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/chaincode/approved",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"version": "1.0", "sequence": 1, "packageId": testChaincodePackageID()}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/chaincode/committed",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"version": "1.0", "sequence": 1}))
+	em.On("OperationUpdate", mock.MatchedBy(func(update *core.OperationUpdate) bool {
+		return update.NamespacedOpID == "ns1:op1" &&
+			update.Status == core.OpStatusSucceeded &&
+			update.Output["sequence"] == int64(1)
+	})).Return()
+
+	deployChaincodeSync(t, e, testChaincodeDefinition(), nil)
+	assert.Equal(t, 3, httpmock.GetTotalCallCount())
+	em.AssertExpectations(t)
+}
+
+func TestDeployContractRetryApprovedDifferentPackage(t *testing.T) {
+	e, _, cancel := newTestFabricDeploy()
+	defer cancel()
+
+	registerChaincodeNotFound()
+	httpmock.RegisterResponder("POST", "http://localhost:12345/chaincode/install",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"packageId": testChaincodePackageID()}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/chaincode/approved",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"version": "1.0", "sequence": 1, "packageId": "other_1.0:1234"}))
+	httpmock.RegisterResponder("POST", "http://localhost:12345/chaincode/approve",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/chaincode/committed",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"version": "0.9", "sequence": 0}))
+	httpmock.RegisterResponder("POST", "http://localhost:12345/chaincode/commit",
+		httpmock.NewJsonResponderOrPanic(202, fftypes.JSONObject{"id": "ns1:op1"}))
+
+	deployChaincodeSync(t, e, testChaincodeDefinition(), nil)
+	assert.Equal(t, 1, httpmock.GetCallCountInfo()["POST http://localhost:12345/chaincode/approve"])
+	assert.Equal(t, 1, httpmock.GetCallCountInfo()["POST http://localhost:12345/chaincode/commit"])
+}
+
+func TestDeployContractInputUnsupported(t *testing.T) {
+	e, _, cancel := newTestFabricDeploy()
+	defer cancel()
+
+	err := e.DeployContract(context.Background(), "ns1:op1", "signer001", testChaincodeDefinition(), testChaincodePackage(), []interface{}{"init"}, nil)
+	assert.Regexp(t, "FF10519", err)
+}
+
+func TestDeployContractBadDefinition(t *testing.T) {
+	e, _, cancel := newTestFabricDeploy()
+	defer cancel()
+
+	for _, def := range []string{
+		`[]`,
+		`{"version":"1.0","sequence":1}`,
+		`{"name":"cc","sequence":1}`,
+		`{"name":"cc","version":"1.0"}`,
+	} {
+		err := e.DeployContract(context.Background(), "ns1:op1", "signer001", fftypes.JSONAnyPtr(def), testChaincodePackage(), nil, nil)
+		assert.Regexp(t, "FF10517", err)
+	}
+}
+
+func TestDeployContractNoChannel(t *testing.T) {
+	e, _, cancel := newTestFabricDeploy()
+	defer cancel()
+	e.defaultChannel = ""
+
+	err := e.DeployContract(context.Background(), "ns1:op1", "signer001", testChaincodeDefinition(), testChaincodePackage(), nil, nil)
+	assert.Regexp(t, "FF10440", err)
+}
+
+func TestDeployContractBadPackage(t *testing.T) {
+	e, _, cancel := newTestFabricDeploy()
+	defer cancel()
+
+	for _, pkg := range []string{`{}`, `""`, `"!!not base64"`} {
+		err := e.DeployContract(context.Background(), "ns1:op1", "signer001", testChaincodeDefinition(), fftypes.JSONAnyPtr(pkg), nil, nil)
+		assert.Regexp(t, "FF10518", err)
+	}
+}
+
+func TestDeployContractOptionOverride(t *testing.T) {
+	e, _, cancel := newTestFabricDeploy()
+	defer cancel()
+
+	err := e.DeployContract(context.Background(), "ns1:op1", "signer001", testChaincodeDefinition(), testChaincodePackage(), nil, map[string]interface{}{
+		"sequence": 5,
+	})
+	assert.Regexp(t, "FF10398", err)
+	assert.Zero(t, httpmock.GetTotalCallCount())
+}
+
+func TestDeployContractQueryInstalledFail(t *testing.T) {
+	e, em, cancel := newTestFabricDeploy()
+	defer cancel()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/chaincode/installed",
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{"error": "pop"}))
+	expectDeployFailed(em, "FF10284.*pop")
+
+	deployChaincodeSync(t, e, testChaincodeDefinition(), nil)
+	em.AssertExpectations(t)
+}
+
+func TestDeployContractInstallFail(t *testing.T) {
+	e, em, cancel := newTestFabricDeploy()
+	defer cancel()
+
+	registerChaincodeNotFound()
+	httpmock.RegisterResponder("POST", "http://localhost:12345/chaincode/install",
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{"error": "pop"}))
+	expectDeployFailed(em, "FF10284.*pop")
+
+	deployChaincodeSync(t, e, testChaincodeDefinition(), nil)
+	em.AssertExpectations(t)
+}
+
+func TestDeployContractInstallNoPackageID(t *testing.T) {
+	e, em, cancel := newTestFabricDeploy()
+	defer cancel()
+
+	registerChaincodeNotFound()
+	httpmock.RegisterResponder("POST", "http://localhost:12345/chaincode/install",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{}))
+	expectDeployFailed(em, "FF10520")
+
+	deployChaincodeSync(t, e, testChaincodeDefinition(), nil)
+	em.AssertExpectations(t)
+}
+
+func TestDeployContractQueryApprovedFail(t *testing.T) {
+	e, em, cancel := newTestFabricDeploy()
+	defer cancel()
+
+	registerChaincodeNotFound()
+	httpmock.RegisterResponder("POST", "http://localhost:12345/chaincode/install",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"packageId": testChaincodePackageID()}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/chaincode/approved",
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{"error": "pop"}))
+	expectDeployFailed(em, "FF10284.*pop")
+
+	deployChaincodeSync(t, e, testChaincodeDefinition(), nil)
+	em.AssertExpectations(t)
+}
+
+func TestDeployContractApproveFail(t *testing.T) {
+	e, em, cancel := newTestFabricDeploy()
+	defer cancel()
+
+	registerChaincodeNotFound()
+	httpmock.RegisterResponder("POST", "http://localhost:12345/chaincode/install",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"packageId": testChaincodePackageID()}))
+	httpmock.RegisterResponder("POST", "http://localhost:12345/chaincode/approve",
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{"error": "pop"}))
+	expectDeployFailed(em, "FF10284.*pop")
+
+	deployChaincodeSync(t, e, testChaincodeDefinition(), nil)
+	em.AssertExpectations(t)
+}
+
+func TestDeployContractQueryCommittedFail(t *testing.T) {
+	e, em, cancel := newTestFabricDeploy()
+	defer cancel()
+
+	registerChaincodeNotFound()
+	httpmock.RegisterResponder("POST", "http://localhost:12345/chaincode/install",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"packageId": testChaincodePackageID()}))
+	httpmock.RegisterResponder("POST", "http://localhost:12345/chaincode/approve",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/chaincode/committed",
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{"error": "pop"}))
+	expectDeployFailed(em, "FF10284.*pop")
+
+	deployChaincodeSync(t, e, testChaincodeDefinition(), nil)
+	em.AssertExpectations(t)
+}
+
+func TestDeployContractCommitFail(t *testing.T) {
+	e, em, cancel := newTestFabricDeploy()
+	defer cancel()
+
+	registerChaincodeNotFound()
+	httpmock.RegisterResponder("POST", "http://localhost:12345/chaincode/install",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"packageId": testChaincodePackageID()}))
+	httpmock.RegisterResponder("POST", "http://localhost:12345/chaincode/approve",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{}))
+	httpmock.RegisterResponder("POST", "http://localhost:12345/chaincode/commit",
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{"error": "pop"}))
+	expectDeployFailed(em, "FF10284.*pop")
+
+	deployChaincodeSync(t, e, testChaincodeDefinition(), nil)
+	em.AssertExpectations(t)
+}
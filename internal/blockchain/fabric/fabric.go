@@ -99,6 +99,12 @@ type fabQueryNamedOutput struct {
 	Result  interface{}        `json:"result"`
 }
 
+type fabChainInfo struct {
+	Result struct {
+		Height int64 `json:"height"`
+	} `json:"result"`
+}
+
 type ffiParamSchema struct {
 	Type string `json:"type,omitempty"`
 }
@@ -726,10 +732,6 @@ func (f *Fabric) buildFabconnectRequestBody(ctx context.Context, channel, chainc
 	return body, nil
 }
 
-func (f *Fabric) DeployContract(ctx context.Context, nsOpID, signingKey string, definition, contract *fftypes.JSONAny, input []interface{}, options map[string]interface{}) error {
-	return i18n.NewError(ctx, coremsgs.MsgNotSupportedByBlockchainPlugin)
-}
-
 func (f *Fabric) ValidateInvokeRequest(ctx context.Context, method *fftypes.FFIMethod, input map[string]interface{}, errors []*fftypes.FFIError, hasMessage bool) error {
 	// No additional validation beyond what is enforced by Contract Manager
 	return nil
@@ -768,6 +770,15 @@ func (f *Fabric) InvokeContract(ctx context.Context, nsOpID string, signingKey s
 	return f.invokeContractMethod(ctx, fabricOnChainLocation.Channel, fabricOnChainLocation.Chaincode, method.Name, signingKey, nsOpID, prefixItems, input, options)
 }
 
+func (f *Fabric) InvokeContractBatch(ctx context.Context, nsOpID, signingKey string, calls []*blockchain.ContractCall, options map[string]interface{}) error {
+	// Fabric has no equivalent of a multicall contract, so the AtomicInvokeBatch capability is never set
+	return i18n.NewError(ctx, coremsgs.MsgContractBatchAtomicNotSupported, f.Name())
+}
+
+func (f *Fabric) DecodeInvokeBatchOutput(ctx context.Context, calls []*blockchain.ContractCall, output fftypes.JSONObject) ([]*fftypes.JSONAny, error) {
+	return nil, i18n.NewError(ctx, coremsgs.MsgContractBatchAtomicNotSupported, f.Name())
+}
+
 func (f *Fabric) QueryContract(ctx context.Context, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}, errors []*fftypes.FFIError, options map[string]interface{}) (interface{}, error) {
 	fabricOnChainLocation, err := parseContractLocation(ctx, location)
 	if err != nil {
@@ -849,6 +860,9 @@ func encodeContractLocation(ctx context.Context, ntype blockchain.NormalizeType,
 }
 
 func (f *Fabric) AddContractListener(ctx context.Context, listener *core.ContractListener) error {
+	if len(listener.Filters) > 1 || (len(listener.Filters) == 1 && len(listener.Filters[0].Indexed) > 0) {
+		return i18n.NewError(ctx, coremsgs.MsgListenerFiltersNotSupported, f.Name())
+	}
 	location, err := parseContractLocation(ctx, listener.Location)
 	if err != nil {
 		return err
@@ -867,9 +881,34 @@ func (f *Fabric) DeleteContractListener(ctx context.Context, subscription *core.
 	return f.streams.deleteSubscription(ctx, subscription.BackendID, okNotFound)
 }
 
-func (f *Fabric) GetContractListenerStatus(ctx context.Context, subID string, okNotFound bool) (bool, interface{}, error) {
-	// Fabconnect does not currently provide any additional status info for listener subscriptions.
-	return true, nil, nil
+func (f *Fabric) GetContractListenerStatus(ctx context.Context, subID string, okNotFound bool) (bool, *core.ContractListenerStatus, error) {
+	sub, err := f.streams.getSubscription(ctx, subID, okNotFound)
+	if err != nil || sub == nil {
+		return false, nil, err
+	}
+
+	// Fabconnect keeps the checkpoints of its subscriptions internally, and does not report them. So the checkpoint,
+	// catchup and lag of a listener are not supported on Fabric, and the status is limited to the height of the ledger
+	// on the channel of the subscription. This is only used to report progress, so failures are not returned.
+	status := &core.ContractListenerStatus{}
+	var resErr fabError
+	var info fabChainInfo
+	res, err := f.client.R().
+		SetContext(ctx).
+		SetError(&resErr).
+		SetResult(&info).
+		SetQueryParam("fly-channel", sub.Channel).
+		SetQueryParam("fly-signer", sub.Signer).
+		Get("/chaininfo")
+	if err != nil || !res.IsSuccess() {
+		log.L(ctx).Warnf("Unable to determine the head of the chain for listener %s: %s", subID, wrapError(ctx, &resErr, res, err))
+		return true, status, nil
+	}
+	if info.Result.Height > 0 {
+		head := info.Result.Height - 1
+		status.HeadBlock = &head
+	}
+	return true, status, nil
 }
 
 func (f *Fabric) GetFFIParamValidator(ctx context.Context) (fftypes.FFIParamValidator, error) {
@@ -877,14 +916,22 @@ func (f *Fabric) GetFFIParamValidator(ctx context.Context) (fftypes.FFIParamVali
 	return nil, nil
 }
 
-func (f *Fabric) GenerateFFI(ctx context.Context, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error) {
-	return nil, i18n.NewError(ctx, coremsgs.MsgFFIGenerationUnsupported)
-}
-
 func (f *Fabric) GenerateEventSignature(ctx context.Context, event *fftypes.FFIEventDefinition) string {
 	return event.Name
 }
 
+func (f *Fabric) GenerateEventSignatureWithLocation(ctx context.Context, event *fftypes.FFIEventDefinition, location *fftypes.JSONAny) (string, error) {
+	signature := f.GenerateEventSignature(ctx, event)
+	if location == nil {
+		return signature, nil
+	}
+	parsed, err := parseContractLocation(ctx, location)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%s", f.buildEventLocationString(parsed.Chaincode), signature), nil
+}
+
 func (f *Fabric) GenerateErrorSignature(ctx context.Context, event *fftypes.FFIErrorDefinition) string {
 	// not relevant to Fabric blockchains
 	return ""
@@ -1667,6 +1667,40 @@ func TestAddSubscriptionBadLocation(t *testing.T) {
 	assert.Regexp(t, "FF10310", err)
 }
 
+func TestAddSubscriptionMultipleFilters(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+
+	sub := &core.ContractListener{
+		Filters: core.ListenerFilters{
+			{Event: &core.FFISerializedEvent{}},
+			{Event: &core.FFISerializedEvent{}},
+		},
+	}
+
+	err := e.AddContractListener(context.Background(), sub)
+
+	assert.Regexp(t, "FF10516", err)
+}
+
+func TestAddSubscriptionIndexedFilter(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+
+	sub := &core.ContractListener{
+		Filters: core.ListenerFilters{
+			{
+				Event:   &core.FFISerializedEvent{},
+				Indexed: fftypes.JSONObject{"from": "abc"},
+			},
+		},
+	}
+
+	err := e.AddContractListener(context.Background(), sub)
+
+	assert.Regexp(t, "FF10516", err)
+}
+
 func TestAddSubscriptionFail(t *testing.T) {
 	e, cancel := newTestFabric()
 	defer cancel()
@@ -2193,38 +2227,6 @@ func TestInvokeContractWithBatchOK(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-func TestDeployContractOK(t *testing.T) {
-	e, cancel := newTestFabric()
-	defer cancel()
-	httpmock.ActivateNonDefault(e.client.GetClient())
-	defer httpmock.DeactivateAndReset()
-	signingKey := fftypes.NewRandB32().String()
-	input := []interface{}{
-		float64(1),
-		"1000000000000000000000000",
-	}
-	options := map[string]interface{}{
-		"contract": "not really a contract",
-	}
-	definitionBytes, err := json.Marshal([]interface{}{})
-	contractBytes, err := json.Marshal("0x123456")
-	assert.NoError(t, err)
-	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
-		func(req *http.Request) (*http.Response, error) {
-			var body map[string]interface{}
-			json.NewDecoder(req.Body).Decode(&body)
-			params := body["params"].([]interface{})
-			headers := body["headers"].(map[string]interface{})
-			assert.Equal(t, "DeployContract", headers["type"])
-			assert.Equal(t, float64(1), params[0])
-			assert.Equal(t, "1000000000000000000000000", params[1])
-			assert.Equal(t, body["customOption"].(string), "customValue")
-			return httpmock.NewJsonResponderOrPanic(400, "pop")(req)
-		})
-	err = e.DeployContract(context.Background(), "", signingKey, fftypes.JSONAnyPtrBytes(definitionBytes), fftypes.JSONAnyPtrBytes(contractBytes), input, options)
-	assert.Regexp(t, "FF10429", err)
-}
-
 func TestInvokeContractBadSchema(t *testing.T) {
 	e, cancel := newTestFabric()
 	defer cancel()
@@ -2326,6 +2328,20 @@ func TestInvokeContractFabconnectError(t *testing.T) {
 	assert.Regexp(t, "FF10284", err)
 }
 
+func TestInvokeContractBatchNotSupported(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	err := e.InvokeContractBatch(context.Background(), "ns1:op1", "signer", []*blockchain.ContractCall{}, nil)
+	assert.Regexp(t, "FF10525.*fabric", err)
+}
+
+func TestDecodeInvokeBatchOutputNotSupported(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	_, err := e.DecodeInvokeBatchOutput(context.Background(), []*blockchain.ContractCall{}, fftypes.JSONObject{})
+	assert.Regexp(t, "FF10525.*fabric", err)
+}
+
 func TestQueryContractOK(t *testing.T) {
 	e, cancel := newTestFabric()
 	defer cancel()
@@ -2534,21 +2550,30 @@ func TestGetFFIParamValidator(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-func TestGenerateFFI(t *testing.T) {
+func TestGenerateEventSignature(t *testing.T) {
 	e, _ := newTestFabric()
-	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
-		Name:        "Simple",
-		Version:     "v0.0.1",
-		Description: "desc",
-		Input:       fftypes.JSONAnyPtr(`[]`),
-	})
-	assert.Regexp(t, "FF10347", err)
+	signature := e.GenerateEventSignature(context.Background(), &fftypes.FFIEventDefinition{Name: "Changed"})
+	assert.Equal(t, "Changed", signature)
 }
 
-func TestGenerateEventSignature(t *testing.T) {
+func TestGenerateEventSignatureWithLocation(t *testing.T) {
 	e, _ := newTestFabric()
-	signature := e.GenerateEventSignature(context.Background(), &fftypes.FFIEventDefinition{Name: "Changed"})
+	event := &fftypes.FFIEventDefinition{Name: "Changed"}
+
+	signature, err := e.GenerateEventSignatureWithLocation(context.Background(), event, nil)
+	assert.NoError(t, err)
 	assert.Equal(t, "Changed", signature)
+
+	location := fftypes.JSONAnyPtr(fftypes.JSONObject{"channel": "firefly", "chaincode": "simplestorage"}.String())
+	signature, err = e.GenerateEventSignatureWithLocation(context.Background(), event, location)
+	assert.NoError(t, err)
+	assert.Equal(t, "chaincode=simplestorage:Changed", signature)
+}
+
+func TestGenerateEventSignatureWithLocationBadLocation(t *testing.T) {
+	e, _ := newTestFabric()
+	_, err := e.GenerateEventSignatureWithLocation(context.Background(), &fftypes.FFIEventDefinition{Name: "Changed"}, fftypes.JSONAnyPtr(""))
+	assert.Regexp(t, "FF10310", err)
 }
 
 func TestHandleNetworkAction(t *testing.T) {
@@ -2911,10 +2936,92 @@ func TestGetContractListenerStatus(t *testing.T) {
 	defer cancel()
 	httpmock.ActivateNonDefault(e.client.GetClient())
 	defer httpmock.DeactivateAndReset()
+	e.streams = &streamManager{client: e.client}
 
-	_, status, err := e.GetContractListenerStatus(context.Background(), "id", true)
-	assert.Nil(t, status)
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/sb-1",
+		httpmock.NewJsonResponderOrPanic(200, subscription{ID: "sb-1", Channel: "firefly", Signer: "signer001"}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/chaininfo",
+		func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "firefly", req.URL.Query().Get("fly-channel"))
+			assert.Equal(t, "signer001", req.URL.Query().Get("fly-signer"))
+			return httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{
+				"result": fftypes.JSONObject{"height": 1000},
+			})(req)
+		})
+
+	found, status, err := e.GetContractListenerStatus(context.Background(), "sb-1", false)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Nil(t, status.Checkpoint)
+	assert.False(t, status.Catchup)
+	assert.Equal(t, int64(999), *status.HeadBlock)
+}
+
+func TestGetContractListenerStatusEmptyLedger(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streams = &streamManager{client: e.client}
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/sb-1",
+		httpmock.NewJsonResponderOrPanic(200, subscription{ID: "sb-1"}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/chaininfo",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"result": fftypes.JSONObject{}}))
+
+	found, status, err := e.GetContractListenerStatus(context.Background(), "sb-1", false)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Nil(t, status.HeadBlock)
+}
+
+func TestGetContractListenerStatusChainInfoFail(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streams = &streamManager{client: e.client}
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/sb-1",
+		httpmock.NewJsonResponderOrPanic(200, subscription{ID: "sb-1"}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/chaininfo",
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{"error": "pop"}))
+
+	found, status, err := e.GetContractListenerStatus(context.Background(), "sb-1", false)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Nil(t, status.HeadBlock)
+}
+
+func TestGetContractListenerStatusNotFound(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streams = &streamManager{client: e.client}
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/sb-1",
+		httpmock.NewStringResponder(404, "not found"))
+
+	found, status, err := e.GetContractListenerStatus(context.Background(), "sb-1", true)
 	assert.NoError(t, err)
+	assert.False(t, found)
+	assert.Nil(t, status)
+}
+
+func TestGetContractListenerStatusGetSubFail(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streams = &streamManager{client: e.client}
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/sb-1",
+		httpmock.NewStringResponder(500, "pop"))
+
+	found, _, err := e.GetContractListenerStatus(context.Background(), "sb-1", true)
+	assert.Regexp(t, "FF10284", err)
+	assert.False(t, found)
 }
 
 func TestGetTransactionStatus(t *testing.T) {
@@ -0,0 +1,287 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fabric
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+const (
+	// The system contract that contract-api adds to every chaincode to serve the metadata itself
+	systemContractName = "org.hyperledger.fabric"
+	schemaRefPrefix    = "#/components/schemas/"
+)
+
+// FFIGenerationInput is the input to GenerateFFI, containing the document returned by
+// the "org.hyperledger.fabric:GetMetadata" transaction of a contract-api chaincode
+type FFIGenerationInput struct {
+	Metadata *contractMetadata `json:"metadata,omitempty"`
+}
+
+type contractMetadata struct {
+	Info       *contractMetadataInfo            `json:"info,omitempty"`
+	Contracts  map[string]*contractMetadataItem `json:"contracts"`
+	Components struct {
+		Schemas map[string]interface{} `json:"schemas,omitempty"`
+	} `json:"components"`
+}
+
+type contractMetadataInfo struct {
+	Title       string `json:"title,omitempty"`
+	Version     string `json:"version,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type contractMetadataItem struct {
+	Name         string                `json:"name"`
+	Info         *contractMetadataInfo `json:"info,omitempty"`
+	Default      bool                  `json:"default,omitempty"`
+	Transactions []*contractMetadataTx `json:"transactions"`
+	Events       []*contractMetadataTx `json:"events,omitempty"`
+}
+
+// contractMetadataTx describes a transaction, or an event in the FireFly "events" extension
+type contractMetadataTx struct {
+	Name        string                   `json:"name"`
+	Description string                   `json:"description,omitempty"`
+	Tag         []string                 `json:"tag,omitempty"`
+	Parameters  []*contractMetadataParam `json:"parameters,omitempty"`
+	Returns     json.RawMessage          `json:"returns,omitempty"`
+}
+
+type contractMetadataParam struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Schema      interface{} `json:"schema"`
+}
+
+type metadataConverter struct {
+	ctx     context.Context
+	schemas map[string]interface{}
+}
+
+func (f *Fabric) GenerateFFI(ctx context.Context, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error) {
+	var input FFIGenerationInput
+	err := json.Unmarshal(generationRequest.Input.Bytes(), &input)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgFFIGenerationFailed, "unable to deserialize JSON as contract metadata")
+	}
+	if input.Metadata == nil || len(input.Metadata.Contracts) == 0 {
+		return nil, i18n.NewError(ctx, coremsgs.MsgFFIGenerationFailed, "contract metadata is empty")
+	}
+	return convertMetadataToFFI(ctx, generationRequest, input.Metadata)
+}
+
+func convertMetadataToFFI(ctx context.Context, generationRequest *fftypes.FFIGenerationRequest, metadata *contractMetadata) (*fftypes.FFI, error) {
+	ffi := &fftypes.FFI{
+		Namespace:   generationRequest.Namespace,
+		Name:        generationRequest.Name,
+		Version:     generationRequest.Version,
+		Description: generationRequest.Description,
+		Methods:     []*fftypes.FFIMethod{},
+		Events:      []*fftypes.FFIEvent{},
+	}
+	if info := metadata.Info; info != nil {
+		if ffi.Name == "" {
+			ffi.Name = info.Title
+		}
+		if ffi.Version == "" {
+			ffi.Version = info.Version
+		}
+		if ffi.Description == "" {
+			ffi.Description = info.Description
+		}
+	}
+
+	conv := &metadataConverter{ctx: ctx, schemas: metadata.Components.Schemas}
+
+	// Sort the contracts for a stable ordering of the methods in the generated interface
+	contractNames := make([]string, 0, len(metadata.Contracts))
+	for name := range metadata.Contracts {
+		if name != systemContractName {
+			contractNames = append(contractNames, name)
+		}
+	}
+	sort.Strings(contractNames)
+	if len(contractNames) == 0 {
+		return nil, i18n.NewError(ctx, coremsgs.MsgFFIGenerationFailed, "contract metadata is empty")
+	}
+
+	for _, name := range contractNames {
+		contract := metadata.Contracts[name]
+		if contract == nil {
+			return nil, i18n.NewError(ctx, coremsgs.MsgFFIGenerationFailed, fmt.Sprintf("contract '%s' is null", name))
+		}
+		for _, tx := range contract.Transactions {
+			if tx == nil {
+				return nil, i18n.NewError(ctx, coremsgs.MsgFFIGenerationFailed, fmt.Sprintf("contract '%s' has a null transaction", name))
+			}
+			method, err := conv.convertTransaction(name, contract.Default, tx)
+			if err != nil {
+				return nil, err
+			}
+			ffi.Methods = append(ffi.Methods, method)
+		}
+		for _, ev := range contract.Events {
+			if ev == nil {
+				return nil, i18n.NewError(ctx, coremsgs.MsgFFIGenerationFailed, fmt.Sprintf("contract '%s' has a null event", name))
+			}
+			params, err := conv.convertParams(ev.Parameters)
+			if err != nil {
+				return nil, err
+			}
+			ffi.Events = append(ffi.Events, &fftypes.FFIEvent{
+				FFIEventDefinition: fftypes.FFIEventDefinition{
+					Name:        ev.Name,
+					Description: ev.Description,
+					Params:      params,
+				},
+			})
+		}
+	}
+	return ffi, nil
+}
+
+func (conv *metadataConverter) convertTransaction(contractName string, isDefault bool, tx *contractMetadataTx) (*fftypes.FFIMethod, error) {
+	method := &fftypes.FFIMethod{
+		Name:        tx.Name,
+		Description: tx.Description,
+		Returns:     fftypes.FFIParams{},
+	}
+	if !isDefault {
+		// Transactions on any contract other than the default must be invoked with the contract name as a prefix
+		method.Name = fmt.Sprintf("%s:%s", contractName, tx.Name)
+	}
+	if len(tx.Tag) > 0 {
+		method.Details = fftypes.JSONObject{"tag": tx.Tag}
+	}
+
+	var err error
+	if method.Params, err = conv.convertParams(tx.Parameters); err != nil {
+		return nil, err
+	}
+	if method.Returns, err = conv.convertReturns(tx.Returns); err != nil {
+		return nil, err
+	}
+	return method, nil
+}
+
+func (conv *metadataConverter) convertParams(params []*contractMetadataParam) (fftypes.FFIParams, error) {
+	ffiParams := make(fftypes.FFIParams, len(params))
+	for i, param := range params {
+		if param == nil {
+			return nil, i18n.NewError(conv.ctx, coremsgs.MsgFFIGenerationFailed, "parameter is null")
+		}
+		schema, err := conv.resolveSchema(param.Schema, nil)
+		if err != nil {
+			return nil, err
+		}
+		if param.Description != "" {
+			if schemaMap, ok := schema.(map[string]interface{}); ok {
+				if _, ok := schemaMap["description"]; !ok {
+					schemaMap["description"] = param.Description
+				}
+			}
+		}
+		schemaBytes, _ := json.Marshal(schema)
+		ffiParams[i] = &fftypes.FFIParam{
+			Name:   param.Name,
+			Schema: fftypes.JSONAnyPtrBytes(schemaBytes),
+		}
+	}
+	return ffiParams, nil
+}
+
+// convertReturns handles each of the forms of "returns" generated by the contract-api implementations:
+// a bare schema, a single named parameter, or a list of named parameters
+func (conv *metadataConverter) convertReturns(returns json.RawMessage) (fftypes.FFIParams, error) {
+	if len(returns) == 0 {
+		return fftypes.FFIParams{}, nil
+	}
+	var params []*contractMetadataParam
+	if err := json.Unmarshal(returns, &params); err != nil {
+		var value map[string]interface{}
+		if err := json.Unmarshal(returns, &value); err != nil {
+			return nil, i18n.WrapError(conv.ctx, err, coremsgs.MsgFFIGenerationFailed, "unable to parse transaction returns")
+		}
+		if schema, ok := value["schema"]; ok {
+			name, _ := value["name"].(string)
+			params = []*contractMetadataParam{{Name: name, Schema: schema}}
+		} else {
+			params = []*contractMetadataParam{{Schema: value}}
+		}
+	}
+	return conv.convertParams(params)
+}
+
+// resolveSchema returns a copy of the supplied schema, with all references to the
+// component schemas of the metadata replaced inline
+func (conv *metadataConverter) resolveSchema(schema interface{}, refStack []string) (interface{}, error) {
+	switch s := schema.(type) {
+	case map[string]interface{}:
+		if ref, ok := s["$ref"].(string); ok {
+			return conv.resolveRef(ref, refStack)
+		}
+		resolved := make(map[string]interface{}, len(s))
+		for k, v := range s {
+			if k == "$id" {
+				// Identifiers are only meaningful within the metadata document, and would break compilation of the FFI schema
+				continue
+			}
+			rv, err := conv.resolveSchema(v, refStack)
+			if err != nil {
+				return nil, err
+			}
+			resolved[k] = rv
+		}
+		return resolved, nil
+	case []interface{}:
+		resolved := make([]interface{}, len(s))
+		for i, v := range s {
+			rv, err := conv.resolveSchema(v, refStack)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = rv
+		}
+		return resolved, nil
+	default:
+		return schema, nil
+	}
+}
+
+func (conv *metadataConverter) resolveRef(ref string, refStack []string) (interface{}, error) {
+	name := strings.TrimPrefix(ref, schemaRefPrefix)
+	target, ok := conv.schemas[name]
+	if !ok || !strings.HasPrefix(ref, schemaRefPrefix) {
+		return nil, i18n.NewError(conv.ctx, coremsgs.MsgFFIGenerationFailed, fmt.Sprintf("unable to resolve schema reference '%s'", ref))
+	}
+	for _, parent := range refStack {
+		if parent == name {
+			return nil, i18n.NewError(conv.ctx, coremsgs.MsgFFIGenerationFailed, fmt.Sprintf("circular schema reference '%s'", ref))
+		}
+	}
+	return conv.resolveSchema(target, append(refStack, name))
+}
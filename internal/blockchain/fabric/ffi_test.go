@@ -0,0 +1,323 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fabric
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+// A trimmed down version of the metadata returned by the asset-transfer-basic Go chaincode
+const testGoContractMetadata = `{
+	"info": {
+		"title": "asset_transfer",
+		"version": "latest"
+	},
+	"contracts": {
+		"SmartContract": {
+			"name": "SmartContract",
+			"default": true,
+			"info": {"title": "SmartContract", "version": "latest"},
+			"transactions": [
+				{
+					"name": "CreateAsset",
+					"tag": ["submit"],
+					"parameters": [
+						{"name": "param0", "schema": {"type": "string"}},
+						{"name": "param1", "schema": {"type": "integer", "format": "int64"}, "description": "the size"}
+					]
+				},
+				{
+					"name": "ReadAsset",
+					"tag": ["evaluate"],
+					"parameters": [
+						{"name": "param0", "schema": {"type": "string"}}
+					],
+					"returns": {"$ref": "#/components/schemas/Asset"}
+				},
+				{
+					"name": "GetAllAssets",
+					"returns": {"type": "array", "items": {"$ref": "#/components/schemas/Asset"}}
+				}
+			],
+			"events": [
+				{
+					"name": "AssetCreated",
+					"parameters": [
+						{"name": "ID", "schema": {"type": "string"}}
+					]
+				}
+			]
+		},
+		"org.hyperledger.fabric": {
+			"name": "org.hyperledger.fabric",
+			"transactions": [
+				{"name": "GetMetadata", "returns": {"type": "string"}}
+			]
+		}
+	},
+	"components": {
+		"schemas": {
+			"Asset": {
+				"$id": "Asset",
+				"type": "object",
+				"additionalProperties": false,
+				"properties": {
+					"ID": {"type": "string"},
+					"Size": {"type": "integer", "format": "int64"}
+				},
+				"required": ["ID", "Size"]
+			}
+		}
+	}
+}`
+
+func TestGenerateFFIGoMetadata(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+
+	ffi, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Namespace:   "ns1",
+		Name:        "assets",
+		Version:     "v1.0.0",
+		Description: "desc",
+		Input:       fftypes.JSONAnyPtr(`{"metadata":` + testGoContractMetadata + `}`),
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "ns1", ffi.Namespace)
+	assert.Equal(t, "assets", ffi.Name)
+	assert.Equal(t, "v1.0.0", ffi.Version)
+	assert.Equal(t, "desc", ffi.Description)
+
+	assert.Len(t, ffi.Methods, 3)
+	assert.Equal(t, "CreateAsset", ffi.Methods[0].Name)
+	assert.Equal(t, fftypes.JSONObject{"tag": []string{"submit"}}, ffi.Methods[0].Details)
+	assert.Len(t, ffi.Methods[0].Params, 2)
+	assert.Equal(t, "param0", ffi.Methods[0].Params[0].Name)
+	assert.JSONEq(t, `{"type":"string"}`, ffi.Methods[0].Params[0].Schema.String())
+	assert.Equal(t, "param1", ffi.Methods[0].Params[1].Name)
+	assert.JSONEq(t, `{"type":"integer","format":"int64","description":"the size"}`, ffi.Methods[0].Params[1].Schema.String())
+	assert.Empty(t, ffi.Methods[0].Returns)
+
+	assert.Equal(t, "ReadAsset", ffi.Methods[1].Name)
+	assert.Len(t, ffi.Methods[1].Returns, 1)
+	assert.Equal(t, "", ffi.Methods[1].Returns[0].Name)
+	assert.JSONEq(t, `{
+		"type": "object",
+		"additionalProperties": false,
+		"properties": {
+			"ID": {"type": "string"},
+			"Size": {"type": "integer", "format": "int64"}
+		},
+		"required": ["ID", "Size"]
+	}`, ffi.Methods[1].Returns[0].Schema.String())
+
+	assert.Equal(t, "GetAllAssets", ffi.Methods[2].Name)
+	assert.Nil(t, ffi.Methods[2].Details)
+	assert.Empty(t, ffi.Methods[2].Params)
+	assert.JSONEq(t, `{
+		"type": "array",
+		"items": {
+			"type": "object",
+			"additionalProperties": false,
+			"properties": {
+				"ID": {"type": "string"},
+				"Size": {"type": "integer", "format": "int64"}
+			},
+			"required": ["ID", "Size"]
+		}
+	}`, ffi.Methods[2].Returns[0].Schema.String())
+
+	assert.Len(t, ffi.Events, 1)
+	assert.Equal(t, "AssetCreated", ffi.Events[0].Name)
+	assert.Len(t, ffi.Events[0].Params, 1)
+	assert.Equal(t, "ID", ffi.Events[0].Params[0].Name)
+}
+
+func TestGenerateFFINodeMetadataMultipleContracts(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+
+	ffi, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Input: fftypes.JSONAnyPtr(`{
+			"metadata": {
+				"info": {"title": "fabcar", "version": "1.0.0", "description": "Cars"},
+				"contracts": {
+					"FabCar": {
+						"name": "FabCar",
+						"default": true,
+						"transactions": [
+							{
+								"name": "queryCar",
+								"parameters": [{"name": "carNumber", "description": "", "schema": {"type": "string"}}],
+								"returns": [{"name": "success", "schema": {"$ref": "#/components/schemas/Car"}}]
+							}
+						]
+					},
+					"Admin": {
+						"name": "Admin",
+						"transactions": [
+							{
+								"name": "reset",
+								"returns": {"name": "count", "schema": {"type": "number"}}
+							}
+						]
+					}
+				},
+				"components": {
+					"schemas": {
+						"Car": {
+							"$id": "Car",
+							"type": "object",
+							"properties": {
+								"owner": {"$ref": "#/components/schemas/Owner"},
+								"colours": {"type": "array", "items": [{"type": "string"}]}
+							}
+						},
+						"Owner": {"$id": "Owner", "type": "string"}
+					}
+				}
+			}
+		}`),
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "fabcar", ffi.Name)
+	assert.Equal(t, "1.0.0", ffi.Version)
+	assert.Equal(t, "Cars", ffi.Description)
+
+	assert.Len(t, ffi.Methods, 2)
+	assert.Equal(t, "Admin:reset", ffi.Methods[0].Name)
+	assert.Equal(t, "count", ffi.Methods[0].Returns[0].Name)
+	assert.JSONEq(t, `{"type":"number"}`, ffi.Methods[0].Returns[0].Schema.String())
+	assert.Equal(t, "queryCar", ffi.Methods[1].Name)
+	assert.Equal(t, "success", ffi.Methods[1].Returns[0].Name)
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"owner": {"type": "string"},
+			"colours": {"type": "array", "items": [{"type": "string"}]}
+		}
+	}`, ffi.Methods[1].Returns[0].Schema.String())
+	assert.Empty(t, ffi.Events)
+}
+
+func TestGenerateFFIBadJSON(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Input: fftypes.JSONAnyPtr(`{"metadata": []}`),
+	})
+	assert.Regexp(t, "FF10346.*deserialize", err)
+}
+
+func TestGenerateFFIEmpty(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Input: fftypes.JSONAnyPtr(`{}`),
+	})
+	assert.Regexp(t, "FF10346.*empty", err)
+}
+
+func TestGenerateFFISystemContractOnly(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Input: fftypes.JSONAnyPtr(`{"metadata": {"contracts": {"org.hyperledger.fabric": {"transactions": []}}}}`),
+	})
+	assert.Regexp(t, "FF10346.*empty", err)
+}
+
+func testGenerateFFITransaction(t *testing.T, tx string) error {
+	e, cancel := newTestFabric()
+	defer cancel()
+	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Input: fftypes.JSONAnyPtr(`{
+			"metadata": {
+				"contracts": {"c1": {"default": true, "transactions": [` + tx + `]}},
+				"components": {
+					"schemas": {
+						"A": {"type": "object", "properties": {"b": {"$ref": "#/components/schemas/B"}}},
+						"B": {"type": "array", "items": [{"$ref": "#/components/schemas/A"}]}
+					}
+				}
+			}
+		}`),
+	})
+	return err
+}
+
+func TestGenerateFFIBadParamRef(t *testing.T) {
+	err := testGenerateFFITransaction(t, `{"name": "tx1", "parameters": [{"name": "p1", "schema": {"$ref": "#/components/schemas/Missing"}}]}`)
+	assert.Regexp(t, "FF10346.*unable to resolve schema reference '#/components/schemas/Missing'", err)
+}
+
+func TestGenerateFFIExternalRef(t *testing.T) {
+	err := testGenerateFFITransaction(t, `{"name": "tx1", "parameters": [{"name": "p1", "schema": {"$ref": "A"}}]}`)
+	assert.Regexp(t, "FF10346.*unable to resolve schema reference 'A'", err)
+}
+
+func TestGenerateFFICircularRef(t *testing.T) {
+	err := testGenerateFFITransaction(t, `{"name": "tx1", "returns": {"$ref": "#/components/schemas/A"}}`)
+	assert.Regexp(t, "FF10346.*circular schema reference '#/components/schemas/A'", err)
+}
+
+func TestGenerateFFIBadReturns(t *testing.T) {
+	err := testGenerateFFITransaction(t, `{"name": "tx1", "returns": "string"}`)
+	assert.Regexp(t, "FF10346.*returns", err)
+}
+
+func TestGenerateFFIBadEventParam(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Input: fftypes.JSONAnyPtr(`{
+			"metadata": {
+				"contracts": {
+					"c1": {
+						"transactions": [],
+						"events": [{"name": "ev1", "parameters": [{"name": "p1", "schema": {"$ref": "#/components/schemas/Missing"}}]}]
+					}
+				}
+			}
+		}`),
+	})
+	assert.Regexp(t, "FF10346.*Missing", err)
+}
+
+func TestGenerateFFINullEntries(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	for contracts, expected := range map[string]string{
+		`{"c1": null}`:                                                                    "contract 'c1' is null",
+		`{"c1": {"transactions": [null]}}`:                                                "contract 'c1' has a null transaction",
+		`{"c1": {"transactions": [], "events": [null]}}`:                                  "contract 'c1' has a null event",
+		`{"c1": {"transactions": [{"name": "tx1", "parameters": [null]}]}}`:               "parameter is null",
+		`{"c1": {"transactions": [{"name": "tx1", "returns": [null]}]}}`:                  "parameter is null",
+		`{"c1": {"transactions": [], "events": [{"name": "ev1", "parameters": [null]}]}}`: "parameter is null",
+	} {
+		_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+			Input: fftypes.JSONAnyPtr(`{"metadata": {"contracts": ` + contracts + `}}`),
+		})
+		assert.Regexp(t, "FF10346.*"+expected, err, contracts)
+	}
+}
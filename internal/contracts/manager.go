@@ -20,16 +20,20 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/ffapi"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly/internal/batch"
 	"github.com/hyperledger/firefly/internal/broadcast"
+	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/identity"
+	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/operations"
 	"github.com/hyperledger/firefly/internal/privatemessaging"
 	"github.com/hyperledger/firefly/internal/syncasync"
@@ -43,6 +47,9 @@ import (
 type Manager interface {
 	core.Named
 
+	Start()
+	WaitStop()
+
 	GetFFI(ctx context.Context, name, version string) (*fftypes.FFI, error)
 	GetFFIWithChildren(ctx context.Context, name, version string) (*fftypes.FFI, error)
 	GetFFIByID(ctx context.Context, id *fftypes.UUID) (*fftypes.FFI, error)
@@ -55,6 +62,7 @@ type Manager interface {
 
 	DeployContract(ctx context.Context, req *core.ContractDeployRequest, waitConfirm bool) (interface{}, error)
 	InvokeContract(ctx context.Context, req *core.ContractCallRequest, waitConfirm bool) (interface{}, error)
+	InvokeContractBatch(ctx context.Context, req *core.ContractCallBatchRequest, waitConfirm bool) (*core.ContractCallBatchResponse, error)
 	InvokeContractAPI(ctx context.Context, apiName, methodPath string, req *core.ContractCallRequest, waitConfirm bool) (interface{}, error)
 	GetContractAPI(ctx context.Context, httpServerURL, apiName string) (*core.ContractAPI, error)
 	GetContractAPIInterface(ctx context.Context, apiName string) (*fftypes.FFI, error)
@@ -88,10 +96,14 @@ type contractManager struct {
 	ffiParamValidator fftypes.FFIParamValidator
 	operations        operations.Manager
 	syncasync         syncasync.Bridge
+	metrics           metrics.Manager
+	ctx               context.Context
+	statusInterval    time.Duration
+	statusPollDone    chan struct{}
 }
 
-func NewContractManager(ctx context.Context, ns string, di database.Plugin, bi blockchain.Plugin, dm data.Manager, bm broadcast.Manager, pm privatemessaging.Manager, bp batch.Manager, im identity.Manager, om operations.Manager, txHelper txcommon.Helper, sa syncasync.Bridge) (Manager, error) {
-	if di == nil || im == nil || bi == nil || dm == nil || om == nil || txHelper == nil || sa == nil {
+func NewContractManager(ctx context.Context, ns string, di database.Plugin, bi blockchain.Plugin, dm data.Manager, bm broadcast.Manager, pm privatemessaging.Manager, bp batch.Manager, im identity.Manager, om operations.Manager, txHelper txcommon.Helper, sa syncasync.Bridge, mm metrics.Manager) (Manager, error) {
+	if di == nil || im == nil || bi == nil || dm == nil || om == nil || txHelper == nil || sa == nil || mm == nil {
 		return nil, i18n.NewError(ctx, coremsgs.MsgInitializationNilDepError, "ContractManager")
 	}
 	v, err := bi.GetFFIParamValidator(ctx)
@@ -112,10 +124,14 @@ func NewContractManager(ctx context.Context, ns string, di database.Plugin, bi b
 		ffiParamValidator: v,
 		operations:        om,
 		syncasync:         sa,
+		metrics:           mm,
+		ctx:               ctx,
+		statusInterval:    config.GetDuration(coreconfig.MetricsContractListenerStatusInterval),
 	}
 
 	om.RegisterHandler(ctx, cm, []core.OpType{
 		core.OpTypeBlockchainInvoke,
+		core.OpTypeBlockchainInvokeBatch,
 		core.OpTypeBlockchainContractDeploy,
 	})
 
@@ -131,6 +147,53 @@ func (cm *contractManager) Name() string {
 	return "ContractManager"
 }
 
+// Start polls the status of the contract listeners, to keep their metrics current. This is only
+// started on the leader, so the blockchain connector is not queried by every process in a cluster.
+func (cm *contractManager) Start() {
+	if !cm.metrics.IsMetricsEnabled() || cm.statusInterval <= 0 {
+		return
+	}
+	cm.statusPollDone = make(chan struct{})
+	go cm.statusPollLoop()
+}
+
+func (cm *contractManager) WaitStop() {
+	if cm.statusPollDone != nil {
+		<-cm.statusPollDone
+	}
+}
+
+func (cm *contractManager) statusPollLoop() {
+	defer close(cm.statusPollDone)
+	ticker := time.NewTicker(cm.statusInterval)
+	defer ticker.Stop()
+	for {
+		cm.pollListenerStatus(cm.ctx)
+		select {
+		case <-ticker.C:
+		case <-cm.ctx.Done():
+			log.L(cm.ctx).Debugf("Contract listener status poller exiting")
+			return
+		}
+	}
+}
+
+func (cm *contractManager) pollListenerStatus(ctx context.Context) {
+	listeners, _, err := cm.database.GetContractListeners(ctx, cm.namespace, database.ContractListenerQueryFactory.NewFilter(ctx).And())
+	if err != nil {
+		log.L(ctx).Errorf("Failed to list contract listeners for status: %s", err)
+		return
+	}
+	for _, listener := range listeners {
+		_, status, err := cm.blockchain.GetContractListenerStatus(ctx, listener.BackendID, false)
+		if err != nil {
+			log.L(ctx).Warnf("Failed to get status of contract listener '%s': %s", listener.Name, err)
+		} else if status != nil {
+			cm.metrics.ContractListenerStatus(cm.namespace, listener.Name, status)
+		}
+	}
+}
+
 func (cm *contractManager) newFFISchemaCompiler() *jsonschema.Compiler {
 	c := fftypes.NewFFISchemaCompiler()
 	if cm.ffiParamValidator != nil {
@@ -265,6 +328,43 @@ func (cm *contractManager) writeInvokeTransaction(ctx context.Context, req *core
 	return op, err
 }
 
+func (cm *contractManager) writeInvokeBatchTransaction(ctx context.Context, req *core.ContractCallBatchRequest) ([]*core.Operation, error) {
+	txid, err := cm.txHelper.SubmitNewTransaction(ctx, core.TransactionTypeContractInvoke, req.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Atomic {
+		// A single operation submits all of the calls in one blockchain transaction
+		op := core.NewOperation(
+			cm.blockchain,
+			cm.namespace,
+			txid,
+			core.OpTypeBlockchainInvokeBatch)
+		if err = addBlockchainReqInputs(op, req); err == nil {
+			err = cm.operations.AddOrReuseOperation(ctx, op)
+		}
+		return []*core.Operation{op}, err
+	}
+
+	ops := make([]*core.Operation, len(req.Calls))
+	for i, call := range req.Calls {
+		op := core.NewOperation(
+			cm.blockchain,
+			cm.namespace,
+			txid,
+			core.OpTypeBlockchainInvoke)
+		if err = addBlockchainReqInputs(op, call); err == nil {
+			err = cm.operations.AddOrReuseOperation(ctx, op)
+		}
+		if err != nil {
+			return nil, err
+		}
+		ops[i] = op
+	}
+	return ops, nil
+}
+
 func (cm *contractManager) writeDeployTransaction(ctx context.Context, req *core.ContractDeployRequest) (*core.Operation, error) {
 	txid, err := cm.txHelper.SubmitNewTransaction(ctx, core.TransactionTypeContractDeploy, req.IdempotencyKey)
 	if err != nil {
@@ -377,6 +477,116 @@ func (cm *contractManager) InvokeContract(ctx context.Context, req *core.Contrac
 	}
 }
 
+func (cm *contractManager) resolveInvokeBatchKeys(ctx context.Context, req *core.ContractCallBatchRequest) (err error) {
+	req.Key, err = cm.identity.ResolveInputSigningKey(ctx, req.Key, identity.KeyNormalizationBlockchainPlugin)
+	if err != nil {
+		return err
+	}
+	for i, call := range req.Calls {
+		if call.Message != nil {
+			return i18n.WrapError(ctx, i18n.NewError(ctx, coremsgs.MsgContractBatchCallMessage), coremsgs.MsgContractBatchCallInvalid, i)
+		}
+		call.Type = core.CallTypeInvoke
+		if call.Key == "" {
+			call.Key = req.Key
+		} else if call.Key, err = cm.identity.ResolveInputSigningKey(ctx, call.Key, identity.KeyNormalizationBlockchainPlugin); err != nil {
+			return i18n.WrapError(ctx, err, coremsgs.MsgContractBatchCallInvalid, i)
+		}
+		if req.Atomic && call.Key != req.Key {
+			return i18n.WrapError(ctx, i18n.NewError(ctx, coremsgs.MsgContractBatchKeyMismatch), coremsgs.MsgContractBatchCallInvalid, i)
+		}
+	}
+	return nil
+}
+
+func (cm *contractManager) InvokeContractBatch(ctx context.Context, req *core.ContractCallBatchRequest, waitConfirm bool) (res *core.ContractCallBatchResponse, err error) {
+	if len(req.Calls) == 0 {
+		return nil, i18n.NewError(ctx, coremsgs.MsgContractBatchNoCalls)
+	}
+	if req.Atomic && !cm.blockchain.Capabilities().AtomicInvokeBatch {
+		return nil, i18n.NewError(ctx, coremsgs.MsgContractBatchAtomicNotSupported, cm.blockchain.Name())
+	}
+	if err = cm.resolveInvokeBatchKeys(ctx, req); err != nil {
+		return nil, err
+	}
+
+	var ops []*core.Operation
+	err = cm.database.RunAsGroup(ctx, func(ctx context.Context) (err error) {
+		for i, call := range req.Calls {
+			if err = cm.resolveInvokeContractRequest(ctx, call); err == nil {
+				err = cm.validateInvokeContractRequest(ctx, call)
+			}
+			if err != nil {
+				return i18n.WrapError(ctx, err, coremsgs.MsgContractBatchCallInvalid, i)
+			}
+		}
+		ops, err = cm.writeInvokeBatchTransaction(ctx, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res = &core.ContractCallBatchResponse{
+		Transaction: ops[0].Transaction,
+		Atomic:      req.Atomic,
+		Calls:       make([]*core.ContractCallBatchResult, len(req.Calls)),
+	}
+
+	if req.Atomic {
+		op := ops[0]
+		send := func(ctx context.Context) error {
+			_, err := cm.operations.RunOperation(ctx, opBlockchainInvokeBatch(op, req))
+			return err
+		}
+		if waitConfirm {
+			var confirmed *core.Operation
+			if confirmed, err = cm.syncasync.WaitForInvokeOperation(ctx, op.ID, send); err == nil {
+				op = confirmed
+			}
+		} else {
+			err = send(ctx)
+		}
+		var outputs []*fftypes.JSONAny
+		if err == nil && op.Status == core.OpStatusSucceeded {
+			// The transaction has been confirmed, so failing to decode the results does not fail the request
+			if outputs, err = cm.blockchain.DecodeInvokeBatchOutput(ctx, batchContractCalls(req), op.Output); err != nil {
+				log.L(ctx).Warnf("Unable to decode the results of atomic batch operation %s: %s", op.ID, err)
+				outputs, err = nil, nil
+			}
+		}
+		for i := range req.Calls {
+			res.Calls[i] = &core.ContractCallBatchResult{Index: i, Operation: op}
+			if outputs != nil {
+				res.Calls[i].Output = outputs[i]
+			}
+		}
+		return res, err
+	}
+
+	// Each call is submitted in order. When waiting for confirmation, each call is confirmed before
+	// the next is submitted. Submission stops at the first failure, leaving any remaining
+	// operations in the transaction to be retried.
+	for i, call := range req.Calls {
+		op, call := ops[i], call
+		res.Calls[i] = &core.ContractCallBatchResult{Index: i, Operation: op}
+		send := func(ctx context.Context) error {
+			_, err := cm.operations.RunOperation(ctx, txcommon.OpBlockchainInvoke(op, call, nil))
+			return err
+		}
+		if waitConfirm {
+			confirmed, err := cm.syncasync.WaitForInvokeOperation(ctx, op.ID, send)
+			if err != nil {
+				return res, err
+			}
+			res.Calls[i].Operation = confirmed
+		} else if err = send(ctx); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
 func (cm *contractManager) InvokeContractAPI(ctx context.Context, apiName, methodPath string, req *core.ContractCallRequest, waitConfirm bool) (interface{}, error) {
 	api, err := cm.database.GetContractAPIByName(ctx, cm.namespace, apiName)
 	if err != nil {
@@ -684,7 +894,18 @@ func (cm *contractManager) AddContractListener(ctx context.Context, listener *co
 		return nil, err
 	}
 
-	if listener.Location != nil {
+	if len(listener.Filters) > 0 {
+		if listener.Event != nil || listener.EventPath != "" || listener.Interface != nil || listener.Location != nil {
+			return nil, i18n.NewError(ctx, coremsgs.MsgListenerFiltersAndEvent)
+		}
+		for _, filter := range listener.Filters {
+			if filter.Location != nil {
+				if filter.Location, err = cm.blockchain.NormalizeContractLocation(ctx, blockchain.NormalizeListener, filter.Location); err != nil {
+					return nil, err
+				}
+			}
+		}
+	} else if listener.Location != nil {
 		if listener.Location, err = cm.blockchain.NormalizeContractLocation(ctx, blockchain.NormalizeListener, listener.Location); err != nil {
 			return nil, err
 		}
@@ -706,20 +927,26 @@ func (cm *contractManager) AddContractListener(ctx context.Context, listener *co
 			}
 		}
 
-		if listener.Event == nil {
-			if listener.EventPath == "" || listener.Interface == nil {
-				return i18n.NewError(ctx, coremsgs.MsgListenerNoEvent)
-			}
-			// Copy the event definition into the listener
-			if listener.Event, err = cm.resolveEvent(ctx, listener.Interface, listener.EventPath); err != nil {
+		if len(listener.Filters) > 0 {
+			if err := cm.resolveListenerFilters(ctx, listener); err != nil {
 				return err
 			}
 		} else {
-			listener.Interface = nil
+			if listener.Event == nil {
+				if listener.EventPath == "" || listener.Interface == nil {
+					return i18n.NewError(ctx, coremsgs.MsgListenerNoEvent)
+				}
+				// Copy the event definition into the listener
+				if listener.Event, err = cm.resolveEvent(ctx, listener.Interface, listener.EventPath); err != nil {
+					return err
+				}
+			} else {
+				listener.Interface = nil
+			}
+			listener.Signature = cm.blockchain.GenerateEventSignature(ctx, &listener.Event.FFIEventDefinition)
 		}
 
 		// Namespace + Topic + Location + Signature must be unique
-		listener.Signature = cm.blockchain.GenerateEventSignature(ctx, &listener.Event.FFIEventDefinition)
 		fb := database.ContractListenerQueryFactory.NewFilter(ctx)
 		if existing, _, err := cm.database.GetContractListeners(ctx, cm.namespace, fb.And(
 			fb.Eq("topic", listener.Topic),
@@ -736,8 +963,15 @@ func (cm *contractManager) AddContractListener(ctx context.Context, listener *co
 		return nil, err
 	}
 
-	if err := cm.validateFFIEvent(ctx, &listener.Event.FFIEventDefinition); err != nil {
-		return nil, err
+	for _, filter := range listener.ContractListener.Filters {
+		if err := cm.validateFFIEvent(ctx, &filter.Event.FFIEventDefinition); err != nil {
+			return nil, err
+		}
+	}
+	if len(listener.ContractListener.Filters) == 0 {
+		if err := cm.validateFFIEvent(ctx, &listener.Event.FFIEventDefinition); err != nil {
+			return nil, err
+		}
 	}
 	if err = cm.blockchain.AddContractListener(ctx, &listener.ContractListener); err != nil {
 		return nil, err
@@ -752,6 +986,43 @@ func (cm *contractManager) AddContractListener(ctx context.Context, listener *co
 	return &listener.ContractListener, err
 }
 
+// resolveListenerFilters resolves the event of each filter on the listener, and generates the signature
+// that is used to match each filter against received events. A listener with a single filter is also
+// given the event and location of that filter, so it is stored in the same way as a listener without filters.
+func (cm *contractManager) resolveListenerFilters(ctx context.Context, listener *core.ContractListenerInput) (err error) {
+	filters := make(core.ListenerFilters, len(listener.Filters))
+	signatures := make([]string, len(listener.Filters))
+	for i, input := range listener.Filters {
+		filter := &input.ListenerFilter
+		if filter.Event == nil {
+			if input.EventPath == "" || filter.Interface == nil {
+				return i18n.NewError(ctx, coremsgs.MsgListenerNoEvent)
+			}
+			if filter.Event, err = cm.resolveEvent(ctx, filter.Interface, input.EventPath); err != nil {
+				return err
+			}
+		} else {
+			filter.Interface = nil
+		}
+		if filter.Signature, err = cm.blockchain.GenerateEventSignatureWithLocation(ctx, &filter.Event.FFIEventDefinition, filter.Location); err != nil {
+			return err
+		}
+		filters[i] = filter
+		signatures[i] = filter.Signature
+	}
+	listener.ContractListener.Filters = filters
+
+	if len(filters) == 1 {
+		listener.Interface = filters[0].Interface
+		listener.Event = filters[0].Event
+		listener.Location = filters[0].Location
+		listener.Signature = cm.blockchain.GenerateEventSignature(ctx, &listener.Event.FFIEventDefinition)
+	} else {
+		listener.Signature = strings.Join(signatures, ";")
+	}
+	return nil
+}
+
 func (cm *contractManager) AddContractAPIListener(ctx context.Context, apiName, eventPath string, listener *core.ContractListener) (output *core.ContractListener, err error) {
 	api, err := cm.database.GetContractAPIByName(ctx, cm.namespace, apiName)
 	if err != nil {
@@ -795,9 +1066,11 @@ func (cm *contractManager) GetContractListenerByNameOrIDWithStatus(ctx context.C
 	}
 	_, status, err := cm.blockchain.GetContractListenerStatus(ctx, listener.BackendID, false)
 	if err != nil {
-		status = core.ListenerStatusError{
-			StatusError: err.Error(),
+		status = &core.ContractListenerStatus{
+			Error: err.Error(),
 		}
+	} else if status != nil && cm.metrics.IsMetricsEnabled() {
+		cm.metrics.ContractListenerStatus(cm.namespace, listener.Name, status)
 	}
 	enrichedListener = &core.ContractListenerWithStatus{
 		ContractListener: *listener,
@@ -844,7 +1117,13 @@ func (cm *contractManager) DeleteContractListenerByNameOrID(ctx context.Context,
 		if err = cm.blockchain.DeleteContractListener(ctx, listener, true /* ok if not found */); err != nil {
 			return err
 		}
-		return cm.database.DeleteContractListenerByID(ctx, cm.namespace, listener.ID)
+		if err = cm.database.DeleteContractListenerByID(ctx, cm.namespace, listener.ID); err != nil {
+			return err
+		}
+		if cm.metrics.IsMetricsEnabled() {
+			cm.metrics.ContractListenerDeleted(cm.namespace, listener.Name)
+		}
+		return nil
 	})
 }
 
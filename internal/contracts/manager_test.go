@@ -38,6 +38,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
 	"github.com/hyperledger/firefly/mocks/operationmocks"
 	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
 	"github.com/hyperledger/firefly/mocks/syncasyncmocks"
@@ -64,10 +65,12 @@ func newTestContractManager() *contractManager {
 	cmi.On("GetCache", mock.Anything).Return(cache.NewUmanagedCache(ctx, 100, 5*time.Minute), nil)
 	txHelper, _ := txcommon.NewTransactionHelper(ctx, "ns1", mdi, mdm, cmi)
 	msa := &syncasyncmocks.Bridge{}
+	mmm := &metricsmocks.Manager{}
 	mbi.On("GetFFIParamValidator", mock.Anything).Return(nil, nil)
 	mom.On("RegisterHandler", mock.Anything, mock.Anything, mock.Anything)
 
 	mbi.On("Name").Return("mockblockchain").Maybe()
+	mmm.On("IsMetricsEnabled").Return(false).Maybe()
 
 	mdi.On("GetContractListeners", mock.Anything, "ns1", mock.Anything).Return(nil, nil, nil).Once()
 	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything).Maybe()
@@ -76,13 +79,13 @@ func newTestContractManager() *contractManager {
 			a[1].(func(context.Context) error)(a[0].(context.Context)),
 		}
 	}
-	cm, _ := NewContractManager(context.Background(), "ns1", mdi, mbi, mdm, mbm, mpm, mbp, mim, mom, txHelper, msa)
+	cm, _ := NewContractManager(context.Background(), "ns1", mdi, mbi, mdm, mbm, mpm, mbp, mim, mom, txHelper, msa, mmm)
 	cm.(*contractManager).txHelper = &txcommonmocks.Helper{}
 	return cm.(*contractManager)
 }
 
 func TestNewContractManagerFail(t *testing.T) {
-	_, err := NewContractManager(context.Background(), "", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	_, err := NewContractManager(context.Background(), "", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	assert.Regexp(t, "FF10128", err)
 }
 
@@ -91,6 +94,55 @@ func TestName(t *testing.T) {
 	assert.Equal(t, "ContractManager", cm.Name())
 }
 
+func TestStartMetricsDisabled(t *testing.T) {
+	cm := newTestContractManager()
+	cm.Start()
+	assert.Nil(t, cm.statusPollDone)
+	cm.WaitStop()
+}
+
+func TestStartPollListenerStatus(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mmm := &metricsmocks.Manager{}
+	cm.metrics = mmm
+	ctx, cancel := context.WithCancel(context.Background())
+	cm.ctx = ctx
+	cm.statusInterval = time.Minute
+
+	status := &core.ContractListenerStatus{
+		Checkpoint: &core.ContractListenerCheckpoint{Block: 10},
+	}
+	mmm.On("IsMetricsEnabled").Return(true)
+	mdi.On("GetContractListeners", ctx, "ns1", mock.Anything).Return([]*core.ContractListener{
+		{Name: "listener1", BackendID: "id1"},
+		{Name: "listener2", BackendID: "id2"},
+	}, nil, nil)
+	mbi.On("GetContractListenerStatus", ctx, "id1", false).Return(true, status, nil)
+	mbi.On("GetContractListenerStatus", ctx, "id2", false).Return(false, nil, fmt.Errorf("pop"))
+	mmm.On("ContractListenerStatus", "ns1", "listener1", status).Run(func(args mock.Arguments) {
+		cancel()
+	}).Return()
+
+	cm.Start()
+	cm.WaitStop()
+
+	mmm.AssertExpectations(t)
+	mbi.AssertExpectations(t)
+}
+
+func TestPollListenerStatusListFail(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetContractListeners", context.Background(), "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	cm.pollListenerStatus(context.Background())
+
+	mdi.AssertExpectations(t)
+}
+
 func TestNewContractManagerFFISchemaLoaderFail(t *testing.T) {
 	mdi := &databasemocks.Plugin{}
 	mdm := &datamocks.Manager{}
@@ -105,8 +157,9 @@ func TestNewContractManagerFFISchemaLoaderFail(t *testing.T) {
 	cmi.On("GetCache", mock.Anything).Return(cache.NewUmanagedCache(ctx, 100, 5*time.Minute), nil)
 	txHelper, _ := txcommon.NewTransactionHelper(ctx, "ns1", mdi, mdm, cmi)
 	msa := &syncasyncmocks.Bridge{}
+	mmm := &metricsmocks.Manager{}
 	mbi.On("GetFFIParamValidator", mock.Anything).Return(nil, fmt.Errorf("pop"))
-	_, err := NewContractManager(context.Background(), "ns1", mdi, mbi, mdm, mbm, mpm, mbp, mim, mom, txHelper, msa)
+	_, err := NewContractManager(context.Background(), "ns1", mdi, mbi, mdm, mbm, mpm, mbp, mim, mom, txHelper, msa, mmm)
 	assert.Regexp(t, "pop", err)
 }
 
@@ -124,10 +177,11 @@ func TestNewContractManagerFFISchemaLoader(t *testing.T) {
 	cmi.On("GetCache", mock.Anything).Return(cache.NewUmanagedCache(ctx, 100, 5*time.Minute), nil)
 	txHelper, _ := txcommon.NewTransactionHelper(ctx, "ns1", mdi, mdm, cmi)
 	msa := &syncasyncmocks.Bridge{}
+	mmm := &metricsmocks.Manager{}
 	mdi.On("GetContractListeners", mock.Anything, "ns1", mock.Anything).Return(nil, nil, nil)
 	mbi.On("GetFFIParamValidator", mock.Anything).Return(&ffi2abi.ParamValidator{}, nil)
 	mom.On("RegisterHandler", mock.Anything, mock.Anything, mock.Anything)
-	_, err := NewContractManager(context.Background(), "ns1", mdi, mbi, mdm, mbm, mpm, mbp, mim, mom, txHelper, msa)
+	_, err := NewContractManager(context.Background(), "ns1", mdi, mbi, mdm, mbm, mpm, mbp, mim, mom, txHelper, msa, mmm)
 	assert.NoError(t, err)
 }
 
@@ -822,6 +876,292 @@ func TestAddContractListenerByEventPath(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestAddContractListenerFilters(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	interfaceID := fftypes.NewUUID()
+	location := fftypes.JSONAnyPtr(fftypes.JSONObject{
+		"address": "0x123",
+	}.String())
+	event := &fftypes.FFIEvent{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		FFIEventDefinition: fftypes.FFIEventDefinition{
+			Name: "changed",
+			Params: fftypes.FFIParams{
+				{
+					Name:   "value",
+					Schema: fftypes.JSONAnyPtr(`{"type": "integer"}`),
+				},
+			},
+		},
+	}
+
+	sub := &core.ContractListenerInput{
+		ContractListener: core.ContractListener{
+			Topic: "test-topic",
+		},
+		Filters: []*core.ListenerFilterInput{
+			{
+				ListenerFilter: core.ListenerFilter{
+					Interface: &fftypes.FFIReference{
+						ID: interfaceID,
+					},
+					Location: location,
+					Indexed:  fftypes.JSONObject{"from": "0x456"},
+				},
+				EventPath: "changed",
+			},
+			{
+				ListenerFilter: core.ListenerFilter{
+					Event: &core.FFISerializedEvent{
+						FFIEventDefinition: fftypes.FFIEventDefinition{
+							Name: "transfer",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	mbi.On("NormalizeContractLocation", context.Background(), blockchain.NormalizeListener, location).Return(location, nil)
+	mdi.On("GetFFIByID", context.Background(), "ns1", interfaceID).Return(&fftypes.FFI{}, nil)
+	mdi.On("GetFFIEvent", context.Background(), "ns1", interfaceID, "changed").Return(event, nil)
+	mbi.On("GenerateEventSignatureWithLocation", context.Background(), &event.FFIEventDefinition, location).Return("address=0x123:changed", nil)
+	mbi.On("GenerateEventSignatureWithLocation", context.Background(), mock.MatchedBy(func(e *fftypes.FFIEventDefinition) bool {
+		return e.Name == "transfer"
+	}), (*fftypes.JSONAny)(nil)).Return("transfer", nil)
+	mdi.On("GetContractListeners", context.Background(), "ns1", mock.Anything).Return(nil, nil, nil)
+	mbi.On("AddContractListener", context.Background(), &sub.ContractListener).Return(nil)
+	mdi.On("InsertContractListener", context.Background(), &sub.ContractListener).Return(nil)
+
+	result, err := cm.AddContractListener(context.Background(), sub)
+	assert.NoError(t, err)
+	assert.NotNil(t, result.ID)
+	assert.Nil(t, result.Event)
+	assert.Nil(t, result.Location)
+	assert.Equal(t, "address=0x123:changed;transfer", result.Signature)
+	assert.Len(t, result.Filters, 2)
+	assert.Equal(t, "changed", result.Filters[0].Event.Name)
+	assert.Equal(t, "address=0x123:changed", result.Filters[0].Signature)
+	assert.Equal(t, "0x456", result.Filters[0].Indexed.GetString("from"))
+	assert.Equal(t, "transfer", result.Filters[1].Signature)
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestAddContractListenerSingleFilter(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	location := fftypes.JSONAnyPtr(fftypes.JSONObject{
+		"address": "0x123",
+	}.String())
+	sub := &core.ContractListenerInput{
+		ContractListener: core.ContractListener{
+			Topic: "test-topic",
+		},
+		Filters: []*core.ListenerFilterInput{
+			{
+				ListenerFilter: core.ListenerFilter{
+					Event: &core.FFISerializedEvent{
+						FFIEventDefinition: fftypes.FFIEventDefinition{
+							Name: "changed",
+						},
+					},
+					Location: location,
+				},
+			},
+		},
+	}
+
+	mbi.On("NormalizeContractLocation", context.Background(), blockchain.NormalizeListener, location).Return(location, nil)
+	mbi.On("GenerateEventSignatureWithLocation", context.Background(), mock.Anything, location).Return("address=0x123:changed", nil)
+	mbi.On("GenerateEventSignature", context.Background(), mock.Anything).Return("changed")
+	mdi.On("GetContractListeners", context.Background(), "ns1", mock.Anything).Return(nil, nil, nil)
+	mbi.On("AddContractListener", context.Background(), &sub.ContractListener).Return(nil)
+	mdi.On("InsertContractListener", context.Background(), &sub.ContractListener).Return(nil)
+
+	result, err := cm.AddContractListener(context.Background(), sub)
+	assert.NoError(t, err)
+	assert.Equal(t, "changed", result.Event.Name)
+	assert.Equal(t, location, result.Location)
+	assert.Equal(t, "changed", result.Signature)
+	assert.Len(t, result.Filters, 1)
+	assert.Equal(t, "address=0x123:changed", result.Filters[0].Signature)
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestAddContractListenerFiltersAndEvent(t *testing.T) {
+	cm := newTestContractManager()
+
+	sub := &core.ContractListenerInput{
+		ContractListener: core.ContractListener{
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"address": "0x123",
+			}.String()),
+			Topic: "test-topic",
+		},
+		Filters: []*core.ListenerFilterInput{
+			{EventPath: "changed"},
+		},
+	}
+
+	_, err := cm.AddContractListener(context.Background(), sub)
+	assert.Regexp(t, "FF10514", err)
+}
+
+func TestAddContractListenerFilterBadLocation(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+
+	location := fftypes.JSONAnyPtr(fftypes.JSONObject{
+		"address": "0x123",
+	}.String())
+	sub := &core.ContractListenerInput{
+		ContractListener: core.ContractListener{
+			Topic: "test-topic",
+		},
+		Filters: []*core.ListenerFilterInput{
+			{
+				ListenerFilter: core.ListenerFilter{
+					Location: location,
+				},
+			},
+		},
+	}
+
+	mbi.On("NormalizeContractLocation", context.Background(), blockchain.NormalizeListener, location).Return(nil, fmt.Errorf("pop"))
+
+	_, err := cm.AddContractListener(context.Background(), sub)
+	assert.EqualError(t, err, "pop")
+
+	mbi.AssertExpectations(t)
+}
+
+func TestAddContractListenerFilterMissingEvent(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := &core.ContractListenerInput{
+		ContractListener: core.ContractListener{
+			Topic: "test-topic",
+		},
+		Filters: []*core.ListenerFilterInput{
+			{EventPath: "changed"},
+		},
+	}
+
+	_, err := cm.AddContractListener(context.Background(), sub)
+	assert.Regexp(t, "FF10317", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestAddContractListenerFilterEventLookupFail(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	interfaceID := fftypes.NewUUID()
+	sub := &core.ContractListenerInput{
+		ContractListener: core.ContractListener{
+			Topic: "test-topic",
+		},
+		Filters: []*core.ListenerFilterInput{
+			{
+				ListenerFilter: core.ListenerFilter{
+					Interface: &fftypes.FFIReference{
+						ID: interfaceID,
+					},
+				},
+				EventPath: "changed",
+			},
+		},
+	}
+
+	mdi.On("GetFFIByID", context.Background(), "ns1", interfaceID).Return(&fftypes.FFI{}, nil)
+	mdi.On("GetFFIEvent", context.Background(), "ns1", interfaceID, "changed").Return(nil, fmt.Errorf("pop"))
+
+	_, err := cm.AddContractListener(context.Background(), sub)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestAddContractListenerFilterSignatureFail(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+
+	sub := &core.ContractListenerInput{
+		ContractListener: core.ContractListener{
+			Topic: "test-topic",
+		},
+		Filters: []*core.ListenerFilterInput{
+			{
+				ListenerFilter: core.ListenerFilter{
+					Event: &core.FFISerializedEvent{},
+				},
+			},
+		},
+	}
+
+	mbi.On("GenerateEventSignatureWithLocation", context.Background(), mock.Anything, (*fftypes.JSONAny)(nil)).Return("", fmt.Errorf("pop"))
+
+	_, err := cm.AddContractListener(context.Background(), sub)
+	assert.EqualError(t, err, "pop")
+
+	mbi.AssertExpectations(t)
+}
+
+func TestAddContractListenerFilterValidateFail(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := &core.ContractListenerInput{
+		ContractListener: core.ContractListener{
+			Topic: "test-topic",
+		},
+		Filters: []*core.ListenerFilterInput{
+			{
+				ListenerFilter: core.ListenerFilter{
+					Event: &core.FFISerializedEvent{
+						FFIEventDefinition: fftypes.FFIEventDefinition{
+							Name: "changed",
+							Params: fftypes.FFIParams{
+								{
+									Name:   "value",
+									Schema: fftypes.JSONAnyPtr(`{"type": "null"}`),
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				ListenerFilter: core.ListenerFilter{
+					Event: &core.FFISerializedEvent{},
+				},
+			},
+		},
+	}
+
+	mbi.On("GenerateEventSignatureWithLocation", context.Background(), mock.Anything, (*fftypes.JSONAny)(nil)).Return("changed", nil)
+	mdi.On("GetContractListeners", context.Background(), "ns1", mock.Anything).Return(nil, nil, nil)
+
+	_, err := cm.AddContractListener(context.Background(), sub)
+	assert.Regexp(t, "does not validate", err)
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
 func TestAddContractListenerBadLocation(t *testing.T) {
 	cm := newTestContractManager()
 	mbi := cm.blockchain.(*blockchainmocks.Plugin)
@@ -979,7 +1319,7 @@ func TestAddContractListenerVerifyOk(t *testing.T) {
 	})).Return([]*core.ContractListener{}, nil, nil).Once()
 
 	mbi := cm.blockchain.(*blockchainmocks.Plugin)
-	mbi.On("GetContractListenerStatus", ctx, "12345", true).Return(true, struct{}{}, nil)
+	mbi.On("GetContractListenerStatus", ctx, "12345", true).Return(true, &core.ContractListenerStatus{}, nil)
 	mbi.On("GetContractListenerStatus", ctx, "23456", true).Return(false, nil, nil)
 	mbi.On("AddContractListener", ctx, mock.MatchedBy(func(l *core.ContractListener) bool {
 		prevBackendID := l.BackendID
@@ -1014,7 +1354,7 @@ func TestAddContractListenerVerifyUpdateFail(t *testing.T) {
 	}, nil, nil).Once()
 
 	mbi := cm.blockchain.(*blockchainmocks.Plugin)
-	mbi.On("GetContractListenerStatus", ctx, "12345", true).Return(true, struct{}{}, nil)
+	mbi.On("GetContractListenerStatus", ctx, "12345", true).Return(true, &core.ContractListenerStatus{}, nil)
 	mbi.On("GetContractListenerStatus", ctx, "23456", true).Return(false, nil, nil)
 	mbi.On("AddContractListener", ctx, mock.MatchedBy(func(l *core.ContractListener) bool {
 		prevBackendID := l.BackendID
@@ -1049,7 +1389,7 @@ func TestAddContractListenerVerifyAddFail(t *testing.T) {
 	}, nil, nil).Once()
 
 	mbi := cm.blockchain.(*blockchainmocks.Plugin)
-	mbi.On("GetContractListenerStatus", ctx, "12345", true).Return(true, struct{}{}, nil)
+	mbi.On("GetContractListenerStatus", ctx, "12345", true).Return(true, &core.ContractListenerStatus{}, nil)
 	mbi.On("GetContractListenerStatus", ctx, "23456", true).Return(false, nil, nil)
 	mbi.On("AddContractListener", ctx, mock.MatchedBy(func(l *core.ContractListener) bool {
 		prevBackendID := l.BackendID
@@ -2341,6 +2681,389 @@ func TestInvokeContractMethodBadInput(t *testing.T) {
 	assert.Regexp(t, "FF10304", err)
 }
 
+func newTestInvokeBatchCalls() []*core.ContractCallRequest {
+	return []*core.ContractCallRequest{
+		{
+			Location: fftypes.JSONAnyPtr(`{"address":"0x1"}`),
+			Method: &fftypes.FFIMethod{
+				Name:    "approve",
+				Params:  fftypes.FFIParams{},
+				Returns: fftypes.FFIParams{},
+			},
+		},
+		{
+			Location: fftypes.JSONAnyPtr(`{"address":"0x2"}`),
+			Method: &fftypes.FFIMethod{
+				Name:    "transfer",
+				Params:  fftypes.FFIParams{},
+				Returns: fftypes.FFIParams{},
+			},
+		},
+	}
+}
+
+func TestInvokeContractBatch(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mom := cm.operations.(*operationmocks.Manager)
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+
+	txID := fftypes.NewUUID()
+	req := &core.ContractCallBatchRequest{
+		Calls:          newTestInvokeBatchCalls(),
+		IdempotencyKey: "idem1",
+	}
+	req.Calls[1].Key = "key2"
+
+	mim.On("ResolveInputSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mim.On("ResolveInputSigningKey", mock.Anything, "key2", identity.KeyNormalizationBlockchainPlugin).Return("key2-resolved", nil)
+	mth.On("SubmitNewTransaction", mock.Anything, core.TransactionTypeContractInvoke, core.IdempotencyKey("idem1")).Return(txID, nil)
+	mom.On("AddOrReuseOperation", mock.Anything, mock.MatchedBy(func(op *core.Operation) bool {
+		return op.Namespace == "ns1" && op.Type == core.OpTypeBlockchainInvoke && op.Transaction.Equals(txID)
+	})).Return(nil).Twice()
+	mom.On("RunOperation", mock.Anything, mock.MatchedBy(func(op *core.PreparedOperation) bool {
+		data := op.Data.(txcommon.BlockchainInvokeData)
+		return data.Request == req.Calls[0] && data.Request.Key == "key-resolved"
+	})).Return(nil, nil).Once()
+	mom.On("RunOperation", mock.Anything, mock.MatchedBy(func(op *core.PreparedOperation) bool {
+		data := op.Data.(txcommon.BlockchainInvokeData)
+		return data.Request == req.Calls[1] && data.Request.Key == "key2-resolved"
+	})).Return(nil, nil).Once()
+	mbi.On("ValidateInvokeRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, false).Return(nil).Twice()
+
+	res, err := cm.InvokeContractBatch(context.Background(), req, false)
+	assert.NoError(t, err)
+	assert.Equal(t, txID, res.Transaction)
+	assert.False(t, res.Atomic)
+	assert.Len(t, res.Calls, 2)
+	assert.Equal(t, 1, res.Calls[1].Index)
+	assert.NotEqual(t, res.Calls[0].Operation.ID, res.Calls[1].Operation.ID)
+	assert.Equal(t, core.CallTypeInvoke, req.Calls[0].Type)
+
+	mth.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mom.AssertExpectations(t)
+	mbi.AssertExpectations(t)
+}
+
+func TestInvokeContractBatchConfirm(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mom := cm.operations.(*operationmocks.Manager)
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	msa := cm.syncasync.(*syncasyncmocks.Bridge)
+
+	req := &core.ContractCallBatchRequest{
+		Calls: newTestInvokeBatchCalls(),
+	}
+
+	confirmed := &core.Operation{Status: core.OpStatusSucceeded}
+	mim.On("ResolveInputSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mth.On("SubmitNewTransaction", mock.Anything, core.TransactionTypeContractInvoke, core.IdempotencyKey("")).Return(fftypes.NewUUID(), nil)
+	mom.On("AddOrReuseOperation", mock.Anything, mock.Anything).Return(nil)
+	mom.On("RunOperation", mock.Anything, mock.Anything).Return(nil, nil)
+	msa.On("WaitForInvokeOperation", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			send := args[2].(syncasync.SendFunction)
+			send(context.Background())
+		}).
+		Return(confirmed, nil).Once()
+	msa.On("WaitForInvokeOperation", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("pop")).Once()
+	mbi.On("ValidateInvokeRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, false).Return(nil)
+
+	res, err := cm.InvokeContractBatch(context.Background(), req, true)
+	assert.EqualError(t, err, "pop")
+	assert.Equal(t, confirmed, res.Calls[0].Operation)
+	assert.NotNil(t, res.Calls[1].Operation)
+
+	mth.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mom.AssertExpectations(t)
+	msa.AssertExpectations(t)
+	mom.AssertNumberOfCalls(t, "RunOperation", 1)
+}
+
+func TestInvokeContractBatchSendFail(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mom := cm.operations.(*operationmocks.Manager)
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+
+	req := &core.ContractCallBatchRequest{
+		Calls: newTestInvokeBatchCalls(),
+	}
+
+	mim.On("ResolveInputSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mth.On("SubmitNewTransaction", mock.Anything, core.TransactionTypeContractInvoke, core.IdempotencyKey("")).Return(fftypes.NewUUID(), nil)
+	mom.On("AddOrReuseOperation", mock.Anything, mock.Anything).Return(nil)
+	mom.On("RunOperation", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop")).Once()
+	mbi.On("ValidateInvokeRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, false).Return(nil)
+
+	res, err := cm.InvokeContractBatch(context.Background(), req, false)
+	assert.EqualError(t, err, "pop")
+	assert.Nil(t, res.Calls[1])
+
+	mth.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestInvokeContractBatchAtomic(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mom := cm.operations.(*operationmocks.Manager)
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	msa := cm.syncasync.(*syncasyncmocks.Bridge)
+
+	req := &core.ContractCallBatchRequest{
+		Key:    "key1",
+		Atomic: true,
+		Calls:  newTestInvokeBatchCalls(),
+	}
+	req.Calls[1].Key = "key1"
+
+	confirmed := &core.Operation{Status: core.OpStatusSucceeded, Output: fftypes.JSONObject{"returnValue": "0x1234"}}
+	mbi.On("Capabilities").Return(&blockchain.Capabilities{AtomicInvokeBatch: true})
+	mbi.On("DecodeInvokeBatchOutput", mock.Anything, mock.MatchedBy(func(calls []*blockchain.ContractCall) bool {
+		return len(calls) == 2 && calls[1].Method == req.Calls[1].Method
+	}), confirmed.Output).Return([]*fftypes.JSONAny{fftypes.JSONAnyPtr(`{"output":"1"}`), fftypes.JSONAnyPtr(`{}`)}, nil)
+	mim.On("ResolveInputSigningKey", mock.Anything, "key1", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mth.On("SubmitNewTransaction", mock.Anything, core.TransactionTypeContractInvoke, core.IdempotencyKey("")).Return(fftypes.NewUUID(), nil)
+	mom.On("AddOrReuseOperation", mock.Anything, mock.MatchedBy(func(op *core.Operation) bool {
+		return op.Type == core.OpTypeBlockchainInvokeBatch && len(op.Input["calls"].([]interface{})) == 2
+	})).Return(nil).Once()
+	mom.On("RunOperation", mock.Anything, mock.MatchedBy(func(op *core.PreparedOperation) bool {
+		data := op.Data.(blockchainInvokeBatchData)
+		return op.Type == core.OpTypeBlockchainInvokeBatch && data.Request == req
+	})).Return(nil, nil)
+	msa.On("WaitForInvokeOperation", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			send := args[2].(syncasync.SendFunction)
+			send(context.Background())
+		}).
+		Return(confirmed, nil)
+	mbi.On("ValidateInvokeRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, false).Return(nil)
+
+	res, err := cm.InvokeContractBatch(context.Background(), req, true)
+	assert.NoError(t, err)
+	assert.True(t, res.Atomic)
+	assert.Len(t, res.Calls, 2)
+	assert.Equal(t, confirmed, res.Calls[0].Operation)
+	assert.Equal(t, confirmed, res.Calls[1].Operation)
+	assert.Equal(t, `{"output":"1"}`, res.Calls[0].Output.String())
+	assert.Equal(t, `{}`, res.Calls[1].Output.String())
+	assert.Equal(t, "key-resolved", req.Calls[0].Key)
+
+	mth.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mom.AssertExpectations(t)
+	msa.AssertExpectations(t)
+}
+
+func TestInvokeContractBatchAtomicDecodeFail(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mom := cm.operations.(*operationmocks.Manager)
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	msa := cm.syncasync.(*syncasyncmocks.Bridge)
+
+	req := &core.ContractCallBatchRequest{
+		Atomic: true,
+		Calls:  newTestInvokeBatchCalls(),
+	}
+
+	confirmed := &core.Operation{Status: core.OpStatusSucceeded}
+	mbi.On("Capabilities").Return(&blockchain.Capabilities{AtomicInvokeBatch: true})
+	mbi.On("DecodeInvokeBatchOutput", mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	mim.On("ResolveInputSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mth.On("SubmitNewTransaction", mock.Anything, core.TransactionTypeContractInvoke, core.IdempotencyKey("")).Return(fftypes.NewUUID(), nil)
+	mom.On("AddOrReuseOperation", mock.Anything, mock.Anything).Return(nil)
+	msa.On("WaitForInvokeOperation", mock.Anything, mock.Anything, mock.Anything).Return(confirmed, nil)
+	mbi.On("ValidateInvokeRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, false).Return(nil)
+
+	res, err := cm.InvokeContractBatch(context.Background(), req, true)
+	assert.NoError(t, err)
+	assert.Equal(t, confirmed, res.Calls[0].Operation)
+	assert.Nil(t, res.Calls[0].Output)
+}
+
+func TestInvokeContractBatchAtomicConfirmFail(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mom := cm.operations.(*operationmocks.Manager)
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	msa := cm.syncasync.(*syncasyncmocks.Bridge)
+
+	req := &core.ContractCallBatchRequest{
+		Atomic: true,
+		Calls:  newTestInvokeBatchCalls(),
+	}
+
+	mbi.On("Capabilities").Return(&blockchain.Capabilities{AtomicInvokeBatch: true})
+	mim.On("ResolveInputSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mth.On("SubmitNewTransaction", mock.Anything, core.TransactionTypeContractInvoke, core.IdempotencyKey("")).Return(fftypes.NewUUID(), nil)
+	mom.On("AddOrReuseOperation", mock.Anything, mock.Anything).Return(nil)
+	msa.On("WaitForInvokeOperation", mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	mbi.On("ValidateInvokeRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, false).Return(nil)
+
+	res, err := cm.InvokeContractBatch(context.Background(), req, true)
+	assert.EqualError(t, err, "pop")
+	assert.NotNil(t, res.Calls[0].Operation)
+	assert.Equal(t, res.Calls[0].Operation, res.Calls[1].Operation)
+}
+
+func TestInvokeContractBatchAtomicSend(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mom := cm.operations.(*operationmocks.Manager)
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+
+	req := &core.ContractCallBatchRequest{
+		Atomic: true,
+		Calls:  newTestInvokeBatchCalls(),
+	}
+
+	mbi.On("Capabilities").Return(&blockchain.Capabilities{AtomicInvokeBatch: true})
+	mim.On("ResolveInputSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mth.On("SubmitNewTransaction", mock.Anything, core.TransactionTypeContractInvoke, core.IdempotencyKey("")).Return(fftypes.NewUUID(), nil)
+	mom.On("AddOrReuseOperation", mock.Anything, mock.Anything).Return(nil)
+	mom.On("RunOperation", mock.Anything, mock.Anything).Return(nil, nil)
+	mbi.On("ValidateInvokeRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, false).Return(nil)
+
+	res, err := cm.InvokeContractBatch(context.Background(), req, false)
+	assert.NoError(t, err)
+	assert.Equal(t, core.OpTypeBlockchainInvokeBatch, res.Calls[0].Operation.Type)
+
+	mom.AssertExpectations(t)
+}
+
+func TestInvokeContractBatchNoCalls(t *testing.T) {
+	cm := newTestContractManager()
+	_, err := cm.InvokeContractBatch(context.Background(), &core.ContractCallBatchRequest{}, false)
+	assert.Regexp(t, "FF10521", err)
+}
+
+func TestInvokeContractBatchAtomicNotSupported(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("Capabilities").Return(&blockchain.Capabilities{})
+
+	_, err := cm.InvokeContractBatch(context.Background(), &core.ContractCallBatchRequest{
+		Atomic: true,
+		Calls:  newTestInvokeBatchCalls(),
+	}, false)
+	assert.Regexp(t, "FF10525.*mockblockchain", err)
+}
+
+func TestInvokeContractBatchResolveKeyFail(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mim.On("ResolveInputSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("", fmt.Errorf("pop"))
+
+	_, err := cm.InvokeContractBatch(context.Background(), &core.ContractCallBatchRequest{
+		Calls: newTestInvokeBatchCalls(),
+	}, false)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestInvokeContractBatchResolveCallKeyFail(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mim.On("ResolveInputSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mim.On("ResolveInputSigningKey", mock.Anything, "key2", identity.KeyNormalizationBlockchainPlugin).Return("", fmt.Errorf("pop"))
+
+	req := &core.ContractCallBatchRequest{
+		Calls: newTestInvokeBatchCalls(),
+	}
+	req.Calls[1].Key = "key2"
+	_, err := cm.InvokeContractBatch(context.Background(), req, false)
+	assert.Regexp(t, "FF10522.*1.*pop", err)
+}
+
+func TestInvokeContractBatchAtomicKeyMismatch(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("Capabilities").Return(&blockchain.Capabilities{AtomicInvokeBatch: true})
+	mim.On("ResolveInputSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mim.On("ResolveInputSigningKey", mock.Anything, "key2", identity.KeyNormalizationBlockchainPlugin).Return("key2-resolved", nil)
+
+	req := &core.ContractCallBatchRequest{
+		Atomic: true,
+		Calls:  newTestInvokeBatchCalls(),
+	}
+	req.Calls[1].Key = "key2"
+	_, err := cm.InvokeContractBatch(context.Background(), req, false)
+	assert.Regexp(t, "FF10522.*FF10524", err)
+}
+
+func TestInvokeContractBatchMessage(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mim.On("ResolveInputSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+
+	req := &core.ContractCallBatchRequest{
+		Calls: newTestInvokeBatchCalls(),
+	}
+	req.Calls[0].Message = &core.MessageInOut{}
+	_, err := cm.InvokeContractBatch(context.Background(), req, false)
+	assert.Regexp(t, "FF10522.*FF10523", err)
+}
+
+func TestInvokeContractBatchBadCall(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mim.On("ResolveInputSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mbi.On("ValidateInvokeRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, false).Return(nil)
+
+	req := &core.ContractCallBatchRequest{
+		Calls: newTestInvokeBatchCalls(),
+	}
+	req.Calls[1].Method = nil
+	_, err := cm.InvokeContractBatch(context.Background(), req, false)
+	assert.Regexp(t, "FF10522.*1.*FF10313", err)
+}
+
+func TestInvokeContractBatchTXFail(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mim.On("ResolveInputSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mth.On("SubmitNewTransaction", mock.Anything, core.TransactionTypeContractInvoke, core.IdempotencyKey("")).Return(nil, fmt.Errorf("pop"))
+	mbi.On("ValidateInvokeRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, false).Return(nil)
+
+	_, err := cm.InvokeContractBatch(context.Background(), &core.ContractCallBatchRequest{
+		Calls: newTestInvokeBatchCalls(),
+	}, false)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestInvokeContractBatchAddOperationFail(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mom := cm.operations.(*operationmocks.Manager)
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mim.On("ResolveInputSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mth.On("SubmitNewTransaction", mock.Anything, core.TransactionTypeContractInvoke, core.IdempotencyKey("")).Return(fftypes.NewUUID(), nil)
+	mom.On("AddOrReuseOperation", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	mbi.On("ValidateInvokeRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, false).Return(nil)
+
+	_, err := cm.InvokeContractBatch(context.Background(), &core.ContractCallBatchRequest{
+		Calls: newTestInvokeBatchCalls(),
+	}, false)
+	assert.EqualError(t, err, "pop")
+}
+
 func TestQueryContract(t *testing.T) {
 	cm := newTestContractManager()
 	mbi := cm.blockchain.(*blockchainmocks.Plugin)
@@ -2422,12 +3145,41 @@ func TestGetContractListenerByNameOrIDWithStatus(t *testing.T) {
 	id := fftypes.NewUUID()
 	backendID := "testID"
 	mdi.On("GetContractListenerByID", context.Background(), "ns1", id).Return(&core.ContractListener{BackendID: backendID}, nil)
-	mbi.On("GetContractListenerStatus", context.Background(), backendID, false).Return(true, fftypes.JSONAnyPtr(fftypes.JSONObject{}.String()), nil)
+	mbi.On("GetContractListenerStatus", context.Background(), backendID, false).Return(true, &core.ContractListenerStatus{}, nil)
 
 	_, err := cm.GetContractListenerByNameOrIDWithStatus(context.Background(), id.String())
 	assert.NoError(t, err)
 }
 
+func TestGetContractListenerByNameOrIDWithStatusMetrics(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mmm := &metricsmocks.Manager{}
+	cm.metrics = mmm
+
+	id := fftypes.NewUUID()
+	backendID := "testID"
+	head := int64(100)
+	lag := int64(90)
+	status := &core.ContractListenerStatus{
+		Checkpoint: &core.ContractListenerCheckpoint{Block: 10},
+		HeadBlock:  &head,
+		Lag:        &lag,
+		Catchup:    true,
+	}
+	mdi.On("GetContractListenerByID", context.Background(), "ns1", id).Return(&core.ContractListener{Name: "listener1", BackendID: backendID}, nil)
+	mbi.On("GetContractListenerStatus", context.Background(), backendID, false).Return(true, status, nil)
+	mmm.On("IsMetricsEnabled").Return(true)
+	mmm.On("ContractListenerStatus", "ns1", "listener1", status).Return()
+
+	listener, err := cm.GetContractListenerByNameOrIDWithStatus(context.Background(), id.String())
+	assert.NoError(t, err)
+	assert.Equal(t, status, listener.Status)
+
+	mmm.AssertExpectations(t)
+}
+
 func TestGetContractListenerByNameOrIDWithStatusListenerFail(t *testing.T) {
 	cm := newTestContractManager()
 	mdi := cm.database.(*databasemocks.Plugin)
@@ -2451,11 +3203,7 @@ func TestGetContractListenerByNameOrIDWithStatusPluginFail(t *testing.T) {
 
 	listener, err := cm.GetContractListenerByNameOrIDWithStatus(context.Background(), id.String())
 
-	testError := core.ListenerStatusError{
-		StatusError: "pop",
-	}
-
-	assert.Equal(t, listener.Status, testError)
+	assert.Equal(t, "pop", listener.Status.Error)
 	assert.NoError(t, err)
 }
 
@@ -2620,6 +3368,47 @@ func TestDeleteContractListener(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestDeleteContractListenerMetrics(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mmm := &metricsmocks.Manager{}
+	cm.metrics = mmm
+
+	sub := &core.ContractListener{
+		ID:   fftypes.NewUUID(),
+		Name: "sub1",
+	}
+
+	mdi.On("GetContractListener", context.Background(), "ns1", "sub1").Return(sub, nil)
+	mbi.On("DeleteContractListener", context.Background(), sub, true).Return(nil)
+	mdi.On("DeleteContractListenerByID", context.Background(), "ns1", sub.ID).Return(nil)
+	mmm.On("IsMetricsEnabled").Return(true)
+	mmm.On("ContractListenerDeleted", "ns1", "sub1").Return()
+
+	err := cm.DeleteContractListenerByNameOrID(context.Background(), "sub1")
+	assert.NoError(t, err)
+
+	mmm.AssertExpectations(t)
+}
+
+func TestDeleteContractListenerDatabaseFail(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := &core.ContractListener{
+		ID: fftypes.NewUUID(),
+	}
+
+	mdi.On("GetContractListener", context.Background(), "ns1", "sub1").Return(sub, nil)
+	mbi.On("DeleteContractListener", context.Background(), sub, true).Return(nil)
+	mdi.On("DeleteContractListenerByID", context.Background(), "ns1", sub.ID).Return(fmt.Errorf("pop"))
+
+	err := cm.DeleteContractListenerByNameOrID(context.Background(), "sub1")
+	assert.EqualError(t, err, "pop")
+}
+
 func TestDeleteContractListenerBlockchainFail(t *testing.T) {
 	cm := newTestContractManager()
 	mbi := cm.blockchain.(*blockchainmocks.Plugin)
@@ -34,6 +34,10 @@ type blockchainContractDeployData struct {
 	Request *core.ContractDeployRequest `json:"request"`
 }
 
+type blockchainInvokeBatchData struct {
+	Request *core.ContractCallBatchRequest `json:"request"`
+}
+
 func addBlockchainReqInputs(op *core.Operation, req interface{}) (err error) {
 	var reqJSON []byte
 	if reqJSON, err = json.Marshal(req); err == nil {
@@ -51,6 +55,15 @@ func retrieveBlockchainDeployInputs(ctx context.Context, op *core.Operation) (*c
 	return &req, nil
 }
 
+func retrieveBlockchainInvokeBatchInputs(ctx context.Context, op *core.Operation) (*core.ContractCallBatchRequest, error) {
+	var req core.ContractCallBatchRequest
+	s := op.Input.String()
+	if err := json.Unmarshal([]byte(s), &req); err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgJSONObjectParseFailed, s)
+	}
+	return &req, nil
+}
+
 func (cm *contractManager) PrepareOperation(ctx context.Context, op *core.Operation) (*core.PreparedOperation, error) {
 	switch op.Type {
 	case core.OpTypeBlockchainInvoke:
@@ -100,6 +113,13 @@ func (cm *contractManager) PrepareOperation(ctx context.Context, op *core.Operat
 
 		return txcommon.OpBlockchainInvoke(op, req, batchPin), nil
 
+	case core.OpTypeBlockchainInvokeBatch:
+		req, err := retrieveBlockchainInvokeBatchInputs(ctx, op)
+		if err != nil {
+			return nil, err
+		}
+		return opBlockchainInvokeBatch(op, req), nil
+
 	case core.OpTypeBlockchainContractDeploy:
 		req, err := retrieveBlockchainDeployInputs(ctx, op)
 		if err != nil {
@@ -128,6 +148,10 @@ func (cm *contractManager) RunOperation(ctx context.Context, op *core.PreparedOp
 		}
 		return nil, false, cm.blockchain.InvokeContract(ctx, op.NamespacedIDString(), req.Key, req.Location, req.Method, req.Input, req.Errors, req.Options, batchPin)
 
+	case blockchainInvokeBatchData:
+		req := data.Request
+		return nil, false, cm.blockchain.InvokeContractBatch(ctx, op.NamespacedIDString(), req.Key, batchContractCalls(req), req.Options)
+
 	case blockchainContractDeployData:
 		req := data.Request
 		return nil, false, cm.blockchain.DeployContract(ctx, op.NamespacedIDString(), req.Key, req.Definition, req.Contract, req.Input, req.Options)
@@ -139,7 +163,7 @@ func (cm *contractManager) RunOperation(ctx context.Context, op *core.PreparedOp
 func (cm *contractManager) OnOperationUpdate(ctx context.Context, op *core.Operation, update *core.OperationUpdate) error {
 	// Special handling for blockchain operations, which writes an event when it succeeds or fails
 	switch op.Type {
-	case core.OpTypeBlockchainInvoke:
+	case core.OpTypeBlockchainInvoke, core.OpTypeBlockchainInvokeBatch:
 		if update.Status == core.OpStatusSucceeded {
 			event := core.NewEvent(core.EventTypeBlockchainInvokeOpSucceeded, op.Namespace, op.ID, op.Transaction, "")
 			if err := cm.database.InsertEvent(ctx, event); err != nil {
@@ -178,3 +202,26 @@ func opBlockchainContractDeploy(op *core.Operation, req *core.ContractDeployRequ
 		Data:      blockchainContractDeployData{Request: req},
 	}
 }
+
+func batchContractCalls(req *core.ContractCallBatchRequest) []*blockchain.ContractCall {
+	calls := make([]*blockchain.ContractCall, len(req.Calls))
+	for i, call := range req.Calls {
+		calls[i] = &blockchain.ContractCall{
+			Location: call.Location,
+			Method:   call.Method,
+			Input:    call.Input,
+			Errors:   call.Errors,
+		}
+	}
+	return calls
+}
+
+func opBlockchainInvokeBatch(op *core.Operation, req *core.ContractCallBatchRequest) *core.PreparedOperation {
+	return &core.PreparedOperation{
+		ID:        op.ID,
+		Namespace: op.Namespace,
+		Plugin:    op.Plugin,
+		Type:      op.Type,
+		Data:      blockchainInvokeBatchData{Request: req},
+	}
+}
@@ -129,6 +129,53 @@ func TestPrepareAndRunBlockchainContractDeploy(t *testing.T) {
 	mbi.AssertExpectations(t)
 }
 
+func TestPrepareAndRunBlockchainInvokeBatch(t *testing.T) {
+	cm := newTestContractManager()
+
+	op := &core.Operation{
+		Type:      core.OpTypeBlockchainInvokeBatch,
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+	signingKey := "0x2468"
+	req := &core.ContractCallBatchRequest{
+		Key:    signingKey,
+		Atomic: true,
+		Calls: []*core.ContractCallRequest{
+			{
+				Location: fftypes.JSONAnyPtr(`{"address":"0x1"}`),
+				Method:   &fftypes.FFIMethod{Name: "approve"},
+				Input:    map[string]interface{}{"amount": "1"},
+			},
+			{
+				Location: fftypes.JSONAnyPtr(`{"address":"0x2"}`),
+				Method:   &fftypes.FFIMethod{Name: "transfer"},
+			},
+		},
+		Options: map[string]interface{}{"gas": "1000"},
+	}
+	err := addBlockchainReqInputs(op, req)
+	assert.NoError(t, err)
+
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("InvokeContractBatch", context.Background(), "ns1:"+op.ID.String(), signingKey, mock.MatchedBy(func(calls []*blockchain.ContractCall) bool {
+		return len(calls) == 2 &&
+			calls[0].Method.Name == "approve" && calls[0].Input["amount"] == "1" &&
+			calls[1].Location.JSONObject().GetString("address") == "0x2"
+	}), map[string]interface{}{"gas": "1000"}).Return(nil)
+
+	po, err := cm.PrepareOperation(context.Background(), op)
+	assert.NoError(t, err)
+	assert.Equal(t, req, po.Data.(blockchainInvokeBatchData).Request)
+
+	_, complete, err := cm.RunOperation(context.Background(), po)
+
+	assert.False(t, complete)
+	assert.NoError(t, err)
+
+	mbi.AssertExpectations(t)
+}
+
 func TestPrepareOperationNotSupported(t *testing.T) {
 	cm := newTestContractManager()
 
@@ -162,6 +209,18 @@ func TestPrepareOperationBlockchainInvokeBadInput(t *testing.T) {
 	assert.Regexp(t, "FF00127", err)
 }
 
+func TestPrepareOperationBlockchainInvokeBatchBadInput(t *testing.T) {
+	cm := newTestContractManager()
+
+	op := &core.Operation{
+		Type:  core.OpTypeBlockchainInvokeBatch,
+		Input: fftypes.JSONObject{"calls": "bad"},
+	}
+
+	_, err := cm.PrepareOperation(context.Background(), op)
+	assert.Regexp(t, "FF00127", err)
+}
+
 func TestPrepareOperationBlockchainInvokeWithPrivateBatch(t *testing.T) {
 	cm := newTestContractManager()
 
@@ -663,3 +722,25 @@ func TestOperationUpdateInvokeFail(t *testing.T) {
 
 	mdi.AssertExpectations(t)
 }
+
+func TestOperationUpdateInvokeBatchSucceed(t *testing.T) {
+	cm := newTestContractManager()
+
+	op := &core.Operation{
+		ID:   fftypes.NewUUID(),
+		Type: core.OpTypeBlockchainInvokeBatch,
+	}
+	update := &core.OperationUpdate{
+		Status: core.OpStatusSucceeded,
+	}
+
+	mdi := cm.database.(*databasemocks.Plugin)
+	mdi.On("InsertEvent", context.Background(), mock.MatchedBy(func(event *core.Event) bool {
+		return event.Type == core.EventTypeBlockchainInvokeOpSucceeded && *event.Reference == *op.ID
+	})).Return(nil)
+
+	err := cm.OnOperationUpdate(context.Background(), op, update)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
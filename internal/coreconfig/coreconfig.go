@@ -65,6 +65,8 @@ const (
 	NamespaceMultipartyContractLocation = "location"
 	// NamespaceMultipartyContractOptions is an object of additional blockchain-specific configuration
 	NamespaceMultipartyContractOptions = "options"
+	// NamespaceRetention contains the data retention policy for a namespace
+	NamespaceRetention = "retention"
 )
 
 // The following keys can be access from the root configuration.
@@ -274,6 +276,8 @@ var (
 	MetricsEnabled = ffc("metrics.enabled")
 	// MetricsPath determines what path to serve the Prometheus metrics from
 	MetricsPath = ffc("metrics.path")
+	// MetricsContractListenerStatusInterval is how often the leader polls the status of contract listeners to update their metrics
+	MetricsContractListenerStatusInterval = ffc("metrics.contractListenerStatusInterval")
 	// NamespacesDefault is the default namespace - must be in the predefines list
 	NamespacesDefault = ffc("namespaces.default")
 	// NamespacesPredefined is a list of namespaces to ensure exists, without requiring a broadcast from the network
@@ -312,6 +316,8 @@ var (
 	OrchestratorStartupAttempts = ffc("orchestrator.startupAttempts")
 	// SubscriptionDefaultsReadAhead default read ahead to enable for subscriptions that do not explicitly configure readahead
 	SubscriptionDefaultsReadAhead = ffc("subscription.defaults.batchSize")
+	// SubscriptionDefaultsBatchTimeout default batch timeout for subscriptions that enable batch delivery, but do not explicitly configure a timeout
+	SubscriptionDefaultsBatchTimeout = ffc("subscription.defaults.batchTimeout")
 	// SubscriptionMax maximum number of pre-defined subscriptions that can exist (note for high fan-out consider connecting a dedicated pub/sub broker to the dispatcher)
 	SubscriptionMax = ffc("subscription.max")
 	// SubscriptionsRetryInitialDelay is the initial retry delay
@@ -427,6 +433,7 @@ func setDefaults() {
 	viper.SetDefault(string(PrivateMessagingBatchTimeout), "1s")
 	viper.SetDefault(string(PrivateMessagingBatchPayloadLimit), "800Kb")
 	viper.SetDefault(string(SubscriptionDefaultsReadAhead), 0)
+	viper.SetDefault(string(SubscriptionDefaultsBatchTimeout), "50ms")
 	viper.SetDefault(string(SubscriptionMax), 500)
 	viper.SetDefault(string(SubscriptionsRetryInitialDelay), "250ms")
 	viper.SetDefault(string(SubscriptionsRetryMaxDelay), "30s")
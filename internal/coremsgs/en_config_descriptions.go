@@ -25,6 +25,9 @@ var ffc = func(key, translation, fieldType string) i18n.ConfigMessageKey {
 	return i18n.FFC(language.AmericanEnglish, key, translation, fieldType)
 }
 
+// objectArrayType is the type of a config key holding a list of objects, with the keys of each object described under "<key>[]"
+var objectArrayType = "`[]object`"
+
 //revive:disable
 var (
 	ConfigGlobalMigrationsAuto      = ffc("config.global.migrations.auto", "Enables automatic database migrations", i18n.BooleanType)
@@ -86,6 +89,9 @@ var (
 	ConfigBlockchainEthereumFFTMURL      = ffc("config.blockchain.ethereum.fftm.url", "The URL of the FireFly Transaction Manager runtime, if enabled", i18n.StringType)
 	ConfigBlockchainEthereumFFTMProxyURL = ffc("config.blockchain.ethereum.fftm.proxy.url", "Optional HTTP proxy server to use when connecting to the Transaction Manager", i18n.StringType)
 
+	ConfigBlockchainEthereumMulticallAddress        = ffc("config.blockchain.ethereum.multicall.address", "The address of a Multicall3 compatible contract. When set, contract invoke batches can be submitted atomically as a single transaction", i18n.StringType)
+	ConfigBlockchainEthereumMulticallPreserveSender = ffc("config.blockchain.ethereum.multicall.preserveSender", "When true, atomic contract invoke batches are submitted to the 'multicall' method of the contract they call, such as one that extends the OpenZeppelin Multicall contract, so that msg.sender is the signing key. All calls in a batch must then target the same contract", i18n.BooleanType)
+
 	ConfigBlockchainFabricFabconnectBatchSize    = ffc("config.blockchain.fabric.fabconnect.batchSize", "The number of events Fabconnect should batch together for delivery to FireFly core. Only applies when automatically creating a new event stream", i18n.IntType)
 	ConfigBlockchainFabricFabconnectBatchTimeout = ffc("config.blockchain.fabric.fabconnect.batchTimeout", "The maximum amount of time to wait for a batch to complete", i18n.TimeDurationType)
 	ConfigBlockchainFabricFabconnectChaincode    = ffc("config.blockchain.fabric.fabconnect.chaincode", "The name of the Fabric chaincode that FireFly will use for BatchPin transactions (deprecated - use namespaces.predefined[].multiparty.contract[].location.chaincode)", i18n.StringType)
@@ -128,17 +134,55 @@ var (
 	ConfigPluginDatabaseName = ffc("config.plugins.database[].name", "The name of the Database plugin", i18n.StringType)
 	ConfigPluginDatabaseType = ffc("config.plugins.database[].type", "The type of the configured Database plugin", i18n.StringType)
 
-	ConfigPluginDatabasePostgresMaxConnIdleTime = ffc("config.plugins.database[].postgres.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
-	ConfigPluginDatabasePostgresMaxConnLifetime = ffc("config.plugins.database[].postgres.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
-	ConfigPluginDatabasePostgresMaxConns        = ffc("config.plugins.database[].postgres.maxConns", "Maximum connections to the database", i18n.IntType)
-	ConfigPluginDatabasePostgresMaxIdleConns    = ffc("config.plugins.database[].postgres.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
-	ConfigPluginDatabasePostgresURL             = ffc("config.plugins.database[].postgres.url", "The PostgreSQL connection string for the database", i18n.StringType)
-
-	ConfigPluginDatabaseSqlite3MaxConnIdleTime = ffc("config.plugins.database[].sqlite3.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
-	ConfigPluginDatabaseSqlite3MaxConnLifetime = ffc("config.plugins.database[].sqlite3.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
-	ConfigPluginDatabaseSqlite3MaxConns        = ffc("config.plugins.database[].sqlite3.maxConns", "Maximum connections to the database", i18n.IntType)
-	ConfigPluginDatabaseSqlite3MaxIdleConns    = ffc("config.plugins.database[].sqlite3.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
-	ConfigPluginDatabaseSqlite3URL             = ffc("config.plugins.database[].sqlite3.url", "The SQLite connection string for the database", i18n.StringType)
+	ConfigPluginDatabasePostgresMaxConnIdleTime            = ffc("config.plugins.database[].postgres.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
+	ConfigPluginDatabasePostgresMaxConnLifetime            = ffc("config.plugins.database[].postgres.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
+	ConfigPluginDatabasePostgresMaxConns                   = ffc("config.plugins.database[].postgres.maxConns", "Maximum connections to the database", i18n.IntType)
+	ConfigPluginDatabasePostgresMaxIdleConns               = ffc("config.plugins.database[].postgres.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
+	ConfigPluginDatabasePostgresURL                        = ffc("config.plugins.database[].postgres.url", "The PostgreSQL connection string for the database", i18n.StringType)
+	ConfigPluginDatabasePostgresClusterEnabled             = ffc("config.plugins.database[].postgres.cluster.enabled", "Enables running multiple FireFly processes against the same PostgreSQL database, with leader election for each namespace and change notifications shared between the processes", i18n.BooleanType)
+	ConfigPluginDatabasePostgresClusterChannel             = ffc("config.plugins.database[].postgres.cluster.channel", "The PostgreSQL LISTEN/NOTIFY channel used to share change notifications between processes", i18n.StringType)
+	ConfigPluginDatabasePostgresClusterLeaderRetryInterval = ffc("config.plugins.database[].postgres.cluster.leaderRetryInterval", "How often a standby process attempts to become the leader of a namespace, and how often the leader checks its database connection", i18n.TimeDurationType)
+	ConfigPluginDatabasePostgresPartitioningEnabled        = ffc("config.plugins.database[].postgres.partitioning.enabled", "Range partitions the events, blockchainevents and operations tables - the tables are converted by 'firefly db up', then FireFly creates their future partitions", i18n.BooleanType)
+	ConfigPluginDatabasePostgresPartitioningStrategy       = ffc("config.plugins.database[].postgres.partitioning.strategy", "Whether the tables are partitioned by 'sequence' or by 'time'", i18n.StringType)
+	ConfigPluginDatabasePostgresPartitioningSequenceRange  = ffc("config.plugins.database[].postgres.partitioning.sequenceRange", "The number of sequences held by each partition, when partitioning by sequence", i18n.IntType)
+	ConfigPluginDatabasePostgresPartitioningTimeRange      = ffc("config.plugins.database[].postgres.partitioning.timeRange", "The length of time covered by each partition, when partitioning by time", i18n.TimeDurationType)
+	ConfigPluginDatabasePostgresPartitioningPremake        = ffc("config.plugins.database[].postgres.partitioning.premake", "The number of partitions to create ahead of the one currently being written", i18n.IntType)
+	ConfigPluginDatabasePostgresPartitioningCheckInterval  = ffc("config.plugins.database[].postgres.partitioning.checkInterval", "How often to create future partitions, and drop expired partitions", i18n.TimeDurationType)
+	ConfigPluginDatabasePostgresPartitioningRetention      = ffc("config.plugins.database[].postgres.partitioning.retention", "When partitioning by time, partitions that only hold rows older than this are dropped. Zero keeps all partitions", i18n.TimeDurationType)
+	ConfigPluginDatabasePostgresReadReplicaURL             = ffc("config.plugins.database[].postgres.readReplica.url", "The PostgreSQL connection string for an optional read-only replica of the database, used for queries made by read-only API routes", i18n.StringType)
+	ConfigPluginDatabasePostgresReadReplicaMaxConns        = ffc("config.plugins.database[].postgres.readReplica.maxConns", "Maximum connections to the read replica", i18n.IntType)
+	ConfigPluginDatabasePostgresReadReplicaMaxConnIdleTime = ffc("config.plugins.database[].postgres.readReplica.maxConnIdleTime", "The maximum amount of time a read replica connection can be idle", i18n.TimeDurationType)
+	ConfigPluginDatabasePostgresReadReplicaMaxIdleConns    = ffc("config.plugins.database[].postgres.readReplica.maxIdleConns", "The maximum number of idle connections to the read replica", i18n.IntType)
+	ConfigPluginDatabasePostgresReadReplicaMaxConnLifetime = ffc("config.plugins.database[].postgres.readReplica.maxConnLifetime", "The maximum amount of time to keep a read replica connection open", i18n.TimeDurationType)
+	ConfigPluginDatabasePostgresEncryptionEnabled          = ffc("config.plugins.database[].postgres.encryption.enabled", "Encrypts the values of data records written to the database, using envelope encryption with a data key that is wrapped by the configured key provider", i18n.BooleanType)
+	ConfigPluginDatabasePostgresEncryptionProvider         = ffc("config.plugins.database[].postgres.encryption.provider", "The key provider that wraps the data keys - `keyfile` or `kms`", i18n.StringType)
+	ConfigPluginDatabasePostgresEncryptionKeyfilePath      = ffc("config.plugins.database[].postgres.encryption.keyfile.path", "The path to a file containing the hex encoded 32 byte master key, for the `keyfile` provider", i18n.StringType)
+	ConfigPluginDatabasePostgresEncryptionKMSType          = ffc("config.plugins.database[].postgres.encryption.kms.type", "The type of key management service holding the master key, for the `kms` provider. Only the `local` stand-in is currently supported", i18n.StringType)
+	ConfigPluginDatabasePostgresEncryptionKMSKeyID         = ffc("config.plugins.database[].postgres.encryption.kms.keyId", "The identifier of the master key in the key management service", i18n.StringType)
+	ConfigPluginDatabasePostgresEncryptionKMSLocalKeyDir   = ffc("config.plugins.database[].postgres.encryption.kms.local.keyDir", "The directory containing the hex encoded master keys of the `local` key management service, in files named `<keyId>.key`", i18n.StringType)
+
+	ConfigPluginDatabaseSqlite3MaxConnIdleTime           = ffc("config.plugins.database[].sqlite3.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
+	ConfigPluginDatabaseSqlite3MaxConnLifetime           = ffc("config.plugins.database[].sqlite3.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
+	ConfigPluginDatabaseSqlite3MaxConns                  = ffc("config.plugins.database[].sqlite3.maxConns", "Maximum connections to the database", i18n.IntType)
+	ConfigPluginDatabaseSqlite3MaxIdleConns              = ffc("config.plugins.database[].sqlite3.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
+	ConfigPluginDatabaseSqlite3URL                       = ffc("config.plugins.database[].sqlite3.url", "The SQLite connection string for the database", i18n.StringType)
+	ConfigPluginDatabaseSqlite3EncryptionEnabled         = ffc("config.plugins.database[].sqlite3.encryption.enabled", "Encrypts the values of data records written to the database, using envelope encryption with a data key that is wrapped by the configured key provider", i18n.BooleanType)
+	ConfigPluginDatabaseSqlite3EncryptionProvider        = ffc("config.plugins.database[].sqlite3.encryption.provider", "The key provider that wraps the data keys - `keyfile` or `kms`", i18n.StringType)
+	ConfigPluginDatabaseSqlite3EncryptionKeyfilePath     = ffc("config.plugins.database[].sqlite3.encryption.keyfile.path", "The path to a file containing the hex encoded 32 byte master key, for the `keyfile` provider", i18n.StringType)
+	ConfigPluginDatabaseSqlite3EncryptionKMSType         = ffc("config.plugins.database[].sqlite3.encryption.kms.type", "The type of key management service holding the master key, for the `kms` provider. Only the `local` stand-in is currently supported", i18n.StringType)
+	ConfigPluginDatabaseSqlite3EncryptionKMSKeyID        = ffc("config.plugins.database[].sqlite3.encryption.kms.keyId", "The identifier of the master key in the key management service", i18n.StringType)
+	ConfigPluginDatabaseSqlite3EncryptionKMSLocalKeyDir  = ffc("config.plugins.database[].sqlite3.encryption.kms.local.keyDir", "The directory containing the hex encoded master keys of the `local` key management service, in files named `<keyId>.key`", i18n.StringType)
+	ConfigPluginDatabaseSqliteGoMaxConnIdleTime          = ffc("config.plugins.database[].sqlitego.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
+	ConfigPluginDatabaseSqliteGoMaxConnLifetime          = ffc("config.plugins.database[].sqlitego.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
+	ConfigPluginDatabaseSqliteGoMaxConns                 = ffc("config.plugins.database[].sqlitego.maxConns", "Maximum connections to the database", i18n.IntType)
+	ConfigPluginDatabaseSqliteGoMaxIdleConns             = ffc("config.plugins.database[].sqlitego.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
+	ConfigPluginDatabaseSqliteGoURL                      = ffc("config.plugins.database[].sqlitego.url", "The SQLite connection string for the database", i18n.StringType)
+	ConfigPluginDatabaseSqliteGoEncryptionEnabled        = ffc("config.plugins.database[].sqlitego.encryption.enabled", "Encrypts the values of data records written to the database, using envelope encryption with a data key that is wrapped by the configured key provider", i18n.BooleanType)
+	ConfigPluginDatabaseSqliteGoEncryptionProvider       = ffc("config.plugins.database[].sqlitego.encryption.provider", "The key provider that wraps the data keys - `keyfile` or `kms`", i18n.StringType)
+	ConfigPluginDatabaseSqliteGoEncryptionKeyfilePath    = ffc("config.plugins.database[].sqlitego.encryption.keyfile.path", "The path to a file containing the hex encoded 32 byte master key, for the `keyfile` provider", i18n.StringType)
+	ConfigPluginDatabaseSqliteGoEncryptionKMSType        = ffc("config.plugins.database[].sqlitego.encryption.kms.type", "The type of key management service holding the master key, for the `kms` provider. Only the `local` stand-in is currently supported", i18n.StringType)
+	ConfigPluginDatabaseSqliteGoEncryptionKMSKeyID       = ffc("config.plugins.database[].sqlitego.encryption.kms.keyId", "The identifier of the master key in the key management service", i18n.StringType)
+	ConfigPluginDatabaseSqliteGoEncryptionKMSLocalKeyDir = ffc("config.plugins.database[].sqlitego.encryption.kms.local.keyDir", "The directory containing the hex encoded master keys of the `local` key management service, in files named `<keyId>.key`", i18n.StringType)
 
 	ConfigPluginBlockchain     = ffc("config.plugins.blockchain", "The list of configured Blockchain plugins", i18n.StringType)
 	ConfigPluginBlockchainName = ffc("config.plugins.blockchain[].name", "The name of the configured Blockchain plugin", i18n.StringType)
@@ -173,6 +217,9 @@ var (
 	ConfigPluginBlockchainEthereumFFTMURL      = ffc("config.plugins.blockchain[].ethereum.fftm.url", "The URL of the FireFly Transaction Manager runtime, if enabled", i18n.StringType)
 	ConfigPluginBlockchainEthereumFFTMProxyURL = ffc("config.plugins.blockchain[].ethereum.fftm.proxy.url", "Optional HTTP proxy server to use when connecting to the Transaction Manager", i18n.StringType)
 
+	ConfigPluginBlockchainEthereumMulticallAddress        = ffc("config.plugins.blockchain[].ethereum.multicall.address", "The address of a Multicall3 compatible contract. When set, contract invoke batches can be submitted atomically as a single transaction", i18n.StringType)
+	ConfigPluginBlockchainEthereumMulticallPreserveSender = ffc("config.plugins.blockchain[].ethereum.multicall.preserveSender", "When true, atomic contract invoke batches are submitted to the 'multicall' method of the contract they call, such as one that extends the OpenZeppelin Multicall contract, so that msg.sender is the signing key. All calls in a batch must then target the same contract", i18n.BooleanType)
+
 	ConfigPluginBlockchainFabricFabconnectBatchSize    = ffc("config.plugins.blockchain[].fabric.fabconnect.batchSize", "The number of events Fabconnect should batch together for delivery to FireFly core. Only applies when automatically creating a new event stream", i18n.IntType)
 	ConfigPluginBlockchainFabricFabconnectBatchTimeout = ffc("config.plugins.blockchain[].fabric.fabconnect.batchTimeout", "The maximum amount of time to wait for a batch to complete", i18n.TimeDurationType)
 	ConfigPluginBlockchainFabricFabconnectPrefixLong   = ffc("config.plugins.blockchain[].fabric.fabconnect.prefixLong", "The prefix that will be used for Fabconnect specific HTTP headers when FireFly makes requests to Fabconnect", i18n.StringType)
@@ -191,17 +238,55 @@ var (
 
 	ConfigDatabaseType = ffc("config.database.type", "The type of the database interface plugin to use", i18n.IntType)
 
-	ConfigDatabasePostgresMaxConnIdleTime = ffc("config.database.postgres.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
-	ConfigDatabasePostgresMaxConnLifetime = ffc("config.database.postgres.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
-	ConfigDatabasePostgresMaxConns        = ffc("config.database.postgres.maxConns", "Maximum connections to the database", i18n.IntType)
-	ConfigDatabasePostgresMaxIdleConns    = ffc("config.database.postgres.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
-	ConfigDatabasePostgresURL             = ffc("config.database.postgres.url", "The PostgreSQL connection string for the database", i18n.StringType)
-
-	ConfigDatabaseSqlite3MaxConnIdleTime = ffc("config.database.sqlite3.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
-	ConfigDatabaseSqlite3MaxConnLifetime = ffc("config.database.sqlite3.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
-	ConfigDatabaseSqlite3MaxConns        = ffc("config.database.sqlite3.maxConns", "Maximum connections to the database", i18n.IntType)
-	ConfigDatabaseSqlite3MaxIdleConns    = ffc("config.database.sqlite3.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
-	ConfigDatabaseSqlite3URL             = ffc("config.database.sqlite3.url", "The SQLite connection string for the database", i18n.StringType)
+	ConfigDatabasePostgresMaxConnIdleTime            = ffc("config.database.postgres.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
+	ConfigDatabasePostgresMaxConnLifetime            = ffc("config.database.postgres.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
+	ConfigDatabasePostgresMaxConns                   = ffc("config.database.postgres.maxConns", "Maximum connections to the database", i18n.IntType)
+	ConfigDatabasePostgresMaxIdleConns               = ffc("config.database.postgres.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
+	ConfigDatabasePostgresURL                        = ffc("config.database.postgres.url", "The PostgreSQL connection string for the database", i18n.StringType)
+	ConfigDatabasePostgresClusterEnabled             = ffc("config.database.postgres.cluster.enabled", "Enables running multiple FireFly processes against the same PostgreSQL database, with leader election for each namespace and change notifications shared between the processes", i18n.BooleanType)
+	ConfigDatabasePostgresClusterChannel             = ffc("config.database.postgres.cluster.channel", "The PostgreSQL LISTEN/NOTIFY channel used to share change notifications between processes", i18n.StringType)
+	ConfigDatabasePostgresClusterLeaderRetryInterval = ffc("config.database.postgres.cluster.leaderRetryInterval", "How often a standby process attempts to become the leader of a namespace, and how often the leader checks its database connection", i18n.TimeDurationType)
+	ConfigDatabasePostgresPartitioningEnabled        = ffc("config.database.postgres.partitioning.enabled", "Range partitions the events, blockchainevents and operations tables - the tables are converted by 'firefly db up', then FireFly creates their future partitions", i18n.BooleanType)
+	ConfigDatabasePostgresPartitioningStrategy       = ffc("config.database.postgres.partitioning.strategy", "Whether the tables are partitioned by 'sequence' or by 'time'", i18n.StringType)
+	ConfigDatabasePostgresPartitioningSequenceRange  = ffc("config.database.postgres.partitioning.sequenceRange", "The number of sequences held by each partition, when partitioning by sequence", i18n.IntType)
+	ConfigDatabasePostgresPartitioningTimeRange      = ffc("config.database.postgres.partitioning.timeRange", "The length of time covered by each partition, when partitioning by time", i18n.TimeDurationType)
+	ConfigDatabasePostgresPartitioningPremake        = ffc("config.database.postgres.partitioning.premake", "The number of partitions to create ahead of the one currently being written", i18n.IntType)
+	ConfigDatabasePostgresPartitioningCheckInterval  = ffc("config.database.postgres.partitioning.checkInterval", "How often to create future partitions, and drop expired partitions", i18n.TimeDurationType)
+	ConfigDatabasePostgresPartitioningRetention      = ffc("config.database.postgres.partitioning.retention", "When partitioning by time, partitions that only hold rows older than this are dropped. Zero keeps all partitions", i18n.TimeDurationType)
+	ConfigDatabasePostgresReadReplicaURL             = ffc("config.database.postgres.readReplica.url", "The PostgreSQL connection string for an optional read-only replica of the database, used for queries made by read-only API routes", i18n.StringType)
+	ConfigDatabasePostgresReadReplicaMaxConns        = ffc("config.database.postgres.readReplica.maxConns", "Maximum connections to the read replica", i18n.IntType)
+	ConfigDatabasePostgresReadReplicaMaxConnIdleTime = ffc("config.database.postgres.readReplica.maxConnIdleTime", "The maximum amount of time a read replica connection can be idle", i18n.TimeDurationType)
+	ConfigDatabasePostgresReadReplicaMaxIdleConns    = ffc("config.database.postgres.readReplica.maxIdleConns", "The maximum number of idle connections to the read replica", i18n.IntType)
+	ConfigDatabasePostgresReadReplicaMaxConnLifetime = ffc("config.database.postgres.readReplica.maxConnLifetime", "The maximum amount of time to keep a read replica connection open", i18n.TimeDurationType)
+	ConfigDatabasePostgresEncryptionEnabled          = ffc("config.database.postgres.encryption.enabled", "Encrypts the values of data records written to the database, using envelope encryption with a data key that is wrapped by the configured key provider", i18n.BooleanType)
+	ConfigDatabasePostgresEncryptionProvider         = ffc("config.database.postgres.encryption.provider", "The key provider that wraps the data keys - `keyfile` or `kms`", i18n.StringType)
+	ConfigDatabasePostgresEncryptionKeyfilePath      = ffc("config.database.postgres.encryption.keyfile.path", "The path to a file containing the hex encoded 32 byte master key, for the `keyfile` provider", i18n.StringType)
+	ConfigDatabasePostgresEncryptionKMSType          = ffc("config.database.postgres.encryption.kms.type", "The type of key management service holding the master key, for the `kms` provider. Only the `local` stand-in is currently supported", i18n.StringType)
+	ConfigDatabasePostgresEncryptionKMSKeyID         = ffc("config.database.postgres.encryption.kms.keyId", "The identifier of the master key in the key management service", i18n.StringType)
+	ConfigDatabasePostgresEncryptionKMSLocalKeyDir   = ffc("config.database.postgres.encryption.kms.local.keyDir", "The directory containing the hex encoded master keys of the `local` key management service, in files named `<keyId>.key`", i18n.StringType)
+
+	ConfigDatabaseSqlite3MaxConnIdleTime           = ffc("config.database.sqlite3.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
+	ConfigDatabaseSqlite3MaxConnLifetime           = ffc("config.database.sqlite3.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
+	ConfigDatabaseSqlite3MaxConns                  = ffc("config.database.sqlite3.maxConns", "Maximum connections to the database", i18n.IntType)
+	ConfigDatabaseSqlite3MaxIdleConns              = ffc("config.database.sqlite3.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
+	ConfigDatabaseSqlite3URL                       = ffc("config.database.sqlite3.url", "The SQLite connection string for the database", i18n.StringType)
+	ConfigDatabaseSqlite3EncryptionEnabled         = ffc("config.database.sqlite3.encryption.enabled", "Encrypts the values of data records written to the database, using envelope encryption with a data key that is wrapped by the configured key provider", i18n.BooleanType)
+	ConfigDatabaseSqlite3EncryptionProvider        = ffc("config.database.sqlite3.encryption.provider", "The key provider that wraps the data keys - `keyfile` or `kms`", i18n.StringType)
+	ConfigDatabaseSqlite3EncryptionKeyfilePath     = ffc("config.database.sqlite3.encryption.keyfile.path", "The path to a file containing the hex encoded 32 byte master key, for the `keyfile` provider", i18n.StringType)
+	ConfigDatabaseSqlite3EncryptionKMSType         = ffc("config.database.sqlite3.encryption.kms.type", "The type of key management service holding the master key, for the `kms` provider. Only the `local` stand-in is currently supported", i18n.StringType)
+	ConfigDatabaseSqlite3EncryptionKMSKeyID        = ffc("config.database.sqlite3.encryption.kms.keyId", "The identifier of the master key in the key management service", i18n.StringType)
+	ConfigDatabaseSqlite3EncryptionKMSLocalKeyDir  = ffc("config.database.sqlite3.encryption.kms.local.keyDir", "The directory containing the hex encoded master keys of the `local` key management service, in files named `<keyId>.key`", i18n.StringType)
+	ConfigDatabaseSqliteGoMaxConnIdleTime          = ffc("config.database.sqlitego.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
+	ConfigDatabaseSqliteGoMaxConnLifetime          = ffc("config.database.sqlitego.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
+	ConfigDatabaseSqliteGoMaxConns                 = ffc("config.database.sqlitego.maxConns", "Maximum connections to the database", i18n.IntType)
+	ConfigDatabaseSqliteGoMaxIdleConns             = ffc("config.database.sqlitego.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
+	ConfigDatabaseSqliteGoURL                      = ffc("config.database.sqlitego.url", "The SQLite connection string for the database", i18n.StringType)
+	ConfigDatabaseSqliteGoEncryptionEnabled        = ffc("config.database.sqlitego.encryption.enabled", "Encrypts the values of data records written to the database, using envelope encryption with a data key that is wrapped by the configured key provider", i18n.BooleanType)
+	ConfigDatabaseSqliteGoEncryptionProvider       = ffc("config.database.sqlitego.encryption.provider", "The key provider that wraps the data keys - `keyfile` or `kms`", i18n.StringType)
+	ConfigDatabaseSqliteGoEncryptionKeyfilePath    = ffc("config.database.sqlitego.encryption.keyfile.path", "The path to a file containing the hex encoded 32 byte master key, for the `keyfile` provider", i18n.StringType)
+	ConfigDatabaseSqliteGoEncryptionKMSType        = ffc("config.database.sqlitego.encryption.kms.type", "The type of key management service holding the master key, for the `kms` provider. Only the `local` stand-in is currently supported", i18n.StringType)
+	ConfigDatabaseSqliteGoEncryptionKMSKeyID       = ffc("config.database.sqlitego.encryption.kms.keyId", "The identifier of the master key in the key management service", i18n.StringType)
+	ConfigDatabaseSqliteGoEncryptionKMSLocalKeyDir = ffc("config.database.sqlitego.encryption.kms.local.keyDir", "The directory containing the hex encoded master keys of the `local` key management service, in files named `<keyId>.key`", i18n.StringType)
 
 	ConfigDataexchangeType = ffc("config.dataexchange.type", "The Data Exchange plugin to use", i18n.StringType)
 
@@ -272,32 +357,47 @@ var (
 	ConfigMessageWriterBatchTimeout    = ffc("config.message.writer.batchTimeout", "How long to wait for more messages to arrive before flushing the batch", i18n.TimeDurationType)
 	ConfigMessageWriterCount           = ffc("config.message.writer.count", "The number of message writer workers", i18n.IntType)
 
-	ConfigMetricsAddress      = ffc("config.metrics.address", "The IP address on which the metrics HTTP API should listen", i18n.IntType)
-	ConfigMetricsEnabled      = ffc("config.metrics.enabled", "Enables the metrics API", i18n.BooleanType)
-	ConfigMetricsPath         = ffc("config.metrics.path", "The path from which to serve the Prometheus metrics", i18n.StringType)
-	ConfigMetricsPort         = ffc("config.metrics.port", "The port on which the metrics HTTP API should listen", i18n.IntType)
-	ConfigMetricsPublicURL    = ffc("config.metrics.publicURL", "The fully qualified public URL for the metrics API. This is used for building URLs in HTTP responses and in OpenAPI Spec generation", "URL "+i18n.StringType)
-	ConfigMetricsReadTimeout  = ffc("config.metrics.readTimeout", "The maximum time to wait when reading from an HTTP connection", i18n.TimeDurationType)
-	ConfigMetricsWriteTimeout = ffc("config.metrics.writeTimeout", "The maximum time to wait when writing to an HTTP connection", i18n.TimeDurationType)
-
-	ConfigNamespacesDefault                      = ffc("config.namespaces.default", "The default namespace - must be in the predefined list", i18n.StringType)
-	ConfigNamespacesPredefined                   = ffc("config.namespaces.predefined", "A list of namespaces to ensure exists, without requiring a broadcast from the network", "List "+i18n.StringType)
-	ConfigNamespacesPredefinedName               = ffc("config.namespaces.predefined[].name", "The name of the namespace (must be unique)", i18n.StringType)
-	ConfigNamespacesPredefinedDescription        = ffc("config.namespaces.predefined[].description", "A description for the namespace", i18n.StringType)
-	ConfigNamespacesPredefinedPlugins            = ffc("config.namespaces.predefined[].plugins", "The list of plugins for this namespace", i18n.StringType)
-	ConfigNamespacesPredefinedDefaultKey         = ffc("config.namespaces.predefined[].defaultKey", "A default signing key for blockchain transactions within this namespace", i18n.StringType)
-	ConfigNamespacesPredefinedKeyNormalization   = ffc("config.namespaces.predefined[].asset.manager.keyNormalization", "Mechanism to normalize keys before using them. Valid options are `blockchain_plugin` - use blockchain plugin (default) or `none` - do not attempt normalization", i18n.StringType)
-	ConfigNamespacesMultipartyEnabled            = ffc("config.namespaces.predefined[].multiparty.enabled", "Enables multi-party mode for this namespace (defaults to true if an org name or key is configured, either here or at the root level)", i18n.BooleanType)
-	ConfigNamespacesMultipartyNetworkNamespace   = ffc("config.namespaces.predefined[].multiparty.networknamespace", "The shared namespace name to be sent in multiparty messages, if it differs from the local namespace name", i18n.StringType)
-	ConfigNamespacesMultipartyOrgName            = ffc("config.namespaces.predefined[].multiparty.org.name", "A short name for the local root organization within this namespace", i18n.StringType)
-	ConfigNamespacesMultipartyOrgDesc            = ffc("config.namespaces.predefined[].multiparty.org.description", "A description for the local root organization within this namespace", i18n.StringType)
-	ConfigNamespacesMultipartyOrgKey             = ffc("config.namespaces.predefined[].multiparty.org.key", "The signing key allocated to the root organization within this namespace", i18n.StringType)
-	ConfigNamespacesMultipartyNodeName           = ffc("config.namespaces.predefined[].multiparty.node.name", "The node name for this namespace", i18n.StringType)
-	ConfigNamespacesMultipartyNodeDescription    = ffc("config.namespaces.predefined[].multiparty.node.description", "A description for the node in this namespace", i18n.StringType)
-	ConfigNamespacesMultipartyContract           = ffc("config.namespaces.predefined[].contract", "A list containing configuration for the multi-party blockchain contract", i18n.StringType)
-	ConfigNamespacesMultipartyContractFirstEvent = ffc("config.namespaces.predefined[].multiparty.contract[].firstEvent", "The first event the contract should process. Valid options are `oldest` or `newest`", i18n.StringType)
-	ConfigNamespacesMultipartyContractLocation   = ffc("config.namespaces.predefined[].multiparty.contract[].location", "A blockchain-specific contract location. For example, an Ethereum contract address, or a Fabric chaincode name and channel", i18n.StringType)
-	ConfigNamespacesMultipartyContractOptions    = ffc("config.namespaces.predefined[].multiparty.contract[].options", "Blockchain-specific contract options", i18n.StringType)
+	ConfigMetricsAddress                        = ffc("config.metrics.address", "The IP address on which the metrics HTTP API should listen", i18n.IntType)
+	ConfigMetricsContractListenerStatusInterval = ffc("config.metrics.contractListenerStatusInterval", "How often the leader for each namespace polls the blockchain connector for the status of its contract listeners, to update the contract listener metrics. Set to 0 to disable polling", i18n.TimeDurationType)
+	ConfigMetricsEnabled                        = ffc("config.metrics.enabled", "Enables the metrics API", i18n.BooleanType)
+	ConfigMetricsPath                           = ffc("config.metrics.path", "The path from which to serve the Prometheus metrics", i18n.StringType)
+	ConfigMetricsPort                           = ffc("config.metrics.port", "The port on which the metrics HTTP API should listen", i18n.IntType)
+	ConfigMetricsPublicURL                      = ffc("config.metrics.publicURL", "The fully qualified public URL for the metrics API. This is used for building URLs in HTTP responses and in OpenAPI Spec generation", "URL "+i18n.StringType)
+	ConfigMetricsReadTimeout                    = ffc("config.metrics.readTimeout", "The maximum time to wait when reading from an HTTP connection", i18n.TimeDurationType)
+	ConfigMetricsWriteTimeout                   = ffc("config.metrics.writeTimeout", "The maximum time to wait when writing to an HTTP connection", i18n.TimeDurationType)
+
+	ConfigNamespacesDefault                          = ffc("config.namespaces.default", "The default namespace - must be in the predefined list", i18n.StringType)
+	ConfigNamespacesPredefined                       = ffc("config.namespaces.predefined", "A list of namespaces to ensure exists, without requiring a broadcast from the network", "List "+i18n.StringType)
+	ConfigNamespacesPredefinedName                   = ffc("config.namespaces.predefined[].name", "The name of the namespace (must be unique)", i18n.StringType)
+	ConfigNamespacesPredefinedDescription            = ffc("config.namespaces.predefined[].description", "A description for the namespace", i18n.StringType)
+	ConfigNamespacesPredefinedPlugins                = ffc("config.namespaces.predefined[].plugins", "The list of plugins for this namespace", i18n.StringType)
+	ConfigNamespacesPredefinedDefaultKey             = ffc("config.namespaces.predefined[].defaultKey", "A default signing key for blockchain transactions within this namespace", i18n.StringType)
+	ConfigNamespacesPredefinedKeyNormalization       = ffc("config.namespaces.predefined[].asset.manager.keyNormalization", "Mechanism to normalize keys before using them. Valid options are `blockchain_plugin` - use blockchain plugin (default) or `none` - do not attempt normalization", i18n.StringType)
+	ConfigNamespacesMultipartyEnabled                = ffc("config.namespaces.predefined[].multiparty.enabled", "Enables multi-party mode for this namespace (defaults to true if an org name or key is configured, either here or at the root level)", i18n.BooleanType)
+	ConfigNamespacesMultipartyNetworkNamespace       = ffc("config.namespaces.predefined[].multiparty.networknamespace", "The shared namespace name to be sent in multiparty messages, if it differs from the local namespace name", i18n.StringType)
+	ConfigNamespacesMultipartyOrgName                = ffc("config.namespaces.predefined[].multiparty.org.name", "A short name for the local root organization within this namespace", i18n.StringType)
+	ConfigNamespacesMultipartyOrgDesc                = ffc("config.namespaces.predefined[].multiparty.org.description", "A description for the local root organization within this namespace", i18n.StringType)
+	ConfigNamespacesMultipartyOrgKey                 = ffc("config.namespaces.predefined[].multiparty.org.key", "The signing key allocated to the root organization within this namespace", i18n.StringType)
+	ConfigNamespacesMultipartyNodeName               = ffc("config.namespaces.predefined[].multiparty.node.name", "The node name for this namespace", i18n.StringType)
+	ConfigNamespacesMultipartyNodeDescription        = ffc("config.namespaces.predefined[].multiparty.node.description", "A description for the node in this namespace", i18n.StringType)
+	ConfigNamespacesMultipartyContract               = ffc("config.namespaces.predefined[].contract", "A list containing configuration for the multi-party blockchain contract", i18n.StringType)
+	ConfigNamespacesMultipartyContractFirstEvent     = ffc("config.namespaces.predefined[].multiparty.contract[].firstEvent", "The first event the contract should process. Valid options are `oldest` or `newest`", i18n.StringType)
+	ConfigNamespacesMultipartyContractLocation       = ffc("config.namespaces.predefined[].multiparty.contract[].location", "A blockchain-specific contract location. For example, an Ethereum contract address, or a Fabric chaincode name and channel", i18n.StringType)
+	ConfigNamespacesMultipartyContractOptions        = ffc("config.namespaces.predefined[].multiparty.contract[].options", "Blockchain-specific contract options", i18n.StringType)
+	ConfigNamespacesRetentionInterval                = ffc("config.namespaces.predefined[].retention.interval", "How often the retention policy of the namespace is enforced", i18n.TimeDurationType)
+	ConfigNamespacesRetentionBatchSize               = ffc("config.namespaces.predefined[].retention.batchSize", "The maximum number of records deleted in a single database transaction when enforcing the retention policy", i18n.IntType)
+	ConfigNamespacesRetentionEventsMaxAge            = ffc("config.namespaces.predefined[].retention.events.maxAge", "Prune events older than this age. Records still needed by the node are never pruned", i18n.TimeDurationType)
+	ConfigNamespacesRetentionEventsMaxRows           = ffc("config.namespaces.predefined[].retention.events.maxRows", "Prune the oldest events, beyond this number of the newest. Records still needed by the node are never pruned", i18n.IntType)
+	ConfigNamespacesRetentionMessagesMaxAge          = ffc("config.namespaces.predefined[].retention.messages.maxAge", "Prune messages older than this age. Records still needed by the node are never pruned", i18n.TimeDurationType)
+	ConfigNamespacesRetentionMessagesMaxRows         = ffc("config.namespaces.predefined[].retention.messages.maxRows", "Prune the oldest messages, beyond this number of the newest. Records still needed by the node are never pruned", i18n.IntType)
+	ConfigNamespacesRetentionOperationsMaxAge        = ffc("config.namespaces.predefined[].retention.operations.maxAge", "Prune operations older than this age. Records still needed by the node are never pruned", i18n.TimeDurationType)
+	ConfigNamespacesRetentionOperationsMaxRows       = ffc("config.namespaces.predefined[].retention.operations.maxRows", "Prune the oldest operations, beyond this number of the newest. Records still needed by the node are never pruned", i18n.IntType)
+	ConfigNamespacesRetentionBatchesMaxAge           = ffc("config.namespaces.predefined[].retention.batches.maxAge", "Prune batches older than this age. Records still needed by the node are never pruned", i18n.TimeDurationType)
+	ConfigNamespacesRetentionBatchesMaxRows          = ffc("config.namespaces.predefined[].retention.batches.maxRows", "Prune the oldest batches, beyond this number of the newest. Records still needed by the node are never pruned", i18n.IntType)
+	ConfigNamespacesRetentionPinsMaxAge              = ffc("config.namespaces.predefined[].retention.pins.maxAge", "Prune pins older than this age. Records still needed by the node are never pruned", i18n.TimeDurationType)
+	ConfigNamespacesRetentionPinsMaxRows             = ffc("config.namespaces.predefined[].retention.pins.maxRows", "Prune the oldest pins, beyond this number of the newest. Records still needed by the node are never pruned", i18n.IntType)
+	ConfigNamespacesRetentionBlockchainEventsMaxAge  = ffc("config.namespaces.predefined[].retention.blockchainevents.maxAge", "Prune blockchain events older than this age. Records still needed by the node are never pruned", i18n.TimeDurationType)
+	ConfigNamespacesRetentionBlockchainEventsMaxRows = ffc("config.namespaces.predefined[].retention.blockchainevents.maxRows", "Prune the oldest blockchain events, beyond this number of the newest. Records still needed by the node are never pruned", i18n.IntType)
 
 	ConfigNodeDescription = ffc("config.node.description", "The description of this FireFly node", i18n.StringType)
 	ConfigNodeName        = ffc("config.node.name", "The name of this FireFly node", i18n.StringType)
@@ -332,8 +432,9 @@ var (
 	ConfigPluginSharedstorageIpfsGatewayURL      = ffc("config.plugins.sharedstorage[].ipfs.gateway.url", "The URL for the IPFS Gateway", "URL "+i18n.StringType)
 	ConfigPluginSharedstorageIpfsGatewayProxyURL = ffc("config.plugins.sharedstorage[].ipfs.gateway.proxy.url", "Optional HTTP proxy server to use when connecting to the IPFS Gateway", "URL "+i18n.StringType)
 
-	ConfigSubscriptionMax               = ffc("config.subscription.max", "The maximum number of pre-defined subscriptions that can exist (note for high fan-out consider connecting a dedicated pub/sub broker to the dispatcher)", i18n.IntType)
-	ConfigSubscriptionDefaultsBatchSize = ffc("config.subscription.defaults.batchSize", "Default read ahead to enable for subscriptions that do not explicitly configure readahead", i18n.IntType)
+	ConfigSubscriptionMax                  = ffc("config.subscription.max", "The maximum number of pre-defined subscriptions that can exist (note for high fan-out consider connecting a dedicated pub/sub broker to the dispatcher)", i18n.IntType)
+	ConfigSubscriptionDefaultsBatchSize    = ffc("config.subscription.defaults.batchSize", "Default read ahead to enable for subscriptions that do not explicitly configure readahead", i18n.IntType)
+	ConfigSubscriptionDefaultsBatchTimeout = ffc("config.subscription.defaults.batchTimeout", "Default batch timeout for subscriptions that enable batch delivery, but do not explicitly configure a batch timeout", i18n.TimeDurationType)
 
 	ConfigTokensName     = ffc("config.tokens[].name", "A name to identify this token plugin", i18n.StringType)
 	ConfigTokensPlugin   = ffc("config.tokens[].plugin", "The type of the token plugin to use", i18n.StringType)
@@ -360,7 +461,25 @@ var (
 	ConfigPluginsAuthType = ffc("config.plugins.auth[].type", "The type of the auth plugin to use", i18n.StringType)
 
 	ConfigPluginsEventSystemReadAhead           = ffc("config.events.system.readAhead", "", i18n.IgnoredType)
+	ConfigPluginsEventKafkaBrokers              = ffc("config.events.kafka.brokers", "The list of Kafka brokers to connect to, in host:port format", i18n.ArrayStringType)
+	ConfigPluginsEventKafkaClientID             = ffc("config.events.kafka.clientId", "The client ID to use when connecting to Kafka", i18n.StringType)
+	ConfigPluginsEventKafkaProduceTimeout       = ffc("config.events.kafka.produceTimeout", "The maximum time to wait for the brokers to confirm each produce request", i18n.TimeDurationType)
+	ConfigPluginsEventKafkaVersion              = ffc("config.events.kafka.version", "The version of the Kafka protocol to use, such as 2.8.0. Defaults to the minimum version supported by the client library", i18n.StringType)
+	ConfigPluginsEventNATSURL                   = ffc("config.events.nats.url", "The URL of the NATS server to connect to, such as nats://127.0.0.1:4222. Multiple servers can be provided as a comma separated list", i18n.StringType)
+	ConfigPluginsEventNATSSubjectPrefix         = ffc("config.events.nats.subjectPrefix", "The prefix of the JetStream subject each subscription publishes to. The full subject is <subjectPrefix>.<namespace>.<subscription name>", i18n.StringType)
+	ConfigPluginsEventNATSStream                = ffc("config.events.nats.stream", "The name of a JetStream stream to create on startup if it does not exist, capturing all subjects under the subject prefix. If unset, a stream covering the subjects must be created outside of FireFly", i18n.StringType)
+	ConfigPluginsEventNATSPublishTimeout        = ffc("config.events.nats.publishTimeout", "The maximum time to wait for JetStream to acknowledge each published event", i18n.TimeDurationType)
 	ConfigPluginsEventWebhooksURL               = ffc("config.events.webhooks.url", "", i18n.IgnoredType)
+	ConfigPluginsEventWebhooksSigningKey        = ffc("config.events.webhooks.signing.encryptionKey", "A 32 byte AES-256 key, encoded as hex, used to encrypt inline signing secrets before subscriptions are stored. Inline secrets are rejected if this is not set", i18n.StringType)
+	ConfigPluginsEventWebhooksSigningSecrets    = ffc("config.events.webhooks.signing.secrets", "Named HMAC secrets that webhook subscriptions can reference with the signing.secretRef option", objectArrayType)
+	ConfigPluginsEventWebhooksSigningSecretName = ffc("config.events.webhooks.signing.secrets[].name", "The name subscriptions use to reference the secret", i18n.StringType)
+	ConfigPluginsEventWebhooksSigningSecret     = ffc("config.events.webhooks.signing.secrets[].secret", "The HMAC secret", i18n.StringType)
+	ConfigPluginsEventWebhooksTLSConfigs        = ffc("config.events.webhooks.tlsConfigs", "Named TLS configurations that webhook subscriptions can reference with the tls.configRef option. Subscriptions can only use files on the host through these", objectArrayType)
+	ConfigPluginsEventWebhooksTLSConfigName     = ffc("config.events.webhooks.tlsConfigs[].name", "The name subscriptions use to reference the TLS configuration", i18n.StringType)
+	ConfigPluginsEventWebhooksTLSConfigCertFile = ffc("config.events.webhooks.tlsConfigs[].certFile", "The path to a PEM encoded client certificate to present to the webhook server. Requires keyFile", i18n.StringType)
+	ConfigPluginsEventWebhooksTLSConfigKeyFile  = ffc("config.events.webhooks.tlsConfigs[].keyFile", "The path to the PEM encoded private key for the client certificate. Requires certFile", i18n.StringType)
+	ConfigPluginsEventWebhooksTLSConfigCAFile   = ffc("config.events.webhooks.tlsConfigs[].caFile", "The path to a PEM encoded CA bundle, used instead of the system CAs to verify the webhook server", i18n.StringType)
+	ConfigPluginsEventSSEKeepAliveInterval      = ffc("config.events.sse.keepAliveInterval", "The interval at which a comment is written to idle server-sent events streams, so that proxies do not close the connection", i18n.TimeDurationType)
 	ConfigPluginsEventWebSocketsReadBufferSize  = ffc("config.events.websockets.readBufferSize", "WebSocket read buffer size", i18n.ByteSizeType)
 	ConfigPluginsEventWebSocketsWriteBufferSize = ffc("config.events.websockets.writeBufferSize", "WebSocket write buffer size", i18n.ByteSizeType)
 )
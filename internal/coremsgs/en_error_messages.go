@@ -283,4 +283,92 @@ var (
 	MsgMethodDoesNotSupportPinning        = ffe("FF10443", "This method does not support passing a payload for pinning")
 	MsgOperationNotFoundInTransaction     = ffe("FF10444", "No operation of type %s was found in transaction '%s'")
 	MsgCannotSetParameterWithMessage      = ffe("FF10445", "Cannot provide a value for '%s' when pinning a message", 400)
+	MsgBatchDeliveryNotSupported          = ffe("FF10446", "Batch delivery not supported by transport '%s'", 400)
+	MsgBatchWithReplyNotSupported         = ffe("FF10447", "Webhook subscriptions do not support reply mode in combination with batch delivery", 400)
+	MsgSubscriptionNotConnected           = ffe("FF10448", "Subscription '%s' is not currently connected to a transport, so dead letters cannot be replayed", 409)
+	MsgWebhookFailedStatus                = ffe("FF10449", "Webhook request failed with status %d")
+	MsgInvalidFilterExpression            = ffe("FF10450", "Invalid subscription filter expression '%s'", 400)
+	MsgKafkaTopicEmpty                    = ffe("FF10451", "Kafka subscriptions must specify a 'kafka.topic' option", 400)
+	MsgKafkaInvalidOptions                = ffe("FF10452", "Invalid Kafka subscription options", 400)
+	MsgKafkaInvalidEventField             = ffe("FF10453", "Invalid event field '%s' for Kafka option '%s'. Valid fields: %s", 400)
+	MsgKafkaNoBrokers                     = ffe("FF10454", "No brokers configured for the Kafka event transport")
+	MsgKafkaInvalidVersion                = ffe("FF10455", "Invalid Kafka version '%s'")
+	MsgKafkaProducerInitFailed            = ffe("FF10456", "Failed to create Kafka producer")
+	MsgNATSNoURL                          = ffe("FF10457", "No URL configured for the NATS event transport")
+	MsgNATSConnectFailed                  = ffe("FF10458", "Failed to connect to NATS")
+	MsgNATSStreamInitFailed               = ffe("FF10459", "Failed to initialize JetStream stream '%s'")
+	MsgNATSPublishTimeout                 = ffe("FF10460", "Timed out waiting for JetStream to acknowledge publish to subject '%s'")
+	MsgWebhookSigningInvalidOptions       = ffe("FF10461", "Invalid webhook signing options", 400)
+	MsgWebhookSigningSecretRequired       = ffe("FF10462", "Webhook signing options must specify exactly one of 'secret', 'secretRef' or 'encryptedSecret'", 400)
+	MsgWebhookSigningUnknownSecretRef     = ffe("FF10463", "Unknown webhook signing secret reference '%s'", 400)
+	MsgWebhookSigningNoEncryptionKey      = ffe("FF10464", "Inline webhook signing secrets require an encryption key to be configured for the webhooks plugin", 400)
+	MsgWebhookSigningBadEncryptionKey     = ffe("FF10465", "Invalid webhook signing encryption key - must be 32 bytes encoded as hex")
+	MsgWebhookSigningDecryptFailed        = ffe("FF10466", "Failed to decrypt webhook signing secret", 400)
+	MsgWebhookSigningBadSecretConfig      = ffe("FF10467", "Invalid webhook signing secret configuration at index %d - a name and secret are required")
+	MsgWebhookTLSInvalidOptions           = ffe("FF10468", "Invalid webhook TLS options", 400)
+	MsgWebhookTLSCertKeyRequired          = ffe("FF10469", "Invalid webhook TLS configuration at index %d - a name is required, and both 'certFile' and 'keyFile' or neither")
+	MsgWebhookTLSLoadCertFailed           = ffe("FF10470", "Failed to load webhook TLS client certificate '%s'", 400)
+	MsgWebhookTLSLoadCAFailed             = ffe("FF10471", "Failed to load webhook TLS CA bundle '%s'", 400)
+	MsgWebhookTLSInvalidCA                = ffe("FF10472", "No valid PEM encoded certificates found in webhook TLS CA bundle", 400)
+	MsgWebhookTLSCAConflict               = ffe("FF10473", "Webhook TLS options cannot specify 'ca', as TLS configuration '%s' has a 'caFile'", 400)
+	MsgInvalidSubscriptionTransform       = ffe("FF10474", "Invalid subscription transform template", 400)
+	MsgTransformNotSupported              = ffe("FF10475", "Transform templates not supported by transport '%s'", 400)
+	MsgTransformFailed                    = ffe("FF10476", "Failed to transform event '%s'")
+	MsgTransformInvalidJSON               = ffe("FF10477", "Transform of event '%s' did not produce valid JSON")
+	MsgInvalidPartitionKey                = ffe("FF10478", "Invalid partition key '%s' - valid keys are: %s", 400)
+	MsgPartitionKeyWithBatch              = ffe("FF10479", "Partition key cannot be used with batch delivery", 400)
+	MsgSSEStreamingNotSupported           = ffe("FF10480", "Server-sent events are not supported by the HTTP server")
+	MsgSSEInvalidStart                    = ffe("FF10481", "A server-sent events request must set namespace and either a name or ephemeral=true", 400)
+	MsgSSEInvalidLastEventID              = ffe("FF10482", "Invalid Last-Event-ID '%s' - must be the sequence of an event", 400)
+	MsgSSEConnectionNotActive             = ffe("FF10483", "Server-sent events connection '%s' is not active", 404)
+	MsgSSEAutoAckEnabled                  = ffe("FF10484", "The autoack option is enabled on server-sent events connection '%s'", 400)
+	MsgSSEAckNotMatched                   = ffe("FF10485", "Acknowledgment does not match an event in flight on server-sent events connection '%s'", 400)
+	MsgSSEConnectionClosed                = ffe("FF10486", "Server-sent events connection closed")
+	MsgInvalidCursor                      = ffe("FF10487", "Invalid cursor '%s'", 400)
+	MsgCursorNotCombinable                = ffe("FF10488", "The '%s' query parameter cannot be combined with a cursor", 400)
+	MsgLeadershipHeldElsewhere            = ffe("FF10489", "Leadership of '%s' is held by another process")
+	MsgSnapshotNamespaceNotEmpty          = ffe("FF10490", "Cannot import a snapshot into namespace '%s', as it already contains %s", 409)
+	MsgSnapshotInvalid                    = ffe("FF10491", "Invalid snapshot archive: %s", 400)
+	MsgSnapshotVersionUnsupported         = ffe("FF10492", "Unsupported snapshot version %d", 400)
+	MsgSnapshotHashMismatch               = ffe("FF10493", "Integrity check failed for %s '%s' in snapshot - expected hash '%s', calculated '%s'", 400)
+	MsgSnapshotNoDataExchange             = ffe("FF10494", "Namespace '%s' contains blobs, but has no data exchange plugin to store them", 400)
+	MsgSnapshotNamespaceMismatch          = ffe("FF10495", "Snapshot of namespace '%s' cannot be imported into namespace '%s'", 400)
+	MsgSnapshotCountMismatch              = ffe("FF10496", "Snapshot manifest records %d %s, but the archive contains %d", 400)
+	MsgEncryptionUnknownProvider          = ffe("FF10497", "Unknown encryption key provider '%s'")
+	MsgEncryptionUnknownKMS               = ffe("FF10498", "Unknown KMS type '%s'")
+	MsgEncryptionKeyInvalid               = ffe("FF10499", "Invalid encryption key '%s': %s")
+	MsgEncryptionFailed                   = ffe("FF10500", "Failed to encrypt value: %s")
+	MsgDecryptionFailed                   = ffe("FF10501", "Failed to decrypt value: %s")
+	MsgEncryptionNotEnabled               = ffe("FF10502", "Value of data '%s' is encrypted, but encryption is not enabled on the database plugin")
+	MsgInvalidValuePath                   = ffe("FF10503", "Invalid path '%s' for a filter on data values - each segment of the path must contain only letters, numbers, '_' and '-'", 400)
+	MsgValueFilterNotSupported            = ffe("FF10504", "The database plugin does not support filters on data values", 400)
+	MsgInvalidPartitionStrategy           = ffe("FF10505", "Invalid partitioning strategy '%s' - must be 'sequence' or 'time'")
+	MsgInvalidPartitionRange              = ffe("FF10506", "The range of each partition must be greater than zero")
+	MsgMigrationsReadFailed               = ffe("FF10507", "Failed to read database migrations from '%s'")
+	MsgDatabaseSchemaDirty                = ffe("FF10508", "Database schema is dirty at version %d - a migration failed part way through, and must be repaired manually")
+	MsgDatabaseSchemaNewer                = ffe("FF10509", "Database schema version %d is newer than the latest migration %d - the database was migrated by a newer release")
+	MsgDatabaseSchemaPending              = ffe("FF10510", "Database schema version %d has %d pending migrations, up to version %d")
+	MsgInvalidMigrationSteps              = ffe("FF10511", "Number of migrations to roll back must be a positive integer: '%s'")
+	MsgDatabasePluginNotFound             = ffe("FF10512", "Database plugin '%s' is not configured")
+	MsgDatabasePluginRequired             = ffe("FF10513", "Multiple database plugins are configured - select one with --plugin")
+	MsgListenerFiltersAndEvent            = ffe("FF10514", "A contract listener must be created with either a list of filters, or an event and location, but not both", 400)
+	MsgListenerFilterNotIndexed           = ffe("FF10515", "Parameter '%s' of event '%s' is not indexed, so cannot be used in a listener filter", 400)
+	MsgListenerFiltersNotSupported        = ffe("FF10516", "Contract listeners with multiple filters, or filters on indexed parameters, are not supported by the '%s' blockchain plugin", 400)
+	MsgInvalidChaincodeDefinition         = ffe("FF10517", "Invalid chaincode definition: %s", 400)
+	MsgInvalidChaincodePackage            = ffe("FF10518", "The contract must be a base64 encoded chaincode package", 400)
+	MsgChaincodeDeployInputUnsupported    = ffe("FF10519", "Input parameters are not supported when deploying chaincode - invoke the init function of the chaincode after it is deployed", 400)
+	MsgChaincodeInstallNoPackageID        = ffe("FF10520", "Fabconnect did not return a package ID for the installed chaincode")
+	MsgContractBatchNoCalls               = ffe("FF10521", "A contract invoke batch must contain at least one call", 400)
+	MsgContractBatchCallInvalid           = ffe("FF10522", "Call %d of the contract invoke batch is invalid", 400)
+	MsgContractBatchCallMessage           = ffe("FF10523", "Calls in a contract invoke batch cannot include a message", 400)
+	MsgContractBatchKeyMismatch           = ffe("FF10524", "All calls in an atomic contract invoke batch must be signed by the key of the batch", 400)
+	MsgContractBatchAtomicNotSupported    = ffe("FF10525", "Atomic contract invoke batches are not supported by the '%s' blockchain plugin with its current configuration", 400)
+	MsgPartitionUniqueIndex               = ffe("FF10526", "Cannot partition table '%s', as the partition key cannot be added to its unique index '%s'")
+	MsgWebhookTLSUnknownConfigRef         = ffe("FF10527", "Unknown webhook TLS configuration reference '%s'", 400)
+	MsgValueFilterEncrypted               = ffe("FF10528", "Filters on data values are not supported when data values are encrypted at rest", 400)
+	MsgContractBatchSingleTarget          = ffe("FF10529", "Call %d of the atomic contract invoke batch targets a different contract to the first call - all calls must target the same contract when the sender is preserved", 400)
+	MsgContractBatchOutputInvalid         = ffe("FF10530", "Failed to decode the results of the calls from the return value of the atomic contract invoke batch")
+	MsgBadBlockNumber                     = ffe("FF10531", "Invalid block number returned by the blockchain connector: '%v'")
+	MsgSSENotEnabled                      = ffe("FF10532", "Server-sent events are not enabled - add 'sse' to events.transports.enabled", 404)
+	MsgWebhookSigningEncryptedSecretInput = ffe("FF10533", "Webhook signing option 'encryptedSecret' is set by FireFly, and cannot be submitted - set 'secret' or 'secretRef' instead", 400)
 )
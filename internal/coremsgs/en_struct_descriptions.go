@@ -144,6 +144,19 @@ var (
 	DatatypeCreated   = ffm("Datatype.created", "The time the datatype was created")
 	DatatypeValue     = ffm("Datatype.value", "The definition of the datatype, in the syntax supported by the validator (such as a JSON Schema definition)")
 
+	// DeadLetter field descriptions
+	DeadLetterID           = ffm("DeadLetter.id", "The UUID of the dead letter")
+	DeadLetterNamespace    = ffm("DeadLetter.namespace", "The namespace of the dead letter, which is the namespace of the subscription")
+	DeadLetterSubscription = ffm("DeadLetter.subscription", "A reference to the subscription the event could not be delivered to")
+	DeadLetterEvent        = ffm("DeadLetter.event", "The UUID of the event that could not be delivered")
+	DeadLetterAttempts     = ffm("DeadLetter.attempts", "The number of delivery attempts made for the event, including any replays")
+	DeadLetterReason       = ffm("DeadLetter.reason", "The failure reason returned by the transport on the last rejected delivery attempt")
+	DeadLetterCreated      = ffm("DeadLetter.created", "The time the event was moved to the dead-letter collection")
+	DeadLetterUpdated      = ffm("DeadLetter.updated", "The time of the last failed replay of the dead letter")
+
+	// DeadLetterReplay field descriptions
+	DeadLetterReplayIDs = ffm("DeadLetterReplay.ids", "The UUIDs of the dead letters to replay. If empty, all dead letters for the subscription are replayed")
+
 	// SignerRef field descriptions
 	SignerRefAuthor = ffm("SignerRef.author", "The DID of identity of the submitter")
 	SignerRefKey    = ffm("SignerRef.key", "The on-chain signing key used to sign the transaction")
@@ -210,6 +223,7 @@ var (
 	BlockchainEventInfo       = ffm("BlockchainEvent.info", "Detailed blockchain specific information about the event, as generated by the blockchain connector")
 	BlockchainEventTimestamp  = ffm("BlockchainEvent.timestamp", "The time allocated to this event by the blockchain. This is the block timestamp for most blockchain connectors")
 	BlockchainEventTX         = ffm("BlockchainEvent.tx", "If this blockchain event is coorelated to FireFly transaction such as a FireFly submitted token transfer, this field is set to the UUID of the FireFly transaction")
+	BlockchainEventFilter     = ffm("BlockchainEvent.filter", "The index of the filter on the listener that matched this event, for listeners created with a list of filters")
 
 	// ChartHistogram field descriptions
 	ChartHistogramCount     = ffm("ChartHistogram.count", "Total count of entries in this time bucket within the histogram")
@@ -292,7 +306,7 @@ var (
 	FFIGenerationRequestName        = ffm("FFIGenerationRequest.name", "The name of the FFI to generate")
 	FFIGenerationRequestDescription = ffm("FFIGenerationRequest.description", "The description of the FFI to be generated. Defaults to the description extracted by the blockchain specific converter utility")
 	FFIGenerationRequestVersion     = ffm("FFIGenerationRequest.version", "The version of the FFI to generate")
-	FFIGenerationRequestInput       = ffm("FFIGenerationRequest.input", "A blockchain connector specific payload. For example in Ethereum this is a JSON structure containing an 'abi' array, and optionally a 'devdocs' array.")
+	FFIGenerationRequestInput       = ffm("FFIGenerationRequest.input", "A blockchain connector specific payload. For example in Ethereum this is a JSON structure containing an 'abi' array, and optionally a 'devdocs' array. In Fabric this is a JSON structure containing the contract-api 'metadata' of the chaincode.")
 
 	// ContractListener field descriptions
 	ContractListenerID        = ffm("ContractListener.id", "The UUID of the smart contract listener")
@@ -308,10 +322,34 @@ var (
 	ContractListenerEventPath = ffm("ContractListener.eventPath", "When creating a listener from an existing FFI, this is the pathname of the event on that FFI to be detected by this listener")
 	ContractListenerSignature = ffm("ContractListener.signature", "The stringified signature of the event, as computed by the blockchain plugin")
 	ContractListenerState     = ffm("ContractListener.state", "This field is provided for the event listener implementation of the blockchain provider to record state, such as checkpoint information")
+	ContractListenerFilters   = ffm("ContractListener.filters", "A list of filters, each selecting an event (optionally at a location, and with values for its indexed parameters) to be detected by this listener. Used instead of event and location to detect multiple events with a single listener")
+
+	// ListenerFilter field descriptions
+	ListenerFilterInterface = ffm("ListenerFilter.interface", "A reference to an existing FFI, containing pre-registered type information for the event")
+	ListenerFilterEvent     = ffm("ListenerFilter.event", "The definition of the event, either provided in-line when creating the listener, or extracted from the referenced FFI")
+	ListenerFilterEventPath = ffm("ListenerFilter.eventPath", "When creating a filter from an existing FFI, this is the pathname of the event on that FFI")
+	ListenerFilterLocation  = ffm("ListenerFilter.location", "A blockchain specific contract identifier. If not set, the event is detected from any contract")
+	ListenerFilterIndexed   = ffm("ListenerFilter.indexed", "Values that the indexed parameters of the event must have to match this filter, keyed by parameter name")
+	ListenerFilterSignature = ffm("ListenerFilter.signature", "The stringified signature of the event and location, as computed by the blockchain plugin")
 
 	// ContractListenerOptions field descriptions
 	ContractListenerOptionsFirstEvent = ffm("ContractListenerOptions.firstEvent", "A blockchain specific string, such as a block number, to start listening from. The special strings 'oldest' and 'newest' are supported by all blockchain connectors. Default is 'newest'")
 
+	// ContractListenerWithStatus field descriptions
+	ContractListenerWithStatusStatus = ffm("ContractListenerWithStatus.status", "The progress of the listener through the chain, as reported by the blockchain connector")
+
+	// ContractListenerStatus field descriptions
+	ContractListenerStatusCheckpoint = ffm("ContractListenerStatus.checkpoint", "The last position in the chain that the listener has processed, if reported by the blockchain connector. Not supported on Fabric")
+	ContractListenerStatusHeadBlock  = ffm("ContractListenerStatus.headBlock", "The number of the latest block on the chain, if the blockchain connector reports it")
+	ContractListenerStatusCatchup    = ffm("ContractListenerStatus.catchup", "True if the listener is catching up on historical blocks, rather than following the head of the chain. Not supported on Fabric, where it is always false")
+	ContractListenerStatusLag        = ffm("ContractListenerStatus.lag", "The number of blocks between the checkpoint of the listener and the head of the chain. Not supported on Fabric")
+	ContractListenerStatusError      = ffm("ContractListenerStatus.error", "An error that occurred querying the status of the listener from the blockchain connector")
+
+	// ContractListenerCheckpoint field descriptions
+	ContractListenerCheckpointBlock            = ffm("ContractListenerCheckpoint.block", "The block number of the checkpoint")
+	ContractListenerCheckpointTransactionIndex = ffm("ContractListenerCheckpoint.transactionIndex", "The index of the transaction within the block of the checkpoint")
+	ContractListenerCheckpointLogIndex         = ffm("ContractListenerCheckpoint.logIndex", "The index of the log within the transaction of the checkpoint")
+
 	// DIDDocument field descriptions
 	DIDDocumentContext            = ffm("DIDDocument.@context", "See https://www.w3.org/TR/did-core/#json-ld")
 	DIDDocumentID                 = ffm("DIDDocument.id", "See https://www.w3.org/TR/did-core/#did-document-properties")
@@ -422,6 +460,27 @@ var (
 	NamespaceStatusOrg  = ffm("NamespaceStatus.org", "Details of the root organization identity registered for this namespace on the local node")
 	NamespacePlugins    = ffm("NamespaceStatus.plugins", "Information about plugins configured on this namespace")
 	NamespaceMultiparty = ffm("NamespaceStatus.multiparty", "Information about the multi-party system configured on this namespace")
+	NamespaceSchema     = ffm("NamespaceStatus.schema", "The version of the database schema, compared to the migrations shipped with this node")
+
+	// RetentionStatus field descriptions
+	RetentionStatusEnabled     = ffm("RetentionStatus.enabled", "Whether a retention policy is configured for any collection in this namespace")
+	RetentionStatusInterval    = ffm("RetentionStatus.interval", "How often the retention policy is enforced")
+	RetentionStatusLastRun     = ffm("RetentionStatus.lastRun", "The time the retention policy was last enforced")
+	RetentionStatusLastError   = ffm("RetentionStatus.lastError", "The error that prevented the last run from determining which records are still needed, if any")
+	RetentionStatusProtected   = ffm("RetentionStatus.protected", "The bounds determined on the last run, that protect records still needed by the node from being pruned")
+	RetentionStatusCollections = ffm("RetentionStatus.collections", "The retention policy and pruning results for each collection with a policy configured")
+
+	// RetentionProtected field descriptions
+	RetentionProtectedEventSequence = ffm("RetentionProtected.eventSequence", "Events after this sequence have not yet been delivered to all durable subscriptions, so are not pruned, nor are the messages, operations, batches and blockchain events they reference. Omitted if there are no durable subscriptions")
+	RetentionProtectedPinSequence   = ffm("RetentionProtected.pinSequence", "Pins after this sequence have not yet been processed by the aggregator, so are not pruned, nor are their batches")
+
+	// RetentionCollectionStatus field descriptions
+	RetentionCollectionStatusCollection  = ffm("RetentionCollectionStatus.collection", "The name of the collection")
+	RetentionCollectionStatusMaxAge      = ffm("RetentionCollectionStatus.maxAge", "Records older than this age are pruned")
+	RetentionCollectionStatusMaxRows     = ffm("RetentionCollectionStatus.maxRows", "Records beyond this number of the newest are pruned")
+	RetentionCollectionStatusLastPruned  = ffm("RetentionCollectionStatus.lastPruned", "The number of records pruned on the last run")
+	RetentionCollectionStatusTotalPruned = ffm("RetentionCollectionStatus.totalPruned", "The number of records pruned since the node started")
+	RetentionCollectionStatusLastError   = ffm("RetentionCollectionStatus.lastError", "The error from pruning this collection on the last run, if any")
 
 	// NamespaceStatusNode field descriptions
 	NamespaceStatusNodeName       = ffm("NamespaceStatusNode.name", "The name of this node, as specified in the local configuration")
@@ -451,6 +510,17 @@ var (
 	NamespaceStatusPluginName = ffm("NamespaceStatusPlugin.name", "The name of the plugin")
 	NamespaceStatusPluginType = ffm("NamespaceStatusPlugin.pluginType", "The type of the plugin")
 
+	// DatabaseSchemaStatus field descriptions
+	DatabaseSchemaStatusVersion = ffm("DatabaseSchemaStatus.version", "The version of the last migration applied to the database schema")
+	DatabaseSchemaStatusDirty   = ffm("DatabaseSchemaStatus.dirty", "Whether the last migration failed part way through, leaving the schema in need of manual repair")
+	DatabaseSchemaStatusLatest  = ffm("DatabaseSchemaStatus.latest", "The version of the latest migration in the migrations directory of this node")
+	DatabaseSchemaStatusPending = ffm("DatabaseSchemaStatus.pending", "The migrations in the migrations directory that have not been applied to the database schema")
+
+	// DatabaseMigration field descriptions
+	DatabaseMigrationVersion = ffm("DatabaseMigration.version", "The version of the migration")
+	DatabaseMigrationName    = ffm("DatabaseMigration.name", "The name of the migration, from its file name")
+	DatabaseMigrationSQL     = ffm("DatabaseMigration.sql", "The SQL statements of the migration, when requested as a dry run")
+
 	// NamespaceStatusMultiparty field descriptions
 	NamespaceMultipartyEnabled  = ffm("NamespaceStatusMultiparty.enabled", "Whether multi-party mode is enabled for this namespace")
 	NamespaceMultipartyContract = ffm("NamespaceStatusMultiparty.contract", "Information about the multi-party smart contract configured for this namespace")
@@ -518,6 +588,7 @@ var (
 	SubscriptionFilterDeprecatedTag    = ffm("SubscriptionFilter.tag", "Deprecated: Please use 'message.tag' instead")
 	SubscriptionFilterDeprecatedGroup  = ffm("SubscriptionFilter.group", "Deprecated: Please use 'message.group' instead")
 	SubscriptionFilterDeprecatedAuthor = ffm("SubscriptionFilter.author", "Deprecated: Please use 'message.author' instead")
+	SubscriptionFilterExpression       = ffm("SubscriptionFilter.expression", "A filter expression using the same query syntax as the REST collection APIs, such as 'message.header.cid=abc&tokentransfer.amount=>=100'. Fields are dot-separated paths into the JSON of the event delivery, such as 'blockchainevent.output.value'. All fields must match, and multiple values for the same field match if any one matches")
 
 	// SubscriptionMessageFilter field descriptions
 	SubscriptionMessageFilterTag    = ffm("SubscriptionMessageFilter.tag", "Regular expression to apply to the message 'header.tag' field")
@@ -532,9 +603,17 @@ var (
 	SubscriptionBlockchainEventFilterListener = ffm("SubscriptionBlockchainEventFilter.listener", "Regular expression to apply to the blockchain event 'listener' field, which is the UUID of the event listener. So you can restrict your subscription to certain blockchain listeners. Alternatively to avoid your application need to know listener UUIDs you can set the 'topic' field of blockchain event listeners, and use a topic filter on your subscriptions")
 
 	// SubscriptionCoreOptions field descriptions
-	SubscriptionCoreOptionsFirstEvent = ffm("SubscriptionCoreOptions.firstEvent", "Whether your application would like to receive events from the 'oldest' event emitted by your FireFly node (from the beginning of time), or the 'newest' event (from now), or a specific event sequence. Default is 'newest'")
-	SubscriptionCoreOptionsReadAhead  = ffm("SubscriptionCoreOptions.readAhead", "The number of events to stream ahead to your application, while waiting for confirmation of consumption of those events. At least once delivery semantics are used in FireFly, so if your application crashes/reconnects this is the maximum number of events you would expect to be redelivered after it restarts")
-	SubscriptionCoreOptionsWithData   = ffm("SubscriptionCoreOptions.withData", "Whether message events delivered over the subscription, should be packaged with the full data of those messages in-line as part of the event JSON payload. Or if the application should make separate REST calls to download that data. May not be supported on some transports.")
+	SubscriptionCoreOptionsFirstEvent   = ffm("SubscriptionCoreOptions.firstEvent", "Whether your application would like to receive events from the 'oldest' event emitted by your FireFly node (from the beginning of time), or the 'newest' event (from now), or a specific event sequence. Default is 'newest'")
+	SubscriptionCoreOptionsReadAhead    = ffm("SubscriptionCoreOptions.readAhead", "The number of events to stream ahead to your application, while waiting for confirmation of consumption of those events. At least once delivery semantics are used in FireFly, so if your application crashes/reconnects this is the maximum number of events you would expect to be redelivered after it restarts")
+	SubscriptionCoreOptionsWithData     = ffm("SubscriptionCoreOptions.withData", "Whether message events delivered over the subscription, should be packaged with the full data of those messages in-line as part of the event JSON payload. Or if the application should make separate REST calls to download that data. May not be supported on some transports.")
+	SubscriptionCoreOptionsBatch        = ffm("SubscriptionCoreOptions.batch", "Events are delivered in batches in an ordered array. The batch size is capped to the readAhead limit. The event payload is always an array even if there is a single event in the batch, and the whole batch is acknowledged with a single ack. Commonly used with Webhooks to allow events to be delivered and acknowledged in batches.")
+	SubscriptionCoreOptionsBatchTimeout = ffm("SubscriptionCoreOptions.batchTimeout", "When batching is enabled, the optional timeout to send events even when the batch hasn't filled")
+	SubscriptionCoreOptionsRetry        = ffm("SubscriptionCoreOptions.retry", "The retry policy for events that are rejected by the transport. Without a retry policy rejected events are redelivered indefinitely")
+	SubscriptionCoreOptionsTransform    = ffm("SubscriptionCoreOptions.transform", "A Go template that transforms each event into the JSON payload to deliver, in place of the event. The template is executed against the JSON of the event, including its data when withData is set. Use the json function to insert values as JSON. Not supported by all transports")
+	SubscriptionCoreOptionsPartitionKey = ffm("SubscriptionCoreOptions.partitionKey", "Partitions in-flight events by 'topic', 'group', 'author' or 'tx', so that events with different values can be delivered concurrently up to the readAhead limit, while ordering is maintained between events with the same value. Not supported with batching")
+
+	// SubscriptionRetry field descriptions
+	SubscriptionRetryMaxAttempts = ffm("SubscriptionRetry.maxAttempts", "The maximum number of delivery attempts for an event, after which the event is moved to the dead-letter collection for the subscription and delivery moves on to the next event. Zero means retry indefinitely")
 
 	// TokenApproval field descriptions
 	TokenApprovalLocalID         = ffm("TokenApproval.localId", "The UUID of this token approval, in the local FireFly node")
@@ -638,8 +717,8 @@ var (
 	// ContractDeployRequest field descriptions
 	ContractDeployRequestKey            = ffm("ContractDeployRequest.key", "The blockchain signing key that will be used to deploy the contract. Defaults to the first signing key of the organization that operates the node")
 	ContractDeployRequestInput          = ffm("ContractDeployRequest.input", "An optional array of inputs passed to the smart contract's constructor, if applicable")
-	ContractDeployRequestDefinition     = ffm("ContractDeployRequest.definition", "The definition of the smart contract")
-	ContractDeployRequestContract       = ffm("ContractDeployRequest.contract", "The smart contract to deploy. This should be pre-compiled if required by the blockchain connector")
+	ContractDeployRequestDefinition     = ffm("ContractDeployRequest.definition", "The definition of the smart contract. For example the ABI for Ethereum, or the chaincode definition (name, version, sequence) for Fabric")
+	ContractDeployRequestContract       = ffm("ContractDeployRequest.contract", "The smart contract to deploy. This should be pre-compiled if required by the blockchain connector. For Fabric this is the base64 encoded chaincode package")
 	ContractDeployRequestErrors         = ffm("ContractDeployRequest.errors", "An in-line FFI errors definition for the constructor")
 	ContractDeployRequestOptions        = ffm("ContractDeployRequest.options", "A map of named inputs that will be passed through to the blockchain connector")
 	ContractDeployRequestIdempotencyKey = ffm("ContractDeployRequest.idempotencyKey", "An optional identifier to allow idempotent submission of requests. Stored on the transaction uniquely within a namespace")
@@ -657,11 +736,35 @@ var (
 	ContractCallMessage           = ffm("ContractCallRequest.message", "You can specify a message to correlate with the invocation, which can be of type broadcast or private. Your specified method must support on-chain/off-chain correlation by taking a data input on the call")
 	ContractCallIdempotencyKey    = ffm("ContractCallRequest.idempotencyKey", "An optional identifier to allow idempotent submission of requests. Stored on the transaction uniquely within a namespace")
 
+	// ContractCallBatchRequest field descriptions
+	ContractCallBatchRequestKey            = ffm("ContractCallBatchRequest.key", "The blockchain signing key used for any call that does not specify its own key. Defaults to the first signing key of the organization that operates the node")
+	ContractCallBatchRequestAtomic         = ffm("ContractCallBatchRequest.atomic", "When true, all calls are packed into a single blockchain transaction that succeeds or fails as a whole. Requires support from the blockchain plugin, such as a configured multicall contract on Ethereum. The calls are then made by the Multicall3 contract, so msg.sender in each called contract is the multicall contract rather than the signing key - unless the plugin is configured to preserve the sender")
+	ContractCallBatchRequestCalls          = ffm("ContractCallBatchRequest.calls", "The list of contract invocations to submit, in order")
+	ContractCallBatchRequestOptions        = ffm("ContractCallBatchRequest.options", "A map of named inputs that will be passed through to the blockchain connector, when submitting an atomic batch")
+	ContractCallBatchRequestIdempotencyKey = ffm("ContractCallBatchRequest.idempotencyKey", "An optional identifier to allow idempotent submission of requests. Stored on the transaction uniquely within a namespace")
+
+	// ContractCallBatchResponse field descriptions
+	ContractCallBatchResponseTransaction = ffm("ContractCallBatchResponse.tx", "The FireFly transaction containing all the calls in the batch")
+	ContractCallBatchResponseAtomic      = ffm("ContractCallBatchResponse.atomic", "Whether the calls were packed into a single blockchain transaction")
+	ContractCallBatchResponseCalls       = ffm("ContractCallBatchResponse.calls", "The result of each call, in the same order as the calls in the request")
+
+	// ContractCallBatchResult field descriptions
+	ContractCallBatchResultIndex     = ffm("ContractCallBatchResult.index", "The index of the call in the request")
+	ContractCallBatchResultOperation = ffm("ContractCallBatchResult.operation", "The operation tracking the call. For an atomic batch, all calls share the same operation")
+	ContractCallBatchResultOutput    = ffm("ContractCallBatchResult.output", "The values returned by the call, decoded from the return value of a confirmed atomic batch. Only set if the blockchain connector reports the return value of the transaction")
+
 	// WebSocketStatus field descriptions
 	WebSocketStatusEnabled     = ffm("WebSocketStatus.enabled", "Indicates whether the websockets plugin is enabled")
 	WebSocketStatusConnections = ffm("WebSocketStatus.connections", "List of currently active websocket client connections")
 
 	// WSConnectionStatus field descriptions
+	// SSEConnected field descriptions
+	SSEConnectedConnectionID = ffm("SSEConnected.connectionId", "The unique ID assigned to the connection, which is used to acknowledge events when autoack is disabled")
+
+	// SSEAck field descriptions
+	SSEAckConnectionID = ffm("SSEAck.connectionId", "The ID of the server-sent events connection the event was received on")
+	SSEAckID           = ffm("SSEAck.id", "The ID of the event to acknowledge. If not set, the oldest event in flight on the connection is acknowledged")
+
 	WSConnectionStatusID            = ffm("WSConnectionStatus.id", "The unique ID assigned to this client connection")
 	WSConnectionStatusRemoteAddress = ffm("WSConnectionStatus.remoteAddress", "The remote address of the connected client (if available)")
 	WSConnectionStatusUserAgent     = ffm("WSConnectionStatus.userAgent", "The user agent of the connected client (if available)")
@@ -687,6 +790,24 @@ var (
 	WebhooksOptInputBody    = ffm("WebhookInputOptions.body", "A top-level property of the first data input, to use for the request body. Default is the whole first body")
 	WebhooksOptInputPath    = ffm("WebhookInputOptions.path", "A top-level property of the first data input, to use for a path to append with escaping to the webhook path")
 	WebhooksOptInputReplyTx = ffm("WebhookInputOptions.replytx", "A top-level property of the first data input, to use to dynamically set whether to pin the response (so the requester can choose)")
+	WebhooksOptSigning      = ffm("WebhookSubOptions.signing", "Webhooks only: Options to sign each request with an HMAC-SHA256 signature, so the receiver can verify it came from this node")
+	WebhooksOptTLS          = ffm("WebhookSubOptions.tls", "Webhooks only: TLS options for this subscription, such as a client certificate for mutual TLS, or a private CA to trust. Default is the TLS configuration of the webhooks plugin")
+
+	// WebhookSigning field descriptions
+	WebhookSigningSecret          = ffm("WebhookSigning.secret", "An inline HMAC secret. This is encrypted before the subscription is stored, and is never returned")
+	WebhookSigningSecretRef       = ffm("WebhookSigning.secretRef", "The name of a signing secret configured in the webhooks plugin, as an alternative to an inline secret")
+	WebhookSigningEncryptedSecret = ffm("WebhookSigning.encryptedSecret", "The encrypted form of an inline secret, as stored on the subscription. This is set by FireFly, and is rejected if submitted")
+
+	// WebhookTLS field descriptions
+	WebhookTLSConfigRef  = ffm("WebhookTLS.configRef", "The name of a TLS configuration of the webhooks plugin, holding a client certificate for mutual TLS and/or a CA bundle")
+	WebhookTLSCA         = ffm("WebhookTLS.ca", "An inline PEM encoded CA bundle, used instead of the system CAs to verify the webhook server. Cannot be used with a TLS configuration that has a CA file")
+	WebhookTLSServerName = ffm("WebhookTLS.serverName", "The server name to verify the certificate of the webhook server against, if it differs from the host in the URL")
+
+	// KafkaSubOptions field descriptions
+	KafkaOptKafka        = ffm("KafkaSubOptions.kafka", "Kafka only: Options for producing events to a Kafka topic")
+	KafkaOptTopic        = ffm("KafkaOptions.topic", "The Kafka topic to produce events to")
+	KafkaOptPartitionKey = ffm("KafkaOptions.partitionKey", "The event field to use as the key of each Kafka message, which determines the partition. One of: id, type, topic, reference, correlator, tx, cid, author, tag, group. Default is no key")
+	KafkaOptHeaders      = ffm("KafkaOptions.headers", "A map of Kafka header names, to the event field to set as the value of that header. Valid event fields are the same as for partitionKey")
 
 	// PublishInput field descriptions
 	PublishInputIdempotencyKey = ffm("PublishInput.idempotencyKey", "An optional identifier to allow idempotent submission of requests. Stored on the transaction uniquely within a namespace")
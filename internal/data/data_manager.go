@@ -169,7 +169,7 @@ func (dm *dataManager) getValidatorForDatatype(ctx context.Context, validator co
 		return cachedValue.(Validator), nil
 	}
 
-	datatype, err := dm.database.GetDatatypeByName(ctx, dm.namespace.Name, datatypeRef.Name, datatypeRef.Version)
+	datatype, err := dm.database.GetDatatypeByName(database.WithPrimary(ctx), dm.namespace.Name, datatypeRef.Name, datatypeRef.Version)
 	if err != nil {
 		return nil, err
 	}
@@ -193,6 +193,8 @@ func (dm *dataManager) GetMessageWithDataCached(ctx context.Context, msgID *ffty
 	if mce := dm.queryMessageCache(ctx, msgID, options...); mce != nil {
 		return mce.msg, mce.data, true, nil
 	}
+	// Whatever is read here is added to the cache for every caller, so it must not come from a lagging replica
+	ctx = database.WithPrimary(ctx)
 	msg, err = dm.database.GetMessageByID(ctx, dm.namespace.Name, msgID)
 	if err != nil || msg == nil {
 		return nil, nil, false, err
@@ -208,7 +210,7 @@ func (dm *dataManager) GetMessageDataCached(ctx context.Context, msg *core.Messa
 	if mce := dm.queryMessageCache(ctx, msg.Header.ID, options...); mce != nil {
 		return mce.data, true, nil
 	}
-	return dm.dataLookupAndCache(ctx, msg)
+	return dm.dataLookupAndCache(database.WithPrimary(ctx), msg)
 }
 
 // cachedMessageAndDataLookup is the common function that can lookup and cache a message with its data
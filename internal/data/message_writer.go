@@ -210,7 +210,7 @@ func (mw *messageWriter) persistMWBatch(batch *messageWriterBatch) {
 func (mw *messageWriter) checkIdempotencyDuplicate(ctx context.Context, m *core.Message) error {
 	if m.IdempotencyKey != "" {
 		fb := database.MessageQueryFactory.NewFilter(ctx)
-		existing, _, err := mw.database.GetMessages(ctx, m.Header.Namespace, fb.Eq("idempotencykey", (string)(m.IdempotencyKey)))
+		existing, _, err := mw.database.GetMessages(ctx, m.Header.Namespace, fb.Eq("idempotencykey", (string)(m.IdempotencyKey)), nil)
 		if err != nil {
 			// Don't overwrite the original error for this - return -1 to the caller, who will return the previous error
 			log.L(mw.ctx).Errorf("Failed checking for idempotency errors: %s", err)
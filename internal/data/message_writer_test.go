@@ -182,11 +182,11 @@ func TestPersistMWBatchIdempotencyAllFail(t *testing.T) {
 	mdi.On("GetMessages", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
 		ff, _ := f.Finalize()
 		return strings.Contains(ff.String(), "idem1")
-	})).Return([]*core.Message{m1}, nil, nil)
+	}), mock.Anything).Return([]*core.Message{m1}, nil, nil)
 	mdi.On("GetMessages", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
 		ff, _ := f.Finalize()
 		return strings.Contains(ff.String(), "idem2")
-	})).Return([]*core.Message{m2}, nil, nil)
+	}), mock.Anything).Return([]*core.Message{m2}, nil, nil)
 
 	mw.persistMWBatch(&messageWriterBatch{
 		messages: []*core.Message{m1, m2},
@@ -241,11 +241,11 @@ func TestPersistMWBatchHalfFailResubmit(t *testing.T) {
 	mdi.On("GetMessages", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
 		ff, _ := f.Finalize()
 		return strings.Contains(ff.String(), "idem1")
-	})).Return([]*core.Message{}, nil, nil) // no result
+	}), mock.Anything).Return([]*core.Message{}, nil, nil) // no result
 	mdi.On("GetMessages", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
 		ff, _ := f.Finalize()
 		return strings.Contains(ff.String(), "idem2")
-	})).Return([]*core.Message{m2}, nil, nil) // found result
+	}), mock.Anything).Return([]*core.Message{m2}, nil, nil) // found result
 	mdi.On("InsertDataArray", customCtx, m1d).Return(nil)
 	mdi.On("InsertMessages", customCtx, []*core.Message{m1}).Return(nil) // resubmit reduced batch
 
@@ -290,7 +290,7 @@ func TestPersistMWBatchFailIdemCheck(t *testing.T) {
 	mdi.On("GetMessages", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
 		ff, _ := f.Finalize()
 		return strings.Contains(ff.String(), "idem1")
-	})).Return([]*core.Message{}, nil, fmt.Errorf("failure1"))
+	}), mock.Anything).Return([]*core.Message{}, nil, fmt.Errorf("failure1"))
 
 	mw.persistMWBatch(&messageWriterBatch{
 		messages: []*core.Message{m1},
@@ -363,7 +363,7 @@ func TestWriteMessageNoWorkersIdempotencyDuplicate(t *testing.T) {
 	mdi.On("GetMessages", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
 		ff, _ := f.Finalize()
 		return strings.Contains(ff.String(), "idem1")
-	})).Return([]*core.Message{
+	}), mock.Anything).Return([]*core.Message{
 		{Header: core.MessageHeader{ID: fftypes.NewUUID()}, IdempotencyKey: "idem1"},
 	}, nil, nil)
 
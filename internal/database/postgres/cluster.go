@@ -0,0 +1,259 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+	"github.com/lib/pq"
+)
+
+const notifyBufferSize = 1000
+
+// cluster holds the state used when multiple FireFly processes share the same database
+type cluster struct {
+	ctx           context.Context
+	origin        string
+	channel       string
+	leaderRetry   time.Duration
+	sessionCheck  time.Duration
+	notifications chan *sqlcommon.ChangeEvent
+	sessionMux    sync.Mutex
+	session       *lockSession
+}
+
+// clusterNotification is the payload of each NOTIFY, tagged with the process that sent it
+type clusterNotification struct {
+	Origin string `json:"origin"`
+	*sqlcommon.ChangeEvent
+}
+
+type listener interface {
+	Listen(channel string) error
+	NotificationChannel() <-chan *pq.Notification
+	Close() error
+}
+
+var newListener = func(url string, reconnectInterval time.Duration, eventCallback pq.EventCallbackType) listener {
+	return pq.NewListener(url, reconnectInterval, reconnectInterval*10, eventCallback)
+}
+
+func (psql *Postgres) startCluster(ctx context.Context, url string, conf config.Section) error {
+	c := &cluster{
+		ctx:           ctx,
+		origin:        fftypes.NewUUID().String(),
+		channel:       conf.GetString(PSQLConfClusterChannel),
+		leaderRetry:   conf.GetDuration(PSQLConfClusterLeaderRetryInterval),
+		sessionCheck:  conf.GetDuration(PSQLConfClusterLeaderRetryInterval),
+		notifications: make(chan *sqlcommon.ChangeEvent, notifyBufferSize),
+	}
+	l := newListener(url, c.leaderRetry, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.L(ctx).Warnf("Change listener connection error: %s", err)
+		}
+	})
+	if err := l.Listen(c.channel); err != nil {
+		_ = l.Close()
+		return i18n.WrapError(ctx, err, i18n.MsgDBInitFailed)
+	}
+	log.L(ctx).Infof("Listening for changes from other processes on channel '%s' (origin=%s)", c.channel, c.origin)
+
+	psql.cluster = c
+	psql.SetChangeForwarder(c.forward)
+	go psql.notifyLoop()
+	go psql.listenLoop(l)
+	return nil
+}
+
+// forward is called inline by the database layer after each commit, so must not block
+func (c *cluster) forward(ce *sqlcommon.ChangeEvent) {
+	select {
+	case c.notifications <- ce:
+	default:
+		log.L(c.ctx).Warnf("Change notification buffer full - other processes will not be notified of %s %s in namespace '%s'", ce.Collection, ce.Type, ce.Namespace)
+	}
+}
+
+func (psql *Postgres) notifyLoop() {
+	c := psql.cluster
+	for {
+		select {
+		case <-c.ctx.Done():
+			log.L(c.ctx).Debugf("Change notifier exiting")
+			return
+		case ce := <-c.notifications:
+			payload, _ := json.Marshal(&clusterNotification{Origin: c.origin, ChangeEvent: ce})
+			if _, err := psql.DB().ExecContext(c.ctx, `SELECT pg_notify($1, $2)`, c.channel, string(payload)); err != nil {
+				log.L(c.ctx).Errorf("Failed to notify other processes of change: %s", err)
+			}
+		}
+	}
+}
+
+func (psql *Postgres) listenLoop(l listener) {
+	c := psql.cluster
+	defer func() { _ = l.Close() }()
+	for {
+		select {
+		case <-c.ctx.Done():
+			log.L(c.ctx).Debugf("Change listener exiting")
+			return
+		case n, ok := <-l.NotificationChannel():
+			if !ok {
+				log.L(c.ctx).Debugf("Change listener closed")
+				return
+			}
+			if n == nil {
+				// Sent by the listener after it re-establishes a lost connection
+				log.L(c.ctx).Warnf("Change listener reconnected - changes from other processes might have been missed")
+				continue
+			}
+			var cn clusterNotification
+			if err := json.Unmarshal([]byte(n.Extra), &cn); err != nil || cn.ChangeEvent == nil {
+				log.L(c.ctx).Warnf("Ignoring invalid change notification: %s", n.Extra)
+				continue
+			}
+			if cn.Origin != c.origin {
+				psql.DispatchChangeEvent(cn.ChangeEvent)
+			}
+		}
+	}
+}
+
+// leadershipKey maps a leadership name to a key for a session-level advisory lock
+func leadershipKey(name string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte("firefly:leader:" + name))
+	return int64(h.Sum64())
+}
+
+// lockSession is a single database session that holds every advisory lock taken by this process,
+// so each leadership does not take a connection out of the pool for as long as it is held
+type lockSession struct {
+	conn *sql.Conn
+	lost chan struct{}
+}
+
+func (psql *Postgres) AcquireLeadership(ctx context.Context, name string) (<-chan struct{}, error) {
+	c := psql.cluster
+	if c == nil {
+		return psql.SQLCommon.AcquireLeadership(ctx, name)
+	}
+	key := leadershipKey(name)
+	for attempt := 1; ; attempt++ {
+		s, err := psql.getLockSession(ctx)
+		if err == nil {
+			err = psql.tryLock(ctx, s, name, key)
+		}
+		if err == nil {
+			log.L(ctx).Infof("Acquired leadership of '%s'", name)
+			go psql.holdLeadership(ctx, s, name, key)
+			return s.lost, nil
+		}
+		log.L(ctx).Debugf("Attempt %d to acquire leadership of '%s' failed: %s", attempt, name, err)
+		select {
+		case <-ctx.Done():
+			return nil, i18n.NewError(ctx, coremsgs.MsgContextCanceled)
+		case <-time.After(c.leaderRetry):
+		}
+	}
+}
+
+// getLockSession returns the session holding the locks of this process, taking a connection out of
+// the pool for it if there is not one already
+func (psql *Postgres) getLockSession(ctx context.Context) (*lockSession, error) {
+	c := psql.cluster
+	c.sessionMux.Lock()
+	defer c.sessionMux.Unlock()
+	if c.session == nil {
+		conn, err := psql.DB().Conn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.session = &lockSession{conn: conn, lost: make(chan struct{})}
+		go psql.checkLockSession(c.session)
+	}
+	return c.session, nil
+}
+
+// checkLockSession pings the session on an interval. All locks belong to the session, so if it fails
+// every leadership held by this process is lost.
+func (psql *Postgres) checkLockSession(s *lockSession) {
+	c := psql.cluster
+	// Closing the session (rather than returning the connection to the pool) releases all locks
+	defer discardConn(s.conn)
+	ticker := time.NewTicker(c.sessionCheck)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.conn.PingContext(c.ctx); err != nil && c.ctx.Err() == nil {
+				log.L(c.ctx).Errorf("Lost the database session holding leadership: %s", err)
+				c.sessionMux.Lock()
+				c.session = nil
+				c.sessionMux.Unlock()
+				close(s.lost)
+				return
+			}
+		}
+	}
+}
+
+func (psql *Postgres) tryLock(ctx context.Context, s *lockSession, name string, key int64) error {
+	var acquired bool
+	err := s.conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&acquired)
+	if err == nil && !acquired {
+		err = i18n.NewError(ctx, coremsgs.MsgLeadershipHeldElsewhere, name)
+	}
+	return err
+}
+
+// holdLeadership releases the lock when the context of the leader closes, unless the session is lost first
+func (psql *Postgres) holdLeadership(ctx context.Context, s *lockSession, name string, key int64) {
+	c := psql.cluster
+	select {
+	case <-s.lost:
+	case <-c.ctx.Done():
+	case <-ctx.Done():
+		if _, err := s.conn.ExecContext(c.ctx, `SELECT pg_advisory_unlock($1)`, key); err != nil {
+			log.L(c.ctx).Warnf("Failed to release leadership of '%s': %s", name, err)
+			return
+		}
+		log.L(ctx).Infof("Released leadership of '%s'", name)
+	}
+}
+
+func discardConn(conn *sql.Conn) {
+	_ = conn.Raw(func(driverConn interface{}) error {
+		return driver.ErrBadConn
+	})
+	_ = conn.Close()
+}
@@ -0,0 +1,309 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/stub"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type fakeListener struct {
+	listenErr     error
+	notifications chan *pq.Notification
+	closed        chan struct{}
+}
+
+func (fl *fakeListener) Listen(channel string) error {
+	return fl.listenErr
+}
+
+func (fl *fakeListener) NotificationChannel() <-chan *pq.Notification {
+	return fl.notifications
+}
+
+func (fl *fakeListener) Close() error {
+	close(fl.closed)
+	return nil
+}
+
+type mockDBProvider struct {
+	*Postgres
+	db *sql.DB
+}
+
+func (mp *mockDBProvider) Open(url string) (*sql.DB, error) {
+	return mp.db, nil
+}
+
+func (mp *mockDBProvider) GetMigrationDriver(db *sql.DB) (migratedb.Driver, error) {
+	return stub.WithInstance(nil, &stub.Config{})
+}
+
+func newFakeListener(t *testing.T) *fakeListener {
+	fl := &fakeListener{
+		notifications: make(chan *pq.Notification),
+		closed:        make(chan struct{}),
+	}
+	origListener := newListener
+	newListener = func(url string, reconnectInterval time.Duration, eventCallback pq.EventCallbackType) listener {
+		eventCallback(pq.ListenerEventConnectionAttemptFailed, fmt.Errorf("pop"))
+		return fl
+	}
+	t.Cleanup(func() { newListener = origListener })
+	return fl
+}
+
+func newClusterConfig(t *testing.T, url string) config.Section {
+	t.Cleanup(func() { config.RootConfigReset() })
+	conf := config.RootSection("unittest")
+	(&Postgres{}).InitConfig(conf)
+	conf.Set(sqlcommon.SQLConfDatasourceURL, url)
+	clusterConf := conf.SubSection(PSQLConfCluster)
+	clusterConf.Set(PSQLConfClusterEnabled, true)
+	clusterConf.Set(PSQLConfClusterLeaderRetryInterval, "1ms")
+	return conf
+}
+
+func newMockClusterPostgres(t *testing.T) (context.Context, context.CancelFunc, *Postgres, sqlmock.Sqlmock, *fakeListener) {
+	fl := newFakeListener(t)
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	psql := &Postgres{}
+	conf := newClusterConfig(t, "mock")
+	err = psql.SQLCommon.Init(ctx, &mockDBProvider{Postgres: psql, db: db}, conf, &database.Capabilities{Clustering: true})
+	assert.NoError(t, err)
+	err = psql.startCluster(ctx, "mock", conf.SubSection(PSQLConfCluster))
+	assert.NoError(t, err)
+	return ctx, cancel, psql, mock, fl
+}
+
+func TestInitClusterEnabled(t *testing.T) {
+	fl := newFakeListener(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	psql := &Postgres{}
+	err := psql.Init(ctx, newClusterConfig(t, "!bad connection"))
+	assert.NoError(t, err)
+	assert.True(t, psql.Capabilities().Clustering)
+	assert.Equal(t, "firefly_changes", psql.cluster.channel)
+	cancel()
+	<-fl.closed
+}
+
+func TestInitClusterListenFail(t *testing.T) {
+	fl := newFakeListener(t)
+	fl.listenErr = fmt.Errorf("pop")
+	psql := &Postgres{}
+	err := psql.Init(context.Background(), newClusterConfig(t, "!bad connection"))
+	assert.Regexp(t, "FF00173.*pop", err)
+	<-fl.closed
+}
+
+func TestInitClusterBadConfig(t *testing.T) {
+	conf := newClusterConfig(t, "")
+	psql := &Postgres{}
+	err := psql.Init(context.Background(), conf)
+	assert.Regexp(t, "FF00183", err)
+}
+
+func TestClusterNotify(t *testing.T) {
+	_, cancel, psql, mock, fl := newMockClusterPostgres(t)
+	defer cancel()
+
+	id := fftypes.NewUUID()
+	mock.ExpectExec("SELECT pg_notify").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectExec("SELECT pg_notify").
+		WithArgs("firefly_changes", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	psql.cluster.forward(&sqlcommon.ChangeEvent{Kind: sqlcommon.ChangeKindUUID, Collection: "operations", Type: core.ChangeEventTypeCreated, Namespace: "ns1", ID: id})
+	psql.cluster.forward(&sqlcommon.ChangeEvent{Kind: sqlcommon.ChangeKindUUID, Collection: "operations", Type: core.ChangeEventTypeUpdated, Namespace: "ns1", ID: id})
+	for mock.ExpectationsWereMet() != nil {
+		time.Sleep(1 * time.Millisecond)
+	}
+
+	cancel()
+	<-fl.closed
+}
+
+func TestClusterNotifyBufferFull(t *testing.T) {
+	c := &cluster{ctx: context.Background()}
+	c.forward(&sqlcommon.ChangeEvent{Kind: sqlcommon.ChangeKindUUID, Collection: "operations", Type: core.ChangeEventTypeCreated, Namespace: "ns1"})
+}
+
+func TestClusterListen(t *testing.T) {
+	_, cancel, psql, _, fl := newMockClusterPostgres(t)
+	defer cancel()
+
+	mcb := &databasemocks.Callbacks{}
+	psql.SetHandler("ns1", mcb)
+	id := fftypes.NewUUID()
+	received := make(chan struct{})
+	mcb.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, core.ChangeEventTypeCreated, "ns1", id, int64(12345)).
+		Run(func(args mock.Arguments) { close(received) }).
+		Return()
+
+	notification := func(origin string) *pq.Notification {
+		b, _ := json.Marshal(&clusterNotification{
+			Origin: origin,
+			ChangeEvent: &sqlcommon.ChangeEvent{
+				Kind:       sqlcommon.ChangeKindOrderedUUID,
+				Collection: "messages",
+				Type:       core.ChangeEventTypeCreated,
+				Namespace:  "ns1",
+				ID:         id,
+				Sequence:   12345,
+			},
+		})
+		return &pq.Notification{Channel: "firefly_changes", Extra: string(b)}
+	}
+
+	fl.notifications <- nil
+	fl.notifications <- &pq.Notification{Extra: "!json"}
+	fl.notifications <- &pq.Notification{Extra: "{}"}
+	fl.notifications <- notification(psql.cluster.origin)
+	fl.notifications <- notification(fftypes.NewUUID().String())
+	<-received
+
+	close(fl.notifications)
+	<-fl.closed
+	mcb.AssertExpectations(t)
+}
+
+func TestAcquireLeadershipRetry(t *testing.T) {
+	ctx, cancel, psql, mock, _ := newMockClusterPostgres(t)
+	defer cancel()
+	psql.cluster.sessionCheck = 1 * time.Hour
+
+	key := leadershipKey("namespace:ns1")
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").WithArgs(key).WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").WithArgs(key).WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(false))
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").WithArgs(key).WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(true))
+
+	_, err := psql.AcquireLeadership(ctx, "namespace:ns1")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLockSessionLost(t *testing.T) {
+	ctx, cancel, psql, mock, _ := newMockClusterPostgres(t)
+	defer cancel()
+
+	mock.ExpectPing()
+	mock.ExpectPing().WillReturnError(fmt.Errorf("pop"))
+
+	s, err := psql.getLockSession(ctx)
+	assert.NoError(t, err)
+	<-s.lost
+	psql.cluster.sessionMux.Lock()
+	assert.Nil(t, psql.cluster.session)
+	psql.cluster.sessionMux.Unlock()
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAcquireLeadershipReleasedOnCancel(t *testing.T) {
+	_, cancel, psql, mock, _ := newMockClusterPostgres(t)
+	defer cancel()
+	psql.cluster.sessionCheck = 1 * time.Hour
+
+	key1 := leadershipKey("namespace:ns1")
+	key2 := leadershipKey("namespace:ns2")
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").WithArgs(key1).WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(true))
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").WithArgs(key2).WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(true))
+	mock.ExpectExec("SELECT pg_advisory_unlock").WithArgs(key1).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SELECT pg_advisory_unlock").WithArgs(key2).WillReturnError(fmt.Errorf("pop"))
+
+	// Both leaderships share the same session
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	lost1, err := psql.AcquireLeadership(ctx1, "namespace:ns1")
+	assert.NoError(t, err)
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	lost2, err := psql.AcquireLeadership(ctx2, "namespace:ns2")
+	assert.NoError(t, err)
+	assert.Equal(t, lost1, lost2)
+
+	mock.MatchExpectationsInOrder(false)
+	cancel1()
+	cancel2()
+	for mock.ExpectationsWereMet() != nil {
+		time.Sleep(1 * time.Millisecond)
+	}
+	select {
+	case <-lost1:
+		assert.Fail(t, "leadership should not be reported lost on release")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestAcquireLeadershipShutdown(t *testing.T) {
+	ctx, cancel, psql, mock, _ := newMockClusterPostgres(t)
+	psql.cluster.sessionCheck = 1 * time.Hour
+
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(true))
+
+	lost, err := psql.AcquireLeadership(ctx, "namespace:ns1")
+	assert.NoError(t, err)
+	cancel()
+	select {
+	case <-lost:
+		assert.Fail(t, "leadership should not be reported lost on shutdown")
+	case <-time.After(10 * time.Millisecond):
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAcquireLeadershipSessionFail(t *testing.T) {
+	ctx, cancel, psql, _, _ := newMockClusterPostgres(t)
+	psql.DB().Close()
+	cancel()
+	_, err := psql.AcquireLeadership(ctx, "namespace:ns1")
+	assert.Regexp(t, "FF00154", err)
+}
+
+func TestAcquireLeadershipCancelled(t *testing.T) {
+	ctx, cancel, psql, _, _ := newMockClusterPostgres(t)
+	cancel()
+	_, err := psql.AcquireLeadership(ctx, "namespace:ns1")
+	assert.Regexp(t, "FF00154", err)
+}
+
+func TestLeadershipKey(t *testing.T) {
+	assert.Equal(t, leadershipKey("namespace:ns1"), leadershipKey("namespace:ns1"))
+	assert.NotEqual(t, leadershipKey("namespace:ns1"), leadershipKey("namespace:ns2"))
+}
+
+func TestDefaultListener(t *testing.T) {
+	l := newListener("!bad connection", 1*time.Millisecond, func(event pq.ListenerEventType, err error) {})
+	assert.NoError(t, l.Close())
+}
@@ -23,9 +23,61 @@ import (
 
 const (
 	defaultConnectionLimitPostgreSQL = 50
+	defaultClusterChannel            = "firefly_changes"
+	defaultClusterLeaderRetry        = "5s"
+	defaultPartitioningStrategy      = partitionBySequence
+	defaultPartitioningSequenceRange = 10000000
+	defaultPartitioningTimeRange     = "168h"
+	defaultPartitioningPremake       = 4
+	defaultPartitioningCheckInterval = "1h"
+)
+
+const (
+	// PSQLConfCluster is the sub-section for running multiple processes against one database
+	PSQLConfCluster = "cluster"
+	// PSQLConfClusterEnabled enables leader election and shared change notifications
+	PSQLConfClusterEnabled = "enabled"
+	// PSQLConfClusterChannel is the LISTEN/NOTIFY channel for change notifications
+	PSQLConfClusterChannel = "channel"
+	// PSQLConfClusterLeaderRetryInterval is how often to attempt to become leader, and to check the leader connection
+	PSQLConfClusterLeaderRetryInterval = "leaderRetryInterval"
+	// PSQLConfPartitioning is the sub-section for range partitioning the events, blockchainevents and operations tables
+	PSQLConfPartitioning = "partitioning"
+	// PSQLConfPartitioningEnabled converts the tables to partitioned tables on migration, and creates future partitions
+	PSQLConfPartitioningEnabled = "enabled"
+	// PSQLConfPartitioningStrategy is whether the tables are partitioned by 'sequence' or 'time'
+	PSQLConfPartitioningStrategy = "strategy"
+	// PSQLConfPartitioningSequenceRange is the number of sequences in each partition, for the sequence strategy
+	PSQLConfPartitioningSequenceRange = "sequenceRange"
+	// PSQLConfPartitioningTimeRange is the length of time covered by each partition, for the time strategy
+	PSQLConfPartitioningTimeRange = "timeRange"
+	// PSQLConfPartitioningPremake is the number of partitions to create ahead of the current one
+	PSQLConfPartitioningPremake = "premake"
+	// PSQLConfPartitioningCheckInterval is how often to create future partitions, and drop expired ones
+	PSQLConfPartitioningCheckInterval = "checkInterval"
+	// PSQLConfPartitioningRetention is the age after which a whole partition is dropped, for the time strategy
+	PSQLConfPartitioningRetention = "retention"
 )
 
 func (psql *Postgres) InitConfig(config config.Section) {
 	psql.SQLCommon.InitConfig(psql, config)
 	config.SetDefault(sqlcommon.SQLConfMaxConnections, defaultConnectionLimitPostgreSQL)
+
+	replicaConf := config.SubSection(sqlcommon.SQLConfReadReplica)
+	psql.SQLCommon.InitReadReplicaConfig(replicaConf)
+	replicaConf.SetDefault(sqlcommon.SQLConfMaxConnections, defaultConnectionLimitPostgreSQL)
+
+	clusterConf := config.SubSection(PSQLConfCluster)
+	clusterConf.AddKnownKey(PSQLConfClusterEnabled, false)
+	clusterConf.AddKnownKey(PSQLConfClusterChannel, defaultClusterChannel)
+	clusterConf.AddKnownKey(PSQLConfClusterLeaderRetryInterval, defaultClusterLeaderRetry)
+
+	partitionConf := config.SubSection(PSQLConfPartitioning)
+	partitionConf.AddKnownKey(PSQLConfPartitioningEnabled, false)
+	partitionConf.AddKnownKey(PSQLConfPartitioningStrategy, defaultPartitioningStrategy)
+	partitionConf.AddKnownKey(PSQLConfPartitioningSequenceRange, defaultPartitioningSequenceRange)
+	partitionConf.AddKnownKey(PSQLConfPartitioningTimeRange, defaultPartitioningTimeRange)
+	partitionConf.AddKnownKey(PSQLConfPartitioningPremake, defaultPartitioningPremake)
+	partitionConf.AddKnownKey(PSQLConfPartitioningCheckInterval, defaultPartitioningCheckInterval)
+	partitionConf.AddKnownKey(PSQLConfPartitioningRetention)
 }
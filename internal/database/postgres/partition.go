@@ -0,0 +1,437 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+const (
+	partitionBySequence = "sequence"
+	partitionByTime     = "time"
+)
+
+// partitionedTable is a table that can be range partitioned, along with the column that holds the time of each row
+type partitionedTable struct {
+	name       string
+	timeColumn string
+}
+
+var partitionedTables = []partitionedTable{
+	{name: "events", timeColumn: "created"},
+	{name: "blockchainevents", timeColumn: "timestamp"},
+	{name: "operations", timeColumn: "created"},
+}
+
+// partitionBound matches the bound of a range partition, as returned by pg_get_expr
+var partitionBound = regexp.MustCompile(`FROM \((MINVALUE|'?-?\d+'?)\) TO \('?(-?\d+)'?\)`)
+
+// uniqueIndexColumns matches the definition of a unique index, as returned by pg_indexes, capturing its columns
+var uniqueIndexColumns = regexp.MustCompile(`^(CREATE UNIQUE INDEX .+ USING \w+ \()([^()]+)(\).*)$`)
+
+var timeNow = time.Now
+
+// partitioner holds the configuration used to convert and maintain the partitioned tables
+type partitioner struct {
+	ctx           context.Context
+	strategy      string
+	rangeSize     int64
+	premake       int64
+	checkInterval time.Duration
+	retention     time.Duration
+	tables        []partitionedTable // the tables that have been converted, and so are maintained
+}
+
+type tableIndex struct {
+	name string
+	def  string
+}
+
+type partition struct {
+	name  string
+	lower *int64 // nil for MINVALUE
+	upper int64
+}
+
+func newPartitioner(ctx context.Context, conf config.Section) (*partitioner, error) {
+	p := &partitioner{
+		ctx:           ctx,
+		strategy:      conf.GetString(PSQLConfPartitioningStrategy),
+		premake:       conf.GetInt64(PSQLConfPartitioningPremake),
+		checkInterval: conf.GetDuration(PSQLConfPartitioningCheckInterval),
+		retention:     conf.GetDuration(PSQLConfPartitioningRetention),
+	}
+	switch p.strategy {
+	case partitionBySequence:
+		p.rangeSize = conf.GetInt64(PSQLConfPartitioningSequenceRange)
+	case partitionByTime:
+		p.rangeSize = conf.GetDuration(PSQLConfPartitioningTimeRange).Nanoseconds()
+	default:
+		return nil, i18n.NewError(ctx, coremsgs.MsgInvalidPartitionStrategy, p.strategy)
+	}
+	if p.rangeSize <= 0 {
+		return nil, i18n.NewError(ctx, coremsgs.MsgInvalidPartitionRange)
+	}
+	return p, nil
+}
+
+// startPartitioning maintains the partitions of the tables that have already been converted. Converting a table
+// is an offline step, performed by MigrateUp, so a table that has not been converted is left as it is.
+func (psql *Postgres) startPartitioning(ctx context.Context, conf config.Section) error {
+	p, err := newPartitioner(ctx, conf)
+	if err != nil {
+		return err
+	}
+	psql.partitioner = p
+
+	for _, t := range partitionedTables {
+		// The table does not exist yet if migrations have not been applied
+		var partitioned bool
+		if err := psql.DB().QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM pg_class WHERE oid = to_regclass($1) AND relkind = 'p')`, t.name).Scan(&partitioned); err != nil {
+			return i18n.WrapError(ctx, err, i18n.MsgDBQueryFailed)
+		}
+		if !partitioned {
+			log.L(ctx).Warnf("Table '%s' is not partitioned - stop FireFly and run 'firefly db up' to convert it", t.name)
+			continue
+		}
+		if err := psql.maintainPartitions(ctx, t); err != nil {
+			return err
+		}
+		psql.addPartitionedTable(t)
+	}
+	go psql.partitionLoop()
+	return nil
+}
+
+// partitionTables converts each table to a partitioned table, if it is not one already, and creates its partitions
+func (psql *Postgres) partitionTables(ctx context.Context) error {
+	for _, t := range partitionedTables {
+		if err := psql.partitionTable(ctx, t); err != nil {
+			return err
+		}
+		if err := psql.maintainPartitions(ctx, t); err != nil {
+			return err
+		}
+		psql.addPartitionedTable(t)
+	}
+	return nil
+}
+
+func (psql *Postgres) addPartitionedTable(t partitionedTable) {
+	p := psql.partitioner
+	for _, existing := range p.tables {
+		if existing.name == t.name {
+			return
+		}
+	}
+	p.tables = append(p.tables, t)
+	tables := make([]string, len(p.tables))
+	for i, t := range p.tables {
+		tables[i] = t.name
+	}
+	psql.SetPartitionedTables(tables...)
+}
+
+func (psql *Postgres) partitionLoop() {
+	p := psql.partitioner
+	for {
+		select {
+		case <-p.ctx.Done():
+			log.L(p.ctx).Debugf("Partition maintenance exiting")
+			return
+		case <-time.After(p.checkInterval):
+			for _, t := range p.tables {
+				if err := psql.maintainPartitions(p.ctx, t); err != nil {
+					log.L(p.ctx).Errorf("Failed to maintain the partitions of table '%s': %s", t.name, err)
+				}
+			}
+		}
+	}
+}
+
+func (p *partitioner) keyColumn(t partitionedTable) string {
+	if p.strategy == partitionByTime {
+		return t.timeColumn
+	}
+	return "seq"
+}
+
+// boundaryAfter returns the first partition boundary that is greater than the given value
+func (p *partitioner) boundaryAfter(value int64) int64 {
+	return (value/p.rangeSize + 1) * p.rangeSize
+}
+
+func (p *partitioner) partitionName(table string, lower int64) string {
+	if p.strategy == partitionByTime {
+		return fmt.Sprintf("%s_p%s", table, time.Unix(0, lower).UTC().Format("20060102150405"))
+	}
+	return fmt.Sprintf("%s_p%d", table, lower)
+}
+
+// partitionTx runs DDL against a table in a transaction, holding a lock so that only one process changes its partitions at a time
+func (psql *Postgres) partitionTx(ctx context.Context, table string, fn func(tx *sql.Tx) error) error {
+	tx, err := psql.DB().BeginTx(ctx, nil)
+	if err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgDBBeginFailed)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.ExecContext(ctx, psql.Features().AcquireLock(table+":partitions")); err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgDBLockFailed)
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgDBCommitFailed)
+	}
+	return nil
+}
+
+func execDDL(ctx context.Context, tx *sql.Tx, statements []string) error {
+	for _, statement := range statements {
+		log.L(ctx).Debugf("SQL-> %s", statement)
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			log.L(ctx).Errorf("SQL failed: %s sql=[ %s ]", err, statement)
+			return i18n.WrapError(ctx, err, i18n.MsgDBMigrationFailed)
+		}
+	}
+	return nil
+}
+
+// partitionTable converts a table to a partitioned table, if it is not one already. The existing table becomes the
+// first partition, holding every row up to the next partition boundary, so no rows are copied. A unique index on a
+// partitioned table must include the partition key, so it is added to the columns of each unique index.
+func (psql *Postgres) partitionTable(ctx context.Context, t partitionedTable) error {
+	p := psql.partitioner
+	key := p.keyColumn(t)
+	return psql.partitionTx(ctx, t.name, func(tx *sql.Tx) error {
+		var relkind string
+		if err := tx.QueryRowContext(ctx, `SELECT relkind FROM pg_class WHERE oid = to_regclass($1)`, t.name).Scan(&relkind); err != nil {
+			return i18n.WrapError(ctx, err, i18n.MsgDBQueryFailed)
+		}
+		if relkind == "p" {
+			return nil
+		}
+
+		var sequence string
+		var maxKey int64
+		if err := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT pg_get_serial_sequence($1, 'seq'), COALESCE(MAX(%s), 0) FROM %s`, key, t.name), t.name).Scan(&sequence, &maxKey); err != nil {
+			return i18n.WrapError(ctx, err, i18n.MsgDBQueryFailed)
+		}
+		indexes, err := tableIndexes(ctx, tx, t.name)
+		if err != nil {
+			return err
+		}
+
+		upper := p.boundaryAfter(maxKey)
+		primaryKey := "seq"
+		if p.strategy == partitionByTime {
+			// The partition key must be part of the primary key, and the first partition must hold rows written up to now
+			primaryKey = fmt.Sprintf("seq, %s", key)
+			if now := timeNow().UnixNano(); now > maxKey {
+				upper = p.boundaryAfter(now)
+			}
+		}
+		legacy := t.name + "_legacy"
+		log.L(ctx).Infof("Partitioning table '%s' by %s - existing rows are kept in partition '%s'", t.name, key, legacy)
+
+		statements := []string{
+			fmt.Sprintf(`ALTER TABLE %s RENAME TO %s`, t.name, legacy),
+			fmt.Sprintf(`ALTER TABLE %s RENAME CONSTRAINT %s_pkey TO %s_pkey`, legacy, t.name, legacy),
+		}
+		for _, idx := range indexes {
+			statements = append(statements, fmt.Sprintf(`ALTER INDEX %s RENAME TO %s_legacy`, idx.name, idx.name))
+		}
+		statements = append(statements,
+			fmt.Sprintf(`CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS) PARTITION BY RANGE (%s)`, t.name, legacy, key),
+			fmt.Sprintf(`ALTER TABLE %s ADD PRIMARY KEY (%s)`, t.name, primaryKey),
+		)
+		for _, idx := range indexes {
+			def, err := partitionedIndex(ctx, t.name, idx, key)
+			if err != nil {
+				return err
+			}
+			statements = append(statements, def)
+		}
+		statements = append(statements,
+			fmt.Sprintf(`ALTER SEQUENCE %s OWNED BY %s.seq`, sequence, t.name),
+			fmt.Sprintf(`ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM (MINVALUE) TO (%d)`, t.name, legacy, upper),
+			fmt.Sprintf(`CREATE TABLE %s_default PARTITION OF %s DEFAULT`, t.name, t.name),
+		)
+		return execDDL(ctx, tx, statements)
+	})
+}
+
+// partitionedIndex returns the definition of an index on the partitioned table, adding the partition key to the
+// columns of a unique index if it is not already one of them. The index then only enforces uniqueness within
+// each value of the partition key.
+func partitionedIndex(ctx context.Context, table string, idx *tableIndex, key string) (string, error) {
+	if !strings.HasPrefix(idx.def, "CREATE UNIQUE INDEX") {
+		return idx.def, nil
+	}
+	match := uniqueIndexColumns.FindStringSubmatch(idx.def)
+	if match == nil {
+		return "", i18n.NewError(ctx, coremsgs.MsgPartitionUniqueIndex, table, idx.name)
+	}
+	for _, column := range strings.Split(match[2], ",") {
+		if strings.TrimSpace(column) == key {
+			return idx.def, nil
+		}
+	}
+	return fmt.Sprintf("%s%s, %s%s", match[1], match[2], key, match[3]), nil
+}
+
+// tableIndexes returns the definitions of the indexes of a table, other than its primary key
+func tableIndexes(ctx context.Context, tx *sql.Tx, table string) ([]*tableIndex, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT indexname, indexdef FROM pg_indexes WHERE schemaname = current_schema() AND tablename = $1 ORDER BY indexname`, table)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBQueryFailed)
+	}
+	defer rows.Close()
+	indexes := []*tableIndex{}
+	for rows.Next() {
+		var idx tableIndex
+		if err := rows.Scan(&idx.name, &idx.def); err != nil {
+			return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "pg_indexes")
+		}
+		if idx.name != table+"_pkey" {
+			indexes = append(indexes, &idx)
+		}
+	}
+	return indexes, nil
+}
+
+// tablePartitions returns the range partitions of a table, ignoring the default partition
+func tablePartitions(ctx context.Context, tx *sql.Tx, table string) ([]*partition, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT c.relname, pg_get_expr(c.relpartbound, c.oid) FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid WHERE i.inhparent = to_regclass($1)`, table)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBQueryFailed)
+	}
+	defer rows.Close()
+	partitions := []*partition{}
+	for rows.Next() {
+		var name, bound string
+		if err := rows.Scan(&name, &bound); err != nil {
+			return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "pg_inherits")
+		}
+		match := partitionBound.FindStringSubmatch(bound)
+		if match == nil {
+			continue
+		}
+		part := &partition{name: name}
+		if match[1] != "MINVALUE" {
+			lower, _ := strconv.ParseInt(strings.Trim(match[1], "'"), 10, 64)
+			part.lower = &lower
+		}
+		part.upper, _ = strconv.ParseInt(match[2], 10, 64)
+		partitions = append(partitions, part)
+	}
+	return partitions, nil
+}
+
+// maintainPartitions creates partitions ahead of the rows being written, so that new rows do not fall into the
+// default partition. When partitioning by time, it also drops the partitions that only hold expired rows, unless
+// some of those rows are still needed by the node.
+func (psql *Postgres) maintainPartitions(ctx context.Context, t partitionedTable) error {
+	p := psql.partitioner
+	return psql.partitionTx(ctx, t.name, func(tx *sql.Tx) error {
+		partitions, err := tablePartitions(ctx, tx, t.name)
+		if err != nil {
+			return err
+		}
+
+		var current int64
+		if p.strategy == partitionByTime {
+			current = timeNow().UnixNano()
+		} else if err := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT COALESCE(MAX(seq), 0) FROM %s`, t.name)).Scan(&current); err != nil {
+			return i18n.WrapError(ctx, err, i18n.MsgDBQueryFailed)
+		}
+
+		upper := current + 1
+		for _, part := range partitions {
+			if part.upper > upper {
+				upper = part.upper
+			}
+		}
+		if upper == current+1 {
+			// Rows beyond the last partition went to the default partition, so a new partition cannot include them
+			log.L(ctx).Warnf("Rows of table '%s' up to %d might have been written to its default partition", t.name, current)
+		}
+
+		statements := []string{}
+		for target := current + p.premake*p.rangeSize; upper <= target; {
+			next := p.boundaryAfter(upper)
+			statements = append(statements, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM (%d) TO (%d)`,
+				p.partitionName(t.name, upper), t.name, upper, next))
+			upper = next
+		}
+		if p.strategy == partitionByTime && p.retention > 0 {
+			cutoff := current - p.retention.Nanoseconds()
+			var g *database.RetentionGuards
+			for _, part := range partitions {
+				if part.upper > cutoff {
+					continue
+				}
+				if g == nil {
+					// These are the same guards the retention policy of a namespace applies, across all namespaces
+					if g, err = psql.GetRetentionGuards(ctx, ""); err != nil {
+						return err
+					}
+				}
+				needed, err := psql.partitionNeeded(ctx, tx, t, part, g)
+				if err != nil {
+					return err
+				}
+				if needed {
+					log.L(ctx).Infof("Keeping expired partition '%s' of table '%s', as some of its rows are still needed", part.name, t.name)
+					continue
+				}
+				log.L(ctx).Infof("Dropping partition '%s' of table '%s', as all its rows are older than %s", part.name, t.name, p.retention)
+				statements = append(statements, fmt.Sprintf(`DROP TABLE %s`, part.name))
+			}
+		}
+		return execDDL(ctx, tx, statements)
+	})
+}
+
+// partitionNeeded checks whether an expired partition holds any rows that are still needed by the node
+func (psql *Postgres) partitionNeeded(ctx context.Context, tx *sql.Tx, t partitionedTable, part *partition, g *database.RetentionGuards) (bool, error) {
+	var needed bool
+	err := sq.Select().
+		Column(sq.Expr("EXISTS (?)", sq.Select("1").From(part.name).Where(psql.RetentionNeeded(t.name, "", g)))).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(tx).
+		QueryRowContext(ctx).
+		Scan(&needed)
+	if err != nil {
+		return false, i18n.WrapError(ctx, err, i18n.MsgDBQueryFailed)
+	}
+	return needed, nil
+}
@@ -0,0 +1,537 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTime = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func newPartitionConfig(t *testing.T, strategy string) config.Section {
+	t.Cleanup(func() { config.RootConfigReset() })
+	conf := config.RootSection("unittest")
+	(&Postgres{}).InitConfig(conf)
+	partitionConf := conf.SubSection(PSQLConfPartitioning)
+	partitionConf.Set(PSQLConfPartitioningEnabled, true)
+	partitionConf.Set(PSQLConfPartitioningStrategy, strategy)
+	partitionConf.Set(PSQLConfPartitioningSequenceRange, 1000)
+	partitionConf.Set(PSQLConfPartitioningTimeRange, "24h")
+	partitionConf.Set(PSQLConfPartitioningPremake, 2)
+	partitionConf.Set(PSQLConfPartitioningCheckInterval, "1ms")
+	return conf
+}
+
+func newMockPartitionPostgres(t *testing.T, strategy string) (context.Context, context.CancelFunc, *Postgres, sqlmock.Sqlmock, config.Section) {
+	origNow := timeNow
+	timeNow = func() time.Time { return testTime }
+	t.Cleanup(func() { timeNow = origNow })
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	psql := &Postgres{}
+	conf := newPartitionConfig(t, strategy)
+	conf.Set(sqlcommon.SQLConfDatasourceURL, "mock")
+	migrationsDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(migrationsDir, "000001_create_widgets.up.sql"), []byte("CREATE TABLE widgets (seq INTEGER);"), 0644))
+	conf.Set(sqlcommon.SQLConfMigrationsDirectory, migrationsDir)
+	err = psql.SQLCommon.Init(ctx, &mockDBProvider{Postgres: psql, db: db}, conf, &database.Capabilities{})
+	assert.NoError(t, err)
+	return ctx, cancel, psql, mock, conf.SubSection(PSQLConfPartitioning)
+}
+
+func expectSQL(mock sqlmock.Sqlmock, sql string) *sqlmock.ExpectedExec {
+	return mock.ExpectExec(regexp.QuoteMeta(sql))
+}
+
+func expectPartitioned(mock sqlmock.Sqlmock, table string) {
+	mock.ExpectBegin()
+	mock.ExpectExec("pg_advisory_xact_lock").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectQuery("SELECT relkind").WithArgs(table).WillReturnRows(sqlmock.NewRows([]string{"relkind"}).AddRow("p"))
+	mock.ExpectCommit()
+}
+
+func expectPartitions(mock sqlmock.Sqlmock, table string, rows *sqlmock.Rows) {
+	mock.ExpectBegin()
+	mock.ExpectExec("pg_advisory_xact_lock").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectQuery("FROM pg_inherits").WithArgs(table).WillReturnRows(rows)
+}
+
+func expectIsPartitioned(mock sqlmock.Sqlmock, table string, partitioned bool) {
+	mock.ExpectQuery("SELECT EXISTS").WithArgs(table).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(partitioned))
+}
+
+func expectGuards(mock sqlmock.Sqlmock, eventSequence int64) {
+	mock.ExpectQuery("FROM subscriptions").WithArgs(int64(math.MaxInt64), core.OffsetTypeSubscription).
+		WillReturnRows(sqlmock.NewRows([]string{"min"}).AddRow(eventSequence))
+	mock.ExpectQuery("FROM offsets").WithArgs("ff_aggregator", core.OffsetTypeAggregator).
+		WillReturnRows(sqlmock.NewRows([]string{"otype", "name", "current", "seq"}))
+}
+
+func expectNeeded(mock sqlmock.Sqlmock, part string, needed bool) *sqlmock.ExpectedQuery {
+	return mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE", part))).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(needed))
+}
+
+func TestStartPartitioningSequence(t *testing.T) {
+	ctx, cancel, psql, mock, conf := newMockPartitionPostgres(t, partitionBySequence)
+
+	// events has not been converted, so is left alone
+	expectIsPartitioned(mock, "events", false)
+
+	// blockchainevents has partitions ahead
+	expectIsPartitioned(mock, "blockchainevents", true)
+	expectPartitions(mock, "blockchainevents", sqlmock.NewRows([]string{"relname", "bound"}).
+		AddRow("blockchainevents_legacy", "FOR VALUES FROM (MINVALUE) TO ('1000')").
+		AddRow("blockchainevents_p1000", "FOR VALUES FROM ('1000') TO ('2000')").
+		AddRow("blockchainevents_p2000", "FOR VALUES FROM ('2000') TO ('3000')"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(seq), 0) FROM blockchainevents`)).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(999))
+	mock.ExpectCommit()
+
+	// operations has written past its last partition
+	expectIsPartitioned(mock, "operations", true)
+	expectPartitions(mock, "operations", sqlmock.NewRows([]string{"relname", "bound"}).
+		AddRow("operations_legacy", "FOR VALUES FROM (MINVALUE) TO ('1000')"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(seq), 0) FROM operations`)).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(1200))
+	expectSQL(mock, `CREATE TABLE IF NOT EXISTS operations_p1201 PARTITION OF operations FOR VALUES FROM (1201) TO (2000)`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `CREATE TABLE IF NOT EXISTS operations_p2000 PARTITION OF operations FOR VALUES FROM (2000) TO (3000)`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `CREATE TABLE IF NOT EXISTS operations_p3000 PARTITION OF operations FOR VALUES FROM (3000) TO (4000)`).WillReturnResult(driver.ResultNoRows)
+	mock.ExpectCommit()
+
+	// the maintenance loop fails on its first check, as nothing more is expected
+	err := psql.startPartitioning(ctx, conf)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Len(t, psql.partitioner.tables, 2)
+	cancel()
+}
+
+func TestPartitionTablesSequence(t *testing.T) {
+	ctx, _, psql, mock, conf := newMockPartitionPostgres(t, partitionBySequence)
+	p, err := newPartitioner(ctx, conf)
+	assert.NoError(t, err)
+	psql.partitioner = p
+
+	// events is converted, keeping its unique indexes unique
+	mock.ExpectBegin()
+	mock.ExpectExec("pg_advisory_xact_lock").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectQuery("SELECT relkind").WithArgs("events").WillReturnRows(sqlmock.NewRows([]string{"relkind"}).AddRow("r"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT pg_get_serial_sequence($1, 'seq'), COALESCE(MAX(seq), 0) FROM events`)).WithArgs("events").
+		WillReturnRows(sqlmock.NewRows([]string{"seq", "max"}).AddRow("public.events_seq_seq", 1500))
+	mock.ExpectQuery("FROM pg_indexes").WithArgs("events").WillReturnRows(sqlmock.NewRows([]string{"indexname", "indexdef"}).
+		AddRow("events_id", "CREATE UNIQUE INDEX events_id ON public.events USING btree (id)").
+		AddRow("events_pkey", "CREATE UNIQUE INDEX events_pkey ON public.events USING btree (seq)").
+		AddRow("events_topic", "CREATE INDEX events_topic ON public.events USING btree (topic)"))
+	expectSQL(mock, `ALTER TABLE events RENAME TO events_legacy`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `ALTER TABLE events_legacy RENAME CONSTRAINT events_pkey TO events_legacy_pkey`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `ALTER INDEX events_id RENAME TO events_id_legacy`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `ALTER INDEX events_topic RENAME TO events_topic_legacy`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `CREATE TABLE events (LIKE events_legacy INCLUDING DEFAULTS) PARTITION BY RANGE (seq)`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `ALTER TABLE events ADD PRIMARY KEY (seq)`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `CREATE UNIQUE INDEX events_id ON public.events USING btree (id, seq)`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `CREATE INDEX events_topic ON public.events USING btree (topic)`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `ALTER SEQUENCE public.events_seq_seq OWNED BY events.seq`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `ALTER TABLE events ATTACH PARTITION events_legacy FOR VALUES FROM (MINVALUE) TO (2000)`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `CREATE TABLE events_default PARTITION OF events DEFAULT`).WillReturnResult(driver.ResultNoRows)
+	mock.ExpectCommit()
+	expectPartitions(mock, "events", sqlmock.NewRows([]string{"relname", "bound"}).
+		AddRow("events_legacy", "FOR VALUES FROM (MINVALUE) TO ('2000')").
+		AddRow("events_default", "DEFAULT"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(seq), 0) FROM events`)).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(1500))
+	expectSQL(mock, `CREATE TABLE IF NOT EXISTS events_p2000 PARTITION OF events FOR VALUES FROM (2000) TO (3000)`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `CREATE TABLE IF NOT EXISTS events_p3000 PARTITION OF events FOR VALUES FROM (3000) TO (4000)`).WillReturnResult(driver.ResultNoRows)
+	mock.ExpectCommit()
+
+	// the others are already partitioned
+	for _, table := range []string{"blockchainevents", "operations"} {
+		expectPartitioned(mock, table)
+		expectPartitions(mock, table, sqlmock.NewRows([]string{"relname", "bound"}).
+			AddRow(table+"_p3000", "FOR VALUES FROM ('3000') TO ('4000')"))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(seq), 0) FROM ` + table)).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(1500))
+		mock.ExpectCommit()
+	}
+
+	err = psql.partitionTables(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Len(t, psql.partitioner.tables, 3)
+
+	// tables are only added once
+	psql.addPartitionedTable(partitionedTables[0])
+	assert.Len(t, psql.partitioner.tables, 3)
+}
+
+func TestPartitionTablesTime(t *testing.T) {
+	ctx, _, psql, mock, conf := newMockPartitionPostgres(t, partitionByTime)
+	conf.Set(PSQLConfPartitioningRetention, "24h")
+	p, err := newPartitioner(ctx, conf)
+	assert.NoError(t, err)
+	psql.partitioner = p
+	day := (24 * time.Hour).Nanoseconds()
+	today := testTime.Truncate(24 * time.Hour).UnixNano()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("pg_advisory_xact_lock").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectQuery("SELECT relkind").WithArgs("events").WillReturnRows(sqlmock.NewRows([]string{"relkind"}).AddRow("r"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT pg_get_serial_sequence($1, 'seq'), COALESCE(MAX(created), 0) FROM events`)).WithArgs("events").
+		WillReturnRows(sqlmock.NewRows([]string{"seq", "max"}).AddRow("public.events_seq_seq", today-day))
+	mock.ExpectQuery("FROM pg_indexes").WithArgs("events").WillReturnRows(sqlmock.NewRows([]string{"indexname", "indexdef"}).
+		AddRow("events_id", "CREATE UNIQUE INDEX events_id ON public.events USING btree (id)").
+		AddRow("events_created", "CREATE UNIQUE INDEX events_created ON public.events USING btree (created, id) WHERE (id IS NOT NULL)"))
+	expectSQL(mock, `ALTER TABLE events RENAME TO events_legacy`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `ALTER TABLE events_legacy RENAME CONSTRAINT events_pkey TO events_legacy_pkey`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `ALTER INDEX events_id RENAME TO events_id_legacy`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `ALTER INDEX events_created RENAME TO events_created_legacy`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `CREATE TABLE events (LIKE events_legacy INCLUDING DEFAULTS) PARTITION BY RANGE (created)`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `ALTER TABLE events ADD PRIMARY KEY (seq, created)`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `CREATE UNIQUE INDEX events_id ON public.events USING btree (id, created)`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `CREATE UNIQUE INDEX events_created ON public.events USING btree (created, id) WHERE (id IS NOT NULL)`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `ALTER SEQUENCE public.events_seq_seq OWNED BY events.seq`).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, fmt.Sprintf(`ALTER TABLE events ATTACH PARTITION events_legacy FOR VALUES FROM (MINVALUE) TO (%d)`, today+day)).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, `CREATE TABLE events_default PARTITION OF events DEFAULT`).WillReturnResult(driver.ResultNoRows)
+	mock.ExpectCommit()
+	expectPartitions(mock, "events", sqlmock.NewRows([]string{"relname", "bound"}).
+		AddRow("events_legacy", fmt.Sprintf("FOR VALUES FROM (MINVALUE) TO ('%d')", today+day)))
+	expectSQL(mock, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS events_p20261020000000 PARTITION OF events FOR VALUES FROM (%d) TO (%d)`, today+day, today+2*day)).WillReturnResult(driver.ResultNoRows)
+	expectSQL(mock, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS events_p20261021000000 PARTITION OF events FOR VALUES FROM (%d) TO (%d)`, today+2*day, today+3*day)).WillReturnResult(driver.ResultNoRows)
+	mock.ExpectCommit()
+
+	// The oldest expired partition is dropped, but the next one holds rows that are still needed
+	expectPartitioned(mock, "blockchainevents")
+	expectPartitions(mock, "blockchainevents", sqlmock.NewRows([]string{"relname", "bound"}).
+		AddRow("blockchainevents_p20261016000000", fmt.Sprintf("FOR VALUES FROM ('%d') TO ('%d')", today-3*day, today-2*day)).
+		AddRow("blockchainevents_p20261017000000", fmt.Sprintf("FOR VALUES FROM ('%d') TO ('%d')", today-2*day, today-day)).
+		AddRow("blockchainevents_p20261021000000", fmt.Sprintf("FOR VALUES FROM ('%d') TO ('%d')", today+2*day, today+3*day)))
+	expectGuards(mock, 100)
+	expectNeeded(mock, "blockchainevents_p20261016000000", false).WithArgs(int64(100), int64(100))
+	expectNeeded(mock, "blockchainevents_p20261017000000", true).WithArgs(int64(100), int64(100))
+	expectSQL(mock, `DROP TABLE blockchainevents_p20261016000000`).WillReturnResult(driver.ResultNoRows)
+	mock.ExpectCommit()
+
+	// The expired partition holds operations that are still in progress
+	expectPartitioned(mock, "operations")
+	expectPartitions(mock, "operations", sqlmock.NewRows([]string{"relname", "bound"}).
+		AddRow("operations_p20261016000000", fmt.Sprintf("FOR VALUES FROM ('%d') TO ('%d')", today-3*day, today-2*day)).
+		AddRow("operations_p20261021000000", fmt.Sprintf("FOR VALUES FROM ('%d') TO ('%d')", today+2*day, today+3*day)))
+	expectGuards(mock, 100)
+	expectNeeded(mock, "operations_p20261016000000", true).WithArgs(core.OpStatusSucceeded, core.OpStatusFailed, int64(100), int64(100))
+	mock.ExpectCommit()
+
+	err = psql.partitionTables(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrateUpPartitions(t *testing.T) {
+	ctx, _, psql, mock, conf := newMockPartitionPostgres(t, partitionBySequence)
+	p, err := newPartitioner(ctx, conf)
+	assert.NoError(t, err)
+	psql.partitioner = p
+
+	for _, table := range []string{"events", "blockchainevents", "operations"} {
+		expectPartitioned(mock, table)
+		expectPartitions(mock, table, sqlmock.NewRows([]string{"relname", "bound"}).
+			AddRow(table+"_p3000", "FOR VALUES FROM ('3000') TO ('4000')"))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(seq), 0) FROM ` + table)).WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(1500))
+		mock.ExpectCommit()
+	}
+
+	applied, err := psql.MigrateUp(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, applied, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrateUpPartitionsFail(t *testing.T) {
+	ctx, _, psql, mock, _ := newMockPartitionPostgres(t, partitionBySequence)
+	psql.partitioner = &partitioner{strategy: partitionBySequence, rangeSize: 1000}
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	_, err := psql.MigrateUp(ctx)
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrateUpFail(t *testing.T) {
+	ctx, _, psql, _, _ := newMockPartitionPostgres(t, partitionBySequence)
+	assert.NoError(t, os.RemoveAll(config.RootSection("unittest").GetString(sqlcommon.SQLConfMigrationsDirectory)))
+	_, err := psql.MigrateUp(ctx)
+	assert.Regexp(t, "FF10507", err)
+}
+
+func TestPartitionedIndex(t *testing.T) {
+	ctx := context.Background()
+	def, err := partitionedIndex(ctx, "events", &tableIndex{name: "events_id", def: "CREATE UNIQUE INDEX events_id ON public.events USING btree (namespace, id)"}, "seq")
+	assert.NoError(t, err)
+	assert.Equal(t, "CREATE UNIQUE INDEX events_id ON public.events USING btree (namespace, id, seq)", def)
+
+	def, err = partitionedIndex(ctx, "events", &tableIndex{name: "events_id", def: "CREATE UNIQUE INDEX events_id ON public.events USING btree (id, seq)"}, "seq")
+	assert.NoError(t, err)
+	assert.Equal(t, "CREATE UNIQUE INDEX events_id ON public.events USING btree (id, seq)", def)
+
+	_, err = partitionedIndex(ctx, "events", &tableIndex{name: "events_topic", def: "CREATE UNIQUE INDEX events_topic ON public.events USING btree (lower(topic))"}, "seq")
+	assert.Regexp(t, "FF10526.*events_topic", err)
+}
+
+func TestPartitionTablesFailConvert(t *testing.T) {
+	ctx, _, psql, mock, _ := newMockPartitionPostgres(t, partitionBySequence)
+	psql.partitioner = &partitioner{strategy: partitionBySequence, rangeSize: 1000}
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := psql.partitionTables(ctx)
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPartitionTablesFailMaintain(t *testing.T) {
+	ctx, _, psql, mock, _ := newMockPartitionPostgres(t, partitionBySequence)
+	psql.partitioner = &partitioner{strategy: partitionBySequence, rangeSize: 1000}
+	expectPartitioned(mock, "events")
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := psql.partitionTables(ctx)
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPartitionTableFailUniqueIndex(t *testing.T) {
+	ctx, _, psql, mock, _ := newMockPartitionPostgres(t, partitionBySequence)
+	psql.partitioner = &partitioner{strategy: partitionBySequence, rangeSize: 1000}
+	mock.ExpectBegin()
+	mock.ExpectExec("pg_advisory_xact_lock").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectQuery("SELECT relkind").WillReturnRows(sqlmock.NewRows([]string{"relkind"}).AddRow("r"))
+	mock.ExpectQuery("pg_get_serial_sequence").WillReturnRows(sqlmock.NewRows([]string{"seq", "max"}).AddRow("events_seq_seq", 0))
+	mock.ExpectQuery("FROM pg_indexes").WillReturnRows(sqlmock.NewRows([]string{"indexname", "indexdef"}).
+		AddRow("events_topic", "CREATE UNIQUE INDEX events_topic ON public.events USING btree (lower(topic))"))
+	mock.ExpectRollback()
+	err := psql.partitionTable(ctx, partitionedTables[0])
+	assert.Regexp(t, "FF10526", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func newMockRetentionPostgres(t *testing.T) (context.Context, *Postgres, sqlmock.Sqlmock) {
+	ctx, _, psql, mock, _ := newMockPartitionPostgres(t, partitionByTime)
+	psql.partitioner = &partitioner{strategy: partitionByTime, rangeSize: (24 * time.Hour).Nanoseconds(), retention: 24 * time.Hour}
+	expectPartitions(mock, "events", sqlmock.NewRows([]string{"relname", "bound"}).
+		AddRow("events_p20261016000000", "FOR VALUES FROM ('0') TO ('1')"))
+	return ctx, psql, mock
+}
+
+func TestMaintainPartitionsFailGuardsSubscriptions(t *testing.T) {
+	ctx, psql, mock := newMockRetentionPostgres(t)
+	mock.ExpectQuery("FROM subscriptions").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := psql.maintainPartitions(ctx, partitionedTables[0])
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMaintainPartitionsFailNeeded(t *testing.T) {
+	ctx, psql, mock := newMockRetentionPostgres(t)
+	expectGuards(mock, -1)
+	expectNeeded(mock, "events_p20261016000000", false).WithArgs(int64(-1)).WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := psql.maintainPartitions(ctx, partitionedTables[0])
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInitPartitioningFail(t *testing.T) {
+	psql := &Postgres{}
+	conf := newPartitionConfig(t, partitionBySequence)
+	conf.Set(sqlcommon.SQLConfDatasourceURL, "!bad connection")
+	err := psql.Init(context.Background(), conf)
+	assert.Regexp(t, "FF00176", err)
+}
+
+func TestStartPartitioningBadStrategy(t *testing.T) {
+	ctx, _, psql, _, conf := newMockPartitionPostgres(t, "wrong")
+	err := psql.startPartitioning(ctx, conf)
+	assert.Regexp(t, "FF10505", err)
+}
+
+func TestStartPartitioningBadRange(t *testing.T) {
+	ctx, _, psql, _, conf := newMockPartitionPostgres(t, partitionBySequence)
+	conf.Set(PSQLConfPartitioningSequenceRange, 0)
+	err := psql.startPartitioning(ctx, conf)
+	assert.Regexp(t, "FF10506", err)
+}
+
+func TestStartPartitioningMaintainFail(t *testing.T) {
+	ctx, _, psql, mock, conf := newMockPartitionPostgres(t, partitionBySequence)
+	expectIsPartitioned(mock, "events", true)
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := psql.startPartitioning(ctx, conf)
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStartPartitioningQueryFail(t *testing.T) {
+	ctx, _, psql, mock, conf := newMockPartitionPostgres(t, partitionBySequence)
+	mock.ExpectQuery("SELECT EXISTS").WillReturnError(fmt.Errorf("pop"))
+	err := psql.startPartitioning(ctx, conf)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPartitionLoop(t *testing.T) {
+	ctx, cancel, psql, mock, _ := newMockPartitionPostgres(t, partitionBySequence)
+	psql.partitioner = &partitioner{ctx: ctx, strategy: partitionBySequence, rangeSize: 1000, checkInterval: time.Millisecond, tables: partitionedTables}
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	psql.partitionLoop()
+}
+
+func TestPartitionTableFailLock(t *testing.T) {
+	ctx, _, psql, mock, _ := newMockPartitionPostgres(t, partitionBySequence)
+	psql.partitioner = &partitioner{strategy: partitionBySequence, rangeSize: 1000}
+	mock.ExpectBegin()
+	mock.ExpectExec("pg_advisory_xact_lock").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := psql.partitionTable(ctx, partitionedTables[0])
+	assert.Regexp(t, "FF00187", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPartitionTableFailRelkind(t *testing.T) {
+	ctx, _, psql, mock, _ := newMockPartitionPostgres(t, partitionBySequence)
+	psql.partitioner = &partitioner{strategy: partitionBySequence, rangeSize: 1000}
+	mock.ExpectBegin()
+	mock.ExpectExec("pg_advisory_xact_lock").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectQuery("SELECT relkind").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := psql.partitionTable(ctx, partitionedTables[0])
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPartitionTableFailMax(t *testing.T) {
+	ctx, _, psql, mock, _ := newMockPartitionPostgres(t, partitionBySequence)
+	psql.partitioner = &partitioner{strategy: partitionBySequence, rangeSize: 1000}
+	mock.ExpectBegin()
+	mock.ExpectExec("pg_advisory_xact_lock").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectQuery("SELECT relkind").WillReturnRows(sqlmock.NewRows([]string{"relkind"}).AddRow("r"))
+	mock.ExpectQuery("pg_get_serial_sequence").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := psql.partitionTable(ctx, partitionedTables[0])
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPartitionTableFailIndexes(t *testing.T) {
+	ctx, _, psql, mock, _ := newMockPartitionPostgres(t, partitionBySequence)
+	psql.partitioner = &partitioner{strategy: partitionBySequence, rangeSize: 1000}
+	mock.ExpectBegin()
+	mock.ExpectExec("pg_advisory_xact_lock").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectQuery("SELECT relkind").WillReturnRows(sqlmock.NewRows([]string{"relkind"}).AddRow("r"))
+	mock.ExpectQuery("pg_get_serial_sequence").WillReturnRows(sqlmock.NewRows([]string{"seq", "max"}).AddRow("events_seq_seq", 0))
+	mock.ExpectQuery("FROM pg_indexes").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := psql.partitionTable(ctx, partitionedTables[0])
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPartitionTableFailIndexScan(t *testing.T) {
+	ctx, _, psql, mock, _ := newMockPartitionPostgres(t, partitionBySequence)
+	psql.partitioner = &partitioner{strategy: partitionBySequence, rangeSize: 1000}
+	mock.ExpectBegin()
+	mock.ExpectExec("pg_advisory_xact_lock").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectQuery("SELECT relkind").WillReturnRows(sqlmock.NewRows([]string{"relkind"}).AddRow("r"))
+	mock.ExpectQuery("pg_get_serial_sequence").WillReturnRows(sqlmock.NewRows([]string{"seq", "max"}).AddRow("events_seq_seq", 0))
+	mock.ExpectQuery("FROM pg_indexes").WillReturnRows(sqlmock.NewRows([]string{"indexname"}).AddRow("events_id"))
+	mock.ExpectRollback()
+	err := psql.partitionTable(ctx, partitionedTables[0])
+	assert.Regexp(t, "FF00182", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPartitionTableFailDDL(t *testing.T) {
+	ctx, _, psql, mock, _ := newMockPartitionPostgres(t, partitionBySequence)
+	psql.partitioner = &partitioner{strategy: partitionBySequence, rangeSize: 1000}
+	mock.ExpectBegin()
+	mock.ExpectExec("pg_advisory_xact_lock").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectQuery("SELECT relkind").WillReturnRows(sqlmock.NewRows([]string{"relkind"}).AddRow("r"))
+	mock.ExpectQuery("pg_get_serial_sequence").WillReturnRows(sqlmock.NewRows([]string{"seq", "max"}).AddRow("events_seq_seq", 0))
+	mock.ExpectQuery("FROM pg_indexes").WillReturnRows(sqlmock.NewRows([]string{"indexname", "indexdef"}))
+	mock.ExpectExec("ALTER TABLE events RENAME").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := psql.partitionTable(ctx, partitionedTables[0])
+	assert.Regexp(t, "FF00184", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPartitionTableFailCommit(t *testing.T) {
+	ctx, _, psql, mock, _ := newMockPartitionPostgres(t, partitionBySequence)
+	psql.partitioner = &partitioner{strategy: partitionBySequence, rangeSize: 1000}
+	mock.ExpectBegin()
+	mock.ExpectExec("pg_advisory_xact_lock").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectQuery("SELECT relkind").WillReturnRows(sqlmock.NewRows([]string{"relkind"}).AddRow("p"))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := psql.partitionTable(ctx, partitionedTables[0])
+	assert.Regexp(t, "FF00180", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMaintainPartitionsFailQuery(t *testing.T) {
+	ctx, _, psql, mock, _ := newMockPartitionPostgres(t, partitionBySequence)
+	psql.partitioner = &partitioner{strategy: partitionBySequence, rangeSize: 1000}
+	mock.ExpectBegin()
+	mock.ExpectExec("pg_advisory_xact_lock").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectQuery("FROM pg_inherits").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := psql.maintainPartitions(ctx, partitionedTables[0])
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMaintainPartitionsFailScan(t *testing.T) {
+	ctx, _, psql, mock, _ := newMockPartitionPostgres(t, partitionBySequence)
+	psql.partitioner = &partitioner{strategy: partitionBySequence, rangeSize: 1000}
+	expectPartitions(mock, "events", sqlmock.NewRows([]string{"relname"}).AddRow("events_legacy"))
+	mock.ExpectRollback()
+	err := psql.maintainPartitions(ctx, partitionedTables[0])
+	assert.Regexp(t, "FF00182", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMaintainPartitionsFailMax(t *testing.T) {
+	ctx, _, psql, mock, _ := newMockPartitionPostgres(t, partitionBySequence)
+	psql.partitioner = &partitioner{strategy: partitionBySequence, rangeSize: 1000}
+	expectPartitions(mock, "events", sqlmock.NewRows([]string{"relname", "bound"}))
+	mock.ExpectQuery("SELECT COALESCE").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := psql.maintainPartitions(ctx, partitionedTables[0])
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
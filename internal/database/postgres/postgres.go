@@ -18,8 +18,11 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math/big"
+	"strconv"
+	"strings"
 
 	"database/sql"
 
@@ -29,6 +32,7 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/dbsql"
 	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
 
 	// Import pq driver
@@ -37,11 +41,51 @@ import (
 
 type Postgres struct {
 	sqlcommon.SQLCommon
+	cluster     *cluster
+	partitioner *partitioner
 }
 
 func (psql *Postgres) Init(ctx context.Context, config config.Section) error {
-	capabilities := &database.Capabilities{}
-	return psql.SQLCommon.Init(ctx, psql, config, capabilities)
+	clusterConf := config.SubSection(PSQLConfCluster)
+	capabilities := &database.Capabilities{
+		Clustering: clusterConf.GetBool(PSQLConfClusterEnabled),
+	}
+	err := psql.SQLCommon.Init(ctx, psql, config, capabilities)
+	if err == nil {
+		err = psql.InitReadReplica(ctx, psql, config.SubSection(sqlcommon.SQLConfReadReplica))
+	}
+	if err == nil && capabilities.Clustering {
+		err = psql.startCluster(ctx, config.GetString(sqlcommon.SQLConfDatasourceURL), clusterConf)
+	}
+	if partitionConf := config.SubSection(PSQLConfPartitioning); err == nil && partitionConf.GetBool(PSQLConfPartitioningEnabled) {
+		err = psql.startPartitioning(ctx, partitionConf)
+	}
+	return err
+}
+
+// InitSchema connects to the database without starting the cluster or the partition maintenance loop. The partitioning
+// configuration is still loaded, so that MigrateUp can convert the tables.
+func (psql *Postgres) InitSchema(ctx context.Context, config config.Section) (err error) {
+	err = psql.SQLCommon.Init(ctx, psql, config, &database.Capabilities{})
+	if partitionConf := config.SubSection(PSQLConfPartitioning); err == nil && partitionConf.GetBool(PSQLConfPartitioningEnabled) {
+		psql.partitioner, err = newPartitioner(ctx, partitionConf)
+	}
+	return err
+}
+
+// MigrateUp applies the pending migrations, then converts the tables to partitioned tables if partitioning is enabled.
+// Converting a large table locks it for some time, so it is done here with the node stopped, rather than on startup.
+func (psql *Postgres) MigrateUp(ctx context.Context) ([]*core.DatabaseMigration, error) {
+	applied, err := psql.SQLCommon.MigrateUp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if psql.partitioner != nil {
+		if err := psql.partitionTables(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return applied, nil
 }
 
 func (psql *Postgres) SetHandler(namespace string, handler database.Callbacks) {
@@ -96,3 +140,34 @@ func (psql *Postgres) Open(url string) (*sql.DB, error) {
 func (psql *Postgres) GetMigrationDriver(db *sql.DB) (migratedb.Driver, error) {
 	return postgres.WithInstance(db, &postgres.Config{})
 }
+
+// JSONPathText uses the jsonb path operator, as JSON is stored in text columns
+func (psql *Postgres) JSONPathText(column string, path []string) string {
+	return fmt.Sprintf("(%s::jsonb #>> '{%s}')", column, strings.Join(path, ","))
+}
+
+// JSONPathIndexCondition uses jsonb containment, which can be served by the GIN index on the value of data.
+// The value is matched as a string, and also as JSON if it is valid JSON. As containment does not match
+// by the position in an array, there is no condition for a path that contains an array index.
+func (psql *Postgres) JSONPathIndexCondition(column string, path []string, value string) sq.Sqlizer {
+	for _, segment := range path {
+		if _, err := strconv.Atoi(segment); err == nil {
+			return nil
+		}
+	}
+	str, _ := json.Marshal(value)
+	candidates := []json.RawMessage{str}
+	if json.Valid([]byte(value)) {
+		candidates = append(candidates, json.RawMessage(value))
+	}
+	or := make(sq.Or, len(candidates))
+	for i, candidate := range candidates {
+		var doc interface{} = candidate
+		for j := len(path) - 1; j >= 0; j-- {
+			doc = map[string]interface{}{path[j]: doc}
+		}
+		b, _ := json.Marshal(doc)
+		or[i] = sq.Expr(fmt.Sprintf("%s::jsonb @> ?::jsonb", column), string(b))
+	}
+	return or
+}
@@ -33,11 +33,17 @@ func TestPostgresProvider(t *testing.T) {
 	config := config.RootSection("unittest")
 	psql.InitConfig(config)
 	config.Set(sqlcommon.SQLConfDatasourceURL, "!bad connection")
+	config.SubSection(sqlcommon.SQLConfReadReplica).Set(sqlcommon.SQLConfDatasourceURL, "!bad connection")
 	err := psql.Init(context.Background(), config)
 	assert.NoError(t, err)
 	_, err = psql.GetMigrationDriver(psql.DB())
 	assert.Error(t, err)
 
+	assert.False(t, psql.Capabilities().Clustering)
+	lost, err := psql.AcquireLeadership(context.Background(), "namespace:ns1")
+	assert.NoError(t, err)
+	assert.NotNil(t, lost)
+
 	assert.Equal(t, "postgres", psql.Name())
 	assert.Equal(t, "seq", psql.SequenceColumn())
 	assert.Equal(t, sq.Dollar, psql.Features().PlaceholderFormat)
@@ -51,3 +57,38 @@ func TestPostgresProvider(t *testing.T) {
 	assert.Equal(t, "INSERT INTO test (col1) VALUES (?)  ON CONFLICT DO NOTHING RETURNING seq", sql)
 	assert.True(t, query)
 }
+
+func TestPostgresJSONPath(t *testing.T) {
+	psql := &Postgres{}
+	assert.Equal(t, "(d.value::jsonb #>> '{customer,id}')", psql.JSONPathText("d.value", []string{"customer", "id"}))
+
+	sql, args, err := psql.JSONPathIndexCondition("value", []string{"customer", "id"}, "12345").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(value::jsonb @> ?::jsonb OR value::jsonb @> ?::jsonb)", sql)
+	assert.Equal(t, []interface{}{`{"customer":{"id":"12345"}}`, `{"customer":{"id":12345}}`}, args)
+
+	sql, args, err = psql.JSONPathIndexCondition("value", []string{"name"}, "fred").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(value::jsonb @> ?::jsonb)", sql)
+	assert.Equal(t, []interface{}{`{"name":"fred"}`}, args)
+
+	assert.Nil(t, psql.JSONPathIndexCondition("value", []string{"lines", "0", "sku"}, "abc"))
+}
+
+func TestPostgresInitSchema(t *testing.T) {
+	psql := &Postgres{}
+	config := config.RootSection("unittest")
+	psql.InitConfig(config)
+	config.Set(sqlcommon.SQLConfDatasourceURL, "!bad connection")
+	config.SubSection(PSQLConfCluster).Set(PSQLConfClusterEnabled, true)
+	config.SubSection(PSQLConfPartitioning).Set(PSQLConfPartitioningEnabled, true)
+	err := psql.InitSchema(context.Background(), config)
+	assert.NoError(t, err)
+	assert.Nil(t, psql.cluster)
+	assert.NotNil(t, psql.partitioner)
+	assert.False(t, psql.Capabilities().Clustering)
+
+	config.SubSection(PSQLConfPartitioning).Set(PSQLConfPartitioningStrategy, "wrong")
+	err = (&Postgres{}).InitSchema(context.Background(), config)
+	assert.Regexp(t, "FF10505", err)
+}
@@ -197,3 +197,7 @@ func (s *SQLCommon) UpdateBatch(ctx context.Context, namespace string, id *fftyp
 
 	return s.CommitTx(ctx, tx, autoCommit)
 }
+
+func (s *SQLCommon) DeleteBatches(ctx context.Context, namespace string, filter ffapi.Filter, guards *database.RetentionGuards) (count int64, err error) {
+	return s.deleteMatching(ctx, batchesTable, namespace, filter, batchFilterFieldMap, guards, nil)
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -231,3 +232,38 @@ func TestBatchUpdateFail(t *testing.T) {
 	err := s.UpdateBatch(context.Background(), "ns1", fftypes.NewUUID(), u)
 	assert.Regexp(t, "FF00178", err)
 }
+
+func TestDeleteBatches(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionBatches, core.ChangeEventTypeCreated, "ns1", mock.Anything).Return()
+	batch1 := &core.BatchPersisted{
+		BatchHeader: core.BatchHeader{ID: fftypes.NewUUID(), Namespace: "ns1", Type: core.BatchTypeBroadcast, Created: fftypes.Now()},
+		Hash:        fftypes.NewRandB32(),
+		Manifest:    fftypes.JSONAnyPtr("{}"),
+		Confirmed:   fftypes.Now(),
+	}
+	batch2 := &core.BatchPersisted{
+		BatchHeader: core.BatchHeader{ID: fftypes.NewUUID(), Namespace: "ns1", Type: core.BatchTypeBroadcast, Created: fftypes.Now()},
+		Hash:        fftypes.NewRandB32(),
+		Manifest:    fftypes.JSONAnyPtr("{}"),
+	}
+	_, err := s.InsertOrGetBatch(ctx, batch1)
+	assert.NoError(t, err)
+	_, err = s.InsertOrGetBatch(ctx, batch2)
+	assert.NoError(t, err)
+
+	fb := database.BatchQueryFactory.NewFilter(ctx)
+	count, err := s.DeleteBatches(ctx, "ns1", fb.And(), &database.RetentionGuards{EventSequence: math.MaxInt64, PinSequence: math.MaxInt64})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	batch, err := s.GetBatchByID(ctx, "ns1", batch1.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, batch)
+	batch, err = s.GetBatchByID(ctx, "ns1", batch2.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, batch)
+}
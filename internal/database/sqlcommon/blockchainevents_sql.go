@@ -45,6 +45,7 @@ var (
 		"tx_type",
 		"tx_id",
 		"tx_blockchain_id",
+		"filter_index",
 	}
 	blockchainEventFilterFieldMap = map[string]string{
 		"protocolid":      "protocol_id",
@@ -71,6 +72,7 @@ func (s *SQLCommon) setBlockchainEventInsertValues(query sq.InsertBuilder, event
 		event.TX.Type,
 		event.TX.ID,
 		event.TX.BlockchainID,
+		event.Filter,
 	)
 }
 
@@ -90,6 +92,21 @@ func (s *SQLCommon) InsertOrGetBlockchainEvent(ctx context.Context, event *core.
 	}
 	defer s.RollbackTx(ctx, tx, autoCommit)
 
+	if s.partitioned[blockchaineventsTable] {
+		// There is no unique index on the protocolID to conflict with, so check for an existing event under a lock
+		if err = s.AcquireLockTx(ctx, blockchaineventsTable, tx); err != nil {
+			return nil, err
+		}
+		existing, err = s.GetBlockchainEventByProtocolID(ctx, event.Namespace, event.Listener, event.ProtocolID)
+		if err != nil || existing != nil {
+			return existing, err
+		}
+		if err = s.attemptBlockchainEventInsert(ctx, tx, event, false); err != nil {
+			return nil, err
+		}
+		return nil, s.CommitTx(ctx, tx, autoCommit)
+	}
+
 	opErr := s.attemptBlockchainEventInsert(ctx, tx, event, true /* we want a failure here we can progress past */)
 	if opErr == nil {
 		return nil, s.CommitTx(ctx, tx, autoCommit)
@@ -120,6 +137,7 @@ func (s *SQLCommon) blockchainEventResult(ctx context.Context, row *sql.Rows) (*
 		&event.TX.Type,
 		&event.TX.ID,
 		&event.TX.BlockchainID,
+		&event.Filter,
 	)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, blockchaineventsTable)
@@ -189,3 +207,7 @@ func (s *SQLCommon) GetBlockchainEvents(ctx context.Context, namespace string, f
 
 	return events, s.QueryRes(ctx, blockchaineventsTable, tx, fop, fi), err
 }
+
+func (s *SQLCommon) DeleteBlockchainEvents(ctx context.Context, namespace string, filter ffapi.Filter, guards *database.RetentionGuards) (count int64, err error) {
+	return s.deleteMatching(ctx, blockchaineventsTable, namespace, filter, blockchainEventFilterFieldMap, guards, nil)
+}
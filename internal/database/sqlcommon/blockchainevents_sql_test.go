@@ -18,15 +18,18 @@ package sqlcommon
 
 import (
 	"context"
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestBlockchainEventsE2EWithDB(t *testing.T) {
@@ -35,6 +38,7 @@ func TestBlockchainEventsE2EWithDB(t *testing.T) {
 	ctx := context.Background()
 
 	// Create a new contract event entry
+	filterIndex := 1
 	event := &core.BlockchainEvent{
 		ID:         fftypes.NewUUID(),
 		Namespace:  "ns",
@@ -49,6 +53,7 @@ func TestBlockchainEventsE2EWithDB(t *testing.T) {
 			Type:         core.TransactionTypeBatchPin,
 			BlockchainID: "0x12345",
 		},
+		Filter: &filterIndex,
 	}
 
 	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionBlockchainEvents, core.ChangeEventTypeCreated, "ns", event.ID).Return().Once()
@@ -146,6 +151,72 @@ func TestInsertBlockchainEventFailCommit(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestInsertBlockchainEventPartitioned(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	s.SetPartitionedTables(blockchaineventsTable)
+	ctx := context.Background()
+
+	event := &core.BlockchainEvent{
+		ID:         fftypes.NewUUID(),
+		Namespace:  "ns",
+		Listener:   fftypes.NewUUID(),
+		ProtocolID: "tx1",
+		Timestamp:  fftypes.Now(),
+	}
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionBlockchainEvents, core.ChangeEventTypeCreated, "ns", event.ID).Return().Once()
+	existing, err := s.InsertOrGetBlockchainEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.Nil(t, existing)
+
+	event2 := &core.BlockchainEvent{
+		ID:         fftypes.NewUUID(),
+		Namespace:  event.Namespace,
+		Listener:   event.Listener,
+		ProtocolID: event.ProtocolID,
+		Timestamp:  fftypes.Now(),
+	}
+	existing, err = s.InsertOrGetBlockchainEvent(ctx, event2)
+	assert.NoError(t, err)
+	assert.Equal(t, event.ID, existing.ID)
+}
+
+func TestInsertBlockchainEventPartitionedFailLock(t *testing.T) {
+	s, mock := newMockProvider().init()
+	s.SetPartitionedTables(blockchaineventsTable)
+	mock.ExpectBegin()
+	mock.ExpectExec("<acquire lock blockchainevents>").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.InsertOrGetBlockchainEvent(context.Background(), &core.BlockchainEvent{})
+	assert.Regexp(t, "FF00187", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertBlockchainEventPartitionedFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	s.SetPartitionedTables(blockchaineventsTable)
+	mock.ExpectBegin()
+	mock.ExpectExec("<acquire lock blockchainevents>").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.InsertOrGetBlockchainEvent(context.Background(), &core.BlockchainEvent{})
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertBlockchainEventPartitionedFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	s.SetPartitionedTables(blockchaineventsTable)
+	mock.ExpectBegin()
+	mock.ExpectExec("<acquire lock blockchainevents>").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.InsertOrGetBlockchainEvent(context.Background(), &core.BlockchainEvent{})
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGetBlockchainEventByIDSelectFail(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
@@ -195,3 +266,36 @@ func TestGetBlockchainEventsScanFail(t *testing.T) {
 	assert.Regexp(t, "FF10121", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestDeleteBlockchainEvents(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionBlockchainEvents, core.ChangeEventTypeCreated, "ns1", mock.Anything).Return()
+	old := fftypes.FFTime(time.Now().Add(-1 * time.Hour))
+	event1 := &core.BlockchainEvent{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "Changed", ProtocolID: "000001", Timestamp: &old}
+	event2 := &core.BlockchainEvent{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "Changed", ProtocolID: "000002", Timestamp: fftypes.Now()}
+	event3 := &core.BlockchainEvent{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "Changed", ProtocolID: "000003", Timestamp: &old}
+	for _, event := range []*core.BlockchainEvent{event1, event2, event3} {
+		_, err := s.InsertOrGetBlockchainEvent(ctx, event)
+		assert.NoError(t, err)
+	}
+
+	// The third blockchain event is referenced by an event that is still to be delivered
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+	event := &core.Event{ID: fftypes.NewUUID(), Namespace: "ns1", Type: core.EventTypeBlockchainEventReceived, Reference: event3.ID, Created: fftypes.Now()}
+	assert.NoError(t, s.InsertEvent(ctx, event))
+
+	fb := database.BlockchainEventQueryFactory.NewFilter(ctx)
+	cutoff := fftypes.FFTime(time.Now().Add(-1 * time.Minute))
+	count, err := s.DeleteBlockchainEvents(ctx, "ns1", fb.And(fb.Lt("timestamp", &cutoff)), &database.RetentionGuards{EventSequence: event.Sequence - 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	remaining, _, err := s.GetBlockchainEvents(ctx, "ns1", fb.And().Sort("protocolid"))
+	assert.NoError(t, err)
+	assert.Len(t, remaining, 2)
+	assert.Equal(t, event2.ID, remaining[0].ID)
+	assert.Equal(t, event3.ID, remaining[1].ID)
+}
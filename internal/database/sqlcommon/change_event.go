@@ -0,0 +1,44 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// ChangeKind identifies which of the database.Callbacks functions a change event is delivered to
+type ChangeKind string
+
+const (
+	ChangeKindOrderedUUID ChangeKind = "ordered_uuid"
+	ChangeKindOrdered     ChangeKind = "ordered"
+	ChangeKindUUID        ChangeKind = "uuid"
+	ChangeKindHash        ChangeKind = "hash"
+)
+
+// ChangeEvent is a serializable form of a single database change notification, so that it can be
+// shared with other processes connected to the same database
+type ChangeEvent struct {
+	Kind       ChangeKind           `json:"kind"`
+	Collection string               `json:"collection"`
+	Type       core.ChangeEventType `json:"type"`
+	Namespace  string               `json:"namespace"`
+	ID         *fftypes.UUID        `json:"id,omitempty"`
+	Hash       *fftypes.Bytes32     `json:"hash,omitempty"`
+	Sequence   int64                `json:"sequence,omitempty"`
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/dbsql"
+	"github.com/hyperledger/firefly/internal/encryption"
 )
 
 const (
@@ -38,6 +39,10 @@ const (
 	SQLConfMaxIdleConns = "maxIdleConns"
 	// SQLConfMaxConnLifetime maximum connections to the database
 	SQLConfMaxConnLifetime = "maxConnLifetime"
+	// SQLConfReadReplica is the sub-section for an optional read-only datasource, used for API queries
+	SQLConfReadReplica = "readReplica"
+	// SQLConfEncryption is the sub-section for encryption at rest of data values
+	SQLConfEncryption = "encryption"
 )
 
 const (
@@ -52,4 +57,13 @@ func (s *SQLCommon) InitConfig(provider dbsql.Provider, config config.Section) {
 	config.AddKnownKey(SQLConfMaxConnIdleTime, "1m")
 	config.AddKnownKey(SQLConfMaxIdleConns) // defaults to the max connections
 	config.AddKnownKey(SQLConfMaxConnLifetime)
+	encryption.InitConfig(config.SubSection(SQLConfEncryption))
+}
+
+func (s *SQLCommon) InitReadReplicaConfig(config config.Section) {
+	config.AddKnownKey(SQLConfDatasourceURL)
+	config.AddKnownKey(SQLConfMaxConnections)
+	config.AddKnownKey(SQLConfMaxConnIdleTime, "1m")
+	config.AddKnownKey(SQLConfMaxIdleConns)
+	config.AddKnownKey(SQLConfMaxConnLifetime)
 }
@@ -56,6 +56,16 @@ func TestContractListenerE2EWithDB(t *testing.T) {
 		Options: &core.ContractListenerOptions{
 			FirstEvent: "0",
 		},
+		Filters: core.ListenerFilters{{
+			Event: &core.FFISerializedEvent{
+				FFIEventDefinition: fftypes.FFIEventDefinition{
+					Name: "event1",
+				},
+			},
+			Location:  fftypes.JSONAnyPtrBytes(locationJson),
+			Indexed:   fftypes.JSONObject{"from": "0x123"},
+			Signature: "event1",
+		}},
 	}
 
 	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionContractListeners, core.ChangeEventTypeCreated, "ns", sub.ID).Return()
@@ -211,7 +221,7 @@ func TestContractListenerDeleteFail(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin()
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(contractListenerColumns).AddRow(
-		fftypes.NewUUID(), nil, []byte("{}"), "ns1", "sub1", "123", "{}", "sig", "topic1", nil, fftypes.Now()),
+		fftypes.NewUUID(), nil, []byte("{}"), "ns1", "sub1", "123", "{}", "sig", "topic1", nil, fftypes.Now(), nil),
 	)
 	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
 	err := s.DeleteContractListenerByID(context.Background(), "ns", fftypes.NewUUID())
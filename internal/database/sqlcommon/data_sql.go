@@ -49,7 +49,7 @@ var (
 		"public",
 		"value_size",
 	}
-	dataColumnsWithValue = append(append([]string{}, dataColumnsNoValue...), "value")
+	dataColumnsWithValue = append(append([]string{}, dataColumnsNoValue...), "value", "value_encrypted")
 	dataFilterFieldMap   = map[string]string{
 		"validator":        "validator",
 		"datatype.name":    "datatype_name",
@@ -74,6 +74,10 @@ func (s *SQLCommon) attemptDataUpdate(ctx context.Context, tx *dbsql.TXWrapper,
 		blob = &core.BlobRef{}
 	}
 	data.CalcPath()
+	value, encrypted, err := s.dataValueForDB(ctx, data)
+	if err != nil {
+		return -1, err
+	}
 	return s.UpdateTx(ctx, dataTable, tx,
 		sq.Update(dataTable).
 			Set("validator", string(data.Validator)).
@@ -88,7 +92,8 @@ func (s *SQLCommon) attemptDataUpdate(ctx context.Context, tx *dbsql.TXWrapper,
 			Set("blob_size", blob.Size).
 			Set("public", data.Public).
 			Set("value_size", data.ValueSize).
-			Set("value", data.Value).
+			Set("value", value).
+			Set("value_encrypted", encrypted).
 			Where(sq.Eq{
 				"id":        data.ID,
 				"hash":      data.Hash,
@@ -99,7 +104,7 @@ func (s *SQLCommon) attemptDataUpdate(ctx context.Context, tx *dbsql.TXWrapper,
 		})
 }
 
-func (s *SQLCommon) setDataInsertValues(query sq.InsertBuilder, data *core.Data) sq.InsertBuilder {
+func (s *SQLCommon) setDataInsertValues(ctx context.Context, query sq.InsertBuilder, data *core.Data) (sq.InsertBuilder, error) {
 	datatype := data.Datatype
 	if datatype == nil {
 		datatype = &core.DatatypeRef{}
@@ -109,6 +114,10 @@ func (s *SQLCommon) setDataInsertValues(query sq.InsertBuilder, data *core.Data)
 		blob = &core.BlobRef{}
 	}
 	data.CalcPath()
+	value, encrypted, err := s.dataValueForDB(ctx, data)
+	if err != nil {
+		return query, err
+	}
 	return query.Values(
 		data.ID,
 		string(data.Validator),
@@ -124,13 +133,56 @@ func (s *SQLCommon) setDataInsertValues(query sq.InsertBuilder, data *core.Data)
 		blob.Size,
 		data.Public,
 		data.ValueSize,
-		data.Value,
-	)
+		value,
+		encrypted,
+	), nil
+}
+
+// dataValueAAD binds an encrypted value to the data record it was written to
+func dataValueAAD(data *core.Data) []byte {
+	return []byte(data.Namespace + ":" + data.ID.String())
+}
+
+// dataValueForDB returns the value to store for a data record, which is encrypted if encryption is enabled,
+// and whether it was encrypted. The hash and size of the data are always those of the plaintext value.
+func (s *SQLCommon) dataValueForDB(ctx context.Context, data *core.Data) (interface{}, bool, error) {
+	if s.encryptor == nil || data.Value.IsNil() {
+		return data.Value, false, nil
+	}
+	value, err := s.encryptor.Encrypt(ctx, data.Value.Bytes(), dataValueAAD(data))
+	return value, err == nil, err
+}
+
+// dataValueFromDB sets the value of a data record from the stored value, decrypting it if it was stored encrypted.
+// Values stored before encryption was enabled are returned as-is.
+func (s *SQLCommon) dataValueFromDB(ctx context.Context, data *core.Data, stored sql.NullString, encrypted bool) error {
+	if !stored.Valid {
+		return nil
+	}
+	value := stored.String
+	if encrypted {
+		if s.encryptor == nil {
+			return i18n.NewError(ctx, coremsgs.MsgEncryptionNotEnabled, data.ID)
+		}
+		plaintext, err := s.encryptor.Decrypt(ctx, value, dataValueAAD(data))
+		if err != nil {
+			return err
+		}
+		value = string(plaintext)
+	}
+	data.Value = new(fftypes.JSONAny)
+	if err := data.Value.Scan(value); err != nil {
+		return i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, dataTable)
+	}
+	return nil
 }
 
 func (s *SQLCommon) attemptDataInsert(ctx context.Context, tx *dbsql.TXWrapper, data *core.Data, requestConflictEmptyResult bool) (int64, error) {
-	return s.InsertTxExt(ctx, dataTable, tx,
-		s.setDataInsertValues(sq.Insert(dataTable).Columns(dataColumnsWithValue...), data),
+	query, err := s.setDataInsertValues(ctx, sq.Insert(dataTable).Columns(dataColumnsWithValue...), data)
+	if err != nil {
+		return -1, err
+	}
+	return s.InsertTxExt(ctx, dataTable, tx, query,
 		func() {
 			s.callbacks.UUIDCollectionNSEvent(database.CollectionData, core.ChangeEventTypeCreated, data.Namespace, data.ID)
 		}, requestConflictEmptyResult)
@@ -205,7 +257,9 @@ func (s *SQLCommon) InsertDataArray(ctx context.Context, dataArray core.DataArra
 	if s.Features().MultiRowInsert {
 		query := sq.Insert(dataTable).Columns(dataColumnsWithValue...)
 		for _, data := range dataArray {
-			query = s.setDataInsertValues(query, data)
+			if query, err = s.setDataInsertValues(ctx, query, data); err != nil {
+				return err
+			}
 		}
 		sequences := make([]int64, len(dataArray))
 		err := s.InsertTxRows(ctx, dataTable, tx, query, func() {
@@ -251,8 +305,10 @@ func (s *SQLCommon) dataResult(ctx context.Context, row *sql.Rows, withValue boo
 		&data.Public,
 		&data.ValueSize,
 	}
+	var value sql.NullString
+	var encrypted bool
 	if withValue {
-		results = append(results, &data.Value)
+		results = append(results, &value, &encrypted)
 	}
 	err := row.Scan(results...)
 	if data.Blob.Hash == nil && data.Blob.Public == "" {
@@ -264,6 +320,9 @@ func (s *SQLCommon) dataResult(ctx context.Context, row *sql.Rows, withValue boo
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, dataTable)
 	}
+	if err := s.dataValueFromDB(ctx, &data, value, encrypted); err != nil {
+		return nil, err
+	}
 	return &data, nil
 }
 
@@ -298,11 +357,19 @@ func (s *SQLCommon) GetDataByID(ctx context.Context, namespace string, id *fftyp
 	return data, nil
 }
 
-func (s *SQLCommon) GetData(ctx context.Context, namespace string, filter ffapi.Filter) (message core.DataArray, res *ffapi.FilterResult, err error) {
+func (s *SQLCommon) GetData(ctx context.Context, namespace string, filter ffapi.Filter, valueFilter ffapi.Filter) (message core.DataArray, res *ffapi.FilterResult, err error) {
 
+	preconditions := []sq.Sqlizer{sq.Eq{"namespace": namespace}}
+	valueCond, err := s.valueFilterCondition(ctx, "value", valueFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+	if valueCond != nil {
+		preconditions = append(preconditions, valueCond)
+	}
 	query, fop, fi, err := s.FilterSelect(
 		ctx, "", sq.Select(dataColumnsWithValue...).From(dataTable),
-		filter, dataFilterFieldMap, []interface{}{"sequence"}, sq.Eq{"namespace": namespace})
+		filter, dataFilterFieldMap, []interface{}{"sequence"}, preconditions...)
 	if err != nil {
 		return nil, nil, err
 	}
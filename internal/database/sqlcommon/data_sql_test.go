@@ -18,13 +18,18 @@ package sqlcommon
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/encryption"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/stretchr/testify/assert"
@@ -137,7 +142,7 @@ func TestDataE2EWithDB(t *testing.T) {
 		fb.Eq("hash", dataUpdated.Hash),
 		fb.Gt("created", 0),
 	)
-	dataRes, _, err := s.GetData(ctx, "ns1", filter)
+	dataRes, _, err := s.GetData(ctx, "ns1", filter, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(dataRes))
 	dataReadJson, _ = json.Marshal(dataRes[0])
@@ -160,7 +165,7 @@ func TestDataE2EWithDB(t *testing.T) {
 		fb.Eq("id", dataUpdated.ID.String()),
 		fb.Eq("datatype.version", v2),
 	)
-	dataRes, res, err := s.GetData(ctx, "ns1", filter.Count(true))
+	dataRes, res, err := s.GetData(ctx, "ns1", filter.Count(true), nil)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(dataRes))
 	assert.Equal(t, int64(1), *res.TotalCount)
@@ -170,7 +175,7 @@ func TestDataE2EWithDB(t *testing.T) {
 	// Delete
 	err = s.DeleteData(ctx, "ns1", dataID)
 	assert.NoError(t, err)
-	dataRes, res, err = s.GetData(ctx, "ns1", filter.Count(true))
+	dataRes, res, err = s.GetData(ctx, "ns1", filter.Count(true), nil)
 	assert.NoError(t, err)
 	assert.Len(t, dataRes, 0)
 }
@@ -380,7 +385,7 @@ func TestGetDataQueryFail(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
 	f := database.DataQueryFactory.NewFilter(context.Background()).Eq("id", "")
-	_, _, err := s.GetData(context.Background(), "ns1", f)
+	_, _, err := s.GetData(context.Background(), "ns1", f, nil)
 	assert.Regexp(t, "FF00176", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -388,7 +393,7 @@ func TestGetDataQueryFail(t *testing.T) {
 func TestGetDataBuildQueryFail(t *testing.T) {
 	s, _ := newMockProvider().init()
 	f := database.DataQueryFactory.NewFilter(context.Background()).Eq("id", map[bool]bool{true: false})
-	_, _, err := s.GetData(context.Background(), "ns1", f)
+	_, _, err := s.GetData(context.Background(), "ns1", f, nil)
 	assert.Regexp(t, "FF00143.*id", err)
 }
 
@@ -396,7 +401,7 @@ func TestGetDataReadMessageFail(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
 	f := database.DataQueryFactory.NewFilter(context.Background()).Eq("id", "")
-	_, _, err := s.GetData(context.Background(), "ns1", f)
+	_, _, err := s.GetData(context.Background(), "ns1", f, nil)
 	assert.Regexp(t, "FF10121", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -484,3 +489,175 @@ func TestGetDataSubPathsReadFail(t *testing.T) {
 	assert.Regexp(t, "FF10121", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func newTestEncryptor(t *testing.T, keyID string) *encryption.Encryptor {
+	keyDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(keyDir, "key1.key"), []byte(hex.EncodeToString(fftypes.NewRandB32()[:])), 0600)
+	assert.NoError(t, err)
+	conf := config.RootSection("unittest.encryption")
+	encryption.InitConfig(conf)
+	conf.Set(encryption.ConfEnabled, true)
+	conf.Set(encryption.ConfProvider, encryption.ProviderKMS)
+	conf.Set(encryption.ConfKMSKeyID, keyID)
+	conf.Set(encryption.ConfKMSLocalKeyDir, keyDir)
+	e, err := encryption.NewEncryptor(context.Background(), conf)
+	assert.NoError(t, err)
+	return e
+}
+
+func TestDataEncryptionE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionData, mock.Anything, "ns1", mock.Anything).Return()
+
+	newData := func() *core.Data {
+		value := fftypes.JSONAnyPtr(fftypes.JSONObject{"secret": fftypes.NewUUID().String()}.String())
+		d := &core.Data{
+			ID:        fftypes.NewUUID(),
+			Validator: core.ValidatorTypeJSON,
+			Namespace: "ns1",
+			Created:   fftypes.Now(),
+			Value:     value,
+		}
+		d.Hash, _ = d.CalcHash(context.Background())
+		return d
+	}
+	storedValue := func(id *fftypes.UUID) (string, bool) {
+		var stored string
+		var encrypted bool
+		err := s.DB().QueryRow("SELECT value, value_encrypted FROM data WHERE id = $1", id.String()).Scan(&stored, &encrypted)
+		assert.NoError(t, err)
+		return stored, encrypted
+	}
+
+	// Written before encryption is enabled
+	plainData := newData()
+	err := s.UpsertData(ctx, plainData, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+	stored, encrypted := storedValue(plainData.ID)
+	assert.Equal(t, plainData.Value.String(), stored)
+	assert.False(t, encrypted)
+
+	// A plaintext value that looks like an encrypted one is not mistaken for one
+	lookalikeData := newData()
+	lookalikeData.Value = fftypes.JSONAnyPtr(`{"ffenc":1,"key":"AAAA","data":"AAAA"}`)
+	lookalikeData.Hash, _ = lookalikeData.CalcHash(ctx)
+	err = s.UpsertData(ctx, lookalikeData, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+
+	s.encryptor = newTestEncryptor(t, "key1")
+	data1 := newData()
+	err = s.UpsertData(ctx, data1, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+	data2 := newData()
+	err = s.InsertDataArray(ctx, core.DataArray{data2})
+	assert.NoError(t, err)
+	for _, d := range []*core.Data{data1, data2} {
+		stored, encrypted := storedValue(d.ID)
+		assert.True(t, encrypted)
+		assert.NotContains(t, stored, "secret")
+	}
+
+	// Updates are encrypted too
+	data1.Value = fftypes.JSONAnyPtr(`{"secret":"updated"}`)
+	err = s.UpsertData(ctx, data1, database.UpsertOptimizationExisting)
+	assert.NoError(t, err)
+	stored, encrypted = storedValue(data1.ID)
+	assert.NotContains(t, stored, "updated")
+	assert.True(t, encrypted)
+
+	// Values are decrypted on read, with the hash of the plaintext
+	for _, d := range []*core.Data{plainData, lookalikeData, data1, data2} {
+		dataRead, err := s.GetDataByID(ctx, "ns1", d.ID, true)
+		assert.NoError(t, err)
+		assert.Equal(t, d.Value.String(), dataRead.Value.String())
+		assert.Equal(t, d.Hash, dataRead.Hash)
+	}
+	dataRes, _, err := s.GetData(ctx, "ns1", database.DataQueryFactory.NewFilter(ctx).And(), nil)
+	assert.NoError(t, err)
+	assert.Len(t, dataRes, 4)
+	dataRead, err := s.GetDataByID(ctx, "ns1", data2.ID, true)
+	assert.NoError(t, err)
+	hash, err := dataRead.CalcHash(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, data2.Hash, hash)
+
+	// Encrypted values cannot be read if encryption is disabled
+	s.encryptor = nil
+	_, err = s.GetDataByID(ctx, "ns1", data2.ID, true)
+	assert.Regexp(t, "FF10502", err)
+	dataRead, err = s.GetDataByID(ctx, "ns1", data2.ID, false)
+	assert.NoError(t, err)
+	assert.Nil(t, dataRead.Value)
+	for _, d := range []*core.Data{plainData, lookalikeData} {
+		dataRead, err = s.GetDataByID(ctx, "ns1", d.ID, true)
+		assert.NoError(t, err)
+		assert.Equal(t, d.Value.String(), dataRead.Value.String())
+	}
+}
+
+func TestUpsertDataEncryptFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	s.encryptor = newTestEncryptor(t, "missing")
+	dataID := fftypes.NewUUID()
+	data := &core.Data{ID: dataID, Value: fftypes.JSONAnyPtr(`"value"`)}
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectRollback()
+	err := s.UpsertData(context.Background(), data, database.UpsertOptimizationNew)
+	assert.Regexp(t, "FF10500", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertDataUpdateEncryptFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	s.encryptor = newTestEncryptor(t, "missing")
+	dataID := fftypes.NewUUID()
+	dataHash := fftypes.NewRandB32()
+	data := &core.Data{ID: dataID, Hash: dataHash, Value: fftypes.JSONAnyPtr(`"value"`)}
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"hash"}).AddRow(dataHash.String()))
+	mock.ExpectRollback()
+	err := s.UpsertData(context.Background(), data, database.UpsertOptimizationSkip)
+	assert.Regexp(t, "FF10500", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertDataArrayMultiRowEncryptFail(t *testing.T) {
+	s := newMockProvider()
+	s.multiRowInsert = true
+	s, mock := s.init()
+	s.encryptor = newTestEncryptor(t, "missing")
+	data1 := &core.Data{ID: fftypes.NewUUID(), Namespace: "ns1", Value: fftypes.JSONAnyPtr(`"value"`)}
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	err := s.InsertDataArray(context.Background(), core.DataArray{data1})
+	assert.Regexp(t, "FF10500", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDataByIDDecryptFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	s.encryptor = newTestEncryptor(t, "key1")
+	dataID := fftypes.NewUUID()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(dataColumnsWithValue).AddRow(
+		dataID.String(), "json", "ns1", "", "", nil, nil, nil, "", "", "", 0, "", 0,
+		`{"ffenc":1,"key":"AAAA","data":"AAAA"}`, true,
+	))
+	_, err := s.GetDataByID(context.Background(), "ns1", dataID, true)
+	assert.Regexp(t, "FF10501", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDataByIDBadValue(t *testing.T) {
+	s, mock := newMockProvider().init()
+	dataID := fftypes.NewUUID()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(dataColumnsWithValue).AddRow(
+		dataID.String(), "json", "ns1", "", "", nil, nil, nil, "", "", "", 0, "", 0,
+		`{!bad json`, false,
+	))
+	_, err := s.GetDataByID(context.Background(), "ns1", dataID, true)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
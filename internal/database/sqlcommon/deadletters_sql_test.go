@@ -0,0 +1,258 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadLettersE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	subID := fftypes.NewUUID()
+	dl := &core.DeadLetter{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Subscription: core.SubscriptionRef{
+			ID:        subID,
+			Namespace: "ns1",
+			Name:      "sub1",
+		},
+		Event:    fftypes.NewUUID(),
+		Attempts: 5,
+		Reason:   "pop",
+		Created:  fftypes.Now(),
+	}
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionDeadLetters, core.ChangeEventTypeCreated, "ns1", dl.ID).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionDeadLetters, core.ChangeEventTypeUpdated, "ns1", dl.ID).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionDeadLetters, core.ChangeEventTypeDeleted, "ns1", dl.ID).Return()
+
+	err := s.InsertDeadLetter(ctx, dl)
+	assert.NoError(t, err)
+
+	// Check we get the exact same dead letter back
+	dlRead, err := s.GetDeadLetterByID(ctx, "ns1", dl.ID)
+	assert.NoError(t, err)
+	dlJson, _ := json.Marshal(&dl)
+	dlReadJson, _ := json.Marshal(&dlRead)
+	assert.Equal(t, string(dlJson), string(dlReadJson))
+
+	// Query back by subscription
+	fb := database.DeadLetterQueryFactory.NewFilter(ctx)
+	dls, res, err := s.GetDeadLetters(ctx, "ns1", fb.And(
+		fb.Eq("subscription", subID),
+		fb.Eq("event", dl.Event),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(dls))
+	assert.Equal(t, int64(1), *res.TotalCount)
+
+	// Update the attempts
+	dl.Attempts = 6
+	dl.Reason = "bang"
+	dl.Updated = fftypes.Now()
+	err = s.UpdateDeadLetter(ctx, "ns1", dl.ID, database.DeadLetterQueryFactory.NewUpdate(ctx).
+		Set("attempts", dl.Attempts).
+		Set("reason", dl.Reason).
+		Set("updated", dl.Updated))
+	assert.NoError(t, err)
+	dlRead, err = s.GetDeadLetterByID(ctx, "ns1", dl.ID)
+	assert.NoError(t, err)
+	dlJson, _ = json.Marshal(&dl)
+	dlReadJson, _ = json.Marshal(&dlRead)
+	assert.Equal(t, string(dlJson), string(dlReadJson))
+
+	// Delete it
+	err = s.DeleteDeadLetter(ctx, "ns1", dl.ID)
+	assert.NoError(t, err)
+	dlRead, err = s.GetDeadLetterByID(ctx, "ns1", dl.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, dlRead)
+
+	// Purge all for the subscription
+	dl.ID = fftypes.NewUUID()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionDeadLetters, core.ChangeEventTypeCreated, "ns1", dl.ID).Return()
+	err = s.InsertDeadLetter(ctx, dl)
+	assert.NoError(t, err)
+	err = s.DeleteDeadLetters(ctx, "ns1", subID)
+	assert.NoError(t, err)
+	dls, _, err = s.GetDeadLetters(ctx, "ns1", fb.And())
+	assert.NoError(t, err)
+	assert.Empty(t, dls)
+
+	// Purge is fine when there is nothing to purge
+	err = s.DeleteDeadLetters(ctx, "ns1", subID)
+	assert.NoError(t, err)
+}
+
+func TestInsertDeadLetterFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertDeadLetter(context.Background(), &core.DeadLetter{})
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertDeadLetterFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertDeadLetter(context.Background(), &core.DeadLetter{})
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertDeadLetterFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertDeadLetter(context.Background(), &core.DeadLetter{})
+	assert.Regexp(t, "FF00180", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeadLetterByIDSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetDeadLetterByID(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeadLetterByIDScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetDeadLetterByID(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeadLettersBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.DeadLetterQueryFactory.NewFilter(context.Background()).Eq("id", map[bool]bool{true: false})
+	_, _, err := s.GetDeadLetters(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF00143.*id", err)
+}
+
+func TestGetDeadLettersQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.DeadLetterQueryFactory.NewFilter(context.Background()).Eq("reason", "")
+	_, _, err := s.GetDeadLetters(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeadLettersScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.DeadLetterQueryFactory.NewFilter(context.Background()).Eq("reason", "")
+	_, _, err := s.GetDeadLetters(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateDeadLetterFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpdateDeadLetter(context.Background(), "ns1", fftypes.NewUUID(),
+		database.DeadLetterQueryFactory.NewUpdate(context.Background()).Set("reason", "pop"))
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateDeadLetterFailFilter(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	err := s.UpdateDeadLetter(context.Background(), "ns1", fftypes.NewUUID(),
+		database.DeadLetterQueryFactory.NewUpdate(context.Background()).Set("wrong", "pop"))
+	assert.Regexp(t, "FF00142", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateDeadLetterFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpdateDeadLetter(context.Background(), "ns1", fftypes.NewUUID(),
+		database.DeadLetterQueryFactory.NewUpdate(context.Background()).Set("reason", "pop"))
+	assert.Regexp(t, "FF00178", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateDeadLetterNotFound(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectRollback()
+	err := s.UpdateDeadLetter(context.Background(), "ns1", fftypes.NewUUID(),
+		database.DeadLetterQueryFactory.NewUpdate(context.Background()).Set("reason", "pop"))
+	assert.Regexp(t, "FF10143", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteDeadLetterFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteDeadLetter(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteDeadLetterFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteDeadLetter(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteDeadLettersFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteDeadLetters(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteDeadLettersFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteDeadLetters(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -136,6 +136,7 @@ func (p *eventsPCA) PreCommit(ctx context.Context, tx *dbsql.TXWrapper) (err err
 	} else {
 		// Fall back to individual inserts grouped in a TX
 		for _, event := range p.events {
+			event := event // captured by the post-commit hook
 			query := p.s.setEventInsertValues(sq.Insert(eventsTable).Columns(eventColumns...), event)
 			event.Sequence, err = p.s.InsertTx(ctx, eventsTable, tx, query, func() {
 				p.s.eventInserted(ctx, event)
@@ -225,3 +226,7 @@ func (s *SQLCommon) GetEvents(ctx context.Context, namespace string, filter ffap
 	return events, s.QueryRes(ctx, eventsTable, tx, fop, fi), err
 
 }
+
+func (s *SQLCommon) DeleteEvents(ctx context.Context, namespace string, filter ffapi.Filter, guards *database.RetentionGuards) (count int64, err error) {
+	return s.deleteMatching(ctx, eventsTable, namespace, filter, eventFilterFieldMap, guards, nil)
+}
@@ -183,6 +183,31 @@ func TestInsertEventsPreCommitMultiRowFail(t *testing.T) {
 	s.callbacks.AssertExpectations(t)
 }
 
+func TestInsertEventsPreCommitSingleRowOK(t *testing.T) {
+	s, mock := newMockProvider().init()
+
+	ev1 := &core.Event{ID: fftypes.NewUUID(), Namespace: "ns1"}
+	ev2 := &core.Event{ID: fftypes.NewUUID(), Namespace: "ns1"}
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeCreated, "ns1", ev1.ID, int64(1001)).Once()
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeCreated, "ns1", ev2.ID, int64(1002)).Once()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("<acquire lock ns1>").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectExec("INSERT.*").WillReturnResult(sqlmock.NewResult(1001, 1))
+	mock.ExpectExec("INSERT.*").WillReturnResult(sqlmock.NewResult(1002, 1))
+	mock.ExpectCommit()
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(context.Background())
+	tx.SetPreCommitAccumulator(&eventsPCA{
+		s:      &s.SQLCommon,
+		events: []*core.Event{ev1, ev2},
+	})
+	assert.NoError(t, err)
+	err = s.CommitTx(ctx, tx, autoCommit)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	s.callbacks.AssertExpectations(t)
+}
+
 func TestInsertEventsPreCommitSingleRowFail(t *testing.T) {
 	s, mock := newMockProvider().init()
 	ev1 := &core.Event{ID: fftypes.NewUUID(), Namespace: "ns1"}
@@ -253,3 +278,39 @@ func TestGettEventsReadMessageFail(t *testing.T) {
 	assert.Regexp(t, "FF10121", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestDeleteEvents(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+	events := make([]*core.Event, 3)
+	for i := range events {
+		events[i] = &core.Event{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Type:      core.EventTypeMessageConfirmed,
+			Created:   fftypes.Now(),
+		}
+		err := s.InsertEvent(ctx, events[i])
+		assert.NoError(t, err)
+	}
+
+	fb := database.EventQueryFactory.NewFilter(ctx)
+	guards := &database.RetentionGuards{EventSequence: events[1].Sequence}
+	count, err := s.DeleteEvents(ctx, "ns1", fb.And().Sort("sequence").Limit(1), guards)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	count, err = s.DeleteEvents(ctx, "ns1", fb.And(), guards)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	count, err = s.DeleteEvents(ctx, "ns2", fb.And(), guards)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	remaining, _, err := s.GetEvents(ctx, "ns1", fb.And())
+	assert.NoError(t, err)
+	assert.Len(t, remaining, 1)
+	assert.Equal(t, events[2].ID, remaining[0].ID)
+}
@@ -590,14 +590,26 @@ func (s *SQLCommon) queryBatchIDs(ctx context.Context, query sq.SelectBuilder) (
 	return batchIDs, nil
 }
 
-func (s *SQLCommon) GetMessages(ctx context.Context, namespace string, filter ffapi.Filter) (message []*core.Message, fr *ffapi.FilterResult, err error) {
+func (s *SQLCommon) GetMessages(ctx context.Context, namespace string, filter ffapi.Filter, valueFilter ffapi.Filter) (message []*core.Message, fr *ffapi.FilterResult, err error) {
 	cols := append([]string{}, msgColumns...)
 	cols = append(cols, s.SequenceColumn())
+	preconditions := []sq.Sqlizer{sq.Eq{"namespace_local": namespace}}
+	valueCond, err := s.valueFilterCondition(ctx, "d.value", valueFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+	if valueCond != nil {
+		// Messages match if any of their data matches
+		preconditions = append(preconditions, sq.Expr("id IN (?)",
+			sq.Select("md.message_id").From("messages_data AS md").
+				Join("data AS d ON d.id = md.data_id AND d.namespace = md.namespace").
+				Where(sq.And{sq.Eq{"md.namespace": namespace}, valueCond})))
+	}
 	query, fop, fi, err := s.FilterSelect(ctx, "", sq.Select(cols...).From(messagesTable), filter, msgFilterFieldMap,
 		[]interface{}{
 			&ffapi.SortField{Field: "confirmed", Descending: true, Nulls: ffapi.NullsFirst},
 			&ffapi.SortField{Field: "created", Descending: true},
-		}, sq.Eq{"namespace_local": namespace})
+		}, preconditions...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -651,3 +663,21 @@ func (s *SQLCommon) UpdateMessages(ctx context.Context, namespace string, filter
 
 	return s.CommitTx(ctx, tx, autoCommit)
 }
+
+func (s *SQLCommon) DeleteMessages(ctx context.Context, namespace string, filter ffapi.Filter, guards *database.RetentionGuards) (count int64, err error) {
+	return s.deleteMatching(ctx, messagesTable, namespace, filter, msgFilterFieldMap, guards,
+		func(ctx context.Context, tx *dbsql.TXWrapper, seqs []int64) error {
+			// The data itself is not deleted, as it might be shared with other messages
+			err := s.DeleteTx(ctx, messagesDataJoinTable, tx,
+				sq.Delete(messagesDataJoinTable).Where(sq.And{
+					sq.Eq{"namespace": namespace},
+					sq.Expr("message_id IN (?)", sq.Select("id").From(messagesTable).Where(sq.Eq{s.SequenceColumn(): seqs})),
+				}),
+				nil, // no change event
+			)
+			if err != nil && err != database.DeleteRecordNotFound {
+				return err
+			}
+			return nil
+		})
+}
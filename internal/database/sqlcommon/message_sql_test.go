@@ -21,9 +21,11 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	sq "github.com/Masterminds/squirrel"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly/pkg/core"
@@ -168,7 +170,7 @@ func TestUpsertE2EWithDB(t *testing.T) {
 		fb.Gt("created", "0"),
 		fb.Gt("confirmed", "0"),
 	)
-	msgs, res, err := s.GetMessages(ctx, "ns12345", filter.Count(true))
+	msgs, res, err := s.GetMessages(ctx, "ns12345", filter.Count(true), nil)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(msgs))
 	assert.Equal(t, int64(1), *res.TotalCount)
@@ -205,7 +207,7 @@ func TestUpsertE2EWithDB(t *testing.T) {
 		fb.Eq("id", msgUpdated.Header.ID.String()),
 		fb.Eq("created", "0"),
 	)
-	msgs, _, err = s.GetMessages(ctx, "ns12345", filter)
+	msgs, _, err = s.GetMessages(ctx, "ns12345", filter, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, 0, len(msgs))
 
@@ -223,7 +225,7 @@ func TestUpsertE2EWithDB(t *testing.T) {
 		fb.Eq("id", msgUpdated.Header.ID.String()),
 		fb.Eq("group", gid2),
 	)
-	msgs, _, err = s.GetMessages(ctx, "ns12345", filter)
+	msgs, _, err = s.GetMessages(ctx, "ns12345", filter, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(msgs))
 	assert.Equal(t, *bid2, *msgs[0].BatchID)
@@ -575,7 +577,7 @@ func TestGetMessageByIDLoadRefsFail(t *testing.T) {
 func TestGetMessagesBuildQueryFail(t *testing.T) {
 	s, _ := newMockProvider().init()
 	f := database.MessageQueryFactory.NewFilter(context.Background()).Eq("id", map[bool]bool{true: false})
-	_, _, err := s.GetMessages(context.Background(), "ns1", f)
+	_, _, err := s.GetMessages(context.Background(), "ns1", f, nil)
 	assert.Regexp(t, "FF00143.*id", err)
 }
 
@@ -583,7 +585,7 @@ func TestGetMessagesQueryFail(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
 	f := database.MessageQueryFactory.NewFilter(context.Background()).Eq("id", "")
-	_, _, err := s.GetMessages(context.Background(), "ns1", f)
+	_, _, err := s.GetMessages(context.Background(), "ns1", f, nil)
 	assert.Regexp(t, "FF00176", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -600,7 +602,7 @@ func TestGetMessagesReadMessageFail(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
 	f := database.MessageQueryFactory.NewFilter(context.Background()).Eq("id", "")
-	_, _, err := s.GetMessages(context.Background(), "ns1", f)
+	_, _, err := s.GetMessages(context.Background(), "ns1", f, nil)
 	assert.Regexp(t, "FF10121", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -615,7 +617,7 @@ func TestGetMessagesLoadRefsFail(t *testing.T) {
 		AddRow(msgID.String(), nil, core.MessageTypeBroadcast, "author1", "0x12345", 0, "ns1", "ns1", "t1", "c1", nil, b32.String(), b32.String(), b32.String(), "confirmed", 0, "pin", nil, "", nil, nil, "bob", 0))
 	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
 	f := database.MessageQueryFactory.NewFilter(context.Background()).Gt("confirmed", "0")
-	_, _, err := s.GetMessages(context.Background(), "ns1", f)
+	_, _, err := s.GetMessages(context.Background(), "ns1", f, nil)
 	assert.Regexp(t, "FF00176", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -698,3 +700,113 @@ func TestGetBatchIDsForMessagesScanFail(t *testing.T) {
 	assert.Regexp(t, "FF10121", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestDeleteMessages(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+	msgs := make([]*core.Message, 2)
+	for i := range msgs {
+		msgs[i] = &core.Message{
+			Header: core.MessageHeader{
+				ID:        fftypes.NewUUID(),
+				Type:      core.MessageTypeBroadcast,
+				Namespace: "ns1",
+				Created:   fftypes.Now(),
+				DataHash:  fftypes.NewRandB32(),
+			},
+			Hash:           fftypes.NewRandB32(),
+			LocalNamespace: "ns1",
+			State:          core.MessageStateConfirmed,
+			Data: core.DataRefs{
+				{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32()},
+			},
+		}
+		err := s.UpsertMessage(ctx, msgs[i], database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+	}
+
+	fb := database.MessageQueryFactory.NewFilter(ctx)
+	count, err := s.DeleteMessages(ctx, "ns1", fb.And(fb.Eq("id", msgs[0].Header.ID)), &database.RetentionGuards{EventSequence: math.MaxInt64})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	msg, err := s.GetMessageByID(ctx, "ns1", msgs[0].Header.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, msg)
+	msg, err = s.GetMessageByID(ctx, "ns1", msgs[1].Header.ID)
+	assert.NoError(t, err)
+	assert.Len(t, msg.Data, 1)
+
+	// Only the data references of the deleted message are removed
+	rows, _, err := s.Query(ctx, messagesDataJoinTable, sq.Select("message_id").From(messagesDataJoinTable))
+	assert.NoError(t, err)
+	var msgIDs []string
+	for rows.Next() {
+		var msgID string
+		assert.NoError(t, rows.Scan(&msgID))
+		msgIDs = append(msgIDs, msgID)
+	}
+	rows.Close()
+	assert.Equal(t, []string{msgs[1].Header.ID.String()}, msgIDs)
+}
+
+func TestDeleteMessagesDataRefsFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(12345))
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	_, err := s.DeleteMessages(context.Background(), "ns1", fb.And(), &database.RetentionGuards{})
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMessagesByCursor(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+	msgIDs := make([]*fftypes.UUID, 5)
+	for i := range msgIDs {
+		msg := &core.Message{
+			Header: core.MessageHeader{
+				ID:        fftypes.NewUUID(),
+				Type:      core.MessageTypeBroadcast,
+				Namespace: "ns1",
+				Created:   fftypes.Now(),
+				DataHash:  fftypes.NewRandB32(),
+			},
+			Hash:           fftypes.NewRandB32(),
+			LocalNamespace: "ns1",
+			State:          core.MessageStateConfirmed,
+		}
+		err := s.UpsertMessage(ctx, msg, database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+		msgIDs[len(msgIDs)-1-i] = msg.Header.ID
+	}
+
+	// Page through newest first, two at a time
+	var cursor *database.Cursor
+	var paged []*fftypes.UUID
+	for {
+		filter := database.MessageQueryFactory.NewFilter(ctx).And()
+		filter.Limit(2)
+		err := database.ApplyCursor(ctx, filter, []string{"sequence"}, cursor)
+		assert.NoError(t, err)
+		msgs, _, err := s.GetMessages(ctx, "ns1", filter, nil)
+		assert.NoError(t, err)
+		if len(msgs) == 0 {
+			break
+		}
+		for _, msg := range msgs {
+			paged = append(paged, msg.Header.ID)
+		}
+		cursor = database.NextCursor([]string{"sequence"}, msgs[len(msgs)-1])
+	}
+	assert.Equal(t, msgIDs, paged)
+}
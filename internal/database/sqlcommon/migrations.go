@@ -0,0 +1,198 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"io"
+	"strconv"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// migrationsTable is the table in which golang-migrate records the schema version, for all providers
+const migrationsTable = "schema_migrations"
+
+// SchemaStatus reads the schema version with a plain query, rather than through the migration driver,
+// as the migration drivers hold on to (and for SQLite close) the connection they are given
+func (s *SQLCommon) SchemaStatus(ctx context.Context) (*core.DatabaseSchemaStatus, error) {
+	rows, _, err := s.Query(ctx, migrationsTable, sq.Select("version", "dirty").From(migrationsTable))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var version int64
+	var dirty bool
+	if rows.Next() {
+		if err := rows.Scan(&version, &dirty); err != nil {
+			return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, migrationsTable)
+		}
+	}
+
+	src, err := s.openMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.schemaStatus(ctx, src, uint(version), dirty, false)
+}
+
+func (s *SQLCommon) MigrationStatus(ctx context.Context) (*core.DatabaseSchemaStatus, error) {
+	m, src, err := s.newMigrate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	version, dirty, err := s.migrationVersion(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	return s.schemaStatus(ctx, src, version, dirty, true)
+}
+
+func (s *SQLCommon) MigrateUp(ctx context.Context) ([]*core.DatabaseMigration, error) {
+	m, src, err := s.newMigrate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	version, dirty, err := s.migrationVersion(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	status, err := s.schemaStatus(ctx, src, version, dirty, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBMigrationFailed)
+	}
+	return status.Pending, nil
+}
+
+func (s *SQLCommon) MigrateDown(ctx context.Context, steps int) ([]*core.DatabaseMigration, error) {
+	if steps < 1 {
+		return nil, i18n.NewError(ctx, coremsgs.MsgInvalidMigrationSteps, strconv.Itoa(steps))
+	}
+	m, src, err := s.newMigrate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	version, _, err := s.migrationVersion(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+
+	// Work back from the current version, so we can report which migrations are rolled back
+	var rolledBack []*core.DatabaseMigration
+	for v := version; v > 0 && len(rolledBack) < steps; {
+		migration, err := s.readMigration(ctx, src, v, false)
+		if err != nil {
+			return nil, err
+		}
+		rolledBack = append(rolledBack, migration)
+		if v, err = src.Prev(v); err != nil {
+			v = 0 // rolling back the first migration leaves an empty schema
+		}
+	}
+
+	if len(rolledBack) > 0 {
+		if err := m.Steps(-len(rolledBack)); err != nil {
+			return nil, i18n.WrapError(ctx, err, i18n.MsgDBMigrationFailed)
+		}
+	}
+	return rolledBack, nil
+}
+
+// openMigrations opens the same directory of migrations that is applied on startup when migrations.auto is set
+func (s *SQLCommon) openMigrations(ctx context.Context) (source.Driver, error) {
+	src, err := (&file.File{}).Open("file://" + s.migrationsDir)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgMigrationsReadFailed, s.migrationsDir)
+	}
+	return src, nil
+}
+
+func (s *SQLCommon) newMigrate(ctx context.Context) (*migrate.Migrate, source.Driver, error) {
+	src, err := s.openMigrations(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var m *migrate.Migrate
+	driver, err := s.provider.GetMigrationDriver(s.DB())
+	if err == nil {
+		m, err = migrate.NewWithInstance("file", src, s.provider.MigrationsDir(), driver)
+	}
+	if err != nil {
+		return nil, nil, i18n.WrapError(ctx, err, i18n.MsgDBMigrationFailed)
+	}
+	return m, src, nil
+}
+
+func (s *SQLCommon) migrationVersion(ctx context.Context, m *migrate.Migrate) (uint, bool, error) {
+	version, dirty, err := m.Version()
+	switch {
+	case err == migrate.ErrNilVersion:
+		return 0, false, nil // no migrations have been applied
+	case err != nil:
+		return 0, false, i18n.WrapError(ctx, err, i18n.MsgDBMigrationFailed)
+	}
+	return version, dirty, nil
+}
+
+func (s *SQLCommon) schemaStatus(ctx context.Context, src source.Driver, version uint, dirty, withSQL bool) (*core.DatabaseSchemaStatus, error) {
+	status := &core.DatabaseSchemaStatus{
+		Version: version,
+		Dirty:   dirty,
+	}
+	// The source signals the end of the list with an error
+	for v, err := src.First(); err == nil; v, err = src.Next(v) {
+		status.Latest = v
+		if v > version {
+			migration, err := s.readMigration(ctx, src, v, withSQL)
+			if err != nil {
+				return nil, err
+			}
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status, nil
+}
+
+func (s *SQLCommon) readMigration(ctx context.Context, src source.Driver, version uint, withSQL bool) (*core.DatabaseMigration, error) {
+	r, name, err := src.ReadUp(version)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgMigrationsReadFailed, s.migrationsDir)
+	}
+	defer r.Close()
+	migration := &core.DatabaseMigration{
+		Version: version,
+		Name:    name,
+	}
+	if withSQL {
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, coremsgs.MsgMigrationsReadFailed, s.migrationsDir)
+		}
+		migration.SQL = string(b)
+	}
+	return migration, nil
+}
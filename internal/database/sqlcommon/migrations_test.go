@@ -0,0 +1,268 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang-migrate/migrate/v4/database/stub"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+var testMigrations = []string{"widgets", "gadgets", "gizmos"}
+
+func writeTestMigration(t *testing.T, dir string, version int, name, up, down string) {
+	prefix := filepath.Join(dir, fmt.Sprintf("%06d_%s", version, name))
+	if up != "" {
+		assert.NoError(t, os.WriteFile(prefix+".up.sql", []byte(up), 0644))
+	}
+	if down != "" {
+		assert.NoError(t, os.WriteFile(prefix+".down.sql", []byte(down), 0644))
+	}
+}
+
+// newMigrationsTestProvider creates a SQLite database without applying migrations on startup,
+// against a directory of small test migrations
+func newMigrationsTestProvider(t *testing.T) (*sqliteGoTestProvider, string) {
+	dir := t.TempDir()
+	for i, table := range testMigrations {
+		writeTestMigration(t, dir, i+1, "create_"+table,
+			fmt.Sprintf("CREATE TABLE %s (seq INTEGER PRIMARY KEY);", table),
+			fmt.Sprintf("DROP TABLE %s;", table))
+	}
+
+	conf := config.RootSection("unittest.migrations")
+	tp := &sqliteGoTestProvider{
+		t:            t,
+		callbacks:    &databasemocks.Callbacks{},
+		capabilities: &database.Capabilities{},
+		config:       conf,
+	}
+	tp.SQLCommon.InitConfig(tp, tp.config)
+	tp.config.Set(SQLConfDatasourceURL, filepath.Join(dir, "test.db"))
+	tp.config.Set(SQLConfMigrationsDirectory, dir)
+	tp.config.Set(SQLConfMaxConnections, 1)
+	err := tp.Init(context.Background(), tp, tp.config, tp.capabilities)
+	assert.NoError(t, err)
+	return tp, dir
+}
+
+type versionErrorDriver struct {
+	stub.Stub
+}
+
+func (d *versionErrorDriver) Version() (int, bool, error) {
+	return 0, false, fmt.Errorf("pop")
+}
+
+type readErrorSource struct {
+	source.Driver
+}
+
+func (s *readErrorSource) ReadUp(version uint) (io.ReadCloser, string, error) {
+	return io.NopCloser(iotest.ErrReader(fmt.Errorf("pop"))), "broken", nil
+}
+
+func TestMigrationsUpDownStatus(t *testing.T) {
+	tp, _ := newMigrationsTestProvider(t)
+	defer tp.Close()
+	ctx := context.Background()
+
+	status, err := tp.MigrationStatus(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(0), status.Version)
+	assert.Equal(t, uint(3), status.Latest)
+	assert.False(t, status.Dirty)
+	assert.Len(t, status.Pending, 3)
+	assert.Equal(t, "create_widgets", status.Pending[0].Name)
+	assert.Equal(t, "CREATE TABLE widgets (seq INTEGER PRIMARY KEY);", status.Pending[0].SQL)
+
+	applied, err := tp.MigrateUp(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, applied, 3)
+	assert.Equal(t, uint(3), applied[2].Version)
+	assert.Empty(t, applied[2].SQL)
+
+	applied, err = tp.MigrateUp(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+
+	status, err = tp.SchemaStatus(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), status.Version)
+	assert.Equal(t, uint(3), status.Latest)
+	assert.Empty(t, status.Pending)
+
+	rolledBack, err := tp.MigrateDown(ctx, 2)
+	assert.NoError(t, err)
+	assert.Len(t, rolledBack, 2)
+	assert.Equal(t, "create_gizmos", rolledBack[0].Name)
+	assert.Equal(t, "create_gadgets", rolledBack[1].Name)
+
+	status, err = tp.SchemaStatus(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), status.Version)
+	assert.Len(t, status.Pending, 2)
+	assert.Empty(t, status.Pending[0].SQL)
+
+	rolledBack, err = tp.MigrateDown(ctx, 5)
+	assert.NoError(t, err)
+	assert.Len(t, rolledBack, 1)
+	assert.Equal(t, uint(1), rolledBack[0].Version)
+
+	rolledBack, err = tp.MigrateDown(ctx, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, rolledBack)
+
+	status, err = tp.SchemaStatus(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(0), status.Version)
+	assert.Len(t, status.Pending, 3)
+}
+
+func TestMigrateUpFailLeavesDirty(t *testing.T) {
+	tp, dir := newMigrationsTestProvider(t)
+	defer tp.Close()
+	ctx := context.Background()
+	writeTestMigration(t, dir, 4, "broken", "NOT SQL;", "NOT SQL;")
+
+	_, err := tp.MigrateUp(ctx)
+	assert.Regexp(t, "FF00184", err)
+
+	status, err := tp.MigrationStatus(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(4), status.Version)
+	assert.True(t, status.Dirty)
+
+	_, err = tp.MigrateUp(ctx)
+	assert.Regexp(t, "FF00184.*Dirty", err)
+
+	_, err = tp.MigrateDown(ctx, 1)
+	assert.Regexp(t, "FF00184.*Dirty", err)
+}
+
+func TestMigrationsMissingUpFile(t *testing.T) {
+	tp, dir := newMigrationsTestProvider(t)
+	defer tp.Close()
+	ctx := context.Background()
+
+	_, err := tp.MigrateUp(ctx)
+	assert.NoError(t, err)
+
+	writeTestMigration(t, dir, 4, "downonly", "", "SELECT 1;")
+	_, err = tp.MigrationStatus(ctx)
+	assert.Regexp(t, "FF10507", err)
+	_, err = tp.MigrateUp(ctx)
+	assert.Regexp(t, "FF10507", err)
+
+	assert.NoError(t, os.Remove(filepath.Join(dir, "000003_create_gizmos.up.sql")))
+	_, err = tp.MigrateDown(ctx, 1)
+	assert.Regexp(t, "FF10507", err)
+}
+
+func TestMigrateDownInvalidSteps(t *testing.T) {
+	s := &SQLCommon{}
+	_, err := s.MigrateDown(context.Background(), 0)
+	assert.Regexp(t, "FF10511", err)
+}
+
+func TestMigrationsBadDirectory(t *testing.T) {
+	tp, dir := newMigrationsTestProvider(t)
+	defer tp.Close()
+	ctx := context.Background()
+	tp.migrationsDir = filepath.Join(dir, "missing")
+
+	_, err := tp.MigrationStatus(ctx)
+	assert.Regexp(t, "FF10507", err)
+	_, err = tp.MigrateUp(ctx)
+	assert.Regexp(t, "FF10507", err)
+	_, err = tp.MigrateDown(ctx, 1)
+	assert.Regexp(t, "FF10507", err)
+}
+
+func TestMigrationsDriverFail(t *testing.T) {
+	mp, _ := newMockProvider().init()
+	mp.migrationsDir = t.TempDir()
+	mp.getMigrationDriverError = fmt.Errorf("pop")
+	ctx := context.Background()
+
+	_, err := mp.MigrationStatus(ctx)
+	assert.Regexp(t, "FF00184.*pop", err)
+	_, err = mp.MigrateUp(ctx)
+	assert.Regexp(t, "FF00184.*pop", err)
+	_, err = mp.MigrateDown(ctx, 1)
+	assert.Regexp(t, "FF00184.*pop", err)
+}
+
+func TestMigrationsVersionFail(t *testing.T) {
+	mp, _ := newMockProvider().init()
+	mp.migrationsDir = t.TempDir()
+	mp.migrationDriver = &versionErrorDriver{}
+	ctx := context.Background()
+
+	_, err := mp.MigrationStatus(ctx)
+	assert.Regexp(t, "FF00184.*pop", err)
+	_, err = mp.MigrateUp(ctx)
+	assert.Regexp(t, "FF00184.*pop", err)
+	_, err = mp.MigrateDown(ctx, 1)
+	assert.Regexp(t, "FF00184.*pop", err)
+}
+
+func TestSchemaStatusQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.SchemaStatus(context.Background())
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSchemaStatusScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow("bad", false))
+	_, err := s.SchemaStatus(context.Background())
+	assert.Regexp(t, "FF00182", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSchemaStatusBadDirectory(t *testing.T) {
+	s, mock := newMockProvider().init()
+	s.migrationsDir = filepath.Join(t.TempDir(), "missing")
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(1, false))
+	_, err := s.SchemaStatus(context.Background())
+	assert.Regexp(t, "FF10507", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReadMigrationReadFail(t *testing.T) {
+	tp, _ := newMigrationsTestProvider(t)
+	defer tp.Close()
+	src, err := tp.openMigrations(context.Background())
+	assert.NoError(t, err)
+	_, err = tp.readMigration(context.Background(), &readErrorSource{Driver: src}, 1, true)
+	assert.Regexp(t, "FF10507.*pop", err)
+}
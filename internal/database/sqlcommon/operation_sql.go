@@ -194,3 +194,7 @@ func (s *SQLCommon) UpdateOperation(ctx context.Context, ns string, id *fftypes.
 
 	return s.CommitTx(ctx, tx, autoCommit)
 }
+
+func (s *SQLCommon) DeleteOperations(ctx context.Context, namespace string, filter ffapi.Filter, guards *database.RetentionGuards) (count int64, err error) {
+	return s.deleteMatching(ctx, operationsTable, namespace, filter, opFilterFieldMap, guards, nil)
+}
@@ -20,13 +20,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestOperationE2EWithDB(t *testing.T) {
@@ -228,3 +231,98 @@ func TestOperationUpdateFail(t *testing.T) {
 	err := s.UpdateOperation(context.Background(), "ns1", fftypes.NewUUID(), u)
 	assert.Regexp(t, "FF00178", err)
 }
+
+func TestDeleteOperations(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionOperations, core.ChangeEventTypeCreated, "ns1", mock.Anything).Return()
+	op1 := &core.Operation{ID: fftypes.NewUUID(), Namespace: "ns1", Transaction: fftypes.NewUUID(), Type: core.OpTypeBlockchainPinBatch, Status: core.OpStatusSucceeded, Created: fftypes.Now()}
+	op2 := &core.Operation{ID: fftypes.NewUUID(), Namespace: "ns1", Transaction: fftypes.NewUUID(), Type: core.OpTypeBlockchainPinBatch, Status: core.OpStatusPending, Created: fftypes.Now()}
+	op3 := &core.Operation{ID: fftypes.NewUUID(), Namespace: "ns1", Transaction: fftypes.NewUUID(), Type: core.OpTypeBlockchainPinBatch, Status: core.OpStatusFailed, Created: fftypes.Now()}
+	assert.NoError(t, s.InsertOperation(ctx, op1))
+	assert.NoError(t, s.InsertOperation(ctx, op2))
+	assert.NoError(t, s.InsertOperation(ctx, op3))
+
+	// The transaction of the failed operation is referenced by an event that can be replayed from a dead letter
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionDeadLetters, core.ChangeEventTypeCreated, "ns1", mock.Anything).Return()
+	event := &core.Event{ID: fftypes.NewUUID(), Namespace: "ns1", Type: core.EventTypeTransactionSubmitted, Transaction: op3.Transaction, Created: fftypes.Now()}
+	assert.NoError(t, s.InsertEvent(ctx, event))
+	assert.NoError(t, s.InsertDeadLetter(ctx, &core.DeadLetter{
+		ID:           fftypes.NewUUID(),
+		Namespace:    "ns1",
+		Subscription: core.SubscriptionRef{ID: fftypes.NewUUID(), Name: "sub1"},
+		Event:        event.ID,
+		Created:      fftypes.Now(),
+	}))
+
+	fb := database.OperationQueryFactory.NewFilter(ctx)
+	count, err := s.DeleteOperations(ctx, "ns1", fb.And(), &database.RetentionGuards{EventSequence: event.Sequence})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	op, err := s.GetOperationByID(ctx, "ns1", op1.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, op)
+	op, err = s.GetOperationByID(ctx, "ns1", op2.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, op)
+	op, err = s.GetOperationByID(ctx, "ns1", op3.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, op)
+}
+
+func TestGetOperationsByCursor(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionOperations, core.ChangeEventTypeCreated, "ns1", mock.Anything).Return()
+	// Operations created at the same time are ordered by ID
+	now := time.Now()
+	ops := make([]*core.Operation, 5)
+	for i := range ops {
+		created := fftypes.FFTime(now.Add(time.Duration(i/2) * time.Second))
+		ops[i] = &core.Operation{
+			ID:          fftypes.NewUUID(),
+			Namespace:   "ns1",
+			Transaction: fftypes.NewUUID(),
+			Type:        core.OpTypeBlockchainPinBatch,
+			Status:      core.OpStatusSucceeded,
+			Created:     &created,
+		}
+		assert.NoError(t, s.InsertOperation(ctx, ops[i]))
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].Created.Equal(ops[j].Created) {
+			return ops[i].ID.String() < ops[j].ID.String()
+		}
+		return ops[i].Created.Time().Before(*ops[j].Created.Time())
+	})
+	opIDs := make([]*fftypes.UUID, len(ops))
+	for i, op := range ops {
+		opIDs[i] = op.ID
+	}
+
+	// Page through oldest first, two at a time
+	var cursor *database.Cursor
+	var paged []*fftypes.UUID
+	for {
+		filter := database.OperationQueryFactory.NewFilter(ctx).And()
+		filter.Limit(2).Ascending()
+		err := database.ApplyCursor(ctx, filter, []string{"created", "id"}, cursor)
+		assert.NoError(t, err)
+		page, _, err := s.GetOperations(ctx, "ns1", filter)
+		assert.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+		for _, op := range page {
+			paged = append(paged, op.ID)
+		}
+		cursor = database.NextCursor([]string{"created", "id"}, page[len(page)-1])
+	}
+	assert.Equal(t, opIDs, paged)
+}
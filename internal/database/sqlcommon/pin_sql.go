@@ -226,3 +226,7 @@ func (s *SQLCommon) UpdatePins(ctx context.Context, namespace string, filter ffa
 
 	return s.CommitTx(ctx, tx, autoCommit)
 }
+
+func (s *SQLCommon) DeletePins(ctx context.Context, namespace string, filter ffapi.Filter, guards *database.RetentionGuards) (count int64, err error) {
+	return s.deleteMatching(ctx, pinsTable, namespace, filter, pinFilterFieldMap, guards, nil)
+}
@@ -19,6 +19,7 @@ package sqlcommon
 import (
 	"context"
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -253,3 +254,28 @@ func TestUpdatePinsBadUpdate(t *testing.T) {
 	err := s.UpdatePins(ctx, "ns1", database.PinQueryFactory.NewFilter(ctx).Eq("bad", 1), database.PinQueryFactory.NewUpdate(ctx).Set("dispatched", true))
 	assert.Regexp(t, "FF00142", err)
 }
+
+func TestDeletePins(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("OrderedCollectionNSEvent", database.CollectionPins, core.ChangeEventTypeCreated, "ns1", mock.Anything).Return()
+	batchID := fftypes.NewUUID()
+	pins := []*core.Pin{
+		{Namespace: "ns1", Hash: fftypes.NewRandB32(), Batch: batchID, BatchHash: fftypes.NewRandB32(), Index: 0, Dispatched: true, Created: fftypes.Now()},
+		{Namespace: "ns1", Hash: fftypes.NewRandB32(), Batch: batchID, BatchHash: fftypes.NewRandB32(), Index: 1, Dispatched: false, Created: fftypes.Now()},
+	}
+	err := s.InsertPins(ctx, pins)
+	assert.NoError(t, err)
+
+	fb := database.PinQueryFactory.NewFilter(ctx)
+	count, err := s.DeletePins(ctx, "ns1", fb.And(), &database.RetentionGuards{EventSequence: math.MaxInt64, PinSequence: math.MaxInt64})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	remaining, _, err := s.GetPins(ctx, "ns1", fb.And())
+	assert.NoError(t, err)
+	assert.Len(t, remaining, 1)
+	assert.False(t, remaining[0].Dispatched)
+}
@@ -44,6 +44,7 @@ type mockProvider struct {
 	fakePSQLInsert          bool
 	openError               error
 	getMigrationDriverError error
+	migrationDriver         migratedb.Driver
 	individualSort          bool
 	multiRowInsert          bool
 }
@@ -105,5 +106,5 @@ func (mp *mockProvider) Open(url string) (*sql.DB, error) {
 }
 
 func (mp *mockProvider) GetMigrationDriver(db *sql.DB) (migratedb.Driver, error) {
-	return nil, mp.getMigrationDriverError
+	return mp.migrationDriver, mp.getMigrationDriverError
 }
@@ -19,6 +19,7 @@ package sqlcommon
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -105,3 +106,12 @@ func (tp *sqliteGoTestProvider) Open(url string) (*sql.DB, error) {
 func (tp *sqliteGoTestProvider) GetMigrationDriver(db *sql.DB) (migratedb.Driver, error) {
 	return sqlite3.WithInstance(db, &sqlite3.Config{})
 }
+
+func (tp *sqliteGoTestProvider) JSONPathText(column string, path []string) string {
+	return SQLiteJSONPathText(column, path)
+}
+
+func (tp *sqliteGoTestProvider) JSONPathIndexCondition(column string, path []string, value string) sq.Sqlizer {
+	// Exercises the index condition, with a condition that every row matches
+	return sq.Expr(fmt.Sprintf("json_valid(%s)", column))
+}
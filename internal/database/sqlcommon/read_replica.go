@@ -0,0 +1,134 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/dbsql"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// InitReadReplica opens the optional read-only datasource, if one is configured.
+// Queries made with a context from database.WithReadReplica are then served by the replica,
+// unless they are part of a transaction.
+func (s *SQLCommon) InitReadReplica(ctx context.Context, provider dbsql.Provider, config config.Section) (err error) {
+	url := config.GetString(SQLConfDatasourceURL)
+	if url == "" {
+		return nil
+	}
+	if s.replica, err = provider.Open(url); err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgDBInitFailed)
+	}
+	connLimit := config.GetInt(SQLConfMaxConnections)
+	if connLimit > 0 {
+		s.replica.SetMaxOpenConns(connLimit)
+		s.replica.SetConnMaxIdleTime(config.GetDuration(SQLConfMaxConnIdleTime))
+		maxIdleConns := config.GetInt(SQLConfMaxIdleConns)
+		if maxIdleConns <= 0 {
+			maxIdleConns = connLimit
+		}
+		s.replica.SetMaxIdleConns(maxIdleConns)
+		s.replica.SetConnMaxLifetime(config.GetDuration(SQLConfMaxConnLifetime))
+	}
+	log.L(ctx).Infof("Read replica enabled for %s queries", provider.Name())
+	return nil
+}
+
+func (s *SQLCommon) useReplica(ctx context.Context, tx *dbsql.TXWrapper) bool {
+	return s.replica != nil && tx == nil && database.ReadReplicaAllowed(ctx)
+}
+
+// BeginOrUseTx ensures all queries in a transaction use the primary, so they see the writes of the transaction
+func (s *SQLCommon) BeginOrUseTx(ctx context.Context) (context.Context, *dbsql.TXWrapper, bool, error) {
+	return s.Database.BeginOrUseTx(database.WithPrimary(ctx))
+}
+
+func (s *SQLCommon) RunAsGroup(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.Database.RunAsGroup(database.WithPrimary(ctx), fn)
+}
+
+func (s *SQLCommon) Query(ctx context.Context, table string, q sq.SelectBuilder) (*sql.Rows, *dbsql.TXWrapper, error) {
+	return s.QueryTx(ctx, table, nil, q)
+}
+
+func (s *SQLCommon) QueryTx(ctx context.Context, table string, tx *dbsql.TXWrapper, q sq.SelectBuilder) (*sql.Rows, *dbsql.TXWrapper, error) {
+	if !s.useReplica(ctx, tx) {
+		return s.Database.QueryTx(ctx, table, tx, q)
+	}
+	rows, err := s.replicaQuery(ctx, table, q)
+	return rows, nil, err
+}
+
+func (s *SQLCommon) CountQuery(ctx context.Context, table string, tx *dbsql.TXWrapper, fop sq.Sqlizer, countExpr string) (count int64, err error) {
+	if !s.useReplica(ctx, tx) {
+		return s.Database.CountQuery(ctx, table, tx, fop, countExpr)
+	}
+	count = -1
+	if countExpr == "" {
+		countExpr = "*"
+	}
+	rows, err := s.replicaQuery(ctx, table, sq.Select(fmt.Sprintf("COUNT(%s)", countExpr)).From(table).Where(fop))
+	if err != nil {
+		return count, err
+	}
+	defer rows.Close()
+	if rows.Next() {
+		if err = rows.Scan(&count); err != nil {
+			return count, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, table)
+		}
+	}
+	return count, nil
+}
+
+func (s *SQLCommon) QueryRes(ctx context.Context, table string, tx *dbsql.TXWrapper, fop sq.Sqlizer, fi *ffapi.FilterInfo) *ffapi.FilterResult {
+	fr := &ffapi.FilterResult{}
+	if fi.Count {
+		count, err := s.CountQuery(ctx, table, tx, fop, fi.CountExpr)
+		if err != nil {
+			// Log, but continue
+			log.L(ctx).Warnf("Unable to return count for query: %s", err)
+		}
+		fr.TotalCount = &count // could be -1 if the count extract fails - we still return the result
+	}
+	return fr
+}
+
+func (s *SQLCommon) replicaQuery(ctx context.Context, table string, q sq.SelectBuilder) (*sql.Rows, error) {
+	l := log.L(ctx)
+	sqlQuery, args, err := q.PlaceholderFormat(s.Features().PlaceholderFormat).ToSql()
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBQueryBuildFailed)
+	}
+	l.Debugf(`SQL-> replica query %s`, table)
+	l.Tracef(`SQL-> replica query: %s (args: %+v)`, sqlQuery, args)
+	rows, err := s.replica.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		l.Errorf(`SQL replica query failed: %s sql=[ %s ]`, err, sqlQuery)
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBQueryFailed)
+	}
+	l.Debugf(`SQL<- replica query %s`, table)
+	return rows, nil
+}
@@ -0,0 +1,145 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func newReplicaMockProvider(t *testing.T) (*mockProvider, sqlmock.Sqlmock, sqlmock.Sqlmock) {
+	s, primary := newMockProvider().init()
+	conf := config.RootSection("unittest.replica")
+	s.InitReadReplicaConfig(conf)
+	conf.Set(SQLConfDatasourceURL, "replica")
+	conf.Set(SQLConfMaxConnections, 5)
+	conf.Set(SQLConfMaxIdleConns, 1)
+	replicaDB, replica, _ := sqlmock.New()
+	s.mockDB = replicaDB
+	err := s.InitReadReplica(context.Background(), s, conf)
+	assert.NoError(t, err)
+	return s, primary, replica
+}
+
+func TestInitReadReplicaNotConfigured(t *testing.T) {
+	s, _ := newMockProvider().init()
+	conf := config.RootSection("unittest.replica")
+	s.InitReadReplicaConfig(conf)
+	err := s.InitReadReplica(context.Background(), s, conf)
+	assert.NoError(t, err)
+	assert.Nil(t, s.replica)
+}
+
+func TestInitReadReplicaDefaultIdleConns(t *testing.T) {
+	s, _ := newMockProvider().init()
+	conf := config.RootSection("unittest.replica")
+	s.InitReadReplicaConfig(conf)
+	conf.Set(SQLConfDatasourceURL, "replica")
+	conf.Set(SQLConfMaxConnections, 5)
+	err := s.InitReadReplica(context.Background(), s, conf)
+	assert.NoError(t, err)
+	assert.NotNil(t, s.replica)
+}
+
+func TestInitReadReplicaOpenFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	conf := config.RootSection("unittest.replica")
+	s.InitReadReplicaConfig(conf)
+	conf.Set(SQLConfDatasourceURL, "replica")
+	s.openError = fmt.Errorf("pop")
+	err := s.InitReadReplica(context.Background(), s, conf)
+	assert.Regexp(t, "FF00173.*pop", err)
+}
+
+func TestGetMessagesFromReplica(t *testing.T) {
+	s, primary, replica := newReplicaMockProvider(t)
+	ctx := database.WithReadReplica(context.Background())
+	replica.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(msgColumns))
+	replica.ExpectQuery("SELECT COUNT.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	f := database.MessageQueryFactory.NewFilter(ctx).And().Count(true)
+	msgs, res, err := s.GetMessages(ctx, "ns1", f, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, msgs)
+	assert.Equal(t, int64(0), *res.TotalCount)
+	assert.NoError(t, primary.ExpectationsWereMet())
+	assert.NoError(t, replica.ExpectationsWereMet())
+}
+
+func TestGetMessagesPrimaryWithoutReplicaContext(t *testing.T) {
+	s, primary, replica := newReplicaMockProvider(t)
+	primary.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(msgColumns))
+	f := database.MessageQueryFactory.NewFilter(context.Background()).And()
+	_, _, err := s.GetMessages(context.Background(), "ns1", f, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, primary.ExpectationsWereMet())
+	assert.NoError(t, replica.ExpectationsWereMet())
+}
+
+func TestRunAsGroupUsesPrimary(t *testing.T) {
+	s, primary, replica := newReplicaMockProvider(t)
+	ctx := database.WithReadReplica(context.Background())
+	primary.ExpectBegin()
+	primary.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(msgColumns))
+	primary.ExpectCommit()
+	err := s.RunAsGroup(ctx, func(ctx context.Context) error {
+		f := database.MessageQueryFactory.NewFilter(ctx).And()
+		_, _, err := s.GetMessages(ctx, "ns1", f, nil)
+		return err
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, primary.ExpectationsWereMet())
+	assert.NoError(t, replica.ExpectationsWereMet())
+}
+
+func TestReplicaQueryBuildFail(t *testing.T) {
+	s, _, _ := newReplicaMockProvider(t)
+	ctx := database.WithReadReplica(context.Background())
+	_, _, err := s.QueryTx(ctx, "table1", nil, sq.Select())
+	assert.Regexp(t, "FF10113", err)
+}
+
+func TestReplicaQueryFail(t *testing.T) {
+	s, _, replica := newReplicaMockProvider(t)
+	ctx := database.WithReadReplica(context.Background())
+	replica.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, _, err := s.Query(ctx, "table1", sq.Select("*").From("table1"))
+	assert.Regexp(t, "FF10115.*pop", err)
+}
+
+func TestReplicaCountQueryFail(t *testing.T) {
+	s, _, replica := newReplicaMockProvider(t)
+	ctx := database.WithReadReplica(context.Background())
+	replica.ExpectQuery("SELECT COUNT.*").WillReturnError(fmt.Errorf("pop"))
+	res := s.QueryRes(ctx, "table1", nil, sq.Eq{}, &ffapi.FilterInfo{Count: true})
+	assert.Equal(t, int64(-1), *res.TotalCount)
+}
+
+func TestReplicaCountQueryScanFail(t *testing.T) {
+	s, _, replica := newReplicaMockProvider(t)
+	ctx := database.WithReadReplica(context.Background())
+	replica.ExpectQuery("SELECT COUNT.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow("!int"))
+	_, err := s.CountQuery(ctx, "table1", nil, sq.Eq{}, "")
+	assert.Regexp(t, "FF10121", err)
+}
@@ -0,0 +1,130 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"math"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// aggregatorOffsetName must match the offset the aggregator in the events package uses to track its progress through the pins
+const aggregatorOffsetName = "ff_aggregator"
+
+func (s *SQLCommon) GetRetentionGuards(ctx context.Context, namespace string) (*database.RetentionGuards, error) {
+	g := &database.RetentionGuards{PinSequence: -1}
+
+	// Events are needed until every durable subscription has moved past them - a subscription without an offset has
+	// not started, so it might need any event
+	query := sq.Select().
+		Column("COALESCE(MIN(COALESCE(o.current, -1)), ?)", int64(math.MaxInt64)).
+		From(subscriptionsTable+" s").
+		LeftJoin(offsetsTable+" o ON o.otype = ? AND o.name = CAST(s.id AS TEXT)", core.OffsetTypeSubscription)
+	if namespace != "" {
+		query = query.Where(sq.Eq{"s.namespace": namespace})
+	}
+	rows, _, err := s.Query(ctx, subscriptionsTable, query)
+	if err != nil {
+		return nil, err
+	}
+	rows.Next()
+	err = rows.Scan(&g.EventSequence)
+	rows.Close()
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, subscriptionsTable)
+	}
+
+	// Pins are needed until the aggregator has processed them
+	offset, err := s.GetOffset(ctx, core.OffsetTypeAggregator, aggregatorOffsetName)
+	if err != nil {
+		return nil, err
+	}
+	if offset != nil {
+		g.PinSequence = offset.Current
+	}
+	return g, nil
+}
+
+// RetentionNeeded returns the condition matching the rows of a table that are still needed by the node, so must not
+// be deleted. As well as the rows within the guards, and those still being processed, this includes any row that is
+// referenced (directly, or by its transaction) by an event that is still to be delivered, or that can be replayed
+// from a dead letter. An empty namespace applies the condition across all namespaces.
+func (s *SQLCommon) RetentionNeeded(table, namespace string, g *database.RetentionGuards) sq.Sqlizer {
+	inNamespace := func(condition sq.Sqlizer) sq.Sqlizer {
+		if namespace == "" {
+			return condition
+		}
+		return sq.And{sq.Eq{"namespace": namespace}, condition}
+	}
+	// Every condition must be true or false, and never null, so that rows that are not needed can be matched with NOT
+	deadLetters := sq.Select("event_id").From(deadlettersTable)
+	if namespace != "" {
+		deadLetters = deadLetters.Where(sq.Eq{"namespace": namespace})
+	}
+	pendingEvent := sq.Or{
+		sq.Gt{s.SequenceColumn(): g.EventSequence},
+		sq.Expr("id IN (?)", deadLetters),
+	}
+	referencedBy := func(column string) sq.SelectBuilder {
+		return sq.Select(column).From(eventsTable).Where(inNamespace(sq.And{sq.NotEq{column: nil}, pendingEvent}))
+	}
+	referenced := sq.Or{
+		sq.Expr("id IN (?)", referencedBy("ref")),
+		sq.And{sq.NotEq{"tx_id": nil}, sq.Expr("tx_id IN (?)", referencedBy("tx_id"))},
+	}
+
+	switch table {
+	case eventsTable:
+		return pendingEvent
+	case pinsTable:
+		return sq.Or{
+			sq.Eq{"dispatched": false},
+			sq.Gt{s.SequenceColumn(): g.PinSequence},
+		}
+	case messagesTable:
+		return sq.Or{
+			sq.NotEq{"state": []interface{}{core.MessageStateConfirmed, core.MessageStateRejected}},
+			referenced,
+		}
+	case operationsTable:
+		return sq.Or{
+			sq.NotEq{"opstatus": []interface{}{core.OpStatusSucceeded, core.OpStatusFailed}},
+			referenced,
+		}
+	case batchesTable:
+		// A batch is also needed while the aggregator might still process its pins, or any of its messages are in flight
+		return sq.Or{
+			sq.Eq{"confirmed": nil},
+			referenced,
+			sq.Expr("id IN (?)", sq.Select("batch_id").From(pinsTable).Where(inNamespace(sq.And{
+				sq.NotEq{"batch_id": nil},
+				sq.Or{sq.Eq{"dispatched": false}, sq.Gt{s.SequenceColumn(): g.PinSequence}},
+			}))),
+			sq.Expr("id IN (?)", sq.Select("batch_id").From(messagesTable).Where(inNamespace(sq.And{
+				sq.NotEq{"batch_id": nil},
+				sq.NotEq{"state": []interface{}{core.MessageStateConfirmed, core.MessageStateRejected}},
+			}))),
+		}
+	default: // blockchain events
+		return referenced
+	}
+}
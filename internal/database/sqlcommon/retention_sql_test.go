@@ -0,0 +1,103 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetRetentionGuardsWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// No subscriptions, and the aggregator has not started
+	g, err := s.GetRetentionGuards(ctx, "ns1")
+	assert.NoError(t, err)
+	assert.Equal(t, &database.RetentionGuards{EventSequence: math.MaxInt64, PinSequence: -1}, g)
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionSubscriptions, core.ChangeEventTypeCreated, mock.Anything, mock.Anything).Return()
+	subs := []*core.Subscription{
+		{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"}, Created: fftypes.Now()},
+		{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub2"}, Created: fftypes.Now()},
+		{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns2", Name: "sub3"}, Created: fftypes.Now()},
+	}
+	for _, sub := range subs {
+		assert.NoError(t, s.UpsertSubscription(ctx, sub, false))
+	}
+	offsets := []*core.Offset{
+		{Type: core.OffsetTypeSubscription, Name: subs[0].ID.String(), Current: 20},
+		{Type: core.OffsetTypeSubscription, Name: subs[1].ID.String(), Current: 10},
+		{Type: core.OffsetTypeAggregator, Name: aggregatorOffsetName, Current: 5},
+	}
+	for _, offset := range offsets {
+		assert.NoError(t, s.UpsertOffset(ctx, offset, false))
+	}
+
+	g, err = s.GetRetentionGuards(ctx, "ns1")
+	assert.NoError(t, err)
+	assert.Equal(t, &database.RetentionGuards{EventSequence: 10, PinSequence: 5}, g)
+
+	// The subscription in ns2 has not started, so across all namespaces it might need any event
+	g, err = s.GetRetentionGuards(ctx, "")
+	assert.NoError(t, err)
+	assert.Equal(t, &database.RetentionGuards{EventSequence: -1, PinSequence: 5}, g)
+}
+
+func TestGetRetentionGuardsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetRetentionGuards(context.Background(), "ns1")
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRetentionGuardsScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow("not a number"))
+	_, err := s.GetRetentionGuards(context.Background(), "ns1")
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRetentionGuardsOffsetFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(10))
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetRetentionGuards(context.Background(), "ns1")
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetentionNeededAllNamespaces(t *testing.T) {
+	s, _ := newMockProvider().init()
+	g := &database.RetentionGuards{EventSequence: 10, PinSequence: 5}
+	sql, args, err := s.RetentionNeeded(eventsTable, "", g).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(seq > ? OR id IN (SELECT event_id FROM deadletters))", sql)
+	assert.Equal(t, []interface{}{int64(10)}, args)
+}
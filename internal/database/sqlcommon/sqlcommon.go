@@ -18,10 +18,16 @@ package sqlcommon
 
 import (
 	"context"
+	"database/sql"
 
+	sq "github.com/Masterminds/squirrel"
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/dbsql"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/encryption"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
 
@@ -33,50 +39,68 @@ type SQLCommon struct {
 	dbsql.Database
 	capabilities *database.Capabilities
 	callbacks    callbacks
+	replica      *sql.DB               // optional
+	encryptor    *encryption.Encryptor // optional
+	jsonQuery    JSONQueryProvider     // optional
+	partitioned  map[string]bool
+
+	provider      dbsql.Provider
+	migrationsDir string
 }
 
 type callbacks struct {
 	handlers map[string]database.Callbacks
+	forward  func(ce *ChangeEvent)
 }
 
 func (cb *callbacks) OrderedUUIDCollectionNSEvent(resType database.OrderedUUIDCollectionNS, eventType core.ChangeEventType, ns string, id *fftypes.UUID, sequence int64) {
-	if cb, ok := cb.handlers[ns]; ok {
-		cb.OrderedUUIDCollectionNSEvent(resType, eventType, ns, id, sequence)
-	}
-	if cb, ok := cb.handlers[database.GlobalHandler]; ok {
-		cb.OrderedUUIDCollectionNSEvent(resType, eventType, ns, id, sequence)
-	}
+	cb.emit(&ChangeEvent{Kind: ChangeKindOrderedUUID, Collection: string(resType), Type: eventType, Namespace: ns, ID: id, Sequence: sequence})
 }
 
 func (cb *callbacks) OrderedCollectionNSEvent(resType database.OrderedCollectionNS, eventType core.ChangeEventType, ns string, sequence int64) {
-	if cb, ok := cb.handlers[ns]; ok {
-		cb.OrderedCollectionNSEvent(resType, eventType, ns, sequence)
-	}
-	if cb, ok := cb.handlers[database.GlobalHandler]; ok {
-		cb.OrderedCollectionNSEvent(resType, eventType, ns, sequence)
-	}
+	cb.emit(&ChangeEvent{Kind: ChangeKindOrdered, Collection: string(resType), Type: eventType, Namespace: ns, Sequence: sequence})
 }
 
 func (cb *callbacks) UUIDCollectionNSEvent(resType database.UUIDCollectionNS, eventType core.ChangeEventType, ns string, id *fftypes.UUID) {
-	if cb, ok := cb.handlers[ns]; ok {
-		cb.UUIDCollectionNSEvent(resType, eventType, ns, id)
-	}
-	if cb, ok := cb.handlers[database.GlobalHandler]; ok {
-		cb.UUIDCollectionNSEvent(resType, eventType, ns, id)
-	}
+	cb.emit(&ChangeEvent{Kind: ChangeKindUUID, Collection: string(resType), Type: eventType, Namespace: ns, ID: id})
 }
 
 func (cb *callbacks) HashCollectionNSEvent(resType database.HashCollectionNS, eventType core.ChangeEventType, ns string, hash *fftypes.Bytes32) {
-	if cb, ok := cb.handlers[ns]; ok {
-		cb.HashCollectionNSEvent(resType, eventType, ns, hash)
+	cb.emit(&ChangeEvent{Kind: ChangeKindHash, Collection: string(resType), Type: eventType, Namespace: ns, Hash: hash})
+}
+
+func (cb *callbacks) emit(ce *ChangeEvent) {
+	cb.dispatch(ce)
+	if cb.forward != nil {
+		cb.forward(ce)
 	}
-	if cb, ok := cb.handlers[database.GlobalHandler]; ok {
-		cb.HashCollectionNSEvent(resType, eventType, ns, hash)
+}
+
+func (cb *callbacks) dispatch(ce *ChangeEvent) {
+	for _, key := range []string{ce.Namespace, database.GlobalHandler} {
+		if cb, ok := cb.handlers[key]; ok {
+			switch ce.Kind {
+			case ChangeKindOrderedUUID:
+				cb.OrderedUUIDCollectionNSEvent(database.OrderedUUIDCollectionNS(ce.Collection), ce.Type, ce.Namespace, ce.ID, ce.Sequence)
+			case ChangeKindOrdered:
+				cb.OrderedCollectionNSEvent(database.OrderedCollectionNS(ce.Collection), ce.Type, ce.Namespace, ce.Sequence)
+			case ChangeKindUUID:
+				cb.UUIDCollectionNSEvent(database.UUIDCollectionNS(ce.Collection), ce.Type, ce.Namespace, ce.ID)
+			case ChangeKindHash:
+				cb.HashCollectionNSEvent(database.HashCollectionNS(ce.Collection), ce.Type, ce.Namespace, ce.Hash)
+			}
+		}
 	}
 }
 
 func (s *SQLCommon) Init(ctx context.Context, provider dbsql.Provider, config config.Section, capabilities *database.Capabilities) (err error) {
 	s.capabilities = capabilities
+	s.provider = provider
+	s.migrationsDir = config.GetString(SQLConfMigrationsDirectory)
+	s.jsonQuery, _ = provider.(JSONQueryProvider)
+	if s.encryptor, err = encryption.NewEncryptor(ctx, config.SubSection(SQLConfEncryption)); err != nil {
+		return err
+	}
 	return s.Database.Init(ctx, provider, config)
 }
 
@@ -88,3 +112,79 @@ func (s *SQLCommon) SetHandler(namespace string, handler database.Callbacks) {
 }
 
 func (s *SQLCommon) Capabilities() *database.Capabilities { return s.capabilities }
+
+// SetPartitionedTables records the tables that the provider has partitioned. A unique index cannot span
+// the partitions of a table, so uniqueness is only enforced within each partition. The protocol ID of a
+// blockchain event is still checked on insert, but the IDs of events, operations and blockchain events are
+// not, as they are always newly generated UUIDs.
+func (s *SQLCommon) SetPartitionedTables(tables ...string) {
+	s.partitioned = make(map[string]bool, len(tables))
+	for _, table := range tables {
+		s.partitioned[table] = true
+	}
+}
+
+// SetChangeForwarder registers a function that is passed every change event emitted by this process,
+// after it has been dispatched to the local handlers. Used by providers that share change events
+// between processes connected to the same database.
+func (s *SQLCommon) SetChangeForwarder(forward func(ce *ChangeEvent)) {
+	s.callbacks.forward = forward
+}
+
+// DispatchChangeEvent delivers a change event received from another process to the local handlers only
+func (s *SQLCommon) DispatchChangeEvent(ce *ChangeEvent) {
+	s.callbacks.dispatch(ce)
+}
+
+// AcquireLeadership grants leadership immediately, as a process that does not share its database
+// with other processes is always the leader. Providers that support clustering override this.
+func (s *SQLCommon) AcquireLeadership(ctx context.Context, name string) (<-chan struct{}, error) {
+	return make(chan struct{}), nil
+}
+
+// deleteMatching bulk deletes the records in a namespace that match a filter, without emitting change events.
+// Records still needed according to the guards never match. The sequences of the matching records are selected
+// first, so the filter can use sort, skip and limit to bound the size of each delete. The optional cascade is
+// called with those sequences before the records are deleted, to remove any dependent rows in the same transaction.
+func (s *SQLCommon) deleteMatching(ctx context.Context, table, namespace string, filter ffapi.Filter, filterFieldMap map[string]string, guards *database.RetentionGuards,
+	cascade func(ctx context.Context, tx *dbsql.TXWrapper, seqs []int64) error) (count int64, err error) {
+	query, _, _, err := s.FilterSelect(ctx, "", sq.Select(s.SequenceColumn()).From(table),
+		filter, filterFieldMap, []interface{}{"sequence"}, sq.Eq{"namespace": namespace}, sq.Expr("NOT (?)", s.RetentionNeeded(table, namespace, guards)))
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	rows, _, err := s.QueryTx(ctx, table, tx, query)
+	if err != nil {
+		return 0, err
+	}
+	seqs := []int64{}
+	for rows.Next() {
+		var seq int64
+		if err := rows.Scan(&seq); err != nil {
+			rows.Close()
+			return 0, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, table)
+		}
+		seqs = append(seqs, seq)
+	}
+	rows.Close()
+	if len(seqs) == 0 {
+		return 0, s.CommitTx(ctx, tx, autoCommit)
+	}
+
+	if cascade != nil {
+		if err := cascade(ctx, tx, seqs); err != nil {
+			return 0, err
+		}
+	}
+	if err := s.DeleteTx(ctx, table, tx, sq.Delete(table).Where(sq.Eq{s.SequenceColumn(): seqs}), nil); err != nil {
+		return 0, err
+	}
+	return int64(len(seqs)), s.CommitTx(ctx, tx, autoCommit)
+}
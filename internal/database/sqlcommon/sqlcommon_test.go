@@ -21,9 +21,13 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	sq "github.com/Masterminds/squirrel"
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/hyperledger/firefly-common/pkg/dbsql"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/encryption"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
@@ -110,3 +114,128 @@ func TestNamespaceCallbacks(t *testing.T) {
 	cb.UUIDCollectionNSEvent(database.CollectionOperations, core.ChangeEventTypeCreated, "ns1", id)
 	cb.HashCollectionNSEvent(database.CollectionGroups, core.ChangeEventTypeUpdated, "ns1", hash)
 }
+
+func TestGlobalCallbacksAndForwarder(t *testing.T) {
+	s := &SQLCommon{}
+	tcb := &databasemocks.Callbacks{}
+	s.SetHandler(database.GlobalHandler, tcb)
+	forwarded := []*ChangeEvent{}
+	s.SetChangeForwarder(func(ce *ChangeEvent) {
+		forwarded = append(forwarded, ce)
+	})
+	id := fftypes.NewUUID()
+
+	tcb.On("UUIDCollectionNSEvent", database.CollectionOperations, core.ChangeEventTypeCreated, "ns1", id).Return().Twice()
+
+	s.callbacks.UUIDCollectionNSEvent(database.CollectionOperations, core.ChangeEventTypeCreated, "ns1", id)
+	assert.Len(t, forwarded, 1)
+	assert.Equal(t, &ChangeEvent{
+		Kind:       ChangeKindUUID,
+		Collection: "operations",
+		Type:       core.ChangeEventTypeCreated,
+		Namespace:  "ns1",
+		ID:         id,
+	}, forwarded[0])
+
+	// Events received from other processes are only dispatched locally
+	s.DispatchChangeEvent(forwarded[0])
+	assert.Len(t, forwarded, 1)
+	tcb.AssertExpectations(t)
+}
+
+func TestAcquireLeadershipImmediate(t *testing.T) {
+	s := &SQLCommon{}
+	lost, err := s.AcquireLeadership(context.Background(), "namespace:ns1")
+	assert.NoError(t, err)
+	select {
+	case <-lost:
+		assert.Fail(t, "leadership should not be lost")
+	default:
+	}
+}
+
+func TestDeleteMatchingBuildQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	f := database.EventQueryFactory.NewFilter(context.Background()).Eq("id", map[bool]bool{true: false})
+	_, err := s.deleteMatching(context.Background(), eventsTable, "ns1", f, eventFilterFieldMap, &database.RetentionGuards{}, nil)
+	assert.Regexp(t, "FF00143.*id", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteMatchingFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	f := database.EventQueryFactory.NewFilter(context.Background()).And()
+	_, err := s.deleteMatching(context.Background(), eventsTable, "ns1", f, eventFilterFieldMap, &database.RetentionGuards{}, nil)
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteMatchingQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	f := database.EventQueryFactory.NewFilter(context.Background()).And()
+	_, err := s.deleteMatching(context.Background(), eventsTable, "ns1", f, eventFilterFieldMap, &database.RetentionGuards{}, nil)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteMatchingScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow("not a number"))
+	mock.ExpectRollback()
+	f := database.EventQueryFactory.NewFilter(context.Background()).And()
+	_, err := s.deleteMatching(context.Background(), eventsTable, "ns1", f, eventFilterFieldMap, &database.RetentionGuards{}, nil)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteMatchingCascadeFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(12345))
+	mock.ExpectRollback()
+	f := database.EventQueryFactory.NewFilter(context.Background()).And()
+	_, err := s.deleteMatching(context.Background(), eventsTable, "ns1", f, eventFilterFieldMap, &database.RetentionGuards{},
+		func(ctx context.Context, tx *dbsql.TXWrapper, seqs []int64) error {
+			assert.Equal(t, []int64{12345}, seqs)
+			return fmt.Errorf("pop")
+		})
+	assert.Regexp(t, "pop", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteMatchingDeleteFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(12345))
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	f := database.EventQueryFactory.NewFilter(context.Background()).And()
+	_, err := s.deleteMatching(context.Background(), eventsTable, "ns1", f, eventFilterFieldMap, &database.RetentionGuards{}, nil)
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteMatchingNoneMatched(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"seq"}))
+	mock.ExpectCommit()
+	f := database.EventQueryFactory.NewFilter(context.Background()).And()
+	count, err := s.deleteMatching(context.Background(), eventsTable, "ns1", f, eventFilterFieldMap, &database.RetentionGuards{}, nil)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInitEncryptionFail(t *testing.T) {
+	mp := newMockProvider()
+	defer coreconfig.Reset()
+	mp.config.SubSection(SQLConfEncryption).Set(encryption.ConfEnabled, true)
+	err := mp.Init(context.Background(), mp, mp.config, mp.capabilities)
+	assert.Regexp(t, "FF00183.*keyfile.path", err)
+}
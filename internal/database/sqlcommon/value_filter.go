@@ -0,0 +1,182 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// JSONQueryProvider is implemented by providers that can match paths within a JSON column,
+// to support filters on the values of data
+type JSONQueryProvider interface {
+	// JSONPathText returns an expression for the text at a path within a JSON column - the content of a string,
+	// or the JSON of any other type. It is NULL if there is nothing at the path, or the value is null.
+	// The segments of the path have been checked to contain only letters, numbers, '_' and '-'.
+	JSONPathText(column string, path []string) string
+	// JSONPathIndexCondition optionally returns a condition that allows the database to use an index, when
+	// matching rows where the text at a path equals the value. It can match a superset of those rows.
+	JSONPathIndexCondition(column string, path []string, value string) sq.Sqlizer
+}
+
+// SQLiteJSONPathText implements JSONPathText for the SQLite providers. It uses json_extract, but returns
+// booleans as "true" and "false" rather than as integers.
+func SQLiteJSONPathText(column string, path []string) string {
+	jsonPath := "$"
+	for _, segment := range path {
+		if _, err := strconv.Atoi(segment); err == nil {
+			jsonPath += fmt.Sprintf("[%s]", segment)
+		} else {
+			jsonPath += fmt.Sprintf(`."%s"`, segment)
+		}
+	}
+	return fmt.Sprintf(
+		"(CASE json_type(%[1]s, '%[2]s') WHEN 'true' THEN 'true' WHEN 'false' THEN 'false' ELSE CAST(json_extract(%[1]s, '%[2]s') AS TEXT) END)",
+		column, jsonPath)
+}
+
+// valueFilterCondition returns the condition for a value filter on a JSON column,
+// or nil if there is no value filter
+func (s *SQLCommon) valueFilterCondition(ctx context.Context, column string, filter ffapi.Filter) (sq.Sqlizer, error) {
+	if filter == nil {
+		return nil, nil
+	}
+	if s.jsonQuery == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgValueFilterNotSupported)
+	}
+	if s.encryptor != nil {
+		// Encrypted values are ciphertext in the database, so a filter on them could never match
+		return nil, i18n.NewError(ctx, coremsgs.MsgValueFilterEncrypted)
+	}
+	fi, err := filter.Finalize()
+	if err != nil {
+		return nil, err
+	}
+	return s.valueFilterOp(ctx, column, fi)
+}
+
+func (s *SQLCommon) valueFilterOp(ctx context.Context, column string, op *ffapi.FilterInfo) (sq.Sqlizer, error) {
+	switch op.Op {
+	case ffapi.FilterOpAnd, ffapi.FilterOpOr:
+		children := make([]sq.Sqlizer, len(op.Children))
+		for i, c := range op.Children {
+			var err error
+			if children[i], err = s.valueFilterOp(ctx, column, c); err != nil {
+				return nil, err
+			}
+		}
+		if op.Op == ffapi.FilterOpOr {
+			return sq.Or(children), nil
+		}
+		return sq.And(children), nil
+	}
+
+	path, err := database.ParseValuePath(ctx, op.Field)
+	if err != nil {
+		return nil, err
+	}
+	field := s.jsonQuery.JSONPathText(column, path)
+	var value interface{}
+	if op.Value != nil {
+		value, _ = op.Value.Value()
+	}
+	text, _ := value.(string)
+
+	switch op.Op {
+	case ffapi.FilterOpEq:
+		if value != nil {
+			if ic := s.jsonQuery.JSONPathIndexCondition(column, path, text); ic != nil {
+				return sq.And{ic, sq.Eq{field: value}}, nil
+			}
+		}
+		return sq.Eq{field: value}, nil
+	case ffapi.FilterOpNeq:
+		return sq.NotEq{field: value}, nil
+	case ffapi.FilterOpIn, ffapi.FilterOpNotIn:
+		values := make([]interface{}, len(op.Values))
+		for i, v := range op.Values {
+			values[i], _ = v.Value()
+		}
+		if op.Op == ffapi.FilterOpNotIn {
+			return sq.NotEq{field: values}, nil
+		}
+		return sq.Eq{field: values}, nil
+	case ffapi.FilterOpGt:
+		return sq.Gt{field: value}, nil
+	case ffapi.FilterOpGte:
+		return sq.GtOrEq{field: value}, nil
+	case ffapi.FilterOpLt:
+		return sq.Lt{field: value}, nil
+	case ffapi.FilterOpLte:
+		return sq.LtOrEq{field: value}, nil
+	case ffapi.FilterOpIEq:
+		return valueLike(field, escapeLike(text), false, true), nil
+	case ffapi.FilterOpNIeq:
+		return valueLike(field, escapeLike(text), true, true), nil
+	case ffapi.FilterOpCont:
+		return valueLike(field, "%"+escapeLike(text)+"%", false, false), nil
+	case ffapi.FilterOpNotCont:
+		return valueLike(field, "%"+escapeLike(text)+"%", true, false), nil
+	case ffapi.FilterOpICont:
+		return valueLike(field, "%"+escapeLike(text)+"%", false, true), nil
+	case ffapi.FilterOpNotICont:
+		return valueLike(field, "%"+escapeLike(text)+"%", true, true), nil
+	case ffapi.FilterOpStartsWith:
+		return valueLike(field, escapeLike(text)+"%", false, false), nil
+	case ffapi.FilterOpNotStartsWith:
+		return valueLike(field, escapeLike(text)+"%", true, false), nil
+	case ffapi.FilterOpIStartsWith:
+		return valueLike(field, escapeLike(text)+"%", false, true), nil
+	case ffapi.FilterOpNotIStartsWith:
+		return valueLike(field, escapeLike(text)+"%", true, true), nil
+	case ffapi.FilterOpEndsWith:
+		return valueLike(field, "%"+escapeLike(text), false, false), nil
+	case ffapi.FilterOpNotEndsWith:
+		return valueLike(field, "%"+escapeLike(text), true, false), nil
+	case ffapi.FilterOpIEndsWith:
+		return valueLike(field, "%"+escapeLike(text), false, true), nil
+	case ffapi.FilterOpNotIEndsWith:
+		return valueLike(field, "%"+escapeLike(text), true, true), nil
+	default:
+		return nil, i18n.NewError(ctx, i18n.MsgUnsupportedSQLOpInFilter, op.Op)
+	}
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// valueLike uses an explicit escape character, as only PostgreSQL has a default
+func valueLike(field, pattern string, negate, caseInsensitive bool) sq.Sqlizer {
+	if caseInsensitive {
+		field = fmt.Sprintf("lower(%s)", field)
+		pattern = strings.ToLower(pattern)
+	}
+	like := "LIKE"
+	if negate {
+		like = "NOT LIKE"
+	}
+	return sq.Expr(fmt.Sprintf(`%s %s ? ESCAPE '\'`, field, like), pattern)
+}
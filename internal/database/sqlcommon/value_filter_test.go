@@ -0,0 +1,190 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestValueFilterE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionData, core.ChangeEventTypeCreated, "ns1", mock.Anything).Return()
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+
+	values := map[string]string{
+		"inv1": `{"customer":{"name":"Acme_Corp","id":"c1"},"total":100}`,
+		"inv2": `{"customer":{"name":"Widgets Ltd","id":"c2"},"total":250}`,
+		"inv3": `{"customer":{"name":"acme trading","id":"c3"}}`,
+	}
+	ids := map[string]*fftypes.UUID{}
+	for name, value := range values {
+		data := &core.Data{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Created:   fftypes.Now(),
+			Value:     fftypes.JSONAnyPtr(value),
+		}
+		data.Hash, _ = data.CalcHash(ctx)
+		ids[name] = data.ID
+		err := s.UpsertData(ctx, data, database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+		msg := &core.Message{
+			Header: core.MessageHeader{
+				ID:        fftypes.NewUUID(),
+				Namespace: "ns1",
+				Created:   fftypes.Now(),
+			},
+			LocalNamespace: "ns1",
+			Data:           core.DataRefs{{ID: data.ID, Hash: data.Hash}},
+		}
+		msg.Hash = msg.Header.Hash()
+		msg.Header.DataHash = msg.Data.Hash()
+		ids["msg-"+name] = msg.Header.ID
+		err = s.UpsertMessage(ctx, msg, database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+	}
+
+	qf, err := database.ValueQueryFactory(ctx, "value.customer.name", "value.customer.id", "value.total")
+	assert.NoError(t, err)
+	fb := qf.NewFilter(ctx)
+	tests := []struct {
+		filter   ffapi.Filter
+		expected []string
+	}{
+		{fb.Eq("value.customer.id", "c1"), []string{"inv1"}},
+		{fb.Eq("value.total", nil), []string{"inv3"}},
+		{fb.Neq("value.customer.id", "c1"), []string{"inv2", "inv3"}},
+		{fb.In("value.customer.id", []driver.Value{"c1", "c2"}), []string{"inv1", "inv2"}},
+		{fb.NotIn("value.customer.id", []driver.Value{"c1", "c2"}), []string{"inv3"}},
+		{fb.Gt("value.customer.id", "c2"), []string{"inv3"}},
+		{fb.Gte("value.customer.id", "c2"), []string{"inv2", "inv3"}},
+		{fb.Lt("value.customer.id", "c2"), []string{"inv1"}},
+		{fb.Lte("value.customer.id", "c2"), []string{"inv1", "inv2"}},
+		{fb.IEq("value.customer.name", "ACME_CORP"), []string{"inv1"}},
+		{fb.NIeq("value.customer.name", "ACME_CORP"), []string{"inv2", "inv3"}},
+		{fb.Contains("value.customer.name", "_"), []string{"inv1"}},
+		{fb.NotContains("value.customer.name", "_"), []string{"inv2", "inv3"}},
+		{fb.IContains("value.customer.name", "ACME"), []string{"inv1", "inv3"}},
+		{fb.NotIContains("value.customer.name", "ACME"), []string{"inv2"}},
+		{fb.StartsWith("value.customer.name", "Wid"), []string{"inv2"}},
+		{fb.NotStartsWith("value.customer.name", "Wid"), []string{"inv1", "inv3"}},
+		{fb.IStartsWith("value.customer.name", "ACME"), []string{"inv1", "inv3"}},
+		{fb.NotIStartsWith("value.customer.name", "ACME"), []string{"inv2"}},
+		{fb.EndsWith("value.customer.name", "Ltd"), []string{"inv2"}},
+		{fb.NotEndsWith("value.customer.name", "Ltd"), []string{"inv1", "inv3"}},
+		{fb.IEndsWith("value.customer.name", "TRADING"), []string{"inv3"}},
+		{fb.NotIEndsWith("value.customer.name", "TRADING"), []string{"inv1", "inv2"}},
+		{fb.Or(fb.Eq("value.customer.id", "c1"), fb.Eq("value.total", "250")), []string{"inv1", "inv2"}},
+		{fb.And(fb.IContains("value.customer.name", "acme"), fb.Eq("value.total", "100")), []string{"inv1"}},
+	}
+	for _, test := range tests {
+		fi, _ := test.filter.Finalize()
+		data, _, err := s.GetData(ctx, "ns1", database.DataQueryFactory.NewFilter(ctx).And(), test.filter)
+		assert.NoError(t, err)
+		dataIDs := make([]*fftypes.UUID, len(data))
+		for i, d := range data {
+			dataIDs[i] = d.ID
+		}
+		msgs, _, err := s.GetMessages(ctx, "ns1", database.MessageQueryFactory.NewFilter(ctx).And(), test.filter)
+		assert.NoError(t, err)
+		msgIDs := make([]*fftypes.UUID, len(msgs))
+		for i, m := range msgs {
+			msgIDs[i] = m.Header.ID
+		}
+
+		expectedData := make([]*fftypes.UUID, len(test.expected))
+		expectedMsgs := make([]*fftypes.UUID, len(test.expected))
+		for i, name := range test.expected {
+			expectedData[i] = ids[name]
+			expectedMsgs[i] = ids["msg-"+name]
+		}
+		assert.ElementsMatch(t, expectedData, dataIDs, fi.String())
+		assert.ElementsMatch(t, expectedMsgs, msgIDs, fi.String())
+	}
+}
+
+func TestValueFilterNotSupported(t *testing.T) {
+	s, _ := newMockProvider().init()
+	ctx := context.Background()
+	valueFilter := database.DataQueryFactory.NewFilter(context.Background()).Eq("id", "x")
+	_, _, err := s.GetData(ctx, "ns1", database.DataQueryFactory.NewFilter(ctx).And(), valueFilter)
+	assert.Regexp(t, "FF10504", err)
+	_, _, err = s.GetMessages(ctx, "ns1", database.MessageQueryFactory.NewFilter(ctx).And(), valueFilter)
+	assert.Regexp(t, "FF10504", err)
+}
+
+func TestValueFilterEncrypted(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	s.encryptor = newTestEncryptor(t, "key1")
+	qf, err := database.ValueQueryFactory(context.Background(), "value.a")
+	assert.NoError(t, err)
+	ctx := context.Background()
+	valueFilter := qf.NewFilter(context.Background()).Eq("value.a", "x")
+	_, _, err = s.GetData(ctx, "ns1", database.DataQueryFactory.NewFilter(ctx).And(), valueFilter)
+	assert.Regexp(t, "FF10528", err)
+	_, _, err = s.GetMessages(ctx, "ns1", database.MessageQueryFactory.NewFilter(ctx).And(), valueFilter)
+	assert.Regexp(t, "FF10528", err)
+}
+
+func TestValueFilterFinalizeFail(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	qf, err := database.ValueQueryFactory(context.Background(), "value.a")
+	assert.NoError(t, err)
+	ctx := context.Background()
+	valueFilter := qf.NewFilter(context.Background()).Eq("value.b", "x")
+	_, _, err = s.GetData(ctx, "ns1", database.DataQueryFactory.NewFilter(ctx).And(), valueFilter)
+	assert.Regexp(t, "FF00142", err)
+}
+
+func TestValueFilterBadPath(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	fb := database.DataQueryFactory.NewFilter(context.Background())
+	ctx := context.Background()
+	valueFilter := fb.And(fb.Eq("id", fftypes.NewUUID()))
+	_, _, err := s.GetData(ctx, "ns1", database.DataQueryFactory.NewFilter(ctx).And(), valueFilter)
+	assert.Regexp(t, "FF10503", err)
+}
+
+func TestValueFilterOpUnsupported(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	_, err := s.valueFilterOp(context.Background(), "value", &ffapi.FilterInfo{
+		Op:    ffapi.FilterOp("wrong"),
+		Field: "value.a",
+	})
+	assert.Regexp(t, "FF00190", err)
+}
+
+func TestSQLiteJSONPathText(t *testing.T) {
+	assert.Equal(t,
+		`(CASE json_type(value, '$."lines"[0]."sku"') WHEN 'true' THEN 'true' WHEN 'false' THEN 'false' ELSE CAST(json_extract(value, '$."lines"[0]."sku"') AS TEXT) END)`,
+		SQLiteJSONPathText("value", []string{"lines", "0", "sku"}))
+}
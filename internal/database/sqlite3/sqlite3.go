@@ -62,6 +62,11 @@ func (sqlite *SQLite3) Init(ctx context.Context, config config.Section) error {
 	return sqlite.SQLCommon.Init(ctx, sqlite, config, capabilities)
 }
 
+// InitSchema is the same as Init, as SQLite starts no background processing
+func (sqlite *SQLite3) InitSchema(ctx context.Context, config config.Section) error {
+	return sqlite.Init(ctx, config)
+}
+
 func (sqlite *SQLite3) SetHandler(namespace string, handler database.Callbacks) {
 	sqlite.SQLCommon.SetHandler(namespace, handler)
 }
@@ -96,3 +101,13 @@ func (sqlite *SQLite3) Open(url string) (*sql.DB, error) {
 func (sqlite *SQLite3) GetMigrationDriver(db *sql.DB) (migratedb.Driver, error) {
 	return migratesqlite3.WithInstance(db, &migratesqlite3.Config{})
 }
+
+// JSONPathText uses json_extract, but returns booleans as "true" and "false" rather than as integers
+func (sqlite *SQLite3) JSONPathText(column string, path []string) string {
+	return sqlcommon.SQLiteJSONPathText(column, path)
+}
+
+// JSONPathIndexCondition returns nil, as there is no index on JSON values
+func (sqlite *SQLite3) JSONPathIndexCondition(column string, path []string, value string) sq.Sqlizer {
+	return nil
+}
@@ -21,6 +21,8 @@ package sqlite3
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	sq "github.com/Masterminds/squirrel"
@@ -41,6 +43,8 @@ func TestSQLite3GoProvider(t *testing.T) {
 	config.Set(sqlcommon.SQLConfDatasourceURL, tmpDir)
 	err := sqlite.Init(context.Background(), config)
 	assert.NoError(t, err)
+	err = sqlite.InitSchema(context.Background(), config)
+	assert.NoError(t, err)
 	_, err = sqlite.GetMigrationDriver(sqlite.DB())
 	assert.Error(t, err)
 
@@ -61,3 +65,34 @@ func TestSQLite3GoProvider(t *testing.T) {
 	assert.Equal(t, "INSERT INTO test (col1) VALUES (?)", sql)
 	assert.False(t, query)
 }
+
+func TestSQLite3JSONPathText(t *testing.T) {
+	sqlite := &SQLite3{}
+	assert.Nil(t, sqlite.JSONPathIndexCondition("value", []string{"a"}, "b"))
+
+	db, err := sqlite.Open("file::memory:")
+	assert.NoError(t, err)
+	defer db.Close()
+
+	doc := `{"a":{"b-c":"text","n":12.5,"t":true,"f":false,"z":null,"o":{"x":1}},"l":[{"x":"first"}]}`
+	for path, expected := range map[string]interface{}{
+		"a.b-c":   "text",
+		"a.n":     "12.5",
+		"a.t":     "true",
+		"a.f":     "false",
+		"a.z":     nil,
+		"a.o":     `{"x":1}`,
+		"l.0.x":   "first",
+		"missing": nil,
+	} {
+		var text *string
+		query := fmt.Sprintf("SELECT %s FROM (SELECT ? AS value)", sqlite.JSONPathText("value", strings.Split(path, ".")))
+		err = db.QueryRow(query, doc).Scan(&text)
+		assert.NoError(t, err)
+		if expected == nil {
+			assert.Nil(t, text, path)
+		} else {
+			assert.Equal(t, expected, *text, path)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlitego
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
+	migratesqlite "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/dbsql"
+	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+	"github.com/hyperledger/firefly/pkg/database"
+
+	// Import the pure Go SQLite driver, which does not require CGO
+	_ "modernc.org/sqlite"
+)
+
+// pragmas are run on each new connection, matching the connect hook of the CGO SQLite plugin
+var pragmas = []string{
+	"case_sensitive_like(1)",
+	"busy_timeout(1000)",
+}
+
+type SQLiteGo struct {
+	sqlcommon.SQLCommon
+}
+
+func (sqlite *SQLiteGo) Init(ctx context.Context, config config.Section) error {
+	capabilities := &database.Capabilities{}
+	return sqlite.SQLCommon.Init(ctx, sqlite, config, capabilities)
+}
+
+// InitSchema is the same as Init, as SQLite starts no background processing
+func (sqlite *SQLiteGo) InitSchema(ctx context.Context, config config.Section) error {
+	return sqlite.Init(ctx, config)
+}
+
+func (sqlite *SQLiteGo) SetHandler(namespace string, handler database.Callbacks) {
+	sqlite.SQLCommon.SetHandler(namespace, handler)
+}
+
+func (sqlite *SQLiteGo) Name() string {
+	return "sqlitego"
+}
+
+func (sqlite *SQLiteGo) MigrationsDir() string {
+	return "sqlite"
+}
+
+func (sqlite *SQLiteGo) SequenceColumn() string {
+	return "seq"
+}
+
+func (sqlite *SQLiteGo) Features() dbsql.SQLFeatures {
+	features := dbsql.DefaultSQLProviderFeatures()
+	features.PlaceholderFormat = sq.Dollar
+	features.UseILIKE = false // Not supported
+	return features
+}
+
+func (sqlite *SQLiteGo) ApplyInsertQueryCustomizations(insert sq.InsertBuilder, requestConflictEmptyResult bool) (sq.InsertBuilder, bool) {
+	return insert, false
+}
+
+// Open passes the pragmas to the driver as query parameters of the connection string
+func (sqlite *SQLiteGo) Open(connURL string) (*sql.DB, error) {
+	params := url.Values{"_pragma": pragmas}
+	separator := "?"
+	if strings.Contains(connURL, "?") {
+		separator = "&"
+	}
+	return sql.Open("sqlite", connURL+separator+params.Encode())
+}
+
+func (sqlite *SQLiteGo) GetMigrationDriver(db *sql.DB) (migratedb.Driver, error) {
+	return migratesqlite.WithInstance(db, &migratesqlite.Config{})
+}
+
+// JSONPathText uses json_extract, but returns booleans as "true" and "false" rather than as integers
+func (sqlite *SQLiteGo) JSONPathText(column string, path []string) string {
+	return sqlcommon.SQLiteJSONPathText(column, path)
+}
+
+// JSONPathIndexCondition returns nil, as there is no index on JSON values
+func (sqlite *SQLiteGo) JSONPathIndexCondition(column string, path []string, value string) sq.Sqlizer {
+	return nil
+}
@@ -0,0 +1,152 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlitego
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSQLiteGoProvider(t *testing.T) {
+	sqlite := &SQLiteGo{}
+	sqlite.SetHandler("ns", &databasemocks.Callbacks{})
+	config := config.RootSection("unittest")
+	sqlite.InitConfig(config)
+	config.Set(sqlcommon.SQLConfDatasourceURL, filepath.Join(t.TempDir(), "test.db"))
+	err := sqlite.Init(context.Background(), config)
+	assert.NoError(t, err)
+	err = sqlite.InitSchema(context.Background(), config)
+	assert.NoError(t, err)
+
+	db, err := sqlite.Open("file::memory:?cache=shared")
+	assert.NoError(t, err)
+	var matchesOtherCase bool
+	err = db.QueryRow("SELECT 'abc' LIKE 'ABC'").Scan(&matchesOtherCase)
+	assert.NoError(t, err)
+	assert.False(t, matchesOtherCase)
+	var busyTimeout int
+	err = db.QueryRow("PRAGMA busy_timeout").Scan(&busyTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, busyTimeout)
+	db.Close()
+
+	assert.Equal(t, "sqlitego", sqlite.Name())
+	assert.Equal(t, "sqlite", sqlite.MigrationsDir())
+	assert.Equal(t, "seq", sqlite.SequenceColumn())
+	assert.Equal(t, sq.Dollar, sqlite.Features().PlaceholderFormat)
+
+	insert := sq.Insert("test").Columns("col1").Values("val1")
+	insert, query := sqlite.ApplyInsertQueryCustomizations(insert, false)
+	sql, _, err := insert.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO test (col1) VALUES (?)", sql)
+	assert.False(t, query)
+}
+
+func TestSQLiteGoMigrationsAndEvents(t *testing.T) {
+	ctx := context.Background()
+	sqlite := &SQLiteGo{}
+	callbacks := &databasemocks.Callbacks{}
+	sqlite.SetHandler("ns1", callbacks)
+	config := config.RootSection("unittest")
+	sqlite.InitConfig(config)
+	config.Set(sqlcommon.SQLConfDatasourceURL, filepath.Join(t.TempDir(), "test.db"))
+	config.Set(sqlcommon.SQLConfMigrationsAuto, true)
+	config.Set(sqlcommon.SQLConfMigrationsDirectory, "../../../db/migrations/sqlite")
+	err := sqlite.Init(ctx, config)
+	assert.NoError(t, err)
+	defer sqlite.Close()
+
+	// Events written in a group are delivered to the callbacks in sequence order once committed
+	var sequences []int64
+	callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { sequences = append(sequences, args[4].(int64)) })
+	err = sqlite.RunAsGroup(ctx, func(ctx context.Context) error {
+		for i := 0; i < 3; i++ {
+			if err := sqlite.InsertEvent(ctx, core.NewEvent(core.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, "topic1")); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, sequences, 3)
+	assert.Less(t, sequences[0], sequences[1])
+	assert.Less(t, sequences[1], sequences[2])
+
+	// A group that fails is rolled back
+	err = sqlite.RunAsGroup(ctx, func(ctx context.Context) error {
+		if err := sqlite.InsertEvent(ctx, core.NewEvent(core.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, "topic1")); err != nil {
+			return err
+		}
+		return fmt.Errorf("pop")
+	})
+	assert.EqualError(t, err, "pop")
+
+	fb := database.EventQueryFactory.NewFilter(ctx)
+	events, _, err := sqlite.GetEvents(ctx, "ns1", fb.And().Sort("sequence"))
+	assert.NoError(t, err)
+	assert.Len(t, events, 3)
+	for i, event := range events {
+		assert.Equal(t, sequences[i], event.Sequence)
+	}
+	callbacks.AssertExpectations(t)
+}
+
+func TestSQLiteGoJSONPathText(t *testing.T) {
+	sqlite := &SQLiteGo{}
+	assert.Nil(t, sqlite.JSONPathIndexCondition("value", []string{"a"}, "b"))
+
+	db, err := sqlite.Open("file::memory:")
+	assert.NoError(t, err)
+	defer db.Close()
+
+	doc := `{"a":{"b-c":"text","n":12.5,"t":true,"f":false,"z":null,"o":{"x":1}},"l":[{"x":"first"}]}`
+	for path, expected := range map[string]interface{}{
+		"a.b-c":   "text",
+		"a.n":     "12.5",
+		"a.t":     "true",
+		"a.f":     "false",
+		"a.z":     nil,
+		"a.o":     `{"x":1}`,
+		"l.0.x":   "first",
+		"missing": nil,
+	} {
+		var text *string
+		query := fmt.Sprintf("SELECT %s FROM (SELECT ? AS value)", sqlite.JSONPathText("value", strings.Split(path, ".")))
+		err = db.QueryRow(query, doc).Scan(&text)
+		assert.NoError(t, err)
+		if expected == nil {
+			assert.Nil(t, text, path)
+		} else {
+			assert.Equal(t, expected, *text, path)
+		}
+	}
+}
@@ -112,7 +112,7 @@ func (dh *definitionHandler) confirmVerificationForClaim(ctx context.Context, st
 		fb.Eq("state", core.MessageStateConfirmed),
 		fb.Eq("tag", core.SystemTagIdentityVerification),
 	)
-	candidates, _, err := dh.database.GetMessages(ctx, dh.namespace.Name, filter)
+	candidates, _, err := dh.database.GetMessages(ctx, dh.namespace.Name, filter, nil)
 	if err != nil {
 		return nil, err
 	}
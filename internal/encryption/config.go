@@ -0,0 +1,54 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"github.com/hyperledger/firefly-common/pkg/config"
+)
+
+const (
+	// ConfEnabled turns on encryption of values written to the database
+	ConfEnabled = "enabled"
+	// ConfProvider is the type of key provider that supplies the data keys
+	ConfProvider = "provider"
+	// ConfKeyfilePath is the file holding the master key of the keyfile provider
+	ConfKeyfilePath = "keyfile.path"
+	// ConfKMSType is the type of key management service used by the kms provider
+	ConfKMSType = "kms.type"
+	// ConfKMSKeyID is the identifier of the master key held by the key management service
+	ConfKMSKeyID = "kms.keyId"
+	// ConfKMSLocalKeyDir is the directory holding the master keys of the local stand-in key management service
+	ConfKMSLocalKeyDir = "kms.local.keyDir"
+)
+
+const (
+	// ProviderKeyfile wraps data keys with a master key read from a local file
+	ProviderKeyfile = "keyfile"
+	// ProviderKMS wraps data keys with a master key held in a key management service
+	ProviderKMS = "kms"
+	// KMSTypeLocal is a stand-in key management service, that holds its master keys in local files
+	KMSTypeLocal = "local"
+)
+
+func InitConfig(conf config.Section) {
+	conf.AddKnownKey(ConfEnabled, false)
+	conf.AddKnownKey(ConfProvider, ProviderKeyfile)
+	conf.AddKnownKey(ConfKeyfilePath)
+	conf.AddKnownKey(ConfKMSType, KMSTypeLocal)
+	conf.AddKnownKey(ConfKMSKeyID)
+	conf.AddKnownKey(ConfKMSLocalKeyDir)
+}
@@ -0,0 +1,156 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+const (
+	envelopeVersion = 1
+	envelopePrefix  = `{"ffenc":`
+)
+
+// envelope is the stored form of an encrypted value. It is itself valid JSON, so that columns
+// holding JSON remain parseable by the database, and carries the data key wrapped by the
+// key provider so that each value can be decrypted independently.
+type envelope struct {
+	Version int    `json:"ffenc"`
+	Key     string `json:"key"`
+	Data    string `json:"data"`
+}
+
+// Encryptor performs envelope encryption of values - each value is encrypted with AES-256-GCM using
+// a data key, and the data key is stored with the value wrapped by the configured key provider.
+//
+// One data key is generated per process, and unwrapped data keys are cached, so the key provider is
+// only called once for each distinct data key.
+type Encryptor struct {
+	provider       KeyProvider
+	mux            sync.Mutex
+	currentKey     []byte
+	currentWrapped string
+	keys           map[string][]byte
+}
+
+// NewEncryptor returns an Encryptor for the configuration, or nil if encryption is not enabled
+func NewEncryptor(ctx context.Context, conf config.Section) (*Encryptor, error) {
+	if !conf.GetBool(ConfEnabled) {
+		return nil, nil
+	}
+	provider, err := getKeyProvider(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+	return &Encryptor{
+		provider: provider,
+		keys:     make(map[string][]byte),
+	}, nil
+}
+
+func (e *Encryptor) dataKey(ctx context.Context) ([]byte, string, error) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	if e.currentKey == nil {
+		key, wrapped, err := e.provider.GenerateDataKey(ctx)
+		if err != nil {
+			return nil, "", i18n.NewError(ctx, coremsgs.MsgEncryptionFailed, err)
+		}
+		e.currentKey = key
+		e.currentWrapped = base64.StdEncoding.EncodeToString(wrapped)
+		e.keys[e.currentWrapped] = key
+	}
+	return e.currentKey, e.currentWrapped, nil
+}
+
+func (e *Encryptor) unwrapDataKey(ctx context.Context, wrapped string) ([]byte, error) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	if key, ok := e.keys[wrapped]; ok {
+		return key, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgDecryptionFailed, err)
+	}
+	key, err := e.provider.UnwrapDataKey(ctx, b)
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgDecryptionFailed, err)
+	}
+	e.keys[wrapped] = key
+	return key, nil
+}
+
+// Encrypt returns the envelope for a value. The additional data is authenticated but not stored,
+// so must be supplied again to decrypt - binding the value to the record it was written to.
+func (e *Encryptor) Encrypt(ctx context.Context, plaintext, aad []byte) (string, error) {
+	key, wrapped, err := e.dataKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(key, plaintext, aad)
+	if err != nil {
+		return "", i18n.NewError(ctx, coremsgs.MsgEncryptionFailed, err)
+	}
+	b, _ := json.Marshal(&envelope{
+		Version: envelopeVersion,
+		Key:     wrapped,
+		Data:    base64.StdEncoding.EncodeToString(sealed),
+	})
+	return string(b), nil
+}
+
+// Decrypt returns the value held in an envelope
+func (e *Encryptor) Decrypt(ctx context.Context, stored string, aad []byte) ([]byte, error) {
+	env := parseEnvelope(stored)
+	if env == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgDecryptionFailed, "not encrypted")
+	}
+	key, err := e.unwrapDataKey(ctx, env.Key)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(env.Data)
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgDecryptionFailed, err)
+	}
+	plaintext, err := open(ctx, key, sealed, aad)
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgDecryptionFailed, err)
+	}
+	return plaintext, nil
+}
+
+func parseEnvelope(stored string) *envelope {
+	if !strings.HasPrefix(stored, envelopePrefix) {
+		return nil
+	}
+	var env envelope
+	if err := json.Unmarshal([]byte(stored), &env); err != nil ||
+		env.Version != envelopeVersion || env.Key == "" || env.Data == "" {
+		return nil
+	}
+	return &env
+}
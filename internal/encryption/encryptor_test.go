@@ -0,0 +1,166 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockProvider struct {
+	generateErr error
+	unwrapErr   error
+	unwrapCalls int
+}
+
+func (mp *mockProvider) GenerateDataKey(ctx context.Context) (key, wrapped []byte, err error) {
+	key = make([]byte, keyLength)
+	return key, []byte("wrapped"), mp.generateErr
+}
+
+func (mp *mockProvider) UnwrapDataKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	mp.unwrapCalls++
+	return make([]byte, keyLength), mp.unwrapErr
+}
+
+func TestNewEncryptorDisabled(t *testing.T) {
+	conf := newTestConfig()
+	conf.Set(ConfEnabled, false)
+	e, err := NewEncryptor(context.Background(), conf)
+	assert.NoError(t, err)
+	assert.Nil(t, e)
+}
+
+func TestNewEncryptorBadProvider(t *testing.T) {
+	_, err := NewEncryptor(context.Background(), newTestConfig())
+	assert.Regexp(t, "FF00183", err)
+}
+
+func TestEncryptDecryptKeyfile(t *testing.T) {
+	ctx := context.Background()
+	conf := newKeyfileConfig(t)
+	e, err := NewEncryptor(ctx, conf)
+	assert.NoError(t, err)
+
+	stored, err := e.Encrypt(ctx, []byte(`{"secret":"value"}`), []byte("ns1:id1"))
+	assert.NoError(t, err)
+	assert.NotContains(t, stored, "secret")
+	assert.NotNil(t, parseEnvelope(stored))
+	assert.True(t, json.Valid([]byte(stored)))
+
+	stored2, err := e.Encrypt(ctx, []byte(`{"secret":"value"}`), []byte("ns1:id1"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, stored, stored2)
+
+	// A new process must unwrap the data key with the provider
+	e2, err := NewEncryptor(ctx, conf)
+	assert.NoError(t, err)
+	plaintext, err := e2.Decrypt(ctx, stored, []byte("ns1:id1"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"secret":"value"}`, string(plaintext))
+
+	// The value is bound to its record
+	_, err = e2.Decrypt(ctx, stored, []byte("ns1:id2"))
+	assert.Regexp(t, "FF10501", err)
+}
+
+func TestEncryptDecryptKMS(t *testing.T) {
+	ctx := context.Background()
+	conf := newLocalKMSConfig(t)
+	e, err := NewEncryptor(ctx, conf)
+	assert.NoError(t, err)
+
+	stored, err := e.Encrypt(ctx, []byte(`"hello"`), nil)
+	assert.NoError(t, err)
+
+	e2, err := NewEncryptor(ctx, conf)
+	assert.NoError(t, err)
+	plaintext, err := e2.Decrypt(ctx, stored, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `"hello"`, string(plaintext))
+}
+
+func TestEncryptGenerateKeyFail(t *testing.T) {
+	e := &Encryptor{
+		provider: &mockProvider{generateErr: fmt.Errorf("pop")},
+		keys:     make(map[string][]byte),
+	}
+	_, err := e.Encrypt(context.Background(), []byte("data"), nil)
+	assert.Regexp(t, "FF10500.*pop", err)
+}
+
+func TestEncryptBadKey(t *testing.T) {
+	e := &Encryptor{
+		currentKey:     []byte("short"),
+		currentWrapped: "d3JhcHBlZA==",
+	}
+	_, err := e.Encrypt(context.Background(), []byte("data"), nil)
+	assert.Regexp(t, "FF10500", err)
+}
+
+func TestDecryptCachesKeys(t *testing.T) {
+	ctx := context.Background()
+	mp := &mockProvider{}
+	e := &Encryptor{provider: mp, keys: make(map[string][]byte)}
+	stored, err := e.Encrypt(ctx, []byte("data"), nil)
+	assert.NoError(t, err)
+
+	e.keys = make(map[string][]byte)
+	for i := 0; i < 3; i++ {
+		plaintext, err := e.Decrypt(ctx, stored, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "data", string(plaintext))
+	}
+	assert.Equal(t, 1, mp.unwrapCalls)
+}
+
+func TestDecryptErrors(t *testing.T) {
+	ctx := context.Background()
+	mp := &mockProvider{}
+	e := &Encryptor{provider: mp, keys: make(map[string][]byte)}
+
+	_, err := e.Decrypt(ctx, `"plaintext"`, nil)
+	assert.Regexp(t, "FF10501.*not encrypted", err)
+
+	_, err = e.Decrypt(ctx, `{"ffenc":1,"key":"!!!","data":"AAAA"}`, nil)
+	assert.Regexp(t, "FF10501", err)
+
+	_, err = e.Decrypt(ctx, `{"ffenc":1,"key":"d3JhcHBlZA==","data":"!!!"}`, nil)
+	assert.Regexp(t, "FF10501", err)
+
+	_, err = e.Decrypt(ctx, `{"ffenc":1,"key":"d3JhcHBlZA==","data":"AAAA"}`, nil)
+	assert.Regexp(t, "FF10501", err)
+
+	mp.unwrapErr = fmt.Errorf("pop")
+	_, err = e.Decrypt(ctx, `{"ffenc":1,"key":"b3RoZXI=","data":"AAAA"}`, nil)
+	assert.Regexp(t, "FF10501.*pop", err)
+}
+
+func TestParseEnvelope(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString([]byte("key"))
+	assert.NotNil(t, parseEnvelope(`{"ffenc":1,"key":"`+key+`","data":"AAAA"}`))
+	assert.Nil(t, parseEnvelope(`{"ffenc":2,"key":"`+key+`","data":"AAAA"}`))
+	assert.Nil(t, parseEnvelope(`{"ffenc":1,"key":"`+key+`"}`))
+	assert.Nil(t, parseEnvelope(`{"ffenc":1,`))
+	assert.Nil(t, parseEnvelope(`{"some":"json"}`))
+	assert.Nil(t, parseEnvelope(``))
+}
@@ -0,0 +1,242 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+const keyLength = 32 // AES-256
+
+// KeyProvider supplies the data keys used to encrypt values, and recovers them from the wrapped form
+// that is stored alongside each encrypted value
+type KeyProvider interface {
+	// GenerateDataKey returns a new data key, and the same key wrapped by a master key the provider holds
+	GenerateDataKey(ctx context.Context) (key, wrapped []byte, err error)
+	// UnwrapDataKey recovers a data key from its wrapped form
+	UnwrapDataKey(ctx context.Context, wrapped []byte) (key []byte, err error)
+}
+
+// KMS is the shape of a key management service, that holds master keys which never leave the service.
+// As with the cloud services, the wrapped data key identifies the master key needed to decrypt it.
+type KMS interface {
+	GenerateDataKey(ctx context.Context, keyID string) (plaintext, ciphertextBlob []byte, err error)
+	Decrypt(ctx context.Context, ciphertextBlob []byte) (plaintext []byte, err error)
+}
+
+var providerFactories = map[string]func(ctx context.Context, conf config.Section) (KeyProvider, error){
+	ProviderKeyfile: newKeyfileProvider,
+	ProviderKMS:     newKMSProvider,
+}
+
+var kmsFactories = map[string]func(ctx context.Context, conf config.Section) (KMS, error){
+	KMSTypeLocal: newLocalKMS,
+}
+
+func getKeyProvider(ctx context.Context, conf config.Section) (KeyProvider, error) {
+	providerType := conf.GetString(ConfProvider)
+	factory, ok := providerFactories[providerType]
+	if !ok {
+		return nil, i18n.NewError(ctx, coremsgs.MsgEncryptionUnknownProvider, providerType)
+	}
+	return factory(ctx, conf)
+}
+
+// readKeyFile reads a master key, stored as hex in a file
+func readKeyFile(ctx context.Context, path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgEncryptionKeyInvalid, path, err)
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgEncryptionKeyInvalid, path, err)
+	}
+	if len(key) != keyLength {
+		return nil, i18n.NewError(ctx, coremsgs.MsgEncryptionKeyInvalid, path, fmt.Sprintf("must be %d bytes", keyLength))
+	}
+	return key, nil
+}
+
+func newDataKey() []byte {
+	key := make([]byte, keyLength)
+	_, _ = rand.Read(key)
+	return key
+}
+
+// seal encrypts with AES-GCM, returning the random nonce followed by the ciphertext
+func seal(key, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, _ = rand.Read(nonce)
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// open decrypts the output of seal
+func open(ctx context.Context, key, sealed, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, i18n.NewError(ctx, coremsgs.MsgDecryptionFailed, "too short")
+	}
+	return gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], aad)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+type keyfileProvider struct {
+	masterKey []byte
+}
+
+func newKeyfileProvider(ctx context.Context, conf config.Section) (KeyProvider, error) {
+	path := conf.GetString(ConfKeyfilePath)
+	if path == "" {
+		return nil, i18n.NewError(ctx, i18n.MsgMissingConfig, ConfKeyfilePath, ProviderKeyfile)
+	}
+	masterKey, err := readKeyFile(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return &keyfileProvider{masterKey: masterKey}, nil
+}
+
+func (kp *keyfileProvider) GenerateDataKey(ctx context.Context) (key, wrapped []byte, err error) {
+	key = newDataKey()
+	wrapped, err = seal(kp.masterKey, key, nil)
+	return key, wrapped, err
+}
+
+func (kp *keyfileProvider) UnwrapDataKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	return open(ctx, kp.masterKey, wrapped, nil)
+}
+
+type kmsProvider struct {
+	kms   KMS
+	keyID string
+}
+
+func newKMSProvider(ctx context.Context, conf config.Section) (KeyProvider, error) {
+	keyID := conf.GetString(ConfKMSKeyID)
+	if keyID == "" {
+		return nil, i18n.NewError(ctx, i18n.MsgMissingConfig, ConfKMSKeyID, ProviderKMS)
+	}
+	kmsType := conf.GetString(ConfKMSType)
+	factory, ok := kmsFactories[kmsType]
+	if !ok {
+		return nil, i18n.NewError(ctx, coremsgs.MsgEncryptionUnknownKMS, kmsType)
+	}
+	kms, err := factory(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+	return &kmsProvider{kms: kms, keyID: keyID}, nil
+}
+
+func (kp *kmsProvider) GenerateDataKey(ctx context.Context) (key, wrapped []byte, err error) {
+	return kp.kms.GenerateDataKey(ctx, kp.keyID)
+}
+
+func (kp *kmsProvider) UnwrapDataKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	return kp.kms.Decrypt(ctx, wrapped)
+}
+
+// localKMS is a stand-in for a key management service, for development and test. Each master key
+// is stored as hex in a "<keyId>.key" file in the key directory.
+type localKMS struct {
+	keyDir     string
+	mux        sync.Mutex
+	masterKeys map[string][]byte
+}
+
+func newLocalKMS(ctx context.Context, conf config.Section) (KMS, error) {
+	keyDir := conf.GetString(ConfKMSLocalKeyDir)
+	if keyDir == "" {
+		return nil, i18n.NewError(ctx, i18n.MsgMissingConfig, ConfKMSLocalKeyDir, ProviderKMS)
+	}
+	return &localKMS{
+		keyDir:     keyDir,
+		masterKeys: make(map[string][]byte),
+	}, nil
+}
+
+func (lk *localKMS) masterKey(ctx context.Context, keyID string) ([]byte, error) {
+	lk.mux.Lock()
+	defer lk.mux.Unlock()
+	if key, ok := lk.masterKeys[keyID]; ok {
+		return key, nil
+	}
+	if keyID == "" || filepath.Base(keyID) != keyID {
+		return nil, i18n.NewError(ctx, coremsgs.MsgEncryptionKeyInvalid, keyID, "invalid key ID")
+	}
+	key, err := readKeyFile(ctx, filepath.Join(lk.keyDir, keyID+".key"))
+	if err != nil {
+		return nil, err
+	}
+	lk.masterKeys[keyID] = key
+	return key, nil
+}
+
+// GenerateDataKey returns the ID of the master key as a length prefix, followed by the sealed data key
+func (lk *localKMS) GenerateDataKey(ctx context.Context, keyID string) (plaintext, ciphertextBlob []byte, err error) {
+	masterKey, err := lk.masterKey(ctx, keyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	plaintext = newDataKey()
+	sealed, err := seal(masterKey, plaintext, []byte(keyID))
+	if err != nil {
+		return nil, nil, err
+	}
+	ciphertextBlob = append([]byte{byte(len(keyID))}, keyID...)
+	return plaintext, append(ciphertextBlob, sealed...), nil
+}
+
+func (lk *localKMS) Decrypt(ctx context.Context, ciphertextBlob []byte) ([]byte, error) {
+	if len(ciphertextBlob) == 0 || len(ciphertextBlob) < 1+int(ciphertextBlob[0]) {
+		return nil, i18n.NewError(ctx, coremsgs.MsgDecryptionFailed, "too short")
+	}
+	keyID := string(ciphertextBlob[1 : 1+ciphertextBlob[0]])
+	masterKey, err := lk.masterKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	return open(ctx, masterKey, ciphertextBlob[1+len(keyID):], []byte(keyID))
+}
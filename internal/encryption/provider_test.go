@@ -0,0 +1,208 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"context"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeKey(t *testing.T, dir, name string) string {
+	path := filepath.Join(dir, name)
+	err := os.WriteFile(path, []byte(hex.EncodeToString(newDataKey())+"\n"), 0600)
+	assert.NoError(t, err)
+	return path
+}
+
+func newTestConfig() config.Section {
+	coreconfig.Reset()
+	conf := config.RootSection("encryption")
+	InitConfig(conf)
+	conf.Set(ConfEnabled, true)
+	return conf
+}
+
+func newKeyfileConfig(t *testing.T) config.Section {
+	conf := newTestConfig()
+	conf.Set(ConfKeyfilePath, writeKey(t, t.TempDir(), "master.key"))
+	return conf
+}
+
+func newLocalKMSConfig(t *testing.T) config.Section {
+	dir := t.TempDir()
+	writeKey(t, dir, "key1.key")
+	conf := newTestConfig()
+	conf.Set(ConfProvider, ProviderKMS)
+	conf.Set(ConfKMSKeyID, "key1")
+	conf.Set(ConfKMSLocalKeyDir, dir)
+	return conf
+}
+
+func TestKeyfileProviderRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	kp, err := getKeyProvider(ctx, newKeyfileConfig(t))
+	assert.NoError(t, err)
+
+	key, wrapped, err := kp.GenerateDataKey(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, key, keyLength)
+	assert.NotContains(t, string(wrapped), string(key))
+
+	unwrapped, err := kp.UnwrapDataKey(ctx, wrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, key, unwrapped)
+
+	_, err = kp.UnwrapDataKey(ctx, []byte("short"))
+	assert.Regexp(t, "FF10501", err)
+}
+
+func TestKeyfileProviderMissingPath(t *testing.T) {
+	_, err := getKeyProvider(context.Background(), newTestConfig())
+	assert.Regexp(t, "FF00183.*keyfile.path", err)
+}
+
+func TestKeyfileProviderMissingFile(t *testing.T) {
+	conf := newTestConfig()
+	conf.Set(ConfKeyfilePath, filepath.Join(t.TempDir(), "missing.key"))
+	_, err := getKeyProvider(context.Background(), conf)
+	assert.Regexp(t, "FF10499", err)
+}
+
+func TestKeyfileProviderBadHex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "master.key")
+	err := os.WriteFile(path, []byte("not hex"), 0600)
+	assert.NoError(t, err)
+	conf := newTestConfig()
+	conf.Set(ConfKeyfilePath, path)
+	_, err = getKeyProvider(context.Background(), conf)
+	assert.Regexp(t, "FF10499", err)
+}
+
+func TestKeyfileProviderBadLength(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "master.key")
+	err := os.WriteFile(path, []byte("0102030405"), 0600)
+	assert.NoError(t, err)
+	conf := newTestConfig()
+	conf.Set(ConfKeyfilePath, path)
+	_, err = getKeyProvider(context.Background(), conf)
+	assert.Regexp(t, "FF10499.*32 bytes", err)
+}
+
+func TestUnknownProvider(t *testing.T) {
+	conf := newTestConfig()
+	conf.Set(ConfProvider, "wrong")
+	_, err := getKeyProvider(context.Background(), conf)
+	assert.Regexp(t, "FF10497", err)
+}
+
+func TestKMSProviderRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	conf := newLocalKMSConfig(t)
+	kp, err := getKeyProvider(ctx, conf)
+	assert.NoError(t, err)
+
+	key, wrapped, err := kp.GenerateDataKey(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, key, keyLength)
+	_, wrapped2, err := kp.GenerateDataKey(ctx)
+	assert.NoError(t, err)
+	assert.NotEqual(t, wrapped, wrapped2)
+
+	// A fresh KMS instance must load the master key identified in the blob
+	kp2, err := getKeyProvider(ctx, conf)
+	assert.NoError(t, err)
+	unwrapped, err := kp2.UnwrapDataKey(ctx, wrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, key, unwrapped)
+}
+
+func TestKMSProviderMissingKeyID(t *testing.T) {
+	conf := newTestConfig()
+	conf.Set(ConfProvider, ProviderKMS)
+	_, err := getKeyProvider(context.Background(), conf)
+	assert.Regexp(t, "FF00183.*kms.keyId", err)
+}
+
+func TestKMSProviderUnknownType(t *testing.T) {
+	conf := newTestConfig()
+	conf.Set(ConfProvider, ProviderKMS)
+	conf.Set(ConfKMSKeyID, "key1")
+	conf.Set(ConfKMSType, "wrong")
+	_, err := getKeyProvider(context.Background(), conf)
+	assert.Regexp(t, "FF10498", err)
+}
+
+func TestKMSProviderMissingKeyDir(t *testing.T) {
+	conf := newTestConfig()
+	conf.Set(ConfProvider, ProviderKMS)
+	conf.Set(ConfKMSKeyID, "key1")
+	_, err := getKeyProvider(context.Background(), conf)
+	assert.Regexp(t, "FF00183.*kms.local.keyDir", err)
+}
+
+func TestLocalKMSMissingKey(t *testing.T) {
+	ctx := context.Background()
+	conf := newLocalKMSConfig(t)
+	conf.Set(ConfKMSKeyID, "key2")
+	kp, err := getKeyProvider(ctx, conf)
+	assert.NoError(t, err)
+	_, _, err = kp.GenerateDataKey(ctx)
+	assert.Regexp(t, "FF10499", err)
+}
+
+func TestLocalKMSInvalidKeyID(t *testing.T) {
+	ctx := context.Background()
+	conf := newLocalKMSConfig(t)
+	conf.Set(ConfKMSKeyID, "../key1")
+	kp, err := getKeyProvider(ctx, conf)
+	assert.NoError(t, err)
+	_, _, err = kp.GenerateDataKey(ctx)
+	assert.Regexp(t, "FF10499.*invalid key ID", err)
+}
+
+func TestLocalKMSDecryptBadBlob(t *testing.T) {
+	ctx := context.Background()
+	kp, err := getKeyProvider(ctx, newLocalKMSConfig(t))
+	assert.NoError(t, err)
+
+	_, err = kp.UnwrapDataKey(ctx, []byte{})
+	assert.Regexp(t, "FF10501", err)
+	_, err = kp.UnwrapDataKey(ctx, []byte{10, 'k'})
+	assert.Regexp(t, "FF10501", err)
+	_, err = kp.UnwrapDataKey(ctx, []byte{4, 'k', 'e', 'y', '2'})
+	assert.Regexp(t, "FF10499", err)
+}
+
+func TestSealBadKey(t *testing.T) {
+	_, err := seal([]byte("short"), []byte("data"), nil)
+	assert.Error(t, err)
+	_, err = open(context.Background(), []byte("short"), []byte("data"), nil)
+	assert.Error(t, err)
+}
+
+func TestLocalKMSBadMasterKey(t *testing.T) {
+	lk := &localKMS{masterKeys: map[string][]byte{"key1": []byte("short")}}
+	_, _, err := lk.GenerateDataKey(context.Background(), "key1")
+	assert.Error(t, err)
+}
@@ -18,12 +18,17 @@ package events
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
 )
 
 func buildBlockchainEvent(ns string, subID *fftypes.UUID, event *blockchain.Event, tx *core.BlockchainTransactionRef) *core.BlockchainEvent {
@@ -46,7 +51,7 @@ func buildBlockchainEvent(ns string, subID *fftypes.UUID, event *blockchain.Even
 
 func (em *eventManager) getChainListenerByProtocolIDCached(ctx context.Context, protocolID string) (*core.ContractListener, error) {
 	return em.getChainListenerCached(fmt.Sprintf("pid:%s", protocolID), func() (*core.ContractListener, error) {
-		return em.database.GetContractListenerByBackendID(ctx, em.namespace.Name, protocolID)
+		return em.database.GetContractListenerByBackendID(database.WithPrimary(ctx), em.namespace.Name, protocolID)
 	})
 }
 
@@ -99,6 +104,97 @@ func (em *eventManager) emitBlockchainEventMetric(event *blockchain.Event) {
 	}
 }
 
+// indexedParamSchema is the part of the schema of an event parameter that determines how its values compare
+type indexedParamSchema struct {
+	Type    string `json:"type"`
+	Details struct {
+		Type string `json:"type"`
+	} `json:"details"`
+}
+
+// indexedParamType returns the ABI type of a parameter of the event, or the JSON schema type for blockchains
+// that do not have one
+func indexedParamType(event *core.FFISerializedEvent, name string) string {
+	if event == nil {
+		return ""
+	}
+	for _, param := range event.Params {
+		if param.Name == name {
+			var schema indexedParamSchema
+			_ = json.Unmarshal(param.Schema.Bytes(), &schema)
+			if schema.Details.Type != "" {
+				return schema.Details.Type
+			}
+			return schema.Type
+		}
+	}
+	return ""
+}
+
+func toBigInt(v interface{}) (*big.Int, bool) {
+	switch v := v.(type) {
+	case string:
+		// Decimal, or 0x prefixed hex
+		return new(big.Int).SetString(v, 0)
+	case float64:
+		// Large numbers parsed from JSON are in exponent form, which only represents an integer if it is exact
+		i, accuracy := big.NewFloat(v).Int(nil)
+		return i, accuracy == big.Exact
+	case json.Number:
+		return toBigInt(v.String())
+	case int64:
+		return big.NewInt(v), true
+	case int:
+		return big.NewInt(int64(v)), true
+	default:
+		return nil, false
+	}
+}
+
+// indexedValueMatches compares the value of an indexed parameter of an event with the value in a filter,
+// according to the type of the parameter: integers by value whatever their format, addresses and other
+// hex values case-insensitively, and everything else exactly
+func indexedValueMatches(paramType string, expected, actual interface{}) bool {
+	switch {
+	case paramType == "integer" || strings.HasPrefix(paramType, "int") || strings.HasPrefix(paramType, "uint"):
+		e, ok1 := toBigInt(expected)
+		a, ok2 := toBigInt(actual)
+		return ok1 && ok2 && e.Cmp(a) == 0
+	case paramType == "address" || strings.HasPrefix(paramType, "bytes"):
+		e, ok1 := expected.(string)
+		a, ok2 := actual.(string)
+		return ok1 && ok2 && strings.EqualFold(e, a)
+	default:
+		return reflect.DeepEqual(expected, actual)
+	}
+}
+
+// matchListenerFilter returns the index of the first filter on the listener that matches the event,
+// comparing the signature (qualified by the location when the filter has one) and any indexed values.
+// Listeners without filters match all events delivered to them by the connector.
+func matchListenerFilter(listener *core.ContractListener, event *blockchain.Event) (*int, bool) {
+	if len(listener.Filters) == 0 {
+		return nil, true
+	}
+	for i, f := range listener.Filters {
+		if f.Signature != event.Signature && f.Signature != event.Location+":"+event.Signature {
+			continue
+		}
+		matched := true
+		for name, expected := range f.Indexed {
+			if !indexedValueMatches(indexedParamType(f.Event, name), expected, event.Output[name]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			idx := i
+			return &idx, true
+		}
+	}
+	return nil, false
+}
+
 func (em *eventManager) BlockchainEvent(event *blockchain.EventWithSubscription) error {
 	return em.retry.Do(em.ctx, "persist blockchain event", func(attempt int) (bool, error) {
 		err := em.database.RunAsGroup(em.ctx, func(ctx context.Context) error {
@@ -116,9 +212,16 @@ func (em *eventManager) BlockchainEvent(event *blockchain.EventWithSubscription)
 			}
 			listener.Namespace = em.namespace.Name
 
+			filter, matched := matchListenerFilter(listener, &event.Event)
+			if !matched {
+				log.L(ctx).Debugf("Ignoring blockchain event %s that does not match the filters of listener %s", event.ProtocolID, listener.ID)
+				return nil
+			}
+
 			chainEvent := buildBlockchainEvent(listener.Namespace, listener.ID, &event.Event, &core.BlockchainTransactionRef{
 				BlockchainID: event.BlockchainTXID,
 			})
+			chainEvent.Filter = filter
 			if err := em.maybePersistBlockchainEvent(ctx, chainEvent, listener); err != nil {
 				return err
 			}
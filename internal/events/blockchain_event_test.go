@@ -17,6 +17,7 @@
 package events
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -124,6 +125,151 @@ func TestContractEventWrongNS(t *testing.T) {
 
 }
 
+func testTransferEvent() *core.FFISerializedEvent {
+	return &core.FFISerializedEvent{
+		FFIEventDefinition: fftypes.FFIEventDefinition{
+			Name: "Transfer",
+			Params: fftypes.FFIParams{
+				{Name: "from", Schema: fftypes.JSONAnyPtr(`{"type":"string","details":{"type":"address","indexed":true}}`)},
+				{Name: "value", Schema: fftypes.JSONAnyPtr(`{"type":"integer","details":{"type":"uint256","indexed":true}}`)},
+				{Name: "memo", Schema: fftypes.JSONAnyPtr(`{"type":"string","details":{"type":"string","indexed":true}}`)},
+				{Name: "id", Schema: fftypes.JSONAnyPtr(`{"type":"string","details":{"type":"bytes32","indexed":true}}`)},
+				{Name: "count", Schema: fftypes.JSONAnyPtr(`{"type":"integer"}`)},
+			},
+		},
+	}
+}
+
+func TestMatchListenerFilterIndexedTypes(t *testing.T) {
+	event := &blockchain.Event{
+		Signature: "Transfer(address,uint256)",
+		Output: fftypes.JSONObject{
+			"from":  "0xABCdef",
+			"value": "1000000000000000000000",
+			"memo":  "Hello",
+			"id":    "0xAB12",
+			"count": float64(5),
+			"other": "Value",
+		},
+	}
+	for _, test := range []struct {
+		indexed fftypes.JSONObject
+		matched bool
+	}{
+		{fftypes.JSONObject{"from": "0xabcDEF"}, true},
+		{fftypes.JSONObject{"from": "0xabc"}, false},
+		{fftypes.JSONObject{"from": float64(1)}, false},
+		{fftypes.JSONObject{"value": float64(1e21)}, true},
+		{fftypes.JSONObject{"value": "1000000000000000000000"}, true},
+		{fftypes.JSONObject{"value": "0x3635c9adc5dea00000"}, true},
+		{fftypes.JSONObject{"value": json.Number("1000000000000000000000")}, true},
+		{fftypes.JSONObject{"value": float64(1.5)}, false},
+		{fftypes.JSONObject{"value": "1000000000000000000001"}, false},
+		{fftypes.JSONObject{"value": true}, false},
+		{fftypes.JSONObject{"count": int64(5)}, true},
+		{fftypes.JSONObject{"count": 5}, true},
+		{fftypes.JSONObject{"memo": "Hello"}, true},
+		{fftypes.JSONObject{"memo": "hello"}, false},
+		{fftypes.JSONObject{"id": "0xab12"}, true},
+		{fftypes.JSONObject{"other": "Value"}, true},
+		{fftypes.JSONObject{"other": "value"}, false},
+	} {
+		listener := &core.ContractListener{
+			Filters: core.ListenerFilters{
+				{Signature: "Transfer(address,uint256)", Event: testTransferEvent(), Indexed: test.indexed},
+			},
+		}
+		_, matched := matchListenerFilter(listener, event)
+		assert.Equal(t, test.matched, matched, "indexed: %s", test.indexed)
+	}
+}
+
+func TestMatchListenerFilterNoEvent(t *testing.T) {
+	listener := &core.ContractListener{
+		Filters: core.ListenerFilters{
+			{Signature: "Transfer(address,uint256)", Indexed: fftypes.JSONObject{"from": "0xabc"}},
+		},
+	}
+	_, matched := matchListenerFilter(listener, &blockchain.Event{
+		Signature: "Transfer(address,uint256)",
+		Output:    fftypes.JSONObject{"from": "0xabc"},
+	})
+	assert.True(t, matched)
+}
+
+func TestContractEventMatchFilter(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+
+	ev := &blockchain.EventWithSubscription{
+		Subscription: "sb-1",
+		Event: blockchain.Event{
+			BlockchainTXID: "0xabcd1234",
+			ProtocolID:     "10/20/30",
+			Name:           "Transfer",
+			Location:       "address=0x123",
+			Signature:      "Transfer(address,uint256)",
+			Output: fftypes.JSONObject{
+				"from":  "0xABC",
+				"value": "1",
+			},
+		},
+	}
+	sub := &core.ContractListener{
+		Namespace: "ns1",
+		ID:        fftypes.NewUUID(),
+		Topic:     "topic1",
+		Filters: core.ListenerFilters{
+			{Signature: "Changed(uint256)"},
+			{Signature: "address=0x123:Transfer(address,uint256)", Event: testTransferEvent(), Indexed: fftypes.JSONObject{"from": "0xdef"}},
+			{Signature: "address=0x123:Transfer(address,uint256)", Event: testTransferEvent(), Indexed: fftypes.JSONObject{"from": "0xabc"}},
+		},
+	}
+
+	em.mdi.On("GetContractListenerByBackendID", mock.Anything, "ns1", "sb-1").Return(sub, nil)
+	em.mth.On("InsertOrGetBlockchainEvent", mock.Anything, mock.MatchedBy(func(e *core.BlockchainEvent) bool {
+		return e.Filter != nil && *e.Filter == 2
+	})).Return(nil, nil)
+	em.mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(nil)
+
+	err := em.BlockchainEvent(ev)
+	assert.NoError(t, err)
+
+}
+
+func TestContractEventNoFilterMatch(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+
+	ev := &blockchain.EventWithSubscription{
+		Subscription: "sb-1",
+		Event: blockchain.Event{
+			BlockchainTXID: "0xabcd1234",
+			ProtocolID:     "10/20/30",
+			Name:           "Transfer",
+			Location:       "address=0x123",
+			Signature:      "Transfer(address,uint256)",
+			Output: fftypes.JSONObject{
+				"from": "0xabc",
+			},
+		},
+	}
+	sub := &core.ContractListener{
+		Namespace: "ns1",
+		ID:        fftypes.NewUUID(),
+		Topic:     "topic1",
+		Filters: core.ListenerFilters{
+			{Signature: "Transfer(address,uint256)", Event: testTransferEvent(), Indexed: fftypes.JSONObject{"from": "0xdef"}},
+		},
+	}
+
+	em.mdi.On("GetContractListenerByBackendID", mock.Anything, "ns1", "sb-1").Return(sub, nil)
+
+	err := em.BlockchainEvent(ev)
+	assert.NoError(t, err)
+
+}
+
 func TestPersistBlockchainEventDuplicate(t *testing.T) {
 	em := newTestEventManager(t)
 	defer em.cleanup(t)
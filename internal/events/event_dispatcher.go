@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/ffapi"
@@ -46,6 +47,12 @@ type ackNack struct {
 	id     fftypes.UUID
 	isNack bool
 	offset int64
+	batch  []fftypes.UUID
+}
+
+type deadLetterReplay struct {
+	deadLetter *core.DeadLetter
+	event      *core.Event
 }
 
 type eventDispatcher struct {
@@ -67,8 +74,17 @@ type eventDispatcher struct {
 	mux           sync.Mutex
 	namespace     string
 	readAhead     int
-	subscription  *subscription
-	txHelper      txcommon.Helper
+	batch         bool
+	batchTimeout  time.Duration
+	batches       map[fftypes.UUID][]*core.Event
+	maxAttempts   uint32
+	attempts      map[fftypes.UUID]int64
+	replays       map[fftypes.UUID]*deadLetterReplay
+	partitionKey  partitionKeyFunc
+	// the partition of each in-flight event, when the subscription has a partition key
+	inflightPartitions map[fftypes.UUID]string
+	subscription       *subscription
+	txHelper           txcommon.Helper
 }
 
 func newEventDispatcher(ctx context.Context, enricher *eventEnricher, ei events.Plugin, di database.Plugin, dm data.Manager, bm broadcast.Manager, pm privatemessaging.Manager, connID string, sub *subscription, en *eventNotifier, txHelper txcommon.Helper) *eventDispatcher {
@@ -80,26 +96,48 @@ func newEventDispatcher(ctx context.Context, enricher *eventEnricher, ei events.
 	if readAhead > maxReadAhead {
 		readAhead = maxReadAhead
 	}
+	batch := sub.definition.Options.Batch != nil && *sub.definition.Options.Batch
+	batchTimeout := config.GetDuration(coreconfig.SubscriptionDefaultsBatchTimeout)
+	if sub.definition.Options.BatchTimeout != nil {
+		batchTimeout = time.Duration(*sub.definition.Options.BatchTimeout)
+	}
+	var maxAttempts uint32
+	if sub.definition.Options.Retry != nil {
+		maxAttempts = sub.definition.Options.Retry.MaxAttempts
+	}
+	var partitionKey partitionKeyFunc
+	if sub.definition.Options.PartitionKey != nil {
+		// The key is validated when the subscription is created
+		partitionKey = partitionKeys[*sub.definition.Options.PartitionKey]
+	}
 	ed := &eventDispatcher{
 		ctx: log.WithLogField(log.WithLogField(ctx,
 			"role", fmt.Sprintf("ed[%s]", connID)),
 			"sub", fmt.Sprintf("%s/%s:%s", sub.definition.ID, sub.definition.Namespace, sub.definition.Name)),
-		enricher:      enricher,
-		database:      di,
-		transport:     ei,
-		broadcast:     bm,
-		messaging:     pm,
-		data:          dm,
-		connID:        connID,
-		cancelCtx:     cancelCtx,
-		subscription:  sub,
-		namespace:     sub.definition.Namespace,
-		inflight:      make(map[fftypes.UUID]*core.Event),
-		eventDelivery: make(chan *core.EventDelivery, readAhead+1),
-		readAhead:     int(readAhead),
-		acksNacks:     make(chan ackNack),
-		closed:        make(chan struct{}),
-		txHelper:      txHelper,
+		enricher:           enricher,
+		database:           di,
+		transport:          ei,
+		broadcast:          bm,
+		messaging:          pm,
+		data:               dm,
+		connID:             connID,
+		cancelCtx:          cancelCtx,
+		subscription:       sub,
+		namespace:          sub.definition.Namespace,
+		inflight:           make(map[fftypes.UUID]*core.Event),
+		eventDelivery:      make(chan *core.EventDelivery, readAhead+1),
+		readAhead:          int(readAhead),
+		batch:              batch,
+		batchTimeout:       batchTimeout,
+		batches:            make(map[fftypes.UUID][]*core.Event),
+		maxAttempts:        maxAttempts,
+		attempts:           make(map[fftypes.UUID]int64),
+		replays:            make(map[fftypes.UUID]*deadLetterReplay),
+		partitionKey:       partitionKey,
+		inflightPartitions: make(map[fftypes.UUID]string),
+		acksNacks:          make(chan ackNack),
+		closed:             make(chan struct{}),
+		txHelper:           txHelper,
 	}
 
 	pollerConf := &eventPollerConf{
@@ -146,12 +184,33 @@ func (ed *eventDispatcher) electAndStart() {
 		l.Debugf("Closed before we became leader")
 		return
 	}
+	var lost <-chan struct{}
+	if !ed.subscription.definition.Ephemeral {
+		// Durable subscriptions are loaded by every process sharing the database, so we also need to be elected
+		// across all of those processes - otherwise each process would deliver every event
+		var err error
+		if lost, err = ed.database.AcquireLeadership(ed.ctx, "subscription:"+ed.subscription.definition.ID.String()); err != nil {
+			l.Debugf("Closed before we became leader across processes")
+			return
+		}
+	}
 	// We're ready to go - not
 	ed.elected = true
 	ed.eventPoller.start()
-	go ed.deliverEvents()
+	if ed.batch {
+		go ed.deliverBatchedEvents()
+	} else {
+		go ed.deliverEvents()
+	}
 	// Wait until the event poller closes
-	<-ed.eventPoller.closed
+	select {
+	case <-ed.eventPoller.closed:
+	case <-lost:
+		// Another process might already have taken over, so we must stop delivering
+		l.Errorf("Lost leadership of subscription - delivery stopped in this process")
+		ed.cancelCtx()
+		<-ed.eventPoller.closed
+	}
 }
 
 func (ed *eventDispatcher) getEvents(ctx context.Context, filter ffapi.Filter, offset int64) ([]core.LocallySequenced, error) {
@@ -253,6 +312,10 @@ func (ed *eventDispatcher) filterEvents(candidates []*core.EventDelivery) []*cor
 			}
 		}
 
+		if filter.expressionFilter != nil && !matchFilterExpression(filter.expressionFilter, event) {
+			continue
+		}
+
 		matchingEvents = append(matchingEvents, event)
 	}
 	return matchingEvents
@@ -287,7 +350,10 @@ func (ed *eventDispatcher) bufferedDelivery(events []core.LocallySequenced) (boo
 		var disapatchable []*core.EventDelivery
 		inflightCount := len(ed.inflight)
 		maxDispatch := 1 + ed.readAhead - inflightCount
-		if maxDispatch >= len(matching) {
+		if ed.partitionKey != nil {
+			// Events on different partitions can overtake each other, but never those on the same partition
+			disapatchable, matching = ed.partitionedDispatchableLocked(matching, maxDispatch)
+		} else if maxDispatch >= len(matching) {
 			disapatchable = matching
 			matching = nil
 		} else if maxDispatch > 0 {
@@ -302,6 +368,9 @@ func (ed *eventDispatcher) bufferedDelivery(events []core.LocallySequenced) (boo
 		for _, event := range disapatchable {
 			ed.mux.Lock()
 			ed.inflight[*event.ID] = &event.Event
+			if ed.partitionKey != nil {
+				ed.inflightPartitions[*event.ID] = ed.partitionKey(event)
+			}
 			inflightCount = len(ed.inflight)
 			ed.mux.Unlock()
 
@@ -319,11 +388,16 @@ func (ed *eventDispatcher) bufferedDelivery(events []core.LocallySequenced) (boo
 		case <-ed.ctx.Done():
 			return false, i18n.NewError(ed.ctx, coremsgs.MsgDispatcherClosing)
 		case an := <-ed.acksNacks:
+			// With a partition key, events waiting to be dispatched might be lower than those in flight
+			lowestWaiting := int64(-1)
+			if len(matching) > 0 {
+				lowestWaiting = matching[0].Sequence
+			}
 			if an.isNack {
 				nacks++
-				ed.handleNackOffsetUpdate(an)
+				ed.handleNackOffsetUpdate(an, lowestWaiting)
 			} else if nacks == 0 {
-				ed.handleAckOffsetUpdate(an)
+				ed.handleAckOffsetUpdate(an, lowestWaiting)
 				lastAck = an.offset
 			}
 		}
@@ -334,24 +408,45 @@ func (ed *eventDispatcher) bufferedDelivery(events []core.LocallySequenced) (boo
 	return true, nil // poll again straight away for more messages
 }
 
-func (ed *eventDispatcher) handleNackOffsetUpdate(nack ackNack) {
+func (ed *eventDispatcher) handleNackOffsetUpdate(nack ackNack, lowestWaiting int64) {
 	ed.mux.Lock()
 	defer ed.mux.Unlock()
 	// If we're rejected, we need to redeliver all messages from this offset onwards,
 	// even if we've delivered messages after that.
 	// That means resetting the polling offest, and clearing out all our state
 	delete(ed.inflight, nack.id)
-	if ed.eventPoller.pollingOffset > nack.offset {
-		ed.eventPoller.rewindPollingOffset(nack.offset - 1)
+	rewindTo := nack.offset
+	if ed.partitionKey != nil {
+		// Events on other partitions with lower sequences might not have been acknowledged yet
+		for _, inflight := range ed.inflight {
+			if inflight.Sequence < rewindTo {
+				rewindTo = inflight.Sequence
+			}
+		}
+		if lowestWaiting >= 0 && lowestWaiting < rewindTo {
+			rewindTo = lowestWaiting
+		}
+	}
+	if ed.eventPoller.pollingOffset > rewindTo {
+		ed.eventPoller.rewindPollingOffset(rewindTo - 1)
 	}
 	ed.inflight = map[fftypes.UUID]*core.Event{}
+	ed.inflightPartitions = map[fftypes.UUID]string{}
+	ed.batches = map[fftypes.UUID][]*core.Event{}
 }
 
-func (ed *eventDispatcher) handleAckOffsetUpdate(ack ackNack) {
+func (ed *eventDispatcher) handleAckOffsetUpdate(ack ackNack, lowestWaiting int64) {
 	oldOffset := ed.eventPoller.getPollingOffset()
 	ed.mux.Lock()
 	delete(ed.inflight, ack.id)
-	lowestInflight := int64(-1)
+	delete(ed.inflightPartitions, ack.id)
+	delete(ed.attempts, ack.id)
+	for _, id := range ack.batch {
+		delete(ed.inflight, id)
+		delete(ed.attempts, id)
+	}
+	// The offset can only move past events that have been acknowledged, including any still waiting to be dispatched
+	lowestInflight := lowestWaiting
 	for _, inflight := range ed.inflight {
 		if lowestInflight < 0 || inflight.Sequence < lowestInflight {
 			lowestInflight = inflight.Sequence
@@ -378,11 +473,14 @@ func (ed *eventDispatcher) deliverEvents() {
 			if withData && event.Message != nil {
 				data, _, err = ed.data.GetMessageDataCached(ed.ctx, event.Message)
 			}
+			if err == nil {
+				err = ed.transformEvent(event, data)
+			}
 			if err == nil {
 				err = ed.transport.DeliveryRequest(ed.connID, ed.subscription.definition, event, data)
 			}
 			if err != nil {
-				ed.deliveryResponse(&core.EventDeliveryResponse{ID: event.ID, Rejected: true})
+				ed.deliveryResponse(&core.EventDeliveryResponse{ID: event.ID, Rejected: true, Info: err.Error()})
 			}
 		case <-ed.ctx.Done():
 			return
@@ -390,16 +488,186 @@ func (ed *eventDispatcher) deliverEvents() {
 	}
 }
 
+func (ed *eventDispatcher) deliverBatchedEvents() {
+	withData := ed.subscription.definition.Options.WithData != nil && *ed.subscription.definition.Options.WithData
+	maxBatchSize := ed.readAhead
+	if maxBatchSize < 1 {
+		maxBatchSize = 1
+	}
+
+	var batch []*core.EventDelivery
+	var batchTimer *time.Timer
+	var batchTimeoutCh <-chan time.Time
+	for {
+		var timedOut bool
+		select {
+		case event, ok := <-ed.eventDelivery:
+			if !ok {
+				if batchTimer != nil {
+					batchTimer.Stop()
+				}
+				return
+			}
+			if batch == nil {
+				batchTimer = time.NewTimer(ed.batchTimeout)
+				batchTimeoutCh = batchTimer.C
+			}
+			batch = append(batch, event)
+		case <-batchTimeoutCh:
+			timedOut = true
+		case <-ed.ctx.Done():
+			if batchTimer != nil {
+				batchTimer.Stop()
+			}
+			return
+		}
+
+		if len(batch) == 0 || (!timedOut && len(batch) < maxBatchSize) {
+			continue
+		}
+
+		if !timedOut {
+			batchTimer.Stop()
+		}
+		batchTimer = nil
+		batchTimeoutCh = nil
+		ed.dispatchBatch(batch, withData)
+		batch = nil
+	}
+}
+
+func (ed *eventDispatcher) dispatchBatch(batch []*core.EventDelivery, withData bool) {
+	batchID := fftypes.NewUUID()
+	events := make([]*core.CombinedEventDataDelivery, len(batch))
+	batchEvents := make([]*core.Event, len(batch))
+	var err error
+	for i, event := range batch {
+		log.L(ed.ctx).Debugf("Batching %s event: %.10d/%s [%s]: ref=%s/%s batch=%s", ed.transport.Name(), event.Sequence, event.ID, event.Type, event.Namespace, event.Reference, batchID)
+		events[i] = &core.CombinedEventDataDelivery{Event: event}
+		batchEvents[i] = &event.Event
+		if err == nil && withData && event.Message != nil {
+			events[i].Data, _, err = ed.data.GetMessageDataCached(ed.ctx, event.Message)
+		}
+		if err == nil {
+			err = ed.transformEvent(event, events[i].Data)
+		}
+	}
+
+	ed.mux.Lock()
+	ed.batches[*batchID] = batchEvents
+	ed.mux.Unlock()
+
+	log.L(ed.ctx).Debugf("Dispatching %s batch %s containing %d events", ed.transport.Name(), batchID, len(batch))
+	if err == nil {
+		err = ed.transport.BatchDeliveryRequest(ed.connID, ed.subscription.definition, batchID, events)
+	}
+	if err != nil {
+		ed.deliveryResponse(&core.EventDeliveryResponse{ID: batchID, Rejected: true, Info: err.Error()})
+	}
+}
+
+func (ed *eventDispatcher) batchAckNack(batchID *fftypes.UUID, batchEvents []*core.Event, isNack bool) (an ackNack) {
+	an.id = *batchID
+	an.isNack = isNack
+	an.batch = make([]fftypes.UUID, 0, len(batchEvents))
+	for i, event := range batchEvents {
+		an.batch = append(an.batch, *event.ID)
+		// An ack moves us past the highest sequence in the batch, and a nack rewinds us to the lowest
+		if i == 0 || (an.isNack && event.Sequence < an.offset) || (!an.isNack && event.Sequence > an.offset) {
+			an.offset = event.Sequence
+		}
+	}
+	return an
+}
+
+// recordFailedAttemptsLocked counts a rejected delivery against each of the events, and returns dead letters
+// for the events once the retry policy of the subscription is exhausted.
+// Events delivered in a batch are dead-lettered together, as the batch is acknowledged as a whole.
+func (ed *eventDispatcher) recordFailedAttemptsLocked(events []*core.Event, reason string) []*core.DeadLetter {
+	if ed.maxAttempts == 0 {
+		return nil
+	}
+	exhausted := false
+	for _, event := range events {
+		ed.attempts[*event.ID]++
+		if ed.attempts[*event.ID] >= int64(ed.maxAttempts) {
+			exhausted = true
+		}
+	}
+	if !exhausted {
+		return nil
+	}
+	now := fftypes.Now()
+	deadLetters := make([]*core.DeadLetter, len(events))
+	for i, event := range events {
+		deadLetters[i] = &core.DeadLetter{
+			ID:           fftypes.NewUUID(),
+			Namespace:    ed.namespace,
+			Subscription: ed.subscription.definition.SubscriptionRef,
+			Event:        event.ID,
+			Attempts:     ed.attempts[*event.ID],
+			Reason:       reason,
+			Created:      now,
+		}
+	}
+	return deadLetters
+}
+
+// writeDeadLetters persists dead letters, and returns true if the events can now be
+// acknowledged so that the subscription moves on
+func (ed *eventDispatcher) writeDeadLetters(deadLetters []*core.DeadLetter) bool {
+	if len(deadLetters) == 0 {
+		return false
+	}
+	err := ed.database.RunAsGroup(ed.ctx, func(ctx context.Context) error {
+		for _, deadLetter := range deadLetters {
+			if err := ed.database.InsertDeadLetter(ctx, deadLetter); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.L(ed.ctx).Errorf("Failed to write dead letters (events will be redelivered): %s", err)
+		return false
+	}
+	for _, deadLetter := range deadLetters {
+		log.L(ed.ctx).Warnf("Event %s moved to dead letter %s after %d attempts: %s", deadLetter.Event, deadLetter.ID, deadLetter.Attempts, deadLetter.Reason)
+	}
+	return true
+}
+
 func (ed *eventDispatcher) deliveryResponse(response *core.EventDeliveryResponse) {
 	l := log.L(ed.ctx)
 
 	ed.mux.Lock()
 	var an ackNack
+	var deadLetters []*core.DeadLetter
 	event, found := ed.inflight[*response.ID]
 	if found {
 		an.id = *response.ID
 		an.offset = event.Sequence
 		an.isNack = response.Rejected
+		if an.isNack {
+			deadLetters = ed.recordFailedAttemptsLocked([]*core.Event{event}, response.Info)
+		}
+	} else if replay, isReplay := ed.replays[*response.ID]; isReplay {
+		delete(ed.replays, *response.ID)
+		ed.mux.Unlock()
+		ed.replayResponse(replay, response)
+		return
+	} else if ed.batch {
+		if batchEvents, batchFound := ed.batches[*response.ID]; batchFound {
+			delete(ed.batches, *response.ID)
+			if response.Rejected {
+				deadLetters = ed.recordFailedAttemptsLocked(batchEvents, response.Info)
+			}
+			ed.mux.Unlock()
+			an = ed.batchAckNack(response.ID, batchEvents, response.Rejected && !ed.writeDeadLetters(deadLetters))
+			l.Debugf("Response for %s batch %s containing %d events: rejected=%t info='%s'", ed.transport.Name(), response.ID, len(an.batch), response.Rejected, response.Info)
+			ed.sendAckNack(an)
+			return
+		}
 	}
 	ed.mux.Unlock()
 
@@ -416,12 +684,91 @@ func (ed *eventDispatcher) deliveryResponse(response *core.EventDeliveryResponse
 	}
 
 	l.Debugf("Response for %s event: %.10d/%s [%s]: ref=%s/%s rejected=%t info='%s'", ed.transport.Name(), event.Sequence, event.ID, event.Type, event.Namespace, event.Reference, response.Rejected, response.Info)
+	if an.isNack && ed.writeDeadLetters(deadLetters) {
+		// The event has been moved to the dead-letter collection, so we can move past it
+		an.isNack = false
+	}
+	ed.sendAckNack(an)
+}
+
+// replayDeadLetters redelivers dead-lettered events over the connection of this dispatcher.
+// Replays are independent of the offset of the subscription - an ack removes the dead letter,
+// and a nack counts a further failed attempt against it.
+func (ed *eventDispatcher) replayDeadLetters(deadLetters []*core.DeadLetter) ([]*core.DeadLetter, error) {
+	withData := ed.subscription.definition.Options.WithData != nil && *ed.subscription.definition.Options.WithData
+	replayed := make([]*core.DeadLetter, 0, len(deadLetters))
+	for _, deadLetter := range deadLetters {
+		event, err := ed.database.GetEventByID(ed.ctx, ed.namespace, deadLetter.Event)
+		if err != nil {
+			return replayed, err
+		}
+		if event == nil {
+			log.L(ed.ctx).Warnf("Event %s for dead letter %s no longer exists", deadLetter.Event, deadLetter.ID)
+			continue
+		}
+		enrichedEvent, err := ed.enricher.enrichEvent(ed.ctx, event)
+		if err != nil {
+			return replayed, err
+		}
+		delivery := &core.EventDelivery{
+			EnrichedEvent: *enrichedEvent,
+			Subscription:  ed.subscription.definition.SubscriptionRef,
+		}
+		var data core.DataArray
+		if withData && delivery.Message != nil {
+			if data, _, err = ed.data.GetMessageDataCached(ed.ctx, delivery.Message); err != nil {
+				return replayed, err
+			}
+		}
+
+		ed.mux.Lock()
+		ed.replays[*event.ID] = &deadLetterReplay{deadLetter: deadLetter, event: event}
+		ed.mux.Unlock()
+		replayed = append(replayed, deadLetter)
+
+		log.L(ed.ctx).Debugf("Replaying dead letter %s for %s event: %.10d/%s [%s]", deadLetter.ID, ed.transport.Name(), event.Sequence, event.ID, event.Type)
+		// A transform failure is a failed attempt, like any other rejected delivery
+		if err = ed.transformEvent(delivery, data); err == nil {
+			err = ed.transport.DeliveryRequest(ed.connID, ed.subscription.definition, delivery, data)
+		}
+		if err != nil {
+			ed.deliveryResponse(&core.EventDeliveryResponse{ID: event.ID, Rejected: true, Info: err.Error()})
+		}
+	}
+	return replayed, nil
+}
+
+func (ed *eventDispatcher) replayResponse(replay *deadLetterReplay, response *core.EventDeliveryResponse) {
+	l := log.L(ed.ctx)
+	deadLetter := replay.deadLetter
+	if !response.Rejected {
+		if response.Reply != nil {
+			ed.sendReply(ed.ctx, replay.event, response.Reply)
+		}
+		l.Infof("Replay of dead letter %s for event %s succeeded", deadLetter.ID, deadLetter.Event)
+		if err := ed.database.DeleteDeadLetter(ed.ctx, ed.namespace, deadLetter.ID); err != nil {
+			l.Errorf("Failed to delete dead letter %s: %s", deadLetter.ID, err)
+		}
+		return
+	}
+
+	l.Warnf("Replay of dead letter %s for event %s rejected: %s", deadLetter.ID, deadLetter.Event, response.Info)
+	update := database.DeadLetterQueryFactory.NewUpdate(ed.ctx).
+		Set("attempts", deadLetter.Attempts+1).
+		Set("reason", response.Info).
+		Set("updated", fftypes.Now())
+	if err := ed.database.UpdateDeadLetter(ed.ctx, ed.namespace, deadLetter.ID, update); err != nil {
+		l.Errorf("Failed to update dead letter %s: %s", deadLetter.ID, err)
+	}
+}
+
+func (ed *eventDispatcher) sendAckNack(an ackNack) {
 	// We don't do any meaningful work in this call, we just set things up so the right thing
 	// will happen when the poller wakes up. So we need to pass it over
 	select {
 	case ed.acksNacks <- an:
 	case <-ed.ctx.Done():
-		l.Debugf("Delivery response will not be delivered: closing")
+		log.L(ed.ctx).Debugf("Delivery response will not be delivered: closing")
 		return
 	}
 }
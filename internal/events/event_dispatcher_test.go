@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"sync"
 	"testing"
 	"time"
 
@@ -46,6 +47,7 @@ import (
 
 func newTestEventDispatcher(sub *subscription) (*eventDispatcher, func()) {
 	mdi := &databasemocks.Plugin{}
+	mdi.On("AcquireLeadership", mock.Anything, mock.Anything).Return(make(<-chan struct{}), nil).Maybe()
 	mei := &eventsmocks.Plugin{}
 	mei.On("Capabilities").Return(&events.Capabilities{}).Maybe()
 	mei.On("Name").Return("ut").Maybe()
@@ -151,6 +153,134 @@ func TestEventDispatcherLeaderElection(t *testing.T) {
 
 }
 
+// sharedLeadership emulates leadership held in a database shared by multiple processes
+type sharedLeadership struct {
+	mux  sync.Mutex
+	held map[string]bool
+}
+
+func (sl *sharedLeadership) acquire(ctx context.Context, name string) (<-chan struct{}, error) {
+	for {
+		sl.mux.Lock()
+		if !sl.held[name] {
+			sl.held[name] = true
+			sl.mux.Unlock()
+			go func() {
+				<-ctx.Done()
+				sl.mux.Lock()
+				delete(sl.held, name)
+				sl.mux.Unlock()
+			}()
+			return make(chan struct{}), nil
+		}
+		sl.mux.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(1 * time.Millisecond):
+		}
+	}
+}
+
+func TestEventDispatcherLeaderElectionAcrossProcesses(t *testing.T) {
+	subID := fftypes.NewUUID()
+	event := &core.Event{ID: fftypes.NewUUID(), Sequence: 12346, Namespace: "ns1", Type: core.EventTypeBlockchainContractDeployOpSucceeded}
+	leadership := &sharedLeadership{held: make(map[string]bool)}
+	delivered := make(chan string, 10)
+
+	newProcess := func() (*eventDispatcher, func()) {
+		// Each process loads its own copy of the durable subscription
+		ed, cancel := newTestEventDispatcher(&subscription{
+			dispatcherElection: make(chan bool, 1),
+			definition: &core.Subscription{
+				SubscriptionRef: core.SubscriptionRef{Namespace: "ns1", Name: "sub1", ID: subID},
+			},
+		})
+		mdi := ed.database.(*databasemocks.Plugin)
+		mdi.ExpectedCalls = nil
+		mdi.On("AcquireLeadership", mock.Anything, "subscription:"+subID.String()).Return(leadership.acquire)
+		mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, subID.String()).Return(&core.Offset{
+			Type:    core.OffsetTypeSubscription,
+			Name:    subID.String(),
+			Current: 12345,
+			RowID:   333333,
+		}, nil)
+		mdi.On("GetEvents", mock.Anything, "ns1", mock.Anything).Return([]*core.Event{event}, nil, nil)
+		mei := ed.transport.(*eventsmocks.Plugin)
+		mei.On("DeliveryRequest", ed.connID, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			delivered <- ed.connID
+		}).Return(nil)
+		return ed, cancel
+	}
+
+	ed1, cancel1 := newProcess()
+	defer cancel1()
+	ed2, cancel2 := newProcess()
+	defer cancel2()
+
+	ed1.start()
+	assert.Equal(t, ed1.connID, <-delivered)
+	ed2.start()
+
+	// Only the elected process delivers the event
+	select {
+	case connID := <-delivered:
+		assert.Fail(t, "unexpected delivery", connID)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Once it stops, the other process takes over
+	ed1.close()
+	assert.Equal(t, ed2.connID, <-delivered)
+	ed2.close()
+}
+
+func TestEventDispatcherLeadershipLost(t *testing.T) {
+	subID := fftypes.NewUUID()
+	ed, cancel := newTestEventDispatcher(&subscription{
+		dispatcherElection: make(chan bool, 1),
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{Namespace: "ns1", Name: "sub1", ID: subID},
+		},
+	})
+	defer cancel()
+	lost := make(chan struct{})
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.ExpectedCalls = nil
+	mdi.On("AcquireLeadership", mock.Anything, "subscription:"+subID.String()).Return((<-chan struct{})(lost), nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, subID.String()).Return(&core.Offset{
+		Type:    core.OffsetTypeSubscription,
+		Name:    subID.String(),
+		Current: 12345,
+		RowID:   333333,
+	}, nil).Maybe()
+	mdi.On("GetEvents", mock.Anything, "ns1", mock.Anything).Return([]*core.Event{}, nil, nil).Maybe()
+
+	ed.start()
+	close(lost)
+	<-ed.closed
+	assert.Error(t, ed.ctx.Err())
+	ed.close()
+}
+
+func TestEventDispatcherClosedBeforeLeaderAcrossProcesses(t *testing.T) {
+	ed, cancel := newTestEventDispatcher(&subscription{
+		dispatcherElection: make(chan bool, 1),
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{Namespace: "ns1", Name: "sub1", ID: fftypes.NewUUID()},
+		},
+	})
+	defer cancel()
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.ExpectedCalls = nil
+	mdi.On("AcquireLeadership", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	ed.start()
+	<-ed.closed
+	ed.close()
+	assert.False(t, ed.elected)
+}
+
 func TestEventDispatcherReadAheadOutOfOrderAcks(t *testing.T) {
 	log.SetLevel("debug")
 	var five = uint16(5)
@@ -584,6 +714,76 @@ func TestFilterEventsMatch(t *testing.T) {
 	assert.Equal(t, *id6, *matched[0].ID)
 }
 
+func TestFilterEventsMatchExpression(t *testing.T) {
+
+	sub := &subscription{
+		definition: &core.Subscription{},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	id1 := fftypes.NewUUID()
+	id2 := fftypes.NewUUID()
+	id3 := fftypes.NewUUID()
+	cid := fftypes.NewUUID()
+	pool := fftypes.NewUUID()
+	events := []*core.EventDelivery{
+		{
+			EnrichedEvent: core.EnrichedEvent{
+				Event: core.Event{
+					ID:   id1,
+					Type: core.EventTypeMessageConfirmed,
+				},
+				Message: &core.Message{
+					Header: core.MessageHeader{
+						CID: cid,
+					},
+				},
+			},
+		},
+		{
+			EnrichedEvent: core.EnrichedEvent{
+				Event: core.Event{
+					ID:   id2,
+					Type: core.EventTypeTransferConfirmed,
+				},
+				TokenTransfer: &core.TokenTransfer{
+					Pool:   pool,
+					Amount: *fftypes.NewFFBigInt(1000),
+				},
+			},
+		},
+		{
+			EnrichedEvent: core.EnrichedEvent{
+				Event: core.Event{
+					ID:   id3,
+					Type: core.EventTypeBlockchainEventReceived,
+				},
+				BlockchainEvent: &core.BlockchainEvent{
+					Output: fftypes.JSONObject{
+						"value": "Hello World",
+					},
+				},
+			},
+		},
+	}
+
+	ed.subscription.expressionFilter, _ = parseFilterExpression(ed.ctx, "message.header.cid="+cid.String())
+	matched := ed.filterEvents(events)
+	assert.Equal(t, 1, len(matched))
+	assert.Equal(t, *id1, *matched[0].ID)
+
+	ed.subscription.expressionFilter, _ = parseFilterExpression(ed.ctx, "tokenTransfer.amount=>=999&tokenTransfer.pool="+pool.String())
+	matched = ed.filterEvents(events)
+	assert.Equal(t, 1, len(matched))
+	assert.Equal(t, *id2, *matched[0].ID)
+
+	ed.subscription.expressionFilter, _ = parseFilterExpression(ed.ctx, "blockchainEvent.output.value=:@hello")
+	matched = ed.filterEvents(events)
+	assert.Equal(t, 1, len(matched))
+	assert.Equal(t, *id3, *matched[0].ID)
+}
+
 func TestEnrichTransactionEvents(t *testing.T) {
 	log.SetLevel("debug")
 	sub := &subscription{
@@ -1064,3 +1264,400 @@ func TestEventDispatcherWithReply(t *testing.T) {
 	mbm.AssertExpectations(t)
 	mms.AssertExpectations(t)
 }
+
+func TestEventDispatcherBatchDelivery(t *testing.T) {
+	log.SetLevel("debug")
+	three := uint16(3)
+	yes := true
+	longTimeout := fftypes.FFDuration(1 * time.Minute)
+	sub := &subscription{
+		dispatcherElection: make(chan bool, 1),
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+			Ephemeral:       true,
+			Options: core.SubscriptionOptions{
+				SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+					ReadAhead:    &three,
+					Batch:        &yes,
+					BatchTimeout: &longTimeout,
+				},
+			},
+		},
+	}
+
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	assert.True(t, ed.batch)
+	assert.Equal(t, 1*time.Minute, ed.batchTimeout)
+	go ed.deliverBatchedEvents()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mei := ed.transport.(*eventsmocks.Plugin)
+	mdi.On("UpdateOffset", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	type deliveredBatch struct {
+		id     *fftypes.UUID
+		events []*core.CombinedEventDataDelivery
+	}
+	batches := make(chan *deliveredBatch)
+	deliver := mei.On("BatchDeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliver.RunFn = func(a mock.Arguments) {
+		batches <- &deliveredBatch{
+			id:     a.Get(2).(*fftypes.UUID),
+			events: a.Get(3).([]*core.CombinedEventDataDelivery),
+		}
+	}
+
+	ev1 := fftypes.NewUUID()
+	ev2 := fftypes.NewUUID()
+	ev3 := fftypes.NewUUID()
+	bdDone := make(chan struct{})
+	ed.eventPoller.pollingOffset = 100000
+	go func() {
+		repoll, err := ed.bufferedDelivery([]core.LocallySequenced{
+			&core.Event{ID: ev1, Sequence: 100001},
+			&core.Event{ID: ev2, Sequence: 100002},
+			&core.Event{ID: ev3, Sequence: 100003},
+		})
+		assert.NoError(t, err)
+		assert.True(t, repoll)
+		close(bdDone)
+	}()
+
+	// The batch fills to the readahead size, so is sent without waiting for the timeout
+	b := <-batches
+	assert.Len(t, b.events, 3)
+	assert.Equal(t, *ev1, *b.events[0].Event.ID)
+	assert.Equal(t, *ev3, *b.events[2].Event.ID)
+
+	// A single ack for the whole batch
+	ed.deliveryResponse(&core.EventDeliveryResponse{ID: b.id})
+
+	<-bdDone
+	assert.Equal(t, int64(100003), ed.eventPoller.pollingOffset)
+	assert.Empty(t, ed.inflight)
+	assert.Empty(t, ed.batches)
+
+	mdi.AssertExpectations(t)
+	mei.AssertExpectations(t)
+}
+
+func TestEventDispatcherBatchTimeoutNackRewind(t *testing.T) {
+	ten := uint16(10)
+	yes := true
+	shortTimeout := fftypes.FFDuration(1 * time.Millisecond)
+	sub := &subscription{
+		definition: &core.Subscription{
+			Options: core.SubscriptionOptions{
+				SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+					ReadAhead:    &ten,
+					Batch:        &yes,
+					BatchTimeout: &shortTimeout,
+				},
+			},
+		},
+	}
+
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	go ed.deliverBatchedEvents()
+
+	mei := ed.transport.(*eventsmocks.Plugin)
+	var batchID *fftypes.UUID
+	delivered := make(chan struct{})
+	deliver := mei.On("BatchDeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliver.RunFn = func(a mock.Arguments) {
+		batchID = a.Get(2).(*fftypes.UUID)
+		assert.Len(t, a.Get(3).([]*core.CombinedEventDataDelivery), 2)
+		close(delivered)
+	}
+
+	bdDone := make(chan struct{})
+	ed.eventPoller.pollingOffset = 100050 // ahead of nack
+	go func() {
+		repoll, err := ed.bufferedDelivery([]core.LocallySequenced{
+			&core.Event{ID: fftypes.NewUUID(), Sequence: 100001},
+			&core.Event{ID: fftypes.NewUUID(), Sequence: 100002},
+		})
+		assert.NoError(t, err)
+		assert.True(t, repoll)
+		close(bdDone)
+	}()
+
+	// Batch is not full, so is delivered on timeout
+	<-delivered
+	ed.deliveryResponse(&core.EventDeliveryResponse{
+		ID:       batchID,
+		Rejected: true,
+	})
+
+	<-bdDone
+	assert.Equal(t, int64(100000), ed.eventPoller.pollingOffset)
+	assert.Empty(t, ed.batches)
+}
+
+func TestEventDispatcherBatchDeliveryFailNack(t *testing.T) {
+	yes := true
+	sub := &subscription{
+		definition: &core.Subscription{
+			Options: core.SubscriptionOptions{
+				SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+					Batch:    &yes,
+					WithData: &yes,
+				},
+			},
+		},
+	}
+
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	ed.acksNacks = make(chan ackNack, 1)
+
+	mdm := ed.data.(*datamocks.Manager)
+	mdm.On("GetMessageDataCached", ed.ctx, mock.Anything).Return(nil, false, fmt.Errorf("pop"))
+
+	ev1 := fftypes.NewUUID()
+	ed.inflight[*ev1] = &core.Event{ID: ev1, Sequence: 12345}
+	ed.dispatchBatch([]*core.EventDelivery{
+		{
+			EnrichedEvent: core.EnrichedEvent{
+				Event: core.Event{ID: ev1, Sequence: 12345},
+				Message: &core.Message{
+					Header: core.MessageHeader{ID: fftypes.NewUUID()},
+				},
+			},
+		},
+	}, true)
+
+	an := <-ed.acksNacks
+	assert.True(t, an.isNack)
+	assert.Equal(t, int64(12345), an.offset)
+	assert.Equal(t, []fftypes.UUID{*ev1}, an.batch)
+
+	mdm.AssertExpectations(t)
+}
+
+func TestEventDispatcherBatchDeliveryClosed(t *testing.T) {
+	yes := true
+	sub := &subscription{
+		definition: &core.Subscription{
+			Options: core.SubscriptionOptions{
+				SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+					Batch: &yes,
+				},
+			},
+		},
+	}
+
+	ed, cancel := newTestEventDispatcher(sub)
+	ed.readAhead = 10
+	ed.eventDelivery <- &core.EventDelivery{}
+	close(ed.eventDelivery)
+	ed.deliverBatchedEvents()
+	cancel()
+
+	ed, cancel = newTestEventDispatcher(sub)
+	ed.readAhead = 10
+	ed.eventDelivery <- &core.EventDelivery{}
+	cancel()
+	ed.deliverBatchedEvents()
+}
+
+func newTestDeadLetterDispatcher(maxAttempts uint32, batch bool) (*eventDispatcher, func()) {
+	sub := &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+			Options: core.SubscriptionOptions{
+				SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+					Batch: &batch,
+					Retry: &core.SubscriptionRetry{MaxAttempts: maxAttempts},
+				},
+			},
+		},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	ed.acksNacks = make(chan ackNack, 1)
+	return ed, cancel
+}
+
+func TestDeadLetterAfterMaxAttempts(t *testing.T) {
+	ed, cancel := newTestDeadLetterDispatcher(2, false)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mockRunAsGroupPassthrough(mdi)
+	ev1 := fftypes.NewUUID()
+	mdi.On("InsertDeadLetter", mock.Anything, mock.MatchedBy(func(dl *core.DeadLetter) bool {
+		return dl.Event.Equals(ev1) &&
+			dl.Subscription.ID.Equals(ed.subscription.definition.ID) &&
+			dl.Attempts == 2 &&
+			dl.Reason == "pop2"
+	})).Return(nil)
+
+	// First rejection is a nack, for redelivery
+	ed.inflight[*ev1] = &core.Event{ID: ev1, Sequence: 12345}
+	ed.deliveryResponse(&core.EventDeliveryResponse{ID: ev1, Rejected: true, Info: "pop1"})
+	an := <-ed.acksNacks
+	assert.True(t, an.isNack)
+	assert.Equal(t, int64(1), ed.attempts[*ev1])
+
+	// Second rejection exhausts the retries, so it's dead-lettered and acked
+	ed.inflight[*ev1] = &core.Event{ID: ev1, Sequence: 12345}
+	ed.deliveryResponse(&core.EventDeliveryResponse{ID: ev1, Rejected: true, Info: "pop2"})
+	an = <-ed.acksNacks
+	assert.False(t, an.isNack)
+	assert.Equal(t, int64(12345), an.offset)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestDeadLetterInsertFail(t *testing.T) {
+	ed, cancel := newTestDeadLetterDispatcher(1, false)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mockRunAsGroupPassthrough(mdi)
+	mdi.On("InsertDeadLetter", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	ev1 := fftypes.NewUUID()
+	ed.inflight[*ev1] = &core.Event{ID: ev1, Sequence: 12345}
+	ed.deliveryResponse(&core.EventDeliveryResponse{ID: ev1, Rejected: true, Info: "pop"})
+	an := <-ed.acksNacks
+	assert.True(t, an.isNack)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestDeadLetterBatch(t *testing.T) {
+	ed, cancel := newTestDeadLetterDispatcher(1, true)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mockRunAsGroupPassthrough(mdi)
+	mdi.On("InsertDeadLetter", mock.Anything, mock.Anything).Return(nil).Twice()
+
+	batchID := fftypes.NewUUID()
+	ev1 := fftypes.NewUUID()
+	ev2 := fftypes.NewUUID()
+	ed.batches[*batchID] = []*core.Event{
+		{ID: ev1, Sequence: 100},
+		{ID: ev2, Sequence: 101},
+	}
+	ed.deliveryResponse(&core.EventDeliveryResponse{ID: batchID, Rejected: true, Info: "pop"})
+	an := <-ed.acksNacks
+	assert.False(t, an.isNack)
+	assert.Equal(t, int64(101), an.offset)
+	assert.Equal(t, []fftypes.UUID{*ev1, *ev2}, an.batch)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestReplayDeadLetters(t *testing.T) {
+	ed, cancel := newTestDeadLetterDispatcher(1, false)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mei := ed.transport.(*eventsmocks.Plugin)
+	ev1 := &core.Event{ID: fftypes.NewUUID(), Sequence: 100}
+	ev3 := &core.Event{ID: fftypes.NewUUID(), Sequence: 102}
+	dl1 := &core.DeadLetter{ID: fftypes.NewUUID(), Event: ev1.ID, Attempts: 1}
+	dl2 := &core.DeadLetter{ID: fftypes.NewUUID(), Event: fftypes.NewUUID(), Attempts: 1}
+	dl3 := &core.DeadLetter{ID: fftypes.NewUUID(), Event: ev3.ID, Attempts: 1}
+	mdi.On("GetEventByID", mock.Anything, "ns1", ev1.ID).Return(ev1, nil)
+	mdi.On("GetEventByID", mock.Anything, "ns1", dl2.Event).Return(nil, nil)
+	mdi.On("GetEventByID", mock.Anything, "ns1", ev3.ID).Return(ev3, nil)
+	mdi.On("DeleteDeadLetter", mock.Anything, "ns1", dl1.ID).Return(nil)
+	mdi.On("UpdateDeadLetter", mock.Anything, "ns1", dl3.ID, mock.Anything).Return(nil)
+
+	mei.On("DeliveryRequest", ed.connID, ed.subscription.definition, mock.MatchedBy(func(e *core.EventDelivery) bool {
+		return e.ID.Equals(ev1.ID)
+	}), core.DataArray(nil)).Run(func(a mock.Arguments) {
+		ed.deliveryResponse(&core.EventDeliveryResponse{ID: ev1.ID})
+	}).Return(nil)
+	mei.On("DeliveryRequest", ed.connID, ed.subscription.definition, mock.MatchedBy(func(e *core.EventDelivery) bool {
+		return e.ID.Equals(ev3.ID)
+	}), core.DataArray(nil)).Return(fmt.Errorf("pop"))
+
+	replayed, err := ed.replayDeadLetters([]*core.DeadLetter{dl1, dl2, dl3})
+	assert.NoError(t, err)
+	assert.Equal(t, []*core.DeadLetter{dl1, dl3}, replayed)
+	assert.Empty(t, ed.replays)
+
+	// Replays never affect the offset
+	assert.Empty(t, ed.acksNacks)
+
+	mdi.AssertExpectations(t)
+	mei.AssertExpectations(t)
+}
+
+func TestReplayDeadLettersGetEventFail(t *testing.T) {
+	ed, cancel := newTestDeadLetterDispatcher(1, false)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetEventByID", mock.Anything, "ns1", mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := ed.replayDeadLetters([]*core.DeadLetter{{ID: fftypes.NewUUID(), Event: fftypes.NewUUID()}})
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestReplayDeadLettersEnrichFail(t *testing.T) {
+	ed, cancel := newTestDeadLetterDispatcher(1, false)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdm := ed.data.(*datamocks.Manager)
+	ev1 := &core.Event{ID: fftypes.NewUUID(), Type: core.EventTypeMessageConfirmed, Reference: fftypes.NewUUID()}
+	mdi.On("GetEventByID", mock.Anything, "ns1", ev1.ID).Return(ev1, nil)
+	mdm.On("GetMessageWithDataCached", mock.Anything, ev1.Reference).Return(nil, nil, false, fmt.Errorf("pop"))
+
+	_, err := ed.replayDeadLetters([]*core.DeadLetter{{ID: fftypes.NewUUID(), Event: ev1.ID}})
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestReplayDeadLettersGetDataFail(t *testing.T) {
+	ed, cancel := newTestDeadLetterDispatcher(1, false)
+	defer cancel()
+	yes := true
+	ed.subscription.definition.Options.WithData = &yes
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdm := ed.data.(*datamocks.Manager)
+	ev1 := &core.Event{ID: fftypes.NewUUID(), Type: core.EventTypeMessageConfirmed, Reference: fftypes.NewUUID()}
+	msg := &core.Message{Header: core.MessageHeader{ID: ev1.Reference}}
+	mdi.On("GetEventByID", mock.Anything, "ns1", ev1.ID).Return(ev1, nil)
+	mdm.On("GetMessageWithDataCached", mock.Anything, ev1.Reference).Return(msg, nil, true, nil)
+	mdm.On("GetMessageDataCached", mock.Anything, msg).Return(nil, false, fmt.Errorf("pop"))
+
+	_, err := ed.replayDeadLetters([]*core.DeadLetter{{ID: fftypes.NewUUID(), Event: ev1.ID}})
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestReplayResponseUpdateFailures(t *testing.T) {
+	ed, cancel := newTestDeadLetterDispatcher(1, false)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("DeleteDeadLetter", mock.Anything, "ns1", mock.Anything).Return(fmt.Errorf("pop"))
+	mdi.On("UpdateDeadLetter", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	// Failure to send a reply is logged, but does not prevent the dead letter being removed
+	ed.messaging = nil
+	ev1 := &core.Event{ID: fftypes.NewUUID()}
+	ed.replayResponse(&deadLetterReplay{deadLetter: &core.DeadLetter{ID: fftypes.NewUUID(), Event: ev1.ID}, event: ev1},
+		&core.EventDeliveryResponse{ID: ev1.ID, Reply: &core.MessageInOut{
+			Message: core.Message{Header: core.MessageHeader{Group: fftypes.NewRandB32()}},
+		}})
+	ed.replayResponse(&deadLetterReplay{deadLetter: &core.DeadLetter{ID: fftypes.NewUUID(), Event: ev1.ID}, event: ev1},
+		&core.EventDeliveryResponse{ID: ev1.ID, Rejected: true})
+
+	mdi.AssertExpectations(t)
+}
@@ -60,9 +60,11 @@ type EventManager interface {
 	DeletedSubscriptions() chan<- *fftypes.UUID
 	DeleteDurableSubscription(ctx context.Context, subDef *core.Subscription) (err error)
 	CreateUpdateDurableSubscription(ctx context.Context, subDef *core.Subscription, mustNew bool) (err error)
+	ReplayDeadLetters(ctx context.Context, subDef *core.Subscription, ids []*fftypes.UUID) ([]*core.DeadLetter, error)
 	EnrichEvent(ctx context.Context, event *core.Event) (*core.EnrichedEvent, error)
 	QueueBatchRewind(batchID *fftypes.UUID)
 	Start() error
+	StartAggregator()
 	WaitStop()
 
 	// Bound blockchain callbacks
@@ -113,6 +115,7 @@ type eventManager struct {
 	metrics            metrics.Manager
 	chainListenerCache cache.CInterface
 	multiparty         multiparty.Manager // optional
+	aggregatorStarted  bool
 }
 
 func NewEventManager(ctx context.Context, ns *core.Namespace, di database.Plugin, bi blockchain.Plugin, im identity.Manager, dh definitions.Handler, dm data.Manager, ds definitions.Sender, bm broadcast.Manager, pm privatemessaging.Manager, am assets.Manager, sd shareddownload.Manager, mm metrics.Manager, om operations.Manager, txHelper txcommon.Helper, transports map[string]events.Plugin, mp multiparty.Manager, cacheManager cache.Manager) (EventManager, error) {
@@ -181,15 +184,21 @@ func NewEventManager(ctx context.Context, ns *core.Namespace, di database.Plugin
 
 func (em *eventManager) Start() (err error) {
 	err = em.subManager.start()
-	if err == nil {
-		if em.aggregator != nil {
-			em.aggregator.start()
-			em.blobReceiver.start()
-		}
+	if err == nil && em.blobReceiver != nil {
+		em.blobReceiver.start()
 	}
 	return err
 }
 
+// StartAggregator starts processing pins into events. This must only run in one process for
+// the namespace at a time, so is started separately from the rest of the event manager.
+func (em *eventManager) StartAggregator() {
+	if em.aggregator != nil {
+		em.aggregator.start()
+		em.aggregatorStarted = true
+	}
+}
+
 func (em *eventManager) NewEvents() chan<- int64 {
 	return em.newEventNotifier.newEvents
 }
@@ -216,7 +225,7 @@ func (em *eventManager) WaitStop() {
 		em.blobReceiver.stop()
 		em.blobReceiver = nil
 	}
-	if em.aggregator != nil {
+	if em.aggregatorStarted {
 		<-em.aggregator.eventPoller.closed
 	}
 }
@@ -273,6 +282,10 @@ func (em *eventManager) DeleteDurableSubscription(ctx context.Context, subDef *c
 	return em.database.DeleteSubscriptionByID(ctx, em.namespace.Name, subDef.ID)
 }
 
+func (em *eventManager) ReplayDeadLetters(ctx context.Context, subDef *core.Subscription, ids []*fftypes.UUID) ([]*core.DeadLetter, error) {
+	return em.subManager.replayDeadLetters(ctx, subDef, ids)
+}
+
 func (em *eventManager) AddSystemEventListener(ns string, el system.EventListener) error {
 	return em.internalEvents.AddListener(ns, el)
 }
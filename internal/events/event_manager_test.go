@@ -200,6 +200,7 @@ func TestStartStop(t *testing.T) {
 	em.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil)
 	em.mdi.On("GetSubscriptions", mock.Anything, mock.Anything, mock.Anything).Return([]*core.Subscription{}, nil, nil)
 	assert.NoError(t, em.Start())
+	em.StartAggregator()
 	em.NewEvents() <- 12345
 	em.NewPins() <- 12345
 	em.cancel()
@@ -349,13 +350,6 @@ func TestStartStopEventListenerFail(t *testing.T) {
 func TestEmitSubscriptionEventsNoops(t *testing.T) {
 	em := newTestEventManager(t)
 	defer em.cleanup(t)
-	em.mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, aggregatorOffsetName).Return(&core.Offset{
-		Type:    core.OffsetTypeAggregator,
-		Name:    aggregatorOffsetName,
-		Current: 12345,
-		RowID:   333333,
-	}, nil)
-	em.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil).Maybe()
 	em.mdi.On("GetSubscriptions", mock.Anything, mock.Anything, mock.Anything).Return([]*core.Subscription{}, nil, nil)
 
 	getSubCallReady := make(chan bool, 1)
@@ -571,6 +565,15 @@ func TestCreateDeleteDurableSubscriptionOk(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestEventManagerReplayDeadLetters(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1"}}
+	em.mdi.On("GetDeadLetters", mock.Anything, "ns1", mock.Anything).Return([]*core.DeadLetter{}, nil, nil)
+	_, err := em.ReplayDeadLetters(em.ctx, sub, nil)
+	assert.Regexp(t, "FF10448", err)
+}
+
 func TestAddInternalListener(t *testing.T) {
 	em := newTestEventManager(t)
 	defer em.cleanup(t)
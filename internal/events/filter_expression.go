@@ -0,0 +1,219 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// parseFilterExpression parses a subscription filter expression, which uses the same query string
+// syntax as the REST collection APIs. The field names are dot-separated paths into the JSON
+// representation of the event delivery, so any field can be filtered on without needing a
+// pre-defined query factory.
+func parseFilterExpression(ctx context.Context, expression string) (*ffapi.FilterInfo, error) {
+	values, err := url.ParseQuery(expression)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgInvalidFilterExpression, expression)
+	}
+
+	fields := make([]string, 0, len(values))
+	queryFields := ffapi.QueryFields{}
+	for field := range values {
+		if field == "" {
+			return nil, i18n.NewError(ctx, coremsgs.MsgInvalidFilterExpression, expression)
+		}
+		fields = append(fields, field)
+		queryFields[strings.ToLower(field)] = &ffapi.StringField{}
+	}
+	sort.Strings(fields)
+
+	fb := queryFields.NewFilter(ctx)
+	filter := fb.And()
+	for _, field := range fields {
+		fieldValues := values[field]
+		sort.Strings(fieldValues)
+		conditions := make([]ffapi.Filter, len(fieldValues))
+		for i, value := range fieldValues {
+			if conditions[i], err = database.FilterCondition(ctx, fb, field, value); err != nil {
+				return nil, err
+			}
+		}
+		if len(conditions) == 1 {
+			filter.Condition(conditions[0])
+		} else {
+			filter.Condition(fb.Or(conditions...))
+		}
+	}
+
+	return filter.Finalize()
+}
+
+// matchFilterExpression evaluates a parsed filter expression against an enriched event
+func matchFilterExpression(fi *ffapi.FilterInfo, event *core.EventDelivery) bool {
+	var delivery map[string]interface{}
+	b, _ := json.Marshal(event)
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	_ = d.Decode(&delivery)
+	return evalFilterInfo(fi, delivery)
+}
+
+func evalFilterInfo(fi *ffapi.FilterInfo, delivery map[string]interface{}) bool {
+	switch fi.Op {
+	case ffapi.FilterOpAnd:
+		for _, child := range fi.Children {
+			if !evalFilterInfo(child, delivery) {
+				return false
+			}
+		}
+		return true
+	case ffapi.FilterOpOr:
+		for _, child := range fi.Children {
+			if evalFilterInfo(child, delivery) {
+				return true
+			}
+		}
+		return false
+	}
+
+	actual, found := deliveryFieldValue(delivery, fi.Field)
+	v, _ := fi.Value.Value()
+	expected, isString := v.(string)
+	if !isString {
+		// Only equality checks can be performed against null (enforced when the filter is finalized)
+		return (fi.Op == ffapi.FilterOpEq && !found) || (fi.Op == ffapi.FilterOpNeq && found)
+	}
+	if !found {
+		// As with a database query, a missing field never matches a non-null value
+		return false
+	}
+
+	switch fi.Op {
+	case ffapi.FilterOpEq:
+		return actual == expected
+	case ffapi.FilterOpNeq:
+		return actual != expected
+	case ffapi.FilterOpIEq:
+		return strings.EqualFold(actual, expected)
+	case ffapi.FilterOpNIeq:
+		return !strings.EqualFold(actual, expected)
+	case ffapi.FilterOpGt:
+		return compareFilterValues(actual, expected) > 0
+	case ffapi.FilterOpGte:
+		return compareFilterValues(actual, expected) >= 0
+	case ffapi.FilterOpLt:
+		return compareFilterValues(actual, expected) < 0
+	case ffapi.FilterOpLte:
+		return compareFilterValues(actual, expected) <= 0
+	case ffapi.FilterOpCont:
+		return strings.Contains(actual, expected)
+	case ffapi.FilterOpNotCont:
+		return !strings.Contains(actual, expected)
+	case ffapi.FilterOpICont:
+		return strings.Contains(strings.ToLower(actual), strings.ToLower(expected))
+	case ffapi.FilterOpNotICont:
+		return !strings.Contains(strings.ToLower(actual), strings.ToLower(expected))
+	case ffapi.FilterOpStartsWith:
+		return strings.HasPrefix(actual, expected)
+	case ffapi.FilterOpNotStartsWith:
+		return !strings.HasPrefix(actual, expected)
+	case ffapi.FilterOpIStartsWith:
+		return strings.HasPrefix(strings.ToLower(actual), strings.ToLower(expected))
+	case ffapi.FilterOpNotIStartsWith:
+		return !strings.HasPrefix(strings.ToLower(actual), strings.ToLower(expected))
+	case ffapi.FilterOpEndsWith:
+		return strings.HasSuffix(actual, expected)
+	case ffapi.FilterOpNotEndsWith:
+		return !strings.HasSuffix(actual, expected)
+	case ffapi.FilterOpIEndsWith:
+		return strings.HasSuffix(strings.ToLower(actual), strings.ToLower(expected))
+	case ffapi.FilterOpNotIEndsWith:
+		return !strings.HasSuffix(strings.ToLower(actual), strings.ToLower(expected))
+	default:
+		return false
+	}
+}
+
+// compareFilterValues compares numerically if both values are numbers (such as token amounts,
+// which can exceed 64 bits), and otherwise falls back to a string comparison
+func compareFilterValues(actual, expected string) int {
+	a, aOk := new(big.Float).SetString(actual)
+	e, eOk := new(big.Float).SetString(expected)
+	if aOk && eOk {
+		return a.Cmp(e)
+	}
+	return strings.Compare(actual, expected)
+}
+
+// deliveryFieldValue resolves a dot-separated path into the JSON of the event delivery.
+// Object keys are matched case-insensitively, and array entries can be addressed by index.
+func deliveryFieldValue(delivery map[string]interface{}, path string) (string, bool) {
+	var v interface{} = delivery
+	for _, key := range strings.Split(path, ".") {
+		switch tv := v.(type) {
+		case map[string]interface{}:
+			v = lookupFieldKey(tv, key)
+		case []interface{}:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(tv) {
+				return "", false
+			}
+			v = tv[idx]
+		default:
+			return "", false
+		}
+	}
+
+	switch tv := v.(type) {
+	case nil:
+		return "", false
+	case string:
+		return tv, true
+	case json.Number:
+		return tv.String(), true
+	case bool:
+		return strconv.FormatBool(tv), true
+	default:
+		b, _ := json.Marshal(tv)
+		return string(b), true
+	}
+}
+
+func lookupFieldKey(obj map[string]interface{}, key string) interface{} {
+	if v, ok := obj[key]; ok {
+		return v
+	}
+	for k, v := range obj {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func testFilterDelivery() *core.EventDelivery {
+	return &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID:    fftypes.NewUUID(),
+				Type:  core.EventTypeTransferConfirmed,
+				Topic: "topic1",
+			},
+			TokenTransfer: &core.TokenTransfer{
+				Amount: *fftypes.NewFFBigInt(1000),
+				Key:    "0xAbCd",
+			},
+			BlockchainEvent: &core.BlockchainEvent{
+				Output: fftypes.JSONObject{
+					"count":  12345,
+					"flag":   true,
+					"values": []interface{}{"first", "second"},
+					"nested": map[string]interface{}{"a": "b"},
+				},
+			},
+		},
+	}
+}
+
+func TestFilterExpressionMatching(t *testing.T) {
+	event := testFilterDelivery()
+	for expression, expected := range map[string]bool{
+		"":                                       true,
+		"topic=topic1":                           true,
+		"TOPIC=topic1":                           true,
+		"topic=topic2":                           false,
+		"topic=!topic2":                          true,
+		"topic=!topic1":                          false,
+		"topic=:TOPIC1":                          true,
+		"topic=!:TOPIC1":                         false,
+		"topic=topic2&topic=topic1":              true,
+		"topic=topic2&topic=topic3":              false,
+		"topic=topic1&type=token_approval":       false,
+		"topic=@pic":                             true,
+		"topic=!@pic":                            false,
+		"topic=:@PIC":                            true,
+		"topic=!:@PIC":                           false,
+		"topic=^top":                             true,
+		"topic=!^top":                            false,
+		"topic=:^TOP":                            true,
+		"topic=!:^TOP":                           false,
+		"topic=$ic1":                             true,
+		"topic=!$ic1":                            false,
+		"topic=:$IC1":                            true,
+		"topic=!:$IC1":                           false,
+		"topic=><x":                              true,
+		"tokentransfer.amount=>999":              true,
+		"tokentransfer.amount=>>1000":            false,
+		"tokentransfer.amount=>=1000":            true,
+		"tokentransfer.amount=<1000":             false,
+		"tokentransfer.amount=<<1001":            true,
+		"tokentransfer.amount=<=1000":            true,
+		"tokentransfer.amount=<=1e30":            true,
+		"tokentransfer.key=>0xAA":                true,
+		"tokentransfer.key=<0xAA":                false,
+		"blockchainevent.output.count=12345":     true,
+		"blockchainevent.output.flag=true":       true,
+		"blockchainevent.output.values.1=second": true,
+		"blockchainevent.output.values.2=second": false,
+		"blockchainevent.output.values.x=second": false,
+		"blockchainevent.output.nested=@\"a\"":   true,
+		"blockchainevent.output.count.x=1":       false,
+		"blockchainevent.output.missing=?":       true,
+		"blockchainevent.output.count=?":         false,
+		"blockchainevent.output.missing=!?":      false,
+		"blockchainevent.output.count=!?":        true,
+		"blockchainevent.output.missing=value":   false,
+		"blockchainevent.output.missing=!value":  false,
+	} {
+		fi, err := parseFilterExpression(context.Background(), expression)
+		assert.NoError(t, err, expression)
+		assert.Equal(t, expected, matchFilterExpression(fi, event), expression)
+	}
+}
+
+func TestFilterExpressionUnsupportedOp(t *testing.T) {
+	fi, err := parseFilterExpression(context.Background(), "topic=topic1")
+	assert.NoError(t, err)
+	fi.Children[0].Op = ffapi.FilterOpIn
+	assert.False(t, matchFilterExpression(fi, testFilterDelivery()))
+}
+
+func TestFilterExpressionParseErrors(t *testing.T) {
+	_, err := parseFilterExpression(context.Background(), "topic=%!")
+	assert.Regexp(t, "FF10450", err)
+
+	_, err = parseFilterExpression(context.Background(), "=topic1")
+	assert.Regexp(t, "FF10450", err)
+
+	_, err = parseFilterExpression(context.Background(), "topic=!>1")
+	assert.Regexp(t, "FF00193", err)
+
+	_, err = parseFilterExpression(context.Background(), "topic=>?")
+	assert.Regexp(t, "FF00193", err)
+
+	_, err = parseFilterExpression(context.Background(), "topic=?@")
+	assert.Regexp(t, "FF00144", err)
+}
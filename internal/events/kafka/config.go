@@ -0,0 +1,42 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import "github.com/hyperledger/firefly-common/pkg/config"
+
+const (
+	clientIDDefault       = "firefly"
+	produceTimeoutDefault = "10s"
+)
+
+const (
+	// Brokers is the list of Kafka brokers to connect to
+	Brokers = "brokers"
+	// ClientID is the client ID used when connecting to Kafka
+	ClientID = "clientId"
+	// ProduceTimeout is the maximum time to wait for the brokers to confirm a produce request
+	ProduceTimeout = "produceTimeout"
+	// Version is the version of the Kafka protocol to use
+	Version = "version"
+)
+
+func (k *Kafka) InitConfig(config config.Section) {
+	config.AddKnownKey(Brokers)
+	config.AddKnownKey(ClientID, clientIDDefault)
+	config.AddKnownKey(ProduceTimeout, produceTimeoutDefault)
+	config.AddKnownKey(Version)
+}
@@ -0,0 +1,262 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/Shopify/sarama"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/events"
+)
+
+type Kafka struct {
+	ctx          context.Context
+	capabilities *events.Capabilities
+	callbacks    map[string]events.Callbacks
+	producer     sarama.SyncProducer
+	connID       string
+}
+
+type kafkaEvent struct {
+	*core.EventDelivery
+	Data core.DataArray `json:"data,omitempty"`
+}
+
+// eventFields are the fields of an event that can be used as the partition key, or as the value of a header
+var eventFields = map[string]func(event *core.EventDelivery) string{
+	"id":         func(event *core.EventDelivery) string { return event.ID.String() },
+	"type":       func(event *core.EventDelivery) string { return string(event.Type) },
+	"topic":      func(event *core.EventDelivery) string { return event.Topic },
+	"reference":  func(event *core.EventDelivery) string { return event.Reference.String() },
+	"correlator": func(event *core.EventDelivery) string { return event.Correlator.String() },
+	"tx":         func(event *core.EventDelivery) string { return event.Event.Transaction.String() },
+	"cid": func(event *core.EventDelivery) string {
+		if event.Message == nil {
+			return ""
+		}
+		return event.Message.Header.CID.String()
+	},
+	"author": func(event *core.EventDelivery) string {
+		if event.Message == nil {
+			return ""
+		}
+		return event.Message.Header.Author
+	},
+	"tag": func(event *core.EventDelivery) string {
+		if event.Message == nil {
+			return ""
+		}
+		return event.Message.Header.Tag
+	},
+	"group": func(event *core.EventDelivery) string {
+		if event.Message == nil {
+			return ""
+		}
+		return event.Message.Header.Group.String()
+	},
+}
+
+func (k *Kafka) Name() string { return "kafka" }
+
+func (k *Kafka) Init(ctx context.Context, config config.Section) (err error) {
+	brokers := config.GetStringSlice(Brokers)
+	if len(brokers) == 0 {
+		return i18n.NewError(ctx, coremsgs.MsgKafkaNoBrokers)
+	}
+
+	sc := sarama.NewConfig()
+	sc.ClientID = config.GetString(ClientID)
+	// We only acknowledge events once they have been confirmed by all in-sync replicas
+	sc.Producer.RequiredAcks = sarama.WaitForAll
+	sc.Producer.Return.Successes = true
+	sc.Producer.Timeout = config.GetDuration(ProduceTimeout)
+	if version := config.GetString(Version); version != "" {
+		if sc.Version, err = sarama.ParseKafkaVersion(version); err != nil {
+			return i18n.WrapError(ctx, err, coremsgs.MsgKafkaInvalidVersion, version)
+		}
+	}
+
+	producer, err := sarama.NewSyncProducer(brokers, sc)
+	if err != nil {
+		return i18n.WrapError(ctx, err, coremsgs.MsgKafkaProducerInitFailed)
+	}
+
+	connID := fftypes.ShortID()
+	*k = Kafka{
+		ctx: log.WithLogField(ctx, "kafka", connID),
+		capabilities: &events.Capabilities{
+			BatchDelivery: true,
+			Transform:     true,
+		},
+		callbacks: make(map[string]events.Callbacks),
+		producer:  producer,
+		connID:    connID,
+	}
+	go func() {
+		<-ctx.Done()
+		_ = producer.Close()
+	}()
+	return nil
+}
+
+func (k *Kafka) SetHandler(namespace string, handler events.Callbacks) error {
+	k.callbacks[namespace] = handler
+	// We have a single logical connection, that matches all subscriptions
+	return handler.RegisterConnection(k.connID, func(sr core.SubscriptionRef) bool { return true })
+}
+
+func (k *Kafka) Capabilities() *events.Capabilities {
+	return k.capabilities
+}
+
+func (k *Kafka) ValidateOptions(options *core.SubscriptionOptions) error {
+	_, err := k.parseOptions(options)
+	return err
+}
+
+func (k *Kafka) parseOptions(options *core.SubscriptionOptions) (*core.KafkaOptions, error) {
+	var ko core.KafkaOptions
+	b, _ := json.Marshal(options.TransportOptions()["kafka"])
+	if err := json.Unmarshal(b, &ko); err != nil {
+		return nil, i18n.WrapError(k.ctx, err, coremsgs.MsgKafkaInvalidOptions)
+	}
+	if ko.Topic == "" {
+		return nil, i18n.NewError(k.ctx, coremsgs.MsgKafkaTopicEmpty)
+	}
+	if ko.PartitionKey != "" && eventFields[ko.PartitionKey] == nil {
+		return nil, i18n.NewError(k.ctx, coremsgs.MsgKafkaInvalidEventField, ko.PartitionKey, "partitionKey", validEventFields())
+	}
+	for name, field := range ko.Headers {
+		if eventFields[field] == nil {
+			return nil, i18n.NewError(k.ctx, coremsgs.MsgKafkaInvalidEventField, field, "headers."+name, validEventFields())
+		}
+	}
+	return &ko, nil
+}
+
+func validEventFields() string {
+	fields := make([]string, 0, len(eventFields))
+	for f := range eventFields {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return strings.Join(fields, ",")
+}
+
+func (k *Kafka) buildMessage(ko *core.KafkaOptions, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) *sarama.ProducerMessage {
+	var b []byte
+	if event.Transformed != nil {
+		b = event.Transformed.Bytes()
+	} else {
+		ke := &kafkaEvent{EventDelivery: event}
+		if sub.Options.WithData != nil && *sub.Options.WithData {
+			ke.Data = data
+		}
+		b, _ = json.Marshal(ke)
+	}
+	msg := &sarama.ProducerMessage{
+		Topic: ko.Topic,
+		Value: sarama.ByteEncoder(b),
+	}
+	if ko.PartitionKey != "" {
+		// Without a key, the producer spreads messages across the partitions of the topic
+		if key := eventFields[ko.PartitionKey](event); key != "" {
+			msg.Key = sarama.StringEncoder(key)
+		}
+	}
+	headerNames := make([]string, 0, len(ko.Headers))
+	for name := range ko.Headers {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+	for _, name := range headerNames {
+		if value := eventFields[ko.Headers[name]](event); value != "" {
+			msg.Headers = append(msg.Headers, sarama.RecordHeader{
+				Key:   []byte(name),
+				Value: []byte(value),
+			})
+		}
+	}
+	return msg
+}
+
+func (k *Kafka) DeliveryRequest(connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
+	ko, err := k.parseOptions(&sub.Options)
+	if err != nil {
+		return err
+	}
+
+	msg := k.buildMessage(ko, sub, event, data)
+	log.L(k.ctx).Debugf("Kafka-> %s event %s on subscription %s", ko.Topic, event.ID, sub.ID)
+	partition, offset, err := k.producer.SendMessage(msg)
+	if err != nil {
+		// Returning an error rejects the event, so it will be redelivered
+		log.L(k.ctx).Errorf("Kafka<- %s event %s on subscription %s failed: %s", ko.Topic, event.ID, sub.ID, err)
+		return err
+	}
+	log.L(k.ctx).Infof("Kafka<- %s event %s on subscription %s written to partition %d offset %d", ko.Topic, event.ID, sub.ID, partition, offset)
+
+	if cb, ok := k.callbacks[sub.Namespace]; ok {
+		cb.DeliveryResponse(connID, &core.EventDeliveryResponse{
+			ID:           event.ID,
+			Rejected:     false,
+			Subscription: event.Subscription,
+		})
+	}
+	return nil
+}
+
+func (k *Kafka) BatchDeliveryRequest(connID string, sub *core.Subscription, batchID *fftypes.UUID, events []*core.CombinedEventDataDelivery) error {
+	ko, err := k.parseOptions(&sub.Options)
+	if err != nil {
+		return err
+	}
+
+	msgs := make([]*sarama.ProducerMessage, len(events))
+	for i, e := range events {
+		msgs[i] = k.buildMessage(ko, sub, e.Event, e.Data)
+	}
+	log.L(k.ctx).Debugf("Kafka-> %s batch %s (%d events) on subscription %s", ko.Topic, batchID, len(events), sub.ID)
+	if err := k.producer.SendMessages(msgs); err != nil {
+		// The whole batch is rejected if any message fails, so it will be redelivered.
+		// We report the first failure, as the aggregated error only contains a count.
+		if errs, ok := err.(sarama.ProducerErrors); ok && len(errs) > 0 {
+			err = errs[0].Err
+		}
+		log.L(k.ctx).Errorf("Kafka<- %s batch %s on subscription %s failed: %s", ko.Topic, batchID, sub.ID, err)
+		return err
+	}
+	log.L(k.ctx).Infof("Kafka<- %s batch %s on subscription %s written", ko.Topic, batchID, sub.ID)
+
+	if cb, ok := k.callbacks[sub.Namespace]; ok {
+		cb.DeliveryResponse(connID, &core.EventDeliveryResponse{
+			ID:           batchID,
+			Rejected:     false,
+			Subscription: sub.SubscriptionRef,
+		})
+	}
+	return nil
+}
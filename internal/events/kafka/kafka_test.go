@@ -0,0 +1,369 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestBroker(t *testing.T) *sarama.MockBroker {
+	broker := sarama.NewMockBroker(t, 1)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("topic1", 0, broker.BrokerID()).
+			SetLeader("topic2", 0, broker.BrokerID()),
+		"ProduceRequest": sarama.NewMockProduceResponse(t).
+			SetVersion(3).
+			SetError("topic2", 0, sarama.ErrTopicAuthorizationFailed),
+	})
+	return broker
+}
+
+func newTestKafka(t *testing.T) (k *Kafka, cbs *eventsmocks.Callbacks, cancel func()) {
+	coreconfig.Reset()
+
+	broker := newTestBroker(t)
+	cbs = &eventsmocks.Callbacks{}
+	rc := cbs.On("RegisterConnection", mock.Anything, mock.Anything).Return(nil)
+	rc.RunFn = func(a mock.Arguments) {
+		assert.Equal(t, true, a[1].(events.SubscriptionMatcher)(core.SubscriptionRef{}))
+	}
+	k = &Kafka{}
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	conf := config.RootSection("ut.kafka")
+	k.InitConfig(conf)
+	conf.Set(Brokers, []string{broker.Addr()})
+	conf.Set(Version, "1.0.0")
+	err := k.Init(ctx, conf)
+	assert.NoError(t, err)
+	k.SetHandler("ns1", cbs)
+	assert.Equal(t, "kafka", k.Name())
+	assert.True(t, k.Capabilities().BatchDelivery)
+	return k, cbs, func() {
+		cancelCtx()
+		broker.Close()
+	}
+}
+
+func testSubscription(kafkaOptions fftypes.JSONObject) *core.Subscription {
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Name:      "sub1",
+		},
+	}
+	sub.Options.TransportOptions()["kafka"] = kafkaOptions
+	return sub
+}
+
+func TestInitNoBrokers(t *testing.T) {
+	coreconfig.Reset()
+	k := &Kafka{}
+	conf := config.RootSection("ut.kafka")
+	k.InitConfig(conf)
+	err := k.Init(context.Background(), conf)
+	assert.Regexp(t, "FF10454", err)
+}
+
+func TestInitBadVersion(t *testing.T) {
+	coreconfig.Reset()
+	k := &Kafka{}
+	conf := config.RootSection("ut.kafka")
+	k.InitConfig(conf)
+	conf.Set(Brokers, []string{"localhost:9092"})
+	conf.Set(Version, "not a version")
+	err := k.Init(context.Background(), conf)
+	assert.Regexp(t, "FF10455", err)
+}
+
+func TestInitProducerFail(t *testing.T) {
+	coreconfig.Reset()
+	broker := sarama.NewMockBroker(t, 1)
+	addr := broker.Addr()
+	broker.Close()
+	k := &Kafka{}
+	conf := config.RootSection("ut.kafka")
+	k.InitConfig(conf)
+	conf.Set(Brokers, []string{addr})
+	err := k.Init(context.Background(), conf)
+	assert.Regexp(t, "FF10456", err)
+}
+
+func TestValidateOptions(t *testing.T) {
+	k, _, cancel := newTestKafka(t)
+	defer cancel()
+
+	err := k.ValidateOptions(&testSubscription(fftypes.JSONObject{
+		"topic":        "topic1",
+		"partitionKey": "cid",
+		"headers": map[string]interface{}{
+			"x-firefly-type": "type",
+		},
+	}).Options)
+	assert.NoError(t, err)
+
+	sub := testSubscription(nil)
+	err = k.ValidateOptions(&sub.Options)
+	assert.Regexp(t, "FF10451", err)
+
+	sub = testSubscription(fftypes.JSONObject{"topic": 12345})
+	err = k.ValidateOptions(&sub.Options)
+	assert.Regexp(t, "FF10452", err)
+
+	sub = testSubscription(fftypes.JSONObject{"topic": "topic1", "partitionKey": "wrong"})
+	err = k.ValidateOptions(&sub.Options)
+	assert.Regexp(t, "FF10453.*wrong.*partitionKey", err)
+
+	sub = testSubscription(fftypes.JSONObject{"topic": "topic1", "headers": map[string]interface{}{"x-header": "wrong"}})
+	err = k.ValidateOptions(&sub.Options)
+	assert.Regexp(t, "FF10453.*wrong.*headers.x-header", err)
+}
+
+func TestBuildMessage(t *testing.T) {
+	k, _, cancel := newTestKafka(t)
+	defer cancel()
+
+	yes := true
+	sub := testSubscription(nil)
+	sub.Options.WithData = &yes
+	cid := fftypes.NewUUID()
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID:    fftypes.NewUUID(),
+				Type:  core.EventTypeMessageConfirmed,
+				Topic: "topic1",
+			},
+			Message: &core.Message{
+				Header: core.MessageHeader{
+					CID: cid,
+					Tag: "tag1",
+				},
+			},
+		},
+	}
+	data := core.DataArray{{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"hello"`)}}
+
+	msg := k.buildMessage(&core.KafkaOptions{
+		Topic:        "kafkatopic",
+		PartitionKey: "cid",
+		Headers: map[string]string{
+			"x-type":   "type",
+			"x-author": "author",
+			"x-tag":    "tag",
+		},
+	}, sub, event, data)
+
+	assert.Equal(t, "kafkatopic", msg.Topic)
+	assert.Equal(t, sarama.StringEncoder(cid.String()), msg.Key)
+	assert.Equal(t, []sarama.RecordHeader{
+		{Key: []byte("x-tag"), Value: []byte("tag1")},
+		{Key: []byte("x-type"), Value: []byte(core.EventTypeMessageConfirmed)},
+	}, msg.Headers)
+	var body kafkaEvent
+	err := json.Unmarshal(msg.Value.(sarama.ByteEncoder), &body)
+	assert.NoError(t, err)
+	assert.Equal(t, *event.ID, *body.ID)
+	assert.Equal(t, `"hello"`, body.Data[0].Value.String())
+
+	// No key is set when the field is empty, and data is only included if requested
+	sub.Options.WithData = nil
+	msg = k.buildMessage(&core.KafkaOptions{
+		Topic:        "kafkatopic",
+		PartitionKey: "tx",
+	}, sub, event, data)
+	assert.Nil(t, msg.Key)
+	assert.Empty(t, msg.Headers)
+	body = kafkaEvent{}
+	err = json.Unmarshal(msg.Value.(sarama.ByteEncoder), &body)
+	assert.NoError(t, err)
+	assert.Empty(t, body.Data)
+}
+
+func TestBuildMessageTransformed(t *testing.T) {
+	k, _, cancel := newTestKafka(t)
+	defer cancel()
+
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{ID: fftypes.NewUUID()},
+		},
+	}
+	event.Transformed = fftypes.JSONAnyPtr(`{"transformed":true}`)
+
+	msg := k.buildMessage(&core.KafkaOptions{
+		Topic:        "kafkatopic",
+		PartitionKey: "id",
+	}, testSubscription(nil), event, nil)
+	assert.Equal(t, sarama.ByteEncoder(`{"transformed":true}`), msg.Value)
+	assert.Equal(t, sarama.StringEncoder(event.ID.String()), msg.Key)
+}
+
+func TestEventFields(t *testing.T) {
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID:          fftypes.NewUUID(),
+				Type:        core.EventTypeMessageConfirmed,
+				Topic:       "topic1",
+				Reference:   fftypes.NewUUID(),
+				Correlator:  fftypes.NewUUID(),
+				Transaction: fftypes.NewUUID(),
+			},
+		},
+	}
+	for _, f := range []string{"cid", "author", "tag", "group"} {
+		assert.Empty(t, eventFields[f](event))
+	}
+
+	event.Message = &core.Message{
+		Header: core.MessageHeader{
+			CID:   fftypes.NewUUID(),
+			Group: fftypes.NewRandB32(),
+			Tag:   "tag1",
+			SignerRef: core.SignerRef{
+				Author: "did:firefly:org/org1",
+			},
+		},
+	}
+	assert.Equal(t, map[string]string{
+		"id":         event.ID.String(),
+		"type":       string(core.EventTypeMessageConfirmed),
+		"topic":      "topic1",
+		"reference":  event.Reference.String(),
+		"correlator": event.Correlator.String(),
+		"tx":         event.Event.Transaction.String(),
+		"cid":        event.Message.Header.CID.String(),
+		"author":     "did:firefly:org/org1",
+		"tag":        "tag1",
+		"group":      event.Message.Header.Group.String(),
+	}, func() map[string]string {
+		values := make(map[string]string)
+		for f, fn := range eventFields {
+			values[f] = fn(event)
+		}
+		return values
+	}())
+}
+
+func TestDeliveryRequestOk(t *testing.T) {
+	k, cbs, cancel := newTestKafka(t)
+	defer cancel()
+
+	sub := testSubscription(fftypes.JSONObject{"topic": "topic1", "partitionKey": "topic"})
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID:    fftypes.NewUUID(),
+				Topic: "topic1",
+			},
+		},
+		Subscription: sub.SubscriptionRef,
+	}
+
+	cbs.On("DeliveryResponse", "conn1", mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return *response.ID == *event.ID && !response.Rejected
+	})).Return()
+
+	err := k.DeliveryRequest("conn1", sub, event, nil)
+	assert.NoError(t, err)
+
+	cbs.AssertExpectations(t)
+}
+
+func TestDeliveryRequestProduceFail(t *testing.T) {
+	k, cbs, cancel := newTestKafka(t)
+	defer cancel()
+
+	sub := testSubscription(fftypes.JSONObject{"topic": "topic2"})
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID: fftypes.NewUUID(),
+			},
+		},
+	}
+
+	err := k.DeliveryRequest("conn1", sub, event, nil)
+	assert.Regexp(t, "authorized", err)
+
+	cbs.AssertNotCalled(t, "DeliveryResponse", mock.Anything, mock.Anything)
+}
+
+func TestDeliveryRequestBadOptions(t *testing.T) {
+	k, _, cancel := newTestKafka(t)
+	defer cancel()
+
+	err := k.DeliveryRequest("conn1", testSubscription(nil), &core.EventDelivery{}, nil)
+	assert.Regexp(t, "FF10451", err)
+}
+
+func TestBatchDeliveryRequestOk(t *testing.T) {
+	k, cbs, cancel := newTestKafka(t)
+	defer cancel()
+
+	sub := testSubscription(fftypes.JSONObject{"topic": "topic1"})
+	batchID := fftypes.NewUUID()
+
+	cbs.On("DeliveryResponse", "conn1", mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return *response.ID == *batchID && !response.Rejected
+	})).Return()
+
+	err := k.BatchDeliveryRequest("conn1", sub, batchID, []*core.CombinedEventDataDelivery{
+		{Event: &core.EventDelivery{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: fftypes.NewUUID()}}}},
+		{Event: &core.EventDelivery{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: fftypes.NewUUID()}}}},
+	})
+	assert.NoError(t, err)
+
+	cbs.AssertExpectations(t)
+}
+
+func TestBatchDeliveryRequestProduceFail(t *testing.T) {
+	k, cbs, cancel := newTestKafka(t)
+	defer cancel()
+
+	sub := testSubscription(fftypes.JSONObject{"topic": "topic2"})
+
+	err := k.BatchDeliveryRequest("conn1", sub, fftypes.NewUUID(), []*core.CombinedEventDataDelivery{
+		{Event: &core.EventDelivery{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: fftypes.NewUUID()}}}},
+	})
+	assert.Regexp(t, "authorized", err)
+
+	cbs.AssertNotCalled(t, "DeliveryResponse", mock.Anything, mock.Anything)
+}
+
+func TestBatchDeliveryRequestBadOptions(t *testing.T) {
+	k, _, cancel := newTestKafka(t)
+	defer cancel()
+
+	err := k.BatchDeliveryRequest("conn1", testSubscription(nil), fftypes.NewUUID(), []*core.CombinedEventDataDelivery{})
+	assert.Regexp(t, "FF10451", err)
+}
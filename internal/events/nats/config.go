@@ -0,0 +1,42 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import "github.com/hyperledger/firefly-common/pkg/config"
+
+const (
+	subjectPrefixDefault  = "firefly"
+	publishTimeoutDefault = "10s"
+)
+
+const (
+	// URL is the URL of the NATS server(s) to connect to
+	URL = "url"
+	// SubjectPrefix is the prefix of the JetStream subject for each subscription
+	SubjectPrefix = "subjectPrefix"
+	// Stream is the name of a JetStream stream to create if it does not exist
+	Stream = "stream"
+	// PublishTimeout is the maximum time to wait for JetStream to acknowledge a publish
+	PublishTimeout = "publishTimeout"
+)
+
+func (n *NATS) InitConfig(config config.Section) {
+	config.AddKnownKey(URL)
+	config.AddKnownKey(SubjectPrefix, subjectPrefixDefault)
+	config.AddKnownKey(Stream)
+	config.AddKnownKey(PublishTimeout, publishTimeoutDefault)
+}
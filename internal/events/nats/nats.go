@@ -0,0 +1,197 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/events"
+	natsgo "github.com/nats-io/nats.go"
+)
+
+type NATS struct {
+	ctx            context.Context
+	capabilities   *events.Capabilities
+	callbacks      map[string]events.Callbacks
+	conn           *natsgo.Conn
+	js             natsgo.JetStreamContext
+	subjectPrefix  string
+	publishTimeout time.Duration
+	connID         string
+}
+
+type natsEvent struct {
+	*core.EventDelivery
+	Data core.DataArray `json:"data,omitempty"`
+}
+
+func (n *NATS) Name() string { return "nats" }
+
+func (n *NATS) Init(ctx context.Context, config config.Section) (err error) {
+	url := config.GetString(URL)
+	if url == "" {
+		return i18n.NewError(ctx, coremsgs.MsgNATSNoURL)
+	}
+
+	conn, err := natsgo.Connect(url, natsgo.Name("firefly"))
+	if err != nil {
+		return i18n.WrapError(ctx, err, coremsgs.MsgNATSConnectFailed)
+	}
+	// Only fails for invalid options
+	js, _ := conn.JetStream()
+
+	subjectPrefix := config.GetString(SubjectPrefix)
+	if stream := config.GetString(Stream); stream != "" {
+		_, err = js.StreamInfo(stream)
+		if errors.Is(err, natsgo.ErrStreamNotFound) {
+			log.L(ctx).Infof("Creating JetStream stream '%s' for subjects '%s.>'", stream, subjectPrefix)
+			_, err = js.AddStream(&natsgo.StreamConfig{
+				Name:     stream,
+				Subjects: []string{subjectPrefix + ".>"},
+			})
+		}
+		if err != nil {
+			conn.Close()
+			return i18n.WrapError(ctx, err, coremsgs.MsgNATSStreamInitFailed, stream)
+		}
+	}
+
+	connID := fftypes.ShortID()
+	*n = NATS{
+		ctx: log.WithLogField(ctx, "nats", connID),
+		capabilities: &events.Capabilities{
+			BatchDelivery: true,
+			Transform:     true,
+		},
+		callbacks:      make(map[string]events.Callbacks),
+		conn:           conn,
+		js:             js,
+		subjectPrefix:  subjectPrefix,
+		publishTimeout: config.GetDuration(PublishTimeout),
+		connID:         connID,
+	}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+	return nil
+}
+
+func (n *NATS) SetHandler(namespace string, handler events.Callbacks) error {
+	n.callbacks[namespace] = handler
+	// We have a single logical connection, that matches all subscriptions
+	return handler.RegisterConnection(n.connID, func(sr core.SubscriptionRef) bool { return true })
+}
+
+func (n *NATS) Capabilities() *events.Capabilities {
+	return n.capabilities
+}
+
+func (n *NATS) ValidateOptions(options *core.SubscriptionOptions) error {
+	return nil
+}
+
+// subject maps a subscription to the JetStream subject its events are published to
+func (n *NATS) subject(sub *core.Subscription) string {
+	return n.subjectPrefix + "." + sub.Namespace + "." + sub.Name
+}
+
+func (n *NATS) buildMessage(sub *core.Subscription, event *core.EventDelivery, data core.DataArray) *natsgo.Msg {
+	var b []byte
+	if event.Transformed != nil {
+		b = event.Transformed.Bytes()
+	} else {
+		ne := &natsEvent{EventDelivery: event}
+		if sub.Options.WithData != nil && *sub.Options.WithData {
+			ne.Data = data
+		}
+		b, _ = json.Marshal(ne)
+	}
+	msg := natsgo.NewMsg(n.subject(sub))
+	msg.Data = b
+	// Allows JetStream to discard duplicates, if an event is redelivered after a lost acknowledgement
+	msg.Header.Set(natsgo.MsgIdHdr, event.ID.String())
+	return msg
+}
+
+func (n *NATS) DeliveryRequest(connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
+	msg := n.buildMessage(sub, event, data)
+	log.L(n.ctx).Debugf("NATS-> %s event %s on subscription %s", msg.Subject, event.ID, sub.ID)
+	ack, err := n.js.PublishMsg(msg, natsgo.AckWait(n.publishTimeout))
+	if err != nil {
+		// Returning an error rejects the event, so it will be redelivered
+		log.L(n.ctx).Errorf("NATS<- %s event %s on subscription %s failed: %s", msg.Subject, event.ID, sub.ID, err)
+		return err
+	}
+	log.L(n.ctx).Infof("NATS<- %s event %s on subscription %s stored in stream %s sequence %d", msg.Subject, event.ID, sub.ID, ack.Stream, ack.Sequence)
+
+	if cb, ok := n.callbacks[sub.Namespace]; ok {
+		cb.DeliveryResponse(connID, &core.EventDeliveryResponse{
+			ID:           event.ID,
+			Rejected:     false,
+			Subscription: event.Subscription,
+		})
+	}
+	return nil
+}
+
+func (n *NATS) BatchDeliveryRequest(connID string, sub *core.Subscription, batchID *fftypes.UUID, events []*core.CombinedEventDataDelivery) error {
+	subject := n.subject(sub)
+	log.L(n.ctx).Debugf("NATS-> %s batch %s (%d events) on subscription %s", subject, batchID, len(events), sub.ID)
+
+	// Publish all the events, then wait for every one to be acknowledged
+	futures := make([]natsgo.PubAckFuture, len(events))
+	for i, e := range events {
+		var err error
+		if futures[i], err = n.js.PublishMsgAsync(n.buildMessage(sub, e.Event, e.Data)); err != nil {
+			log.L(n.ctx).Errorf("NATS<- %s batch %s on subscription %s failed: %s", subject, batchID, sub.ID, err)
+			return err
+		}
+	}
+	timeout := time.NewTimer(n.publishTimeout)
+	defer timeout.Stop()
+	for _, f := range futures {
+		select {
+		case <-f.Ok():
+		case err := <-f.Err():
+			// The whole batch is rejected if any event fails, so it will be redelivered
+			log.L(n.ctx).Errorf("NATS<- %s batch %s on subscription %s failed: %s", subject, batchID, sub.ID, err)
+			return err
+		case <-timeout.C:
+			return i18n.NewError(n.ctx, coremsgs.MsgNATSPublishTimeout, subject)
+		}
+	}
+	log.L(n.ctx).Infof("NATS<- %s batch %s on subscription %s stored", subject, batchID, sub.ID)
+
+	if cb, ok := n.callbacks[sub.Namespace]; ok {
+		cb.DeliveryResponse(connID, &core.EventDeliveryResponse{
+			ID:           batchID,
+			Rejected:     false,
+			Subscription: sub.SubscriptionRef,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/nats-io/nats-server/v2/server"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestServer(t *testing.T, jetStream bool) *server.Server {
+	s, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		NoLog:     true,
+		NoSigs:    true,
+		JetStream: jetStream,
+		StoreDir:  t.TempDir(),
+	})
+	assert.NoError(t, err)
+	go s.Start()
+	assert.True(t, s.ReadyForConnections(10*time.Second))
+	return s
+}
+
+func newTestConfig(url string) config.Section {
+	coreconfig.Reset()
+	conf := config.RootSection("ut.nats")
+	(&NATS{}).InitConfig(conf)
+	conf.Set(URL, url)
+	conf.Set(Stream, "firefly")
+	return conf
+}
+
+func newTestNATS(t *testing.T) (n *NATS, cbs *eventsmocks.Callbacks, s *server.Server, cancel func()) {
+	s = newTestServer(t, true)
+	cbs = &eventsmocks.Callbacks{}
+	rc := cbs.On("RegisterConnection", mock.Anything, mock.Anything).Return(nil)
+	rc.RunFn = func(a mock.Arguments) {
+		assert.Equal(t, true, a[1].(events.SubscriptionMatcher)(core.SubscriptionRef{}))
+	}
+	n = &NATS{}
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	err := n.Init(ctx, newTestConfig(s.ClientURL()))
+	assert.NoError(t, err)
+	n.SetHandler("ns1", cbs)
+	assert.Equal(t, "nats", n.Name())
+	assert.True(t, n.Capabilities().BatchDelivery)
+	assert.NoError(t, n.ValidateOptions(&core.SubscriptionOptions{}))
+	return n, cbs, s, func() {
+		cancelCtx()
+		s.Shutdown()
+	}
+}
+
+func testSubscription() *core.Subscription {
+	return &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Name:      "sub1",
+		},
+	}
+}
+
+func testEvent(sub *core.Subscription) *core.EventDelivery {
+	return &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID:    fftypes.NewUUID(),
+				Type:  core.EventTypeMessageConfirmed,
+				Topic: "topic1",
+			},
+		},
+		Subscription: sub.SubscriptionRef,
+	}
+}
+
+func TestInitNoURL(t *testing.T) {
+	n := &NATS{}
+	err := n.Init(context.Background(), newTestConfig(""))
+	assert.Regexp(t, "FF10457", err)
+}
+
+func TestInitConnectFail(t *testing.T) {
+	s := newTestServer(t, false)
+	url := s.ClientURL()
+	s.Shutdown()
+	n := &NATS{}
+	err := n.Init(context.Background(), newTestConfig(url))
+	assert.Regexp(t, "FF10458", err)
+}
+
+func TestInitStreamFail(t *testing.T) {
+	s := newTestServer(t, false)
+	defer s.Shutdown()
+	n := &NATS{}
+	err := n.Init(context.Background(), newTestConfig(s.ClientURL()))
+	assert.Regexp(t, "FF10459.*firefly", err)
+}
+
+func TestInitExistingStream(t *testing.T) {
+	n, _, s, cancel := newTestNATS(t)
+	defer cancel()
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	n2 := &NATS{}
+	err := n2.Init(ctx, newTestConfig(s.ClientURL()))
+	assert.NoError(t, err)
+	cancelCtx()
+	assert.Eventually(t, n2.conn.IsClosed, 5*time.Second, 10*time.Millisecond)
+	assert.False(t, n.conn.IsClosed())
+}
+
+func TestBuildMessage(t *testing.T) {
+	n, _, _, cancel := newTestNATS(t)
+	defer cancel()
+
+	yes := true
+	sub := testSubscription()
+	sub.Options.WithData = &yes
+	event := testEvent(sub)
+	data := core.DataArray{{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"hello"`)}}
+
+	msg := n.buildMessage(sub, event, data)
+	assert.Equal(t, "firefly.ns1.sub1", msg.Subject)
+	assert.Equal(t, event.ID.String(), msg.Header.Get(natsgo.MsgIdHdr))
+	var body natsEvent
+	err := json.Unmarshal(msg.Data, &body)
+	assert.NoError(t, err)
+	assert.Equal(t, *event.ID, *body.ID)
+	assert.Equal(t, `"hello"`, body.Data[0].Value.String())
+
+	// Data is only included if requested
+	sub.Options.WithData = nil
+	msg = n.buildMessage(sub, event, data)
+	body = natsEvent{}
+	err = json.Unmarshal(msg.Data, &body)
+	assert.NoError(t, err)
+	assert.Empty(t, body.Data)
+}
+
+func TestBuildMessageTransformed(t *testing.T) {
+	n, _, _, cancel := newTestNATS(t)
+	defer cancel()
+
+	sub := testSubscription()
+	event := testEvent(sub)
+	event.Transformed = fftypes.JSONAnyPtr(`{"transformed":true}`)
+
+	msg := n.buildMessage(sub, event, nil)
+	assert.Equal(t, `{"transformed":true}`, string(msg.Data))
+	assert.Equal(t, event.ID.String(), msg.Header.Get(natsgo.MsgIdHdr))
+}
+
+func TestDeliveryRequestOk(t *testing.T) {
+	n, cbs, _, cancel := newTestNATS(t)
+	defer cancel()
+
+	sub := testSubscription()
+	event := testEvent(sub)
+
+	cbs.On("DeliveryResponse", "conn1", mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return *response.ID == *event.ID && !response.Rejected
+	})).Return()
+
+	err := n.DeliveryRequest("conn1", sub, event, nil)
+	assert.NoError(t, err)
+
+	// Redelivery of the same event is de-duplicated by JetStream
+	err = n.DeliveryRequest("conn1", sub, event, nil)
+	assert.NoError(t, err)
+
+	si, err := n.js.StreamInfo("firefly")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), si.State.Msgs)
+	msg, err := n.js.GetLastMsg("firefly", "firefly.ns1.sub1")
+	assert.NoError(t, err)
+	var body natsEvent
+	err = json.Unmarshal(msg.Data, &body)
+	assert.NoError(t, err)
+	assert.Equal(t, *event.ID, *body.ID)
+
+	cbs.AssertExpectations(t)
+}
+
+func TestDeliveryRequestPublishFail(t *testing.T) {
+	n, cbs, _, cancel := newTestNATS(t)
+	defer cancel()
+
+	// No stream captures this subject
+	n.subjectPrefix = "other"
+	sub := testSubscription()
+
+	err := n.DeliveryRequest("conn1", sub, testEvent(sub), nil)
+	assert.Error(t, err)
+
+	cbs.AssertNotCalled(t, "DeliveryResponse", mock.Anything, mock.Anything)
+}
+
+func TestBatchDeliveryRequestOk(t *testing.T) {
+	n, cbs, _, cancel := newTestNATS(t)
+	defer cancel()
+
+	sub := testSubscription()
+	batchID := fftypes.NewUUID()
+
+	cbs.On("DeliveryResponse", "conn1", mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return *response.ID == *batchID && !response.Rejected
+	})).Return()
+
+	err := n.BatchDeliveryRequest("conn1", sub, batchID, []*core.CombinedEventDataDelivery{
+		{Event: testEvent(sub)},
+		{Event: testEvent(sub)},
+	})
+	assert.NoError(t, err)
+
+	si, err := n.js.StreamInfo("firefly")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), si.State.Msgs)
+
+	cbs.AssertExpectations(t)
+}
+
+func TestBatchDeliveryRequestPublishFail(t *testing.T) {
+	n, cbs, _, cancel := newTestNATS(t)
+	defer cancel()
+
+	n.conn.Close()
+	sub := testSubscription()
+
+	err := n.BatchDeliveryRequest("conn1", sub, fftypes.NewUUID(), []*core.CombinedEventDataDelivery{
+		{Event: testEvent(sub)},
+	})
+	assert.Error(t, err)
+
+	cbs.AssertNotCalled(t, "DeliveryResponse", mock.Anything, mock.Anything)
+}
+
+func TestBatchDeliveryRequestAckFail(t *testing.T) {
+	n, cbs, _, cancel := newTestNATS(t)
+	defer cancel()
+
+	// No stream captures this subject
+	n.subjectPrefix = "other"
+	sub := testSubscription()
+
+	err := n.BatchDeliveryRequest("conn1", sub, fftypes.NewUUID(), []*core.CombinedEventDataDelivery{
+		{Event: testEvent(sub)},
+	})
+	assert.Error(t, err)
+
+	cbs.AssertNotCalled(t, "DeliveryResponse", mock.Anything, mock.Anything)
+}
+
+func TestBatchDeliveryRequestAckTimeout(t *testing.T) {
+	n, cbs, _, cancel := newTestNATS(t)
+	defer cancel()
+
+	// A plain subscriber receives the message, but never acknowledges it
+	n.subjectPrefix = "other"
+	n.publishTimeout = 100 * time.Millisecond
+	s, err := n.conn.SubscribeSync("other.>")
+	assert.NoError(t, err)
+	defer s.Unsubscribe()
+	sub := testSubscription()
+
+	err = n.BatchDeliveryRequest("conn1", sub, fftypes.NewUUID(), []*core.CombinedEventDataDelivery{
+		{Event: testEvent(sub)},
+	})
+	assert.Regexp(t, "FF10460.*other.ns1.sub1", err)
+
+	cbs.AssertNotCalled(t, "DeliveryResponse", mock.Anything, mock.Anything)
+}
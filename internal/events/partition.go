@@ -0,0 +1,86 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+type partitionKeyFunc func(event *core.EventDelivery) string
+
+// partitionKeys are the fields that in-flight events can be partitioned by. Events without a value
+// for the field (such as events that are not for a message, when partitioning by author) share a partition.
+var partitionKeys = map[core.SubOptsPartitionKey]partitionKeyFunc{
+	core.SubOptsPartitionKeyTopic: func(event *core.EventDelivery) string { return event.Topic },
+	core.SubOptsPartitionKeyGroup: func(event *core.EventDelivery) string {
+		if event.Message == nil || event.Message.Header.Group == nil {
+			return ""
+		}
+		return event.Message.Header.Group.String()
+	},
+	core.SubOptsPartitionKeyAuthor: func(event *core.EventDelivery) string {
+		if event.Message == nil {
+			return ""
+		}
+		return event.Message.Header.Author
+	},
+	core.SubOptsPartitionKeyTransaction: func(event *core.EventDelivery) string {
+		if event.Event.Transaction == nil {
+			return ""
+		}
+		return event.Event.Transaction.String()
+	},
+}
+
+func getPartitionKeyFunc(ctx context.Context, key core.SubOptsPartitionKey) (partitionKeyFunc, error) {
+	fn, ok := partitionKeys[key]
+	if !ok {
+		valid := make([]string, 0, len(partitionKeys))
+		for k := range partitionKeys {
+			valid = append(valid, string(k))
+		}
+		sort.Strings(valid)
+		return nil, i18n.NewError(ctx, coremsgs.MsgInvalidPartitionKey, key, strings.Join(valid, ","))
+	}
+	return fn, nil
+}
+
+// partitionedDispatchableLocked picks up to maxDispatch of the matching events that can be dispatched now,
+// which are those on a partition without an event already in flight - taking only the first event
+// for each partition. The events that must wait are returned in their original order.
+func (ed *eventDispatcher) partitionedDispatchableLocked(matching []*core.EventDelivery, maxDispatch int) (dispatchable, waiting []*core.EventDelivery) {
+	busy := make(map[string]bool, len(ed.inflightPartitions))
+	for _, partition := range ed.inflightPartitions {
+		busy[partition] = true
+	}
+	for _, event := range matching {
+		partition := ed.partitionKey(event)
+		if len(dispatchable) >= maxDispatch || busy[partition] {
+			waiting = append(waiting, event)
+			continue
+		}
+		busy[partition] = true
+		dispatchable = append(dispatchable, event)
+	}
+	return dispatchable, waiting
+}
@@ -0,0 +1,175 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestPartitionedDispatcher(partitionKey core.SubOptsPartitionKey) (*eventDispatcher, func()) {
+	readAhead := uint16(5)
+	sub := &subscription{
+		dispatcherElection: make(chan bool, 1),
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+			Options: core.SubscriptionOptions{
+				SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+					ReadAhead:    &readAhead,
+					PartitionKey: &partitionKey,
+				},
+			},
+		},
+	}
+	return newTestEventDispatcher(sub)
+}
+
+func TestGetPartitionKeyFunc(t *testing.T) {
+	_, err := getPartitionKeyFunc(context.Background(), "cid")
+	assert.Regexp(t, "FF10478.*author,group,topic,tx", err)
+
+	group := fftypes.NewRandB32()
+	tx := fftypes.NewUUID()
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				Topic:       "topic1",
+				Transaction: tx,
+			},
+			Message: &core.Message{
+				Header: core.MessageHeader{
+					SignerRef: core.SignerRef{Author: "org1"},
+					Group:     group,
+				},
+			},
+		},
+	}
+	noMessage := &core.EventDelivery{}
+
+	for key, expected := range map[core.SubOptsPartitionKey]string{
+		core.SubOptsPartitionKeyTopic:       "topic1",
+		core.SubOptsPartitionKeyGroup:       group.String(),
+		core.SubOptsPartitionKeyAuthor:      "org1",
+		core.SubOptsPartitionKeyTransaction: tx.String(),
+	} {
+		fn, err := getPartitionKeyFunc(context.Background(), key)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, fn(event))
+		assert.Equal(t, "", fn(noMessage))
+	}
+}
+
+func TestPartitionedDispatchable(t *testing.T) {
+	ed, cancel := newTestPartitionedDispatcher(core.SubOptsPartitionKeyTopic)
+	defer cancel()
+
+	newEvent := func(seq int64, topic string) *core.EventDelivery {
+		return &core.EventDelivery{EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{ID: fftypes.NewUUID(), Sequence: seq, Topic: topic},
+		}}
+	}
+	ev1 := newEvent(1, "a")
+	ev2 := newEvent(2, "b")
+	ev3 := newEvent(3, "b")
+	ev4 := newEvent(4, "c")
+	ev5 := newEvent(5, "d")
+	ed.inflightPartitions[*fftypes.NewUUID()] = "a"
+
+	dispatchable, waiting := ed.partitionedDispatchableLocked([]*core.EventDelivery{ev1, ev2, ev3, ev4, ev5}, 2)
+	assert.Equal(t, []*core.EventDelivery{ev2, ev4}, dispatchable)
+	assert.Equal(t, []*core.EventDelivery{ev1, ev3, ev5}, waiting)
+}
+
+func TestBufferedDeliveryPartitioned(t *testing.T) {
+	ed, cancel := newTestPartitionedDispatcher(core.SubOptsPartitionKeyTopic)
+	defer cancel()
+	go ed.deliverEvents()
+	ed.eventPoller.offsetCommitted = make(chan int64, 3)
+
+	mei := ed.transport.(*eventsmocks.Plugin)
+	eventDeliveries := make(chan *core.EventDelivery)
+	mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(a mock.Arguments) {
+		eventDeliveries <- a.Get(2).(*core.EventDelivery)
+	})
+
+	ev1 := &core.Event{ID: fftypes.NewUUID(), Sequence: 101, Topic: "a"}
+	ev2 := &core.Event{ID: fftypes.NewUUID(), Sequence: 102, Topic: "a"}
+	ev3 := &core.Event{ID: fftypes.NewUUID(), Sequence: 103, Topic: "b"}
+	ev4 := &core.Event{ID: fftypes.NewUUID(), Sequence: 104, Topic: "a"}
+
+	bdDone := make(chan struct{})
+	go func() {
+		repoll, err := ed.bufferedDelivery([]core.LocallySequenced{ev1, ev2, ev3, ev4})
+		assert.NoError(t, err)
+		assert.True(t, repoll)
+		close(bdDone)
+	}()
+
+	// The first event on each topic is in flight, while the rest of topic "a" waits
+	assert.Equal(t, *ev1.ID, *(<-eventDeliveries).ID)
+	assert.Equal(t, *ev3.ID, *(<-eventDeliveries).ID)
+
+	// Acknowledging a later sequence cannot move the offset past the earlier events
+	ed.deliveryResponse(&core.EventDeliveryResponse{ID: ev3.ID})
+	ed.deliveryResponse(&core.EventDeliveryResponse{ID: ev1.ID})
+	assert.Equal(t, int64(101), <-ed.eventPoller.offsetCommitted)
+
+	// The next event on topic "a" can now be dispatched
+	assert.Equal(t, *ev2.ID, *(<-eventDeliveries).ID)
+	ed.deliveryResponse(&core.EventDeliveryResponse{ID: ev2.ID})
+	assert.Equal(t, int64(102), <-ed.eventPoller.offsetCommitted)
+
+	assert.Equal(t, *ev4.ID, *(<-eventDeliveries).ID)
+	ed.deliveryResponse(&core.EventDeliveryResponse{ID: ev4.ID})
+	assert.Equal(t, int64(104), <-ed.eventPoller.offsetCommitted)
+
+	<-bdDone
+	assert.Empty(t, ed.inflightPartitions)
+	mei.AssertExpectations(t)
+}
+
+func TestNackPartitionedRewindsToLowestUnacknowledged(t *testing.T) {
+	ed, cancel := newTestPartitionedDispatcher(core.SubOptsPartitionKeyTopic)
+	defer cancel()
+
+	ev1 := &core.Event{ID: fftypes.NewUUID(), Sequence: 103}
+	ev2 := &core.Event{ID: fftypes.NewUUID(), Sequence: 105}
+	ed.inflight[*ev1.ID] = ev1
+	ed.inflight[*ev2.ID] = ev2
+	ed.inflightPartitions[*ev1.ID] = "a"
+	ed.inflightPartitions[*ev2.ID] = "b"
+	ed.eventPoller.pollingOffset = 110
+
+	// An event waiting for its partition is the lowest
+	ed.handleNackOffsetUpdate(ackNack{id: *ev2.ID, isNack: true, offset: 105}, 101)
+	assert.Equal(t, int64(100), ed.eventPoller.pollingOffset)
+	assert.Empty(t, ed.inflight)
+	assert.Empty(t, ed.inflightPartitions)
+
+	// An event in flight on another partition is the lowest
+	ed.eventPoller.pollingOffset = 110
+	ed.inflight[*ev1.ID] = ev1
+	ed.inflight[*ev2.ID] = ev2
+	ed.handleNackOffsetUpdate(ackNack{id: *ev2.ID, isNack: true, offset: 105}, -1)
+	assert.Equal(t, int64(102), ed.eventPoller.pollingOffset)
+}
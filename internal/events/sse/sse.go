@@ -0,0 +1,245 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/events"
+)
+
+// SSE streams events to clients as server-sent events, over a long-lived HTTP response.
+// Unlike WebSockets this works through proxies that do not support connection upgrades.
+type SSE struct {
+	ctx               context.Context
+	capabilities      *events.Capabilities
+	callbacks         map[string]events.Callbacks
+	connections       map[string]*sseConnection
+	connMux           sync.Mutex
+	auth              core.Authorizer
+	keepAliveInterval time.Duration
+}
+
+type sseStart struct {
+	namespace   string
+	name        string
+	ephemeral   bool
+	autoAck     bool
+	lastEventID int64
+	filter      core.SubscriptionFilter
+}
+
+func (s *SSE) Name() string { return "sse" }
+
+func (s *SSE) Init(ctx context.Context, config config.Section) error {
+	*s = SSE{
+		ctx:               ctx,
+		capabilities:      &events.Capabilities{},
+		callbacks:         make(map[string]events.Callbacks),
+		connections:       make(map[string]*sseConnection),
+		keepAliveInterval: config.GetDuration(KeepAliveInterval),
+	}
+	return nil
+}
+
+func (s *SSE) SetAuthorizer(auth core.Authorizer) {
+	s.auth = auth
+}
+
+func (s *SSE) SetHandler(namespace string, handler events.Callbacks) error {
+	s.callbacks[namespace] = handler
+	return nil
+}
+
+func (s *SSE) Capabilities() *events.Capabilities {
+	return s.capabilities
+}
+
+func (s *SSE) ValidateOptions(options *core.SubscriptionOptions) error {
+	return nil
+}
+
+func (s *SSE) getConnection(ctx context.Context, connID string) (*sseConnection, error) {
+	s.connMux.Lock()
+	defer s.connMux.Unlock()
+	sc, ok := s.connections[connID]
+	if !ok {
+		return nil, i18n.NewError(ctx, coremsgs.MsgSSEConnectionNotActive, connID)
+	}
+	return sc, nil
+}
+
+func (s *SSE) DeliveryRequest(connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
+	sc, err := s.getConnection(s.ctx, connID)
+	if err != nil {
+		return err
+	}
+	return sc.dispatch(event, data)
+}
+
+func (s *SSE) BatchDeliveryRequest(connID string, sub *core.Subscription, batchID *fftypes.UUID, events []*core.CombinedEventDataDelivery) error {
+	return i18n.NewError(s.ctx, coremsgs.MsgBatchDeliveryNotSupported, s.Name())
+}
+
+// ServeHTTP starts a stream of events for a single subscription, configured by query parameters:
+// - namespace: the namespace of the subscription (required)
+// - name: the name of a durable subscription to start
+// - ephemeral: start an ephemeral subscription, with filters set by the filter.* parameters
+// - autoack: set to false to acknowledge events with a POST, rather than on write
+// A client that reconnects with a Last-Event-ID (header or lastEventId parameter) resumes after that event.
+func (s *SSE) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	if s.callbacks == nil {
+		// The transport is only initialized when it is listed in events.transports.enabled
+		s.errorResponse(req.Context(), res, i18n.NewError(req.Context(), coremsgs.MsgSSENotEnabled))
+		return
+	}
+	start, err := s.parseStart(req)
+	if err == nil {
+		err = s.authorize(req, start.namespace)
+	}
+	if err != nil {
+		s.errorResponse(s.ctx, res, err)
+		return
+	}
+	flusher, ok := res.(http.Flusher)
+	if !ok {
+		s.errorResponse(s.ctx, res, i18n.NewError(s.ctx, coremsgs.MsgSSEStreamingNotSupported))
+		return
+	}
+
+	s.connMux.Lock()
+	sc := newConnection(s.ctx, s, start, res, flusher, req)
+	s.connections[sc.connID] = sc
+	s.connMux.Unlock()
+
+	sc.run(req.Context())
+}
+
+func (s *SSE) parseStart(req *http.Request) (*sseStart, error) {
+	query := req.URL.Query()
+	ephemeral, hasEphemeral := query["ephemeral"]
+	autoAck, hasAutoAck := query["autoack"]
+	start := &sseStart{
+		namespace:   query.Get("namespace"),
+		name:        query.Get("name"),
+		ephemeral:   hasEphemeral && (len(ephemeral) == 0 || ephemeral[0] != "false"),
+		autoAck:     !hasAutoAck || len(autoAck) == 0 || autoAck[0] != "false",
+		lastEventID: -1,
+		filter:      core.NewSubscriptionFilterFromQuery(query),
+	}
+	if start.namespace == "" || (!start.ephemeral && start.name == "") {
+		return nil, i18n.NewError(s.ctx, coremsgs.MsgSSEInvalidStart)
+	}
+	if _, ok := s.callbacks[start.namespace]; !ok {
+		return nil, i18n.NewError(s.ctx, coremsgs.MsgNamespaceDoesNotExist)
+	}
+	// Browsers send the header when they reconnect, and the parameter allows a client to resume a new stream
+	lastEventID := req.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = query.Get("lastEventId")
+	}
+	if lastEventID != "" {
+		sequence, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || sequence < 0 {
+			return nil, i18n.NewError(s.ctx, coremsgs.MsgSSEInvalidLastEventID, lastEventID)
+		}
+		start.lastEventID = sequence
+	}
+	return start, nil
+}
+
+func (s *SSE) authorize(req *http.Request, namespace string) error {
+	if s.auth == nil {
+		return nil
+	}
+	return s.auth.Authorize(req.Context(), &fftypes.AuthReq{
+		Namespace: namespace,
+		Header:    req.Header,
+	})
+}
+
+func (s *SSE) errorResponse(ctx context.Context, res http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if hint, ok := i18n.GetStatusHint(strings.SplitN(err.Error(), ":", 2)[0]); ok {
+		status = hint
+	}
+	log.L(ctx).Errorf("Server-sent events request failed [%d]: %s", status, err)
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(status)
+	_ = json.NewEncoder(res).Encode(&fftypes.RESTError{Error: err.Error()})
+}
+
+// Ack acknowledges an event in flight on a connection started with autoack disabled
+func (s *SSE) Ack(ctx context.Context, namespace string, ack *core.SSEAck) error {
+	sc, err := s.getConnection(ctx, ack.ConnectionID)
+	if err != nil {
+		return err
+	}
+	if sc.start.namespace != namespace {
+		// Connections in other namespaces are not visible
+		return i18n.NewError(ctx, coremsgs.MsgSSEConnectionNotActive, ack.ConnectionID)
+	}
+	inflight, err := sc.checkAck(ctx, ack)
+	if err != nil {
+		return err
+	}
+	s.ack(sc.connID, inflight)
+	return nil
+}
+
+func (s *SSE) ack(connID string, inflight *core.EventDeliveryResponse) {
+	if cb, ok := s.callbacks[inflight.Subscription.Namespace]; ok {
+		cb.DeliveryResponse(connID, inflight)
+	}
+}
+
+func (s *SSE) startSubscription(sc *sseConnection) error {
+	cb := s.callbacks[sc.start.namespace]
+	if sc.start.ephemeral {
+		options := &core.SubscriptionOptions{}
+		if sc.start.lastEventID >= 0 {
+			firstEvent := core.SubOptsFirstEvent(strconv.FormatInt(sc.start.lastEventID, 10))
+			options.FirstEvent = &firstEvent
+		}
+		return cb.EphemeralSubscription(sc.connID, sc.start.namespace, &sc.start.filter, options)
+	}
+	return cb.RegisterConnection(sc.connID, func(sr core.SubscriptionRef) bool {
+		return sr.Namespace == sc.start.namespace && sr.Name == sc.start.name
+	})
+}
+
+func (s *SSE) connClosed(connID string) {
+	s.connMux.Lock()
+	delete(s.connections, connID)
+	s.connMux.Unlock()
+	// Drop lock before calling back
+	for _, cb := range s.callbacks {
+		cb.ConnectionClosed(connID)
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+const (
+	// sseEventConnected is sent first on every stream, with the ID of the connection
+	sseEventConnected = "connected"
+	// sseEventError is sent if the subscription cannot be started, before the stream is closed
+	sseEventError = "error"
+)
+
+type sseEvent struct {
+	*core.EventDelivery
+	Data core.DataArray `json:"data,omitempty"`
+}
+
+type sseConnection struct {
+	ctx        context.Context
+	cancelCtx  func()
+	sse        *SSE
+	connID     string
+	start      *sseStart
+	res        http.ResponseWriter
+	flusher    http.Flusher
+	inflight   []*core.EventDeliveryResponse
+	mux        sync.Mutex
+	closed     bool
+	remoteAddr string
+	userAgent  string
+}
+
+func newConnection(pCtx context.Context, s *SSE, start *sseStart, res http.ResponseWriter, flusher http.Flusher, req *http.Request) *sseConnection {
+	connID := fftypes.NewUUID().String()
+	ctx := log.WithLogField(pCtx, "sse", connID)
+	ctx, cancelCtx := context.WithCancel(ctx)
+	return &sseConnection{
+		ctx:        ctx,
+		cancelCtx:  cancelCtx,
+		sse:        s,
+		connID:     connID,
+		start:      start,
+		res:        res,
+		flusher:    flusher,
+		remoteAddr: req.RemoteAddr,
+		userAgent:  req.UserAgent(),
+	}
+}
+
+// run writes the stream until the client disconnects, or we shut down
+func (sc *sseConnection) run(reqCtx context.Context) {
+	defer sc.close()
+	l := log.L(sc.ctx)
+	l.Infof("Server-sent events stream started remoteAddr=%s userAgent=%s", sc.remoteAddr, sc.userAgent)
+
+	header := sc.res.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// Stops proxies such as nginx from buffering the stream
+	header.Set("X-Accel-Buffering", "no")
+	sc.res.WriteHeader(http.StatusOK)
+
+	err := sc.write("", sseEventConnected, &core.SSEConnected{ConnectionID: sc.connID})
+	if err == nil {
+		err = sc.sse.startSubscription(sc)
+		if err != nil {
+			l.Errorf("Failed to start subscription: %s", err)
+			_ = sc.write("", sseEventError, &fftypes.RESTError{Error: err.Error()})
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(sc.sse.keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-keepAlive.C:
+			if err := sc.writeComment("keepalive"); err != nil {
+				l.Errorf("Write failed on stream: %s", err)
+				return
+			}
+		case <-reqCtx.Done():
+			l.Debugf("Stream closing - client disconnected")
+			return
+		case <-sc.ctx.Done():
+			l.Debugf("Stream closing - context cancelled")
+			return
+		}
+	}
+}
+
+func (sc *sseConnection) dispatch(event *core.EventDelivery, data core.DataArray) error {
+	inflight := &core.EventDeliveryResponse{
+		ID:           event.ID,
+		Subscription: event.Subscription,
+	}
+	if event.Sequence <= sc.start.lastEventID {
+		// The client received this event before it reconnected, so there is no need to send it again
+		log.L(sc.ctx).Debugf("Skipping event %s with sequence %d before Last-Event-ID %d", event.ID, event.Sequence, sc.start.lastEventID)
+		sc.sse.ack(sc.connID, inflight)
+		return nil
+	}
+
+	if !sc.start.autoAck {
+		sc.mux.Lock()
+		sc.inflight = append(sc.inflight, inflight)
+		sc.mux.Unlock()
+	}
+
+	err := sc.write(strconv.FormatInt(event.Sequence, 10), "", &sseEvent{EventDelivery: event, Data: data})
+	if err != nil {
+		// The stream is broken, so we close it and the client must reconnect
+		sc.cancelCtx()
+		return err
+	}
+
+	if sc.start.autoAck {
+		sc.sse.ack(sc.connID, inflight)
+	}
+	return nil
+}
+
+func (sc *sseConnection) write(id, eventType string, payload interface{}) error {
+	b, _ := json.Marshal(payload) // all payloads we send are JSON serializable
+	var buf bytes.Buffer
+	if id != "" {
+		fmt.Fprintf(&buf, "id: %s\n", id)
+	}
+	if eventType != "" {
+		fmt.Fprintf(&buf, "event: %s\n", eventType)
+	}
+	// JSON is always serialized on a single line, so fits in a single data field
+	fmt.Fprintf(&buf, "data: %s\n\n", b)
+	return sc.writeRaw(buf.Bytes())
+}
+
+func (sc *sseConnection) writeComment(comment string) error {
+	return sc.writeRaw([]byte(": " + comment + "\n\n"))
+}
+
+func (sc *sseConnection) writeRaw(b []byte) error {
+	sc.mux.Lock()
+	defer sc.mux.Unlock()
+	// We must not write after the handler has returned
+	if sc.closed {
+		return i18n.NewError(sc.ctx, coremsgs.MsgSSEConnectionClosed)
+	}
+	if _, err := sc.res.Write(b); err != nil {
+		return err
+	}
+	sc.flusher.Flush()
+	return nil
+}
+
+func (sc *sseConnection) checkAck(ctx context.Context, ack *core.SSEAck) (*core.EventDeliveryResponse, error) {
+	sc.mux.Lock()
+	defer sc.mux.Unlock()
+
+	if sc.start.autoAck {
+		return nil, i18n.NewError(ctx, coremsgs.MsgSSEAutoAckEnabled, sc.connID)
+	}
+	for i, candidate := range sc.inflight {
+		// Without an ID we acknowledge the front of the queue
+		if ack.ID == nil || *ack.ID == *candidate.ID {
+			sc.inflight = append(sc.inflight[:i:i], sc.inflight[i+1:]...)
+			return candidate, nil
+		}
+	}
+	return nil, i18n.NewError(ctx, coremsgs.MsgSSEAckNotMatched, sc.connID)
+}
+
+func (sc *sseConnection) close() {
+	sc.mux.Lock()
+	sc.closed = true
+	sc.mux.Unlock()
+	sc.cancelCtx()
+	log.L(sc.ctx).Infof("Server-sent events stream closed")
+	// Drop lock before callback
+	sc.sse.connClosed(sc.connID)
+}
@@ -0,0 +1,483 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sse
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testAuthorizer struct{}
+
+func (t *testAuthorizer) Authorize(ctx context.Context, authReq *fftypes.AuthReq) error {
+	if authReq.Namespace == "ns1" {
+		return nil
+	}
+	return i18n.NewError(ctx, i18n.MsgUnauthorized)
+}
+
+type testStream struct {
+	res    *http.Response
+	reader *bufio.Reader
+}
+
+type testEvent struct {
+	id        string
+	eventType string
+	data      string
+}
+
+func newTestSSE(t *testing.T, cbs *eventsmocks.Callbacks) (*SSE, *httptest.Server, func()) {
+	coreconfig.Reset()
+
+	s := &SSE{}
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	conf := config.RootSection("ut.sse")
+	s.InitConfig(conf)
+	err := s.Init(ctx, conf)
+	assert.NoError(t, err)
+	s.SetHandler("ns1", cbs)
+	cbs2 := &eventsmocks.Callbacks{}
+	cbs2.On("ConnectionClosed", mock.Anything).Return().Maybe()
+	s.SetHandler("ns2", cbs2)
+	s.SetAuthorizer(&testAuthorizer{})
+	assert.Equal(t, "sse", s.Name())
+	assert.Equal(t, &events.Capabilities{}, s.Capabilities())
+	assert.Equal(t, 15*time.Second, s.keepAliveInterval)
+
+	svr := httptest.NewServer(s)
+	return s, svr, func() {
+		cancelCtx()
+		svr.Close()
+	}
+}
+
+func connect(t *testing.T, svr *httptest.Server, query string, header http.Header) *testStream {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s?%s", svr.URL, query), nil)
+	assert.NoError(t, err)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	return &testStream{res: res, reader: bufio.NewReader(res.Body)}
+}
+
+func (ts *testStream) readLine(t *testing.T) string {
+	line, err := ts.reader.ReadString('\n')
+	assert.NoError(t, err)
+	return strings.TrimSuffix(line, "\n")
+}
+
+func (ts *testStream) readEvent(t *testing.T) *testEvent {
+	e := &testEvent{}
+	for {
+		line := ts.readLine(t)
+		switch {
+		case line == "":
+			return e
+		case strings.HasPrefix(line, ":"):
+			// comment
+		case strings.HasPrefix(line, "id: "):
+			e.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			e.eventType = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			e.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func (ts *testStream) readConnected(t *testing.T) string {
+	e := ts.readEvent(t)
+	assert.Equal(t, "connected", e.eventType)
+	var connected core.SSEConnected
+	err := json.Unmarshal([]byte(e.data), &connected)
+	assert.NoError(t, err)
+	return connected.ConnectionID
+}
+
+func testEventDelivery(sequence int64) *core.EventDelivery {
+	return &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID:       fftypes.NewUUID(),
+				Sequence: sequence,
+				Type:     core.EventTypeMessageConfirmed,
+			},
+		},
+		Subscription: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+	}
+}
+
+func matchResponse(event *core.EventDelivery) interface{} {
+	return mock.MatchedBy(func(r *core.EventDeliveryResponse) bool {
+		return r.ID.Equals(event.ID) && !r.Rejected && r.Subscription.Name == event.Subscription.Name
+	})
+}
+
+func TestValidateOptions(t *testing.T) {
+	s := &SSE{}
+	err := s.ValidateOptions(&core.SubscriptionOptions{})
+	assert.NoError(t, err)
+}
+
+func TestEphemeralAutoAck(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, svr, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	started := make(chan struct{})
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.MatchedBy(func(f *core.SubscriptionFilter) bool {
+		return f.Topic == "topic1"
+	}), mock.MatchedBy(func(o *core.SubscriptionOptions) bool {
+		return o.FirstEvent == nil
+	})).Return(nil).Run(func(args mock.Arguments) {
+		close(started)
+	})
+	closed := make(chan struct{})
+	cbs.On("ConnectionClosed", mock.Anything).Return().Run(func(args mock.Arguments) {
+		close(closed)
+	})
+
+	stream := connect(t, svr, "namespace=ns1&ephemeral&filter.topic=topic1", nil)
+	assert.Equal(t, 200, stream.res.StatusCode)
+	assert.Equal(t, "text/event-stream", stream.res.Header.Get("Content-Type"))
+	connID := stream.readConnected(t)
+	<-started
+
+	event := testEventDelivery(12345)
+	cbs.On("DeliveryResponse", connID, matchResponse(event)).Return()
+	err := s.DeliveryRequest(connID, nil, event, core.DataArray{
+		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"value1"`)},
+	})
+	assert.NoError(t, err)
+
+	e := stream.readEvent(t)
+	assert.Equal(t, "12345", e.id)
+	assert.Empty(t, e.eventType)
+	var received sseEvent
+	err = json.Unmarshal([]byte(e.data), &received)
+	assert.NoError(t, err)
+	assert.Equal(t, event.ID, received.ID)
+	assert.Equal(t, `"value1"`, received.Data[0].Value.String())
+
+	stream.res.Body.Close()
+	<-closed
+	cbs.AssertExpectations(t)
+}
+
+func TestEphemeralResumeFromQuery(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	_, svr, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	started := make(chan struct{})
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.MatchedBy(func(o *core.SubscriptionOptions) bool {
+		return *o.FirstEvent == "100"
+	})).Return(nil).Run(func(args mock.Arguments) {
+		close(started)
+	})
+	cbs.On("ConnectionClosed", mock.Anything).Return().Maybe()
+
+	stream := connect(t, svr, "namespace=ns1&ephemeral&lastEventId=100", nil)
+	defer stream.res.Body.Close()
+	stream.readConnected(t)
+	<-started
+	cbs.AssertExpectations(t)
+}
+
+func TestDurableResumeWithAcks(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, svr, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	started := make(chan events.SubscriptionMatcher, 1)
+	cbs.On("RegisterConnection", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		started <- args[1].(events.SubscriptionMatcher)
+	})
+	cbs.On("ConnectionClosed", mock.Anything).Return().Maybe()
+
+	stream := connect(t, svr, "namespace=ns1&name=sub1&autoack=false", http.Header{
+		"Last-Event-ID": []string{"5"},
+	})
+	defer stream.res.Body.Close()
+	connID := stream.readConnected(t)
+	matcher := <-started
+	assert.True(t, matcher(core.SubscriptionRef{Namespace: "ns1", Name: "sub1"}))
+	assert.False(t, matcher(core.SubscriptionRef{Namespace: "ns1", Name: "sub2"}))
+	assert.False(t, matcher(core.SubscriptionRef{Namespace: "ns2", Name: "sub1"}))
+
+	// The client already has the first event, so it is acknowledged without being sent
+	event5 := testEventDelivery(5)
+	cbs.On("DeliveryResponse", connID, matchResponse(event5)).Return().Once()
+	err := s.DeliveryRequest(connID, nil, event5, nil)
+	assert.NoError(t, err)
+
+	event6 := testEventDelivery(6)
+	event7 := testEventDelivery(7)
+	err = s.DeliveryRequest(connID, nil, event6, nil)
+	assert.NoError(t, err)
+	err = s.DeliveryRequest(connID, nil, event7, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "6", stream.readEvent(t).id)
+	assert.Equal(t, "7", stream.readEvent(t).id)
+
+	// Ack out of order by ID, then the front of the queue
+	cbs.On("DeliveryResponse", connID, matchResponse(event7)).Return().Once()
+	err = s.Ack(context.Background(), "ns1", &core.SSEAck{ConnectionID: connID, ID: event7.ID})
+	assert.NoError(t, err)
+	cbs.On("DeliveryResponse", connID, matchResponse(event6)).Return().Once()
+	err = s.Ack(context.Background(), "ns1", &core.SSEAck{ConnectionID: connID})
+	assert.NoError(t, err)
+
+	err = s.Ack(context.Background(), "ns1", &core.SSEAck{ConnectionID: connID, ID: event6.ID})
+	assert.Regexp(t, "FF10485", err)
+	err = s.Ack(context.Background(), "ns2", &core.SSEAck{ConnectionID: connID, ID: event6.ID})
+	assert.Regexp(t, "FF10483", err)
+	err = s.Ack(context.Background(), "ns1", &core.SSEAck{ConnectionID: "unknown"})
+	assert.Regexp(t, "FF10483", err)
+
+	cbs.AssertExpectations(t)
+}
+
+func TestAckAutoAckEnabled(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, svr, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	cbs.On("RegisterConnection", mock.Anything, mock.Anything).Return(nil)
+	cbs.On("ConnectionClosed", mock.Anything).Return().Maybe()
+
+	stream := connect(t, svr, "namespace=ns1&name=sub1", nil)
+	defer stream.res.Body.Close()
+	connID := stream.readConnected(t)
+
+	err := s.Ack(context.Background(), "ns1", &core.SSEAck{ConnectionID: connID})
+	assert.Regexp(t, "FF10484", err)
+}
+
+func TestStartFail(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	_, svr, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	cbs.On("RegisterConnection", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	cbs.On("ConnectionClosed", mock.Anything).Return()
+
+	stream := connect(t, svr, "namespace=ns1&name=sub1", nil)
+	defer stream.res.Body.Close()
+	stream.readConnected(t)
+	e := stream.readEvent(t)
+	assert.Equal(t, "error", e.eventType)
+	assert.Equal(t, `{"error":"pop"}`, e.data)
+
+	_, err := stream.reader.ReadString('\n')
+	assert.Error(t, err) // closed
+}
+
+func TestBadRequests(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	_, svr, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	for query, expected := range map[string]struct {
+		status int
+		code   string
+	}{
+		"name=sub1":                               {400, "FF10481"},
+		"namespace=ns1":                           {400, "FF10481"},
+		"namespace=ns1&ephemeral=false":           {400, "FF10481"},
+		"namespace=ns3&ephemeral":                 {404, "FF10187"},
+		"namespace=ns1&ephemeral&lastEventId=bad": {400, "FF10482"},
+		"namespace=ns1&ephemeral&lastEventId=-5":  {400, "FF10482"},
+		"namespace=ns2&ephemeral":                 {401, "FF00169"},
+	} {
+		stream := connect(t, svr, query, nil)
+		assert.Equal(t, expected.status, stream.res.StatusCode, query)
+		var restErr fftypes.RESTError
+		err := json.NewDecoder(stream.res.Body).Decode(&restErr)
+		assert.NoError(t, err)
+		assert.Regexp(t, expected.code, restErr.Error, query)
+		stream.res.Body.Close()
+	}
+}
+
+type testNoFlushWriter struct {
+	http.ResponseWriter
+}
+
+func TestStreamingNotSupported(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	res := httptest.NewRecorder()
+	s.ServeHTTP(&testNoFlushWriter{ResponseWriter: res}, httptest.NewRequest(http.MethodGet, "/?namespace=ns1&ephemeral", nil))
+	assert.Equal(t, 500, res.Code)
+	assert.Regexp(t, "FF10480", res.Body.String())
+}
+
+func TestNotEnabled(t *testing.T) {
+	s := &SSE{}
+
+	res := httptest.NewRecorder()
+	s.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/?namespace=ns1&ephemeral", nil))
+	assert.Equal(t, 404, res.Code)
+	assert.Regexp(t, "FF10532", res.Body.String())
+}
+
+type testFailingWriter struct {
+	*httptest.ResponseRecorder
+	writes int
+}
+
+func (w *testFailingWriter) Write(b []byte) (int, error) {
+	w.writes--
+	if w.writes < 0 {
+		return 0, fmt.Errorf("pop")
+	}
+	return w.ResponseRecorder.Write(b)
+}
+
+func TestKeepAliveWriteFail(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, cancel := newTestSSE(t, cbs)
+	defer cancel()
+	s.keepAliveInterval = 1 * time.Millisecond
+
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(nil)
+	cbs.On("ConnectionClosed", mock.Anything).Return()
+
+	// The connected event succeeds, then the keepalive fails
+	res := &testFailingWriter{ResponseRecorder: httptest.NewRecorder(), writes: 1}
+	s.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/?namespace=ns1&ephemeral", nil))
+	assert.Regexp(t, "event: connected", res.Body.String())
+	assert.Empty(t, s.connections)
+	cbs.AssertExpectations(t)
+}
+
+func TestKeepAlive(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, svr, cancel := newTestSSE(t, cbs)
+	defer cancel()
+	s.keepAliveInterval = 1 * time.Millisecond
+
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(nil)
+	cbs.On("ConnectionClosed", mock.Anything).Return().Maybe()
+
+	stream := connect(t, svr, "namespace=ns1&ephemeral", nil)
+	defer stream.res.Body.Close()
+	stream.readConnected(t)
+	assert.Equal(t, ": keepalive", stream.readLine(t))
+}
+
+func TestConnectedWriteFail(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	cbs.On("ConnectionClosed", mock.Anything).Return()
+
+	res := &testFailingWriter{ResponseRecorder: httptest.NewRecorder()}
+	s.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/?namespace=ns1&ephemeral", nil))
+	assert.Empty(t, s.connections)
+	cbs.AssertExpectations(t)
+}
+
+func TestCloseOnShutdown(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	_, svr, cancel := newTestSSE(t, cbs)
+
+	started := make(chan struct{})
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		close(started)
+	})
+	closed := make(chan struct{})
+	cbs.On("ConnectionClosed", mock.Anything).Return().Run(func(args mock.Arguments) {
+		close(closed)
+	})
+
+	stream := connect(t, svr, "namespace=ns1&ephemeral", nil)
+	defer stream.res.Body.Close()
+	stream.readConnected(t)
+	<-started
+
+	cancel()
+	<-closed
+}
+
+func TestDeliveryRequestWriteFail(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	sc := newConnection(s.ctx, s, &sseStart{namespace: "ns1", lastEventID: -1, autoAck: true}, httptest.NewRecorder(), nil, httptest.NewRequest(http.MethodGet, "/", nil))
+	sc.closed = true
+	s.connections[sc.connID] = sc
+
+	err := s.DeliveryRequest(sc.connID, nil, testEventDelivery(1), nil)
+	assert.Regexp(t, "FF10486", err)
+	<-sc.ctx.Done()
+}
+
+func TestDeliveryRequestNotActive(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	err := s.DeliveryRequest("unknown", nil, testEventDelivery(1), nil)
+	assert.Regexp(t, "FF10483", err)
+}
+
+func TestBatchDeliveryRequestNotSupported(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	err := s.BatchDeliveryRequest("conn1", nil, fftypes.NewUUID(), nil)
+	assert.Regexp(t, "FF10446", err)
+}
+
+func TestAckUnknownNamespaceCallbacks(t *testing.T) {
+	s := &SSE{callbacks: map[string]events.Callbacks{}}
+	// No callbacks for the namespace is a no-op
+	s.ack("conn1", &core.EventDeliveryResponse{Subscription: core.SubscriptionRef{Namespace: "ns3"}})
+}
+
+func TestNoAuthorizer(t *testing.T) {
+	s := &SSE{}
+	err := s.authorize(httptest.NewRequest(http.MethodGet, "/", nil), "ns1")
+	assert.NoError(t, err)
+}
@@ -18,10 +18,13 @@ package events
 
 import (
 	"context"
+	"database/sql/driver"
 	"regexp"
 	"sync"
+	"text/template"
 
 	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
@@ -46,6 +49,8 @@ type subscription struct {
 	blockchainFilter   *blockchainFilter
 	transactionFilter  *transactionFilter
 	topicFilter        *regexp.Regexp
+	expressionFilter   *ffapi.FilterInfo
+	transform          *template.Template
 }
 
 type messageFilter struct {
@@ -261,6 +266,24 @@ func (sm *subscriptionManager) parseSubscriptionDef(ctx context.Context, subDef
 		return nil, err
 	}
 
+	if subDef.Options.Batch != nil && *subDef.Options.Batch && !transport.Capabilities().BatchDelivery {
+		return nil, i18n.NewError(ctx, coremsgs.MsgBatchDeliveryNotSupported, transport.Name())
+	}
+
+	if subDef.Options.Transform != nil && !transport.Capabilities().Transform {
+		return nil, i18n.NewError(ctx, coremsgs.MsgTransformNotSupported, transport.Name())
+	}
+
+	if subDef.Options.PartitionKey != nil {
+		if subDef.Options.Batch != nil && *subDef.Options.Batch {
+			// Batches are acknowledged as a whole, so there is nothing to partition
+			return nil, i18n.NewError(ctx, coremsgs.MsgPartitionKeyWithBatch)
+		}
+		if _, err := getPartitionKeyFunc(ctx, *subDef.Options.PartitionKey); err != nil {
+			return nil, err
+		}
+	}
+
 	var eventFilter *regexp.Regexp
 	if filter.Events != "" {
 		eventFilter, err = regexp.Compile(filter.Events)
@@ -387,6 +410,20 @@ func (sm *subscriptionManager) parseSubscriptionDef(ctx context.Context, subDef
 		sub.transactionFilter = tf
 	}
 
+	if filter.Expression != "" {
+		sub.expressionFilter, err = parseFilterExpression(ctx, filter.Expression)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if subDef.Options.Transform != nil {
+		sub.transform, err = compileTransform(ctx, subDef.Name, *subDef.Options.Transform)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return sub, err
 }
 
@@ -542,3 +579,37 @@ func (sm *subscriptionManager) deliveryResponse(ei events.Plugin, connID string,
 	sm.mux.Unlock()
 	dispatcher.deliveryResponse(inflight)
 }
+
+func (sm *subscriptionManager) replayDeadLetters(ctx context.Context, subDef *core.Subscription, ids []*fftypes.UUID) ([]*core.DeadLetter, error) {
+	fb := database.DeadLetterQueryFactory.NewFilter(ctx)
+	filter := fb.And(fb.Eq("subscription", subDef.ID))
+	if len(ids) > 0 {
+		idValues := make([]driver.Value, len(ids))
+		for i, id := range ids {
+			idValues[i] = id
+		}
+		filter = filter.Condition(fb.In("id", idValues))
+	}
+	deadLetters, _, err := sm.database.GetDeadLetters(ctx, sm.namespace, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Any dispatcher for the subscription can deliver the replays, as they do not affect the offset.
+	// We must not hold the lock while delivering, as responses can be delivered synchronously.
+	sm.mux.Lock()
+	var dispatcher *eventDispatcher
+	for _, conn := range sm.connections {
+		if d, ok := conn.dispatchers[*subDef.ID]; ok {
+			dispatcher = d
+			break
+		}
+	}
+	sm.mux.Unlock()
+	if dispatcher == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgSubscriptionNotConnected, subDef.ID)
+	}
+
+	log.L(ctx).Infof("Replaying %d dead letters for subscription %s on connection %s", len(deadLetters), subDef.ID, dispatcher.connID)
+	return dispatcher.replayDeadLetters(deadLetters)
+}
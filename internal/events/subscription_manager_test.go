@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly/internal/cache"
 	"github.com/hyperledger/firefly/internal/coreconfig"
@@ -62,6 +63,7 @@ func newTestSubManager(t *testing.T, mei *eventsmocks.Plugin) (*subscriptionMana
 	mei.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	mdi.On("GetEvents", mock.Anything, mock.Anything, mock.Anything).Return([]*core.Event{}, nil, nil).Maybe()
 	mdi.On("GetOffset", mock.Anything, mock.Anything, mock.Anything).Return(&core.Offset{RowID: 3333333, Current: 0}, nil).Maybe()
+	mdi.On("AcquireLeadership", mock.Anything, mock.Anything).Return(make(<-chan struct{}), nil).Maybe()
 	sm, err := newSubscriptionManager(ctx, "ns1", enricher, mdi, mdm, newEventNotifier(ctx, "ut"), mbm, mpm, txHelper, nil)
 	assert.NoError(t, err)
 	sm.transports = map[string]events.Plugin{
@@ -295,6 +297,125 @@ func TestCreateSubscriptionBadTransportOptions(t *testing.T) {
 	assert.Regexp(t, "pop", err)
 }
 
+func TestCreateSubscriptionBatchNotSupported(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	yes := true
+	sub := &core.Subscription{
+		Transport: "ut",
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				Batch: &yes,
+			},
+		},
+	}
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, sub)
+	assert.Regexp(t, "FF10446", err)
+}
+
+func TestCreateSubscriptionTransformNotSupported(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	transform := `{"id":{{json .id}}}`
+	sub := &core.Subscription{
+		Transport: "ut",
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				Transform: &transform,
+			},
+		},
+	}
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, sub)
+	assert.Regexp(t, "FF10475", err)
+}
+
+func TestCreateSubscriptionTransform(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	mei.On("Capabilities").Return(&events.Capabilities{Transform: true})
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	transform := `{"id":{{json .id}}}`
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{Name: "sub1"},
+		Transport:       "ut",
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				Transform: &transform,
+			},
+		},
+	}
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	newSub, err := sm.parseSubscriptionDef(sm.ctx, sub)
+	assert.NoError(t, err)
+	assert.Equal(t, "sub1", newSub.transform.Name())
+
+	badTransform := `{{ .id `
+	sub.Options.Transform = &badTransform
+	_, err = sm.parseSubscriptionDef(sm.ctx, sub)
+	assert.Regexp(t, "FF10474", err)
+}
+
+func TestCreateSubscriptionPartitionKey(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	partitionKey := core.SubOptsPartitionKeyAuthor
+	sub := &core.Subscription{
+		Transport: "ut",
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				PartitionKey: &partitionKey,
+			},
+		},
+	}
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, sub)
+	assert.NoError(t, err)
+}
+
+func TestCreateSubscriptionBadPartitionKey(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	partitionKey := core.SubOptsPartitionKey("cid")
+	sub := &core.Subscription{
+		Transport: "ut",
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				PartitionKey: &partitionKey,
+			},
+		},
+	}
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, sub)
+	assert.Regexp(t, "FF10478.*author,group,topic,tx", err)
+}
+
+func TestCreateSubscriptionPartitionKeyWithBatch(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	mei.On("Capabilities").Return(&events.Capabilities{BatchDelivery: true})
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	partitionKey := core.SubOptsPartitionKeyTopic
+	yes := true
+	sub := &core.Subscription{
+		Transport: "ut",
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				Batch:        &yes,
+				PartitionKey: &partitionKey,
+			},
+		},
+	}
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, sub)
+	assert.Regexp(t, "FF10479", err)
+}
+
 func TestCreateSubscriptionBadEventilter(t *testing.T) {
 	mei := &eventsmocks.Plugin{}
 	sm, cancel := newTestSubManager(t, mei)
@@ -523,6 +644,35 @@ func TestCreateSubscriptionSuccessBlockchainEvent(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestCreateSubscriptionSuccessExpressionFilter(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	sub, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Filter: core.SubscriptionFilter{
+			Expression: "tokentransfer.amount=>=100",
+		},
+		Transport: "ut",
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, sub.expressionFilter)
+}
+
+func TestCreateSubscriptionBadExpressionFilter(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Filter: core.SubscriptionFilter{
+			Expression: "tokentransfer.amount=!>=100",
+		},
+		Transport: "ut",
+	})
+	assert.Regexp(t, "FF00193", err)
+}
+
 func TestCreateSubscriptionWithDeprecatedFilters(t *testing.T) {
 	mei := &eventsmocks.Plugin{}
 	sm, cancel := newTestSubManager(t, mei)
@@ -855,3 +1005,63 @@ func TestDeleteDurableSubscriptionOk(t *testing.T) {
 	assert.Empty(t, sm.durableSubs)
 	<-ed.closed
 }
+
+func TestReplayDeadLettersOk(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := sm.database.(*databasemocks.Plugin)
+
+	subID := fftypes.NewUUID()
+	sub := &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{ID: subID, Namespace: "ns1", Name: "sub1"},
+		},
+	}
+	ed, edCancel := newTestEventDispatcher(sub)
+	defer edCancel()
+	sm.connections["conn1"] = &connection{
+		id:          "conn1",
+		dispatchers: map[fftypes.UUID]*eventDispatcher{*subID: ed},
+	}
+
+	dlID := fftypes.NewUUID()
+	mdi.On("GetDeadLetters", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.AndFilter) bool {
+		fi, _ := f.Finalize()
+		return fi.String() == fmt.Sprintf("( subscription == '%s' ) && ( id IN ['%s'] )", subID, dlID)
+	})).Return([]*core.DeadLetter{}, nil, nil)
+
+	replayed, err := sm.replayDeadLetters(sm.ctx, sub.definition, []*fftypes.UUID{dlID})
+	assert.NoError(t, err)
+	assert.Empty(t, replayed)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestReplayDeadLettersNotConnected(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := sm.database.(*databasemocks.Plugin)
+	mdi.On("GetDeadLetters", mock.Anything, "ns1", mock.Anything).Return([]*core.DeadLetter{}, nil, nil)
+
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID()}}
+	_, err := sm.replayDeadLetters(sm.ctx, sub, nil)
+	assert.Regexp(t, "FF10448", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestReplayDeadLettersQueryFail(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := sm.database.(*databasemocks.Plugin)
+	mdi.On("GetDeadLetters", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID()}}
+	_, err := sm.replayDeadLetters(sm.ctx, sub, nil)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
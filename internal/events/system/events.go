@@ -22,6 +22,8 @@ import (
 
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/events"
 )
@@ -97,6 +99,10 @@ func (se *Events) AddListener(ns string, el EventListener) error {
 	return nil
 }
 
+func (se *Events) BatchDeliveryRequest(connID string, sub *core.Subscription, batchID *fftypes.UUID, events []*core.CombinedEventDataDelivery) error {
+	return i18n.NewError(se.ctx, coremsgs.MsgBatchDeliveryNotSupported, se.Name()) // should never happen, as we don't declare the capability
+}
+
 func (se *Events) DeliveryRequest(connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
 	se.mux.Lock()
 	defer se.mux.Unlock()
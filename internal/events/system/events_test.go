@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/mocks/eventsmocks"
 	"github.com/hyperledger/firefly/pkg/core"
@@ -131,3 +132,13 @@ func TestAddListenerFail(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 
 }
+
+func TestBatchDeliveryRequestNotSupported(t *testing.T) {
+
+	se, cancel := newTestEvents(t)
+	defer cancel()
+
+	assert.False(t, se.Capabilities().BatchDelivery)
+	err := se.BatchDeliveryRequest(se.connID, &core.Subscription{}, fftypes.NewUUID(), []*core.CombinedEventDataDelivery{})
+	assert.Regexp(t, "FF10446", err)
+}
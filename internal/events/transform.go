@@ -0,0 +1,74 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"text/template"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+type transformInput struct {
+	*core.EventDelivery
+	Data core.DataArray `json:"data,omitempty"`
+}
+
+var transformFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// compileTransform parses the transform template of a subscription, so that errors are reported when the subscription is created
+func compileTransform(ctx context.Context, name, source string) (*template.Template, error) {
+	t, err := template.New(name).Funcs(transformFuncs).Parse(source)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgInvalidSubscriptionTransform)
+	}
+	return t, nil
+}
+
+// transformEvent executes the transform template of the subscription (if there is one), against the JSON of the event
+// and its data. The output must be valid JSON, and is delivered by the transport in place of the event.
+func (ed *eventDispatcher) transformEvent(event *core.EventDelivery, data core.DataArray) error {
+	if ed.subscription.transform == nil {
+		return nil
+	}
+
+	var input map[string]interface{}
+	b, _ := json.Marshal(&transformInput{EventDelivery: event, Data: data})
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber() // retain the precision of large numbers, such as token amounts
+	_ = d.Decode(&input)
+
+	out := &bytes.Buffer{}
+	if err := ed.subscription.transform.Execute(out, input); err != nil {
+		return i18n.WrapError(ed.ctx, err, coremsgs.MsgTransformFailed, event.ID)
+	}
+	if !json.Valid(out.Bytes()) {
+		return i18n.NewError(ed.ctx, coremsgs.MsgTransformInvalidJSON, event.ID)
+	}
+	event.Transformed = fftypes.JSONAnyPtrBytes(out.Bytes())
+	return nil
+}
@@ -0,0 +1,169 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestTransformDispatcher(t *testing.T, transform string, batch bool) (*eventDispatcher, func()) {
+	tmpl, err := compileTransform(context.Background(), "sub1", transform)
+	assert.NoError(t, err)
+	ed, cancel := newTestDeadLetterDispatcher(5, batch)
+	ed.subscription.transform = tmpl
+	return ed, cancel
+}
+
+func TestCompileTransformFail(t *testing.T) {
+	_, err := compileTransform(context.Background(), "sub1", "{{ bad }}")
+	assert.Regexp(t, "FF10474", err)
+}
+
+func TestTransformEvent(t *testing.T) {
+	ed, cancel := newTestTransformDispatcher(t, `{"eventId":{{json .id}},"tag":{{json .message.header.tag}},"big":{{json (index .data 0).value.big}}}`, false)
+	defer cancel()
+
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{ID: fftypes.NewUUID()},
+			Message: &core.Message{
+				Header: core.MessageHeader{Tag: "tag1"},
+			},
+		},
+	}
+	err := ed.transformEvent(event, core.DataArray{
+		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`{"big":1180591620717411303424}`)},
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"eventId": "`+event.ID.String()+`",
+		"tag": "tag1",
+		"big": 1180591620717411303424
+	}`, event.Transformed.String())
+	assert.Contains(t, event.Transformed.String(), "1180591620717411303424")
+}
+
+func TestTransformEventNoTransform(t *testing.T) {
+	ed, cancel := newTestDeadLetterDispatcher(5, false)
+	defer cancel()
+
+	event := &core.EventDelivery{}
+	err := ed.transformEvent(event, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, event.Transformed)
+}
+
+func TestTransformEventExecuteFail(t *testing.T) {
+	ed, cancel := newTestTransformDispatcher(t, `{{ template "missing" }}`, false)
+	defer cancel()
+
+	event := &core.EventDelivery{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: fftypes.NewUUID()}}}
+	err := ed.transformEvent(event, nil)
+	assert.Regexp(t, "FF10476", err)
+	assert.Nil(t, event.Transformed)
+}
+
+func TestTransformEventInvalidJSON(t *testing.T) {
+	ed, cancel := newTestTransformDispatcher(t, `{"id":{{.id}}}`, false)
+	defer cancel()
+
+	event := &core.EventDelivery{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: fftypes.NewUUID()}}}
+	err := ed.transformEvent(event, nil)
+	assert.Regexp(t, "FF10477", err)
+}
+
+func TestDeliverEventsTransformed(t *testing.T) {
+	ed, cancel := newTestTransformDispatcher(t, `{"id":{{json .id}}}`, false)
+	defer cancel()
+
+	id1 := fftypes.NewUUID()
+	mei := ed.transport.(*eventsmocks.Plugin)
+	delivered := make(chan struct{})
+	mei.On("DeliveryRequest", ed.connID, ed.subscription.definition, mock.MatchedBy(func(e *core.EventDelivery) bool {
+		return e.Transformed.String() == `{"id":"`+id1.String()+`"}`
+	}), core.DataArray(nil)).Return(nil).Run(func(a mock.Arguments) {
+		close(delivered)
+	})
+
+	ed.eventDelivery <- &core.EventDelivery{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: id1}}}
+	go ed.deliverEvents()
+	<-delivered
+
+	mei.AssertExpectations(t)
+}
+
+func TestDeliverEventsTransformFailNack(t *testing.T) {
+	ed, cancel := newTestTransformDispatcher(t, `not json`, false)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mockRunAsGroupPassthrough(mdi)
+	id1 := fftypes.NewUUID()
+	ed.inflight[*id1] = &core.Event{ID: id1}
+	ed.eventDelivery <- &core.EventDelivery{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: id1}}}
+	go ed.deliverEvents()
+
+	an := <-ed.acksNacks
+	assert.True(t, an.isNack)
+
+	ed.transport.(*eventsmocks.Plugin).AssertNotCalled(t, "DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDispatchBatchTransformFailNack(t *testing.T) {
+	ed, cancel := newTestTransformDispatcher(t, `not json`, true)
+	defer cancel()
+
+	id1 := fftypes.NewUUID()
+	ed.inflight[*id1] = &core.Event{ID: id1, Sequence: 12345}
+	ed.dispatchBatch([]*core.EventDelivery{
+		{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: id1, Sequence: 12345}}},
+	}, false)
+
+	an := <-ed.acksNacks
+	assert.True(t, an.isNack)
+	assert.Equal(t, []fftypes.UUID{*id1}, an.batch)
+
+	mdm := ed.data.(*datamocks.Manager)
+	mdm.AssertExpectations(t)
+}
+
+func TestReplayDeadLettersTransformFail(t *testing.T) {
+	ed, cancel := newTestTransformDispatcher(t, `not json`, false)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	ev1 := &core.Event{ID: fftypes.NewUUID(), Sequence: 100}
+	dl1 := &core.DeadLetter{ID: fftypes.NewUUID(), Event: ev1.ID, Attempts: 1}
+	mdi.On("GetEventByID", mock.Anything, "ns1", ev1.ID).Return(ev1, nil)
+	mdi.On("UpdateDeadLetter", mock.Anything, "ns1", dl1.ID, mock.Anything).Return(nil)
+
+	replayed, err := ed.replayDeadLetters([]*core.DeadLetter{dl1})
+	assert.NoError(t, err)
+	assert.Equal(t, []*core.DeadLetter{dl1}, replayed)
+	assert.Empty(t, ed.replays)
+
+	mdi.AssertExpectations(t)
+}
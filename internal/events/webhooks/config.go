@@ -21,6 +21,42 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/ffresty"
 )
 
+const (
+	// SigningConfig is the sub-section for the signing of webhook requests
+	SigningConfig = "signing"
+	// SigningEncryptionKey is the hex encoded AES-256 key used to encrypt inline signing secrets
+	SigningEncryptionKey = "encryptionKey"
+	// SigningSecrets is the array of named signing secrets that subscriptions can reference
+	SigningSecrets = "secrets"
+	// SigningSecretName is the name subscriptions use to reference a signing secret
+	SigningSecretName = "name"
+	// SigningSecret is the value of a signing secret
+	SigningSecret = "secret"
+	// TLSConfigs is the array of named TLS configurations that subscriptions can reference
+	TLSConfigs = "tlsConfigs"
+	// TLSConfigName is the name subscriptions use to reference a TLS configuration
+	TLSConfigName = "name"
+	// TLSConfigCertFile is the path to a PEM encoded client certificate
+	TLSConfigCertFile = "certFile"
+	// TLSConfigKeyFile is the path to the PEM encoded private key of the client certificate
+	TLSConfigKeyFile = "keyFile"
+	// TLSConfigCAFile is the path to a PEM encoded CA bundle
+	TLSConfigCAFile = "caFile"
+)
+
 func (wh *WebHooks) InitConfig(config config.Section) {
 	ffresty.InitConfig(config)
+
+	signingConf := config.SubSection(SigningConfig)
+	signingConf.AddKnownKey(SigningEncryptionKey)
+	// The array section must be retained, as the entries only know their keys via the section they were added to
+	wh.secretsConf = signingConf.SubArray(SigningSecrets)
+	wh.secretsConf.AddKnownKey(SigningSecretName)
+	wh.secretsConf.AddKnownKey(SigningSecret)
+
+	wh.tlsConf = config.SubArray(TLSConfigs)
+	wh.tlsConf.AddKnownKey(TLSConfigName)
+	wh.tlsConf.AddKnownKey(TLSConfigCertFile)
+	wh.tlsConf.AddKnownKey(TLSConfigKeyFile)
+	wh.tlsConf.AddKnownKey(TLSConfigCAFile)
 }
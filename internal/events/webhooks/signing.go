@@ -0,0 +1,180 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+const (
+	// HeaderEventID is the ID of the event being delivered, or the ID of the batch for batched deliveries
+	HeaderEventID = "X-FireFly-Event-ID"
+	// HeaderTimestamp is the time of the delivery attempt, in seconds since the epoch
+	HeaderTimestamp = "X-FireFly-Timestamp"
+	// HeaderSignature is the HMAC-SHA256 signature of "<event id>.<timestamp>.<body>", formatted as "v1=<hex>"
+	HeaderSignature = "X-FireFly-Signature"
+)
+
+// whSigner holds the plugin level configuration for signing webhook requests.
+// Receivers verify the signature, then reject replays by checking the timestamp is recent and the event ID has not been seen before.
+type whSigner struct {
+	aead    cipher.AEAD
+	secrets map[string][]byte
+}
+
+func newSigner(ctx context.Context, conf config.Section, secretsConf config.ArraySection) (*whSigner, error) {
+	s := &whSigner{
+		secrets: make(map[string][]byte),
+	}
+	if keyHex := conf.GetString(SigningEncryptionKey); keyHex != "" {
+		key, err := hex.DecodeString(keyHex)
+		if err != nil || len(key) != 32 {
+			return nil, i18n.NewError(ctx, coremsgs.MsgWebhookSigningBadEncryptionKey)
+		}
+		block, _ := aes.NewCipher(key) // only fails for an invalid key length
+		s.aead, _ = cipher.NewGCM(block)
+	}
+	for i := 0; i < secretsConf.ArraySize(); i++ {
+		secretConf := secretsConf.ArrayEntry(i)
+		name := secretConf.GetString(SigningSecretName)
+		secret := secretConf.GetString(SigningSecret)
+		if name == "" || secret == "" {
+			return nil, i18n.NewError(ctx, coremsgs.MsgWebhookSigningBadSecretConfig, i)
+		}
+		s.secrets[name] = []byte(secret)
+	}
+	return s, nil
+}
+
+func (wh *WebHooks) parseSigningOptions(options fftypes.JSONObject) (*core.WebhookSigning, error) {
+	if options["signing"] == nil {
+		return nil, nil
+	}
+	var signing core.WebhookSigning
+	b, _ := json.Marshal(options["signing"])
+	if err := json.Unmarshal(b, &signing); err != nil {
+		return nil, i18n.WrapError(wh.ctx, err, coremsgs.MsgWebhookSigningInvalidOptions)
+	}
+	set := 0
+	for _, v := range []string{signing.Secret, signing.SecretRef, signing.EncryptedSecret} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, i18n.NewError(wh.ctx, coremsgs.MsgWebhookSigningSecretRequired)
+	}
+	return &signing, nil
+}
+
+// validateSigningOptions checks the signing options of a subscription, and replaces any inline
+// secret with its encrypted form - so the secret in plaintext is never stored, or returned on the API
+func (wh *WebHooks) validateSigningOptions(options fftypes.JSONObject) error {
+	signing, err := wh.parseSigningOptions(options)
+	if err != nil || signing == nil {
+		return err
+	}
+	if signing.Secret != "" {
+		if wh.signer.aead == nil {
+			return i18n.NewError(wh.ctx, coremsgs.MsgWebhookSigningNoEncryptionKey)
+		}
+		nonce := make([]byte, wh.signer.aead.NonceSize())
+		_, _ = rand.Read(nonce)
+		encrypted := wh.signer.aead.Seal(nonce, nonce, []byte(signing.Secret), nil)
+		options["signing"] = fftypes.JSONObject{
+			"encryptedSecret": base64.StdEncoding.EncodeToString(encrypted),
+		}
+		return nil
+	}
+	_, err = wh.resolveSigningSecret(signing)
+	return err
+}
+
+func (wh *WebHooks) resolveSigningSecret(signing *core.WebhookSigning) ([]byte, error) {
+	if signing.SecretRef != "" {
+		secret, ok := wh.signer.secrets[signing.SecretRef]
+		if !ok {
+			return nil, i18n.NewError(wh.ctx, coremsgs.MsgWebhookSigningUnknownSecretRef, signing.SecretRef)
+		}
+		return secret, nil
+	}
+	if wh.signer.aead == nil {
+		return nil, i18n.NewError(wh.ctx, coremsgs.MsgWebhookSigningNoEncryptionKey)
+	}
+	encrypted, err := base64.StdEncoding.DecodeString(signing.EncryptedSecret)
+	nonceSize := wh.signer.aead.NonceSize()
+	if err != nil || len(encrypted) < nonceSize {
+		return nil, i18n.NewError(wh.ctx, coremsgs.MsgWebhookSigningDecryptFailed)
+	}
+	secret, err := wh.signer.aead.Open(nil, encrypted[:nonceSize], encrypted[nonceSize:], nil)
+	if err != nil {
+		return nil, i18n.NewError(wh.ctx, coremsgs.MsgWebhookSigningDecryptFailed)
+	}
+	return secret, nil
+}
+
+// signRequest adds the signature headers to a request, if the subscription has signing enabled.
+// This must be called after the body has been set, as the body is serialized here so that the
+// signature covers exactly the bytes that are sent.
+func (wh *WebHooks) signRequest(req *whRequest, options fftypes.JSONObject, id *fftypes.UUID) error {
+	signing, err := wh.parseSigningOptions(options)
+	if err != nil || signing == nil {
+		return err
+	}
+	secret, err := wh.resolveSigningSecret(signing)
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	switch b := req.r.Body.(type) {
+	case nil:
+	case []byte:
+		body = b
+	default:
+		body, _ = json.Marshal(b) // all bodies we build are JSON serializable
+		req.r.SetBody(body)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.r.SetHeader(HeaderEventID, id.String())
+	req.r.SetHeader(HeaderTimestamp, timestamp)
+	req.r.SetHeader(HeaderSignature, "v1="+computeSignature(secret, id.String(), timestamp, body))
+	return nil
+}
+
+func computeSignature(secret []byte, id, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(id + "." + timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,353 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testEncryptionKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+func newTestSigningWebHooks(t *testing.T) (wh *WebHooks, cancel func()) {
+	coreconfig.Reset()
+
+	cbs := &eventsmocks.Callbacks{}
+	cbs.On("RegisterConnection", mock.Anything, mock.Anything).Return(nil)
+	wh = &WebHooks{}
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	svrConfig := config.RootSection("ut.webhooks")
+	wh.InitConfig(svrConfig)
+	svrConfig.Set("signing.encryptionKey", testEncryptionKey)
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+ut:
+  webhooks:
+    signing:
+      secrets:
+      - name: secret1
+        secret: shared-secret-1
+`))
+	assert.NoError(t, err)
+	err = wh.Init(ctx, svrConfig)
+	assert.NoError(t, err)
+	wh.SetHandler("ns1", cbs)
+	return wh, cancelCtx
+}
+
+// verifySignature is what a receiver does to check a request
+func verifySignature(t *testing.T, req *http.Request, secret string) (id string, body []byte) {
+	body, err := io.ReadAll(req.Body)
+	assert.NoError(t, err)
+	id = req.Header.Get(HeaderEventID)
+	timestamp := req.Header.Get(HeaderTimestamp)
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), time.Unix(ts, 0), time.Minute)
+	expected := "v1=" + computeSignature([]byte(secret), id, timestamp, body)
+	assert.True(t, hmac.Equal([]byte(expected), []byte(req.Header.Get(HeaderSignature))))
+	return id, body
+}
+
+func TestInitBadEncryptionKey(t *testing.T) {
+	coreconfig.Reset()
+	wh := &WebHooks{}
+	svrConfig := config.RootSection("ut.webhooks")
+	wh.InitConfig(svrConfig)
+	svrConfig.Set("signing.encryptionKey", "0011")
+	err := wh.Init(context.Background(), svrConfig)
+	assert.Regexp(t, "FF10465", err)
+}
+
+func TestInitBadSigningSecret(t *testing.T) {
+	coreconfig.Reset()
+	wh := &WebHooks{}
+	svrConfig := config.RootSection("ut.webhooks")
+	wh.InitConfig(svrConfig)
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+ut:
+  webhooks:
+    signing:
+      secrets:
+      - name: secret1
+`))
+	assert.NoError(t, err)
+	err = wh.Init(context.Background(), svrConfig)
+	assert.Regexp(t, "FF10467.*0", err)
+}
+
+func TestValidateSigningInlineSecretEncrypted(t *testing.T) {
+	wh, cancel := newTestSigningWebHooks(t)
+	defer cancel()
+
+	opts := &core.SubscriptionOptions{}
+	opts.TransportOptions()["url"] = "/anything"
+	opts.TransportOptions()["signing"] = fftypes.JSONObject{"secret": "my-secret"}
+	err := wh.ValidateOptions(opts)
+	assert.NoError(t, err)
+
+	signing := opts.TransportOptions().GetObject("signing")
+	assert.Empty(t, signing.GetString("secret"))
+	encrypted := signing.GetString("encryptedSecret")
+	assert.NotEmpty(t, encrypted)
+	assert.NotContains(t, opts.TransportOptions().String(), "my-secret")
+
+	// The stored form re-validates, and decrypts back to the original secret
+	err = wh.ValidateOptions(opts)
+	assert.NoError(t, err)
+	secret, err := wh.resolveSigningSecret(&core.WebhookSigning{EncryptedSecret: encrypted})
+	assert.NoError(t, err)
+	assert.Equal(t, "my-secret", string(secret))
+}
+
+func TestValidateSigningErrors(t *testing.T) {
+	wh, cancel := newTestSigningWebHooks(t)
+	defer cancel()
+
+	for signing, errMsg := range map[string]interface{}{
+		"FF10461": "not an object",
+		"FF10462": fftypes.JSONObject{"secret": "s1", "secretRef": "secret1"},
+		"FF10463": fftypes.JSONObject{"secretRef": "unknown"},
+		"FF10466": fftypes.JSONObject{"encryptedSecret": "!!!"},
+	} {
+		opts := &core.SubscriptionOptions{}
+		opts.TransportOptions()["url"] = "/anything"
+		opts.TransportOptions()["signing"] = errMsg
+		err := wh.ValidateOptions(opts)
+		assert.Regexp(t, signing, err)
+	}
+
+	opts := &core.SubscriptionOptions{}
+	opts.TransportOptions()["url"] = "/anything"
+	opts.TransportOptions()["signing"] = fftypes.JSONObject{}
+	err := wh.ValidateOptions(opts)
+	assert.Regexp(t, "FF10462", err)
+
+	_, err = wh.resolveSigningSecret(&core.WebhookSigning{EncryptedSecret: "AAAA"})
+	assert.Regexp(t, "FF10466", err)
+	_, err = wh.resolveSigningSecret(&core.WebhookSigning{EncryptedSecret: "AAAAAAAAAAAAAAAAAAAAAAAA"})
+	assert.Regexp(t, "FF10466", err)
+}
+
+func TestValidateSigningNoEncryptionKey(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	opts := &core.SubscriptionOptions{}
+	opts.TransportOptions()["url"] = "/anything"
+	opts.TransportOptions()["signing"] = fftypes.JSONObject{"secret": "my-secret"}
+	err := wh.ValidateOptions(opts)
+	assert.Regexp(t, "FF10464", err)
+
+	opts.TransportOptions()["signing"] = fftypes.JSONObject{"encryptedSecret": "AAAA"}
+	err = wh.ValidateOptions(opts)
+	assert.Regexp(t, "FF10464", err)
+}
+
+func TestDeliveryRequestSigned(t *testing.T) {
+	wh, cancel := newTestSigningWebHooks(t)
+	defer cancel()
+
+	eventID := fftypes.NewUUID()
+	called := false
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		id, body := verifySignature(t, req, "shared-secret-1")
+		assert.Equal(t, eventID.String(), id)
+		assert.JSONEq(t, `{"inputfield":"inputvalue"}`, string(body))
+		res.WriteHeader(200)
+		called = true
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	yes := true
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{Namespace: "ns1"},
+	}
+	sub.Options.WithData = &yes
+	sub.Options.TransportOptions()["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	sub.Options.TransportOptions()["signing"] = fftypes.JSONObject{"secretRef": "secret1"}
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{ID: eventID},
+		},
+	}
+
+	mcb := wh.callbacks["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return !response.Rejected
+	})).Return(nil)
+
+	err := wh.DeliveryRequest(mock.Anything, sub, event, core.DataArray{
+		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`{"inputfield":"inputvalue"}`)},
+	})
+	assert.NoError(t, err)
+	assert.True(t, called)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestDeliveryRequestSignedNoBody(t *testing.T) {
+	wh, cancel := newTestSigningWebHooks(t)
+	defer cancel()
+
+	called := false
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		_, body := verifySignature(t, req, "shared-secret-1")
+		assert.Empty(t, body)
+		res.WriteHeader(200)
+		called = true
+	}).Methods(http.MethodGet)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{Namespace: "ns1"},
+	}
+	sub.Options.TransportOptions()["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	sub.Options.TransportOptions()["method"] = http.MethodGet
+	sub.Options.TransportOptions()["signing"] = fftypes.JSONObject{"secretRef": "secret1"}
+
+	mcb := wh.callbacks["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.Anything).Return(nil)
+
+	err := wh.DeliveryRequest(mock.Anything, sub, &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: fftypes.NewUUID()}},
+	}, nil)
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
+func TestDeliveryRequestSigningFailRejected(t *testing.T) {
+	wh, cancel := newTestSigningWebHooks(t)
+	defer cancel()
+
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{Namespace: "ns1"},
+	}
+	sub.Options.Retry = &core.SubscriptionRetry{MaxAttempts: 3}
+	sub.Options.TransportOptions()["url"] = "http://localhost:1/myapi"
+	// The referenced secret might have been removed from the config since the subscription was created
+	sub.Options.TransportOptions()["signing"] = fftypes.JSONObject{"secretRef": "removed"}
+
+	mcb := wh.callbacks["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return response.Rejected
+	})).Return(nil)
+
+	err := wh.DeliveryRequest(mock.Anything, sub, &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: fftypes.NewUUID()}},
+	}, nil)
+	assert.NoError(t, err)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestBatchDeliveryRequestSigned(t *testing.T) {
+	wh, cancel := newTestSigningWebHooks(t)
+	defer cancel()
+
+	batchID := fftypes.NewUUID()
+	called := false
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		id, _ := verifySignature(t, req, "my-secret")
+		assert.Equal(t, batchID.String(), id)
+		res.WriteHeader(200)
+		called = true
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	yes := true
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{Namespace: "ns1"},
+	}
+	sub.Options.Batch = &yes
+	sub.Options.TransportOptions()["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	sub.Options.TransportOptions()["signing"] = fftypes.JSONObject{"secret": "my-secret"}
+	err := wh.ValidateOptions(&sub.Options)
+	assert.NoError(t, err)
+
+	mcb := wh.callbacks["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return *response.ID == *batchID && !response.Rejected
+	})).Return(nil)
+
+	err = wh.BatchDeliveryRequest(mock.Anything, sub, batchID, []*core.CombinedEventDataDelivery{
+		{Event: &core.EventDelivery{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: fftypes.NewUUID()}}}},
+	})
+	assert.NoError(t, err)
+	assert.True(t, called)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestBatchDeliveryRequestSigningFail(t *testing.T) {
+	wh, cancel := newTestSigningWebHooks(t)
+	defer cancel()
+
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{Namespace: "ns1"},
+	}
+	sub.Options.Retry = &core.SubscriptionRetry{MaxAttempts: 3}
+	sub.Options.TransportOptions()["url"] = "http://localhost:1/myapi"
+	sub.Options.TransportOptions()["signing"] = fftypes.JSONObject{"secretRef": "removed"}
+
+	batchID := fftypes.NewUUID()
+	mcb := wh.callbacks["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return *response.ID == *batchID && response.Rejected
+	})).Return(nil)
+
+	err := wh.BatchDeliveryRequest(mock.Anything, sub, batchID, []*core.CombinedEventDataDelivery{})
+	assert.NoError(t, err)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestSignRequestRawBody(t *testing.T) {
+	wh, cancel := newTestSigningWebHooks(t)
+	defer cancel()
+
+	req := &whRequest{r: wh.client.R().SetBody([]byte("raw"))}
+	id := fftypes.NewUUID()
+	err := wh.signRequest(req, fftypes.JSONObject{"signing": fftypes.JSONObject{"secretRef": "secret1"}}, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1="+computeSignature([]byte("shared-secret-1"), id.String(), req.r.Header.Get(HeaderTimestamp), []byte("raw")), req.r.Header.Get(HeaderSignature))
+}
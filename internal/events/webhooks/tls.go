@@ -0,0 +1,176 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffresty"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// tlsFiles is a named TLS configuration of the plugin. Subscriptions can only use files through one of these,
+// so the paths are chosen by the operator of the node rather than by users of the API.
+type tlsFiles struct {
+	certFile string
+	keyFile  string
+	caFile   string
+}
+
+// tlsClient is a client built from a set of TLS options, along with the version of the files it was built from
+type tlsClient struct {
+	client  *resty.Client
+	version string
+}
+
+func loadTLSConfigs(ctx context.Context, tlsConf config.ArraySection) (map[string]*tlsFiles, error) {
+	configs := make(map[string]*tlsFiles)
+	for i := 0; i < tlsConf.ArraySize(); i++ {
+		entry := tlsConf.ArrayEntry(i)
+		name := entry.GetString(TLSConfigName)
+		files := &tlsFiles{
+			certFile: entry.GetString(TLSConfigCertFile),
+			keyFile:  entry.GetString(TLSConfigKeyFile),
+			caFile:   entry.GetString(TLSConfigCAFile),
+		}
+		if name == "" || (files.certFile == "") != (files.keyFile == "") {
+			return nil, i18n.NewError(ctx, coremsgs.MsgWebhookTLSCertKeyRequired, i)
+		}
+		configs[name] = files
+	}
+	return configs, nil
+}
+
+func (wh *WebHooks) parseTLSOptions(options fftypes.JSONObject) (*core.WebhookTLS, *tlsFiles, error) {
+	if options["tls"] == nil {
+		return nil, nil, nil
+	}
+	// Unknown fields are rejected, rather than ignored, so that paths to files cannot be set on a subscription
+	var tlsOptions core.WebhookTLS
+	b, _ := json.Marshal(options["tls"])
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&tlsOptions); err != nil {
+		return nil, nil, i18n.WrapError(wh.ctx, err, coremsgs.MsgWebhookTLSInvalidOptions)
+	}
+	files := &tlsFiles{}
+	if tlsOptions.ConfigRef != "" {
+		var ok bool
+		if files, ok = wh.tlsConfigs[tlsOptions.ConfigRef]; !ok {
+			return nil, nil, i18n.NewError(wh.ctx, coremsgs.MsgWebhookTLSUnknownConfigRef, tlsOptions.ConfigRef)
+		}
+	}
+	if files.caFile != "" && tlsOptions.CA != "" {
+		return nil, nil, i18n.NewError(wh.ctx, coremsgs.MsgWebhookTLSCAConflict, tlsOptions.ConfigRef)
+	}
+	return &tlsOptions, files, nil
+}
+
+// version identifies the current content of the files, by their size and modification time. It is checked each time
+// a client is used, so that rotated certificates are picked up without restarting the node.
+func (files *tlsFiles) version() string {
+	var version string
+	for _, file := range []string{files.certFile, files.keyFile, files.caFile} {
+		if file == "" {
+			continue
+		}
+		if info, err := os.Stat(file); err == nil {
+			version += fmt.Sprintf("%d:%d;", info.Size(), info.ModTime().UnixNano())
+		} else {
+			version += "missing;"
+		}
+	}
+	return version
+}
+
+// getClient returns the client to use for a subscription. Subscriptions with their own TLS options
+// share a client (and its connection pool) with any other subscription that has identical options.
+// The client is rebuilt when the files of its TLS configuration change.
+func (wh *WebHooks) getClient(options fftypes.JSONObject) (*resty.Client, error) {
+	tlsOptions, files, err := wh.parseTLSOptions(options)
+	if err != nil || tlsOptions == nil {
+		return wh.client, err
+	}
+	b, _ := json.Marshal(tlsOptions)
+	key := string(b)
+	version := files.version()
+
+	wh.tlsClientsMux.Lock()
+	defer wh.tlsClientsMux.Unlock()
+	existing, ok := wh.tlsClients[key]
+	if ok && existing.version == version {
+		return existing.client, nil
+	}
+	tlsConfig, err := wh.buildTLSConfig(tlsOptions, files)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		log.L(wh.ctx).Infof("Reloaded webhook TLS configuration '%s' after its files changed", tlsOptions.ConfigRef)
+		existing.client.GetClient().CloseIdleConnections()
+	}
+	client := ffresty.New(wh.ctx, wh.conf)
+	client.SetTLSClientConfig(tlsConfig)
+	wh.tlsClients[key] = &tlsClient{client: client, version: version}
+	return client, nil
+}
+
+func (wh *WebHooks) buildTLSConfig(tlsOptions *core.WebhookTLS, files *tlsFiles) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: tlsOptions.ServerName,
+	}
+
+	if files.certFile != "" {
+		cert, err := tls.LoadX509KeyPair(files.certFile, files.keyFile)
+		if err != nil {
+			return nil, i18n.WrapError(wh.ctx, err, coremsgs.MsgWebhookTLSLoadCertFailed, files.certFile)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	var caPEM []byte
+	switch {
+	case files.caFile != "":
+		var err error
+		if caPEM, err = os.ReadFile(files.caFile); err != nil {
+			return nil, i18n.WrapError(wh.ctx, err, coremsgs.MsgWebhookTLSLoadCAFailed, files.caFile)
+		}
+	case tlsOptions.CA != "":
+		caPEM = []byte(tlsOptions.CA)
+	}
+	if caPEM != nil {
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, i18n.NewError(wh.ctx, coremsgs.MsgWebhookTLSInvalidCA)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,302 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testPKI struct {
+	caFile     string
+	caPEM      string
+	certFile   string
+	keyFile    string
+	serverCert tls.Certificate
+	caPool     *x509.CertPool
+}
+
+func newTestCert(t *testing.T, template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, []byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	return cert, key,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// newTestPKI creates a private CA, with a server certificate for 127.0.0.1 and a client certificate
+func newTestPKI(t *testing.T) *testPKI {
+	notAfter := time.Now().Add(time.Hour)
+	ca, caKey, caPEM, _ := newTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil, nil)
+	_, _, serverPEM, serverKeyPEM := newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "server"},
+		NotAfter:     notAfter,
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:     []string{"webhooks.example.com"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca, caKey)
+	_, _, clientPEM, clientKeyPEM := newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, caKey)
+
+	dir := t.TempDir()
+	pki := &testPKI{
+		caFile:   filepath.Join(dir, "ca.pem"),
+		caPEM:    string(caPEM),
+		certFile: filepath.Join(dir, "client.pem"),
+		keyFile:  filepath.Join(dir, "client.key"),
+		caPool:   x509.NewCertPool(),
+	}
+	pki.caPool.AddCert(ca)
+	assert.NoError(t, os.WriteFile(pki.caFile, caPEM, 0600))
+	assert.NoError(t, os.WriteFile(pki.certFile, clientPEM, 0600))
+	assert.NoError(t, os.WriteFile(pki.keyFile, clientKeyPEM, 0600))
+	var err error
+	pki.serverCert, err = tls.X509KeyPair(serverPEM, serverKeyPEM)
+	assert.NoError(t, err)
+	return pki
+}
+
+// newTestTLSWebHooks configures named TLS configurations for the files of the test PKI
+func newTestTLSWebHooks(t *testing.T, pki *testPKI) (wh *WebHooks, cancel func()) {
+	coreconfig.Reset()
+
+	cbs := &eventsmocks.Callbacks{}
+	cbs.On("RegisterConnection", mock.Anything, mock.Anything).Return(nil)
+	wh = &WebHooks{}
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	svrConfig := config.RootSection("ut.webhooks")
+	wh.InitConfig(svrConfig)
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(fmt.Sprintf(`
+ut:
+  webhooks:
+    tlsConfigs:
+    - name: mtls
+      certFile: %[1]s
+      keyFile: %[2]s
+    - name: private-ca
+      caFile: %[3]s
+    - name: missing-cert
+      certFile: %[4]s
+      keyFile: %[2]s
+    - name: missing-ca
+      caFile: %[4]s
+`, pki.certFile, pki.keyFile, pki.caFile, filepath.Join(filepath.Dir(pki.caFile), "missing.pem"))))
+	assert.NoError(t, err)
+	err = wh.Init(ctx, svrConfig)
+	assert.NoError(t, err)
+	wh.SetHandler("ns1", cbs)
+	return wh, cancelCtx
+}
+
+func TestInitBadTLSConfig(t *testing.T) {
+	coreconfig.Reset()
+	wh := &WebHooks{}
+	svrConfig := config.RootSection("ut.webhooks")
+	wh.InitConfig(svrConfig)
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+ut:
+  webhooks:
+    tlsConfigs:
+    - name: mtls
+      certFile: client.pem
+`))
+	assert.NoError(t, err)
+	err = wh.Init(context.Background(), svrConfig)
+	assert.Regexp(t, "FF10469.*0", err)
+}
+
+func TestValidateOptionsTLSErrors(t *testing.T) {
+	pki := newTestPKI(t)
+	wh, cancel := newTestTLSWebHooks(t, pki)
+	defer cancel()
+
+	for errMsg, tlsOptions := range map[string]interface{}{
+		"FF10468":           "not an object",
+		"FF10468.*certFile": fftypes.JSONObject{"certFile": pki.certFile},
+		"FF10527.*unknown":  fftypes.JSONObject{"configRef": "unknown"},
+		"FF10470":           fftypes.JSONObject{"configRef": "missing-cert"},
+		"FF10471":           fftypes.JSONObject{"configRef": "missing-ca"},
+		"FF10472":           fftypes.JSONObject{"ca": "not a cert"},
+		"FF10473":           fftypes.JSONObject{"configRef": "private-ca", "ca": pki.caPEM},
+	} {
+		opts := &core.SubscriptionOptions{}
+		opts.TransportOptions()["url"] = "https://localhost/anything"
+		opts.TransportOptions()["tls"] = tlsOptions
+		err := wh.ValidateOptions(opts)
+		assert.Regexp(t, errMsg, err)
+	}
+	assert.Empty(t, wh.tlsClients)
+}
+
+func TestGetClientCached(t *testing.T) {
+	pki := newTestPKI(t)
+	wh, cancel := newTestTLSWebHooks(t, pki)
+	defer cancel()
+
+	client, err := wh.getClient(fftypes.JSONObject{})
+	assert.NoError(t, err)
+	assert.Equal(t, wh.client, client)
+
+	client1, err := wh.getClient(fftypes.JSONObject{"tls": fftypes.JSONObject{"configRef": "private-ca"}})
+	assert.NoError(t, err)
+	assert.NotEqual(t, wh.client, client1)
+	client2, err := wh.getClient(fftypes.JSONObject{"tls": fftypes.JSONObject{"configRef": "private-ca"}})
+	assert.NoError(t, err)
+	assert.Same(t, client1, client2)
+	client3, err := wh.getClient(fftypes.JSONObject{"tls": fftypes.JSONObject{"ca": pki.caPEM}})
+	assert.NoError(t, err)
+	assert.NotSame(t, client1, client3)
+	assert.Len(t, wh.tlsClients, 2)
+}
+
+func TestGetClientReloadsRotatedFiles(t *testing.T) {
+	pki := newTestPKI(t)
+	wh, cancel := newTestTLSWebHooks(t, pki)
+	defer cancel()
+	options := fftypes.JSONObject{"tls": fftypes.JSONObject{"configRef": "mtls"}}
+
+	client1, err := wh.getClient(options)
+	assert.NoError(t, err)
+
+	// Rotate the client certificate, by replacing the files with the ones from a new PKI
+	rotated := newTestPKI(t)
+	for src, dst := range map[string]string{rotated.certFile: pki.certFile, rotated.keyFile: pki.keyFile} {
+		b, err := os.ReadFile(src)
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(dst, b, 0600))
+		future := time.Now().Add(time.Minute)
+		assert.NoError(t, os.Chtimes(dst, future, future))
+	}
+	client2, err := wh.getClient(options)
+	assert.NoError(t, err)
+	assert.NotSame(t, client1, client2)
+	assert.Len(t, wh.tlsClients, 1)
+
+	// A rotation that leaves the files unusable fails, until they are fixed
+	assert.NoError(t, os.Remove(pki.keyFile))
+	_, err = wh.getClient(options)
+	assert.Regexp(t, "FF10470", err)
+}
+
+func TestDeliveryRequestMutualTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	wh, cancel := newTestTLSWebHooks(t, pki)
+	defer cancel()
+
+	var clientCN string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		clientCN = req.TLS.PeerCertificates[0].Subject.CommonName
+		res.WriteHeader(200)
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{pki.serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pki.caPool,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	retry := &core.SubscriptionRetry{MaxAttempts: 3}
+	newSub := func(tlsOptions fftypes.JSONObject) *core.Subscription {
+		sub := &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{Namespace: "ns1"},
+		}
+		sub.Options.Retry = retry
+		sub.Options.TransportOptions()["url"] = fmt.Sprintf("https://%s/myapi", server.Listener.Addr())
+		if tlsOptions != nil {
+			sub.Options.TransportOptions()["tls"] = tlsOptions
+		}
+		assert.NoError(t, wh.ValidateOptions(&sub.Options))
+		return sub
+	}
+
+	mcb := wh.callbacks["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return !response.Rejected
+	})).Return(nil).Once()
+
+	// Presents the client certificate, and trusts the private CA
+	err := wh.DeliveryRequest(mock.Anything, newSub(fftypes.JSONObject{
+		"configRef":  "mtls",
+		"ca":         pki.caPEM,
+		"serverName": "webhooks.example.com",
+	}), &core.EventDelivery{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: fftypes.NewUUID()}}}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "client", clientCN)
+	mcb.AssertExpectations(t)
+
+	// Subscriptions without TLS options do not trust the private CA, and the server
+	// rejects connections without a client certificate
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return response.Rejected
+	})).Return(nil).Twice()
+	err = wh.DeliveryRequest(mock.Anything, newSub(nil), &core.EventDelivery{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: fftypes.NewUUID()}}}, nil)
+	assert.NoError(t, err)
+	err = wh.DeliveryRequest(mock.Anything, newSub(fftypes.JSONObject{
+		"configRef": "private-ca",
+	}), &core.EventDelivery{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: fftypes.NewUUID()}}}, nil)
+	assert.NoError(t, err)
+	mcb.AssertExpectations(t)
+}
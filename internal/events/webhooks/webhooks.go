@@ -25,6 +25,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/hyperledger/firefly-common/pkg/config"
@@ -38,11 +39,18 @@ import (
 )
 
 type WebHooks struct {
-	ctx          context.Context
-	capabilities *events.Capabilities
-	callbacks    map[string]events.Callbacks
-	client       *resty.Client
-	connID       string
+	ctx           context.Context
+	capabilities  *events.Capabilities
+	callbacks     map[string]events.Callbacks
+	conf          config.Section
+	client        *resty.Client
+	tlsClients    map[string]*tlsClient
+	tlsClientsMux sync.Mutex
+	tlsConfigs    map[string]*tlsFiles
+	signer        *whSigner
+	secretsConf   config.ArraySection
+	tlsConf       config.ArraySection
+	connID        string
 }
 
 type whRequest struct {
@@ -54,6 +62,11 @@ type whRequest struct {
 	replyTx   string
 }
 
+type whBatchEntry struct {
+	*core.EventDelivery
+	Data core.DataArray `json:"data,omitempty"`
+}
+
 type whResponse struct {
 	Status  int                `json:"status"`
 	Headers fftypes.JSONObject `json:"headers"`
@@ -63,13 +76,30 @@ type whResponse struct {
 func (wh *WebHooks) Name() string { return "webhooks" }
 
 func (wh *WebHooks) Init(ctx context.Context, config config.Section) (err error) {
+	signer, err := newSigner(ctx, config.SubSection(SigningConfig), wh.secretsConf)
+	if err != nil {
+		return err
+	}
+	tlsConfigs, err := loadTLSConfigs(ctx, wh.tlsConf)
+	if err != nil {
+		return err
+	}
 	connID := fftypes.ShortID()
 	*wh = WebHooks{
-		ctx:          log.WithLogField(ctx, "webhook", wh.connID),
-		capabilities: &events.Capabilities{},
-		callbacks:    make(map[string]events.Callbacks),
-		client:       ffresty.New(ctx, config),
-		connID:       connID,
+		ctx: log.WithLogField(ctx, "webhook", wh.connID),
+		capabilities: &events.Capabilities{
+			BatchDelivery: true,
+			Transform:     true,
+		},
+		callbacks:   make(map[string]events.Callbacks),
+		conf:        config,
+		client:      ffresty.New(ctx, config),
+		tlsClients:  make(map[string]*tlsClient),
+		tlsConfigs:  tlsConfigs,
+		signer:      signer,
+		secretsConf: wh.secretsConf,
+		tlsConf:     wh.tlsConf,
+		connID:      connID,
 	}
 	return nil
 }
@@ -85,8 +115,12 @@ func (wh *WebHooks) Capabilities() *events.Capabilities {
 }
 
 func (wh *WebHooks) buildRequest(options fftypes.JSONObject, firstData fftypes.JSONObject) (req *whRequest, err error) {
+	client, err := wh.getClient(options)
+	if err != nil {
+		return nil, err
+	}
 	req = &whRequest{
-		r: wh.client.R().
+		r: client.R().
 			SetDoNotParseResponse(true).
 			SetContext(wh.ctx),
 		url:       options.GetString("url"),
@@ -175,11 +209,69 @@ func (wh *WebHooks) ValidateOptions(options *core.SubscriptionOptions) error {
 		defaultTrue := true
 		options.WithData = &defaultTrue
 	}
+	if options.Batch != nil && *options.Batch && options.TransportOptions().GetBool("reply") {
+		return i18n.NewError(wh.ctx, coremsgs.MsgBatchWithReplyNotSupported)
+	}
+	if err := wh.validateSigningOptions(options.TransportOptions()); err != nil {
+		return err
+	}
 	_, err := wh.buildRequest(options.TransportOptions(), fftypes.JSONObject{})
 	return err
 }
 
 func (wh *WebHooks) attemptRequest(sub *core.Subscription, event *core.EventDelivery, data core.DataArray) (req *whRequest, res *whResponse, err error) {
+	req, err = wh.buildEventRequest(sub, event, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.L(wh.ctx).Debugf("Webhook-> %s %s event %s on subscription %s", req.method, req.url, event.ID, sub.ID)
+	res, err = wh.executeRequest(req)
+	if err != nil {
+		log.L(wh.ctx).Errorf("Webhook<- %s %s event %s on subscription %s failed: %s", req.method, req.url, event.ID, sub.ID, err)
+		return nil, nil, err
+	}
+	log.L(wh.ctx).Infof("Webhook<- %s %s event %s on subscription %s returned %d", req.method, req.url, event.ID, sub.ID, res.Status)
+	return req, res, nil
+}
+
+func (wh *WebHooks) attemptBatchRequest(sub *core.Subscription, batchID *fftypes.UUID, events []*core.CombinedEventDataDelivery) (res *whResponse, err error) {
+	// Batches are always sent as a JSON array, with the data of each event in-line when withData is set.
+	// As such the options to extract the query, headers, body and path from the first data item do not apply.
+	req, err := wh.buildRequest(sub.Options.TransportOptions(), nil)
+	if err != nil {
+		return nil, err
+	}
+	withData := sub.Options.WithData != nil && *sub.Options.WithData
+	body := make([]interface{}, len(events))
+	for i, e := range events {
+		if e.Event.Transformed != nil {
+			body[i] = e.Event.Transformed
+			continue
+		}
+		entry := &whBatchEntry{EventDelivery: e.Event}
+		if withData {
+			entry.Data = e.Data
+		}
+		body[i] = entry
+	}
+	if req.method == http.MethodPost || req.method == http.MethodPatch || req.method == http.MethodPut {
+		req.r.SetBody(body)
+	}
+	if err := wh.signRequest(req, sub.Options.TransportOptions(), batchID); err != nil {
+		return nil, err
+	}
+
+	log.L(wh.ctx).Debugf("Webhook-> %s %s batch %s (%d events) on subscription %s", req.method, req.url, batchID, len(events), sub.ID)
+	res, err = wh.executeRequest(req)
+	if err != nil {
+		log.L(wh.ctx).Errorf("Webhook<- %s %s batch %s on subscription %s failed: %s", req.method, req.url, batchID, sub.ID, err)
+		return nil, err
+	}
+	log.L(wh.ctx).Infof("Webhook<- %s %s batch %s on subscription %s returned %d", req.method, req.url, batchID, sub.ID, res.Status)
+	return res, nil
+}
+
+func (wh *WebHooks) buildEventRequest(sub *core.Subscription, event *core.EventDelivery, data core.DataArray) (req *whRequest, err error) {
 	withData := sub.Options.WithData != nil && *sub.Options.WithData
 	allData := make([]*fftypes.JSONAny, 0, len(data))
 	var firstData fftypes.JSONObject
@@ -206,11 +298,14 @@ func (wh *WebHooks) attemptRequest(sub *core.Subscription, event *core.EventDeli
 
 	req, err = wh.buildRequest(sub.Options.TransportOptions(), firstData)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if req.method == http.MethodPost || req.method == http.MethodPatch || req.method == http.MethodPut {
 		switch {
+		case event.Transformed != nil:
+			// The subscription has a transform, which determines the whole body
+			req.r.SetBody(event.Transformed.Bytes())
 		case req.body != nil:
 			// We might have been told to extract a body from the first data record
 			req.r.SetBody(req.body)
@@ -226,12 +321,16 @@ func (wh *WebHooks) attemptRequest(sub *core.Subscription, event *core.EventDeli
 
 		}
 	}
+	if err := wh.signRequest(req, sub.Options.TransportOptions(), event.ID); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
 
-	log.L(wh.ctx).Debugf("Webhook-> %s %s event %s on subscription %s", req.method, req.url, event.ID, sub.ID)
+func (wh *WebHooks) executeRequest(req *whRequest) (res *whResponse, err error) {
 	resp, err := req.r.Execute(req.method, req.url)
 	if err != nil {
-		log.L(wh.ctx).Errorf("Webhook<- %s %s event %s on subscription %s failed: %s", req.method, req.url, event.ID, sub.ID, err)
-		return nil, nil, err
+		return nil, err
 	}
 	defer func() { _ = resp.RawBody().Close() }()
 
@@ -239,7 +338,6 @@ func (wh *WebHooks) attemptRequest(sub *core.Subscription, event *core.EventDeli
 		Status:  resp.StatusCode(),
 		Headers: fftypes.JSONObject{},
 	}
-	log.L(wh.ctx).Infof("Webhook<- %s %s event %s on subscription %s returned %d", req.method, req.url, event.ID, sub.ID, res.Status)
 	header := resp.Header()
 	for h := range header {
 		res.Headers[h] = header.Get(h)
@@ -254,7 +352,7 @@ func (wh *WebHooks) attemptRequest(sub *core.Subscription, event *core.EventDeli
 		var resData interface{}
 		err = json.NewDecoder(resp.RawBody()).Decode(&resData)
 		if err != nil {
-			return nil, nil, i18n.WrapError(wh.ctx, err, coremsgs.MsgWebhooksReplyBadJSON)
+			return nil, i18n.WrapError(wh.ctx, err, coremsgs.MsgWebhooksReplyBadJSON)
 		}
 		b, _ := json.Marshal(&resData) // we know we can re-marshal It
 		res.Body = fftypes.JSONAnyPtrBytes(b)
@@ -269,7 +367,7 @@ func (wh *WebHooks) attemptRequest(sub *core.Subscription, event *core.EventDeli
 		res.Body = fftypes.JSONAnyPtrBytes(buf.Bytes())
 	}
 
-	return req, res, nil
+	return res, nil
 }
 
 func (wh *WebHooks) doDelivery(connID string, reply bool, sub *core.Subscription, event *core.EventDelivery, data core.DataArray, fastAck bool) {
@@ -322,16 +420,35 @@ func (wh *WebHooks) doDelivery(connID string, reply bool, sub *core.Subscription
 			})
 		}
 	} else if !fastAck {
+		rejected, info := wh.checkDeliveryFailure(sub, res, gwErr)
 		if cb, ok := wh.callbacks[sub.Namespace]; ok {
 			cb.DeliveryResponse(connID, &core.EventDeliveryResponse{
 				ID:           event.ID,
-				Rejected:     false,
+				Rejected:     rejected,
+				Info:         info,
 				Subscription: event.Subscription,
 			})
 		}
 	}
 }
 
+// checkDeliveryFailure determines whether a failed request should reject the delivery.
+// This only happens for subscriptions with a retry policy, so that the failed events are retried
+// and eventually dead-lettered. Otherwise events are acknowledged regardless of the outcome.
+func (wh *WebHooks) checkDeliveryFailure(sub *core.Subscription, res *whResponse, gwErr error) (rejected bool, info string) {
+	if sub.Options.Retry == nil || sub.Options.Retry.MaxAttempts == 0 {
+		return false, ""
+	}
+	switch {
+	case gwErr != nil:
+		return true, gwErr.Error()
+	case res.Status < 200 || res.Status >= 300:
+		return true, i18n.NewError(wh.ctx, coremsgs.MsgWebhookFailedStatus, res.Status).Error()
+	default:
+		return false, ""
+	}
+}
+
 func (wh *WebHooks) DeliveryRequest(connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
 	reply := sub.Options.TransportOptions().GetBool("reply")
 	if reply && event.Message != nil && event.Message.Header.CID != nil {
@@ -367,3 +484,43 @@ func (wh *WebHooks) DeliveryRequest(connID string, sub *core.Subscription, event
 	wh.doDelivery(connID, reply, sub, event, data, false)
 	return nil
 }
+
+func (wh *WebHooks) doBatchDelivery(connID string, sub *core.Subscription, batchID *fftypes.UUID, events []*core.CombinedEventDataDelivery, fastAck bool) {
+	res, gwErr := wh.attemptBatchRequest(sub, batchID, events)
+	if gwErr != nil {
+		// As with individual events, we log the failure but the batch is still acknowledged
+		// unless the subscription has a retry policy
+		log.L(wh.ctx).Errorf("Failed to invoke webhook for batch %s: %s", batchID, gwErr)
+	} else {
+		log.L(wh.ctx).Tracef("Webhook batch response status: %d", res.Status)
+	}
+	if !fastAck {
+		rejected, info := wh.checkDeliveryFailure(sub, res, gwErr)
+		if cb, ok := wh.callbacks[sub.Namespace]; ok {
+			cb.DeliveryResponse(connID, &core.EventDeliveryResponse{
+				ID:           batchID,
+				Rejected:     rejected,
+				Info:         info,
+				Subscription: sub.SubscriptionRef,
+			})
+		}
+	}
+}
+
+func (wh *WebHooks) BatchDeliveryRequest(connID string, sub *core.Subscription, batchID *fftypes.UUID, events []*core.CombinedEventDataDelivery) error {
+	// In fastack mode we acknowledge the whole batch immediately, and invoke the webhook in the background
+	if sub.Options.TransportOptions().GetBool("fastack") {
+		if cb, ok := wh.callbacks[sub.Namespace]; ok {
+			cb.DeliveryResponse(connID, &core.EventDeliveryResponse{
+				ID:           batchID,
+				Rejected:     false,
+				Subscription: sub.SubscriptionRef,
+			})
+		}
+		go wh.doBatchDelivery(connID, sub, batchID, events, true)
+		return nil
+	}
+
+	wh.doBatchDelivery(connID, sub, batchID, events, false)
+	return nil
+}
@@ -119,6 +119,22 @@ func TestValidateOptionsBadQuery(t *testing.T) {
 	assert.Regexp(t, "FF10243.*query", err)
 }
 
+func TestValidateOptionsBatchWithReply(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	yes := true
+	opts := &core.SubscriptionOptions{
+		SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+			Batch: &yes,
+		},
+	}
+	opts.TransportOptions()["url"] = "/anything"
+	opts.TransportOptions()["reply"] = true
+	err := wh.ValidateOptions(opts)
+	assert.Regexp(t, "FF10447", err)
+}
+
 func TestRequestWithBodyReplyEndToEnd(t *testing.T) {
 	wh, cancel := newTestWebHooks(t)
 	defer cancel()
@@ -855,3 +871,368 @@ func TestDeliveryRequestReplyToReply(t *testing.T) {
 
 	mcb.AssertExpectations(t)
 }
+
+func TestBatchDeliveryRequestWithData(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+	assert.True(t, wh.Capabilities().BatchDelivery)
+
+	ev1 := fftypes.NewUUID()
+	ev2 := fftypes.NewUUID()
+	batchID := fftypes.NewUUID()
+
+	called := false
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		var body []fftypes.JSONObject
+		err := json.NewDecoder(req.Body).Decode(&body)
+		assert.NoError(t, err)
+		assert.Len(t, body, 2)
+		assert.Equal(t, ev1.String(), body[0].GetString("id"))
+		assert.Equal(t, "value1", body[0].GetObjectArray("data")[0].GetString("value"))
+		assert.Equal(t, ev2.String(), body[1].GetString("id"))
+		assert.Nil(t, body[1]["data"])
+		res.WriteHeader(200)
+		called = true
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	yes := true
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+		},
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				WithData: &yes,
+				Batch:    &yes,
+			},
+		},
+	}
+	sub.Options.TransportOptions()["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+
+	mcb := wh.callbacks["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return *response.ID == *batchID && !response.Rejected && *response.Subscription.ID == *sub.ID
+	})).Return(nil).Once()
+
+	err := wh.BatchDeliveryRequest(mock.Anything, sub, batchID, []*core.CombinedEventDataDelivery{
+		{
+			Event: &core.EventDelivery{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: ev1}}},
+			Data: core.DataArray{
+				{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"value1"`)},
+			},
+		},
+		{
+			Event: &core.EventDelivery{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: ev2}}},
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, called)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestBatchDeliveryRequestTransformed(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+	assert.True(t, wh.Capabilities().Transform)
+
+	ev1 := fftypes.NewUUID()
+	ev2 := fftypes.NewUUID()
+	batchID := fftypes.NewUUID()
+
+	called := false
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		var body []fftypes.JSONObject
+		err := json.NewDecoder(req.Body).Decode(&body)
+		assert.NoError(t, err)
+		assert.Equal(t, []fftypes.JSONObject{
+			{"event": ev1.String()},
+			{"event": ev2.String()},
+		}, body)
+		res.WriteHeader(200)
+		called = true
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	yes := true
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+		},
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				Batch: &yes,
+			},
+		},
+	}
+	sub.Options.TransportOptions()["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+
+	mcb := wh.callbacks["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return *response.ID == *batchID && !response.Rejected
+	})).Return(nil).Once()
+
+	err := wh.BatchDeliveryRequest(mock.Anything, sub, batchID, []*core.CombinedEventDataDelivery{
+		{
+			Event: &core.EventDelivery{
+				EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: ev1}},
+				Transformed:   fftypes.JSONAnyPtr(`{"event":"` + ev1.String() + `"}`),
+			},
+		},
+		{
+			Event: &core.EventDelivery{
+				EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: ev2}},
+				Transformed:   fftypes.JSONAnyPtr(`{"event":"` + ev2.String() + `"}`),
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, called)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestDeliveryRequestTransformed(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	ev1 := fftypes.NewUUID()
+	called := false
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		var body fftypes.JSONObject
+		err := json.NewDecoder(req.Body).Decode(&body)
+		assert.NoError(t, err)
+		assert.Equal(t, fftypes.JSONObject{"event": ev1.String()}, body)
+		res.WriteHeader(200)
+		called = true
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	yes := true
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+		},
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				WithData: &yes,
+			},
+		},
+	}
+	sub.Options.TransportOptions()["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+
+	mcb := wh.callbacks["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return *response.ID == *ev1 && !response.Rejected
+	})).Return(nil).Once()
+
+	err := wh.DeliveryRequest(mock.Anything, sub, &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: ev1}},
+		Transformed:   fftypes.JSONAnyPtr(`{"event":"` + ev1.String() + `"}`),
+	}, core.DataArray{
+		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`{"ignored":true}`)},
+	})
+	assert.NoError(t, err)
+	assert.True(t, called)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestBatchDeliveryRequestFastAckFail(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	r := mux.NewRouter()
+	server := httptest.NewServer(r)
+	server.Close()
+
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			Namespace: "ns1",
+		},
+	}
+	sub.Options.TransportOptions()["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	sub.Options.TransportOptions()["fastack"] = true
+
+	batchID := fftypes.NewUUID()
+	acked := make(chan struct{})
+	mcb := wh.callbacks["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return *response.ID == *batchID
+	})).Return(nil).Run(func(a mock.Arguments) {
+		close(acked)
+	})
+
+	err := wh.BatchDeliveryRequest(mock.Anything, sub, batchID, []*core.CombinedEventDataDelivery{
+		{Event: &core.EventDelivery{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: fftypes.NewUUID()}}}},
+	})
+	assert.NoError(t, err)
+
+	<-acked
+	mcb.AssertExpectations(t)
+}
+
+func TestBatchDeliveryRequestBadOptions(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			Namespace: "ns1",
+		},
+	}
+
+	batchID := fftypes.NewUUID()
+	mcb := wh.callbacks["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return *response.ID == *batchID
+	})).Return(nil)
+
+	err := wh.BatchDeliveryRequest(mock.Anything, sub, batchID, []*core.CombinedEventDataDelivery{})
+	assert.NoError(t, err)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestDeliveryRequestRetryPolicyRejectsBadStatus(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(500)
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+		},
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				Retry: &core.SubscriptionRetry{MaxAttempts: 3},
+			},
+		},
+	}
+	sub.Options.TransportOptions()["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID: fftypes.NewUUID(),
+			},
+		},
+		Subscription: sub.SubscriptionRef,
+	}
+
+	mcb := wh.callbacks["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return *response.ID == *event.ID && response.Rejected && response.Info == "FF10449: Webhook request failed with status 500"
+	})).Return(nil)
+
+	err := wh.DeliveryRequest(mock.Anything, sub, event, nil)
+	assert.NoError(t, err)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestDeliveryRequestRetryPolicyRejectsGatewayError(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	r := mux.NewRouter()
+	server := httptest.NewServer(r)
+	server.Close()
+
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+		},
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				Retry: &core.SubscriptionRetry{MaxAttempts: 3},
+			},
+		},
+	}
+	sub.Options.TransportOptions()["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID: fftypes.NewUUID(),
+			},
+		},
+		Subscription: sub.SubscriptionRef,
+	}
+
+	mcb := wh.callbacks["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return *response.ID == *event.ID && response.Rejected && response.Info != ""
+	})).Return(nil)
+
+	err := wh.DeliveryRequest(mock.Anything, sub, event, nil)
+	assert.NoError(t, err)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestBatchDeliveryRequestRetryPolicy(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	status := 503
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(status)
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	yes := true
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+		},
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				Batch: &yes,
+				Retry: &core.SubscriptionRetry{MaxAttempts: 3},
+			},
+		},
+	}
+	sub.Options.TransportOptions()["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	events := []*core.CombinedEventDataDelivery{
+		{Event: &core.EventDelivery{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: fftypes.NewUUID()}}}},
+	}
+
+	batch1 := fftypes.NewUUID()
+	batch2 := fftypes.NewUUID()
+	mcb := wh.callbacks["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return *response.ID == *batch1 && response.Rejected
+	})).Return(nil).Once()
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return *response.ID == *batch2 && !response.Rejected
+	})).Return(nil).Once()
+
+	err := wh.BatchDeliveryRequest(mock.Anything, sub, batch1, events)
+	assert.NoError(t, err)
+
+	status = 204
+	err = wh.BatchDeliveryRequest(mock.Anything, sub, batch2, events)
+	assert.NoError(t, err)
+
+	mcb.AssertExpectations(t)
+}
@@ -183,11 +183,21 @@ func (wc *websocketConnection) receiveLoop() {
 }
 
 func (wc *websocketConnection) dispatch(event *core.EventDelivery) error {
-	inflight := &core.EventDeliveryResponse{
+	return wc.dispatchInflight(&core.EventDeliveryResponse{
 		ID:           event.ID,
 		Subscription: event.Subscription,
-	}
+	}, event)
+}
+
+func (wc *websocketConnection) dispatchBatch(batch *core.WSEventBatch) error {
+	// The batch is acknowledged as a whole, using the ID of the batch
+	return wc.dispatchInflight(&core.EventDeliveryResponse{
+		ID:           batch.ID,
+		Subscription: batch.Subscription,
+	}, batch)
+}
 
+func (wc *websocketConnection) dispatchInflight(inflight *core.EventDeliveryResponse, msg interface{}) error {
 	var autoAck bool
 	wc.mux.Lock()
 	autoAck = wc.autoAck
@@ -196,7 +206,7 @@ func (wc *websocketConnection) dispatch(event *core.EventDelivery) error {
 	}
 	wc.mux.Unlock()
 
-	err := wc.send(event)
+	err := wc.send(msg)
 	if err != nil {
 		return err
 	}
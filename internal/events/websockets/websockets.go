@@ -23,6 +23,7 @@ import (
 
 	"github.com/gorilla/websocket"
 	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly/internal/coremsgs"
@@ -44,10 +45,12 @@ func (ws *WebSockets) Name() string { return "websockets" }
 
 func (ws *WebSockets) Init(ctx context.Context, config config.Section) error {
 	*ws = WebSockets{
-		ctx:          ctx,
-		connections:  make(map[string]*websocketConnection),
-		capabilities: &events.Capabilities{},
-		callbacks:    make(map[string]events.Callbacks),
+		ctx:         ctx,
+		connections: make(map[string]*websocketConnection),
+		capabilities: &events.Capabilities{
+			BatchDelivery: true,
+		},
+		callbacks: make(map[string]events.Callbacks),
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  int(config.GetByteSize(ReadBufferSize)),
 			WriteBufferSize: int(config.GetByteSize(WriteBufferSize)),
@@ -93,6 +96,25 @@ func (ws *WebSockets) DeliveryRequest(connID string, sub *core.Subscription, eve
 	return conn.dispatch(event)
 }
 
+func (ws *WebSockets) BatchDeliveryRequest(connID string, sub *core.Subscription, batchID *fftypes.UUID, events []*core.CombinedEventDataDelivery) error {
+	ws.connMux.Lock()
+	conn, ok := ws.connections[connID]
+	ws.connMux.Unlock()
+	if !ok {
+		return i18n.NewError(ws.ctx, coremsgs.MsgWSConnectionNotActive, connID)
+	}
+	batch := &core.WSEventBatch{
+		Type:         core.WSEventBatchType,
+		ID:           batchID,
+		Subscription: sub.SubscriptionRef,
+		Events:       make([]*core.EventDelivery, len(events)),
+	}
+	for i, e := range events {
+		batch.Events[i] = e.Event
+	}
+	return conn.dispatchBatch(batch)
+}
+
 func (ws *WebSockets) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 	wsConn, err := ws.upgrader.Upgrade(res, req, nil)
 	if err != nil {
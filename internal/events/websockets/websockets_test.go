@@ -210,6 +210,67 @@ func TestStartReceiveAckEphemeral(t *testing.T) {
 	cbs.AssertExpectations(t)
 }
 
+func TestStartReceiveAckBatchEphemeral(t *testing.T) {
+	log.SetLevel("trace")
+
+	cbs := &eventsmocks.Callbacks{}
+	ws, wsc, cancel := newTestWebsockets(t, cbs, nil)
+	defer cancel()
+	assert.True(t, ws.Capabilities().BatchDelivery)
+
+	batchID := fftypes.NewUUID()
+	var connID string
+	sub := cbs.On("EphemeralSubscription",
+		mock.MatchedBy(func(s string) bool { connID = s; return true }),
+		"ns1", mock.Anything, mock.MatchedBy(func(o *core.SubscriptionOptions) bool {
+			return o.Batch != nil && *o.Batch
+		})).Return(nil)
+	ack := cbs.On("DeliveryResponse",
+		mock.MatchedBy(func(s string) bool { return s == connID }),
+		mock.MatchedBy(func(r *core.EventDeliveryResponse) bool { return *r.ID == *batchID })).Return(nil)
+
+	waitSubscribed := make(chan struct{})
+	sub.RunFn = func(a mock.Arguments) {
+		close(waitSubscribed)
+	}
+
+	waitAcked := make(chan struct{})
+	ack.RunFn = func(a mock.Arguments) {
+		close(waitAcked)
+	}
+
+	err := wsc.Send(context.Background(), []byte(`{"type":"start","namespace":"ns1","ephemeral":true,"options":{"batch":true}}`))
+	assert.NoError(t, err)
+
+	<-waitSubscribed
+	subRef := core.SubscriptionRef{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+	ev1 := fftypes.NewUUID()
+	ev2 := fftypes.NewUUID()
+	err = ws.BatchDeliveryRequest(connID, &core.Subscription{SubscriptionRef: subRef}, batchID, []*core.CombinedEventDataDelivery{
+		{Event: &core.EventDelivery{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: ev1}}, Subscription: subRef}},
+		{Event: &core.EventDelivery{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: ev2}}, Subscription: subRef}},
+	})
+	assert.NoError(t, err)
+
+	b := <-wsc.Receive()
+	var res core.WSEventBatch
+	err = json.Unmarshal(b, &res)
+	assert.NoError(t, err)
+	assert.Equal(t, core.WSEventBatchType, res.Type)
+	assert.Equal(t, *batchID, *res.ID)
+	assert.Len(t, res.Events, 2)
+	assert.Equal(t, *ev2, *res.Events[1].ID)
+
+	err = wsc.Send(context.Background(), []byte(fmt.Sprintf(`{"type":"ack","id":"%s"}`, batchID)))
+	assert.NoError(t, err)
+
+	<-waitAcked
+	cbs.AssertExpectations(t)
+}
+
 func TestStartReceiveDurable(t *testing.T) {
 	cbs := &eventsmocks.Callbacks{}
 	ws, wsc, cancel := newTestWebsockets(t, cbs, nil)
@@ -634,6 +695,15 @@ func TestWebsocketDispatchAfterClose(t *testing.T) {
 	assert.Regexp(t, "FF10173", err)
 }
 
+func TestWebsocketBatchDispatchAfterClose(t *testing.T) {
+	ws := &WebSockets{
+		ctx:         context.Background(),
+		connections: make(map[string]*websocketConnection),
+	}
+	err := ws.BatchDeliveryRequest("gone", &core.Subscription{}, fftypes.NewUUID(), []*core.CombinedEventDataDelivery{})
+	assert.Regexp(t, "FF10173", err)
+}
+
 func TestDispatchAutoAck(t *testing.T) {
 	cbs := &eventsmocks.Callbacks{}
 	cbs.On("DeliveryResponse", mock.Anything, mock.Anything).Return(nil)
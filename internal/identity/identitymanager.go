@@ -420,6 +420,8 @@ func (im *identityManager) cachedIdentityLookupByVerifierRef(ctx context.Context
 	if cachedValue := im.identityCache.Get(cacheKey); cachedValue != nil {
 		return cachedValue.(*core.Identity), nil
 	}
+	// The identity is shared with other callers through the cache, so it must not be read from a lagging replica
+	ctx = database.WithPrimary(ctx)
 	verifier, err := im.database.GetVerifierByValue(ctx, verifierRef.Type, namespace, verifierRef.Value)
 	if err != nil {
 		return nil, err
@@ -458,6 +460,7 @@ func (im *identityManager) cachedIdentityLookup(ctx context.Context, namespace,
 	if cachedValue := im.identityCache.Get(cacheKey); cachedValue != nil {
 		identity = cachedValue.(*core.Identity)
 	} else {
+		ctx = database.WithPrimary(ctx)
 		if strings.HasPrefix(didLookupStr, core.DIDPrefix) {
 			if !strings.HasPrefix(didLookupStr, core.FireFlyDIDPrefix) {
 				return nil, false, i18n.NewError(ctx, coremsgs.MsgDIDResolverUnknown, didLookupStr)
@@ -515,7 +518,7 @@ func (im *identityManager) cachedIdentityLookupByID(ctx context.Context, namespa
 	if cachedValue := im.identityCache.Get(cacheKey); cachedValue != nil {
 		identity = cachedValue.(*core.Identity)
 	} else {
-		identity, err = im.database.GetIdentityByID(ctx, namespace, id)
+		identity, err = im.database.GetIdentityByID(database.WithPrimary(ctx), namespace, id)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,66 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var ContractListenerCheckpointGauge *prometheus.GaugeVec
+var ContractListenerHeadGauge *prometheus.GaugeVec
+var ContractListenerLagGauge *prometheus.GaugeVec
+var ContractListenerCatchupGauge *prometheus.GaugeVec
+
+// ContractListenerCheckpointGaugeName is the prometheus metric for tracking the block number of the checkpoint of each contract listener
+var ContractListenerCheckpointGaugeName = "ff_contract_listener_checkpoint_block"
+
+// ContractListenerHeadGaugeName is the prometheus metric for tracking the head of the chain, as seen by each contract listener
+var ContractListenerHeadGaugeName = "ff_contract_listener_head_block"
+
+// ContractListenerLagGaugeName is the prometheus metric for tracking the number of blocks each contract listener is behind the head of the chain
+var ContractListenerLagGaugeName = "ff_contract_listener_lag_blocks"
+
+// ContractListenerCatchupGaugeName is the prometheus metric for tracking whether each contract listener is catching up on historical blocks
+var ContractListenerCatchupGaugeName = "ff_contract_listener_catchup"
+
+var ListenerLabelName = "listener"
+
+func InitContractListenerMetrics() {
+	ContractListenerCheckpointGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: ContractListenerCheckpointGaugeName,
+		Help: "Block number of the checkpoint of the contract listener",
+	}, []string{NamespaceLabelName, ListenerLabelName})
+	ContractListenerHeadGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: ContractListenerHeadGaugeName,
+		Help: "Block number of the head of the chain, as reported for the contract listener",
+	}, []string{NamespaceLabelName, ListenerLabelName})
+	ContractListenerLagGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: ContractListenerLagGaugeName,
+		Help: "Number of blocks between the checkpoint of the contract listener and the head of the chain",
+	}, []string{NamespaceLabelName, ListenerLabelName})
+	ContractListenerCatchupGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: ContractListenerCatchupGaugeName,
+		Help: "1 if the contract listener is catching up on historical blocks, otherwise 0",
+	}, []string{NamespaceLabelName, ListenerLabelName})
+}
+
+func RegisterContractListenerMetrics() {
+	registry.MustRegister(ContractListenerCheckpointGauge)
+	registry.MustRegister(ContractListenerHeadGauge)
+	registry.MustRegister(ContractListenerLagGauge)
+	registry.MustRegister(ContractListenerCatchupGauge)
+}
@@ -39,6 +39,9 @@ type Manager interface {
 	BlockchainTransaction(location, methodName string)
 	BlockchainQuery(location, methodName string)
 	BlockchainEvent(location, signature string)
+	RecordsPruned(namespace, collection string, count int64)
+	ContractListenerStatus(namespace, listener string, status *core.ContractListenerStatus)
+	ContractListenerDeleted(namespace, listener string)
 	AddTime(id string)
 	GetTime(id string) time.Time
 	DeleteTime(id string)
@@ -146,6 +149,37 @@ func (mm *metricsManager) BlockchainEvent(location, signature string) {
 	BlockchainEventsCounter.WithLabelValues(location, signature).Inc()
 }
 
+func (mm *metricsManager) RecordsPruned(namespace, collection string, count int64) {
+	RetentionPrunedCounter.WithLabelValues(namespace, collection).Add(float64(count))
+}
+
+func (mm *metricsManager) ContractListenerStatus(namespace, listener string, status *core.ContractListenerStatus) {
+	if status.Checkpoint != nil {
+		ContractListenerCheckpointGauge.WithLabelValues(namespace, listener).Set(float64(status.Checkpoint.Block))
+	}
+	if status.HeadBlock != nil {
+		ContractListenerHeadGauge.WithLabelValues(namespace, listener).Set(float64(*status.HeadBlock))
+	}
+	if status.Lag != nil {
+		ContractListenerLagGauge.WithLabelValues(namespace, listener).Set(float64(*status.Lag))
+	}
+	if status.Checkpoint != nil {
+		// Catchup is only reported along with a checkpoint
+		catchup := float64(0)
+		if status.Catchup {
+			catchup = 1
+		}
+		ContractListenerCatchupGauge.WithLabelValues(namespace, listener).Set(catchup)
+	}
+}
+
+func (mm *metricsManager) ContractListenerDeleted(namespace, listener string) {
+	ContractListenerCheckpointGauge.DeleteLabelValues(namespace, listener)
+	ContractListenerHeadGauge.DeleteLabelValues(namespace, listener)
+	ContractListenerLagGauge.DeleteLabelValues(namespace, listener)
+	ContractListenerCatchupGauge.DeleteLabelValues(namespace, listener)
+}
+
 func (mm *metricsManager) AddTime(id string) {
 	mutex.Lock()
 	mm.timeMap[id] = time.Now()
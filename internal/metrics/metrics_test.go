@@ -215,6 +215,66 @@ func TestBlockchainEvents(t *testing.T) {
 	assert.Equal(t, float64(1), v)
 }
 
+func TestRecordsPruned(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.RecordsPruned("ns1", "events", 10)
+	m, err := RetentionPrunedCounter.GetMetricWith(prometheus.Labels{NamespaceLabelName: "ns1", CollectionLabelName: "events"})
+	assert.NoError(t, err)
+	v := testutil.ToFloat64(m)
+	assert.Equal(t, float64(10), v)
+}
+
+func TestContractListenerStatus(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	head := int64(100)
+	lag := int64(90)
+	labels := prometheus.Labels{NamespaceLabelName: "ns1", ListenerLabelName: "listener1"}
+
+	mm.ContractListenerStatus("ns1", "listener1", &core.ContractListenerStatus{
+		Checkpoint: &core.ContractListenerCheckpoint{Block: 10},
+		HeadBlock:  &head,
+		Lag:        &lag,
+		Catchup:    true,
+	})
+	m, err := ContractListenerCheckpointGauge.GetMetricWith(labels)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(10), testutil.ToFloat64(m))
+	m, err = ContractListenerHeadGauge.GetMetricWith(labels)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(100), testutil.ToFloat64(m))
+	m, err = ContractListenerLagGauge.GetMetricWith(labels)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(90), testutil.ToFloat64(m))
+	m, err = ContractListenerCatchupGauge.GetMetricWith(labels)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m))
+
+	mm.ContractListenerStatus("ns1", "listener1", &core.ContractListenerStatus{
+		Checkpoint: &core.ContractListenerCheckpoint{Block: 20},
+	})
+	assert.Equal(t, float64(0), testutil.ToFloat64(m))
+
+	// Without a checkpoint, catchup is not reported
+	mm.ContractListenerStatus("ns1", "listener2", &core.ContractListenerStatus{HeadBlock: &head})
+	assert.Equal(t, 1, testutil.CollectAndCount(ContractListenerCatchupGauge))
+}
+
+func TestContractListenerDeleted(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.ContractListenerStatus("ns1", "listener1", &core.ContractListenerStatus{
+		Checkpoint: &core.ContractListenerCheckpoint{Block: 10},
+	})
+	assert.Equal(t, 1, testutil.CollectAndCount(ContractListenerCheckpointGauge))
+	assert.Equal(t, 1, testutil.CollectAndCount(ContractListenerCatchupGauge))
+
+	mm.ContractListenerDeleted("ns1", "listener1")
+	assert.Equal(t, 0, testutil.CollectAndCount(ContractListenerCheckpointGauge))
+	assert.Equal(t, 0, testutil.CollectAndCount(ContractListenerCatchupGauge))
+}
+
 func TestIsMetricsEnabledTrue(t *testing.T) {
 	mm, cancel := newTestMetricsManager(t)
 	defer cancel()
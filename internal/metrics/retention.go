@@ -0,0 +1,40 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var RetentionPrunedCounter *prometheus.CounterVec
+
+// RetentionPrunedCounterName is the prometheus metric for tracking the total number of records pruned by the retention policy
+var RetentionPrunedCounterName = "ff_retention_pruned_total"
+
+var NamespaceLabelName = "ns"
+var CollectionLabelName = "collection"
+
+func InitRetentionMetrics() {
+	RetentionPrunedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: RetentionPrunedCounterName,
+		Help: "Number of records pruned by the data retention policy",
+	}, []string{NamespaceLabelName, CollectionLabelName})
+}
+
+func RegisterRetentionMetrics() {
+	registry.MustRegister(RetentionPrunedCounter)
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/hyperledger/firefly/internal/dataexchange/dxfactory"
 	"github.com/hyperledger/firefly/internal/events/eifactory"
 	"github.com/hyperledger/firefly/internal/identity/iifactory"
+	"github.com/hyperledger/firefly/internal/retention"
 	"github.com/hyperledger/firefly/internal/sharedstorage/ssfactory"
 	"github.com/hyperledger/firefly/internal/tokens/tifactory"
 	"github.com/hyperledger/firefly/pkg/core"
@@ -78,6 +79,8 @@ func InitConfig() {
 	contractConf.AddKnownKey(coreconfig.NamespaceMultipartyContractLocation)
 	contractConf.AddKnownKey(coreconfig.NamespaceMultipartyContractOptions)
 
+	retention.InitConfig(namespacePredefined.SubSection(coreconfig.NamespaceRetention))
+
 	bifactory.InitConfigDeprecated(deprecatedBlockchainConfig)
 	bifactory.InitConfig(blockchainConfig)
 	difactory.InitConfigDeprecated(deprecatedDatabaseConfig)
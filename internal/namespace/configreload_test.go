@@ -409,6 +409,7 @@ ui:
 func mockInitConfig(nmm *nmMocks) {
 	nmm.mdi.On("Init", mock.Anything, mock.Anything).Return(nil)
 	nmm.mdi.On("SetHandler", database.GlobalHandler, mock.Anything).Return()
+	nmm.mdi.On("Capabilities").Return(&database.Capabilities{}).Maybe()
 	nmm.mbi.On("Init", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	nmm.mdx.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	nmm.mps.On("Init", mock.Anything, mock.Anything).Return(nil)
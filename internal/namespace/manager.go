@@ -40,7 +40,9 @@ import (
 	"github.com/hyperledger/firefly/internal/identity/iifactory"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/orchestrator"
+	"github.com/hyperledger/firefly/internal/retention"
 	"github.com/hyperledger/firefly/internal/sharedstorage/ssfactory"
+	"github.com/hyperledger/firefly/internal/snapshot"
 	"github.com/hyperledger/firefly/internal/spievents"
 	"github.com/hyperledger/firefly/internal/tokens/tifactory"
 	"github.com/hyperledger/firefly/pkg/blockchain"
@@ -56,6 +58,8 @@ import (
 
 type Manager interface {
 	Init(ctx context.Context, cancelCtx context.CancelFunc, reset chan bool, reloadConfig func() error) error
+	InitSnapshot(ctx context.Context, cancelCtx context.CancelFunc, name string) (snapshot.Manager, error)
+	InitDatabase(ctx context.Context, cancelCtx context.CancelFunc, name string) (database.Plugin, error)
 	Start() error
 	WaitStop()
 	Reset(ctx context.Context) error
@@ -155,6 +159,15 @@ func stringSlicesEqual(a, b []string) bool {
 	return true
 }
 
+func stringSliceContains(a []string, s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 func NewNamespaceManager() Manager {
 	nm := &namespaceManager{
 		namespaces:          make(map[string]*namespace),
@@ -353,7 +366,7 @@ func (nm *namespaceManager) initNamespace(ns *namespace) error {
 
 	ns.orchestrator = nm.orchestratorFactory(&ns.Namespace, ns.config, ns.plugins, nm.metrics, nm.cacheManager)
 	ns.ctx, ns.cancelCtx = context.WithCancel(bgCtx)
-	if err := ns.orchestrator.Init(ns.ctx, ns.cancelCtx); err != nil {
+	if err := ns.orchestrator.Init(ns.ctx, nm.cancelCtx /* allow orchestrator to stop whole process */); err != nil {
 		return err
 	}
 	return nil
@@ -380,16 +393,11 @@ func (nm *namespaceManager) startNamespacesAndPlugins(namespacesToStart map[stri
 	}
 	for _, plugin := range pluginsToStart {
 		switch plugin.category {
-		case pluginCategoryBlockchain:
-			if err := plugin.blockchain.Start(); err != nil {
-				return err
-			}
-		case pluginCategoryDataexchange:
-			if err := plugin.dataexchange.Start(); err != nil {
-				return err
-			}
-		case pluginCategoryTokens:
-			if err := plugin.tokens.Start(); err != nil {
+		case pluginCategoryBlockchain, pluginCategoryDataexchange, pluginCategoryTokens:
+			if di := nm.clusteredDatabase(plugin); di != nil {
+				// Other processes share the database, so only one of them can listen to the connector
+				go nm.campaignForPlugin(plugin, di)
+			} else if err := plugin.start(); err != nil {
 				return err
 			}
 		}
@@ -397,6 +405,54 @@ func (nm *namespaceManager) startNamespacesAndPlugins(namespacesToStart map[stri
 	return nil
 }
 
+// start connects a plugin to its connector, to begin receiving events
+func (p *plugin) start() error {
+	switch p.category {
+	case pluginCategoryBlockchain:
+		return p.blockchain.Start()
+	case pluginCategoryDataexchange:
+		return p.dataexchange.Start()
+	default:
+		return p.tokens.Start()
+	}
+}
+
+// clusteredDatabase returns the database used by the namespaces of a plugin, if it is shared with other processes
+func (nm *namespaceManager) clusteredDatabase(p *plugin) database.Plugin {
+	for _, ns := range nm.namespaces {
+		if ns.plugins.Database.Plugin == nil || !stringSliceContains(ns.pluginNames, p.name) {
+			continue
+		}
+		if ns.plugins.Database.Plugin.Capabilities().Clustering {
+			return ns.plugins.Database.Plugin
+		}
+	}
+	return nil
+}
+
+func (nm *namespaceManager) campaignForPlugin(p *plugin, di database.Plugin) {
+	lost, err := di.AcquireLeadership(p.ctx, "plugin:"+p.name)
+	if err == nil {
+		log.L(p.ctx).Infof("Elected leader for plugin '%s'", p.name)
+		err = p.start()
+	}
+	if err != nil {
+		if p.ctx.Err() == nil {
+			log.L(p.ctx).Errorf("Failed to start plugin '%s' as leader - stopping: %s", p.name, err)
+			nm.cancelCtx()
+		}
+		return
+	}
+
+	select {
+	case <-p.ctx.Done():
+	case <-lost:
+		// Another process might already be listening to the connector
+		log.L(p.ctx).Errorf("Lost leadership for plugin '%s' - stopping", p.name)
+		nm.cancelCtx()
+	}
+}
+
 func (nm *namespaceManager) WaitStop() {
 	nm.nsMux.Lock()
 	namespaces := make(map[string]*namespace, len(nm.namespaces))
@@ -917,6 +973,7 @@ func (nm *namespaceManager) loadNamespace(ctx context.Context, name string, inde
 		DefaultKey:          conf.GetString(coreconfig.NamespaceDefaultKey),
 		TokenBroadcastNames: nm.tokenBroadcastNames,
 		KeyNormalization:    keyNormalization,
+		Retention:           retention.ReadConfig(conf.SubSection(coreconfig.NamespaceRetention)),
 	}
 	if multipartyEnabled.(bool) {
 		contractsConf := multipartyConf.SubArray(coreconfig.NamespaceMultipartyContract)
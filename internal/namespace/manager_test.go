@@ -1753,6 +1753,7 @@ func TestStart(t *testing.T) {
 
 	nsStarted := make(chan struct{})
 
+	nmm.mdi.On("Capabilities").Return(&database.Capabilities{})
 	nmm.mbi.On("Start", mock.Anything).Return(nil)
 	nmm.mdx.On("Start", mock.Anything).Return(nil)
 	nmm.mti[0].On("Start", mock.Anything).Return(nil)
@@ -1813,6 +1814,67 @@ func TestStartTokensFail(t *testing.T) {
 
 }
 
+func TestStartPluginsClustered(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	lost := make(chan struct{})
+	started := make(chan struct{})
+	nmm.mdi.On("Capabilities").Return(&database.Capabilities{Clustering: true})
+	nmm.mdi.On("AcquireLeadership", mock.Anything, "plugin:ethereum").Return((<-chan struct{})(lost), nil)
+	nmm.mbi.On("Start").Return(nil).Run(func(args mock.Arguments) { close(started) })
+
+	err := nm.startNamespacesAndPlugins(map[string]*namespace{}, map[string]*plugin{
+		"ethereum": nm.plugins["ethereum"],
+	})
+	assert.NoError(t, err)
+
+	<-started
+	close(lost)
+	<-nm.ctx.Done()
+}
+
+func TestStartPluginsClusteredStartFail(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	nmm.mdi.On("Capabilities").Return(&database.Capabilities{Clustering: true})
+	nmm.mdi.On("AcquireLeadership", mock.Anything, "plugin:ffdx").Return(make(<-chan struct{}), nil)
+	nmm.mdx.On("Start").Return(fmt.Errorf("pop"))
+
+	err := nm.startNamespacesAndPlugins(map[string]*namespace{}, map[string]*plugin{
+		"ffdx": nm.plugins["ffdx"],
+	})
+	assert.NoError(t, err)
+
+	<-nm.ctx.Done()
+}
+
+func TestStartPluginsClusteredStandbyStop(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	p := nm.plugins["erc721"]
+	nmm.mdi.On("AcquireLeadership", mock.Anything, "plugin:erc721").Run(func(args mock.Arguments) {
+		p.cancelCtx()
+	}).Return(nil, fmt.Errorf("pop"))
+
+	nm.campaignForPlugin(p, nmm.mdi)
+	assert.NoError(t, nm.ctx.Err())
+}
+
+func TestStartPluginsClusteredLeaderStop(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	p := nm.plugins["erc721"]
+	nmm.mdi.On("AcquireLeadership", mock.Anything, "plugin:erc721").Return(make(<-chan struct{}), nil)
+	nmm.mti[0].On("Start").Return(nil).Run(func(args mock.Arguments) { p.cancelCtx() })
+
+	nm.campaignForPlugin(p, nmm.mdi)
+	assert.NoError(t, nm.ctx.Err())
+}
+
 func TestStartOrchestratorFail(t *testing.T) {
 	nm, nmm, cleanup := newTestNamespaceManager(t, true)
 	defer cleanup()
@@ -0,0 +1,63 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespace
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// InitDatabase loads the configuration, and initializes only the schema of the named database plugin - or the
+// only database plugin if no name is given - so it can be migrated offline. None of the background processing
+// of the plugin is started, and migrations are never applied automatically here, regardless of the
+// configuration, as that is the job of the caller.
+func (nm *namespaceManager) InitDatabase(ctx context.Context, cancelCtx context.CancelFunc, name string) (database.Plugin, error) {
+	nm.ctx = ctx
+	nm.cancelCtx = cancelCtx
+
+	rawConfig := nm.dumpRootConfig()
+	nm.loadManagers(ctx)
+	plugins, err := nm.loadPlugins(ctx, rawConfig)
+	if err != nil {
+		return nil, err
+	}
+	nm.plugins = plugins
+
+	var selected *plugin
+	for _, p := range plugins {
+		if p.category != pluginCategoryDatabase || (name != "" && p.name != name) {
+			continue
+		}
+		if selected != nil {
+			return nil, i18n.NewError(ctx, coremsgs.MsgDatabasePluginRequired)
+		}
+		selected = p
+	}
+	if selected == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgDatabasePluginNotFound, name)
+	}
+
+	selected.config.Set(sqlcommon.SQLConfMigrationsAuto, false)
+	if err := selected.database.InitSchema(ctx, selected.config); err != nil {
+		return nil, err
+	}
+	return selected.database, nil
+}
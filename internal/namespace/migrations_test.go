@@ -0,0 +1,116 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestInitDatabase(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, false)
+	defer cleanup()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(testBaseConfig))
+	assert.NoError(t, err)
+
+	nmm.mdi.On("InitSchema", mock.Anything, mock.MatchedBy(func(conf interface{}) bool {
+		return !nm.plugins["postgres"].config.GetBool(sqlcommon.SQLConfMigrationsAuto)
+	})).Return(nil)
+
+	di, err := nm.InitDatabase(nm.ctx, nm.cancelCtx, "")
+	assert.NoError(t, err)
+	assert.Equal(t, nmm.mdi, di)
+}
+
+func TestInitDatabaseByName(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, false)
+	defer cleanup()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(testBaseConfig))
+	assert.NoError(t, err)
+
+	nmm.mdi.On("InitSchema", mock.Anything, mock.Anything).Return(nil)
+
+	di, err := nm.InitDatabase(nm.ctx, nm.cancelCtx, "postgres")
+	assert.NoError(t, err)
+	assert.Equal(t, nmm.mdi, di)
+}
+
+func TestInitDatabaseUnknownPlugin(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, false)
+	defer cleanup()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(testBaseConfig))
+	assert.NoError(t, err)
+
+	_, err = nm.InitDatabase(nm.ctx, nm.cancelCtx, "ffdx")
+	assert.Regexp(t, "FF10512.*ffdx", err)
+}
+
+func TestInitDatabaseMultiplePlugins(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, false)
+	defer cleanup()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+plugins:
+  database:
+  - name: postgres
+    type: postgres
+  - name: sqlite
+    type: sqlite3
+`))
+	assert.NoError(t, err)
+
+	_, err = nm.InitDatabase(nm.ctx, nm.cancelCtx, "")
+	assert.Regexp(t, "FF10513", err)
+}
+
+func TestInitDatabaseBadPlugins(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, false)
+	defer cleanup()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(testBaseConfig))
+	assert.NoError(t, err)
+	nm.databaseFactory = func(ctx context.Context, pluginType string) (database.Plugin, error) {
+		return nil, fmt.Errorf("pop")
+	}
+
+	_, err = nm.InitDatabase(nm.ctx, nm.cancelCtx, "")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestInitDatabaseInitFail(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, false)
+	defer cleanup()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(testBaseConfig))
+	assert.NoError(t, err)
+
+	nmm.mdi.On("InitSchema", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err = nm.InitDatabase(nm.ctx, nm.cancelCtx, "")
+	assert.EqualError(t, err, "pop")
+}
@@ -0,0 +1,59 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespace
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/snapshot"
+)
+
+// InitSnapshot loads the configuration, and initializes only the database and data exchange plugins
+// of a single namespace - without starting anything - so the namespace can be exported or imported offline
+func (nm *namespaceManager) InitSnapshot(ctx context.Context, cancelCtx context.CancelFunc, name string) (snapshot.Manager, error) {
+	nm.ctx = ctx
+	nm.cancelCtx = cancelCtx
+
+	rawConfig := nm.dumpRootConfig()
+	nm.loadManagers(ctx)
+	plugins, err := nm.loadPlugins(ctx, rawConfig)
+	if err != nil {
+		return nil, err
+	}
+	nm.plugins = plugins
+	namespaces, err := nm.loadNamespaces(ctx, rawConfig, plugins)
+	if err != nil {
+		return nil, err
+	}
+	ns := namespaces[name]
+	if ns == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgUnknownNamespace, name)
+	}
+
+	pluginsToInit := map[string]*plugin{
+		ns.plugins.Database.Name: plugins[ns.plugins.Database.Name],
+	}
+	if ns.plugins.DataExchange.Plugin != nil {
+		pluginsToInit[ns.plugins.DataExchange.Name] = plugins[ns.plugins.DataExchange.Name]
+	}
+	if err := nm.initPlugins(pluginsToInit); err != nil {
+		return nil, err
+	}
+	return snapshot.NewSnapshotManager(ctx, ns.Name, ns.plugins.Database.Plugin, ns.plugins.DataExchange.Plugin)
+}
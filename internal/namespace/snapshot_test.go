@@ -0,0 +1,123 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestInitSnapshot(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, false)
+	defer cleanup()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(testBaseConfig))
+	assert.NoError(t, err)
+
+	nmm.mdi.On("Init", mock.Anything, mock.Anything).Return(nil)
+	nmm.mdi.On("SetHandler", database.GlobalHandler, nm).Return()
+	nmm.mdx.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	sm, err := nm.InitSnapshot(nm.ctx, nm.cancelCtx, "default")
+	assert.NoError(t, err)
+	assert.NotNil(t, sm)
+}
+
+func TestInitSnapshotNoDataExchange(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, false)
+	defer cleanup()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+namespaces:
+  default: ns1
+  predefined:
+  - name: ns1
+    plugins: [postgres]
+plugins:
+  database:
+  - name: postgres
+    type: postgres
+`))
+	assert.NoError(t, err)
+
+	nmm.mdi.On("Init", mock.Anything, mock.Anything).Return(nil)
+	nmm.mdi.On("SetHandler", database.GlobalHandler, nm).Return()
+
+	sm, err := nm.InitSnapshot(nm.ctx, nm.cancelCtx, "ns1")
+	assert.NoError(t, err)
+	assert.NotNil(t, sm)
+}
+
+func TestInitSnapshotUnknownNamespace(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, false)
+	defer cleanup()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(testBaseConfig))
+	assert.NoError(t, err)
+
+	_, err = nm.InitSnapshot(nm.ctx, nm.cancelCtx, "ns2")
+	assert.Regexp(t, "FF10436", err)
+}
+
+func TestInitSnapshotBadPlugins(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, false)
+	defer cleanup()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(testBaseConfig))
+	assert.NoError(t, err)
+	nm.databaseFactory = func(ctx context.Context, pluginType string) (database.Plugin, error) {
+		return nil, fmt.Errorf("pop")
+	}
+
+	_, err = nm.InitSnapshot(nm.ctx, nm.cancelCtx, "default")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestInitSnapshotBadNamespaces(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, false)
+	defer cleanup()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(testBaseConfig))
+	assert.NoError(t, err)
+	config.Set(coreconfig.NamespacesDefault, "unknown")
+
+	_, err = nm.InitSnapshot(nm.ctx, nm.cancelCtx, "default")
+	assert.Regexp(t, "FF10166", err)
+}
+
+func TestInitSnapshotInitFail(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, false)
+	defer cleanup()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(testBaseConfig))
+	assert.NoError(t, err)
+
+	nmm.mdi.On("Init", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	nmm.mdx.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	_, err = nm.InitSnapshot(nm.ctx, nm.cancelCtx, "default")
+	assert.EqualError(t, err, "pop")
+}
@@ -220,7 +220,8 @@ func (om *operationsManager) GetOperationByIDCached(ctx context.Context, opID *f
 	if cached := om.getCachedOperation(opID); cached != nil {
 		return cached, nil
 	}
-	op, err := om.database.GetOperationByID(ctx, om.namespace, opID)
+	// Operations are updated in place in the cache, so the cached copy must be read from the primary
+	op, err := om.database.GetOperationByID(database.WithPrimary(ctx), om.namespace, opID)
 	if err == nil && op != nil {
 		om.cacheOperation(op)
 	}
@@ -240,7 +241,7 @@ func (om *operationsManager) getOperationsCached(ctx context.Context, opIDs []*f
 
 	if len(cacheMisses) > 0 {
 		opFilter := database.OperationQueryFactory.NewFilter(ctx).In("id", cacheMisses)
-		dbOps, _, err := om.database.GetOperations(ctx, om.namespace, opFilter)
+		dbOps, _, err := om.database.GetOperations(database.WithPrimary(ctx), om.namespace, opFilter)
 		if err != nil {
 			return nil, err
 		}
@@ -192,12 +192,12 @@ func (or *orchestrator) GetTransactions(ctx context.Context, filter ffapi.AndFil
 	return or.database().GetTransactions(ctx, or.namespace.Name, filter)
 }
 
-func (or *orchestrator) GetMessages(ctx context.Context, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error) {
-	return or.database().GetMessages(ctx, or.namespace.Name, filter)
+func (or *orchestrator) GetMessages(ctx context.Context, filter ffapi.AndFilter, valueFilter ffapi.Filter) ([]*core.Message, *ffapi.FilterResult, error) {
+	return or.database().GetMessages(ctx, or.namespace.Name, filter, valueFilter)
 }
 
-func (or *orchestrator) GetMessagesWithData(ctx context.Context, filter ffapi.AndFilter) ([]*core.MessageInOut, *ffapi.FilterResult, error) {
-	msgs, fr, err := or.database().GetMessages(ctx, or.namespace.Name, filter)
+func (or *orchestrator) GetMessagesWithData(ctx context.Context, filter ffapi.AndFilter, valueFilter ffapi.Filter) ([]*core.MessageInOut, *ffapi.FilterResult, error) {
+	msgs, fr, err := or.database().GetMessages(ctx, or.namespace.Name, filter, valueFilter)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -259,8 +259,8 @@ func (or *orchestrator) GetBatches(ctx context.Context, filter ffapi.AndFilter)
 	return or.database().GetBatches(ctx, or.namespace.Name, filter)
 }
 
-func (or *orchestrator) GetData(ctx context.Context, filter ffapi.AndFilter) (core.DataArray, *ffapi.FilterResult, error) {
-	return or.database().GetData(ctx, or.namespace.Name, filter)
+func (or *orchestrator) GetData(ctx context.Context, filter ffapi.AndFilter, valueFilter ffapi.Filter) (core.DataArray, *ffapi.FilterResult, error) {
+	return or.database().GetData(ctx, or.namespace.Name, filter, valueFilter)
 }
 
 func (or *orchestrator) GetDataSubPaths(ctx context.Context, path string) ([]string, error) {
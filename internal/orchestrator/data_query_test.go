@@ -174,20 +174,20 @@ func TestGetMessages(t *testing.T) {
 	or := newTestOrchestrator()
 	defer or.cleanup(t)
 	u := fftypes.NewUUID()
-	or.mdi.On("GetMessages", mock.Anything, "ns", mock.Anything).Return([]*core.Message{}, nil, nil)
+	or.mdi.On("GetMessages", mock.Anything, "ns", mock.Anything, mock.Anything).Return([]*core.Message{}, nil, nil)
 	fb := database.MessageQueryFactory.NewFilter(context.Background())
 	f := fb.And(fb.Eq("id", u))
-	_, _, err := or.GetMessages(context.Background(), f)
+	_, _, err := or.GetMessages(context.Background(), f, nil)
 	assert.NoError(t, err)
 }
 
 func TestGetMessagesWithDataFailMsg(t *testing.T) {
 	or := newTestOrchestrator()
 	defer or.cleanup(t)
-	or.mdi.On("GetMessages", mock.Anything, "ns", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	or.mdi.On("GetMessages", mock.Anything, "ns", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
 	fb := database.MessageQueryFactory.NewFilter(context.Background())
 	f := fb.And(fb.Eq("id", fftypes.NewUUID()))
-	_, _, err := or.GetMessagesWithData(context.Background(), f)
+	_, _, err := or.GetMessagesWithData(context.Background(), f, nil)
 	assert.EqualError(t, err, "pop")
 }
 
@@ -202,11 +202,11 @@ func TestGetMessagesWithDataOk(t *testing.T) {
 		},
 		Data: core.DataRefs{},
 	}
-	or.mdi.On("GetMessages", mock.Anything, "ns", mock.Anything).Return([]*core.Message{msg}, nil, nil)
+	or.mdi.On("GetMessages", mock.Anything, "ns", mock.Anything, mock.Anything).Return([]*core.Message{msg}, nil, nil)
 	fb := database.MessageQueryFactory.NewFilter(context.Background())
 	or.mdm.On("GetMessageDataCached", mock.Anything, mock.Anything).Return(core.DataArray{}, true, nil)
 	f := fb.And(fb.Eq("id", u))
-	_, _, err := or.GetMessagesWithData(context.Background(), f)
+	_, _, err := or.GetMessagesWithData(context.Background(), f, nil)
 	assert.NoError(t, err)
 }
 
@@ -221,11 +221,11 @@ func TestGetMessagesWithDataFail(t *testing.T) {
 		},
 		Data: core.DataRefs{},
 	}
-	or.mdi.On("GetMessages", mock.Anything, "ns", mock.Anything).Return([]*core.Message{msg}, nil, nil)
+	or.mdi.On("GetMessages", mock.Anything, "ns", mock.Anything, mock.Anything).Return([]*core.Message{msg}, nil, nil)
 	fb := database.MessageQueryFactory.NewFilter(context.Background())
 	or.mdm.On("GetMessageDataCached", mock.Anything, mock.Anything).Return(nil, true, fmt.Errorf("pop"))
 	f := fb.And(fb.Eq("id", u))
-	_, _, err := or.GetMessagesWithData(context.Background(), f)
+	_, _, err := or.GetMessagesWithData(context.Background(), f, nil)
 	assert.EqualError(t, err, "pop")
 }
 
@@ -431,10 +431,10 @@ func TestGetData(t *testing.T) {
 	or := newTestOrchestrator()
 	defer or.cleanup(t)
 	u := fftypes.NewUUID()
-	or.mdi.On("GetData", mock.Anything, "ns", mock.Anything).Return(core.DataArray{}, nil, nil)
+	or.mdi.On("GetData", mock.Anything, "ns", mock.Anything, mock.Anything).Return(core.DataArray{}, nil, nil)
 	fb := database.DataQueryFactory.NewFilter(context.Background())
 	f := fb.And(fb.Eq("id", u))
-	_, _, err := or.GetData(context.Background(), f)
+	_, _, err := or.GetData(context.Background(), f, nil)
 	assert.NoError(t, err)
 }
 
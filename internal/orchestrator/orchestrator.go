@@ -39,7 +39,9 @@ import (
 	"github.com/hyperledger/firefly/internal/networkmap"
 	"github.com/hyperledger/firefly/internal/operations"
 	"github.com/hyperledger/firefly/internal/privatemessaging"
+	"github.com/hyperledger/firefly/internal/retention"
 	"github.com/hyperledger/firefly/internal/shareddownload"
+	"github.com/hyperledger/firefly/internal/snapshot"
 	"github.com/hyperledger/firefly/internal/syncasync"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/blockchain"
@@ -70,6 +72,8 @@ type Orchestrator interface {
 	NetworkMap() networkmap.Manager
 	Operations() operations.Manager
 	Identity() identity.Manager
+	Retention() retention.Manager
+	Snapshot() snapshot.Manager
 
 	// Status
 	GetStatus(ctx context.Context) (*core.NamespaceStatus, error)
@@ -81,6 +85,9 @@ type Orchestrator interface {
 	CreateSubscription(ctx context.Context, subDef *core.Subscription) (*core.Subscription, error)
 	CreateUpdateSubscription(ctx context.Context, subDef *core.Subscription) (*core.Subscription, error)
 	DeleteSubscription(ctx context.Context, id string) error
+	GetSubscriptionDeadLetters(ctx context.Context, id string, filter ffapi.AndFilter) ([]*core.DeadLetter, *ffapi.FilterResult, error)
+	ReplaySubscriptionDeadLetters(ctx context.Context, id string, replay *core.DeadLetterReplay) ([]*core.DeadLetter, error)
+	DeleteSubscriptionDeadLetters(ctx context.Context, id string) error
 
 	// Data Query
 	GetNamespace(ctx context.Context) *core.Namespace
@@ -91,8 +98,8 @@ type Orchestrator interface {
 	GetTransactions(ctx context.Context, filter ffapi.AndFilter) ([]*core.Transaction, *ffapi.FilterResult, error)
 	GetMessageByID(ctx context.Context, id string) (*core.Message, error)
 	GetMessageByIDWithData(ctx context.Context, id string) (*core.MessageInOut, error)
-	GetMessages(ctx context.Context, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error)
-	GetMessagesWithData(ctx context.Context, filter ffapi.AndFilter) ([]*core.MessageInOut, *ffapi.FilterResult, error)
+	GetMessages(ctx context.Context, filter ffapi.AndFilter, valueFilter ffapi.Filter) ([]*core.Message, *ffapi.FilterResult, error)
+	GetMessagesWithData(ctx context.Context, filter ffapi.AndFilter, valueFilter ffapi.Filter) ([]*core.MessageInOut, *ffapi.FilterResult, error)
 	GetMessageTransaction(ctx context.Context, id string) (*core.Transaction, error)
 	GetMessageEvents(ctx context.Context, id string, filter ffapi.AndFilter) ([]*core.Event, *ffapi.FilterResult, error)
 	GetMessageData(ctx context.Context, id string) (core.DataArray, error)
@@ -100,7 +107,7 @@ type Orchestrator interface {
 	GetBatchByID(ctx context.Context, id string) (*core.BatchPersisted, error)
 	GetBatches(ctx context.Context, filter ffapi.AndFilter) ([]*core.BatchPersisted, *ffapi.FilterResult, error)
 	GetDataByID(ctx context.Context, id string) (*core.Data, error)
-	GetData(ctx context.Context, filter ffapi.AndFilter) (core.DataArray, *ffapi.FilterResult, error)
+	GetData(ctx context.Context, filter ffapi.AndFilter, valueFilter ffapi.Filter) (core.DataArray, *ffapi.FilterResult, error)
 	GetDataSubPaths(ctx context.Context, path string) ([]string, error)
 	GetDatatypeByID(ctx context.Context, id string) (*core.Datatype, error)
 	GetDatatypeByName(ctx context.Context, name, version string) (*core.Datatype, error)
@@ -182,12 +189,16 @@ type Config struct {
 	KeyNormalization    string
 	Multiparty          multiparty.Config
 	TokenBroadcastNames map[string]string
+	Retention           retention.Config
 }
 
 type orchestrator struct {
 	ctx            context.Context
 	cancelCtx      context.CancelFunc
+	stopProcess    context.CancelFunc
 	started        bool
+	leading        bool
+	campaignDone   chan struct{}
 	namespace      *core.Namespace
 	config         Config
 	plugins        *Plugins
@@ -209,6 +220,8 @@ type orchestrator struct {
 	metrics        metrics.Manager
 	cacheManager   cache.Manager
 	operations     operations.Manager
+	retention      retention.Manager
+	snapshot       snapshot.Manager
 	txHelper       txcommon.Helper
 }
 
@@ -229,6 +242,7 @@ func (or *orchestrator) Init(ctx context.Context, cancelCtx context.CancelFunc)
 		namespaceLog += "->" + or.namespace.NetworkName
 	}
 	or.ctx, or.cancelCtx = context.WithCancel(log.WithLogField(ctx, "ns", namespaceLog))
+	or.stopProcess = cancelCtx
 	err = or.initComponents(or.ctx)
 	if err == nil {
 		err = or.initHandlers(or.ctx)
@@ -266,6 +280,27 @@ func (or *orchestrator) tokens() map[string]tokens.Plugin {
 
 func (or *orchestrator) Start() (err error) {
 	or.data.Start()
+	err = or.events.Start()
+	if err == nil {
+		err = or.operations.Start()
+	}
+	if err == nil {
+		if or.database().Capabilities().Clustering {
+			// Other processes share this database, so only start the leader components once elected
+			or.campaignDone = make(chan struct{})
+			go or.campaign()
+		} else {
+			err = or.startLeaderComponents()
+		}
+	}
+
+	or.started = true
+	return err
+}
+
+// startLeaderComponents starts the processing that must only run in one process for the namespace
+func (or *orchestrator) startLeaderComponents() (err error) {
+	or.leading = true
 	if or.config.Multiparty.Enabled {
 		err = or.batch.Start()
 		if err == nil {
@@ -276,21 +311,49 @@ func (or *orchestrator) Start() (err error) {
 		}
 	}
 	if err == nil {
-		err = or.events.Start()
+		or.events.StartAggregator()
+		or.retention.Start()
+		if or.contracts != nil {
+			or.contracts.Start()
+		}
 	}
+	return err
+}
+
+func (or *orchestrator) campaign() {
+	defer close(or.campaignDone)
+
+	lost, err := or.database().AcquireLeadership(or.ctx, "namespace:"+or.namespace.Name)
 	if err == nil {
-		err = or.operations.Start()
+		log.L(or.ctx).Infof("Elected leader for namespace")
+		err = or.startLeaderComponents()
+	}
+	if err != nil {
+		if or.ctx.Err() == nil {
+			log.L(or.ctx).Errorf("Failed to start as leader for namespace - stopping: %s", err)
+			or.stopProcess()
+		}
+		return
 	}
 
-	or.started = true
-	return err
+	select {
+	case <-or.ctx.Done():
+	case <-lost:
+		// Another process might already be leader, so we cannot continue to process as leader
+		log.L(or.ctx).Errorf("Lost leadership for namespace - stopping")
+		or.stopProcess()
+	}
 }
 
 func (or *orchestrator) WaitStop() {
 	if !or.started {
 		return
 	}
-	if or.batch != nil {
+	if or.campaignDone != nil {
+		<-or.campaignDone
+		or.campaignDone = nil
+	}
+	if or.batch != nil && or.leading {
 		or.batch.WaitStop()
 		or.batch = nil
 	}
@@ -314,7 +377,15 @@ func (or *orchestrator) WaitStop() {
 		or.operations.WaitStop()
 		or.operations = nil
 	}
+	if or.retention != nil {
+		or.retention.WaitStop()
+		or.retention = nil
+	}
+	if or.contracts != nil && or.leading {
+		or.contracts.WaitStop()
+	}
 	or.started = false
+	or.leading = false
 }
 
 func (or *orchestrator) Broadcast() broadcast.Manager {
@@ -357,6 +428,14 @@ func (or *orchestrator) Operations() operations.Manager {
 	return or.operations
 }
 
+func (or *orchestrator) Retention() retention.Manager {
+	return or.retention
+}
+
+func (or *orchestrator) Snapshot() snapshot.Manager {
+	return or.snapshot
+}
+
 func (or *orchestrator) MultiParty() multiparty.Manager {
 	return or.multiparty
 }
@@ -433,6 +512,18 @@ func (or *orchestrator) initManagers(ctx context.Context) (err error) {
 		}
 	}
 
+	if or.retention == nil {
+		if or.retention, err = retention.NewRetentionManager(ctx, or.namespace.Name, or.config.Retention, or.database(), or.metrics); err != nil {
+			return err
+		}
+	}
+
+	if or.snapshot == nil {
+		if or.snapshot, err = snapshot.NewSnapshotManager(ctx, or.namespace.Name, or.database(), or.dataexchange()); err != nil {
+			return err
+		}
+	}
+
 	if or.config.Multiparty.Enabled {
 		if or.multiparty == nil {
 			or.multiparty, err = multiparty.NewMultipartyManager(or.ctx, or.namespace, or.config.Multiparty, or.database(), or.blockchain(), or.operations, or.metrics, or.txHelper)
@@ -477,7 +568,7 @@ func (or *orchestrator) initManagers(ctx context.Context) (err error) {
 
 	if or.blockchain() != nil {
 		if or.contracts == nil {
-			or.contracts, err = contracts.NewContractManager(ctx, or.namespace.Name, or.database(), or.blockchain(), or.data, or.broadcast, or.messaging, or.batch, or.identity, or.operations, or.txHelper, or.syncasync)
+			or.contracts, err = contracts.NewContractManager(ctx, or.namespace.Name, or.database(), or.blockchain(), or.data, or.broadcast, or.messaging, or.batch, or.identity, or.operations, or.txHelper, or.syncasync, or.metrics)
 			if err != nil {
 				return err
 			}
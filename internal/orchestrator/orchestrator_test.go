@@ -46,12 +46,15 @@ import (
 	"github.com/hyperledger/firefly/mocks/networkmapmocks"
 	"github.com/hyperledger/firefly/mocks/operationmocks"
 	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
+	"github.com/hyperledger/firefly/mocks/retentionmocks"
 	"github.com/hyperledger/firefly/mocks/shareddownloadmocks"
 	"github.com/hyperledger/firefly/mocks/sharedstoragemocks"
+	"github.com/hyperledger/firefly/mocks/snapshotmocks"
 	"github.com/hyperledger/firefly/mocks/spieventsmocks"
 	"github.com/hyperledger/firefly/mocks/tokenmocks"
 	"github.com/hyperledger/firefly/mocks/txcommonmocks"
 	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -79,6 +82,8 @@ type testOrchestrator struct {
 	mmi *metricsmocks.Manager
 	cmi *cachemocks.Manager
 	mom *operationmocks.Manager
+	mrm *retentionmocks.Manager
+	msm *snapshotmocks.Manager
 	mth *txcommonmocks.Helper
 	msd *shareddownloadmocks.Manager
 	mae *spieventsmocks.Manager
@@ -106,6 +111,8 @@ func (tor *testOrchestrator) cleanup(t *testing.T) {
 	tor.mmi.AssertExpectations(t)
 	tor.cmi.AssertExpectations(t)
 	tor.mom.AssertExpectations(t)
+	tor.mrm.AssertExpectations(t)
+	tor.msm.AssertExpectations(t)
 	tor.mth.AssertExpectations(t)
 	tor.msd.AssertExpectations(t)
 	tor.mae.AssertExpectations(t)
@@ -140,6 +147,8 @@ func newTestOrchestrator() *testOrchestrator {
 		mmi: &metricsmocks.Manager{},
 		cmi: &cachemocks.Manager{},
 		mom: &operationmocks.Manager{},
+		mrm: &retentionmocks.Manager{},
+		msm: &snapshotmocks.Manager{},
 		mth: &txcommonmocks.Helper{},
 		msd: &shareddownloadmocks.Manager{},
 		mae: &spieventsmocks.Manager{},
@@ -160,6 +169,8 @@ func newTestOrchestrator() *testOrchestrator {
 	tor.orchestrator.metrics = tor.mmi
 	tor.orchestrator.cacheManager = tor.cmi
 	tor.orchestrator.operations = tor.mom
+	tor.orchestrator.retention = tor.mrm
+	tor.orchestrator.snapshot = tor.msm
 	tor.orchestrator.sharedDownload = tor.msd
 	tor.orchestrator.txHelper = tor.mth
 	tor.orchestrator.defhandler = tor.mdh
@@ -242,6 +253,8 @@ func TestInitOK(t *testing.T) {
 	assert.Equal(t, or.mam, or.Assets())
 	assert.Equal(t, or.mdm, or.Data())
 	assert.Equal(t, or.mom, or.Operations())
+	assert.Equal(t, or.mrm, or.Retention())
+	assert.Equal(t, or.msm, or.Snapshot())
 	assert.Equal(t, or.mcm, or.Contracts())
 	assert.Equal(t, or.mnm, or.NetworkMap())
 	assert.Equal(t, or.mmp, or.MultiParty())
@@ -428,11 +441,32 @@ func TestInitOperationsComponentFail(t *testing.T) {
 	assert.Regexp(t, "FF10128", err)
 }
 
+func TestInitRetentionComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.plugins.Database.Plugin = nil
+	or.retention = nil
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestInitSnapshotComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.plugins.Database.Plugin = nil
+	or.snapshot = nil
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10128", err)
+}
+
 func TestStartBatchFail(t *testing.T) {
 	coreconfig.Reset()
 	or := newTestOrchestrator()
 	defer or.cleanup(t)
 	or.mdm.On("Start").Return(nil)
+	or.mem.On("Start").Return(nil)
+	or.mom.On("Start").Return(nil)
+	or.mdi.On("Capabilities").Return(&database.Capabilities{})
 	or.mba.On("Start").Return(fmt.Errorf("pop"))
 	err := or.Start()
 	assert.EqualError(t, err, "pop")
@@ -448,18 +482,197 @@ func TestStartStopOk(t *testing.T) {
 	or.mbm.On("Start").Return(nil)
 	or.msd.On("Start").Return(nil)
 	or.mom.On("Start").Return(nil)
+	or.mrm.On("Start").Return()
+	or.mcm.On("Start").Return()
+	or.mem.On("StartAggregator").Return()
+	or.mdi.On("Capabilities").Return(&database.Capabilities{})
 	or.mba.On("WaitStop").Return(nil)
 	or.mbm.On("WaitStop").Return(nil)
 	or.mdm.On("WaitStop").Return(nil)
 	or.msd.On("WaitStop").Return(nil)
 	or.mom.On("WaitStop").Return(nil)
 	or.mem.On("WaitStop").Return(nil)
+	or.mrm.On("WaitStop").Return()
+	or.mcm.On("WaitStop").Return()
 	err := or.Start()
 	assert.NoError(t, err)
 	or.WaitStop()
 	or.WaitStop() // swallows dups
 }
 
+func TestStartClusteredLeaderLost(t *testing.T) {
+	coreconfig.Reset()
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	stopped := make(chan struct{})
+	or.stopProcess = func() { close(stopped) }
+	lost := make(chan struct{})
+	elected := make(chan struct{})
+	or.mdi.On("Capabilities").Return(&database.Capabilities{Clustering: true})
+	or.mdi.On("AcquireLeadership", mock.Anything, "namespace:ns").Return((<-chan struct{})(lost), nil)
+	or.mdm.On("Start").Return(nil)
+	or.mem.On("Start").Return(nil)
+	or.mom.On("Start").Return(nil)
+	or.mba.On("Start").Return(nil)
+	or.mbm.On("Start").Return(nil)
+	or.msd.On("Start").Return(nil)
+	or.mem.On("StartAggregator").Return()
+	or.mcm.On("Start").Return()
+	or.mrm.On("Start").Run(func(args mock.Arguments) { close(elected) }).Return()
+	or.mba.On("WaitStop").Return(nil)
+	or.mbm.On("WaitStop").Return(nil)
+	or.mdm.On("WaitStop").Return(nil)
+	or.msd.On("WaitStop").Return(nil)
+	or.mom.On("WaitStop").Return(nil)
+	or.mem.On("WaitStop").Return(nil)
+	or.mrm.On("WaitStop").Return()
+	or.mcm.On("WaitStop").Return()
+	err := or.Start()
+	assert.NoError(t, err)
+	<-elected
+	close(lost)
+	<-stopped
+	or.WaitStop()
+}
+
+func TestStartClusteredLeaderStartFail(t *testing.T) {
+	coreconfig.Reset()
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	stopped := make(chan struct{})
+	or.stopProcess = func() { close(stopped) }
+	or.mdi.On("Capabilities").Return(&database.Capabilities{Clustering: true})
+	or.mdi.On("AcquireLeadership", mock.Anything, "namespace:ns").Return(make(<-chan struct{}), nil)
+	or.mdm.On("Start").Return(nil)
+	or.mem.On("Start").Return(nil)
+	or.mom.On("Start").Return(nil)
+	or.mba.On("Start").Return(fmt.Errorf("pop"))
+	err := or.Start()
+	assert.NoError(t, err)
+	<-stopped
+	<-or.campaignDone
+}
+
+func TestStartClusteredStandbyStop(t *testing.T) {
+	coreconfig.Reset()
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("Capabilities").Return(&database.Capabilities{Clustering: true})
+	or.mdi.On("AcquireLeadership", mock.Anything, "namespace:ns").Run(func(args mock.Arguments) {
+		<-args[0].(context.Context).Done()
+	}).Return(nil, fmt.Errorf("pop"))
+	or.mdm.On("Start").Return(nil)
+	or.mem.On("Start").Return(nil)
+	or.mom.On("Start").Return(nil)
+	or.mbm.On("WaitStop").Return(nil)
+	or.mdm.On("WaitStop").Return(nil)
+	or.msd.On("WaitStop").Return(nil)
+	or.mom.On("WaitStop").Return(nil)
+	or.mem.On("WaitStop").Return(nil)
+	or.mrm.On("WaitStop").Return()
+	err := or.Start()
+	assert.NoError(t, err)
+	or.cancelCtx()
+	or.WaitStop()
+}
+
+func TestStartClusteredLeaderStop(t *testing.T) {
+	coreconfig.Reset()
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	elected := make(chan struct{})
+	or.mdi.On("Capabilities").Return(&database.Capabilities{Clustering: true})
+	or.mdi.On("AcquireLeadership", mock.Anything, "namespace:ns").Return(make(<-chan struct{}), nil)
+	or.mdm.On("Start").Return(nil)
+	or.mem.On("Start").Return(nil)
+	or.mom.On("Start").Return(nil)
+	or.mba.On("Start").Return(nil)
+	or.mbm.On("Start").Return(nil)
+	or.msd.On("Start").Return(nil)
+	or.mem.On("StartAggregator").Return()
+	or.mcm.On("Start").Return()
+	or.mrm.On("Start").Run(func(args mock.Arguments) { close(elected) }).Return()
+	or.mba.On("WaitStop").Return(nil)
+	or.mbm.On("WaitStop").Return(nil)
+	or.mdm.On("WaitStop").Return(nil)
+	or.msd.On("WaitStop").Return(nil)
+	or.mom.On("WaitStop").Return(nil)
+	or.mem.On("WaitStop").Return(nil)
+	or.mrm.On("WaitStop").Return()
+	or.mcm.On("WaitStop").Return()
+	err := or.Start()
+	assert.NoError(t, err)
+	<-elected
+	or.cancelCtx()
+	or.WaitStop()
+}
+
+func TestStartClusteredTwoProcesses(t *testing.T) {
+	coreconfig.Reset()
+	// Emulates the leadership lock in the database shared by both processes
+	lock := make(chan struct{}, 1)
+	elected := make(chan *testOrchestrator, 2)
+	newProcess := func() *testOrchestrator {
+		or := newTestOrchestrator()
+		or.mdi.On("Capabilities").Return(&database.Capabilities{Clustering: true})
+		or.mdi.On("AcquireLeadership", mock.Anything, "namespace:ns").Return(func(ctx context.Context, name string) (<-chan struct{}, error) {
+			select {
+			case lock <- struct{}{}:
+				go func() {
+					<-ctx.Done()
+					<-lock
+				}()
+				return make(chan struct{}), nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		})
+		or.mdm.On("Start").Return(nil)
+		or.mem.On("Start").Return(nil)
+		or.mom.On("Start").Return(nil)
+		or.mba.On("Start").Return(nil).Maybe()
+		or.mbm.On("Start").Return(nil).Maybe()
+		or.msd.On("Start").Return(nil).Maybe()
+		or.mem.On("StartAggregator").Return().Maybe()
+		or.mcm.On("Start").Return().Maybe()
+		or.mrm.On("Start").Run(func(args mock.Arguments) { elected <- or }).Return().Maybe()
+		or.mba.On("WaitStop").Return(nil).Maybe()
+		or.mbm.On("WaitStop").Return(nil)
+		or.mdm.On("WaitStop").Return(nil)
+		or.msd.On("WaitStop").Return(nil)
+		or.mom.On("WaitStop").Return(nil)
+		or.mem.On("WaitStop").Return(nil)
+		or.mrm.On("WaitStop").Return()
+		or.mcm.On("WaitStop").Return().Maybe()
+		return or
+	}
+	or1 := newProcess()
+	defer or1.cleanup(t)
+	or2 := newProcess()
+	defer or2.cleanup(t)
+
+	assert.NoError(t, or1.Start())
+	assert.NoError(t, or2.Start())
+
+	// Only one process runs the leader components
+	leader := <-elected
+	select {
+	case <-elected:
+		assert.Fail(t, "both processes elected")
+	case <-time.After(50 * time.Millisecond):
+	}
+	leader.mem.AssertCalled(t, "StartAggregator")
+
+	// The other process takes over when the leader stops
+	leader.cancelCtx()
+	leader.WaitStop()
+	standby := <-elected
+	assert.NotEqual(t, leader, standby)
+	standby.mem.AssertCalled(t, "StartAggregator")
+	standby.cancelCtx()
+	standby.WaitStop()
+}
+
 func TestNetworkAction(t *testing.T) {
 	or := newTestOrchestrator()
 	or.namespace.Name = core.LegacySystemNamespace
@@ -87,6 +87,12 @@ func (or *orchestrator) GetStatus(ctx context.Context) (status *core.NamespaceSt
 		},
 	}
 
+	schema, err := or.database().SchemaStatus(ctx)
+	if err != nil {
+		log.L(ctx).Warnf("Failed to query database schema version for status: %s", err)
+	}
+	status.Schema = schema
+
 	if or.config.Multiparty.Enabled {
 		status.Node = &core.NamespaceStatusNode{Name: or.config.Multiparty.Node.Name}
 		status.Org = &core.NamespaceStatusOrg{Name: or.config.Multiparty.Org.Name}
@@ -110,6 +110,7 @@ func TestGetStatusRegistered(t *testing.T) {
 	or.config.Multiparty.Org.Name = "org1"
 	or.config.Multiparty.Node.Name = "node1"
 
+	or.mdi.On("SchemaStatus", or.ctx).Return(&core.DatabaseSchemaStatus{Version: 3, Latest: 3}, nil)
 	or.mem.On("GetPlugins").Return(mockEventPlugins)
 
 	status, err := or.GetStatus(or.ctx)
@@ -126,6 +127,7 @@ func TestGetStatusRegistered(t *testing.T) {
 	assert.True(t, status.Node.Registered)
 	assert.Equal(t, *nodeID, *status.Node.ID)
 	assert.Equal(t, "0x12345", status.Org.Verifiers[0].Value)
+	assert.Equal(t, uint(3), status.Schema.Version)
 
 	// Plugins
 	assert.ElementsMatch(t, pluginsResult.Blockchain, status.Plugins.Blockchain)
@@ -156,6 +158,7 @@ func TestGetStatusVerifierLookupFail(t *testing.T) {
 	}, nil)
 	or.mdi.On("GetVerifiers", or.ctx, "ns", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
 
+	or.mdi.On("SchemaStatus", or.ctx).Return(&core.DatabaseSchemaStatus{Version: 3, Latest: 3}, nil)
 	or.mem.On("GetPlugins").Return(mockEventPlugins)
 
 	_, err := or.GetStatus(or.ctx)
@@ -198,6 +201,7 @@ func TestGetStatusWrongNodeOwner(t *testing.T) {
 	or.config.Multiparty.Org.Name = "org1"
 	or.config.Multiparty.Node.Name = "node1"
 
+	or.mdi.On("SchemaStatus", or.ctx).Return(&core.DatabaseSchemaStatus{Version: 3, Latest: 3}, nil)
 	or.mem.On("GetPlugins").Return(mockEventPlugins)
 
 	status, err := or.GetStatus(or.ctx)
@@ -228,6 +232,7 @@ func TestGetStatusUnregistered(t *testing.T) {
 	or.config.Multiparty.Org.Name = "org1"
 	or.config.Multiparty.Node.Name = "node1"
 
+	or.mdi.On("SchemaStatus", or.ctx).Return(nil, fmt.Errorf("pop"))
 	or.mem.On("GetPlugins").Return(mockEventPlugins)
 
 	status, err := or.GetStatus(or.ctx)
@@ -240,6 +245,7 @@ func TestGetStatusUnregistered(t *testing.T) {
 
 	assert.Equal(t, "node1", status.Node.Name)
 	assert.False(t, status.Node.Registered)
+	assert.Nil(t, status.Schema)
 
 }
 
@@ -271,6 +277,7 @@ func TestGetStatusOrgOnlyRegistered(t *testing.T) {
 	or.config.Multiparty.Org.Name = "org1"
 	or.config.Multiparty.Node.Name = "node1"
 
+	or.mdi.On("SchemaStatus", or.ctx).Return(&core.DatabaseSchemaStatus{Version: 3, Latest: 3}, nil)
 	or.mem.On("GetPlugins").Return(mockEventPlugins)
 
 	status, err := or.GetStatus(or.ctx)
@@ -322,6 +329,7 @@ func TestGetStatusNodeError(t *testing.T) {
 
 	or.config.Multiparty.Node.Name = "node1"
 
+	or.mdi.On("SchemaStatus", or.ctx).Return(&core.DatabaseSchemaStatus{Version: 3, Latest: 3}, nil)
 	or.mem.On("GetPlugins").Return(mockEventPlugins)
 
 	_, err := or.GetStatus(or.ctx)
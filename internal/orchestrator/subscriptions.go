@@ -46,21 +46,34 @@ func (or *orchestrator) createUpdateSubscription(ctx context.Context, subDef *co
 	if subDef.Transport == system.SystemEventsTransport {
 		return nil, i18n.NewError(ctx, coremsgs.MsgSystemTransportInternal)
 	}
+	// The encrypted form of a signing secret is not bound to the subscription it was encrypted for,
+	// so it must only ever come from the webhooks plugin encrypting an inline secret
+	if _, ok := subDef.Options.TransportOptions().GetObject("signing")["encryptedSecret"]; ok {
+		return nil, i18n.NewError(ctx, coremsgs.MsgWebhookSigningEncryptedSecretInput)
+	}
 
 	return subDef, or.events.CreateUpdateDurableSubscription(ctx, subDef, mustNew)
 }
 
-func (or *orchestrator) DeleteSubscription(ctx context.Context, id string) error {
+func (or *orchestrator) getExistingSubscription(ctx context.Context, id string) (*core.Subscription, error) {
 	u, err := fftypes.ParseUUID(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	sub, err := or.database().GetSubscriptionByID(ctx, or.namespace.Name, u)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if sub == nil {
-		return i18n.NewError(ctx, coremsgs.Msg404NotFound)
+		return nil, i18n.NewError(ctx, coremsgs.Msg404NotFound)
+	}
+	return sub, nil
+}
+
+func (or *orchestrator) DeleteSubscription(ctx context.Context, id string) error {
+	sub, err := or.getExistingSubscription(ctx, id)
+	if err != nil {
+		return err
 	}
 	return or.events.DeleteDurableSubscription(ctx, sub)
 }
@@ -103,3 +116,28 @@ func (or *orchestrator) GetSubscriptionByIDWithStatus(ctx context.Context, id st
 
 	return subWithStatus, nil
 }
+
+func (or *orchestrator) GetSubscriptionDeadLetters(ctx context.Context, id string, filter ffapi.AndFilter) ([]*core.DeadLetter, *ffapi.FilterResult, error) {
+	u, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	filter = filter.Condition(filter.Builder().Eq("subscription", u))
+	return or.database().GetDeadLetters(ctx, or.namespace.Name, filter)
+}
+
+func (or *orchestrator) ReplaySubscriptionDeadLetters(ctx context.Context, id string, replay *core.DeadLetterReplay) ([]*core.DeadLetter, error) {
+	sub, err := or.getExistingSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return or.events.ReplayDeadLetters(ctx, sub, replay.IDs)
+}
+
+func (or *orchestrator) DeleteSubscriptionDeadLetters(ctx context.Context, id string) error {
+	sub, err := or.getExistingSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+	return or.database().DeleteDeadLetters(ctx, or.namespace.Name, sub.ID)
+}
@@ -18,6 +18,7 @@ package orchestrator
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -54,6 +55,21 @@ func TestCreateSubscriptionSystemTransport(t *testing.T) {
 	assert.Regexp(t, "FF10266", err)
 }
 
+func TestCreateSubscriptionEncryptedSecret(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			Name: "sub1",
+		},
+	}
+	err := json.Unmarshal([]byte(`{"signing": {"encryptedSecret": "c2VjcmV0"}}`), &sub.Options)
+	assert.NoError(t, err)
+	_, err = or.CreateUpdateSubscription(or.ctx, sub)
+	assert.Regexp(t, "FF10533", err)
+}
+
 func TestCreateSubscriptionOk(t *testing.T) {
 	or := newTestOrchestrator()
 	defer or.cleanup(t)
@@ -217,3 +233,79 @@ func TestGetSGetSubscriptionsByIDWithStatusUnknownSub(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Nil(t, subWithStatus)
 }
+
+func TestGetSubscriptionDeadLetters(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	u := fftypes.NewUUID()
+	or.mdi.On("GetDeadLetters", mock.Anything, "ns", mock.Anything).Return([]*core.DeadLetter{}, nil, nil)
+	fb := database.DeadLetterQueryFactory.NewFilter(context.Background())
+	f := fb.And(fb.Eq("reason", "pop"))
+	_, _, err := or.GetSubscriptionDeadLetters(context.Background(), u.String(), f)
+	assert.NoError(t, err)
+	fi, err := f.Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("( reason == 'pop' ) && ( subscription == '%s' )", u), fi.String())
+}
+
+func TestGetSubscriptionDeadLettersBadUUID(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	fb := database.DeadLetterQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetSubscriptionDeadLetters(context.Background(), "! a UUID", fb.And())
+	assert.Regexp(t, "FF00138", err)
+}
+
+func TestReplaySubscriptionDeadLetters(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Name:      "sub1",
+			Namespace: "ns",
+		},
+	}
+	replay := &core.DeadLetterReplay{IDs: []*fftypes.UUID{fftypes.NewUUID()}}
+	or.mdi.On("GetSubscriptionByID", mock.Anything, "ns", sub.ID).Return(sub, nil)
+	or.mem.On("ReplayDeadLetters", mock.Anything, sub, replay.IDs).Return([]*core.DeadLetter{}, nil)
+	_, err := or.ReplaySubscriptionDeadLetters(or.ctx, sub.ID.String(), replay)
+	assert.NoError(t, err)
+}
+
+func TestReplaySubscriptionDeadLettersNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	or.mdi.On("GetSubscriptionByID", mock.Anything, "ns", mock.Anything).Return(nil, nil)
+	_, err := or.ReplaySubscriptionDeadLetters(or.ctx, fftypes.NewUUID().String(), &core.DeadLetterReplay{})
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestDeleteSubscriptionDeadLetters(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Name:      "sub1",
+			Namespace: "ns",
+		},
+	}
+	or.mdi.On("GetSubscriptionByID", mock.Anything, "ns", sub.ID).Return(sub, nil)
+	or.mdi.On("DeleteDeadLetters", mock.Anything, "ns", sub.ID).Return(nil)
+	err := or.DeleteSubscriptionDeadLetters(or.ctx, sub.ID.String())
+	assert.NoError(t, err)
+}
+
+func TestDeleteSubscriptionDeadLettersBadUUID(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	err := or.DeleteSubscriptionDeadLetters(or.ctx, "! a UUID")
+	assert.Regexp(t, "FF00138", err)
+}
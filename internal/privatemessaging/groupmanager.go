@@ -185,6 +185,8 @@ func (gm *groupManager) getGroupNodes(ctx context.Context, groupHash *fftypes.By
 		return ghe.group, ghe.nodes, nil
 	}
 
+	// The group and its nodes are cached for all callers, so are read from the primary
+	ctx = database.WithPrimary(ctx)
 	group, err := gm.database.GetGroupByHash(ctx, gm.namespace.Name, groupHash)
 	if err != nil || (allowNil && group == nil) {
 		return nil, nil, err
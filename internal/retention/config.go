@@ -0,0 +1,79 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+)
+
+const (
+	// RetentionInterval how often the retention policy is enforced
+	RetentionInterval = "interval"
+	// RetentionBatchSize the maximum number of records deleted in a single database transaction
+	RetentionBatchSize = "batchSize"
+	// RetentionMaxAge records of a collection older than this age are pruned
+	RetentionMaxAge = "maxAge"
+	// RetentionMaxRows records of a collection beyond this number of the newest are pruned
+	RetentionMaxRows = "maxRows"
+)
+
+// Config is the retention policy of a namespace
+type Config struct {
+	Interval  time.Duration
+	BatchSize int
+	Policies  []*Policy
+}
+
+// Policy is the retention policy of a single collection. A zero value for either limit means no limit.
+type Policy struct {
+	Collection string
+	MaxAge     time.Duration
+	MaxRows    int64
+}
+
+func InitConfig(conf config.Section) {
+	conf.AddKnownKey(RetentionInterval, "10m")
+	conf.AddKnownKey(RetentionBatchSize, 500)
+	for _, c := range collections {
+		collectionConf := conf.SubSection(c.name)
+		collectionConf.AddKnownKey(RetentionMaxAge)
+		collectionConf.AddKnownKey(RetentionMaxRows)
+	}
+}
+
+// ReadConfig reads the retention policy from the retention section of a namespace.
+// Only the collections with at least one limit configured are included.
+func ReadConfig(conf config.Section) Config {
+	rc := Config{
+		Interval:  conf.GetDuration(RetentionInterval),
+		BatchSize: conf.GetInt(RetentionBatchSize),
+	}
+	for _, c := range collections {
+		collectionConf := conf.SubSection(c.name)
+		policy := &Policy{
+			Collection: c.name,
+			MaxAge:     collectionConf.GetDuration(RetentionMaxAge),
+			MaxRows:    collectionConf.GetInt64(RetentionMaxRows),
+		}
+		if policy.MaxAge > 0 || policy.MaxRows > 0 {
+			rc.Policies = append(rc.Policies, policy)
+		}
+	}
+	return rc
+}
@@ -0,0 +1,54 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadConfig(t *testing.T) {
+	coreconfig.Reset()
+	conf := config.RootSection("retention")
+	InitConfig(conf)
+	conf.SubSection("events").Set(RetentionMaxAge, "24h")
+	conf.SubSection("operations").Set(RetentionMaxRows, 1000)
+	conf.SubSection("pins").Set(RetentionMaxAge, "1h")
+	conf.SubSection("pins").Set(RetentionMaxRows, 10)
+
+	rc := ReadConfig(conf)
+	assert.Equal(t, 10*time.Minute, rc.Interval)
+	assert.Equal(t, 500, rc.BatchSize)
+	assert.Equal(t, []*Policy{
+		{Collection: "events", MaxAge: 24 * time.Hour},
+		{Collection: "operations", MaxRows: 1000},
+		{Collection: "pins", MaxAge: time.Hour, MaxRows: 10},
+	}, rc.Policies)
+}
+
+func TestReadConfigNoPolicies(t *testing.T) {
+	coreconfig.Reset()
+	conf := config.RootSection("retention")
+	InitConfig(conf)
+
+	rc := ReadConfig(conf)
+	assert.Empty(t, rc.Policies)
+}
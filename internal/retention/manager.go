@@ -0,0 +1,278 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/metrics"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// Manager enforces the retention policy of a namespace, by periodically pruning old records
+type Manager interface {
+	Start()
+	WaitStop()
+	GetStatus(ctx context.Context) *core.RetentionStatus
+}
+
+type retentionManager struct {
+	ctx       context.Context
+	namespace string
+	conf      Config
+	database  database.Plugin
+	metrics   metrics.Manager
+	statusMux sync.Mutex
+	status    *core.RetentionStatus
+	done      chan struct{}
+}
+
+// collection describes how to prune a collection. The database never deletes the records that are still needed,
+// according to the retention guards of the namespace.
+type collection struct {
+	name         string
+	queryFactory *ffapi.QueryFields
+	// timeField is the age of a record
+	timeField string
+	// sortField orders the records from oldest to newest, to find the newest records to keep
+	sortField string
+	delete    func(di database.Plugin, ctx context.Context, namespace string, filter ffapi.Filter, guards *database.RetentionGuards) (int64, error)
+}
+
+var collections = []*collection{
+	{
+		name:         string(database.CollectionEvents),
+		queryFactory: database.EventQueryFactory,
+		timeField:    "created",
+		sortField:    "sequence",
+		delete:       database.Plugin.DeleteEvents,
+	},
+	{
+		name:         string(database.CollectionMessages),
+		queryFactory: database.MessageQueryFactory,
+		timeField:    "confirmed",
+		sortField:    "sequence",
+		delete:       database.Plugin.DeleteMessages,
+	},
+	{
+		name:         string(database.CollectionOperations),
+		queryFactory: database.OperationQueryFactory,
+		timeField:    "updated",
+		sortField:    "created",
+		delete:       database.Plugin.DeleteOperations,
+	},
+	{
+		name:         string(database.CollectionBatches),
+		queryFactory: database.BatchQueryFactory,
+		timeField:    "confirmed",
+		sortField:    "created",
+		delete:       database.Plugin.DeleteBatches,
+	},
+	{
+		name:         string(database.CollectionPins),
+		queryFactory: database.PinQueryFactory,
+		timeField:    "created",
+		sortField:    "sequence",
+		delete:       database.Plugin.DeletePins,
+	},
+	{
+		name:         string(database.CollectionBlockchainEvents),
+		queryFactory: database.BlockchainEventQueryFactory,
+		timeField:    "timestamp",
+		sortField:    "timestamp",
+		delete:       database.Plugin.DeleteBlockchainEvents,
+	},
+}
+
+func getCollection(name string) *collection {
+	for _, c := range collections {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func NewRetentionManager(ctx context.Context, ns string, conf Config, di database.Plugin, mm metrics.Manager) (Manager, error) {
+	if di == nil || mm == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgInitializationNilDepError, "RetentionManager")
+	}
+	rm := &retentionManager{
+		ctx:       log.WithLogField(ctx, "role", "retention"),
+		namespace: ns,
+		conf:      conf,
+		database:  di,
+		metrics:   mm,
+		status: &core.RetentionStatus{
+			Enabled:     len(conf.Policies) > 0,
+			Collections: make([]*core.RetentionCollectionStatus, len(conf.Policies)),
+		},
+	}
+	if rm.status.Enabled {
+		interval := fftypes.FFDuration(conf.Interval)
+		rm.status.Interval = &interval
+	}
+	for i, p := range conf.Policies {
+		rm.status.Collections[i] = &core.RetentionCollectionStatus{
+			Collection: p.Collection,
+			MaxRows:    p.MaxRows,
+		}
+		if p.MaxAge > 0 {
+			maxAge := fftypes.FFDuration(p.MaxAge)
+			rm.status.Collections[i].MaxAge = &maxAge
+		}
+	}
+	return rm, nil
+}
+
+func (rm *retentionManager) Start() {
+	if !rm.status.Enabled {
+		return
+	}
+	rm.done = make(chan struct{})
+	go rm.pruneLoop()
+}
+
+func (rm *retentionManager) WaitStop() {
+	if rm.done != nil {
+		<-rm.done
+	}
+}
+
+func (rm *retentionManager) GetStatus(ctx context.Context) *core.RetentionStatus {
+	rm.statusMux.Lock()
+	defer rm.statusMux.Unlock()
+	status := *rm.status
+	if status.Protected != nil {
+		protected := *status.Protected
+		status.Protected = &protected
+	}
+	status.Collections = make([]*core.RetentionCollectionStatus, len(rm.status.Collections))
+	for i, cs := range rm.status.Collections {
+		csCopy := *cs
+		status.Collections[i] = &csCopy
+	}
+	return &status
+}
+
+func (rm *retentionManager) pruneLoop() {
+	defer close(rm.done)
+	ticker := time.NewTicker(rm.conf.Interval)
+	defer ticker.Stop()
+	for {
+		rm.prune()
+		select {
+		case <-ticker.C:
+		case <-rm.ctx.Done():
+			log.L(rm.ctx).Debugf("Retention manager exiting")
+			return
+		}
+	}
+}
+
+func (rm *retentionManager) prune() {
+	g, err := rm.database.GetRetentionGuards(rm.ctx, rm.namespace)
+
+	rm.statusMux.Lock()
+	rm.status.LastRun = fftypes.Now()
+	rm.status.LastError = ""
+	if err != nil {
+		rm.status.LastError = err.Error()
+	} else {
+		rm.status.Protected = &core.RetentionProtected{
+			PinSequence: g.PinSequence,
+		}
+		if g.EventSequence != math.MaxInt64 {
+			eventSequence := g.EventSequence
+			rm.status.Protected.EventSequence = &eventSequence
+		}
+	}
+	rm.statusMux.Unlock()
+	if err != nil {
+		// Without the guards we cannot know what is still needed, so nothing is pruned until the next run
+		log.L(rm.ctx).Errorf("Failed to determine the records still needed by the node: %s", err)
+		return
+	}
+
+	for i, p := range rm.conf.Policies {
+		count, err := rm.pruneCollection(rm.ctx, p, g)
+		if err != nil {
+			log.L(rm.ctx).Errorf("Failed to prune %s: %s", p.Collection, err)
+		} else if count > 0 {
+			log.L(rm.ctx).Infof("Pruned %d %s", count, p.Collection)
+		}
+		if count > 0 && rm.metrics.IsMetricsEnabled() {
+			rm.metrics.RecordsPruned(rm.namespace, p.Collection, count)
+		}
+
+		rm.statusMux.Lock()
+		cs := rm.status.Collections[i]
+		cs.LastPruned = count
+		cs.TotalPruned += count
+		cs.LastError = ""
+		if err != nil {
+			cs.LastError = err.Error()
+		}
+		rm.statusMux.Unlock()
+	}
+}
+
+func (rm *retentionManager) pruneCollection(ctx context.Context, p *Policy, g *database.RetentionGuards) (total int64, err error) {
+	c := getCollection(p.Collection)
+	if p.MaxAge > 0 {
+		cutoff := fftypes.FFTime(time.Now().Add(-p.MaxAge))
+		total, err = rm.pruneUntilDone(ctx, c, g, &cutoff, 0)
+	}
+	if err == nil && p.MaxRows > 0 {
+		var count int64
+		count, err = rm.pruneUntilDone(ctx, c, g, nil, p.MaxRows)
+		total += count
+	}
+	return total, err
+}
+
+// pruneUntilDone deletes batches of records until there are no more to prune. Records are pruned if they are
+// older than the cutoff (if set), or beyond the newest records to keep (if set), but only where the guards
+// show they are no longer needed.
+func (rm *retentionManager) pruneUntilDone(ctx context.Context, c *collection, g *database.RetentionGuards, cutoff *fftypes.FFTime, keep int64) (total int64, err error) {
+	for {
+		fb := c.queryFactory.NewFilter(ctx)
+		conditions := fb.And()
+		if cutoff != nil {
+			conditions = conditions.Condition(fb.Lt(c.timeField, cutoff))
+		}
+		filter := conditions.Limit(uint64(rm.conf.BatchSize))
+		if keep > 0 {
+			// Skip the newest records, which are the ones to keep
+			filter = filter.Sort(c.sortField).Descending().Skip(uint64(keep))
+		}
+		count, err := c.delete(rm.database, ctx, rm.namespace, filter, g)
+		total += count
+		if err != nil || count < int64(rm.conf.BatchSize) || ctx.Err() != nil {
+			return total, err
+		}
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestRetentionManager(t *testing.T, policies ...*Policy) (*retentionManager, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	mdi := &databasemocks.Plugin{}
+	mmi := &metricsmocks.Manager{}
+	rm, err := NewRetentionManager(ctx, "ns1", Config{
+		Interval:  time.Minute,
+		BatchSize: 10,
+		Policies:  policies,
+	}, mdi, mmi)
+	assert.NoError(t, err)
+	return rm.(*retentionManager), func() {
+		cancel()
+		mdi.AssertExpectations(t)
+		mmi.AssertExpectations(t)
+	}
+}
+
+func filterString(t *testing.T, filter ffapi.Filter) string {
+	fi, err := filter.Finalize()
+	assert.NoError(t, err)
+	return fi.String()
+}
+
+func mockGuards(mdi *databasemocks.Plugin) *database.RetentionGuards {
+	g := &database.RetentionGuards{EventSequence: 100, PinSequence: 50}
+	mdi.On("GetRetentionGuards", mock.Anything, "ns1").Return(g, nil)
+	return g
+}
+
+func TestNewRetentionManagerMissingDeps(t *testing.T) {
+	_, err := NewRetentionManager(context.Background(), "ns1", Config{}, nil, nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestStartDisabled(t *testing.T) {
+	rm, cancel := newTestRetentionManager(t)
+	defer cancel()
+
+	rm.Start()
+	rm.WaitStop()
+
+	status := rm.GetStatus(context.Background())
+	assert.False(t, status.Enabled)
+	assert.Nil(t, status.Interval)
+	assert.Nil(t, status.LastRun)
+}
+
+func TestPruneLoop(t *testing.T) {
+	rm, cancel := newTestRetentionManager(t, &Policy{Collection: "blockchainevents", MaxAge: time.Hour})
+	defer cancel()
+	mdi := rm.database.(*databasemocks.Plugin)
+	mmi := rm.metrics.(*metricsmocks.Manager)
+
+	g := mockGuards(mdi)
+	pruned := make(chan struct{})
+	mdi.On("DeleteBlockchainEvents", mock.Anything, "ns1", mock.Anything, g).Return(int64(1), nil).Run(func(args mock.Arguments) {
+		close(pruned)
+	}).Once()
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("RecordsPruned", "ns1", "blockchainevents", int64(1)).Return()
+
+	rm.Start()
+	<-pruned
+	cancel()
+	rm.WaitStop()
+
+	status := rm.GetStatus(context.Background())
+	assert.True(t, status.Enabled)
+	assert.Equal(t, fftypes.FFDuration(time.Minute), *status.Interval)
+	assert.Equal(t, fftypes.FFDuration(time.Hour), *status.Collections[0].MaxAge)
+}
+
+func TestPruneAllCollections(t *testing.T) {
+	policies := make([]*Policy, len(collections))
+	for i, c := range collections {
+		policies[i] = &Policy{Collection: c.name, MaxAge: time.Hour, MaxRows: 5}
+	}
+	rm, cancel := newTestRetentionManager(t, policies...)
+	defer cancel()
+	mdi := rm.database.(*databasemocks.Plugin)
+	mmi := rm.metrics.(*metricsmocks.Manager)
+
+	g := mockGuards(mdi)
+	for _, c := range collections {
+		method := map[string]string{
+			"events":           "DeleteEvents",
+			"messages":         "DeleteMessages",
+			"operations":       "DeleteOperations",
+			"batches":          "DeleteBatches",
+			"pins":             "DeletePins",
+			"blockchainevents": "DeleteBlockchainEvents",
+		}[c.name]
+		ageFilter := fmt.Sprintf("( %s << ", c.timeField)
+		rowsFilter := fmt.Sprintf("sort=-%s skip=5 limit=10", c.sortField)
+		// Age pass fills a batch, so it is repeated
+		mdi.On(method, mock.Anything, "ns1", mock.MatchedBy(func(filter ffapi.Filter) bool {
+			return strings.HasPrefix(filterString(t, filter), ageFilter)
+		}), g).Return(int64(10), nil).Once()
+		mdi.On(method, mock.Anything, "ns1", mock.MatchedBy(func(filter ffapi.Filter) bool {
+			return strings.HasPrefix(filterString(t, filter), ageFilter)
+		}), g).Return(int64(2), nil).Once()
+		mdi.On(method, mock.Anything, "ns1", mock.MatchedBy(func(filter ffapi.Filter) bool {
+			return strings.HasSuffix(filterString(t, filter), rowsFilter)
+		}), g).Return(int64(1), nil).Once()
+	}
+	mmi.On("IsMetricsEnabled").Return(false)
+
+	rm.prune()
+
+	status := rm.GetStatus(context.Background())
+	assert.NotNil(t, status.LastRun)
+	assert.Empty(t, status.LastError)
+	assert.Equal(t, int64(100), *status.Protected.EventSequence)
+	assert.Equal(t, int64(50), status.Protected.PinSequence)
+	for _, cs := range status.Collections {
+		assert.Equal(t, int64(13), cs.LastPruned)
+		assert.Equal(t, int64(13), cs.TotalPruned)
+		assert.Empty(t, cs.LastError)
+	}
+}
+
+func TestPruneDeleteFail(t *testing.T) {
+	rm, cancel := newTestRetentionManager(t, &Policy{Collection: "events", MaxAge: time.Hour, MaxRows: 5})
+	defer cancel()
+	mdi := rm.database.(*databasemocks.Plugin)
+	mmi := rm.metrics.(*metricsmocks.Manager)
+
+	g := mockGuards(mdi)
+	mdi.On("DeleteEvents", mock.Anything, "ns1", mock.Anything, g).Return(int64(3), fmt.Errorf("pop")).Once()
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("RecordsPruned", "ns1", "events", int64(3)).Return()
+
+	rm.prune()
+
+	status := rm.GetStatus(context.Background())
+	assert.Equal(t, int64(3), status.Collections[0].LastPruned)
+	assert.Regexp(t, "pop", status.Collections[0].LastError)
+}
+
+func TestPruneGuardsFail(t *testing.T) {
+	rm, cancel := newTestRetentionManager(t, &Policy{Collection: "events", MaxAge: time.Hour})
+	defer cancel()
+	mdi := rm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetRetentionGuards", mock.Anything, "ns1").Return(nil, fmt.Errorf("pop"))
+
+	rm.prune()
+
+	status := rm.GetStatus(context.Background())
+	assert.NotNil(t, status.LastRun)
+	assert.Regexp(t, "pop", status.LastError)
+	assert.Nil(t, status.Protected)
+}
+
+func TestPruneNoSubscriptions(t *testing.T) {
+	rm, cancel := newTestRetentionManager(t)
+	defer cancel()
+	mdi := rm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetRetentionGuards", mock.Anything, "ns1").Return(&database.RetentionGuards{EventSequence: math.MaxInt64, PinSequence: -1}, nil)
+
+	rm.status.Enabled = true
+	rm.prune()
+	status := rm.GetStatus(context.Background())
+	assert.Nil(t, status.Protected.EventSequence)
+	assert.Equal(t, int64(-1), status.Protected.PinSequence)
+}
+
+func TestGetCollectionUnknown(t *testing.T) {
+	assert.Nil(t, getCollection("unknown"))
+}
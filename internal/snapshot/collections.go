@@ -0,0 +1,449 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// aggregatorOffsetName must match the offset the aggregator in the events package uses to track its progress through the pins
+const aggregatorOffsetName = "ff_aggregator"
+
+// collection describes how to export a collection a page at a time, and how to verify and restore each page on import
+type collection struct {
+	name         string
+	queryFactory ffapi.QueryFactory
+	// sort gives a stable order for paging through the collection
+	sort []string
+	// global collections are not scoped to the namespace, so are not required to be empty on import
+	global bool
+	list   func(ctx context.Context, sm *snapshotManager, filter ffapi.AndFilter) (records interface{}, count int, err error)
+	// exportContent writes any additional entries needed for a page of records, after the page itself
+	exportContent func(ctx context.Context, sm *snapshotManager, ex *exporter, records interface{}) error
+	newPage       func() interface{}
+	restore       func(ctx context.Context, sm *snapshotManager, im *importer, page interface{}) error
+}
+
+// collections are exported and imported in this order
+var collections = []*collection{
+	{
+		name:         "datatypes",
+		queryFactory: database.DatatypeQueryFactory,
+		sort:         []string{"id"},
+		list: func(ctx context.Context, sm *snapshotManager, filter ffapi.AndFilter) (interface{}, int, error) {
+			records, _, err := sm.database.GetDatatypes(ctx, sm.namespace, filter)
+			return records, len(records), err
+		},
+		newPage: func() interface{} { return &[]*core.Datatype{} },
+		restore: func(ctx context.Context, sm *snapshotManager, im *importer, page interface{}) error {
+			for _, dt := range *page.(*[]*core.Datatype) {
+				if dt.Hash != nil {
+					if hash := dt.Value.Hash(); !hash.Equals(dt.Hash) {
+						return i18n.NewError(ctx, coremsgs.MsgSnapshotHashMismatch, "datatype", dt.ID, dt.Hash, hash)
+					}
+				}
+				if err := sm.database.UpsertDatatype(ctx, dt, false); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		name:         "ffis",
+		queryFactory: database.FFIQueryFactory,
+		sort:         []string{"id"},
+		list: func(ctx context.Context, sm *snapshotManager, filter ffapi.AndFilter) (interface{}, int, error) {
+			records, _, err := sm.database.GetFFIs(ctx, sm.namespace, filter)
+			return records, len(records), err
+		},
+		newPage: func() interface{} { return &[]*fftypes.FFI{} },
+		restore: func(ctx context.Context, sm *snapshotManager, im *importer, page interface{}) error {
+			for _, ffi := range *page.(*[]*fftypes.FFI) {
+				if err := sm.database.UpsertFFI(ctx, ffi); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		name:         "ffimethods",
+		queryFactory: database.FFIMethodQueryFactory,
+		sort:         []string{"id"},
+		list: func(ctx context.Context, sm *snapshotManager, filter ffapi.AndFilter) (interface{}, int, error) {
+			records, _, err := sm.database.GetFFIMethods(ctx, sm.namespace, filter)
+			return records, len(records), err
+		},
+		newPage: func() interface{} { return &[]*fftypes.FFIMethod{} },
+		restore: func(ctx context.Context, sm *snapshotManager, im *importer, page interface{}) error {
+			for _, method := range *page.(*[]*fftypes.FFIMethod) {
+				if err := sm.database.UpsertFFIMethod(ctx, method); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		name:         "ffievents",
+		queryFactory: database.FFIEventQueryFactory,
+		sort:         []string{"id"},
+		list: func(ctx context.Context, sm *snapshotManager, filter ffapi.AndFilter) (interface{}, int, error) {
+			records, _, err := sm.database.GetFFIEvents(ctx, sm.namespace, filter)
+			return records, len(records), err
+		},
+		newPage: func() interface{} { return &[]*fftypes.FFIEvent{} },
+		restore: func(ctx context.Context, sm *snapshotManager, im *importer, page interface{}) error {
+			for _, event := range *page.(*[]*fftypes.FFIEvent) {
+				if err := sm.database.UpsertFFIEvent(ctx, event); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		name:         "ffierrors",
+		queryFactory: database.FFIErrorQueryFactory,
+		sort:         []string{"id"},
+		list: func(ctx context.Context, sm *snapshotManager, filter ffapi.AndFilter) (interface{}, int, error) {
+			records, _, err := sm.database.GetFFIErrors(ctx, sm.namespace, filter)
+			return records, len(records), err
+		},
+		newPage: func() interface{} { return &[]*fftypes.FFIError{} },
+		restore: func(ctx context.Context, sm *snapshotManager, im *importer, page interface{}) error {
+			for _, ffiError := range *page.(*[]*fftypes.FFIError) {
+				if err := sm.database.UpsertFFIError(ctx, ffiError); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		name:         "contractapis",
+		queryFactory: database.ContractAPIQueryFactory,
+		sort:         []string{"id"},
+		list: func(ctx context.Context, sm *snapshotManager, filter ffapi.AndFilter) (interface{}, int, error) {
+			records, _, err := sm.database.GetContractAPIs(ctx, sm.namespace, filter)
+			return records, len(records), err
+		},
+		newPage: func() interface{} { return &[]*core.ContractAPI{} },
+		restore: func(ctx context.Context, sm *snapshotManager, im *importer, page interface{}) error {
+			for _, api := range *page.(*[]*core.ContractAPI) {
+				if err := sm.database.UpsertContractAPI(ctx, api); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		name:         "identities",
+		queryFactory: database.IdentityQueryFactory,
+		sort:         []string{"id"},
+		list: func(ctx context.Context, sm *snapshotManager, filter ffapi.AndFilter) (interface{}, int, error) {
+			records, _, err := sm.database.GetIdentities(ctx, sm.namespace, filter)
+			return records, len(records), err
+		},
+		newPage: func() interface{} { return &[]*core.Identity{} },
+		restore: func(ctx context.Context, sm *snapshotManager, im *importer, page interface{}) error {
+			for _, identity := range *page.(*[]*core.Identity) {
+				if err := sm.database.UpsertIdentity(ctx, identity, database.UpsertOptimizationNew); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		name:         "verifiers",
+		queryFactory: database.VerifierQueryFactory,
+		sort:         []string{"hash"},
+		list: func(ctx context.Context, sm *snapshotManager, filter ffapi.AndFilter) (interface{}, int, error) {
+			records, _, err := sm.database.GetVerifiers(ctx, sm.namespace, filter)
+			return records, len(records), err
+		},
+		newPage: func() interface{} { return &[]*core.Verifier{} },
+		restore: func(ctx context.Context, sm *snapshotManager, im *importer, page interface{}) error {
+			for _, verifier := range *page.(*[]*core.Verifier) {
+				sealed := *verifier
+				if hash := sealed.Seal().Hash; !hash.Equals(verifier.Hash) {
+					return i18n.NewError(ctx, coremsgs.MsgSnapshotHashMismatch, "verifier", verifier.Value, verifier.Hash, hash)
+				}
+				if err := sm.database.UpsertVerifier(ctx, verifier, database.UpsertOptimizationNew); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		name:         "groups",
+		queryFactory: database.GroupQueryFactory,
+		sort:         []string{"hash"},
+		list: func(ctx context.Context, sm *snapshotManager, filter ffapi.AndFilter) (interface{}, int, error) {
+			records, _, err := sm.database.GetGroups(ctx, sm.namespace, filter)
+			return records, len(records), err
+		},
+		newPage: func() interface{} { return &[]*core.Group{} },
+		restore: func(ctx context.Context, sm *snapshotManager, im *importer, page interface{}) error {
+			for _, group := range *page.(*[]*core.Group) {
+				if hash := group.GroupIdentity.Hash(); !hash.Equals(group.Hash) {
+					return i18n.NewError(ctx, coremsgs.MsgSnapshotHashMismatch, "group", group.Name, group.Hash, hash)
+				}
+				if err := sm.database.UpsertGroup(ctx, group, database.UpsertOptimizationNew); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		name:         "data",
+		queryFactory: database.DataQueryFactory,
+		sort:         []string{"id"},
+		list: func(ctx context.Context, sm *snapshotManager, filter ffapi.AndFilter) (interface{}, int, error) {
+			records, _, err := sm.database.GetData(ctx, sm.namespace, filter, nil)
+			return records, len(records), err
+		},
+		newPage: func() interface{} { return &core.DataArray{} },
+		restore: func(ctx context.Context, sm *snapshotManager, im *importer, page interface{}) error {
+			data := *page.(*core.DataArray)
+			for _, d := range data {
+				hash, err := d.CalcHash(ctx)
+				if err != nil {
+					return err
+				}
+				if !hash.Equals(d.Hash) {
+					return i18n.NewError(ctx, coremsgs.MsgSnapshotHashMismatch, "data", d.ID, d.Hash, hash)
+				}
+			}
+			return sm.database.InsertDataArray(ctx, data)
+		},
+	},
+	{
+		name:         "blobs",
+		queryFactory: database.BlobQueryFactory,
+		sort:         []string{"data_id", "hash"},
+		list: func(ctx context.Context, sm *snapshotManager, filter ffapi.AndFilter) (interface{}, int, error) {
+			records, _, err := sm.database.GetBlobs(ctx, sm.namespace, filter)
+			return records, len(records), err
+		},
+		exportContent: func(ctx context.Context, sm *snapshotManager, ex *exporter, records interface{}) error {
+			if sm.dataexchange == nil {
+				return i18n.NewError(ctx, coremsgs.MsgSnapshotNoDataExchange, sm.namespace)
+			}
+			for _, blob := range records.([]*core.Blob) {
+				if err := exportBlob(ctx, sm, ex, blob); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		newPage: func() interface{} { return &[]*core.Blob{} },
+		restore: func(ctx context.Context, sm *snapshotManager, im *importer, page interface{}) error {
+			// The blob records are stored once their content has been uploaded, as the payload reference will change
+			blobs := *page.(*[]*core.Blob)
+			if len(blobs) > 0 && sm.dataexchange == nil {
+				return i18n.NewError(ctx, coremsgs.MsgSnapshotNoDataExchange, sm.namespace)
+			}
+			im.pendingBlobs = append(im.pendingBlobs, blobs...)
+			return nil
+		},
+	},
+	{
+		name:         "messages",
+		queryFactory: database.MessageQueryFactory,
+		sort:         []string{"sequence"},
+		list: func(ctx context.Context, sm *snapshotManager, filter ffapi.AndFilter) (interface{}, int, error) {
+			records, _, err := sm.database.GetMessages(ctx, sm.namespace, filter, nil)
+			return records, len(records), err
+		},
+		newPage: func() interface{} { return &[]*core.Message{} },
+		restore: func(ctx context.Context, sm *snapshotManager, im *importer, page interface{}) error {
+			msgs := *page.(*[]*core.Message)
+			for _, msg := range msgs {
+				if hash := msg.Header.Hash(); !hash.Equals(msg.Hash) {
+					return i18n.NewError(ctx, coremsgs.MsgSnapshotHashMismatch, "message", msg.Header.ID, msg.Hash, hash)
+				}
+				if dataHash := msg.Data.Hash(); !dataHash.Equals(msg.Header.DataHash) {
+					return i18n.NewError(ctx, coremsgs.MsgSnapshotHashMismatch, "message data", msg.Header.ID, msg.Header.DataHash, dataHash)
+				}
+			}
+			return sm.database.InsertMessages(ctx, msgs)
+		},
+	},
+	{
+		name:         "batches",
+		queryFactory: database.BatchQueryFactory,
+		sort:         []string{"id"},
+		list: func(ctx context.Context, sm *snapshotManager, filter ffapi.AndFilter) (interface{}, int, error) {
+			records, _, err := sm.database.GetBatches(ctx, sm.namespace, filter)
+			return records, len(records), err
+		},
+		newPage: func() interface{} { return &[]*core.BatchPersisted{} },
+		restore: func(ctx context.Context, sm *snapshotManager, im *importer, page interface{}) error {
+			for _, batch := range *page.(*[]*core.BatchPersisted) {
+				if _, err := sm.database.InsertOrGetBatch(ctx, batch); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		name:         "tokenpools",
+		queryFactory: database.TokenPoolQueryFactory,
+		sort:         []string{"id"},
+		list: func(ctx context.Context, sm *snapshotManager, filter ffapi.AndFilter) (interface{}, int, error) {
+			records, _, err := sm.database.GetTokenPools(ctx, sm.namespace, filter)
+			return records, len(records), err
+		},
+		newPage: func() interface{} { return &[]*core.TokenPool{} },
+		restore: func(ctx context.Context, sm *snapshotManager, im *importer, page interface{}) error {
+			for _, pool := range *page.(*[]*core.TokenPool) {
+				if err := sm.database.UpsertTokenPool(ctx, pool); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		name:         "tokentransfers",
+		queryFactory: database.TokenTransferQueryFactory,
+		sort:         []string{"localid"},
+		list: func(ctx context.Context, sm *snapshotManager, filter ffapi.AndFilter) (interface{}, int, error) {
+			records, _, err := sm.database.GetTokenTransfers(ctx, sm.namespace, filter)
+			return records, len(records), err
+		},
+		newPage: func() interface{} { return &[]*core.TokenTransfer{} },
+		restore: func(ctx context.Context, sm *snapshotManager, im *importer, page interface{}) error {
+			for _, transfer := range *page.(*[]*core.TokenTransfer) {
+				if err := sm.database.UpsertTokenTransfer(ctx, transfer); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		name:         "tokenbalances",
+		queryFactory: database.TokenBalanceQueryFactory,
+		sort:         []string{"pool", "tokenindex", "key"},
+		list: func(ctx context.Context, sm *snapshotManager, filter ffapi.AndFilter) (interface{}, int, error) {
+			records, _, err := sm.database.GetTokenBalances(ctx, sm.namespace, filter)
+			return records, len(records), err
+		},
+		newPage: func() interface{} { return &[]*core.TokenBalance{} },
+		restore: func(ctx context.Context, sm *snapshotManager, im *importer, page interface{}) error {
+			// Balances can only be written by applying a transfer, so each balance is restored as a single mint
+			// to the account. This gives the exported balance, even where older transfers have been pruned.
+			for _, balance := range *page.(*[]*core.TokenBalance) {
+				if err := sm.database.UpdateTokenBalances(ctx, &core.TokenTransfer{
+					Type:       core.TokenTransferTypeMint,
+					Pool:       balance.Pool,
+					TokenIndex: balance.TokenIndex,
+					URI:        balance.URI,
+					Connector:  balance.Connector,
+					Namespace:  balance.Namespace,
+					To:         balance.Key,
+					Amount:     balance.Balance,
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		name:         "nextpins",
+		queryFactory: database.NextPinQueryFactory,
+		sort:         []string{"context", "identity", "hash"},
+		list: func(ctx context.Context, sm *snapshotManager, filter ffapi.AndFilter) (interface{}, int, error) {
+			records, _, err := sm.database.GetNextPins(ctx, sm.namespace, filter)
+			return records, len(records), err
+		},
+		newPage: func() interface{} { return &[]*core.NextPin{} },
+		restore: func(ctx context.Context, sm *snapshotManager, im *importer, page interface{}) error {
+			for _, nextpin := range *page.(*[]*core.NextPin) {
+				if err := sm.database.InsertNextPin(ctx, nextpin); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		name:         "offsets",
+		queryFactory: database.OffsetQueryFactory,
+		sort:         []string{"type", "name"},
+		global:       true,
+		list: func(ctx context.Context, sm *snapshotManager, filter ffapi.AndFilter) (interface{}, int, error) {
+			// Offsets are not namespaced, so we export those of the aggregator and of the subscriptions in the namespace
+			subs, _, err := sm.database.GetSubscriptions(ctx, sm.namespace, database.SubscriptionQueryFactory.NewFilter(ctx).And())
+			if err != nil {
+				return nil, 0, err
+			}
+			names := []driver.Value{aggregatorOffsetName}
+			for _, sub := range subs {
+				names = append(names, sub.ID.String())
+			}
+			records, _, err := sm.database.GetOffsets(ctx, filter.Condition(filter.Builder().In("name", names)))
+			return records, len(records), err
+		},
+		newPage: func() interface{} { return &[]*core.Offset{} },
+		restore: func(ctx context.Context, sm *snapshotManager, im *importer, page interface{}) error {
+			// Offsets record positions in sequences that are local to the exporting database, and the events and pins
+			// they refer to are not part of the snapshot. So they are restored to the start, unless they already exist.
+			for _, offset := range *page.(*[]*core.Offset) {
+				existing, err := sm.database.GetOffset(ctx, offset.Type, offset.Name)
+				if err != nil {
+					return err
+				}
+				if existing == nil {
+					offset.Current = -1
+					if err := sm.database.UpsertOffset(ctx, offset, false); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	},
+}
+
+func exportBlob(ctx context.Context, sm *snapshotManager, ex *exporter, blob *core.Blob) error {
+	reader, err := sm.dataexchange.DownloadBlob(ctx, blob.PayloadRef)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	ex.blobs++
+	return ex.writeEntry(fmt.Sprintf("%s/%06d", blobDataDir, ex.blobs), blob.Size, reader)
+}
@@ -0,0 +1,130 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func getCollection(name string) *collection {
+	for _, c := range collections {
+		if c.name == name {
+			return c
+		}
+	}
+	panic(name)
+}
+
+func TestRestoreDatabaseErrors(t *testing.T) {
+	r := newTestRecords()
+	for _, tc := range []struct {
+		collection string
+		page       interface{}
+		method     string
+		returns    []interface{}
+	}{
+		{"datatypes", &[]*core.Datatype{r.datatype}, "UpsertDatatype", []interface{}{fmt.Errorf("pop")}},
+		{"ffis", &[]*fftypes.FFI{r.ffi}, "UpsertFFI", []interface{}{fmt.Errorf("pop")}},
+		{"ffimethods", &[]*fftypes.FFIMethod{r.method}, "UpsertFFIMethod", []interface{}{fmt.Errorf("pop")}},
+		{"ffievents", &[]*fftypes.FFIEvent{r.event}, "UpsertFFIEvent", []interface{}{fmt.Errorf("pop")}},
+		{"ffierrors", &[]*fftypes.FFIError{r.ffiError}, "UpsertFFIError", []interface{}{fmt.Errorf("pop")}},
+		{"contractapis", &[]*core.ContractAPI{r.api}, "UpsertContractAPI", []interface{}{fmt.Errorf("pop")}},
+		{"identities", &[]*core.Identity{r.identity}, "UpsertIdentity", []interface{}{fmt.Errorf("pop")}},
+		{"verifiers", &[]*core.Verifier{r.verifier}, "UpsertVerifier", []interface{}{fmt.Errorf("pop")}},
+		{"groups", &[]*core.Group{r.group}, "UpsertGroup", []interface{}{fmt.Errorf("pop")}},
+		{"data", &r.data, "InsertDataArray", []interface{}{fmt.Errorf("pop")}},
+		{"messages", &[]*core.Message{r.message}, "InsertMessages", []interface{}{fmt.Errorf("pop")}},
+		{"batches", &[]*core.BatchPersisted{r.batch}, "InsertOrGetBatch", []interface{}{nil, fmt.Errorf("pop")}},
+		{"tokenpools", &[]*core.TokenPool{r.pool}, "UpsertTokenPool", []interface{}{fmt.Errorf("pop")}},
+		{"tokentransfers", &[]*core.TokenTransfer{r.transfer}, "UpsertTokenTransfer", []interface{}{fmt.Errorf("pop")}},
+		{"tokenbalances", &[]*core.TokenBalance{r.balance}, "UpdateTokenBalances", []interface{}{fmt.Errorf("pop")}},
+		{"nextpins", &[]*core.NextPin{r.nextpin}, "InsertNextPin", []interface{}{fmt.Errorf("pop")}},
+		{"offsets", &[]*core.Offset{r.offset}, "GetOffset", []interface{}{nil, fmt.Errorf("pop")}},
+	} {
+		sm, mdi, _ := newTestSnapshotManager(t)
+		mdi.On(tc.method, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tc.returns...).Maybe()
+		mdi.On(tc.method, mock.Anything, mock.Anything, mock.Anything).Return(tc.returns...).Maybe()
+		mdi.On(tc.method, mock.Anything, mock.Anything).Return(tc.returns...).Maybe()
+		err := getCollection(tc.collection).restore(context.Background(), sm, &importer{}, tc.page)
+		assert.EqualError(t, err, "pop", tc.collection)
+	}
+}
+
+func TestRestoreOffsetUpsertFail(t *testing.T) {
+	r := newTestRecords()
+	sm, mdi, _ := newTestSnapshotManager(t)
+	mdi.On("GetOffset", mock.Anything, r.offset.Type, r.offset.Name).Return(nil, nil)
+	mdi.On("UpsertOffset", mock.Anything, r.offset, false).Return(fmt.Errorf("pop"))
+	err := getCollection("offsets").restore(context.Background(), sm, &importer{}, &[]*core.Offset{r.offset})
+	assert.EqualError(t, err, "pop")
+	mdi.AssertExpectations(t)
+}
+
+func TestRestoreHashMismatch(t *testing.T) {
+	r := newTestRecords()
+	r.datatype.Hash = fftypes.NewRandB32()
+	r.verifier.Hash = fftypes.NewRandB32()
+	r.group.Hash = fftypes.NewRandB32()
+	r.data[0].Hash = fftypes.NewRandB32()
+	badHeader := *r.message
+	badHeader.Hash = fftypes.NewRandB32()
+	badData := *r.message
+	badData.Data = core.DataRefs{}
+	for _, tc := range []struct {
+		collection string
+		page       interface{}
+		err        string
+	}{
+		{"datatypes", &[]*core.Datatype{r.datatype}, "FF10493.*datatype"},
+		{"verifiers", &[]*core.Verifier{r.verifier}, "FF10493.*verifier"},
+		{"groups", &[]*core.Group{r.group}, "FF10493.*group"},
+		{"data", &r.data, "FF10493.*data"},
+		{"data", &core.DataArray{{ID: fftypes.NewUUID()}}, "FF00109"},
+		{"messages", &[]*core.Message{&badHeader}, "FF10493.*message"},
+		{"messages", &[]*core.Message{&badData}, "FF10493.*message data"},
+	} {
+		sm, _, _ := newTestSnapshotManager(t)
+		err := getCollection(tc.collection).restore(context.Background(), sm, &importer{}, tc.page)
+		assert.Regexp(t, tc.err, err, tc.collection)
+	}
+}
+
+func TestRestoreBlobsNoDataExchange(t *testing.T) {
+	r := newTestRecords()
+	sm, _, _ := newTestSnapshotManager(t)
+	sm.dataexchange = nil
+	err := getCollection("blobs").restore(context.Background(), sm, &importer{}, &[]*core.Blob{r.blob})
+	assert.Regexp(t, "FF10494", err)
+}
+
+func TestExportBlobShort(t *testing.T) {
+	r := newTestRecords()
+	r.blob.Size = 1000
+	sm, mdi, mdx := newTestSnapshotManager(t)
+	mockExport(mdi, mdx, r)
+
+	err := sm.Export(context.Background(), &bytes.Buffer{})
+	assert.Regexp(t, "missed writing", err)
+}
@@ -0,0 +1,352 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/dataexchange"
+)
+
+const (
+	// snapshotVersion is the version of the archive format written by Export, and the only version Import accepts
+	snapshotVersion = 1
+	// headerEntry is always the first entry in the archive
+	headerEntry = "snapshot.json"
+	// manifestEntry is always the last entry in the archive, and holds the hashes of all the other entries
+	manifestEntry = "manifest.json"
+	// blobDataDir holds the content of each blob, in the same order as the blob records
+	blobDataDir = "blobdata"
+	// exportPageSize is the number of records written to each entry of the archive
+	exportPageSize = 250
+)
+
+// Manager exports the data of a namespace to a versioned archive, and imports it again into an empty database
+type Manager interface {
+	Export(ctx context.Context, w io.Writer) error
+	Import(ctx context.Context, r io.Reader) error
+}
+
+type snapshotManager struct {
+	namespace    string
+	database     database.Plugin
+	dataexchange dataexchange.Plugin
+}
+
+type header struct {
+	Version   int             `json:"version"`
+	Namespace string          `json:"namespace"`
+	Created   *fftypes.FFTime `json:"created"`
+}
+
+type manifest struct {
+	Entries map[string]*fftypes.Bytes32 `json:"entries"`
+	Counts  map[string]int              `json:"counts"`
+}
+
+// NewSnapshotManager creates a snapshot manager for a namespace. The data exchange plugin is optional,
+// and only required if the namespace contains blobs
+func NewSnapshotManager(ctx context.Context, ns string, di database.Plugin, dx dataexchange.Plugin) (Manager, error) {
+	if di == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgInitializationNilDepError, "SnapshotManager")
+	}
+	return &snapshotManager{
+		namespace:    ns,
+		database:     di,
+		dataexchange: dx,
+	}, nil
+}
+
+type exporter struct {
+	tw       *tar.Writer
+	manifest *manifest
+	blobs    int
+}
+
+func (ex *exporter) writeEntry(name string, size int64, r io.Reader) error {
+	if err := ex.tw.WriteHeader(&tar.Header{
+		Name:     name,
+		Mode:     0644,
+		Size:     size,
+		Typeflag: tar.TypeReg,
+		ModTime:  time.Now(),
+	}); err != nil {
+		return err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(ex.tw, io.TeeReader(r, h)); err != nil {
+		return err
+	}
+	ex.manifest.Entries[name] = fftypes.HashResult(h)
+	return nil
+}
+
+func (ex *exporter) writeJSON(name string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ex.writeEntry(name, int64(len(b)), bytes.NewReader(b))
+}
+
+// Export writes every collection of the namespace to the archive a page at a time. The export does not run
+// in a single transaction, so the node should be idle (or stopped) to obtain a consistent snapshot
+func (sm *snapshotManager) Export(ctx context.Context, w io.Writer) (err error) {
+	gz := gzip.NewWriter(w)
+	ex := &exporter{
+		tw: tar.NewWriter(gz),
+		manifest: &manifest{
+			Entries: make(map[string]*fftypes.Bytes32),
+			Counts:  make(map[string]int),
+		},
+	}
+	err = ex.writeJSON(headerEntry, &header{
+		Version:   snapshotVersion,
+		Namespace: sm.namespace,
+		Created:   fftypes.Now(),
+	})
+	for _, c := range collections {
+		if err == nil {
+			err = sm.exportCollection(ctx, ex, c)
+		}
+	}
+	if err == nil {
+		err = ex.writeJSON(manifestEntry, ex.manifest)
+	}
+	if err == nil {
+		err = ex.tw.Close()
+	}
+	if err == nil {
+		err = gz.Close()
+	}
+	return err
+}
+
+func (sm *snapshotManager) exportCollection(ctx context.Context, ex *exporter, c *collection) error {
+	for page := 0; ; page++ {
+		fb := c.queryFactory.NewFilterLimit(ctx, exportPageSize)
+		filter := fb.And()
+		for _, field := range c.sort {
+			filter.Sort(field)
+		}
+		filter.Skip(uint64(page * exportPageSize))
+		records, count, err := c.list(ctx, sm, filter)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
+		}
+		if err := ex.writeJSON(fmt.Sprintf("%s/%06d.json", c.name, page), records); err != nil {
+			return err
+		}
+		if c.exportContent != nil {
+			if err := c.exportContent(ctx, sm, ex, records); err != nil {
+				return err
+			}
+		}
+		ex.manifest.Counts[c.name] += count
+		log.L(ctx).Debugf("Exported %d %s from namespace '%s'", count, c.name, sm.namespace)
+		if count < exportPageSize {
+			return nil
+		}
+	}
+}
+
+type importer struct {
+	tr           *tar.Reader
+	entries      map[string]*fftypes.Bytes32
+	counts       map[string]int
+	pendingBlobs []*core.Blob
+}
+
+// Import restores an archive written by Export into the namespace, which must be empty. All the records are
+// written in a single database group, so that a failed integrity check on any entry leaves the database unchanged.
+// Blobs uploaded to data exchange before a failure are not removed
+func (sm *snapshotManager) Import(ctx context.Context, r io.Reader) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return i18n.NewError(ctx, coremsgs.MsgSnapshotInvalid, err)
+	}
+	im := &importer{
+		tr:      tar.NewReader(gz),
+		entries: make(map[string]*fftypes.Bytes32),
+		counts:  make(map[string]int),
+	}
+	return sm.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		if err := sm.importHeader(ctx, im); err != nil {
+			return err
+		}
+		if err := sm.checkEmpty(ctx); err != nil {
+			return err
+		}
+		for {
+			th, err := im.tr.Next()
+			if err != nil {
+				// the manifest must be the final entry, so we should never reach the end of the archive
+				return i18n.NewError(ctx, coremsgs.MsgSnapshotInvalid, err)
+			}
+			switch {
+			case th.Name == manifestEntry:
+				return sm.importManifest(ctx, im)
+			case strings.HasPrefix(th.Name, blobDataDir+"/"):
+				err = sm.importBlobData(ctx, im, th.Name)
+			default:
+				err = sm.importPage(ctx, im, th.Name)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	})
+}
+
+func (im *importer) readEntry(ctx context.Context, name string, v interface{}) error {
+	h := sha256.New()
+	if err := json.NewDecoder(io.TeeReader(im.tr, h)).Decode(v); err != nil {
+		return i18n.NewError(ctx, coremsgs.MsgSnapshotInvalid, fmt.Sprintf("%s: %s", name, err))
+	}
+	im.entries[name] = fftypes.HashResult(h)
+	return nil
+}
+
+func (sm *snapshotManager) importHeader(ctx context.Context, im *importer) error {
+	th, err := im.tr.Next()
+	if err != nil {
+		return i18n.NewError(ctx, coremsgs.MsgSnapshotInvalid, err)
+	}
+	if th.Name != headerEntry {
+		return i18n.NewError(ctx, coremsgs.MsgSnapshotInvalid, th.Name)
+	}
+	var hdr header
+	if err := im.readEntry(ctx, th.Name, &hdr); err != nil {
+		return err
+	}
+	if hdr.Version != snapshotVersion {
+		return i18n.NewError(ctx, coremsgs.MsgSnapshotVersionUnsupported, hdr.Version)
+	}
+	if hdr.Namespace != sm.namespace {
+		return i18n.NewError(ctx, coremsgs.MsgSnapshotNamespaceMismatch, hdr.Namespace, sm.namespace)
+	}
+	log.L(ctx).Infof("Importing snapshot of namespace '%s' created %s", hdr.Namespace, hdr.Created)
+	return nil
+}
+
+func (sm *snapshotManager) checkEmpty(ctx context.Context) error {
+	for _, c := range collections {
+		if c.global {
+			continue
+		}
+		_, count, err := c.list(ctx, sm, c.queryFactory.NewFilterLimit(ctx, 1).And())
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return i18n.NewError(ctx, coremsgs.MsgSnapshotNamespaceNotEmpty, sm.namespace, c.name)
+		}
+	}
+	return nil
+}
+
+func (sm *snapshotManager) importPage(ctx context.Context, im *importer, name string) error {
+	var c *collection
+	for _, candidate := range collections {
+		if strings.HasPrefix(name, candidate.name+"/") {
+			c = candidate
+			break
+		}
+	}
+	if c == nil {
+		return i18n.NewError(ctx, coremsgs.MsgSnapshotInvalid, name)
+	}
+	page := c.newPage()
+	if err := im.readEntry(ctx, name, page); err != nil {
+		return err
+	}
+	if err := c.restore(ctx, sm, im, page); err != nil {
+		return err
+	}
+	count := reflect.ValueOf(page).Elem().Len()
+	im.counts[c.name] += count
+	log.L(ctx).Debugf("Imported %d %s into namespace '%s'", count, c.name, sm.namespace)
+	return nil
+}
+
+func (sm *snapshotManager) importBlobData(ctx context.Context, im *importer, name string) error {
+	if len(im.pendingBlobs) == 0 {
+		return i18n.NewError(ctx, coremsgs.MsgSnapshotInvalid, name)
+	}
+	blob := im.pendingBlobs[0]
+	im.pendingBlobs = im.pendingBlobs[1:]
+
+	id := blob.DataID
+	if id == nil {
+		id = fftypes.NewUUID()
+	}
+	h := sha256.New()
+	payloadRef, hash, size, err := sm.dataexchange.UploadBlob(ctx, sm.namespace, *id, io.TeeReader(im.tr, h))
+	if err != nil {
+		return err
+	}
+	im.entries[name] = fftypes.HashResult(h)
+	if !hash.Equals(blob.Hash) || size != blob.Size {
+		return i18n.NewError(ctx, coremsgs.MsgSnapshotHashMismatch, "blob", blob.DataID, blob.Hash, hash)
+	}
+	blob.PayloadRef = payloadRef
+	return sm.database.InsertBlob(ctx, blob)
+}
+
+func (sm *snapshotManager) importManifest(ctx context.Context, im *importer) error {
+	var m manifest
+	if err := json.NewDecoder(im.tr).Decode(&m); err != nil {
+		return i18n.NewError(ctx, coremsgs.MsgSnapshotInvalid, fmt.Sprintf("%s: %s", manifestEntry, err))
+	}
+	if len(im.pendingBlobs) > 0 {
+		return i18n.NewError(ctx, coremsgs.MsgSnapshotInvalid, fmt.Sprintf("missing %s for %d blobs", blobDataDir, len(im.pendingBlobs)))
+	}
+	if len(im.entries) != len(m.Entries) {
+		return i18n.NewError(ctx, coremsgs.MsgSnapshotCountMismatch, len(m.Entries), "entries", len(im.entries))
+	}
+	for name, hash := range im.entries {
+		if !hash.Equals(m.Entries[name]) {
+			return i18n.NewError(ctx, coremsgs.MsgSnapshotHashMismatch, "entry", name, m.Entries[name], hash)
+		}
+	}
+	for _, c := range collections {
+		if im.counts[c.name] != m.Counts[c.name] {
+			return i18n.NewError(ctx, coremsgs.MsgSnapshotCountMismatch, m.Counts[c.name], c.name, im.counts[c.name])
+		}
+	}
+	log.L(ctx).Infof("Imported snapshot of namespace '%s' with %d entries", sm.namespace, len(im.entries))
+	return nil
+}
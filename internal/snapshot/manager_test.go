@@ -0,0 +1,561 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var blobContent = []byte("some binary content")
+
+type testRecords struct {
+	datatype *core.Datatype
+	ffi      *fftypes.FFI
+	method   *fftypes.FFIMethod
+	event    *fftypes.FFIEvent
+	ffiError *fftypes.FFIError
+	api      *core.ContractAPI
+	identity *core.Identity
+	verifier *core.Verifier
+	group    *core.Group
+	data     core.DataArray
+	blob     *core.Blob
+	message  *core.Message
+	batch    *core.BatchPersisted
+	pool     *core.TokenPool
+	transfer *core.TokenTransfer
+	balance  *core.TokenBalance
+	nextpin  *core.NextPin
+	sub      *core.Subscription
+	offset   *core.Offset
+}
+
+func newTestRecords() *testRecords {
+	dt := &core.Datatype{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Name:      "widget",
+		Version:   "1.0",
+		Value:     fftypes.JSONAnyPtr(`{"type":"object"}`),
+	}
+	dt.Hash = dt.Value.Hash()
+
+	verifier := (&core.Verifier{
+		Identity:  fftypes.NewUUID(),
+		Namespace: "ns1",
+		VerifierRef: core.VerifierRef{
+			Type:  core.VerifierTypeEthAddress,
+			Value: "0x12345",
+		},
+	}).Seal()
+
+	group := &core.Group{
+		GroupIdentity: core.GroupIdentity{
+			Namespace: "ns1",
+			Name:      "group1",
+			Members:   core.Members{{Identity: "did:firefly:org/org1", Node: fftypes.NewUUID()}},
+		},
+	}
+	group.Seal()
+
+	blobHash := fftypes.Bytes32(sha256.Sum256(blobContent))
+	dataValue := &core.Data{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Value:     fftypes.JSONAnyPtr(`"hello"`),
+	}
+	dataValue.Hash = dataValue.Value.Hash()
+	dataBlob := &core.Data{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Blob:      &core.BlobRef{Hash: &blobHash, Size: int64(len(blobContent))},
+	}
+	dataBlob.Hash, _ = dataBlob.CalcHash(context.Background())
+
+	msg := &core.Message{
+		Header: core.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Type:      core.MessageTypeBroadcast,
+		},
+		Data: core.DataRefs{
+			{ID: dataValue.ID, Hash: dataValue.Hash},
+			{ID: dataBlob.ID, Hash: dataBlob.Hash},
+		},
+	}
+	msg.Header.DataHash = msg.Data.Hash()
+	msg.Hash = msg.Header.Hash()
+
+	poolID := fftypes.NewUUID()
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+	}
+	return &testRecords{
+		datatype: dt,
+		ffi:      &fftypes.FFI{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "ffi1", Version: "1.0"},
+		method:   &fftypes.FFIMethod{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "set"},
+		event:    &fftypes.FFIEvent{ID: fftypes.NewUUID(), Namespace: "ns1"},
+		ffiError: &fftypes.FFIError{ID: fftypes.NewUUID(), Namespace: "ns1"},
+		api:      &core.ContractAPI{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "api1"},
+		identity: &core.Identity{IdentityBase: core.IdentityBase{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "org1"}},
+		verifier: verifier,
+		group:    group,
+		data:     core.DataArray{dataValue, dataBlob},
+		blob: &core.Blob{
+			Namespace:  "ns1",
+			Hash:       &blobHash,
+			PayloadRef: "ns1/blob1",
+			Size:       int64(len(blobContent)),
+			DataID:     dataBlob.ID,
+		},
+		message:  msg,
+		batch:    &core.BatchPersisted{BatchHeader: core.BatchHeader{ID: fftypes.NewUUID(), Namespace: "ns1"}},
+		pool:     &core.TokenPool{ID: poolID, Namespace: "ns1", Name: "pool1"},
+		transfer: &core.TokenTransfer{LocalID: fftypes.NewUUID(), Namespace: "ns1", Pool: poolID, To: "0x12345", Amount: *fftypes.NewFFBigInt(10)},
+		balance:  &core.TokenBalance{Pool: poolID, Namespace: "ns1", Key: "0x12345", Balance: *fftypes.NewFFBigInt(10)},
+		nextpin:  &core.NextPin{Namespace: "ns1", Context: fftypes.NewRandB32(), Identity: "org1", Hash: fftypes.NewRandB32(), Nonce: 12},
+		sub:      sub,
+		offset:   &core.Offset{Type: core.OffsetTypeSubscription, Name: sub.ID.String(), Current: 12345},
+	}
+}
+
+func newTestSnapshotManager(t *testing.T) (*snapshotManager, *databasemocks.Plugin, *dataexchangemocks.Plugin) {
+	mdi := &databasemocks.Plugin{}
+	mdx := &dataexchangemocks.Plugin{}
+	sm, err := NewSnapshotManager(context.Background(), "ns1", mdi, mdx)
+	assert.NoError(t, err)
+	return sm.(*snapshotManager), mdi, mdx
+}
+
+func mockExport(mdi *databasemocks.Plugin, mdx *dataexchangemocks.Plugin, r *testRecords) {
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return([]*core.Datatype{r.datatype}, nil, nil)
+	mdi.On("GetFFIs", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFI{r.ffi}, nil, nil)
+	mdi.On("GetFFIMethods", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFIMethod{r.method}, nil, nil)
+	mdi.On("GetFFIEvents", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFIEvent{r.event}, nil, nil)
+	mdi.On("GetFFIErrors", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFIError{r.ffiError}, nil, nil)
+	mdi.On("GetContractAPIs", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractAPI{r.api}, nil, nil)
+	mdi.On("GetIdentities", mock.Anything, "ns1", mock.Anything).Return([]*core.Identity{r.identity}, nil, nil)
+	mdi.On("GetVerifiers", mock.Anything, "ns1", mock.Anything).Return([]*core.Verifier{r.verifier}, nil, nil)
+	mdi.On("GetGroups", mock.Anything, "ns1", mock.Anything).Return([]*core.Group{r.group}, nil, nil)
+	mdi.On("GetData", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(r.data, nil, nil)
+	mdi.On("GetBlobs", mock.Anything, "ns1", mock.Anything).Return([]*core.Blob{r.blob}, nil, nil)
+	mdi.On("GetMessages", mock.Anything, "ns1", mock.Anything, mock.Anything).Return([]*core.Message{r.message}, nil, nil)
+	mdi.On("GetBatches", mock.Anything, "ns1", mock.Anything).Return([]*core.BatchPersisted{r.batch}, nil, nil)
+	mdi.On("GetTokenPools", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenPool{r.pool}, nil, nil)
+	mdi.On("GetTokenTransfers", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenTransfer{r.transfer}, nil, nil)
+	mdi.On("GetTokenBalances", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenBalance{r.balance}, nil, nil)
+	mdi.On("GetNextPins", mock.Anything, "ns1", mock.Anything).Return([]*core.NextPin{r.nextpin}, nil, nil)
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{r.sub}, nil, nil)
+	mdi.On("GetOffsets", mock.Anything, mock.Anything).Return([]*core.Offset{r.offset}, nil, nil)
+	mdx.On("DownloadBlob", mock.Anything, "ns1/blob1").Return(io.NopCloser(bytes.NewReader(blobContent)), nil)
+}
+
+func mockEmpty(mdi *databasemocks.Plugin) {
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return([]*core.Datatype{}, nil, nil)
+	mdi.On("GetFFIs", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFI{}, nil, nil)
+	mdi.On("GetFFIMethods", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFIMethod{}, nil, nil)
+	mdi.On("GetFFIEvents", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFIEvent{}, nil, nil)
+	mdi.On("GetFFIErrors", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFIError{}, nil, nil)
+	mdi.On("GetContractAPIs", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractAPI{}, nil, nil)
+	mdi.On("GetIdentities", mock.Anything, "ns1", mock.Anything).Return([]*core.Identity{}, nil, nil)
+	mdi.On("GetVerifiers", mock.Anything, "ns1", mock.Anything).Return([]*core.Verifier{}, nil, nil)
+	mdi.On("GetGroups", mock.Anything, "ns1", mock.Anything).Return([]*core.Group{}, nil, nil)
+	mdi.On("GetData", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(core.DataArray{}, nil, nil)
+	mdi.On("GetBlobs", mock.Anything, "ns1", mock.Anything).Return([]*core.Blob{}, nil, nil)
+	mdi.On("GetMessages", mock.Anything, "ns1", mock.Anything, mock.Anything).Return([]*core.Message{}, nil, nil)
+	mdi.On("GetBatches", mock.Anything, "ns1", mock.Anything).Return([]*core.BatchPersisted{}, nil, nil)
+	mdi.On("GetTokenPools", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenPool{}, nil, nil)
+	mdi.On("GetTokenTransfers", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenTransfer{}, nil, nil)
+	mdi.On("GetTokenBalances", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenBalance{}, nil, nil)
+	mdi.On("GetNextPins", mock.Anything, "ns1", mock.Anything).Return([]*core.NextPin{}, nil, nil)
+}
+
+func mockUploadBlob(mdx *dataexchangemocks.Plugin, payloadRef string, hash *fftypes.Bytes32, size int64, err error) *mock.Call {
+	return mdx.On("UploadBlob", mock.Anything, "ns1", mock.Anything, mock.Anything).
+		Run(func(a mock.Arguments) {
+			_, _ = io.ReadAll(a[3].(io.Reader))
+		}).
+		Return(payloadRef, hash, size, err)
+}
+
+func mockRunAsGroup(mdi *databasemocks.Plugin) {
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+}
+
+func exportTestArchive(t *testing.T, r *testRecords) []byte {
+	sm, mdi, mdx := newTestSnapshotManager(t)
+	mockExport(mdi, mdx, r)
+	buf := &bytes.Buffer{}
+	err := sm.Export(context.Background(), buf)
+	assert.NoError(t, err)
+	mdi.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+	return buf.Bytes()
+}
+
+type testEntry struct {
+	name    string
+	content []byte
+}
+
+func readTestArchive(t *testing.T, archive []byte) []*testEntry {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	assert.NoError(t, err)
+	tr := tar.NewReader(gz)
+	entries := []*testEntry{}
+	for {
+		th, err := tr.Next()
+		if err == io.EOF {
+			return entries
+		}
+		assert.NoError(t, err)
+		content, err := io.ReadAll(tr)
+		assert.NoError(t, err)
+		entries = append(entries, &testEntry{name: th.Name, content: content})
+	}
+}
+
+func writeTestArchive(entries []*testEntry) []byte {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		_ = tw.WriteHeader(&tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.content)), Typeflag: tar.TypeReg})
+		_, _ = tw.Write(e.content)
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+	return buf.Bytes()
+}
+
+func jsonEntry(name string, v interface{}) *testEntry {
+	b, _ := json.Marshal(v)
+	return &testEntry{name: name, content: b}
+}
+
+func testHeader() *testEntry {
+	return jsonEntry(headerEntry, &header{Version: snapshotVersion, Namespace: "ns1", Created: fftypes.Now()})
+}
+
+func TestNewSnapshotManagerMissingDeps(t *testing.T) {
+	_, err := NewSnapshotManager(context.Background(), "ns1", nil, nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	r := newTestRecords()
+	archive := exportTestArchive(t, r)
+
+	entries := readTestArchive(t, archive)
+	assert.Equal(t, headerEntry, entries[0].name)
+	assert.Equal(t, manifestEntry, entries[len(entries)-1].name)
+	var m manifest
+	err := json.Unmarshal(entries[len(entries)-1].content, &m)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, m.Counts["data"])
+	assert.Equal(t, 1, m.Counts["offsets"])
+	assert.Len(t, m.Entries, len(entries)-1)
+
+	sm, mdi, mdx := newTestSnapshotManager(t)
+	mockRunAsGroup(mdi)
+	mockEmpty(mdi)
+	mdi.On("UpsertDatatype", mock.Anything, mock.Anything, false).Return(nil)
+	mdi.On("UpsertFFI", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpsertFFIMethod", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpsertFFIEvent", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpsertFFIError", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpsertContractAPI", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpsertIdentity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpsertVerifier", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpsertGroup", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("InsertDataArray", mock.Anything, mock.MatchedBy(func(data core.DataArray) bool {
+		return len(data) == 2 && data[0].ID.Equals(r.data[0].ID)
+	})).Return(nil)
+	mockUploadBlob(mdx, "newref", r.blob.Hash, r.blob.Size, nil)
+	mdi.On("InsertBlob", mock.Anything, mock.MatchedBy(func(blob *core.Blob) bool {
+		return blob.PayloadRef == "newref"
+	})).Return(nil)
+	mdi.On("InsertMessages", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("InsertOrGetBatch", mock.Anything, mock.Anything).Return(nil, nil)
+	mdi.On("UpsertTokenPool", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpsertTokenTransfer", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpdateTokenBalances", mock.Anything, mock.MatchedBy(func(transfer *core.TokenTransfer) bool {
+		return transfer.To == "0x12345" && transfer.Amount.Int().Int64() == 10 && transfer.From == ""
+	})).Return(nil)
+	mdi.On("InsertNextPin", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, r.offset.Name).Return(nil, nil)
+	mdi.On("UpsertOffset", mock.Anything, mock.MatchedBy(func(offset *core.Offset) bool {
+		return offset.Current == -1
+	}), false).Return(nil)
+
+	err = sm.Import(context.Background(), bytes.NewReader(archive))
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+}
+
+func TestExportPaging(t *testing.T) {
+	sm, mdi, _ := newTestSnapshotManager(t)
+	page := make([]*core.Datatype, exportPageSize)
+	for i := range page {
+		page[i] = &core.Datatype{ID: fftypes.NewUUID()}
+	}
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return(page, nil, nil).Once()
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return([]*core.Datatype{}, nil, nil).Once()
+	mdi.On("GetFFIs", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := sm.Export(context.Background(), &bytes.Buffer{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestExportNoDataExchange(t *testing.T) {
+	r := newTestRecords()
+	sm, mdi, mdx := newTestSnapshotManager(t)
+	mockExport(mdi, mdx, r)
+	mdx.ExpectedCalls = nil
+	sm.dataexchange = nil
+
+	err := sm.Export(context.Background(), &bytes.Buffer{})
+	assert.Regexp(t, "FF10494", err)
+}
+
+func TestExportDownloadBlobFail(t *testing.T) {
+	r := newTestRecords()
+	sm, mdi, mdx := newTestSnapshotManager(t)
+	mockExport(mdi, mdx, r)
+	mdx.ExpectedCalls = nil
+	mdx.On("DownloadBlob", mock.Anything, "ns1/blob1").Return(nil, fmt.Errorf("pop"))
+
+	err := sm.Export(context.Background(), &bytes.Buffer{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestExportBlobSizeMismatch(t *testing.T) {
+	r := newTestRecords()
+	r.blob.Size = 1
+	sm, mdi, mdx := newTestSnapshotManager(t)
+	mockExport(mdi, mdx, r)
+
+	err := sm.Export(context.Background(), &bytes.Buffer{})
+	assert.Regexp(t, "write too long", err)
+}
+
+func TestExportMarshalFail(t *testing.T) {
+	sm, mdi, _ := newTestSnapshotManager(t)
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return([]*core.Datatype{
+		{Value: fftypes.JSONAnyPtr("!json")},
+	}, nil, nil)
+
+	err := sm.Export(context.Background(), &bytes.Buffer{})
+	assert.Error(t, err)
+}
+
+func TestExportOffsetsSubscriptionsFail(t *testing.T) {
+	sm, mdi, _ := newTestSnapshotManager(t)
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, _, err := collections[len(collections)-1].list(context.Background(), sm, nil)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestImportBadGzip(t *testing.T) {
+	sm, _, _ := newTestSnapshotManager(t)
+	err := sm.Import(context.Background(), bytes.NewReader([]byte("not gzip")))
+	assert.Regexp(t, "FF10491", err)
+}
+
+func TestImportHeaderErrors(t *testing.T) {
+	for _, tc := range []struct {
+		entries []*testEntry
+		err     string
+	}{
+		{entries: []*testEntry{}, err: "FF10491.*EOF"},
+		{entries: []*testEntry{jsonEntry("datatypes/000000.json", []string{})}, err: "FF10491.*datatypes"},
+		{entries: []*testEntry{{name: headerEntry, content: []byte("!json")}}, err: "FF10491.*snapshot.json"},
+		{entries: []*testEntry{jsonEntry(headerEntry, &header{Version: 99})}, err: "FF10492"},
+		{entries: []*testEntry{jsonEntry(headerEntry, &header{Version: snapshotVersion, Namespace: "ns2"})}, err: "FF10495"},
+	} {
+		sm, mdi, _ := newTestSnapshotManager(t)
+		mockRunAsGroup(mdi)
+		err := sm.Import(context.Background(), bytes.NewReader(writeTestArchive(tc.entries)))
+		assert.Regexp(t, tc.err, err)
+	}
+}
+
+func TestImportNotEmpty(t *testing.T) {
+	sm, mdi, _ := newTestSnapshotManager(t)
+	mockRunAsGroup(mdi)
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return([]*core.Datatype{{}}, nil, nil)
+	err := sm.Import(context.Background(), bytes.NewReader(writeTestArchive([]*testEntry{testHeader()})))
+	assert.Regexp(t, "FF10490.*datatypes", err)
+}
+
+func TestImportCheckEmptyFail(t *testing.T) {
+	sm, mdi, _ := newTestSnapshotManager(t)
+	mockRunAsGroup(mdi)
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := sm.Import(context.Background(), bytes.NewReader(writeTestArchive([]*testEntry{testHeader()})))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestImportEntryErrors(t *testing.T) {
+	for _, tc := range []struct {
+		entries []*testEntry
+		err     string
+	}{
+		{entries: []*testEntry{}, err: "FF10491.*EOF"},
+		{entries: []*testEntry{jsonEntry("unknown/000000.json", []string{})}, err: "FF10491.*unknown"},
+		{entries: []*testEntry{{name: "datatypes/000000.json", content: []byte("!json")}}, err: "FF10491.*datatypes"},
+		{entries: []*testEntry{jsonEntry("datatypes/000000.json", []*core.Datatype{{Value: fftypes.JSONAnyPtr("{}"), Hash: fftypes.NewRandB32()}})}, err: "FF10493.*datatype"},
+		{entries: []*testEntry{{name: blobDataDir + "/000001", content: blobContent}}, err: "FF10491.*blobdata"},
+		{entries: []*testEntry{{name: manifestEntry, content: []byte("!json")}}, err: "FF10491.*manifest"},
+		{entries: []*testEntry{jsonEntry(manifestEntry, &manifest{})}, err: "FF10496.*entries"},
+	} {
+		sm, mdi, _ := newTestSnapshotManager(t)
+		mockRunAsGroup(mdi)
+		mockEmpty(mdi)
+		err := sm.Import(context.Background(), bytes.NewReader(writeTestArchive(append([]*testEntry{testHeader()}, tc.entries...))))
+		assert.Regexp(t, tc.err, err)
+	}
+}
+
+func TestImportManifestMismatch(t *testing.T) {
+	r := newTestRecords()
+	entries := readTestArchive(t, exportTestArchive(t, r))
+	var m manifest
+	err := json.Unmarshal(entries[len(entries)-1].content, &m)
+	assert.NoError(t, err)
+
+	for _, tc := range []struct {
+		modify func(entries []*testEntry, m *manifest) []*testEntry
+		err    string
+	}{
+		{
+			modify: func(entries []*testEntry, m *manifest) []*testEntry {
+				m.Entries[headerEntry] = fftypes.NewRandB32()
+				return entries
+			},
+			err: "FF10493.*entry.*snapshot.json",
+		},
+		{
+			modify: func(entries []*testEntry, m *manifest) []*testEntry {
+				m.Counts["datatypes"] = 2
+				return entries
+			},
+			err: "FF10496.*datatypes",
+		},
+		{
+			modify: func(entries []*testEntry, m *manifest) []*testEntry {
+				// drop the blob content
+				var filtered []*testEntry
+				for _, e := range entries {
+					if e.name != blobDataDir+"/000001" {
+						filtered = append(filtered, e)
+					}
+				}
+				return filtered
+			},
+			err: "FF10491.*missing blobdata for 1 blobs",
+		},
+	} {
+		sm, mdi, mdx := newTestSnapshotManager(t)
+		mockRunAsGroup(mdi)
+		mockEmpty(mdi)
+		mdi.On("UpsertDatatype", mock.Anything, mock.Anything, false).Return(nil)
+		mdi.On("UpsertFFI", mock.Anything, mock.Anything).Return(nil)
+		mdi.On("UpsertFFIMethod", mock.Anything, mock.Anything).Return(nil)
+		mdi.On("UpsertFFIEvent", mock.Anything, mock.Anything).Return(nil)
+		mdi.On("UpsertFFIError", mock.Anything, mock.Anything).Return(nil)
+		mdi.On("UpsertContractAPI", mock.Anything, mock.Anything).Return(nil)
+		mdi.On("UpsertIdentity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		mdi.On("UpsertVerifier", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		mdi.On("UpsertGroup", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		mdi.On("InsertDataArray", mock.Anything, mock.Anything).Return(nil)
+		mockUploadBlob(mdx, "newref", r.blob.Hash, r.blob.Size, nil).Maybe()
+		mdi.On("InsertBlob", mock.Anything, mock.Anything).Return(nil).Maybe()
+		mdi.On("InsertMessages", mock.Anything, mock.Anything).Return(nil)
+		mdi.On("InsertOrGetBatch", mock.Anything, mock.Anything).Return(nil, nil)
+		mdi.On("UpsertTokenPool", mock.Anything, mock.Anything).Return(nil)
+		mdi.On("UpsertTokenTransfer", mock.Anything, mock.Anything).Return(nil)
+		mdi.On("UpdateTokenBalances", mock.Anything, mock.Anything).Return(nil)
+		mdi.On("InsertNextPin", mock.Anything, mock.Anything).Return(nil)
+		mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, r.offset.Name).Return(r.offset, nil)
+
+		var m2 manifest
+		_ = json.Unmarshal(entries[len(entries)-1].content, &m2)
+		modified := tc.modify(append([]*testEntry{}, entries[0:len(entries)-1]...), &m2)
+		modified = append(modified, jsonEntry(manifestEntry, &m2))
+		err := sm.Import(context.Background(), bytes.NewReader(writeTestArchive(modified)))
+		assert.Regexp(t, tc.err, err)
+	}
+}
+
+func TestImportBlobDataErrors(t *testing.T) {
+	r := newTestRecords()
+	blobPage := jsonEntry("blobs/000000.json", []*core.Blob{r.blob})
+	blobData := &testEntry{name: blobDataDir + "/000001", content: blobContent}
+
+	sm, mdi, mdx := newTestSnapshotManager(t)
+	mockRunAsGroup(mdi)
+	mockEmpty(mdi)
+	mockUploadBlob(mdx, "", nil, 0, fmt.Errorf("pop")).Once()
+	err := sm.Import(context.Background(), bytes.NewReader(writeTestArchive([]*testEntry{testHeader(), blobPage, blobData})))
+	assert.EqualError(t, err, "pop")
+
+	mockUploadBlob(mdx, "newref", fftypes.NewRandB32(), r.blob.Size, nil).Once()
+	err = sm.Import(context.Background(), bytes.NewReader(writeTestArchive([]*testEntry{testHeader(), blobPage, blobData})))
+	assert.Regexp(t, "FF10493.*blob", err)
+
+	mockUploadBlob(mdx, "newref", r.blob.Hash, r.blob.Size, nil).Once()
+	mdi.On("InsertBlob", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	err = sm.Import(context.Background(), bytes.NewReader(writeTestArchive([]*testEntry{testHeader(), blobPage, blobData})))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestImportBlobNoDataID(t *testing.T) {
+	r := newTestRecords()
+	r.blob.DataID = nil
+	sm, mdi, mdx := newTestSnapshotManager(t)
+	mockUploadBlob(mdx, "newref", r.blob.Hash, r.blob.Size, nil)
+	mdi.On("InsertBlob", mock.Anything, r.blob).Return(nil)
+	im := &importer{
+		tr:           tar.NewReader(bytes.NewReader(writeTestArchive(nil))),
+		entries:      make(map[string]*fftypes.Bytes32),
+		pendingBlobs: []*core.Blob{r.blob},
+	}
+	err := sm.importBlobData(context.Background(), im, blobDataDir+"/000001")
+	assert.NoError(t, err)
+	assert.Equal(t, "newref", r.blob.PayloadRef)
+}
@@ -94,7 +94,8 @@ func (t *transactionHelper) GetTransactionByIDCached(ctx context.Context, id *ff
 	if cachedValue := t.transactionCache.Get(id.String()); cachedValue != nil {
 		return cachedValue.(*core.Transaction), nil
 	}
-	tx, err := t.database.GetTransactionByID(ctx, t.namespace, id)
+	// The cached transaction is updated in place by other callers, so it must be read from the primary
+	tx, err := t.database.GetTransactionByID(database.WithPrimary(ctx), t.namespace, id)
 	if err != nil || tx == nil {
 		return tx, err
 	}
@@ -192,7 +193,7 @@ func (t *transactionHelper) GetBlockchainEventByIDCached(ctx context.Context, id
 	if cachedValue := t.blockchainEventCache.Get(id.String()); cachedValue != nil {
 		return cachedValue.(*core.BlockchainEvent), nil
 	}
-	chainEvent, err := t.database.GetBlockchainEventByID(ctx, t.namespace, id)
+	chainEvent, err := t.database.GetBlockchainEventByID(database.WithPrimary(ctx), t.namespace, id)
 	if err != nil || chainEvent == nil {
 		return chainEvent, err
 	}
@@ -476,6 +476,29 @@ func TestGetTransactionByIDCached(t *testing.T) {
 
 }
 
+func TestGetTransactionByIDCachedReadsPrimary(t *testing.T) {
+
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper, _, _ := NewTestTransactionHelper(mdi, mdm)
+	ctx := database.WithReadReplica(context.Background())
+
+	txid := fftypes.NewUUID()
+	mdi.On("GetTransactionByID", mock.MatchedBy(func(ctx context.Context) bool {
+		return !database.ReadReplicaAllowed(ctx)
+	}), "ns1", txid).Return(&core.Transaction{
+		ID:        txid,
+		Namespace: "ns1",
+	}, nil).Once()
+
+	tx, err := txHelper.GetTransactionByIDCached(ctx, txid)
+	assert.NoError(t, err)
+	assert.Equal(t, txid, tx.ID)
+
+	mdi.AssertExpectations(t)
+
+}
+
 func TestGetTransactionByIDCachedFail(t *testing.T) {
 
 	mdi := &databasemocks.Plugin{}
@@ -78,6 +78,32 @@ func (_m *Plugin) Capabilities() *blockchain.Capabilities {
 	return r0
 }
 
+// DecodeInvokeBatchOutput provides a mock function with given fields: ctx, calls, output
+func (_m *Plugin) DecodeInvokeBatchOutput(ctx context.Context, calls []*blockchain.ContractCall, output fftypes.JSONObject) ([]*fftypes.JSONAny, error) {
+	ret := _m.Called(ctx, calls, output)
+
+	var r0 []*fftypes.JSONAny
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*blockchain.ContractCall, fftypes.JSONObject) ([]*fftypes.JSONAny, error)); ok {
+		return rf(ctx, calls, output)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []*blockchain.ContractCall, fftypes.JSONObject) []*fftypes.JSONAny); ok {
+		r0 = rf(ctx, calls, output)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.JSONAny)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []*blockchain.ContractCall, fftypes.JSONObject) error); ok {
+		r1 = rf(ctx, calls, output)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteContractListener provides a mock function with given fields: ctx, subscription, okNotFound
 func (_m *Plugin) DeleteContractListener(ctx context.Context, subscription *core.ContractListener, okNotFound bool) error {
 	ret := _m.Called(ctx, subscription, okNotFound)
@@ -134,6 +160,30 @@ func (_m *Plugin) GenerateEventSignature(ctx context.Context, event *fftypes.FFI
 	return r0
 }
 
+// GenerateEventSignatureWithLocation provides a mock function with given fields: ctx, event, location
+func (_m *Plugin) GenerateEventSignatureWithLocation(ctx context.Context, event *fftypes.FFIEventDefinition, location *fftypes.JSONAny) (string, error) {
+	ret := _m.Called(ctx, event, location)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.FFIEventDefinition, *fftypes.JSONAny) (string, error)); ok {
+		return rf(ctx, event, location)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.FFIEventDefinition, *fftypes.JSONAny) string); ok {
+		r0 = rf(ctx, event, location)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.FFIEventDefinition, *fftypes.JSONAny) error); ok {
+		r1 = rf(ctx, event, location)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GenerateFFI provides a mock function with given fields: ctx, generationRequest
 func (_m *Plugin) GenerateFFI(ctx context.Context, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error) {
 	ret := _m.Called(ctx, generationRequest)
@@ -194,13 +244,13 @@ func (_m *Plugin) GetAndConvertDeprecatedContractConfig(ctx context.Context) (*f
 }
 
 // GetContractListenerStatus provides a mock function with given fields: ctx, subID, okNotFound
-func (_m *Plugin) GetContractListenerStatus(ctx context.Context, subID string, okNotFound bool) (bool, interface{}, error) {
+func (_m *Plugin) GetContractListenerStatus(ctx context.Context, subID string, okNotFound bool) (bool, *core.ContractListenerStatus, error) {
 	ret := _m.Called(ctx, subID, okNotFound)
 
 	var r0 bool
-	var r1 interface{}
+	var r1 *core.ContractListenerStatus
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, bool) (bool, interface{}, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) (bool, *core.ContractListenerStatus, error)); ok {
 		return rf(ctx, subID, okNotFound)
 	}
 	if rf, ok := ret.Get(0).(func(context.Context, string, bool) bool); ok {
@@ -209,11 +259,11 @@ func (_m *Plugin) GetContractListenerStatus(ctx context.Context, subID string, o
 		r0 = ret.Get(0).(bool)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, bool) interface{}); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool) *core.ContractListenerStatus); ok {
 		r1 = rf(ctx, subID, okNotFound)
 	} else {
 		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(interface{})
+			r1 = ret.Get(1).(*core.ContractListenerStatus)
 		}
 	}
 
@@ -335,6 +385,20 @@ func (_m *Plugin) InvokeContract(ctx context.Context, nsOpID string, signingKey
 	return r0
 }
 
+// InvokeContractBatch provides a mock function with given fields: ctx, nsOpID, signingKey, calls, options
+func (_m *Plugin) InvokeContractBatch(ctx context.Context, nsOpID string, signingKey string, calls []*blockchain.ContractCall, options map[string]interface{}) error {
+	ret := _m.Called(ctx, nsOpID, signingKey, calls, options)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []*blockchain.ContractCall, map[string]interface{}) error); ok {
+		r0 = rf(ctx, nsOpID, signingKey, calls, options)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Name provides a mock function with given fields:
 func (_m *Plugin) Name() string {
 	ret := _m.Called()
@@ -589,6 +589,32 @@ func (_m *Manager) InvokeContractAPI(ctx context.Context, apiName string, method
 	return r0, r1
 }
 
+// InvokeContractBatch provides a mock function with given fields: ctx, req, waitConfirm
+func (_m *Manager) InvokeContractBatch(ctx context.Context, req *core.ContractCallBatchRequest, waitConfirm bool) (*core.ContractCallBatchResponse, error) {
+	ret := _m.Called(ctx, req, waitConfirm)
+
+	var r0 *core.ContractCallBatchResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.ContractCallBatchRequest, bool) (*core.ContractCallBatchResponse, error)); ok {
+		return rf(ctx, req, waitConfirm)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *core.ContractCallBatchRequest, bool) *core.ContractCallBatchResponse); ok {
+		r0 = rf(ctx, req, waitConfirm)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.ContractCallBatchResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *core.ContractCallBatchRequest, bool) error); ok {
+		r1 = rf(ctx, req, waitConfirm)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Name provides a mock function with given fields:
 func (_m *Manager) Name() string {
 	ret := _m.Called()
@@ -704,6 +730,16 @@ func (_m *Manager) RunOperation(ctx context.Context, op *core.PreparedOperation)
 	return r0, r1, r2
 }
 
+// Start provides a mock function with given fields:
+func (_m *Manager) Start() {
+	_m.Called()
+}
+
+// WaitStop provides a mock function with given fields:
+func (_m *Manager) WaitStop() {
+	_m.Called()
+}
+
 type mockConstructorTestingTNewManager interface {
 	mock.TestingT
 	Cleanup(func())
@@ -23,6 +23,32 @@ type Plugin struct {
 	mock.Mock
 }
 
+// AcquireLeadership provides a mock function with given fields: ctx, name
+func (_m *Plugin) AcquireLeadership(ctx context.Context, name string) (<-chan struct{}, error) {
+	ret := _m.Called(ctx, name)
+
+	var r0 <-chan struct{}
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (<-chan struct{}, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) <-chan struct{}); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan struct{})
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Capabilities provides a mock function with given fields:
 func (_m *Plugin) Capabilities() *database.Capabilities {
 	ret := _m.Called()
@@ -39,6 +65,30 @@ func (_m *Plugin) Capabilities() *database.Capabilities {
 	return r0
 }
 
+// DeleteBatches provides a mock function with given fields: ctx, namespace, filter, guards
+func (_m *Plugin) DeleteBatches(ctx context.Context, namespace string, filter ffapi.Filter, guards *database.RetentionGuards) (int64, error) {
+	ret := _m.Called(ctx, namespace, filter, guards)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, *database.RetentionGuards) (int64, error)); ok {
+		return rf(ctx, namespace, filter, guards)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, *database.RetentionGuards) int64); ok {
+		r0 = rf(ctx, namespace, filter, guards)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter, *database.RetentionGuards) error); ok {
+		r1 = rf(ctx, namespace, filter, guards)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteBlob provides a mock function with given fields: ctx, sequence
 func (_m *Plugin) DeleteBlob(ctx context.Context, sequence int64) error {
 	ret := _m.Called(ctx, sequence)
@@ -53,6 +103,30 @@ func (_m *Plugin) DeleteBlob(ctx context.Context, sequence int64) error {
 	return r0
 }
 
+// DeleteBlockchainEvents provides a mock function with given fields: ctx, namespace, filter, guards
+func (_m *Plugin) DeleteBlockchainEvents(ctx context.Context, namespace string, filter ffapi.Filter, guards *database.RetentionGuards) (int64, error) {
+	ret := _m.Called(ctx, namespace, filter, guards)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, *database.RetentionGuards) (int64, error)); ok {
+		return rf(ctx, namespace, filter, guards)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, *database.RetentionGuards) int64); ok {
+		r0 = rf(ctx, namespace, filter, guards)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter, *database.RetentionGuards) error); ok {
+		r1 = rf(ctx, namespace, filter, guards)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteContractListenerByID provides a mock function with given fields: ctx, namespace, id
 func (_m *Plugin) DeleteContractListenerByID(ctx context.Context, namespace string, id *fftypes.UUID) error {
 	ret := _m.Called(ctx, namespace, id)
@@ -81,6 +155,82 @@ func (_m *Plugin) DeleteData(ctx context.Context, namespace string, id *fftypes.
 	return r0
 }
 
+// DeleteDeadLetter provides a mock function with given fields: ctx, namespace, id
+func (_m *Plugin) DeleteDeadLetter(ctx context.Context, namespace string, id *fftypes.UUID) error {
+	ret := _m.Called(ctx, namespace, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, namespace, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteDeadLetters provides a mock function with given fields: ctx, namespace, subscriptionID
+func (_m *Plugin) DeleteDeadLetters(ctx context.Context, namespace string, subscriptionID *fftypes.UUID) error {
+	ret := _m.Called(ctx, namespace, subscriptionID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, namespace, subscriptionID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteEvents provides a mock function with given fields: ctx, namespace, filter, guards
+func (_m *Plugin) DeleteEvents(ctx context.Context, namespace string, filter ffapi.Filter, guards *database.RetentionGuards) (int64, error) {
+	ret := _m.Called(ctx, namespace, filter, guards)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, *database.RetentionGuards) (int64, error)); ok {
+		return rf(ctx, namespace, filter, guards)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, *database.RetentionGuards) int64); ok {
+		r0 = rf(ctx, namespace, filter, guards)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter, *database.RetentionGuards) error); ok {
+		r1 = rf(ctx, namespace, filter, guards)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteMessages provides a mock function with given fields: ctx, namespace, filter, guards
+func (_m *Plugin) DeleteMessages(ctx context.Context, namespace string, filter ffapi.Filter, guards *database.RetentionGuards) (int64, error) {
+	ret := _m.Called(ctx, namespace, filter, guards)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, *database.RetentionGuards) (int64, error)); ok {
+		return rf(ctx, namespace, filter, guards)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, *database.RetentionGuards) int64); ok {
+		r0 = rf(ctx, namespace, filter, guards)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter, *database.RetentionGuards) error); ok {
+		r1 = rf(ctx, namespace, filter, guards)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteNonce provides a mock function with given fields: ctx, hash
 func (_m *Plugin) DeleteNonce(ctx context.Context, hash *fftypes.Bytes32) error {
 	ret := _m.Called(ctx, hash)
@@ -109,6 +259,54 @@ func (_m *Plugin) DeleteOffset(ctx context.Context, t fftypes.FFEnum, name strin
 	return r0
 }
 
+// DeleteOperations provides a mock function with given fields: ctx, namespace, filter, guards
+func (_m *Plugin) DeleteOperations(ctx context.Context, namespace string, filter ffapi.Filter, guards *database.RetentionGuards) (int64, error) {
+	ret := _m.Called(ctx, namespace, filter, guards)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, *database.RetentionGuards) (int64, error)); ok {
+		return rf(ctx, namespace, filter, guards)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, *database.RetentionGuards) int64); ok {
+		r0 = rf(ctx, namespace, filter, guards)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter, *database.RetentionGuards) error); ok {
+		r1 = rf(ctx, namespace, filter, guards)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeletePins provides a mock function with given fields: ctx, namespace, filter, guards
+func (_m *Plugin) DeletePins(ctx context.Context, namespace string, filter ffapi.Filter, guards *database.RetentionGuards) (int64, error) {
+	ret := _m.Called(ctx, namespace, filter, guards)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, *database.RetentionGuards) (int64, error)); ok {
+		return rf(ctx, namespace, filter, guards)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, *database.RetentionGuards) int64); ok {
+		r0 = rf(ctx, namespace, filter, guards)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter, *database.RetentionGuards) error); ok {
+		r1 = rf(ctx, namespace, filter, guards)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteSubscriptionByID provides a mock function with given fields: ctx, namespace, id
 func (_m *Plugin) DeleteSubscriptionByID(ctx context.Context, namespace string, id *fftypes.UUID) error {
 	ret := _m.Called(ctx, namespace, id)
@@ -584,34 +782,34 @@ func (_m *Plugin) GetContractListeners(ctx context.Context, namespace string, fi
 	return r0, r1, r2
 }
 
-// GetData provides a mock function with given fields: ctx, namespace, filter
-func (_m *Plugin) GetData(ctx context.Context, namespace string, filter ffapi.Filter) (core.DataArray, *ffapi.FilterResult, error) {
-	ret := _m.Called(ctx, namespace, filter)
+// GetData provides a mock function with given fields: ctx, namespace, filter, valueFilter
+func (_m *Plugin) GetData(ctx context.Context, namespace string, filter ffapi.Filter, valueFilter ffapi.Filter) (core.DataArray, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespace, filter, valueFilter)
 
 	var r0 core.DataArray
 	var r1 *ffapi.FilterResult
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) (core.DataArray, *ffapi.FilterResult, error)); ok {
-		return rf(ctx, namespace, filter)
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, ffapi.Filter) (core.DataArray, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespace, filter, valueFilter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) core.DataArray); ok {
-		r0 = rf(ctx, namespace, filter)
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, ffapi.Filter) core.DataArray); ok {
+		r0 = rf(ctx, namespace, filter, valueFilter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(core.DataArray)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter) *ffapi.FilterResult); ok {
-		r1 = rf(ctx, namespace, filter)
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespace, filter, valueFilter)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*ffapi.FilterResult)
 		}
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.Filter) error); ok {
-		r2 = rf(ctx, namespace, filter)
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.Filter, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespace, filter, valueFilter)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -793,6 +991,67 @@ func (_m *Plugin) GetDatatypes(ctx context.Context, namespace string, filter ffa
 	return r0, r1, r2
 }
 
+// GetDeadLetterByID provides a mock function with given fields: ctx, namespace, id
+func (_m *Plugin) GetDeadLetterByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.DeadLetter, error) {
+	ret := _m.Called(ctx, namespace, id)
+
+	var r0 *core.DeadLetter
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) (*core.DeadLetter, error)); ok {
+		return rf(ctx, namespace, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) *core.DeadLetter); ok {
+		r0 = rf(ctx, namespace, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.DeadLetter)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, namespace, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeadLetters provides a mock function with given fields: ctx, namespace, filter
+func (_m *Plugin) GetDeadLetters(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.DeadLetter, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespace, filter)
+
+	var r0 []*core.DeadLetter
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) ([]*core.DeadLetter, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespace, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) []*core.DeadLetter); ok {
+		r0 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.DeadLetter)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespace, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetEventByID provides a mock function with given fields: ctx, namespace, id
 func (_m *Plugin) GetEventByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.Event, error) {
 	ret := _m.Called(ctx, namespace, id)
@@ -1324,34 +1583,34 @@ func (_m *Plugin) GetMessageIDs(ctx context.Context, namespace string, filter ff
 	return r0, r1
 }
 
-// GetMessages provides a mock function with given fields: ctx, namespace, filter
-func (_m *Plugin) GetMessages(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.Message, *ffapi.FilterResult, error) {
-	ret := _m.Called(ctx, namespace, filter)
+// GetMessages provides a mock function with given fields: ctx, namespace, filter, valueFilter
+func (_m *Plugin) GetMessages(ctx context.Context, namespace string, filter ffapi.Filter, valueFilter ffapi.Filter) ([]*core.Message, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespace, filter, valueFilter)
 
 	var r0 []*core.Message
 	var r1 *ffapi.FilterResult
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) ([]*core.Message, *ffapi.FilterResult, error)); ok {
-		return rf(ctx, namespace, filter)
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, ffapi.Filter) ([]*core.Message, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespace, filter, valueFilter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) []*core.Message); ok {
-		r0 = rf(ctx, namespace, filter)
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, ffapi.Filter) []*core.Message); ok {
+		r0 = rf(ctx, namespace, filter, valueFilter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*core.Message)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter) *ffapi.FilterResult); ok {
-		r1 = rf(ctx, namespace, filter)
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespace, filter, valueFilter)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*ffapi.FilterResult)
 		}
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.Filter) error); ok {
-		r2 = rf(ctx, namespace, filter)
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.Filter, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespace, filter, valueFilter)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -1699,6 +1958,32 @@ func (_m *Plugin) GetPins(ctx context.Context, namespace string, filter ffapi.Fi
 	return r0, r1, r2
 }
 
+// GetRetentionGuards provides a mock function with given fields: ctx, namespace
+func (_m *Plugin) GetRetentionGuards(ctx context.Context, namespace string) (*database.RetentionGuards, error) {
+	ret := _m.Called(ctx, namespace)
+
+	var r0 *database.RetentionGuards
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*database.RetentionGuards, error)); ok {
+		return rf(ctx, namespace)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *database.RetentionGuards); ok {
+		r0 = rf(ctx, namespace)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*database.RetentionGuards)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, namespace)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetSubscriptionByID provides a mock function with given fields: ctx, namespace, id
 func (_m *Plugin) GetSubscriptionByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.Subscription, error) {
 	ret := _m.Called(ctx, namespace, id)
@@ -2371,6 +2656,20 @@ func (_m *Plugin) InitConfig(_a0 config.Section) {
 	_m.Called(_a0)
 }
 
+// InitSchema provides a mock function with given fields: ctx, _a1
+func (_m *Plugin) InitSchema(ctx context.Context, _a1 config.Section) error {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, config.Section) error); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertBlob provides a mock function with given fields: ctx, blob
 func (_m *Plugin) InsertBlob(ctx context.Context, blob *core.Blob) error {
 	ret := _m.Called(ctx, blob)
@@ -2427,6 +2726,20 @@ func (_m *Plugin) InsertDataArray(ctx context.Context, data core.DataArray) erro
 	return r0
 }
 
+// InsertDeadLetter provides a mock function with given fields: ctx, deadLetter
+func (_m *Plugin) InsertDeadLetter(ctx context.Context, deadLetter *core.DeadLetter) error {
+	ret := _m.Called(ctx, deadLetter)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.DeadLetter) error); ok {
+		r0 = rf(ctx, deadLetter)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertEvent provides a mock function with given fields: ctx, data
 func (_m *Plugin) InsertEvent(ctx context.Context, data *core.Event) error {
 	ret := _m.Called(ctx, data)
@@ -2591,6 +2904,84 @@ func (_m *Plugin) InsertTransaction(ctx context.Context, data *core.Transaction)
 	return r0
 }
 
+// MigrateDown provides a mock function with given fields: ctx, steps
+func (_m *Plugin) MigrateDown(ctx context.Context, steps int) ([]*core.DatabaseMigration, error) {
+	ret := _m.Called(ctx, steps)
+
+	var r0 []*core.DatabaseMigration
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*core.DatabaseMigration, error)); ok {
+		return rf(ctx, steps)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*core.DatabaseMigration); ok {
+		r0 = rf(ctx, steps)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.DatabaseMigration)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, steps)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MigrateUp provides a mock function with given fields: ctx
+func (_m *Plugin) MigrateUp(ctx context.Context) ([]*core.DatabaseMigration, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*core.DatabaseMigration
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*core.DatabaseMigration, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*core.DatabaseMigration); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.DatabaseMigration)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MigrationStatus provides a mock function with given fields: ctx
+func (_m *Plugin) MigrationStatus(ctx context.Context) (*core.DatabaseSchemaStatus, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *core.DatabaseSchemaStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*core.DatabaseSchemaStatus, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *core.DatabaseSchemaStatus); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.DatabaseSchemaStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Name provides a mock function with given fields:
 func (_m *Plugin) Name() string {
 	ret := _m.Called()
@@ -2633,6 +3024,32 @@ func (_m *Plugin) RunAsGroup(ctx context.Context, fn func(context.Context) error
 	return r0
 }
 
+// SchemaStatus provides a mock function with given fields: ctx
+func (_m *Plugin) SchemaStatus(ctx context.Context) (*core.DatabaseSchemaStatus, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *core.DatabaseSchemaStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*core.DatabaseSchemaStatus, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *core.DatabaseSchemaStatus); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.DatabaseSchemaStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SetHandler provides a mock function with given fields: namespace, handler
 func (_m *Plugin) SetHandler(namespace string, handler database.Callbacks) {
 	_m.Called(namespace, handler)
@@ -2680,6 +3097,20 @@ func (_m *Plugin) UpdateData(ctx context.Context, namespace string, id *fftypes.
 	return r0
 }
 
+// UpdateDeadLetter provides a mock function with given fields: ctx, namespace, id, update
+func (_m *Plugin) UpdateDeadLetter(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) error {
+	ret := _m.Called(ctx, namespace, id, update)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID, ffapi.Update) error); ok {
+		r0 = rf(ctx, namespace, id, update)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateMessage provides a mock function with given fields: ctx, namespace, id, update
 func (_m *Plugin) UpdateMessage(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) error {
 	ret := _m.Called(ctx, namespace, id, update)
@@ -227,6 +227,32 @@ func (_m *EventManager) QueueBatchRewind(batchID *fftypes.UUID) {
 	_m.Called(batchID)
 }
 
+// ReplayDeadLetters provides a mock function with given fields: ctx, subDef, ids
+func (_m *EventManager) ReplayDeadLetters(ctx context.Context, subDef *core.Subscription, ids []*fftypes.UUID) ([]*core.DeadLetter, error) {
+	ret := _m.Called(ctx, subDef, ids)
+
+	var r0 []*core.DeadLetter
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.Subscription, []*fftypes.UUID) ([]*core.DeadLetter, error)); ok {
+		return rf(ctx, subDef, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *core.Subscription, []*fftypes.UUID) []*core.DeadLetter); ok {
+		r0 = rf(ctx, subDef, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.DeadLetter)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *core.Subscription, []*fftypes.UUID) error); ok {
+		r1 = rf(ctx, subDef, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SharedStorageBatchDownloaded provides a mock function with given fields: ss, payloadRef, data
 func (_m *EventManager) SharedStorageBatchDownloaded(ss sharedstorage.Plugin, payloadRef string, data []byte) (*fftypes.UUID, error) {
 	ret := _m.Called(ss, payloadRef, data)
@@ -272,6 +298,11 @@ func (_m *EventManager) Start() error {
 	return r0
 }
 
+// StartAggregator provides a mock function with given fields:
+func (_m *EventManager) StartAggregator() {
+	_m.Called()
+}
+
 // SubscriptionUpdates provides a mock function with given fields:
 func (_m *EventManager) SubscriptionUpdates() chan<- *fftypes.UUID {
 	ret := _m.Called()
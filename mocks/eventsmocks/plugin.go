@@ -6,11 +6,9 @@ import (
 	context "context"
 
 	config "github.com/hyperledger/firefly-common/pkg/config"
-
+	fftypes "github.com/hyperledger/firefly-common/pkg/fftypes"
 	core "github.com/hyperledger/firefly/pkg/core"
-
 	events "github.com/hyperledger/firefly/pkg/events"
-
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -19,6 +17,20 @@ type Plugin struct {
 	mock.Mock
 }
 
+// BatchDeliveryRequest provides a mock function with given fields: connID, sub, batchID, _a3
+func (_m *Plugin) BatchDeliveryRequest(connID string, sub *core.Subscription, batchID *fftypes.UUID, _a3 []*core.CombinedEventDataDelivery) error {
+	ret := _m.Called(connID, sub, batchID, _a3)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, *core.Subscription, *fftypes.UUID, []*core.CombinedEventDataDelivery) error); ok {
+		r0 = rf(connID, sub, batchID, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Capabilities provides a mock function with given fields:
 func (_m *Plugin) Capabilities() *events.Capabilities {
 	ret := _m.Called()
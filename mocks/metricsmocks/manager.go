@@ -41,6 +41,16 @@ func (_m *Manager) BlockchainTransaction(location string, methodName string) {
 	_m.Called(location, methodName)
 }
 
+// ContractListenerDeleted provides a mock function with given fields: namespace, listener
+func (_m *Manager) ContractListenerDeleted(namespace string, listener string) {
+	_m.Called(namespace, listener)
+}
+
+// ContractListenerStatus provides a mock function with given fields: namespace, listener, status
+func (_m *Manager) ContractListenerStatus(namespace string, listener string, status *core.ContractListenerStatus) {
+	_m.Called(namespace, listener, status)
+}
+
 // CountBatchPin provides a mock function with given fields:
 func (_m *Manager) CountBatchPin() {
 	_m.Called()
@@ -89,6 +99,11 @@ func (_m *Manager) MessageSubmitted(msg *core.Message) {
 	_m.Called(msg)
 }
 
+// RecordsPruned provides a mock function with given fields: namespace, collection, count
+func (_m *Manager) RecordsPruned(namespace string, collection string, count int64) {
+	_m.Called(namespace, collection, count)
+}
+
 // TransferConfirmed provides a mock function with given fields: transfer
 func (_m *Manager) TransferConfirmed(transfer *core.TokenTransfer) {
 	_m.Called(transfer)
@@ -5,13 +5,17 @@ package namespacemocks
 import (
 	context "context"
 
-	fftypes "github.com/hyperledger/firefly-common/pkg/fftypes"
 	core "github.com/hyperledger/firefly/pkg/core"
+	database "github.com/hyperledger/firefly/pkg/database"
+
+	fftypes "github.com/hyperledger/firefly-common/pkg/fftypes"
 
 	mock "github.com/stretchr/testify/mock"
 
 	orchestrator "github.com/hyperledger/firefly/internal/orchestrator"
 
+	snapshot "github.com/hyperledger/firefly/internal/snapshot"
+
 	spievents "github.com/hyperledger/firefly/internal/spievents"
 )
 
@@ -100,6 +104,58 @@ func (_m *Manager) Init(ctx context.Context, cancelCtx context.CancelFunc, reset
 	return r0
 }
 
+// InitDatabase provides a mock function with given fields: ctx, cancelCtx, name
+func (_m *Manager) InitDatabase(ctx context.Context, cancelCtx context.CancelFunc, name string) (database.Plugin, error) {
+	ret := _m.Called(ctx, cancelCtx, name)
+
+	var r0 database.Plugin
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, context.CancelFunc, string) (database.Plugin, error)); ok {
+		return rf(ctx, cancelCtx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, context.CancelFunc, string) database.Plugin); ok {
+		r0 = rf(ctx, cancelCtx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(database.Plugin)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, context.CancelFunc, string) error); ok {
+		r1 = rf(ctx, cancelCtx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InitSnapshot provides a mock function with given fields: ctx, cancelCtx, name
+func (_m *Manager) InitSnapshot(ctx context.Context, cancelCtx context.CancelFunc, name string) (snapshot.Manager, error) {
+	ret := _m.Called(ctx, cancelCtx, name)
+
+	var r0 snapshot.Manager
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, context.CancelFunc, string) (snapshot.Manager, error)); ok {
+		return rf(ctx, cancelCtx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, context.CancelFunc, string) snapshot.Manager); ok {
+		r0 = rf(ctx, cancelCtx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(snapshot.Manager)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, context.CancelFunc, string) error); ok {
+		r1 = rf(ctx, cancelCtx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // MustOrchestrator provides a mock function with given fields: ns
 func (_m *Manager) MustOrchestrator(ns string) orchestrator.Orchestrator {
 	ret := _m.Called(ns)
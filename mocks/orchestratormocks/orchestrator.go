@@ -37,6 +37,10 @@ import (
 	operations "github.com/hyperledger/firefly/internal/operations"
 
 	privatemessaging "github.com/hyperledger/firefly/internal/privatemessaging"
+
+	retention "github.com/hyperledger/firefly/internal/retention"
+
+	snapshot "github.com/hyperledger/firefly/internal/snapshot"
 )
 
 // Orchestrator is an autogenerated mock type for the Orchestrator type
@@ -220,6 +224,20 @@ func (_m *Orchestrator) DeleteSubscription(ctx context.Context, id string) error
 	return r0
 }
 
+// DeleteSubscriptionDeadLetters provides a mock function with given fields: ctx, id
+func (_m *Orchestrator) DeleteSubscriptionDeadLetters(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Events provides a mock function with given fields:
 func (_m *Orchestrator) Events() events.EventManager {
 	ret := _m.Called()
@@ -384,34 +402,34 @@ func (_m *Orchestrator) GetChartHistogram(ctx context.Context, startTime int64,
 	return r0, r1
 }
 
-// GetData provides a mock function with given fields: ctx, filter
-func (_m *Orchestrator) GetData(ctx context.Context, filter ffapi.AndFilter) (core.DataArray, *ffapi.FilterResult, error) {
-	ret := _m.Called(ctx, filter)
+// GetData provides a mock function with given fields: ctx, filter, valueFilter
+func (_m *Orchestrator) GetData(ctx context.Context, filter ffapi.AndFilter, valueFilter ffapi.Filter) (core.DataArray, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, filter, valueFilter)
 
 	var r0 core.DataArray
 	var r1 *ffapi.FilterResult
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) (core.DataArray, *ffapi.FilterResult, error)); ok {
-		return rf(ctx, filter)
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter, ffapi.Filter) (core.DataArray, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, filter, valueFilter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) core.DataArray); ok {
-		r0 = rf(ctx, filter)
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter, ffapi.Filter) core.DataArray); ok {
+		r0 = rf(ctx, filter, valueFilter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(core.DataArray)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, ffapi.AndFilter) *ffapi.FilterResult); ok {
-		r1 = rf(ctx, filter)
+	if rf, ok := ret.Get(1).(func(context.Context, ffapi.AndFilter, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, filter, valueFilter)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*ffapi.FilterResult)
 		}
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, ffapi.AndFilter) error); ok {
-		r2 = rf(ctx, filter)
+	if rf, ok := ret.Get(2).(func(context.Context, ffapi.AndFilter, ffapi.Filter) error); ok {
+		r2 = rf(ctx, filter, valueFilter)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -819,34 +837,34 @@ func (_m *Orchestrator) GetMessageTransaction(ctx context.Context, id string) (*
 	return r0, r1
 }
 
-// GetMessages provides a mock function with given fields: ctx, filter
-func (_m *Orchestrator) GetMessages(ctx context.Context, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error) {
-	ret := _m.Called(ctx, filter)
+// GetMessages provides a mock function with given fields: ctx, filter, valueFilter
+func (_m *Orchestrator) GetMessages(ctx context.Context, filter ffapi.AndFilter, valueFilter ffapi.Filter) ([]*core.Message, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, filter, valueFilter)
 
 	var r0 []*core.Message
 	var r1 *ffapi.FilterResult
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error)); ok {
-		return rf(ctx, filter)
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter, ffapi.Filter) ([]*core.Message, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, filter, valueFilter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) []*core.Message); ok {
-		r0 = rf(ctx, filter)
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter, ffapi.Filter) []*core.Message); ok {
+		r0 = rf(ctx, filter, valueFilter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*core.Message)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, ffapi.AndFilter) *ffapi.FilterResult); ok {
-		r1 = rf(ctx, filter)
+	if rf, ok := ret.Get(1).(func(context.Context, ffapi.AndFilter, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, filter, valueFilter)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*ffapi.FilterResult)
 		}
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, ffapi.AndFilter) error); ok {
-		r2 = rf(ctx, filter)
+	if rf, ok := ret.Get(2).(func(context.Context, ffapi.AndFilter, ffapi.Filter) error); ok {
+		r2 = rf(ctx, filter, valueFilter)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -889,34 +907,34 @@ func (_m *Orchestrator) GetMessagesForData(ctx context.Context, dataID string, f
 	return r0, r1, r2
 }
 
-// GetMessagesWithData provides a mock function with given fields: ctx, filter
-func (_m *Orchestrator) GetMessagesWithData(ctx context.Context, filter ffapi.AndFilter) ([]*core.MessageInOut, *ffapi.FilterResult, error) {
-	ret := _m.Called(ctx, filter)
+// GetMessagesWithData provides a mock function with given fields: ctx, filter, valueFilter
+func (_m *Orchestrator) GetMessagesWithData(ctx context.Context, filter ffapi.AndFilter, valueFilter ffapi.Filter) ([]*core.MessageInOut, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, filter, valueFilter)
 
 	var r0 []*core.MessageInOut
 	var r1 *ffapi.FilterResult
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) ([]*core.MessageInOut, *ffapi.FilterResult, error)); ok {
-		return rf(ctx, filter)
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter, ffapi.Filter) ([]*core.MessageInOut, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, filter, valueFilter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) []*core.MessageInOut); ok {
-		r0 = rf(ctx, filter)
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter, ffapi.Filter) []*core.MessageInOut); ok {
+		r0 = rf(ctx, filter, valueFilter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*core.MessageInOut)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, ffapi.AndFilter) *ffapi.FilterResult); ok {
-		r1 = rf(ctx, filter)
+	if rf, ok := ret.Get(1).(func(context.Context, ffapi.AndFilter, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, filter, valueFilter)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*ffapi.FilterResult)
 		}
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, ffapi.AndFilter) error); ok {
-		r2 = rf(ctx, filter)
+	if rf, ok := ret.Get(2).(func(context.Context, ffapi.AndFilter, ffapi.Filter) error); ok {
+		r2 = rf(ctx, filter, valueFilter)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -1175,6 +1193,41 @@ func (_m *Orchestrator) GetSubscriptionByIDWithStatus(ctx context.Context, id st
 	return r0, r1
 }
 
+// GetSubscriptionDeadLetters provides a mock function with given fields: ctx, id, filter
+func (_m *Orchestrator) GetSubscriptionDeadLetters(ctx context.Context, id string, filter ffapi.AndFilter) ([]*core.DeadLetter, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, id, filter)
+
+	var r0 []*core.DeadLetter
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.AndFilter) ([]*core.DeadLetter, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, id, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.AndFilter) []*core.DeadLetter); ok {
+		r0 = rf(ctx, id, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.DeadLetter)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, id, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, id, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetSubscriptions provides a mock function with given fields: ctx, filter
 func (_m *Orchestrator) GetSubscriptions(ctx context.Context, filter ffapi.AndFilter) ([]*core.Subscription, *ffapi.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
@@ -1461,6 +1514,32 @@ func (_m *Orchestrator) PrivateMessaging() privatemessaging.Manager {
 	return r0
 }
 
+// ReplaySubscriptionDeadLetters provides a mock function with given fields: ctx, id, replay
+func (_m *Orchestrator) ReplaySubscriptionDeadLetters(ctx context.Context, id string, replay *core.DeadLetterReplay) ([]*core.DeadLetter, error) {
+	ret := _m.Called(ctx, id, replay)
+
+	var r0 []*core.DeadLetter
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *core.DeadLetterReplay) ([]*core.DeadLetter, error)); ok {
+		return rf(ctx, id, replay)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *core.DeadLetterReplay) []*core.DeadLetter); ok {
+		r0 = rf(ctx, id, replay)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.DeadLetter)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *core.DeadLetterReplay) error); ok {
+		r1 = rf(ctx, id, replay)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RequestReply provides a mock function with given fields: ctx, msg
 func (_m *Orchestrator) RequestReply(ctx context.Context, msg *core.MessageInOut) (*core.MessageInOut, error) {
 	ret := _m.Called(ctx, msg)
@@ -1487,6 +1566,22 @@ func (_m *Orchestrator) RequestReply(ctx context.Context, msg *core.MessageInOut
 	return r0, r1
 }
 
+// Retention provides a mock function with given fields:
+func (_m *Orchestrator) Retention() retention.Manager {
+	ret := _m.Called()
+
+	var r0 retention.Manager
+	if rf, ok := ret.Get(0).(func() retention.Manager); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(retention.Manager)
+		}
+	}
+
+	return r0
+}
+
 // RewindPins provides a mock function with given fields: ctx, rewind
 func (_m *Orchestrator) RewindPins(ctx context.Context, rewind *core.PinRewind) (*core.PinRewind, error) {
 	ret := _m.Called(ctx, rewind)
@@ -1513,6 +1608,22 @@ func (_m *Orchestrator) RewindPins(ctx context.Context, rewind *core.PinRewind)
 	return r0, r1
 }
 
+// Snapshot provides a mock function with given fields:
+func (_m *Orchestrator) Snapshot() snapshot.Manager {
+	ret := _m.Called()
+
+	var r0 snapshot.Manager
+	if rf, ok := ret.Get(0).(func() snapshot.Manager); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(snapshot.Manager)
+		}
+	}
+
+	return r0
+}
+
 // Start provides a mock function with given fields:
 func (_m *Orchestrator) Start() error {
 	ret := _m.Called()
@@ -0,0 +1,56 @@
+// Code generated by mockery v2.20.2. DO NOT EDIT.
+
+package retentionmocks
+
+import (
+	context "context"
+
+	core "github.com/hyperledger/firefly/pkg/core"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Manager is an autogenerated mock type for the Manager type
+type Manager struct {
+	mock.Mock
+}
+
+// GetStatus provides a mock function with given fields: ctx
+func (_m *Manager) GetStatus(ctx context.Context) *core.RetentionStatus {
+	ret := _m.Called(ctx)
+
+	var r0 *core.RetentionStatus
+	if rf, ok := ret.Get(0).(func(context.Context) *core.RetentionStatus); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.RetentionStatus)
+		}
+	}
+
+	return r0
+}
+
+// Start provides a mock function with given fields:
+func (_m *Manager) Start() {
+	_m.Called()
+}
+
+// WaitStop provides a mock function with given fields:
+func (_m *Manager) WaitStop() {
+	_m.Called()
+}
+
+type mockConstructorTestingTNewManager interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewManager creates a new instance of Manager. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewManager(t mockConstructorTestingTNewManager) *Manager {
+	mock := &Manager{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -85,6 +85,14 @@ type Plugin interface {
 	// InvokeContract submits a new transaction to be executed by custom on-chain logic
 	InvokeContract(ctx context.Context, nsOpID, signingKey string, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}, errors []*fftypes.FFIError, options map[string]interface{}, batch *BatchPin) error
 
+	// InvokeContractBatch submits a single transaction that executes all of the supplied calls atomically.
+	// Only called if the AtomicInvokeBatch capability is set
+	InvokeContractBatch(ctx context.Context, nsOpID, signingKey string, calls []*ContractCall, options map[string]interface{}) error
+
+	// DecodeInvokeBatchOutput decodes the result of each call of an atomic batch, from the output of its succeeded operation.
+	// Returns nil if the output does not include the return value of the transaction, as not all connectors report it.
+	DecodeInvokeBatchOutput(ctx context.Context, calls []*ContractCall, output fftypes.JSONObject) ([]*fftypes.JSONAny, error)
+
 	// QueryContract executes a method via custom on-chain logic and returns the result
 	QueryContract(ctx context.Context, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}, errors []*fftypes.FFIError, options map[string]interface{}) (interface{}, error)
 
@@ -95,7 +103,7 @@ type Plugin interface {
 	DeleteContractListener(ctx context.Context, subscription *core.ContractListener, okNotFound bool) error
 
 	// GetContractListenerStatus gets the status of a contract listener from the backend connector. Returns false if not found
-	GetContractListenerStatus(ctx context.Context, subID string, okNotFound bool) (bool, interface{}, error)
+	GetContractListenerStatus(ctx context.Context, subID string, okNotFound bool) (bool, *core.ContractListenerStatus, error)
 
 	// GetFFIParamValidator returns a blockchain-plugin-specific validator for FFIParams and their JSON Schema
 	GetFFIParamValidator(ctx context.Context) (fftypes.FFIParamValidator, error)
@@ -109,6 +117,11 @@ type Plugin interface {
 	// GenerateEventSignature generates a strigified signature for the event, incorporating any fields significant to identifying the event as unique
 	GenerateEventSignature(ctx context.Context, event *fftypes.FFIEventDefinition) string
 
+	// GenerateEventSignatureWithLocation generates the signature of the event qualified by the location of the contract,
+	// in the form "<location>:<signature>" where the location is formatted as in the Location of received events.
+	// With no location this is the same as GenerateEventSignature. Used to identify the filter of a listener that matched an event.
+	GenerateEventSignatureWithLocation(ctx context.Context, event *fftypes.FFIEventDefinition, location *fftypes.JSONAny) (string, error)
+
 	// GenerateErrorSignature generates a strigified signature for the custom error, incorporating any fields significant to identifying the error as unique
 	GenerateErrorSignature(ctx context.Context, errorDef *fftypes.FFIErrorDefinition) string
 
@@ -161,6 +174,16 @@ type Callbacks interface {
 // Capabilities the supported featureset of the blockchain
 // interface implemented by the plugin, with the specified config
 type Capabilities struct {
+	// AtomicInvokeBatch is set if multiple contract invocations can be submitted as a single atomic transaction
+	AtomicInvokeBatch bool
+}
+
+// ContractCall is a single invocation of custom on-chain logic, within an atomic batch
+type ContractCall struct {
+	Location *fftypes.JSONAny
+	Method   *fftypes.FFIMethod
+	Input    map[string]interface{}
+	Errors   []*fftypes.FFIError
 }
 
 // MultipartyContract represents the location and configuration of a FireFly multiparty contract for batch pinning of messages
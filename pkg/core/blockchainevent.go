@@ -29,4 +29,5 @@ type BlockchainEvent struct {
 	Info       fftypes.JSONObject       `ffstruct:"BlockchainEvent" json:"info,omitempty"`
 	Timestamp  *fftypes.FFTime          `ffstruct:"BlockchainEvent" json:"timestamp,omitempty"`
 	TX         BlockchainTransactionRef `ffstruct:"BlockchainEvent" json:"tx"`
+	Filter     *int                     `ffstruct:"BlockchainEvent" json:"filter,omitempty"`
 }
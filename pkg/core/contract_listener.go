@@ -37,23 +37,54 @@ type ContractListener struct {
 	Signature string                   `ffstruct:"ContractListener" json:"signature" ffexcludeinput:"true"`
 	Topic     string                   `ffstruct:"ContractListener" json:"topic,omitempty"`
 	Options   *ContractListenerOptions `ffstruct:"ContractListener" json:"options,omitempty"`
+	Filters   ListenerFilters          `ffstruct:"ContractListener" json:"filters,omitempty" ffexcludeinput:"true"`
+}
+
+// ListenerFilter selects an event to be detected by a contract listener, optionally only at a given
+// location, and only when its indexed parameters have the given values
+type ListenerFilter struct {
+	Interface *fftypes.FFIReference `ffstruct:"ListenerFilter" json:"interface,omitempty"`
+	Event     *FFISerializedEvent   `ffstruct:"ListenerFilter" json:"event,omitempty"`
+	Location  *fftypes.JSONAny      `ffstruct:"ListenerFilter" json:"location,omitempty"`
+	Indexed   fftypes.JSONObject    `ffstruct:"ListenerFilter" json:"indexed,omitempty"`
+	Signature string                `ffstruct:"ListenerFilter" json:"signature,omitempty" ffexcludeinput:"true"`
+}
+
+type ListenerFilters []*ListenerFilter
+
+type ListenerFilterInput struct {
+	ListenerFilter
+	EventPath string `ffstruct:"ListenerFilter" json:"eventPath,omitempty"`
 }
 
 type ContractListenerWithStatus struct {
 	ContractListener
-	Status interface{} `ffstruct:"ContractListenerWithStatus" json:"status,omitempty" ffexcludeinput:"true"`
+	Status *ContractListenerStatus `ffstruct:"ContractListenerWithStatus" json:"status,omitempty" ffexcludeinput:"true"`
 }
-type ContractListenerOptions struct {
-	FirstEvent string `ffstruct:"ContractListenerOptions" json:"firstEvent,omitempty"`
+
+// ContractListenerStatus is the progress of a contract listener through the chain, as reported by the
+// blockchain connector. Fields that the connector cannot report are omitted.
+type ContractListenerStatus struct {
+	Checkpoint *ContractListenerCheckpoint `ffstruct:"ContractListenerStatus" json:"checkpoint,omitempty"`
+	HeadBlock  *int64                      `ffstruct:"ContractListenerStatus" json:"headBlock,omitempty"`
+	Catchup    bool                        `ffstruct:"ContractListenerStatus" json:"catchup"`
+	Lag        *int64                      `ffstruct:"ContractListenerStatus" json:"lag,omitempty"`
+	Error      string                      `ffstruct:"ContractListenerStatus" json:"error,omitempty"`
 }
 
-type ListenerStatusError struct {
-	StatusError string `ffstruct:"ListenerStatusError" json:"error,omitempty"`
+type ContractListenerCheckpoint struct {
+	Block            int64 `ffstruct:"ContractListenerCheckpoint" json:"block"`
+	TransactionIndex int64 `ffstruct:"ContractListenerCheckpoint" json:"transactionIndex"`
+	LogIndex         int64 `ffstruct:"ContractListenerCheckpoint" json:"logIndex"`
+}
+type ContractListenerOptions struct {
+	FirstEvent string `ffstruct:"ContractListenerOptions" json:"firstEvent,omitempty"`
 }
 
 type ContractListenerInput struct {
 	ContractListener
-	EventPath string `ffstruct:"ContractListener" json:"eventPath,omitempty"`
+	EventPath string                 `ffstruct:"ContractListener" json:"eventPath,omitempty"`
+	Filters   []*ListenerFilterInput `ffstruct:"ContractListener" json:"filters,omitempty"`
 }
 
 type FFISerializedEvent struct {
@@ -80,6 +111,28 @@ func (fse FFISerializedEvent) Value() (driver.Value, error) {
 	return bytes, nil
 }
 
+// Scan implements sql.Scanner
+func (lf *ListenerFilters) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(src), &lf)
+	case []byte:
+		return json.Unmarshal(src, &lf)
+	default:
+		return i18n.NewError(context.Background(), i18n.MsgTypeRestoreFailed, src, lf)
+	}
+}
+
+func (lf ListenerFilters) Value() (driver.Value, error) {
+	if lf == nil {
+		return nil, nil
+	}
+	bytes, _ := json.Marshal(lf)
+	return bytes, nil
+}
+
 // Scan implements sql.Scanner
 func (o *ContractListenerOptions) Scan(src interface{}) error {
 	switch src := src.(type) {
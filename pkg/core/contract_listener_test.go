@@ -96,3 +96,43 @@ func TestContractListenerOptionsValue(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, `{"firstEvent":"newest"}`, string(val.([]byte)))
 }
+
+func TestListenerFiltersScan(t *testing.T) {
+	var filters ListenerFilters
+	err := filters.Scan([]byte(`[{"event":{"name":"Changed"},"indexed":{"from":"0x123"},"signature":"Changed()"}]`))
+	assert.NoError(t, err)
+	assert.Len(t, filters, 1)
+	assert.Equal(t, "Changed", filters[0].Event.Name)
+	assert.Equal(t, "0x123", filters[0].Indexed.GetString("from"))
+}
+
+func TestListenerFiltersScanNil(t *testing.T) {
+	var filters ListenerFilters
+	err := filters.Scan(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, filters)
+}
+
+func TestListenerFiltersScanString(t *testing.T) {
+	var filters ListenerFilters
+	err := filters.Scan(`[{"signature":"Changed()"}]`)
+	assert.NoError(t, err)
+	assert.Equal(t, "Changed()", filters[0].Signature)
+}
+
+func TestListenerFiltersScanError(t *testing.T) {
+	var filters ListenerFilters
+	err := filters.Scan(false)
+	assert.Regexp(t, "FF00105", err)
+}
+
+func TestListenerFiltersValue(t *testing.T) {
+	filters := ListenerFilters{{Signature: "Changed()"}}
+	val, err := filters.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"signature":"Changed()"}]`, string(val.([]byte)))
+
+	val, err = ListenerFilters(nil).Value()
+	assert.NoError(t, err)
+	assert.Nil(t, val)
+}
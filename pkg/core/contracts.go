@@ -41,8 +41,28 @@ type ContractCallRequest struct {
 	Input          map[string]interface{} `ffstruct:"ContractCallRequest" json:"input"`
 	Errors         []*fftypes.FFIError    `ffstruct:"ContractCallRequest" json:"errors,omitempty" ffexcludeinput:"postContractAPIInvoke,postContractAPIQuery"`
 	Options        map[string]interface{} `ffstruct:"ContractCallRequest" json:"options"`
-	Message        *MessageInOut          `ffstruct:"ContractCallRequest" json:"message,omitempty" ffexcludeinput:"postContractQuery,postContractAPIQuery"`
-	IdempotencyKey IdempotencyKey         `ffstruct:"ContractCallRequest" json:"idempotencyKey,omitempty" ffexcludeoutput:"true"`
+	Message        *MessageInOut          `ffstruct:"ContractCallRequest" json:"message,omitempty" ffexcludeinput:"postContractQuery,postContractAPIQuery,postContractInvokeBatch"`
+	IdempotencyKey IdempotencyKey         `ffstruct:"ContractCallRequest" json:"idempotencyKey,omitempty" ffexcludeoutput:"true" ffexcludeinput:"postContractInvokeBatch"`
+}
+
+type ContractCallBatchRequest struct {
+	Key            string                 `ffstruct:"ContractCallBatchRequest" json:"key,omitempty"`
+	Atomic         bool                   `ffstruct:"ContractCallBatchRequest" json:"atomic,omitempty"`
+	Calls          []*ContractCallRequest `ffstruct:"ContractCallBatchRequest" json:"calls"`
+	Options        map[string]interface{} `ffstruct:"ContractCallBatchRequest" json:"options,omitempty"`
+	IdempotencyKey IdempotencyKey         `ffstruct:"ContractCallBatchRequest" json:"idempotencyKey,omitempty" ffexcludeoutput:"true"`
+}
+
+type ContractCallBatchResponse struct {
+	Transaction *fftypes.UUID              `ffstruct:"ContractCallBatchResponse" json:"tx"`
+	Atomic      bool                       `ffstruct:"ContractCallBatchResponse" json:"atomic"`
+	Calls       []*ContractCallBatchResult `ffstruct:"ContractCallBatchResponse" json:"calls"`
+}
+
+type ContractCallBatchResult struct {
+	Index     int              `ffstruct:"ContractCallBatchResult" json:"index"`
+	Operation *Operation       `ffstruct:"ContractCallBatchResult" json:"operation"`
+	Output    *fftypes.JSONAny `ffstruct:"ContractCallBatchResult" json:"output,omitempty"`
 }
 
 type ContractDeployRequest struct {
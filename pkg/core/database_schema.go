@@ -0,0 +1,32 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+// DatabaseSchemaStatus is the version of the database schema, compared to the migrations available to the node
+type DatabaseSchemaStatus struct {
+	Version uint                 `ffstruct:"DatabaseSchemaStatus" json:"version"`
+	Dirty   bool                 `ffstruct:"DatabaseSchemaStatus" json:"dirty"`
+	Latest  uint                 `ffstruct:"DatabaseSchemaStatus" json:"latest"`
+	Pending []*DatabaseMigration `ffstruct:"DatabaseSchemaStatus" json:"pending,omitempty"`
+}
+
+// DatabaseMigration is a single numbered migration of the database schema
+type DatabaseMigration struct {
+	Version uint   `ffstruct:"DatabaseMigration" json:"version"`
+	Name    string `ffstruct:"DatabaseMigration" json:"name"`
+	SQL     string `ffstruct:"DatabaseMigration" json:"sql,omitempty"`
+}
@@ -0,0 +1,38 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "github.com/hyperledger/firefly-common/pkg/fftypes"
+
+// DeadLetter records an event that could not be delivered to a subscription, after exhausting the
+// retry policy of that subscription. The subscription moves on past the event, and the dead letter
+// can be replayed or purged later.
+type DeadLetter struct {
+	ID           *fftypes.UUID   `ffstruct:"DeadLetter" json:"id"`
+	Namespace    string          `ffstruct:"DeadLetter" json:"namespace"`
+	Subscription SubscriptionRef `ffstruct:"DeadLetter" json:"subscription"`
+	Event        *fftypes.UUID   `ffstruct:"DeadLetter" json:"event"`
+	Attempts     int64           `ffstruct:"DeadLetter" json:"attempts"`
+	Reason       string          `ffstruct:"DeadLetter" json:"reason,omitempty"`
+	Created      *fftypes.FFTime `ffstruct:"DeadLetter" json:"created"`
+	Updated      *fftypes.FFTime `ffstruct:"DeadLetter" json:"updated,omitempty"`
+}
+
+// DeadLetterReplay is the input to a request to replay dead letters for a subscription
+type DeadLetterReplay struct {
+	IDs []*fftypes.UUID `ffstruct:"DeadLetterReplay" json:"ids,omitempty"`
+}
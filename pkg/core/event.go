@@ -97,6 +97,17 @@ type EnrichedEvent struct {
 type EventDelivery struct {
 	EnrichedEvent
 	Subscription SubscriptionRef `json:"subscription"`
+
+	// Transformed is the output of the transform template of the subscription, if it has one.
+	// Transports deliver this in place of the event and its data.
+	Transformed *fftypes.JSONAny `json:"-"`
+}
+
+// CombinedEventDataDelivery pairs an event with the data of its message (if the subscription is set to include data),
+// for transports that deliver a batch of events in a single request
+type CombinedEventDataDelivery struct {
+	Event *EventDelivery
+	Data  DataArray
 }
 
 // EventDeliveryResponse is the payload an application sends back, to confirm it has accepted (or rejected) the event and as such
@@ -0,0 +1,27 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+type KafkaSubOptions struct {
+	Kafka *KafkaOptions `ffstruct:"KafkaSubOptions" json:"kafka,omitempty"`
+}
+
+type KafkaOptions struct {
+	Topic        string            `ffstruct:"KafkaOptions" json:"topic,omitempty"`
+	PartitionKey string            `ffstruct:"KafkaOptions" json:"partitionKey,omitempty"`
+	Headers      map[string]string `ffstruct:"KafkaOptions" json:"headers,omitempty"`
+}
@@ -25,6 +25,7 @@ type NamespaceStatus struct {
 	Org        *NamespaceStatusOrg       `ffstruct:"NamespaceStatus" json:"org,omitempty"`
 	Plugins    NamespaceStatusPlugins    `ffstruct:"NamespaceStatus" json:"plugins"`
 	Multiparty NamespaceStatusMultiparty `ffstruct:"NamespaceStatus" json:"multiparty"`
+	Schema     *DatabaseSchemaStatus     `ffstruct:"NamespaceStatus" json:"schema,omitempty"`
 }
 
 // NamespaceStatusNode is the information about the local node, returned in the namespace status
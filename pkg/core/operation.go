@@ -39,6 +39,8 @@ var (
 	OpTypeBlockchainContractDeploy = fftypes.FFEnumValue("optype", "blockchain_deploy")
 	// OpTypeBlockchainInvoke is a smart contract invoke
 	OpTypeBlockchainInvoke = fftypes.FFEnumValue("optype", "blockchain_invoke")
+	// OpTypeBlockchainInvokeBatch is a set of smart contract invokes, submitted atomically as a single blockchain transaction
+	OpTypeBlockchainInvokeBatch = fftypes.FFEnumValue("optype", "blockchain_invoke_batch")
 	// OpTypeSharedStorageUploadBatch is a shared storage operation to upload broadcast data
 	OpTypeSharedStorageUploadBatch = fftypes.FFEnumValue("optype", "sharedstorage_upload_batch")
 	// OpTypeSharedStorageUploadBlob is a shared storage operation to upload blob data
@@ -65,6 +67,7 @@ var (
 
 func (op *Operation) IsBlockchainOperation() bool {
 	return op.Type == OpTypeBlockchainInvoke ||
+		op.Type == OpTypeBlockchainInvokeBatch ||
 		op.Type == OpTypeBlockchainNetworkAction ||
 		op.Type == OpTypeBlockchainPinBatch ||
 		op.Type == OpTypeBlockchainContractDeploy
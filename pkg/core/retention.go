@@ -0,0 +1,45 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "github.com/hyperledger/firefly-common/pkg/fftypes"
+
+// RetentionStatus is the status of the data retention policy of a namespace
+type RetentionStatus struct {
+	Enabled     bool                         `ffstruct:"RetentionStatus" json:"enabled"`
+	Interval    *fftypes.FFDuration          `ffstruct:"RetentionStatus" json:"interval,omitempty"`
+	LastRun     *fftypes.FFTime              `ffstruct:"RetentionStatus" json:"lastRun,omitempty"`
+	LastError   string                       `ffstruct:"RetentionStatus" json:"lastError,omitempty"`
+	Protected   *RetentionProtected          `ffstruct:"RetentionStatus" json:"protected,omitempty"`
+	Collections []*RetentionCollectionStatus `ffstruct:"RetentionStatus" json:"collections"`
+}
+
+// RetentionProtected are the bounds that protect records still needed by the node from being pruned
+type RetentionProtected struct {
+	EventSequence *int64 `ffstruct:"RetentionProtected" json:"eventSequence,omitempty"`
+	PinSequence   int64  `ffstruct:"RetentionProtected" json:"pinSequence"`
+}
+
+// RetentionCollectionStatus is the retention policy of a single collection, and what has been pruned from it
+type RetentionCollectionStatus struct {
+	Collection  string              `ffstruct:"RetentionCollectionStatus" json:"collection"`
+	MaxAge      *fftypes.FFDuration `ffstruct:"RetentionCollectionStatus" json:"maxAge,omitempty"`
+	MaxRows     int64               `ffstruct:"RetentionCollectionStatus" json:"maxRows,omitempty"`
+	LastPruned  int64               `ffstruct:"RetentionCollectionStatus" json:"lastPruned"`
+	TotalPruned int64               `ffstruct:"RetentionCollectionStatus" json:"totalPruned"`
+	LastError   string              `ffstruct:"RetentionCollectionStatus" json:"lastError,omitempty"`
+}
@@ -37,6 +37,7 @@ type SubscriptionFilter struct {
 	DeprecatedTag    string                `ffstruct:"SubscriptionFilter" json:"tag,omitempty"`
 	DeprecatedGroup  string                `ffstruct:"SubscriptionFilter" json:"group,omitempty"`
 	DeprecatedAuthor string                `ffstruct:"SubscriptionFilter" json:"author,omitempty"`
+	Expression       string                `ffstruct:"SubscriptionFilter" json:"expression,omitempty"`
 }
 
 func NewSubscriptionFilterFromQuery(query url.Values) SubscriptionFilter {
@@ -59,6 +60,7 @@ func NewSubscriptionFilterFromQuery(query url.Values) SubscriptionFilter {
 		DeprecatedTopics: query.Get("filter.topics"),
 		DeprecatedGroup:  query.Get("filter.group"),
 		DeprecatedAuthor: query.Get("filter.author"),
+		Expression:       query.Get("filter.expression"),
 	}
 }
 
@@ -87,17 +89,44 @@ const (
 	SubOptsFirstEventNewest SubOptsFirstEvent = "newest"
 )
 
+// SubOptsPartitionKey is the field of an event used to partition in-flight deliveries, so that ordering is
+// only maintained between events with the same value of that field
+type SubOptsPartitionKey string
+
+const (
+	// SubOptsPartitionKeyTopic partitions events by topic
+	SubOptsPartitionKeyTopic SubOptsPartitionKey = "topic"
+	// SubOptsPartitionKeyGroup partitions events by the privacy group of the message
+	SubOptsPartitionKeyGroup SubOptsPartitionKey = "group"
+	// SubOptsPartitionKeyAuthor partitions events by the author of the message
+	SubOptsPartitionKeyAuthor SubOptsPartitionKey = "author"
+	// SubOptsPartitionKeyTransaction partitions events by transaction ID
+	SubOptsPartitionKeyTransaction SubOptsPartitionKey = "tx"
+)
+
 // SubscriptionCoreOptions are the core options that apply across all transports
 type SubscriptionCoreOptions struct {
-	FirstEvent *SubOptsFirstEvent `ffstruct:"SubscriptionCoreOptions" json:"firstEvent,omitempty"`
-	ReadAhead  *uint16            `ffstruct:"SubscriptionCoreOptions" json:"readAhead,omitempty"`
-	WithData   *bool              `ffstruct:"SubscriptionCoreOptions" json:"withData,omitempty"`
+	FirstEvent   *SubOptsFirstEvent   `ffstruct:"SubscriptionCoreOptions" json:"firstEvent,omitempty"`
+	ReadAhead    *uint16              `ffstruct:"SubscriptionCoreOptions" json:"readAhead,omitempty"`
+	WithData     *bool                `ffstruct:"SubscriptionCoreOptions" json:"withData,omitempty"`
+	Batch        *bool                `ffstruct:"SubscriptionCoreOptions" json:"batch,omitempty"`
+	BatchTimeout *fftypes.FFDuration  `ffstruct:"SubscriptionCoreOptions" json:"batchTimeout,omitempty"`
+	Retry        *SubscriptionRetry   `ffstruct:"SubscriptionCoreOptions" json:"retry,omitempty"`
+	Transform    *string              `ffstruct:"SubscriptionCoreOptions" json:"transform,omitempty"`
+	PartitionKey *SubOptsPartitionKey `ffstruct:"SubscriptionCoreOptions" json:"partitionKey,omitempty"`
+}
+
+// SubscriptionRetry is the retry policy for a subscription. Once an event has been rejected by the
+// transport MaxAttempts times, it is moved to the dead-letter collection and the subscription moves on.
+type SubscriptionRetry struct {
+	MaxAttempts uint32 `ffstruct:"SubscriptionRetry" json:"maxAttempts,omitempty"`
 }
 
 // SubscriptionOptions customize the behavior of subscriptions
 type SubscriptionOptions struct {
 	SubscriptionCoreOptions
 	WebhookSubOptions
+	KafkaSubOptions
 
 	// Extensible by the specific transport - so we serialize/de-serialize via map.
 	additionalOptions fftypes.JSONObject
@@ -143,6 +172,11 @@ func (so *SubscriptionOptions) UnmarshalJSON(b []byte) error {
 	delete(so.additionalOptions, "firstEvent")
 	delete(so.additionalOptions, "readAhead")
 	delete(so.additionalOptions, "withData")
+	delete(so.additionalOptions, "batch")
+	delete(so.additionalOptions, "batchTimeout")
+	delete(so.additionalOptions, "retry")
+	delete(so.additionalOptions, "transform")
+	delete(so.additionalOptions, "partitionKey")
 	return nil
 }
 
@@ -159,6 +193,21 @@ func (so SubscriptionOptions) MarshalJSON() ([]byte, error) {
 	if so.ReadAhead != nil {
 		so.additionalOptions["readAhead"] = float64(*so.ReadAhead)
 	}
+	if so.Batch != nil {
+		so.additionalOptions["batch"] = so.Batch
+	}
+	if so.BatchTimeout != nil {
+		so.additionalOptions["batchTimeout"] = so.BatchTimeout.String()
+	}
+	if so.Retry != nil {
+		so.additionalOptions["retry"] = so.Retry
+	}
+	if so.Transform != nil {
+		so.additionalOptions["transform"] = *so.Transform
+	}
+	if so.PartitionKey != nil {
+		so.additionalOptions["partitionKey"] = *so.PartitionKey
+	}
 	return json.Marshal(&so.additionalOptions)
 }
 
@@ -20,7 +20,9 @@ import (
 	"encoding/json"
 	"net/url"
 	"testing"
+	"time"
 
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -94,6 +96,87 @@ func TestSubscriptionOptionsDatabaseSerialization(t *testing.T) {
 	assert.Regexp(t, "FF00105", err)
 }
 
+func TestSubscriptionOptionsBatchSerialization(t *testing.T) {
+	yes := true
+	batchTimeout := fftypes.FFDuration(250 * time.Millisecond)
+	opts := SubscriptionOptions{
+		SubscriptionCoreOptions: SubscriptionCoreOptions{
+			Batch:        &yes,
+			BatchTimeout: &batchTimeout,
+		},
+	}
+
+	b, err := opts.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"batch":true,"batchTimeout":"250ms"}`, string(b.([]byte)))
+
+	var opts2 SubscriptionOptions
+	err = opts2.Scan(b)
+	assert.NoError(t, err)
+	assert.True(t, *opts2.Batch)
+	assert.Equal(t, batchTimeout, *opts2.BatchTimeout)
+	assert.Nil(t, opts2.TransportOptions()["batch"])
+	assert.Nil(t, opts2.TransportOptions()["batchTimeout"])
+}
+
+func TestSubscriptionOptionsRetrySerialization(t *testing.T) {
+	opts := SubscriptionOptions{
+		SubscriptionCoreOptions: SubscriptionCoreOptions{
+			Retry: &SubscriptionRetry{
+				MaxAttempts: 5,
+			},
+		},
+	}
+
+	b, err := opts.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"retry":{"maxAttempts":5}}`, string(b.([]byte)))
+
+	var opts2 SubscriptionOptions
+	err = opts2.Scan(b)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(5), opts2.Retry.MaxAttempts)
+	assert.Nil(t, opts2.TransportOptions()["retry"])
+}
+
+func TestSubscriptionOptionsTransformSerialization(t *testing.T) {
+	transform := `{"id":{{json .id}}}`
+	opts := SubscriptionOptions{
+		SubscriptionCoreOptions: SubscriptionCoreOptions{
+			Transform: &transform,
+		},
+	}
+
+	b, err := opts.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"transform":"{\"id\":{{json .id}}}"}`, string(b.([]byte)))
+
+	var opts2 SubscriptionOptions
+	err = opts2.Scan(b)
+	assert.NoError(t, err)
+	assert.Equal(t, transform, *opts2.Transform)
+	assert.Nil(t, opts2.TransportOptions()["transform"])
+}
+
+func TestSubscriptionOptionsPartitionKeySerialization(t *testing.T) {
+	partitionKey := SubOptsPartitionKeyTopic
+	opts := SubscriptionOptions{
+		SubscriptionCoreOptions: SubscriptionCoreOptions{
+			PartitionKey: &partitionKey,
+		},
+	}
+
+	b, err := opts.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"partitionKey":"topic"}`, string(b.([]byte)))
+
+	var opts2 SubscriptionOptions
+	err = opts2.Scan(b)
+	assert.NoError(t, err)
+	assert.Equal(t, SubOptsPartitionKeyTopic, *opts2.PartitionKey)
+	assert.Nil(t, opts2.TransportOptions()["partitionKey"])
+}
+
 func TestSubscriptionUnMarshalFail(t *testing.T) {
 
 	b, err := json.Marshal(&SubscriptionOptions{})
@@ -108,7 +191,7 @@ func TestSubscriptionUnMarshalFail(t *testing.T) {
 }
 
 func TestNewSubscriptionFilterFromQuery(t *testing.T) {
-	query, _ := url.ParseQuery("filter.events=message_confirmed&filter.topic=topic1&filter.message.author=did:firefly:org/author1&filter.blockchain.name=flapflip&filter.transaction.type=test&filter.group=deprecated")
+	query, _ := url.ParseQuery("filter.events=message_confirmed&filter.topic=topic1&filter.message.author=did:firefly:org/author1&filter.blockchain.name=flapflip&filter.transaction.type=test&filter.group=deprecated&filter.expression=message.header.cid%3Dabc")
 	expectedFilter := SubscriptionFilter{
 		Events: "message_confirmed",
 		Topic:  "topic1",
@@ -122,6 +205,7 @@ func TestNewSubscriptionFilterFromQuery(t *testing.T) {
 			Type: "test",
 		},
 		DeprecatedGroup: "deprecated",
+		Expression:      "message.header.cid=abc",
 	}
 	filter := NewSubscriptionFilterFromQuery(query)
 	assert.Equal(t, expectedFilter, filter)
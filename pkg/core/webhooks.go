@@ -27,6 +27,8 @@ type WebhookSubOptions struct {
 	Headers  map[string]string   `ffstruct:"WebhookSubOptions" json:"headers,omitempty"`
 	Query    map[string]string   `ffstruct:"WebhookSubOptions" json:"query,omitempty"`
 	Input    WebhookInputOptions `ffstruct:"WebhookSubOptions" json:"input,omitempty"`
+	Signing  *WebhookSigning     `ffstruct:"WebhookSubOptions" json:"signing,omitempty"`
+	TLS      *WebhookTLS         `ffstruct:"WebhookSubOptions" json:"tls,omitempty"`
 }
 
 type WebhookInputOptions struct {
@@ -36,3 +38,21 @@ type WebhookInputOptions struct {
 	Path    string `ffstruct:"WebhookInputOptions" json:"path,omitempty"`
 	ReplyTX string `ffstruct:"WebhookInputOptions" json:"replytx,omitempty"`
 }
+
+// WebhookSigning configures HMAC signing of webhook deliveries. An inline secret is encrypted
+// before the subscription is stored, so only the encrypted form is ever returned. The encrypted
+// form is rejected on input, so it cannot be copied from one subscription to another.
+type WebhookSigning struct {
+	Secret          string `ffstruct:"WebhookSigning" json:"secret,omitempty"`
+	SecretRef       string `ffstruct:"WebhookSigning" json:"secretRef,omitempty"`
+	EncryptedSecret string `ffstruct:"WebhookSigning" json:"encryptedSecret,omitempty" ffexcludeinput:"true"`
+}
+
+// WebhookTLS is the TLS configuration for a webhook subscription, when it needs to differ from the
+// TLS configuration of the webhooks plugin. Files are only ever referenced through a named TLS
+// configuration of the webhooks plugin, so the API cannot be used to read files from the host.
+type WebhookTLS struct {
+	ConfigRef  string `ffstruct:"WebhookTLS" json:"configRef,omitempty"`
+	CA         string `ffstruct:"WebhookTLS" json:"ca,omitempty"`
+	ServerName string `ffstruct:"WebhookTLS" json:"serverName,omitempty"`
+}
@@ -29,6 +29,8 @@ var (
 
 	// WSProtocolErrorEventType is a special event "type" field for server to send the client, if it performs a ProtocolError
 	WSProtocolErrorEventType = fftypes.FFEnumValue("wstype", "protocol_error")
+	// WSEventBatchType is a special event "type" field for server to send the client a batch of events, which is acknowledged as a whole
+	WSEventBatchType = fftypes.FFEnumValue("wstype", "event_batch")
 )
 
 // WSActionBase is the base fields of all client actions sent on the websocket
@@ -61,3 +63,11 @@ type WSError struct {
 	Type  WSClientPayloadType `ffstruct:"WSAck" json:"type" ffenum:"wstype"`
 	Error string              `ffstruct:"WSAck" json:"error"`
 }
+
+// WSEventBatch is sent to the client by the server for subscriptions configured with batch delivery
+type WSEventBatch struct {
+	Type         WSClientPayloadType `ffstruct:"WSEventBatch" json:"type" ffenum:"wstype"`
+	ID           *fftypes.UUID       `ffstruct:"WSEventBatch" json:"id"`
+	Subscription SubscriptionRef     `ffstruct:"WSEventBatch" json:"subscription"`
+	Events       []*EventDelivery    `ffstruct:"WSEventBatch" json:"events"`
+}
@@ -0,0 +1,152 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+// Cursor is the position of the last record on a page of results, when paging through a collection in order
+// of its keyset, rather than by skip/limit. It is passed to clients as an opaque token.
+//
+// Collections with a local sequence are ordered by that sequence. Other collections are ordered by creation
+// time, with the ID breaking any tie.
+type Cursor struct {
+	Sequence *int64          `json:"s,omitempty"`
+	Created  *fftypes.FFTime `json:"c,omitempty"`
+	ID       *fftypes.UUID   `json:"i,omitempty"`
+}
+
+var (
+	sequenceKeyset = []string{"sequence"}
+	createdKeyset  = []string{"created", "id"}
+)
+
+// ParseCursor parses a token previously returned by Cursor.String
+func ParseCursor(ctx context.Context, token string) (*Cursor, error) {
+	var c Cursor
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(b, &c)
+	}
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgInvalidCursor, token)
+	}
+	return &c, nil
+}
+
+func (c *Cursor) String() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// CursorKeyset returns the fields that order a collection when paging by cursor,
+// or nil if the collection cannot be paged by cursor
+func CursorKeyset(ctx context.Context, qf ffapi.QueryFactory) []string {
+	fields := make(map[string]bool)
+	for _, f := range qf.NewFilter(ctx).Fields() {
+		fields[f] = true
+	}
+	switch {
+	case fields["sequence"]:
+		return sequenceKeyset
+	case fields["created"] && fields["id"]:
+		return createdKeyset
+	default:
+		return nil
+	}
+}
+
+// ApplyCursor orders the filter by the keyset of the collection, and restricts it to the records after the cursor.
+// A nil cursor requests the first page. The direction is taken from the filter, and defaults to descending.
+func ApplyCursor(ctx context.Context, filter ffapi.AndFilter, keyset []string, c *Cursor) error {
+	fi, err := filter.Finalize()
+	if err != nil {
+		return err
+	}
+	if len(fi.Sort) > 0 {
+		return i18n.NewError(ctx, coremsgs.MsgCursorNotCombinable, "sort")
+	}
+	if fi.Skip > 0 {
+		return i18n.NewError(ctx, coremsgs.MsgCursorNotCombinable, "skip")
+	}
+
+	for _, field := range keyset {
+		filter.Sort("-" + field)
+	}
+	if c == nil {
+		return nil
+	}
+
+	// Finalizing applies any ascending/descending flag from the query to the keyset
+	fi, _ = filter.Finalize()
+	fb := filter.Builder()
+	after := fb.Gt
+	if fi.Sort[0].Descending {
+		after = fb.Lt
+	}
+	switch {
+	case keyset[0] == "sequence" && c.Sequence != nil:
+		filter.Condition(after("sequence", *c.Sequence))
+	case keyset[0] == "created" && c.Created != nil && c.ID != nil:
+		filter.Condition(fb.Or(
+			after("created", c.Created),
+			fb.And(fb.Eq("created", c.Created), after("id", c.ID)),
+		))
+	default:
+		return i18n.NewError(ctx, coremsgs.MsgInvalidCursor, c.String())
+	}
+	return nil
+}
+
+// NextCursor returns the cursor for the page after the given record, which must be the last record
+// of the current page. Returns nil if the record does not have the fields of the keyset.
+func NextCursor(keyset []string, record interface{}) *Cursor {
+	v := reflect.Indirect(reflect.ValueOf(record))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	var c Cursor
+	if keyset[0] == "sequence" {
+		if seq, ok := fieldValue(v, "Sequence").(int64); ok {
+			c.Sequence = &seq
+		}
+	} else {
+		c.Created, _ = fieldValue(v, "Created").(*fftypes.FFTime)
+		c.ID, _ = fieldValue(v, "ID").(*fftypes.UUID)
+	}
+	if c.Sequence == nil && (c.Created == nil || c.ID == nil) {
+		return nil
+	}
+	return &c
+}
+
+func fieldValue(v reflect.Value, name string) interface{} {
+	f := v.FieldByName(name)
+	if !f.IsValid() {
+		return nil
+	}
+	return f.Interface()
+}
@@ -0,0 +1,143 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	seq := int64(12345)
+	c := &Cursor{Sequence: &seq}
+	c1, err := ParseCursor(context.Background(), c.String())
+	assert.NoError(t, err)
+	assert.Equal(t, c, c1)
+
+	c = &Cursor{Created: fftypes.Now(), ID: fftypes.NewUUID()}
+	c1, err = ParseCursor(context.Background(), c.String())
+	assert.NoError(t, err)
+	assert.Equal(t, c.ID, c1.ID)
+	assert.True(t, c.Created.Equal(c1.Created))
+}
+
+func TestParseCursorBadToken(t *testing.T) {
+	_, err := ParseCursor(context.Background(), "!!!")
+	assert.Regexp(t, "FF10487", err)
+	_, err = ParseCursor(context.Background(), "bm90IGpzb24")
+	assert.Regexp(t, "FF10487", err)
+}
+
+func TestCursorKeyset(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, []string{"sequence"}, CursorKeyset(ctx, MessageQueryFactory))
+	assert.Equal(t, []string{"sequence"}, CursorKeyset(ctx, EventQueryFactory))
+	assert.Equal(t, []string{"sequence"}, CursorKeyset(ctx, PinQueryFactory))
+	assert.Equal(t, []string{"created", "id"}, CursorKeyset(ctx, OperationQueryFactory))
+	assert.Nil(t, CursorKeyset(ctx, TokenBalanceQueryFactory))
+}
+
+func TestApplyCursorSequence(t *testing.T) {
+	ctx := context.Background()
+	filter := EventQueryFactory.NewFilter(ctx).And(EventQueryFactory.NewFilter(ctx).Eq("type", "message_confirmed"))
+	seq := int64(100)
+	err := ApplyCursor(ctx, filter, []string{"sequence"}, &Cursor{Sequence: &seq})
+	assert.NoError(t, err)
+	fi, err := filter.Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, "( type == 'message_confirmed' ) && ( sequence << 100 ) sort=-sequence", fi.String())
+
+	filter = EventQueryFactory.NewFilter(ctx).And()
+	filter.Ascending()
+	err = ApplyCursor(ctx, filter, []string{"sequence"}, &Cursor{Sequence: &seq})
+	assert.NoError(t, err)
+	fi, err = filter.Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, "( sequence >> 100 ) sort=sequence", fi.String())
+}
+
+func TestApplyCursorCreated(t *testing.T) {
+	ctx := context.Background()
+	id := fftypes.NewUUID()
+	created := fftypes.UnixTime(1000)
+	filter := OperationQueryFactory.NewFilter(ctx).And()
+	err := ApplyCursor(ctx, filter, []string{"created", "id"}, &Cursor{Created: created, ID: id})
+	assert.NoError(t, err)
+	fi, err := filter.Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("( ( created << 1000000000000 ) || ( ( created == 1000000000000 ) && ( id << '%s' ) ) ) sort=-created,-id", id), fi.String())
+}
+
+func TestApplyCursorFirstPage(t *testing.T) {
+	ctx := context.Background()
+	filter := OperationQueryFactory.NewFilter(ctx).And()
+	err := ApplyCursor(ctx, filter, []string{"created", "id"}, nil)
+	assert.NoError(t, err)
+	fi, err := filter.Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, " sort=-created,-id", fi.String())
+}
+
+func TestApplyCursorWrongKeyset(t *testing.T) {
+	ctx := context.Background()
+	filter := OperationQueryFactory.NewFilter(ctx).And()
+	seq := int64(100)
+	err := ApplyCursor(ctx, filter, []string{"created", "id"}, &Cursor{Sequence: &seq})
+	assert.Regexp(t, "FF10487", err)
+}
+
+func TestApplyCursorNotCombinable(t *testing.T) {
+	ctx := context.Background()
+	filter := EventQueryFactory.NewFilter(ctx).And()
+	filter.Sort("created")
+	err := ApplyCursor(ctx, filter, []string{"sequence"}, nil)
+	assert.Regexp(t, "FF10488.*sort", err)
+
+	filter = EventQueryFactory.NewFilter(ctx).And()
+	filter.Skip(10)
+	err = ApplyCursor(ctx, filter, []string{"sequence"}, nil)
+	assert.Regexp(t, "FF10488.*skip", err)
+}
+
+func TestApplyCursorBadFilter(t *testing.T) {
+	ctx := context.Background()
+	fb := EventQueryFactory.NewFilter(ctx)
+	filter := fb.And(fb.Eq("wrong", "value"))
+	err := ApplyCursor(ctx, filter, []string{"sequence"}, nil)
+	assert.Regexp(t, "FF00142", err)
+}
+
+func TestNextCursor(t *testing.T) {
+	c := NextCursor([]string{"sequence"}, &core.EnrichedEvent{Event: core.Event{Sequence: 42}})
+	assert.Equal(t, int64(42), *c.Sequence)
+
+	op := &core.Operation{ID: fftypes.NewUUID(), Created: fftypes.Now()}
+	c = NextCursor([]string{"created", "id"}, op)
+	assert.Equal(t, op.ID, c.ID)
+	assert.Equal(t, op.Created, c.Created)
+
+	assert.Nil(t, NextCursor([]string{"created", "id"}, &core.Operation{}))
+	assert.Nil(t, NextCursor([]string{"sequence"}, &core.Operation{}))
+	assert.Nil(t, NextCursor([]string{"sequence"}, "not a struct"))
+	assert.Nil(t, NextCursor([]string{"sequence"}, ffapi.FilterResultsWithCount{}))
+}
@@ -0,0 +1,115 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+)
+
+type filterModifiers struct {
+	negate          bool
+	caseInsensitive bool
+	emptyIsNull     bool
+}
+
+// FilterCondition parses the operator and modifier prefix of a single query value, such as "!:@abc", into a
+// condition on a field. It follows the same grammar as the filters on the REST collection APIs, for filters
+// that cannot be built from a fixed set of query fields.
+func FilterCondition(ctx context.Context, fb ffapi.FilterBuilder, field, value string) (ffapi.Filter, error) {
+	mods := filterModifiers{}
+	operator := make([]rune, 0, 2)
+	prefixLength := 0
+opFinder:
+	for _, r := range value {
+		switch r {
+		case '!':
+			mods.negate = true
+			prefixLength++
+		case ':':
+			mods.caseInsensitive = true
+			prefixLength++
+		case '?':
+			mods.emptyIsNull = true
+			prefixLength++
+		case '>', '<':
+			// "><" and "<>" are a single character operator, followed by the match string
+			if len(operator) == 1 && operator[0] != r {
+				break opFinder
+			}
+			operator = append(operator, r)
+			prefixLength++
+			if len(operator) > 1 {
+				break opFinder
+			}
+		case '=', '@', '^', '$':
+			operator = append(operator, r)
+			prefixLength++
+			break opFinder
+		default:
+			break opFinder
+		}
+	}
+
+	var matchString driver.Value = value[prefixLength:]
+	if mods.emptyIsNull && prefixLength == len(value) {
+		matchString = nil
+	}
+
+	type filterFunc func(name string, value driver.Value) ffapi.Filter
+	pick := func(f, notF, iF, notIF filterFunc) ffapi.Filter {
+		switch {
+		case mods.caseInsensitive && mods.negate:
+			return notIF(field, matchString)
+		case mods.caseInsensitive:
+			return iF(field, matchString)
+		case mods.negate:
+			return notF(field, matchString)
+		default:
+			return f(field, matchString)
+		}
+	}
+
+	op := string(operator)
+	switch op {
+	case ">=", "<=", ">", ">>", "<", "<<":
+		if mods != (filterModifiers{}) {
+			return nil, i18n.NewError(ctx, i18n.MsgQueryOpUnsupportedMod, op, field)
+		}
+		switch op {
+		case ">=":
+			return fb.Gte(field, matchString), nil
+		case "<=":
+			return fb.Lte(field, matchString), nil
+		case ">", ">>":
+			return fb.Gt(field, matchString), nil
+		default:
+			return fb.Lt(field, matchString), nil
+		}
+	case "@":
+		return pick(fb.Contains, fb.NotContains, fb.IContains, fb.NotIContains), nil
+	case "^":
+		return pick(fb.StartsWith, fb.NotStartsWith, fb.IStartsWith, fb.NotIStartsWith), nil
+	case "$":
+		return pick(fb.EndsWith, fb.NotEndsWith, fb.IEndsWith, fb.NotIEndsWith), nil
+	default:
+		return pick(fb.Eq, fb.Neq, fb.IEq, fb.NIeq), nil
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterCondition(t *testing.T) {
+	ctx := context.Background()
+	qf := ffapi.QueryFields{"f": &ffapi.StringField{}}
+	fb := qf.NewFilter(ctx)
+
+	for value, expected := range map[string]string{
+		"abc":    "f == 'abc'",
+		"=abc":   "f == 'abc'",
+		"!abc":   "f != 'abc'",
+		":abc":   "f := 'abc'",
+		"!:abc":  "f ;= 'abc'",
+		"@abc":   "f %= 'abc'",
+		"!@abc":  "f !% 'abc'",
+		":@abc":  "f :% 'abc'",
+		"!:@abc": "f ;% 'abc'",
+		"^abc":   "f ^= 'abc'",
+		"!^abc":  "f !^ 'abc'",
+		":^abc":  "f :^ 'abc'",
+		"!:^abc": "f ;^ 'abc'",
+		"$abc":   "f $= 'abc'",
+		"!$abc":  "f !$ 'abc'",
+		":$abc":  "f :$ 'abc'",
+		"!:$abc": "f ;$ 'abc'",
+		">=5":    "f >= '5'",
+		"<=5":    "f <= '5'",
+		">5":     "f >> '5'",
+		">>5":    "f >> '5'",
+		"<5":     "f << '5'",
+		"<<5":    "f << '5'",
+		"><5":    "f >> '<5'",
+		"?":      "f == null",
+		"!?":     "f != null",
+	} {
+		filter, err := FilterCondition(ctx, fb, "f", value)
+		assert.NoError(t, err)
+		fi, err := filter.Finalize()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, fi.String(), value)
+	}
+
+	_, err := FilterCondition(ctx, fb, "f", "!>5")
+	assert.Regexp(t, "FF00193", err)
+}
@@ -59,12 +59,37 @@ type Plugin interface {
 	// Init initializes the plugin, with configuration
 	Init(ctx context.Context, config config.Section) error
 
+	// InitSchema is an alternative to Init for offline tooling, which only connects to the database so that its
+	// schema can be inspected and migrated. No background processing, such as clustering, is started.
+	InitSchema(ctx context.Context, config config.Section) error
+
 	// SetHandler registers a handler to receive callbacks
 	// Plugin will attempt (but is not guaranteed) to deliver events only for the given namespace
 	SetHandler(namespace string, handler Callbacks)
 
 	// Capabilities returns capabilities - not called until after Init
 	Capabilities() *Capabilities
+
+	// AcquireLeadership blocks until this process is elected leader for the given name, across all
+	// processes sharing the same database. Leadership is held until the context is cancelled.
+	// The returned channel is closed if leadership is subsequently lost.
+	// Plugins that do not support clustering grant leadership immediately.
+	AcquireLeadership(ctx context.Context, name string) (lost <-chan struct{}, err error)
+
+	// SchemaStatus returns the version of the database schema, and any migrations not yet applied to it.
+	// Read-only, and safe to call while the node is running.
+	SchemaStatus(ctx context.Context) (*core.DatabaseSchemaStatus, error)
+
+	// MigrationStatus is the equivalent of SchemaStatus for offline tooling, including the SQL of each pending
+	// migration. The migration functions take ownership of the database connection, so once any of them have
+	// been called the plugin must not be used for anything else.
+	MigrationStatus(ctx context.Context) (*core.DatabaseSchemaStatus, error)
+
+	// MigrateUp applies all pending migrations, returning the migrations that were applied
+	MigrateUp(ctx context.Context) ([]*core.DatabaseMigration, error)
+
+	// MigrateDown rolls back the given number of migrations, returning the migrations that were rolled back
+	MigrateDown(ctx context.Context, steps int) ([]*core.DatabaseMigration, error)
 }
 
 type iNamespaceCollection interface {
@@ -97,8 +122,9 @@ type iMessageCollection interface {
 	// GetMessageByID - Get a message by ID
 	GetMessageByID(ctx context.Context, namespace string, id *fftypes.UUID) (message *core.Message, err error)
 
-	// GetMessages - List messages, reverse sorted (newest first) by Confirmed then Created, with pagination, and simple must filters
-	GetMessages(ctx context.Context, namespace string, filter ffapi.Filter) (message []*core.Message, res *ffapi.FilterResult, err error)
+	// GetMessages - List messages, reverse sorted (newest first) by Confirmed then Created, with pagination, and simple must filters.
+	// An optional value filter (from a ValueQueryFactory) restricts the messages to those with at least one data item whose value matches
+	GetMessages(ctx context.Context, namespace string, filter ffapi.Filter, valueFilter ffapi.Filter) (message []*core.Message, res *ffapi.FilterResult, err error)
 
 	// GetMessageIDs - Retrieves messages, but only querying the messages ID (no other fields)
 	GetMessageIDs(ctx context.Context, namespace string, filter ffapi.Filter) (ids []*core.IDAndSequence, err error)
@@ -111,6 +137,11 @@ type iMessageCollection interface {
 
 	// GetBatchIDsForDataAttachments - an optimized query to retrieve any non-null batch IDs for a list of data IDs that might be attached to messages in batches
 	GetBatchIDsForDataAttachments(ctx context.Context, namespace string, dataIDs []*fftypes.UUID) (batchIDs []*fftypes.UUID, err error)
+
+	// DeleteMessages - Bulk delete the messages matching a filter, with their data references (but not the data itself).
+	//                  Messages still needed according to the guards are not deleted. No change events are emitted.
+	//                  Returns the number of messages deleted.
+	DeleteMessages(ctx context.Context, namespace string, filter ffapi.Filter, guards *RetentionGuards) (count int64, err error)
 }
 
 type iDataCollection interface {
@@ -128,8 +159,8 @@ type iDataCollection interface {
 	// GetDataByID - Get a data record by ID
 	GetDataByID(ctx context.Context, namespace string, id *fftypes.UUID, withValue bool) (message *core.Data, err error)
 
-	// GetData - Get data
-	GetData(ctx context.Context, namespace string, filter ffapi.Filter) (message core.DataArray, res *ffapi.FilterResult, err error)
+	// GetData - Get data. An optional value filter (from a ValueQueryFactory) restricts the data to that with a matching value
+	GetData(ctx context.Context, namespace string, filter ffapi.Filter, valueFilter ffapi.Filter) (message core.DataArray, res *ffapi.FilterResult, err error)
 
 	// GetDataSubPaths - returns unique paths that have files in them, under the specified path.
 	// Requires DB specific processing of the blob.path field.
@@ -154,6 +185,10 @@ type iBatchCollection interface {
 
 	// GetBatches - Get batches
 	GetBatches(ctx context.Context, namespace string, filter ffapi.Filter) (message []*core.BatchPersisted, res *ffapi.FilterResult, err error)
+
+	// DeleteBatches - Bulk delete the batches matching a filter, other than those still needed according to the guards.
+	//                 No change events are emitted. Returns the number deleted.
+	DeleteBatches(ctx context.Context, namespace string, filter ffapi.Filter, guards *RetentionGuards) (count int64, err error)
 }
 
 type iTransactionCollection interface {
@@ -213,6 +248,10 @@ type iPinCollection interface {
 
 	// UpdatePins - Updates pins
 	UpdatePins(ctx context.Context, namespace string, filter ffapi.Filter, update ffapi.Update) (err error)
+
+	// DeletePins - Bulk delete the pins matching a filter, other than those still needed according to the guards.
+	//              No change events are emitted. Returns the number deleted.
+	DeletePins(ctx context.Context, namespace string, filter ffapi.Filter, guards *RetentionGuards) (count int64, err error)
 }
 
 type iOperationCollection interface {
@@ -227,6 +266,10 @@ type iOperationCollection interface {
 
 	// GetOperations - Get operation
 	GetOperations(ctx context.Context, namespace string, filter ffapi.Filter) (operation []*core.Operation, res *ffapi.FilterResult, err error)
+
+	// DeleteOperations - Bulk delete the operations matching a filter, other than those still needed according to the guards.
+	//                    No change events are emitted. Returns the number deleted.
+	DeleteOperations(ctx context.Context, namespace string, filter ffapi.Filter, guards *RetentionGuards) (count int64, err error)
 }
 
 type iSubscriptionCollection interface {
@@ -250,6 +293,26 @@ type iSubscriptionCollection interface {
 	DeleteSubscriptionByID(ctx context.Context, namespace string, id *fftypes.UUID) (err error)
 }
 
+type iDeadLetterCollection interface {
+	// InsertDeadLetter - Insert a dead letter for an event that could not be delivered to a subscription
+	InsertDeadLetter(ctx context.Context, deadLetter *core.DeadLetter) (err error)
+
+	// UpdateDeadLetter - Update a dead letter
+	UpdateDeadLetter(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) (err error)
+
+	// GetDeadLetterByID - Get a dead letter by ID
+	GetDeadLetterByID(ctx context.Context, namespace string, id *fftypes.UUID) (deadLetter *core.DeadLetter, err error)
+
+	// GetDeadLetters - Get dead letters
+	GetDeadLetters(ctx context.Context, namespace string, filter ffapi.Filter) (deadLetters []*core.DeadLetter, res *ffapi.FilterResult, err error)
+
+	// DeleteDeadLetter - Delete a dead letter
+	DeleteDeadLetter(ctx context.Context, namespace string, id *fftypes.UUID) (err error)
+
+	// DeleteDeadLetters - Delete all dead letters for a subscription
+	DeleteDeadLetters(ctx context.Context, namespace string, subscriptionID *fftypes.UUID) (err error)
+}
+
 type iEventCollection interface {
 	// InsertEvent - Insert an event. The order of the sequences added to the database, must match the order that
 	//               the rows/objects appear available to the event dispatcher. For a concurrency enabled database
@@ -262,6 +325,10 @@ type iEventCollection interface {
 
 	// GetEvents - Get events
 	GetEvents(ctx context.Context, namespace string, filter ffapi.Filter) (message []*core.Event, res *ffapi.FilterResult, err error)
+
+	// DeleteEvents - Bulk delete the events matching a filter, other than those still needed according to the guards.
+	//                No change events are emitted. Returns the number deleted.
+	DeleteEvents(ctx context.Context, namespace string, filter ffapi.Filter, guards *RetentionGuards) (count int64, err error)
 }
 
 type iIdentitiesCollection interface {
@@ -510,6 +577,10 @@ type iBlockchainEventCollection interface {
 
 	// GetBlockchainEvents - get blockchain events
 	GetBlockchainEvents(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.BlockchainEvent, *ffapi.FilterResult, error)
+
+	// DeleteBlockchainEvents - Bulk delete the blockchain events matching a filter, other than those still needed according to the guards.
+	//                          No change events are emitted. Returns the number deleted.
+	DeleteBlockchainEvents(ctx context.Context, namespace string, filter ffapi.Filter, guards *RetentionGuards) (count int64, err error)
 }
 
 // PersistenceInterface are the operations that must be implemented by a database interface plugin.
@@ -518,6 +589,12 @@ type iChartCollection interface {
 	GetChartHistogram(ctx context.Context, namespace string, intervals []core.ChartHistogramInterval, collection CollectionName) ([]*core.ChartHistogram, error)
 }
 
+type iRetention interface {
+	// GetRetentionGuards - Get the bounds of the records in a namespace that are still needed by the node, so must not be
+	//                      deleted. An empty namespace gets the bounds across all namespaces.
+	GetRetentionGuards(ctx context.Context, namespace string) (*RetentionGuards, error)
+}
+
 // PeristenceInterface are the operations that must be implemented by a database interface plugin.
 // The database mechanism of Firefly is designed to provide the balance between being able
 // to query the data a member of the network has transferred/received via Firefly efficiently,
@@ -562,6 +639,7 @@ type PersistenceInterface interface {
 	iPinCollection
 	iOperationCollection
 	iSubscriptionCollection
+	iDeadLetterCollection
 	iEventCollection
 	iIdentitiesCollection
 	iVerifiersCollection
@@ -581,6 +659,7 @@ type PersistenceInterface interface {
 	iContractListenerCollection
 	iBlockchainEventCollection
 	iChartCollection
+	iRetention
 }
 
 // CollectionName represents all collections
@@ -626,6 +705,7 @@ const (
 	CollectionFFIErrors         UUIDCollectionNS = "ffierrors"
 	CollectionContractAPIs      UUIDCollectionNS = "contractapis"
 	CollectionContractListeners UUIDCollectionNS = "contractlisteners"
+	CollectionDeadLetters       UUIDCollectionNS = "deadletters"
 	CollectionIdentities        UUIDCollectionNS = "identities"
 )
 
@@ -686,6 +766,17 @@ type Callbacks interface {
 // Capabilities defines the capabilities a plugin can report as implementing or not
 type Capabilities struct {
 	Concurrency bool
+	Clustering  bool
+}
+
+// RetentionGuards are the bounds of the records that are still needed by the node. Beyond these, records are also
+// needed while they are still being processed, or while they are referenced by an event that is still to be
+// delivered, or that can be replayed from a dead letter.
+type RetentionGuards struct {
+	// EventSequence events after this sequence are still to be delivered to a durable subscription
+	EventSequence int64
+	// PinSequence pins after this sequence have not yet been processed by the aggregator
+	PinSequence int64
 }
 
 // MessageQueryFactory filter fields for messages
@@ -1007,6 +1098,17 @@ var ContractListenerQueryFactory = &ffapi.QueryFields{
 	"state":     &ffapi.JSONField{},
 }
 
+// DeadLetterQueryFactory filter fields for subscription dead letters
+var DeadLetterQueryFactory = &ffapi.QueryFields{
+	"id":           &ffapi.UUIDField{},
+	"subscription": &ffapi.UUIDField{},
+	"event":        &ffapi.UUIDField{},
+	"attempts":     &ffapi.Int64Field{},
+	"reason":       &ffapi.StringField{},
+	"created":      &ffapi.TimeField{},
+	"updated":      &ffapi.TimeField{},
+}
+
 // BlockchainEventQueryFactory filter fields for contract events
 var BlockchainEventQueryFactory = &ffapi.QueryFields{
 	"id":              &ffapi.UUIDField{},
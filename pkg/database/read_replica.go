@@ -0,0 +1,42 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import "context"
+
+type readReplicaKey struct{}
+
+// WithReadReplica allows the queries made with the returned context to be served by a read replica
+// of the database, if the plugin has one configured. Results might lag behind recent writes.
+func WithReadReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readReplicaKey{}, true)
+}
+
+// WithPrimary forces the queries made with the returned context to be served by the primary database,
+// for callers that need to read their own writes, or that fill caches shared with other callers
+func WithPrimary(ctx context.Context) context.Context {
+	if !ReadReplicaAllowed(ctx) {
+		return ctx
+	}
+	return context.WithValue(ctx, readReplicaKey{}, false)
+}
+
+// ReadReplicaAllowed returns true if the context allows queries to be served by a read replica
+func ReadReplicaAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(readReplicaKey{}).(bool)
+	return allowed
+}
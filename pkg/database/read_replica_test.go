@@ -0,0 +1,34 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadReplicaContext(t *testing.T) {
+	ctx := context.Background()
+	assert.False(t, ReadReplicaAllowed(ctx))
+	assert.Equal(t, ctx, WithPrimary(ctx))
+	ctx = WithReadReplica(ctx)
+	assert.True(t, ReadReplicaAllowed(ctx))
+	ctx = WithPrimary(ctx)
+	assert.False(t, ReadReplicaAllowed(ctx))
+}
@@ -0,0 +1,62 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+// ValuePathPrefix is the prefix of the filter fields that match a path within the JSON value of data,
+// such as "value.customer.id"
+const ValuePathPrefix = "value."
+
+var valuePathSegment = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ParseValuePath returns the segments of the path in a "value.<path>" filter field. Segments that are
+// numbers select an element of an array.
+func ParseValuePath(ctx context.Context, field string) ([]string, error) {
+	if !strings.HasPrefix(field, ValuePathPrefix) {
+		return nil, i18n.NewError(ctx, coremsgs.MsgInvalidValuePath, field)
+	}
+	path := strings.Split(strings.TrimPrefix(field, ValuePathPrefix), ".")
+	for _, segment := range path {
+		if !valuePathSegment.MatchString(segment) {
+			return nil, i18n.NewError(ctx, coremsgs.MsgInvalidValuePath, field)
+		}
+	}
+	return path, nil
+}
+
+// ValueQueryFactory returns filter fields for the supplied "value.<path>" fields, to build the value filter
+// passed to GetData and GetMessages. The value at each path is matched as text - strings by their content,
+// and other types by their JSON.
+func ValueQueryFactory(ctx context.Context, fields ...string) (*ffapi.QueryFields, error) {
+	qf := ffapi.QueryFields{}
+	for _, field := range fields {
+		if _, err := ParseValuePath(ctx, field); err != nil {
+			return nil, err
+		}
+		qf[strings.ToLower(field)] = &ffapi.StringField{}
+	}
+	return &qf, nil
+}
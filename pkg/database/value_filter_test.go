@@ -0,0 +1,55 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseValuePath(t *testing.T) {
+	ctx := context.Background()
+	path, err := ParseValuePath(ctx, "value.customer.address_lines.0")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"customer", "address_lines", "0"}, path)
+
+	_, err = ParseValuePath(ctx, "customer")
+	assert.Regexp(t, "FF10503", err)
+	_, err = ParseValuePath(ctx, "value.")
+	assert.Regexp(t, "FF10503", err)
+	_, err = ParseValuePath(ctx, "value.a..b")
+	assert.Regexp(t, "FF10503", err)
+	_, err = ParseValuePath(ctx, "value.a'b")
+	assert.Regexp(t, "FF10503", err)
+}
+
+func TestValueQueryFactory(t *testing.T) {
+	ctx := context.Background()
+	qf, err := ValueQueryFactory(ctx, "value.customerId", "value.total")
+	assert.NoError(t, err)
+	fb := qf.NewFilter(ctx)
+	assert.ElementsMatch(t, []string{"value.customerid", "value.total"}, fb.Fields())
+
+	fi, err := fb.And(fb.Eq("value.customerId", "c1"), fb.Gt("value.total", "100")).Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, "value.customerId", fi.Children[0].Field)
+
+	_, err = ValueQueryFactory(ctx, "value.bad path")
+	assert.Regexp(t, "FF10503", err)
+}
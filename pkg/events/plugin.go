@@ -20,6 +20,7 @@ import (
 	"context"
 
 	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly/pkg/core"
 )
 
@@ -49,6 +50,11 @@ type Plugin interface {
 	// DeliveryRequest requests delivery of work on a connection, which must later be responded to
 	// Data will only be supplied as non-nil if the subscription is set to include data
 	DeliveryRequest(connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error
+
+	// BatchDeliveryRequest requests delivery of a batch of events on a connection, for subscriptions configured for batching.
+	// Only called if the plugin declares the BatchDelivery capability.
+	// The batch must later be responded to with a single DeliveryResponse, using the batch ID, which applies to all events in the batch
+	BatchDeliveryRequest(connID string, sub *core.Subscription, batchID *fftypes.UUID, events []*core.CombinedEventDataDelivery) error
 }
 
 type SubscriptionMatcher func(core.SubscriptionRef) bool
@@ -78,4 +84,9 @@ type Callbacks interface {
 	DeliveryResponse(connID string, inflight *core.EventDeliveryResponse)
 }
 
-type Capabilities struct{}
+type Capabilities struct {
+	// BatchDelivery indicates the plugin supports BatchDeliveryRequest
+	BatchDelivery bool
+	// Transform indicates the plugin delivers EventDelivery.Transformed in place of the event, when set
+	Transform bool
+}